@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// AdmissionStats is number of admitted and rejected sets
+type AdmissionStats struct {
+	Admitted int64
+	Rejected int64
+}
+
+// AdmissionCacher is cacher admitting values only for keys accessed frequently enough
+// frequency of keys is estimated with TinyLFU count-min sketch of gets and sets,
+// so one-hit-wonder keys do not evict valuable entries of a bounded cacher
+type AdmissionCacher struct {
+	Cacher
+	sketch    *internal.Sketch
+	threshold int
+	admitted  atomic.Int64
+	rejected  atomic.Int64
+}
+
+// AdmissionOption provides admission options
+type AdmissionOption func(*AdmissionCacher)
+
+// WithAdmissionThreshold returns option to set minimum estimated accesses of key,
+// including the set itself, for value to be admitted, default is 2
+func WithAdmissionThreshold(threshold int) AdmissionOption {
+	return func(a *AdmissionCacher) {
+		a.threshold = threshold
+	}
+}
+
+// WithSketchWidth returns option to set counters per row of frequency sketch,
+// it should be about the number of entries of the bounded cacher, default is 4096
+func WithSketchWidth(width int) AdmissionOption {
+	return func(a *AdmissionCacher) {
+		a.sketch = internal.NewSketch(width)
+	}
+}
+
+// Admission returns cacher filtering sets to c by estimated key frequency
+// values loaded with Load are always admitted
+func Admission(c Cacher, options ...AdmissionOption) *AdmissionCacher {
+	a := &AdmissionCacher{Cacher: c, threshold: 2}
+
+	for _, option := range options {
+		option(a)
+	}
+
+	if a.sketch == nil {
+		a.sketch = internal.NewSketch(4096)
+	}
+
+	return a
+}
+
+// Set sets key-value to cache if key is accessed frequently enough
+// rejected values are dropped without error
+func (a *AdmissionCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	a.sketch.Add(key)
+
+	if a.sketch.Estimate(key) < a.threshold {
+		a.rejected.Add(1)
+		return nil
+	}
+
+	a.admitted.Add(1)
+
+	return a.Cacher.Set(ctx, key, value, options...)
+}
+
+// Get gets value from cache and counts access of key
+func (a *AdmissionCacher) Get(ctx context.Context, key string) (any, error) {
+	a.sketch.Add(key)
+
+	return a.Cacher.Get(ctx, key)
+}
+
+// Stats returns admission stats
+func (a *AdmissionCacher) Stats() AdmissionStats {
+	return AdmissionStats{Admitted: a.admitted.Load(), Rejected: a.rejected.Load()}
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAdmission(t *testing.T) {
+	m := newMapCacher()
+	c := Admission(m)
+	ctx := context.Background()
+
+	// one hit wonder is rejected
+	_ = c.Set(ctx, "once", "value")
+	if _, ok := m.data["once"]; ok {
+		t.Errorf("Admission().Set() admitted one hit wonder")
+	}
+
+	// key read before set is admitted
+	_, _ = c.Get(ctx, "popular")
+	_ = c.Set(ctx, "popular", "value")
+	if _, ok := m.data["popular"]; !ok {
+		t.Errorf("Admission().Set() rejected popular key")
+	}
+
+	if got, want := c.Stats(), (AdmissionStats{Admitted: 1, Rejected: 1}); got != want {
+		t.Errorf("Admission().Stats() = %+v, want %+v", got, want)
+	}
+}
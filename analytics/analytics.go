@@ -0,0 +1,318 @@
+// Package analytics aggregates key usage of cache per key prefix over time windows
+// for capacity planning and tuning of expiration
+package analytics
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+)
+
+// OtherPrefix is prefix of keys counted after maximum number of prefixes is reached
+const OtherPrefix = "_other"
+
+// sizeBounds are value size histogram bounds in bytes, from 16B to 64MB
+var sizeBounds = internal.ExponentialBounds(16, 2, 23)
+
+// PrefixUsage is usage of keys with prefix in window
+type PrefixUsage struct {
+	Prefix  string `json:"prefix"`
+	Hits    int64  `json:"hits"`
+	Misses  int64  `json:"misses"`
+	Sets    int64  `json:"sets"`
+	Deletes int64  `json:"deletes"`
+	// Size is distribution of cost of set values in bytes, see cache.SetConfiguration.CostOf
+	Size cache.Distribution `json:"size"`
+}
+
+// Gets returns number of gets
+func (u PrefixUsage) Gets() int64 {
+	return u.Hits + u.Misses
+}
+
+// HitRatio returns ratio of hits to all gets, or zero if there is no get
+func (u PrefixUsage) HitRatio() float64 {
+	if gets := u.Gets(); gets > 0 {
+		return float64(u.Hits) / float64(gets)
+	}
+
+	return 0
+}
+
+// Window is usage of all prefixes in time window, ordered by prefix
+type Window struct {
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Prefixes []PrefixUsage `json:"prefixes"`
+}
+
+// counters are counters of prefix in current window
+type counters struct {
+	hits    int64
+	misses  int64
+	sets    int64
+	deletes int64
+	size    *internal.Histogram
+}
+
+// Analytics aggregates usage of keys per prefix in windows of fixed length
+// window is closed by the first operation after it ends or by Flush,
+// closed windows are kept in history and passed to exporters
+type Analytics struct {
+	mu          sync.Mutex
+	window      time.Duration
+	prefix      func(key string) string
+	maxPrefixes int
+	history     int
+	exporters   []func(Window)
+	now         func() time.Time
+
+	start   time.Time
+	current map[string]*counters
+	closed  []Window
+}
+
+// Option provides analytics options
+type Option func(*Analytics)
+
+// WithWindow returns option to set length of window, default is 1 minute
+func WithWindow(window time.Duration) Option {
+	return func(a *Analytics) {
+		a.window = window
+	}
+}
+
+// WithPrefixFunc returns option to set function returning prefix of key,
+// by default prefix is part of key before the first '.' or ':', or empty if there is none
+func WithPrefixFunc(prefix func(key string) string) Option {
+	return func(a *Analytics) {
+		a.prefix = prefix
+	}
+}
+
+// WithMaxPrefixes returns option to set maximum number of prefixes counted in window,
+// keys of other prefixes are counted as OtherPrefix, default is 1000
+func WithMaxPrefixes(n int) Option {
+	return func(a *Analytics) {
+		a.maxPrefixes = n
+	}
+}
+
+// WithHistory returns option to set number of closed windows kept, default is 60
+func WithHistory(n int) Option {
+	return func(a *Analytics) {
+		a.history = n
+	}
+}
+
+// WithExporter returns option to pass every closed window to export, it is called synchronously
+func WithExporter(export func(Window)) Option {
+	return func(a *Analytics) {
+		a.exporters = append(a.exporters, export)
+	}
+}
+
+// New returns analytics
+func New(options ...Option) *Analytics {
+	a := &Analytics{
+		window:      time.Minute,
+		prefix:      Prefix,
+		maxPrefixes: 1000,
+		history:     60,
+		now:         time.Now,
+	}
+
+	for _, option := range options {
+		option(a)
+	}
+
+	a.start = a.now()
+	a.current = make(map[string]*counters)
+
+	return a
+}
+
+// Prefix returns part of key before the first '.' or ':', or empty if there is none
+func Prefix(key string) string {
+	if i := strings.IndexAny(key, ".:"); i >= 0 {
+		return key[:i]
+	}
+
+	return ""
+}
+
+// record counts operation on key in current window
+func (a *Analytics) record(key string, count func(*counters)) {
+	prefix := a.prefix(key)
+
+	a.mu.Lock()
+	closed, ok := a.rotate(a.now())
+
+	c, found := a.current[prefix]
+	if !found {
+		if len(a.current) >= a.maxPrefixes {
+			prefix = OtherPrefix
+		}
+		if c, found = a.current[prefix]; !found {
+			c = &counters{size: internal.NewHistogram(sizeBounds)}
+			a.current[prefix] = c
+		}
+	}
+	count(c)
+	a.mu.Unlock()
+
+	if ok {
+		a.export(closed)
+	}
+}
+
+// rotate closes current window if it ended before now, must be called with lock held
+func (a *Analytics) rotate(now time.Time) (Window, bool) {
+	end := a.start.Add(a.window)
+	if now.Before(end) {
+		return Window{}, false
+	}
+
+	closed := a.snapshot(end)
+	a.keep(closed)
+	// windows without operations are skipped
+	a.start = end.Add(now.Sub(end).Truncate(a.window))
+	a.current = make(map[string]*counters)
+
+	return closed, true
+}
+
+// snapshot returns current window ending at end, must be called with lock held
+func (a *Analytics) snapshot(end time.Time) Window {
+	window := Window{Start: a.start, End: end, Prefixes: make([]PrefixUsage, 0, len(a.current))}
+	for prefix, c := range a.current {
+		usage := PrefixUsage{Prefix: prefix, Hits: c.hits, Misses: c.misses, Sets: c.sets, Deletes: c.deletes}
+		if c.size.Count() > 0 {
+			usage.Size = cache.Distribution{
+				Count: c.size.Count(),
+				Sum:   c.size.Sum(),
+				P50:   c.size.Quantile(0.50),
+				P95:   c.size.Quantile(0.95),
+				P99:   c.size.Quantile(0.99),
+			}
+		}
+		window.Prefixes = append(window.Prefixes, usage)
+	}
+	sort.Slice(window.Prefixes, func(i, j int) bool { return window.Prefixes[i].Prefix < window.Prefixes[j].Prefix })
+
+	return window
+}
+
+// keep appends closed window to history, must be called with lock held
+func (a *Analytics) keep(window Window) {
+	a.closed = append(a.closed, window)
+	if len(a.closed) > a.history {
+		a.closed = a.closed[len(a.closed)-a.history:]
+	}
+}
+
+// export passes closed window to exporters
+func (a *Analytics) export(window Window) {
+	for _, export := range a.exporters {
+		export(window)
+	}
+}
+
+// Flush closes current window now, even if it has not ended, and returns it
+func (a *Analytics) Flush() Window {
+	a.mu.Lock()
+	now := a.now()
+	closed := a.snapshot(now)
+	a.keep(closed)
+	a.start = now
+	a.current = make(map[string]*counters)
+	a.mu.Unlock()
+
+	a.export(closed)
+
+	return closed
+}
+
+// Current returns usage of current window so far
+func (a *Analytics) Current() Window {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.snapshot(a.now())
+}
+
+// Windows returns closed windows kept in history, oldest first
+func (a *Analytics) Windows() []Window {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return append([]Window(nil), a.closed...)
+}
+
+// tracked is cacher recording key usage in analytics
+type tracked struct {
+	cache.Cacher
+	analytics *Analytics
+}
+
+// Track returns cacher recording usage of keys of c
+// gets count as hits or misses, failed operations are not counted
+func (a *Analytics) Track(c cache.Cacher) cache.Cacher {
+	return &tracked{Cacher: c, analytics: a}
+}
+
+// Set sets key-value to cache and counts set and size of value
+func (t *tracked) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	if err := t.Cacher.Set(ctx, key, value, options...); err != nil {
+		return err
+	}
+
+	setConfig := &cache.SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+	size := setConfig.CostOf(value)
+
+	t.analytics.record(key, func(c *counters) {
+		c.sets++
+		c.size.Observe(size)
+	})
+
+	return nil
+}
+
+// Get gets value from cache and counts hit or miss
+func (t *tracked) Get(ctx context.Context, key string) (any, error) {
+	value, err := t.Cacher.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	t.analytics.record(key, func(c *counters) {
+		if value != nil {
+			c.hits++
+		} else {
+			c.misses++
+		}
+	})
+
+	return value, nil
+}
+
+// Delete deletes value from cache and counts delete
+func (t *tracked) Delete(ctx context.Context, key string) error {
+	if err := t.Cacher.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	t.analytics.record(key, func(c *counters) {
+		c.deletes++
+	})
+
+	return nil
+}
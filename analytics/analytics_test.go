@@ -0,0 +1,109 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+)
+
+func TestAnalytics(t *testing.T) {
+	ctx := context.Background()
+	clock := cachetest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var exported []Window
+	a := New(WithWindow(time.Minute), WithMaxPrefixes(2), WithExporter(func(w Window) {
+		exported = append(exported, w)
+	}))
+	a.now = clock.Now
+	a.start = clock.Now()
+	c := a.Track(cachetest.NewCacher())
+
+	_ = c.Set(ctx, "user.1", []byte("0123456789"))
+	_ = c.Set(ctx, "user.2", "value", cache.WithCost(100))
+	_, _ = c.Get(ctx, "user.1")
+	_, _ = c.Get(ctx, "user.3")
+	_, _ = c.Get(ctx, "session:1")
+	_ = c.Delete(ctx, "session:1")
+	_, _ = c.Get(ctx, "other")
+
+	if got := a.Current().Prefixes; len(got) != 3 || got[0].Prefix != OtherPrefix {
+		t.Errorf("Current().Prefixes = %+v, want 3 prefixes with %v", got, OtherPrefix)
+	}
+
+	// operation after window ends closes it, empty windows are skipped
+	clock.Advance(3*time.Minute + time.Second)
+	_, _ = c.Get(ctx, "user.1")
+
+	if len(exported) != 1 {
+		t.Fatalf("exported = %v windows, want %v", len(exported), 1)
+	}
+	window := exported[0]
+	if !window.End.Equal(window.Start.Add(time.Minute)) {
+		t.Errorf("window = %v - %v, want 1 minute", window.Start, window.End)
+	}
+
+	want := map[string]PrefixUsage{
+		OtherPrefix: {Prefix: OtherPrefix, Misses: 1},
+		"session":   {Prefix: "session", Misses: 1, Deletes: 1},
+		"user":      {Prefix: "user", Hits: 1, Misses: 1, Sets: 2},
+	}
+	for _, usage := range window.Prefixes {
+		w := want[usage.Prefix]
+		if usage.Hits != w.Hits || usage.Misses != w.Misses || usage.Sets != w.Sets || usage.Deletes != w.Deletes {
+			t.Errorf("usage = %+v, want %+v", usage, w)
+		}
+	}
+	user := window.Prefixes[2]
+	if user.Size.Count != 2 || user.Size.Sum != 110 {
+		t.Errorf("user size = %+v, want count %v and sum %v", user.Size, 2, 110)
+	}
+	if got := user.HitRatio(); got != 0.5 {
+		t.Errorf("HitRatio() = %v, want %v", got, 0.5)
+	}
+
+	current := a.Current()
+	if want := window.Start.Add(3 * time.Minute); !current.Start.Equal(want) {
+		t.Errorf("Current().Start = %v, want %v", current.Start, want)
+	}
+
+	flushed := a.Flush()
+	if len(flushed.Prefixes) != 1 || flushed.Prefixes[0].Hits != 1 {
+		t.Errorf("Flush() = %+v, want one hit of user", flushed)
+	}
+	if got := a.Windows(); len(got) != 2 {
+		t.Errorf("Windows() = %v windows, want %v", len(got), 2)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	windows := []Window{{
+		Start:    start,
+		End:      start.Add(time.Minute),
+		Prefixes: []PrefixUsage{{Prefix: "user", Hits: 3, Misses: 1, Sets: 1}},
+	}}
+
+	var csv bytes.Buffer
+	if err := WriteCSV(&csv, windows); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 2 || lines[1] != "2024-01-01T00:00:00Z,2024-01-01T00:01:00Z,user,4,3,1,0.7500,1,0,0,0,0,0,0" {
+		t.Errorf("WriteCSV() = %v", csv.String())
+	}
+
+	var data bytes.Buffer
+	if err := WriteJSON(&data, windows); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded []Window
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0].Prefixes[0].Hits != 3 {
+		t.Errorf("WriteJSON() = %v, error = %v", data.String(), err)
+	}
+}
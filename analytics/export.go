@@ -0,0 +1,59 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader is header row of csv export
+var csvHeader = []string{
+	"start", "end", "prefix", "gets", "hits", "misses", "hit_ratio", "sets", "deletes",
+	"size_count", "size_sum", "size_p50", "size_p95", "size_p99",
+}
+
+// WriteJSON writes windows to w as json array
+func WriteJSON(w io.Writer, windows []Window) error {
+	if windows == nil {
+		windows = []Window{}
+	}
+
+	return json.NewEncoder(w).Encode(windows)
+}
+
+// WriteCSV writes windows to w as csv with header, one row per prefix of window
+func WriteCSV(w io.Writer, windows []Window) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, window := range windows {
+		for _, usage := range window.Prefixes {
+			row := []string{
+				window.Start.Format(time.RFC3339),
+				window.End.Format(time.RFC3339),
+				usage.Prefix,
+				strconv.FormatInt(usage.Gets(), 10),
+				strconv.FormatInt(usage.Hits, 10),
+				strconv.FormatInt(usage.Misses, 10),
+				strconv.FormatFloat(usage.HitRatio(), 'f', 4, 64),
+				strconv.FormatInt(usage.Sets, 10),
+				strconv.FormatInt(usage.Deletes, 10),
+				strconv.FormatInt(usage.Size.Count, 10),
+				strconv.FormatInt(usage.Size.Sum, 10),
+				strconv.FormatInt(usage.Size.P50, 10),
+				strconv.FormatInt(usage.Size.P95, 10),
+				strconv.FormatInt(usage.Size.P99, 10),
+			}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// AuditRecord is audit record of a mutation
+type AuditRecord struct {
+	// Actor is who performed the mutation, as returned by actor extractor
+	Actor string
+	Time  time.Time
+	Op    Operation
+	// Key is key after redaction
+	Key string
+	// Value is value after redaction, empty when values are not audited
+	Value string
+	Err   error
+}
+
+// Redactor redacts sensitive data before it is written to audit record
+type Redactor func(string) string
+
+// RedactAll replaces data with fixed placeholder
+func RedactAll(string) string {
+	return "[REDACTED]"
+}
+
+// RedactHash replaces data with its SHA-256 hash, so equal data can still be correlated
+func RedactHash(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// audited is cacher emitting audit record for every mutation
+type audited struct {
+	Cacher
+	sink       func(context.Context, AuditRecord)
+	actor      func(context.Context) string
+	keyRedact  Redactor
+	valueAudit Redactor
+}
+
+// AuditOption provides audit options
+type AuditOption func(*audited)
+
+// WithAuditActor returns option to set function extracting actor from context
+func WithAuditActor(actor func(context.Context) string) AuditOption {
+	return func(a *audited) {
+		a.actor = actor
+	}
+}
+
+// WithKeyRedaction returns option to redact keys, by default keys are audited as is
+func WithKeyRedaction(redactor Redactor) AuditOption {
+	return func(a *audited) {
+		a.keyRedact = redactor
+	}
+}
+
+// WithValueAudit returns option to include values redacted by redactor in audit records
+// by default values are not audited, pass nil redactor to audit values as is
+func WithValueAudit(redactor Redactor) AuditOption {
+	return func(a *audited) {
+		if redactor == nil {
+			redactor = func(value string) string { return value }
+		}
+		a.valueAudit = redactor
+	}
+}
+
+// Audited returns cacher passing an audit record of every set, delete and load to sink
+// sink is called synchronously after the mutation
+func Audited(c Cacher, sink func(context.Context, AuditRecord), options ...AuditOption) Cacher {
+	a := &audited{Cacher: c, sink: sink}
+
+	for _, option := range options {
+		option(a)
+	}
+
+	return a
+}
+
+// audit passes record of mutation to sink
+func (a *audited) audit(ctx context.Context, op Operation, key string, value any, err error) {
+	record := AuditRecord{Time: time.Now(), Op: op, Key: key, Err: err}
+
+	if a.actor != nil {
+		record.Actor = a.actor(ctx)
+	}
+
+	if a.keyRedact != nil {
+		record.Key = a.keyRedact(key)
+	}
+
+	if a.valueAudit != nil && value != nil {
+		record.Value = a.valueAudit(fmt.Sprint(value))
+	}
+
+	a.sink(ctx, record)
+}
+
+// Set sets key-value to cache and audits it
+func (a *audited) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	err := a.Cacher.Set(ctx, key, value, options...)
+	a.audit(ctx, OpSet, key, value, err)
+
+	return err
+}
+
+// Delete deletes value from cache and audits it
+func (a *audited) Delete(ctx context.Context, key string) error {
+	err := a.Cacher.Delete(ctx, key)
+	a.audit(ctx, OpDelete, key, nil, err)
+
+	return err
+}
+
+// Load loads key-values into cache and audits every key
+func (a *audited) Load(ctx context.Context, data map[string]any) error {
+	err := a.Cacher.Load(ctx, data)
+	for key, value := range data {
+		a.audit(ctx, OpLoad, key, value, err)
+	}
+
+	return err
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+type actorKey struct{}
+
+func TestAudited(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   []AuditOption
+		wantKey   string
+		wantValue string
+	}{
+		{
+			name:    "test default",
+			options: nil,
+			wantKey: "key",
+		},
+		{
+			name:      "test hashed key and value",
+			options:   []AuditOption{WithKeyRedaction(RedactHash), WithValueAudit(RedactHash)},
+			wantKey:   RedactHash("key"),
+			wantValue: RedactHash("value"),
+		},
+		{
+			name:      "test redacted key and plain value",
+			options:   []AuditOption{WithKeyRedaction(RedactAll), WithValueAudit(nil)},
+			wantKey:   "[REDACTED]",
+			wantValue: "value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []AuditRecord
+			options := append(tt.options, WithAuditActor(func(ctx context.Context) string {
+				actor, _ := ctx.Value(actorKey{}).(string)
+				return actor
+			}))
+			c := Audited(newMapCacher(), func(_ context.Context, r AuditRecord) {
+				records = append(records, r)
+			}, options...)
+
+			ctx := context.WithValue(context.Background(), actorKey{}, "alice")
+			_ = c.Set(ctx, "key", "value")
+			_, _ = c.Get(ctx, "key")
+			_ = c.Delete(ctx, "key")
+
+			if len(records) != 2 {
+				t.Fatalf("Audited() records = %v, want %v", len(records), 2)
+			}
+			if r := records[0]; r.Op != OpSet || r.Actor != "alice" || r.Key != tt.wantKey || r.Value != tt.wantValue {
+				t.Errorf("Audited() set record = %+v, want key %v value %v", r, tt.wantKey, tt.wantValue)
+			}
+			if r := records[1]; r.Op != OpDelete || r.Key != tt.wantKey || r.Value != "" {
+				t.Errorf("Audited() delete record = %+v, want key %v", r, tt.wantKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var (
+	// ErrBackupFormat is returned when restored data is not a backup of supported version
+	ErrBackupFormat = errors.New("invalid backup format")
+)
+
+// backupMagic starts backup, it is followed by format version
+var backupMagic = []byte("CACHEBAK")
+
+const (
+	// backupVersion is version of backup format, version 2 records time backup is written
+	backupVersion byte = 2
+	// backupVersionUntimed is version of backup format without time backup is written
+	backupVersionUntimed byte = 1
+	// kindGob marks value encoded with gob, custom types must be registered with gob.Register
+	kindGob byte = 'g'
+)
+
+func init() {
+	// values unmarshalled from json are commonly cached
+	gob.Register(map[string]any{})
+	gob.Register([]any{})
+	gob.Register(time.Time{})
+}
+
+// Backup writes all keys of c with their values and remaining ttl to w and returns number of written keys
+// c must implement Scanner, ttl is written if c implements TTLReader
+// format is magic, version and time of backup in unix milliseconds followed by records of key,
+// kind of value, value and ttl in milliseconds, []byte and string values are written as is
+// and other values are gob encoded
+func Backup(ctx context.Context, c Cacher, w io.Writer) (int, error) {
+	scanner, ok := c.(Scanner)
+	if !ok {
+		return 0, ErrNotScanner
+	}
+	ttlReader, hasTTL := c.(TTLReader)
+
+	bw := bufio.NewWriter(w)
+	bw.Write(backupMagic)
+	bw.WriteByte(backupVersion)
+	bw.Write(binary.AppendVarint(nil, time.Now().UnixMilli()))
+
+	written := 0
+	it := scanner.Keys(ctx, "")
+	for it.Next(ctx) {
+		key := it.Key()
+
+		var value any
+		var ttl time.Duration
+		var err error
+		if hasTTL {
+			value, ttl, err = ttlReader.GetWithTTL(ctx, key)
+		} else {
+			value, err = c.Get(ctx, key)
+		}
+		if err != nil {
+			return written, fmt.Errorf("key %s: %w", key, err)
+		}
+		if value == nil {
+			continue
+		}
+
+		kind, data, err := encodeValue(value)
+		if err != nil {
+			return written, fmt.Errorf("key %s: %w", key, err)
+		}
+
+		bw.WriteByte(1)
+		writeBackupBytes(bw, []byte(key))
+		bw.WriteByte(kind)
+		writeBackupBytes(bw, data)
+		bw.Write(binary.AppendUvarint(nil, uint64(ttl.Milliseconds())))
+		written++
+	}
+	if err := it.Err(); err != nil {
+		return written, err
+	}
+
+	bw.WriteByte(0)
+
+	return written, bw.Flush()
+}
+
+// Restore stores all keys of backup read from r to c and returns number of restored keys
+// keys get ttl remaining when backup was written less time passed since then, keys which expired
+// in the meantime are skipped, keys without ttl get default ttl of c
+func Restore(ctx context.Context, c Cacher, r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(backupMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil || !bytes.Equal(header[:len(backupMagic)], backupMagic) {
+		return 0, ErrBackupFormat
+	}
+
+	var elapsed time.Duration
+	switch version := header[len(backupMagic)]; version {
+	case backupVersion:
+		written, err := binary.ReadVarint(br)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrBackupFormat, err)
+		}
+		elapsed = max(time.Since(time.UnixMilli(written)), 0)
+	case backupVersionUntimed:
+	default:
+		return 0, fmt.Errorf("%w: unsupported version %d", ErrBackupFormat, version)
+	}
+
+	restored := 0
+	for {
+		more, err := br.ReadByte()
+		if err != nil {
+			return restored, fmt.Errorf("%w: %v", ErrBackupFormat, err)
+		}
+		if more == 0 {
+			return restored, nil
+		}
+
+		key, err := readBackupBytes(br)
+		if err != nil {
+			return restored, fmt.Errorf("%w: %v", ErrBackupFormat, err)
+		}
+		kind, err := br.ReadByte()
+		if err != nil {
+			return restored, fmt.Errorf("%w: %v", ErrBackupFormat, err)
+		}
+		data, err := readBackupBytes(br)
+		if err != nil {
+			return restored, fmt.Errorf("%w: %v", ErrBackupFormat, err)
+		}
+		ttl, err := binary.ReadUvarint(br)
+		if err != nil {
+			return restored, fmt.Errorf("%w: %v", ErrBackupFormat, err)
+		}
+
+		value, err := decodeValue(kind, data)
+		if err != nil {
+			return restored, fmt.Errorf("key %s: %w", key, err)
+		}
+
+		var options []SetOption
+		if ttl > 0 {
+			remaining := time.Duration(ttl)*time.Millisecond - elapsed
+			if remaining <= 0 {
+				continue
+			}
+			options = append(options, WithTTL(remaining))
+		}
+		if err := c.Set(ctx, string(key), value, options...); err != nil {
+			return restored, fmt.Errorf("key %s: %w", key, err)
+		}
+		restored++
+	}
+}
+
+// encodeValue returns kind and encoded value, []byte and string values are kept as is and other values are gob encoded
+func encodeValue(value any) (byte, []byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return kindBytes, v, nil
+	case string:
+		return kindString, []byte(v), nil
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return 0, nil, err
+	}
+
+	return kindGob, buf.Bytes(), nil
+}
+
+// decodeValue returns value of kind and encoded value
+func decodeValue(kind byte, data []byte) (any, error) {
+	switch kind {
+	case kindBytes:
+		return data, nil
+	case kindString:
+		return string(data), nil
+	case kindGob:
+		var value any
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+			return nil, err
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown value kind %q", ErrBackupFormat, kind)
+	}
+}
+
+// writeBackupBytes writes length prefixed bytes
+func writeBackupBytes(w *bufio.Writer, data []byte) {
+	w.Write(binary.AppendUvarint(nil, uint64(len(data))))
+	w.Write(data)
+}
+
+// readBackupBytes reads length prefixed bytes
+func readBackupBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Backup writes all keys of cache with their values and remaining ttl to w and returns number of written keys,
+// see Backup, keys and values are written as stored by cacher, cacher must implement Scanner
+func (c *PatternedCache) Backup(ctx context.Context, w io.Writer) (int, error) {
+	ctx = withScope(ctx, c.scope)
+
+	written, err := Backup(ctx, c.unwrapped(), w)
+	if err != nil {
+		loggerFrom(ctx).Error(ctx, "failed to back up cache", "backup", "", err)
+	}
+
+	return written, err
+}
+
+// Restore stores all keys of backup read from r to cache and returns number of restored keys, see Restore,
+// e.g. to warm new instance from backup written on shutdown of previous one without reading persistence storage
+func (c *PatternedCache) Restore(ctx context.Context, r io.Reader) (int, error) {
+	ctx = withScope(ctx, c.scope)
+
+	restored, err := Restore(ctx, c.unwrapped(), r)
+	if err != nil {
+		loggerFrom(ctx).Error(ctx, "failed to restore cache", "restore", "", err)
+	}
+
+	return restored, err
+}
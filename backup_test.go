@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackup(t *testing.T) {
+	src := newMapCacher()
+	src.data = map[string]any{"a": "value", "b": []byte("bytes"), "c": 42, "d": map[string]any{"x": 1.5}}
+
+	buf := &bytes.Buffer{}
+	written, err := Backup(context.Background(), &scanCacher{src}, buf)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if written != 4 {
+		t.Errorf("Backup() = %v, want %v", written, 4)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    int
+		wantErr error
+	}{
+		{name: "test restore", data: buf.Bytes(), want: 4},
+		{name: "test not backup", data: []byte("not a backup"), wantErr: ErrBackupFormat},
+		{name: "test truncated backup", data: buf.Bytes()[:buf.Len()-5], want: 3, wantErr: ErrBackupFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newMapCacher()
+
+			got, err := Restore(context.Background(), dst, bytes.NewReader(tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Restore() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Restore() = %v, want %v", got, tt.want)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(dst.data, src.data) {
+				t.Errorf("Restore() data = %v, want %v", dst.data, src.data)
+			}
+		})
+	}
+
+	if _, err := Backup(context.Background(), src, buf); err != ErrNotScanner {
+		t.Errorf("Backup() error = %v, want %v", err, ErrNotScanner)
+	}
+}
+
+func TestRestore_Elapsed(t *testing.T) {
+	buf := &bytes.Buffer{}
+	bw := bufio.NewWriter(buf)
+	bw.Write(backupMagic)
+	bw.WriteByte(backupVersion)
+	bw.Write(binary.AppendVarint(nil, time.Now().Add(-2*time.Hour).UnixMilli()))
+	for key, ttl := range map[string]time.Duration{"expired": time.Hour, "kept": 3 * time.Hour, "forever": 0} {
+		bw.WriteByte(1)
+		writeBackupBytes(bw, []byte(key))
+		bw.WriteByte(kindString)
+		writeBackupBytes(bw, []byte("value"))
+		bw.Write(binary.AppendUvarint(nil, uint64(ttl.Milliseconds())))
+	}
+	bw.WriteByte(0)
+	_ = bw.Flush()
+
+	dst := newMapCacher()
+	c, _ := New(dst, nil)
+	restored, err := c.Restore(context.Background(), buf)
+	if err != nil || restored != 2 {
+		t.Fatalf("Restore() = %v, %v, want 2", restored, err)
+	}
+	if _, ok := dst.data["expired"]; ok {
+		t.Errorf("key expired since backup is restored")
+	}
+}
@@ -0,0 +1,483 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// BatchPattern is implemented by patterns which handle multiple keys at once
+// patterns which do not implement it are called once per key by PatternedCache
+type BatchPattern interface {
+	SetMany(context.Context, map[string]any, Cacher, Persister, ...SetOption) error
+	GetMany(context.Context, []string, Cacher, Persister) (map[string]any, error)
+	DeleteMany(context.Context, []string, Cacher, Persister) error
+}
+
+// SetMany sets multiple key-values to cache
+// returned error joins errors of all failed keys
+func (c *PatternedCache) SetMany(ctx context.Context, data map[string]any, options ...SetOption) error {
+	start := time.Now()
+	keys := internal.SortedKeys(data)
+	ctx, end := c.trace(ctx, OpSet, strings.Join(keys, ","))
+
+	var err error
+	if batch, ok := c.pattern.(BatchPattern); ok {
+		err = batch.SetMany(withScope(ctx, c.scope), data, c.cacher, c.persister, options...)
+	} else {
+		var errs []error
+		for _, key := range keys {
+			if _, err := c.handle(withScope(ctx, c.scope), &Call{Op: OpSet, Key: key, Value: data[key], Options: options}); err != nil {
+				errs = append(errs, keyError(key, err))
+			}
+		}
+		err = errors.Join(errs...)
+	}
+	c.scope.logger.Operation(ctx, "set_many", strings.Join(keys, ","), start, err)
+	failed := KeyErrors(err, keys)
+	for _, key := range keys {
+		RecordOperation(c.metrics, OpSet, start, data[key], failedErr(failed, key, err))
+	}
+
+	if err == nil {
+		for _, key := range keys {
+			c.scope.events.publish(Event{Type: EventSet, Key: key, Time: start})
+		}
+	}
+	end(nil, err)
+
+	return err
+}
+
+// GetMany retrieves values of multiple keys from cache
+// returned map contains only keys which are found, values of keys which fail to be retrieved
+// are missing and returned error joins their KeyError, see GetManyResult
+func (c *PatternedCache) GetMany(ctx context.Context, keys []string) (map[string]any, error) {
+	start := time.Now()
+	ctx, end := c.trace(ctx, OpGet, strings.Join(keys, ","))
+	ctx, loads := withLoads(ctx)
+
+	var values map[string]any
+	var err error
+	if batch, ok := c.pattern.(BatchPattern); ok {
+		values, err = batch.GetMany(withScope(ctx, c.scope), keys, c.cacher, c.persister)
+	} else {
+		var errs []error
+		values = make(map[string]any, len(keys))
+		for _, key := range keys {
+			value, gerr := c.handle(withScope(ctx, c.scope), &Call{Op: OpGet, Key: key})
+			if errors.Is(gerr, ErrNotFound) {
+				continue
+			}
+			if gerr != nil {
+				errs = append(errs, keyError(key, gerr))
+				continue
+			}
+			if value != nil {
+				values[key] = value
+			}
+		}
+		err = errors.Join(errs...)
+	}
+	c.scope.logger.Operation(ctx, "get_many", strings.Join(keys, ","), start, err)
+
+	failed := KeyErrors(err, keys)
+	for _, key := range keys {
+		recordGet(c.metrics, loads, key, start, values[key], failedErr(failed, key, err))
+		if _, ok := failed[key]; ok {
+			continue
+		}
+		_, found := values[key]
+		switch {
+		case found && loads.loaded(key):
+			c.scope.events.publish(Event{Type: EventLoad, Key: key, Time: start})
+		case found:
+			c.scope.events.publish(Event{Type: EventHit, Key: key, Time: start})
+		default:
+			c.scope.events.publish(Event{Type: EventMiss, Key: key, Time: start})
+		}
+	}
+
+	if len(values) > 0 {
+		end(values, err)
+	} else {
+		end(nil, err)
+	}
+
+	return values, err
+}
+
+// DeleteMany deletes values of multiple keys from cache
+// returned error joins errors of all failed keys
+func (c *PatternedCache) DeleteMany(ctx context.Context, keys ...string) error {
+	start := time.Now()
+	ctx, end := c.trace(ctx, OpDelete, strings.Join(keys, ","))
+
+	var err error
+	if batch, ok := c.pattern.(BatchPattern); ok {
+		err = batch.DeleteMany(withScope(ctx, c.scope), keys, c.cacher, c.persister)
+	} else {
+		var errs []error
+		for _, key := range keys {
+			if _, err := c.handle(withScope(ctx, c.scope), &Call{Op: OpDelete, Key: key}); err != nil {
+				errs = append(errs, keyError(key, err))
+			}
+		}
+		err = errors.Join(errs...)
+	}
+	c.scope.logger.Operation(ctx, "delete_many", strings.Join(keys, ","), start, err)
+	failed := KeyErrors(err, keys)
+	for _, key := range keys {
+		RecordOperation(c.metrics, OpDelete, start, nil, failedErr(failed, key, err))
+	}
+
+	if err == nil {
+		for _, key := range keys {
+			c.scope.events.publish(Event{Type: EventDelete, Key: key, Time: start})
+		}
+	}
+	end(nil, err)
+
+	return err
+}
+
+// failedErr returns error of key failed by batch operation returning err,
+// err fails all keys if it does not consist of KeyError
+func failedErr(failed map[string]error, key string, err error) error {
+	if err, ok := failed[key]; ok {
+		return err
+	}
+	if err != nil && !isKeyErrors(err) {
+		return err
+	}
+
+	return nil
+}
+
+// SetMany stores key-values to cache
+func (r *CacheAside) SetMany(ctx context.Context, data map[string]any, c Cacher, _ Persister, options ...SetOption) error {
+	return setMany(ctx, data, c, options...)
+}
+
+// GetMany retrieves values from cache
+func (r *CacheAside) GetMany(ctx context.Context, keys []string, c Cacher, _ Persister) (map[string]any, error) {
+	if skipped(ctx) || refreshed(ctx) {
+		return map[string]any{}, nil
+	}
+
+	return getMany(ctx, keys, c)
+}
+
+// DeleteMany deletes values from cache
+func (r *CacheAside) DeleteMany(ctx context.Context, keys []string, c Cacher, _ Persister) error {
+	return deleteMany(ctx, keys, c)
+}
+
+// SetMany stores key-values to cache
+func (r *ReadThrough) SetMany(ctx context.Context, data map[string]any, c Cacher, _ Persister, options ...SetOption) error {
+	return setMany(ctx, data, c, options...)
+}
+
+// GetMany retrieves values from cache
+// values not found are retrieved from persistence storage and stored to cache in one load
+func (r *ReadThrough) GetMany(ctx context.Context, keys []string, c Cacher, p Persister) (map[string]any, error) {
+	return readThroughMany(ctx, keys, c, p)
+}
+
+// DeleteMany deletes values from cache
+func (r *ReadThrough) DeleteMany(ctx context.Context, keys []string, c Cacher, _ Persister) error {
+	return deleteMany(ctx, keys, c)
+}
+
+// SetMany stores key-values to cache and persistence storage, which saves them at once if it implements
+// BatchPersister, values which fail to be saved are deleted from cache
+func (w *WriteThrough) SetMany(ctx context.Context, data map[string]any, c Cacher, p Persister, options ...SetOption) error {
+	if err := setMany(ctx, data, c, options...); err != nil {
+		return err
+	}
+
+	if p == nil {
+		return nil
+	}
+
+	var errs []error
+	failed := saveMany(ctx, data, p)
+	for _, key := range internal.SortedKeys(failed) {
+		err := failed[key]
+		errs = append(errs, keyError(key, err))
+
+		if derr := c.Delete(ctx, key); derr != nil {
+			loggerFrom(ctx).Error(ctx, "failed to delete value from cache", "delete", key, derr)
+		} else {
+			emit(ctx, Event{Type: EventEvictOnError, Key: key, Time: time.Now(), Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetMany retrieves values from cache
+// values not found are retrieved from persistence storage and stored to cache in one load
+func (w *WriteThrough) GetMany(ctx context.Context, keys []string, c Cacher, p Persister) (map[string]any, error) {
+	return readThroughMany(ctx, keys, c, p)
+}
+
+// DeleteMany deletes values from cache and persistence storage
+func (w *WriteThrough) DeleteMany(ctx context.Context, keys []string, c Cacher, p Persister) error {
+	if err := deleteMany(ctx, keys, c); err != nil {
+		return err
+	}
+
+	if p == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := p.Delete(ctx, key); err != nil {
+			errs = append(errs, keyError(key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SetMany stores key-values to cache and queues them to be persisted
+func (w *WriteBehind) SetMany(ctx context.Context, data map[string]any, c Cacher, p Persister, options ...SetOption) error {
+	release, err := w.admit()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := setMany(ctx, data, c, options...); err != nil {
+		return err
+	}
+
+	if p != nil {
+		for _, key := range internal.SortedKeys(data) {
+			if err := w.enqueue(ctx, OpSave, key, data[key], c, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetMany retrieves values from cache
+// values not found are retrieved from persistence storage and stored to cache in one load
+func (w *WriteBehind) GetMany(ctx context.Context, keys []string, c Cacher, p Persister) (map[string]any, error) {
+	return readThroughMany(ctx, keys, c, p)
+}
+
+// DeleteMany deletes values from cache and queues them to be deleted from persistence storage
+func (w *WriteBehind) DeleteMany(ctx context.Context, keys []string, c Cacher, p Persister) error {
+	release, err := w.admit()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := deleteMany(ctx, keys, c); err != nil {
+		return err
+	}
+
+	if p != nil {
+		for _, key := range keys {
+			if err := w.enqueue(ctx, OpDelete, key, nil, c, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetMany stores key-values to persistence storage, at once if it implements BatchPersister
+func (w *WriteAround) SetMany(ctx context.Context, data map[string]any, _ Cacher, p Persister, _ ...SetOption) error {
+	if p == nil {
+		return nil
+	}
+
+	var errs []error
+	failed := saveMany(ctx, data, p)
+	for _, key := range internal.SortedKeys(failed) {
+		errs = append(errs, keyError(key, failed[key]))
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetMany retrieves values from cache
+// values not found are retrieved from persistence storage and stored to cache in one load
+func (w *WriteAround) GetMany(ctx context.Context, keys []string, c Cacher, p Persister) (map[string]any, error) {
+	return readThroughMany(ctx, keys, c, p)
+}
+
+// DeleteMany deletes values from persistence storage and cache
+// values which fail to be deleted from persistence storage are kept in cache
+func (w *WriteAround) DeleteMany(ctx context.Context, keys []string, c Cacher, p Persister) error {
+	var errs []error
+	deleted := keys
+
+	if p != nil {
+		deleted = make([]string, 0, len(keys))
+		for _, key := range keys {
+			if err := p.Delete(ctx, key); err != nil {
+				loggerFrom(ctx).Error(ctx, "failed to delete value from persistence storage", "delete", key, err)
+				errs = append(errs, keyError(key, err))
+				continue
+			}
+			deleted = append(deleted, key)
+		}
+	}
+
+	if err := deleteMany(ctx, deleted, c); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// setMany stores key-values to cache
+// values are loaded at once if no set option is given, since load uses cacher global TTL
+func setMany(ctx context.Context, data map[string]any, c Cacher, options ...SetOption) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	if len(options) == 0 {
+		return c.Load(ctx, data)
+	}
+
+	var errs []error
+	for _, key := range internal.SortedKeys(data) {
+		if err := c.Set(ctx, key, data[key], options...); err != nil {
+			errs = append(errs, keyError(key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// getMany retrieves values from cache, only keys which are found are returned
+// returned error joins errors of keys which fail to be retrieved
+func getMany(ctx context.Context, keys []string, c Cacher) (map[string]any, error) {
+	return GetMany(ctx, c, keys)
+}
+
+// deleteMany deletes values from cache
+func deleteMany(ctx context.Context, keys []string, c Cacher) error {
+	return DeleteMany(ctx, c, keys...)
+}
+
+// readThroughMany retrieves values from cache
+// values not found are retrieved from persistence storage, at once if it implements BatchPersister,
+// and stored to cache in one load
+// cache is not read if context skips or refreshes cache, and not written if context skips cache
+// returned error joins errors of keys which fail to be retrieved from persistence storage
+func readThroughMany(ctx context.Context, keys []string, c Cacher, p Persister) (map[string]any, error) {
+	values := make(map[string]any, len(keys))
+	notFound := map[string]struct{}{}
+
+	if !skipped(ctx) && !refreshed(ctx) {
+		cached, err := GetMany(ctx, c, keys)
+		if err != nil {
+			loggerFrom(ctx).Error(ctx, "failed to get values from cache", "get_many", strings.Join(keys, ","), err)
+		}
+		for key, value := range cached {
+			if isNotFound(value) {
+				notFound[key] = struct{}{}
+				continue
+			}
+			values[key] = value
+		}
+	}
+
+	if p == nil {
+		return values, nil
+	}
+
+	missing := make([]string, 0, len(keys)-len(values))
+	for _, key := range keys {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if _, ok := notFound[key]; ok {
+			continue
+		}
+		missing = append(missing, key)
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	loaded, err := SelectMany(ctx, p, missing)
+	failed := failedKeys(err, missing)
+	for _, key := range missing {
+		if _, ok := failed[key]; ok {
+			continue
+		}
+		if value, ok := loaded[key]; ok && value != nil {
+			values[key] = value
+			markLoaded(ctx, key)
+			continue
+		}
+		delete(loaded, key)
+		if !skipped(ctx) {
+			cacheNotFound(ctx, c, key)
+		}
+	}
+
+	if len(loaded) > 0 && !skipped(ctx) {
+		if err := c.Load(ctx, loaded); err != nil {
+			loggerFrom(ctx).Error(ctx, "failed to load values to cache", "load", strings.Join(internal.SortedKeys(loaded), ","), err)
+		}
+	}
+
+	var errs []error
+	for _, key := range missing {
+		if err, ok := failed[key]; ok {
+			errs = append(errs, keyError(key, err))
+		}
+	}
+
+	return values, errors.Join(errs...)
+}
+
+// saveMany saves key-values to persistence storage at once if it implements BatchPersister
+// and returns errors of keys which fail to be saved
+func saveMany(ctx context.Context, data map[string]any, p Persister) map[string]error {
+	failed := failedKeys(SaveAll(ctx, p, data), internal.SortedKeys(data))
+	for _, key := range internal.SortedKeys(failed) {
+		loggerFrom(ctx).Error(ctx, "failed to save value to persistence storage", "save", key, failed[key])
+	}
+
+	return failed
+}
+
+// KeyError is error of operation on key
+type KeyError struct {
+	Key string
+	Err error
+}
+
+// Error returns error message prefixed by key
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+// Unwrap returns error of operation
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// keyError returns error annotated with key
+func keyError(key string, err error) error {
+	return &KeyError{Key: key, Err: err}
+}
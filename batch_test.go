@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// singlePattern hides batch operations of pattern
+type singlePattern struct {
+	Pattern
+}
+
+func TestPatternedCache_GetMany(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   Pattern
+		cached    map[string]any
+		persisted map[string]any
+		want      map[string]any
+		wantCache map[string]any
+	}{
+		{
+			name:      "test cache aside",
+			pattern:   &CacheAside{},
+			cached:    map[string]any{"a": 1},
+			persisted: map[string]any{"b": 2},
+			want:      map[string]any{"a": 1},
+			wantCache: map[string]any{"a": 1},
+		},
+		{
+			name:      "test read through",
+			pattern:   &ReadThrough{},
+			cached:    map[string]any{"a": 1},
+			persisted: map[string]any{"b": 2},
+			want:      map[string]any{"a": 1, "b": 2},
+			wantCache: map[string]any{"a": 1, "b": 2},
+		},
+		{
+			name:      "test single key pattern",
+			pattern:   &singlePattern{&ReadThrough{}},
+			cached:    map[string]any{"a": 1},
+			persisted: map[string]any{"b": 2},
+			want:      map[string]any{"a": 1, "b": 2},
+			wantCache: map[string]any{"a": 1, "b": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := newMapCacher()
+			cacher.data = tt.cached
+			persister := newMapPersister()
+			persister.data = tt.persisted
+			c, _ := New(cacher, persister, WithPattern(tt.pattern))
+			cached := len(tt.cached)
+
+			var hits, loads int
+			c.Subscribe(func(e Event) {
+				switch e.Type {
+				case EventHit:
+					hits++
+				case EventLoad:
+					loads++
+				}
+			})
+
+			got, err := c.GetMany(context.Background(), []string{"a", "b", "c"})
+			if err != nil {
+				t.Errorf("GetMany() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetMany() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(cacher.data, tt.wantCache) {
+				t.Errorf("GetMany() cache = %v, want %v", cacher.data, tt.wantCache)
+			}
+			if hits != cached || loads != len(tt.want)-cached {
+				t.Errorf("GetMany() hits, loads = %v, %v, want %v, %v", hits, loads, cached, len(tt.want)-cached)
+			}
+		})
+	}
+}
+
+func TestPatternedCache_SetMany(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       Pattern
+		saveErr       error
+		options       []SetOption
+		wantErr       bool
+		wantCache     map[string]any
+		wantPersisted map[string]any
+	}{
+		{
+			name:          "test cache aside",
+			pattern:       &CacheAside{},
+			wantCache:     map[string]any{"a": 1, "b": 2},
+			wantPersisted: map[string]any{},
+		},
+		{
+			name:          "test write through with options",
+			pattern:       &WriteThrough{},
+			options:       []SetOption{WithTTL(0)},
+			wantCache:     map[string]any{"a": 1, "b": 2},
+			wantPersisted: map[string]any{"a": 1, "b": 2},
+		},
+		{
+			name:          "test write through save error",
+			pattern:       &WriteThrough{},
+			saveErr:       errors.New("failed"),
+			wantErr:       true,
+			wantCache:     map[string]any{},
+			wantPersisted: map[string]any{},
+		},
+		{
+			name:          "test write around",
+			pattern:       &WriteAround{},
+			wantCache:     map[string]any{},
+			wantPersisted: map[string]any{"a": 1, "b": 2},
+		},
+		{
+			name:          "test single key pattern",
+			pattern:       &singlePattern{&WriteThrough{}},
+			wantCache:     map[string]any{"a": 1, "b": 2},
+			wantPersisted: map[string]any{"a": 1, "b": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := newMapCacher()
+			persister := newMapPersister()
+			persister.saveErr = tt.saveErr
+			c, _ := New(cacher, persister, WithPattern(tt.pattern))
+
+			err := c.SetMany(context.Background(), map[string]any{"a": 1, "b": 2}, tt.options...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetMany() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(cacher.data, tt.wantCache) {
+				t.Errorf("SetMany() cache = %v, want %v", cacher.data, tt.wantCache)
+			}
+			if !reflect.DeepEqual(persister.data, tt.wantPersisted) {
+				t.Errorf("SetMany() persisted = %v, want %v", persister.data, tt.wantPersisted)
+			}
+		})
+	}
+}
+
+func TestPatternedCache_DeleteMany(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       Pattern
+		wantCache     map[string]any
+		wantPersisted map[string]any
+	}{
+		{
+			name:          "test cache aside",
+			pattern:       &CacheAside{},
+			wantCache:     map[string]any{"c": 3},
+			wantPersisted: map[string]any{"a": 1, "b": 2, "c": 3},
+		},
+		{
+			name:          "test write behind",
+			pattern:       &WriteBehind{},
+			wantCache:     map[string]any{"c": 3},
+			wantPersisted: map[string]any{"c": 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := newMapCacher()
+			cacher.data = map[string]any{"a": 1, "b": 2, "c": 3}
+			persister := newMapPersister()
+			persister.data = map[string]any{"a": 1, "b": 2, "c": 3}
+			c, _ := New(cacher, persister, WithPattern(tt.pattern))
+
+			if err := c.DeleteMany(context.Background(), "a", "b"); err != nil {
+				t.Errorf("DeleteMany() error = %v", err)
+			}
+			if drainer, ok := tt.pattern.(Drainer); ok {
+				_ = drainer.Drain(context.Background())
+			}
+			if !reflect.DeepEqual(cacher.data, tt.wantCache) {
+				t.Errorf("DeleteMany() cache = %v, want %v", cacher.data, tt.wantCache)
+			}
+			if !reflect.DeepEqual(persister.data, tt.wantPersisted) {
+				t.Errorf("DeleteMany() persisted = %v, want %v", persister.data, tt.wantPersisted)
+			}
+		})
+	}
+}
+
+// failingPersister fails to select keys of errs
+type failingPersister struct {
+	mapPersister
+	errs map[string]error
+}
+
+func (p *failingPersister) SelectOne(ctx context.Context, key string) (any, error) {
+	if err, ok := p.errs[key]; ok {
+		return nil, err
+	}
+	return p.mapPersister.SelectOne(ctx, key)
+}
+
+func TestPatternedCache_GetManyResult(t *testing.T) {
+	errDown := errors.New("down")
+	for _, pattern := range []Pattern{&ReadThrough{}, &singlePattern{&ReadThrough{}}} {
+		persister := &failingPersister{mapPersister: *newMapPersister(), errs: map[string]error{"c": errDown}}
+		persister.data["b"] = 2
+		cacher := newMapCacher()
+		cacher.data["a"] = 1
+		c, _ := New(cacher, persister, WithPattern(pattern))
+
+		result := c.GetManyResult(context.Background(), []string{"a", "b", "c", "d"})
+		if want := map[string]any{"a": 1, "b": 2}; !reflect.DeepEqual(result.Values, want) {
+			t.Errorf("GetManyResult().Values = %v, want %v", result.Values, want)
+		}
+		if want := map[string]error{"c": errDown}; !reflect.DeepEqual(result.Errors, want) {
+			t.Errorf("GetManyResult().Errors = %v, want %v", result.Errors, want)
+		}
+		if want := []string{"d"}; !reflect.DeepEqual(result.Misses, want) {
+			t.Errorf("GetManyResult().Misses = %v, want %v", result.Misses, want)
+		}
+		if err := result.Err(); !errors.Is(err, errDown) || err.Error() != "c: down" {
+			t.Errorf("GetManyResult().Err() = %v, want %v", err, "c: down")
+		}
+	}
+}
+
+func TestPatternedCache_SetManyResult(t *testing.T) {
+	errDown := errors.New("down")
+	persister := &mapPersister{data: map[string]any{}, saveErr: errDown}
+	c, _ := New(newMapCacher(), persister, WithPattern(&WriteThrough{}))
+
+	result := c.SetManyResult(context.Background(), map[string]any{"a": 1, "b": 2})
+	if want := []string{"a", "b"}; !reflect.DeepEqual(result.Failed(), want) {
+		t.Errorf("SetManyResult().Failed() = %v, want %v", result.Failed(), want)
+	}
+	if !errors.Is(result.Errors["a"], errDown) {
+		t.Errorf("SetManyResult().Errors[a] = %v, want %v", result.Errors["a"], errDown)
+	}
+}
+
+func TestNewBatchResult(t *testing.T) {
+	errDown := errors.New("down")
+	result := NewBatchResult([]string{"a", "b"}, map[string]any{"a": 1}, errDown)
+	if want := map[string]error{"b": errDown}; !reflect.DeepEqual(result.Errors, want) || len(result.Misses) != 0 {
+		t.Errorf("NewBatchResult() = %+v, want b failed with %v", result, errDown)
+	}
+}
+
+// batchPersister saves and selects keys at once, failing keys of failKeys
+type batchPersister struct {
+	*mapPersister
+	failKeys map[string]bool
+	saves    int
+	selects  int
+}
+
+func (p *batchPersister) SaveAll(ctx context.Context, data map[string]any) error {
+	p.saves++
+
+	var errs []error
+	for key, value := range data {
+		if p.failKeys[key] {
+			errs = append(errs, keyError(key, errors.New("save failed")))
+			continue
+		}
+		_ = p.Save(ctx, key, value)
+	}
+	return errors.Join(errs...)
+}
+
+func (p *batchPersister) SelectMany(ctx context.Context, keys []string) (map[string]any, error) {
+	p.selects++
+
+	values := map[string]any{}
+	for _, key := range keys {
+		if value, _ := p.SelectOne(ctx, key); value != nil {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func TestPatternedCache_BatchPersister(t *testing.T) {
+	ctx := context.Background()
+	cacher := newMapCacher()
+	persister := &batchPersister{mapPersister: newMapPersister(), failKeys: map[string]bool{"c": true}}
+	c, _ := New(cacher, persister, WithPattern(&WriteThrough{}))
+
+	err := c.SetMany(ctx, map[string]any{"a": 1, "b": 2, "c": 3})
+	if failed := KeyErrors(err, []string{"a", "b", "c"}); len(failed) != 1 || failed["c"] == nil {
+		t.Errorf("SetMany() error = %v, want error of key c", err)
+	}
+	if persister.saves != 1 {
+		t.Errorf("SaveAll() calls = %d, want 1", persister.saves)
+	}
+	if _, ok := cacher.data["c"]; ok {
+		t.Errorf("value of key failed to be saved is cached")
+	}
+
+	cacher.data = map[string]any{"a": 1}
+	values, err := c.GetMany(ctx, []string{"a", "b", "c"})
+	if want := map[string]any{"a": 1, "b": 2}; err != nil || !reflect.DeepEqual(values, want) {
+		t.Errorf("GetMany() = %v, %v, want %v", values, err, want)
+	}
+	if persister.selects != 1 {
+		t.Errorf("SelectMany() calls = %d, want 1", persister.selects)
+	}
+	if cacher.data["b"] != 2 {
+		t.Errorf("selected value is not cached")
+	}
+}
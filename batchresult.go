@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// BatchResult is per-key outcome of batch operation, so partial success can be acted upon
+type BatchResult struct {
+	// Values are values of keys which are found
+	Values map[string]any
+	// Errors are errors of keys which failed
+	Errors map[string]error
+	// Misses are keys which are not found, in order they were given
+	Misses []string
+}
+
+// NewBatchResult returns result of batch operation on keys which returned values and err
+// KeyError joined in err fails its key, other errors fail all keys without value
+// keys which neither have value nor failed are misses
+func NewBatchResult(keys []string, values map[string]any, err error) *BatchResult {
+	result := &BatchResult{Values: make(map[string]any, len(values)), Errors: KeyErrors(err, keys)}
+
+	for _, key := range keys {
+		if _, ok := result.Errors[key]; ok {
+			continue
+		}
+		if value, ok := values[key]; ok {
+			result.Values[key] = value
+			continue
+		}
+		if err != nil && !isKeyErrors(err) {
+			result.Errors[key] = err
+			continue
+		}
+		result.Misses = append(result.Misses, key)
+	}
+
+	return result
+}
+
+// Err returns errors of all failed keys joined, nil if no key failed
+func (r *BatchResult) Err() error {
+	errs := make([]error, 0, len(r.Errors))
+	for _, key := range internal.SortedKeys(r.Errors) {
+		errs = append(errs, keyError(key, r.Errors[key]))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Failed returns sorted keys which failed
+func (r *BatchResult) Failed() []string {
+	return internal.SortedKeys(r.Errors)
+}
+
+// KeyErrors returns errors of keys found as KeyError in err, which may join multiple errors
+// only keys given are returned, errors are unwrapped from KeyError
+func KeyErrors(err error, keys []string) map[string]error {
+	errs := make(map[string]error)
+	if err == nil {
+		return errs
+	}
+
+	given := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		given[key] = true
+	}
+
+	var walk func(error)
+	walk = func(err error) {
+		var keyErr *KeyError
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, err := range joined.Unwrap() {
+				walk(err)
+			}
+		} else if errors.As(err, &keyErr) && given[keyErr.Key] {
+			errs[keyErr.Key] = keyErr.Err
+		}
+	}
+	walk(err)
+
+	return errs
+}
+
+// isKeyErrors reports whether err consists only of KeyError
+func isKeyErrors(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			if !isKeyErrors(err) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var keyErr *KeyError
+	return errors.As(err, &keyErr)
+}
+
+// GetManyResult retrieves values of multiple keys from cache like GetMany,
+// returning values, errors and misses per key
+func (c *PatternedCache) GetManyResult(ctx context.Context, keys []string) *BatchResult {
+	values, err := c.GetMany(ctx, keys)
+
+	return NewBatchResult(keys, values, err)
+}
+
+// SetManyResult sets multiple key-values to cache like SetMany, returning errors per key
+// result has no values and misses, keys without error are set
+func (c *PatternedCache) SetManyResult(ctx context.Context, data map[string]any, options ...SetOption) *BatchResult {
+	err := c.SetMany(ctx, data, options...)
+
+	result := &BatchResult{Values: map[string]any{}, Errors: KeyErrors(err, internal.SortedKeys(data))}
+	if err != nil && !isKeyErrors(err) {
+		for key := range data {
+			if _, ok := result.Errors[key]; !ok {
+				result.Errors[key] = err
+			}
+		}
+	}
+
+	return result
+}
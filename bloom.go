@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// BloomFilter is in-process bloom filter of keys written to cache or persistence storage,
+// cacher and persister wrapped with it short-circuit gets of keys which were never written,
+// so misses of sparse keyspace do not cost round trips to backends
+// keys are not removed from filter on delete, deleted keys are read from backends as before
+type BloomFilter struct {
+	bloom      *internal.Bloom
+	suppressed atomic.Int64
+}
+
+// NewBloomFilter returns bloom filter sized for capacity keys with false positive rate, e.g. 0.01
+func NewBloomFilter(capacity int, falsePositive float64) *BloomFilter {
+	return &BloomFilter{bloom: internal.NewBloom(capacity, falsePositive)}
+}
+
+// Add adds keys to filter
+func (b *BloomFilter) Add(keys ...string) {
+	for _, key := range keys {
+		b.bloom.Add(key)
+	}
+}
+
+// MayContain returns false if key was certainly never written and true if it may be
+func (b *BloomFilter) MayContain(key string) bool {
+	return b.bloom.MayContain(key)
+}
+
+// Suppressed returns number of gets short-circuited by filter
+func (b *BloomFilter) Suppressed() int64 {
+	return b.suppressed.Load()
+}
+
+// Warm adds all keys of persistence storage to filter
+func (b *BloomFilter) Warm(ctx context.Context, p Persister) error {
+	data, err := p.SelectAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for key := range data {
+		b.bloom.Add(key)
+	}
+
+	return nil
+}
+
+// suppress reports whether get of key is short-circuited
+func (b *BloomFilter) suppress(key string) bool {
+	if b.bloom.MayContain(key) {
+		return false
+	}
+
+	b.suppressed.Add(1)
+
+	return true
+}
+
+// Cacher returns cacher adding keys set or loaded to c to filter
+// and returning nil value without calling c for keys not in filter
+func (b *BloomFilter) Cacher(c Cacher) Cacher {
+	return &bloomCacher{Cacher: c, filter: b}
+}
+
+// Persister returns persister adding keys saved to or selected from p to filter
+// and returning nil value without calling p for keys not in filter
+func (b *BloomFilter) Persister(p Persister) Persister {
+	return &bloomPersister{Persister: p, filter: b}
+}
+
+// bloomCacher is cacher short-circuiting gets with bloom filter
+type bloomCacher struct {
+	Cacher
+	filter *BloomFilter
+}
+
+// Set adds key to filter and sets key-value to cache
+func (c *bloomCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	c.filter.Add(key)
+
+	return c.Cacher.Set(ctx, key, value, options...)
+}
+
+// Get gets value from cache if key may be in filter
+func (c *bloomCacher) Get(ctx context.Context, key string) (any, error) {
+	if c.filter.suppress(key) {
+		return nil, nil
+	}
+
+	return c.Cacher.Get(ctx, key)
+}
+
+// Load adds keys to filter and loads key-values into cache
+func (c *bloomCacher) Load(ctx context.Context, data map[string]any) error {
+	for key := range data {
+		c.filter.Add(key)
+	}
+
+	return c.Cacher.Load(ctx, data)
+}
+
+// bloomPersister is persister short-circuiting selects with bloom filter
+type bloomPersister struct {
+	Persister
+	filter *BloomFilter
+}
+
+// Save adds key to filter and saves key-value to persistence storage
+func (p *bloomPersister) Save(ctx context.Context, key string, value any) error {
+	p.filter.Add(key)
+
+	return p.Persister.Save(ctx, key, value)
+}
+
+// SelectOne selects value from persistence storage if key may be in filter
+func (p *bloomPersister) SelectOne(ctx context.Context, key string) (any, error) {
+	if p.filter.suppress(key) {
+		return nil, nil
+	}
+
+	return p.Persister.SelectOne(ctx, key)
+}
+
+// SelectAll selects all key-values from persistence storage and adds their keys to filter
+func (p *bloomPersister) SelectAll(ctx context.Context) (map[string]any, error) {
+	data, err := p.Persister.SelectAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for key := range data {
+		p.filter.Add(key)
+	}
+
+	return data, nil
+}
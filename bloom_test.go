@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	ctx := context.Background()
+	persister := newMapPersister()
+	persister.data["stored"] = "value"
+
+	filter := NewBloomFilter(100, 0.01)
+	if err := filter.Warm(ctx, persister); err != nil {
+		t.Fatalf("Warm() error = %v", err)
+	}
+
+	cacher := newMapCacher()
+	c, _ := New(filter.Cacher(cacher), filter.Persister(persister), WithPattern(&ReadThrough{}))
+
+	if got, _ := c.Get(ctx, "stored"); got != "value" {
+		t.Errorf("Get(stored) = %v, want %v", got, "value")
+	}
+
+	// never written key costs no round trip to cacher or persister
+	persister.data["unknown"] = "value"
+	if got, _ := c.Get(ctx, "unknown"); got != nil {
+		t.Errorf("Get(unknown) = %v, want nil", got)
+	}
+	if got := filter.Suppressed(); got != 2 {
+		t.Errorf("Suppressed() = %v, want %v", got, 2)
+	}
+
+	_ = c.Set(ctx, "written", "value")
+	if !filter.MayContain("written") {
+		t.Errorf("MayContain(written) = false, want true")
+	}
+	if got, _ := c.Get(ctx, "written"); got != "value" {
+		t.Errorf("Get(written) = %v, want %v", got, "value")
+	}
+}
@@ -0,0 +1,306 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen is returned by circuit breaker without fallback while cache is failing
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+)
+
+// BreakerState is state of circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed means operations reach cache
+	BreakerClosed BreakerState = iota
+	// BreakerOpen means cache is failing and operations fail fast or use fallback
+	BreakerOpen
+	// BreakerHalfOpen means open timeout is over and probes check whether cache recovered
+	BreakerHalfOpen
+)
+
+// String returns name of breaker state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerMetrics is implemented by metrics which record state changes of circuit breaker, e.g. Stats
+type BreakerMetrics interface {
+	// ObserveBreakerState records state circuit breaker changed to
+	ObserveBreakerState(state BreakerState)
+}
+
+// breakerChange is change of breaker state
+type breakerChange struct {
+	from BreakerState
+	to   BreakerState
+}
+
+// CircuitBreaker is cacher failing fast while cache is failing, e.g. when redis is down,
+// instead of paying timeout of every operation, reads and writes of open breaker go to fallback cacher if set
+type CircuitBreaker struct {
+	Cacher
+	threshold int
+	timeout   time.Duration
+	probes    int
+	fallback  Cacher
+	onChange  func(BreakerState)
+	metrics   BreakerMetrics
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	changes  []breakerChange
+	failures int
+	openEnd  time.Time
+	probing  int
+}
+
+// BreakerOption provides circuit breaker options
+type BreakerOption func(*CircuitBreaker)
+
+// WithFailureThreshold returns option to set number of consecutive failures opening breaker, default is 5
+func WithFailureThreshold(failures int) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.threshold = failures
+	}
+}
+
+// WithOpenTimeout returns option to set how long breaker stays open before it is probed, default is 10 seconds
+func WithOpenTimeout(timeout time.Duration) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.timeout = timeout
+	}
+}
+
+// WithHalfOpenProbes returns option to set number of concurrent operations probing half open breaker, default is 1
+func WithHalfOpenProbes(probes int) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.probes = probes
+	}
+}
+
+// WithFallback returns option to use fallback cacher, e.g. memory cacher, while breaker is open,
+// failed reads of closed breaker are also served by fallback, successful writes delete keys from fallback
+// so values stored while breaker was open are not served after cache recovers and fails again
+func WithFallback(fallback Cacher) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.fallback = fallback
+	}
+}
+
+// WithBreakerStateChange returns option to call onChange when breaker state changes,
+// it is called synchronously so it must not block
+func WithBreakerStateChange(onChange func(BreakerState)) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.onChange = onChange
+	}
+}
+
+// WithBreakerMetrics returns option to record state changes of breaker to metrics
+func WithBreakerMetrics(metrics BreakerMetrics) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.metrics = metrics
+	}
+}
+
+// NewCircuitBreaker returns cacher opening circuit to c after consecutive failures,
+// after open timeout probes reach c and close circuit if they succeed
+// errors of missing keys and unmet set conditions and canceled operations are not failures
+// state changes are emitted as EventBypass and EventRecover to subscribers of patterned cache
+func NewCircuitBreaker(c Cacher, options ...BreakerOption) *CircuitBreaker {
+	b := &CircuitBreaker{
+		Cacher:    c,
+		threshold: 5,
+		timeout:   10 * time.Second,
+		probes:    1,
+		now:       time.Now,
+	}
+
+	for _, option := range options {
+		option(b)
+	}
+
+	return b
+}
+
+// State returns current state of breaker
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// allow reports whether operation reaches cache, the first operations after open timeout are let through as probes
+func (b *CircuitBreaker) allow(ctx context.Context) bool {
+	b.mu.Lock()
+	defer b.notify(ctx)
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Before(b.openEnd) {
+			return false
+		}
+		b.change(BreakerHalfOpen)
+		b.probing = 1
+		return true
+	case BreakerHalfOpen:
+		if b.probing >= b.probes {
+			return false
+		}
+		b.probing++
+		return true
+	default:
+		return true
+	}
+}
+
+// record records result of operation and changes state if cache started failing or recovered
+func (b *CircuitBreaker) record(ctx context.Context, err error) {
+	b.mu.Lock()
+	defer b.notify(ctx)
+	defer b.mu.Unlock()
+
+	failed := err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrNotStored) && !errors.Is(err, context.Canceled)
+
+	switch b.state {
+	case BreakerHalfOpen:
+		b.probing--
+		if failed {
+			b.trip()
+			return
+		}
+		b.failures = 0
+		b.change(BreakerClosed)
+	case BreakerClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		if b.failures++; b.failures >= b.threshold {
+			b.trip()
+		}
+	}
+}
+
+// trip opens breaker, must be called with lock held
+func (b *CircuitBreaker) trip() {
+	b.openEnd = b.now().Add(b.timeout)
+	b.probing = 0
+	b.change(BreakerOpen)
+}
+
+// change sets state and queues notification about it, must be called with lock held
+func (b *CircuitBreaker) change(state BreakerState) {
+	if b.state == state {
+		return
+	}
+
+	b.changes = append(b.changes, breakerChange{from: b.state, to: state})
+	b.state = state
+}
+
+// notify notifies about queued state changes, it is called without lock held
+// so listeners can use breaker
+func (b *CircuitBreaker) notify(ctx context.Context) {
+	b.mu.Lock()
+	changes := b.changes
+	b.changes = nil
+	b.mu.Unlock()
+
+	for _, change := range changes {
+		if b.onChange != nil {
+			b.onChange(change.to)
+		}
+		if b.metrics != nil {
+			b.metrics.ObserveBreakerState(change.to)
+		}
+
+		// failed probe keeps cache bypassed, it is not a new bypass
+		switch {
+		case change.to == BreakerOpen && change.from == BreakerClosed:
+			emit(ctx, Event{Type: EventBypass, Time: b.now()})
+		case change.to == BreakerClosed:
+			emit(ctx, Event{Type: EventRecover, Time: b.now()})
+		}
+	}
+}
+
+// do calls fn with cache if breaker allows it, otherwise with fallback, and reports whether fallback is called,
+// ErrCircuitOpen is returned if breaker is open and there is no fallback
+func (b *CircuitBreaker) do(ctx context.Context, fn func(Cacher) error) (bool, error) {
+	if !b.allow(ctx) {
+		if b.fallback == nil {
+			return false, ErrCircuitOpen
+		}
+		return true, fn(b.fallback)
+	}
+
+	err := fn(b.Cacher)
+	b.record(ctx, err)
+
+	return false, err
+}
+
+// Set sets key-value to cache, or to fallback while breaker is open
+func (b *CircuitBreaker) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	fallback, err := b.do(ctx, func(c Cacher) error {
+		return c.Set(ctx, key, value, options...)
+	})
+	if err == nil && !fallback && b.fallback != nil {
+		_ = b.fallback.Delete(ctx, key)
+	}
+
+	return err
+}
+
+// Get gets value from cache, or from fallback while breaker is open or when cache fails
+func (b *CircuitBreaker) Get(ctx context.Context, key string) (any, error) {
+	var value any
+	fallback, err := b.do(ctx, func(c Cacher) (err error) {
+		value, err = c.Get(ctx, key)
+		return err
+	})
+	if err != nil && !fallback && b.fallback != nil && !errors.Is(err, ErrNotFound) {
+		return b.fallback.Get(ctx, key)
+	}
+
+	return value, err
+}
+
+// Delete deletes value from cache and fallback
+func (b *CircuitBreaker) Delete(ctx context.Context, key string) error {
+	fallback, err := b.do(ctx, func(c Cacher) error {
+		return c.Delete(ctx, key)
+	})
+	if err == nil && !fallback && b.fallback != nil {
+		_ = b.fallback.Delete(ctx, key)
+	}
+
+	return err
+}
+
+// Load loads data into cache, or into fallback while breaker is open
+func (b *CircuitBreaker) Load(ctx context.Context, data map[string]any) error {
+	_, err := b.do(ctx, func(c Cacher) error {
+		return c.Load(ctx, data)
+	})
+
+	return err
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failingCacher fails operations while err is set
+type failingCacher struct {
+	*mapCacher
+	err   error
+	calls int
+}
+
+func (f *failingCacher) Get(ctx context.Context, key string) (any, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.mapCacher.Get(ctx, key)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	primary := &failingCacher{mapCacher: newMapCacher()}
+	stats := NewStats("breaker")
+
+	var states []BreakerState
+	b := NewCircuitBreaker(primary, WithFailureThreshold(3), WithOpenTimeout(time.Second), WithBreakerMetrics(stats),
+		WithBreakerStateChange(func(state BreakerState) { states = append(states, state) }))
+	b.now = func() time.Time { return now }
+
+	_ = b.Set(ctx, "key", "cached")
+	primary.err = errors.New("connection refused")
+	for i := 0; i < 3; i++ {
+		if _, err := b.Get(ctx, "key"); err == nil {
+			t.Fatalf("Get() error = nil, want failure")
+		}
+	}
+	if got := b.State(); got != BreakerOpen {
+		t.Fatalf("State() = %v, want %v", got, BreakerOpen)
+	}
+
+	// open breaker fails fast without reaching cache
+	if _, err := b.Get(ctx, "key"); !errors.Is(err, ErrCircuitOpen) || primary.calls != 3 {
+		t.Errorf("Get() error = %v after %d calls, want %v after 3 calls", err, primary.calls, ErrCircuitOpen)
+	}
+
+	// failed probe opens breaker again
+	now = now.Add(time.Second)
+	_, _ = b.Get(ctx, "key")
+	if got := b.State(); got != BreakerOpen {
+		t.Fatalf("State() after failed probe = %v, want %v", got, BreakerOpen)
+	}
+
+	// successful probe closes breaker
+	now = now.Add(time.Second)
+	primary.err = nil
+	if got, err := b.Get(ctx, "key"); err != nil || got != "cached" {
+		t.Errorf("Get() = %v, %v, want %v", got, err, "cached")
+	}
+
+	want := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if len(states) != len(want) {
+		t.Fatalf("states = %v, want %v", states, want)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Errorf("states = %v, want %v", states, want)
+			break
+		}
+	}
+	if got := stats.Snapshot().BreakerOpens; got != 2 {
+		t.Errorf("BreakerOpens = %v, want %v", got, 2)
+	}
+}
+
+func TestCircuitBreaker_Fallback(t *testing.T) {
+	ctx := context.Background()
+	primary := &failingCacher{mapCacher: newMapCacher(), err: errors.New("timeout")}
+	fallback := newMapCacher()
+	b := NewCircuitBreaker(primary, WithFailureThreshold(1), WithFallback(fallback))
+
+	_ = fallback.Set(ctx, "key", "fallback")
+	for i := 0; i < 2; i++ {
+		if got, err := b.Get(ctx, "key"); err != nil || got != "fallback" {
+			t.Errorf("Get() = %v, %v, want %v", got, err, "fallback")
+		}
+	}
+
+	_ = b.Set(ctx, "other", "value")
+	if got, _ := fallback.Get(ctx, "other"); got != "value" {
+		t.Errorf("fallback Get() = %v, want %v", got, "value")
+	}
+}
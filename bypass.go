@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// skipKey is context key of skip cache flag
+type skipKey struct{}
+
+// refreshKey is context key of force refresh flag
+type refreshKey struct{}
+
+// staleKey is context key of max staleness of reads
+type staleKey struct{}
+
+// SkipCache returns context making patterns bypass cache on reads,
+// values are read from persistence storage and not stored to cache
+// writes still update cache so it stays consistent with persistence storage
+func SkipCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipKey{}, true)
+}
+
+// ForceRefresh returns context making patterns ignore cached values on reads,
+// values are reloaded from persistence storage and stored to cache
+func ForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, refreshKey{}, true)
+}
+
+// AllowStale returns context making reads serve values which are stale by at most maxStaleness
+// immediately while they are revalidated from persistence storage in background,
+// 0 maxStaleness allows any staleness, values are kept stale by cachers with stale retention,
+// see WithStaleRetention, or with soft ttl, see WithSoftTTL
+func AllowStale(ctx context.Context, maxStaleness time.Duration) context.Context {
+	return context.WithValue(ctx, staleKey{}, maxStaleness)
+}
+
+// skipped reports whether context skips cache
+func skipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipKey{}).(bool)
+	return skip
+}
+
+// refreshed reports whether context forces refresh
+func refreshed(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshKey{}).(bool)
+	return refresh
+}
+
+// staleAllowed reports whether context allows stale values and returns max staleness
+func staleAllowed(ctx context.Context) (time.Duration, bool) {
+	maxStaleness, ok := ctx.Value(staleKey{}).(time.Duration)
+	return maxStaleness, ok
+}
+
+// allowsStale reports whether context allows values stale for staleness
+func allowsStale(ctx context.Context, staleness time.Duration) bool {
+	maxStaleness, ok := staleAllowed(ctx)
+	return ok && (maxStaleness <= 0 || staleness <= maxStaleness)
+}
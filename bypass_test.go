@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSkipCacheAndForceRefresh(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        func(context.Context) context.Context
+		want       any
+		wantCached any
+	}{
+		{name: "test normal read", ctx: func(ctx context.Context) context.Context { return ctx }, want: "stale", wantCached: "stale"},
+		{name: "test skip cache", ctx: SkipCache, want: "fresh", wantCached: "stale"},
+		{name: "test force refresh", ctx: ForceRefresh, want: "fresh", wantCached: "fresh"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMapCacher()
+			m.data["key"] = "stale"
+			p := newMapPersister()
+			p.data["key"] = "fresh"
+
+			c, _ := New(m, p, WithPattern(&ReadThrough{}))
+			got, err := c.Get(tt.ctx(context.Background()), "key")
+			if err != nil || got != tt.want {
+				t.Errorf("PatternedCache.Get() = %v, %v, want %v", got, err, tt.want)
+			}
+			if cached := m.data["key"]; cached != tt.wantCached {
+				t.Errorf("PatternedCache.Get() cached = %v, want %v", cached, tt.wantCached)
+			}
+		})
+	}
+}
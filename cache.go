@@ -4,12 +4,19 @@ import (
 	"context"
 	"errors"
 	"io"
+	"log/slog"
 	"time"
+
+	"github.com/albinzx/cache/internal"
 )
 
 var (
 	// ErrCacherNil is returned when cacher is nil
 	ErrCacherNil = errors.New("cacher is nil")
+	// ErrInvalidOption is returned by validating constructors when options are invalid
+	ErrInvalidOption = errors.New("invalid cacher option")
+	// ErrNotFound is returned on miss by cachers and patterned cache with not found error option
+	ErrNotFound = errors.New("key not found")
 )
 
 // Cache defines cache operation
@@ -25,6 +32,16 @@ type Cache interface {
 // SetConfiguration holds configuration for set operation
 type SetConfiguration struct {
 	TTL time.Duration
+	// Cost is weight of value given by WithCost, 0 if it is not given
+	Cost int64
+	// SoftTTL is time value stays fresh given by WithSoftTTL, 0 means fresh until TTL
+	SoftTTL time.Duration
+	// Tags are tags of value given by WithTags
+	Tags []string
+	// Mode is condition of set given by IfNotExists or IfExists
+	Mode SetMode
+	// KeepTTL keeps remaining ttl of existing key, given by KeepTTL
+	KeepTTL bool
 }
 
 // SetOption provides options for set operation
@@ -37,6 +54,24 @@ func WithTTL(ttl time.Duration) SetOption {
 	}
 }
 
+// WithCost sets weight of value used by size-aware cachers for admission and eviction,
+// by default cost is serialized length of value
+func WithCost(cost int64) SetOption {
+	return func(setConfig *SetConfiguration) {
+		setConfig.Cost = cost
+	}
+}
+
+// CostOf returns cost given by WithCost or serialized length of value,
+// values which can not be serialized with gob cost 0
+func (s *SetConfiguration) CostOf(value any) int64 {
+	if s.Cost > 0 {
+		return s.Cost
+	}
+
+	return costOf(value)
+}
+
 // Cacher defines operation for cache implementation
 type Cacher interface {
 	io.Closer
@@ -70,6 +105,29 @@ type PatternedCache struct {
 	cacher    Cacher
 	persister Persister
 	pattern   Pattern
+	policy    *TTLPolicy
+	ttlFunc   func(key string, value any) time.Duration
+	codecs    *CodecRegistry
+	keyRules  *KeyRules
+	keyCodec  KeyEncoding
+	reporter  func(error)
+	notFound  bool
+	metrics   Metrics
+	tracer    Tracer
+	group     internal.Group
+
+	warmOnStart bool
+	warmOptions []WarmOption
+	negativeTTL time.Duration
+	degraded    *degradedReads
+	retry       internal.Retry
+	middlewares []Middleware
+	handle      Handler
+
+	slogger       *slog.Logger
+	logLevel      slog.Level
+	errorLogLevel slog.Level
+	scope         *scope
 }
 
 // New creates a new cache with the given cacher and persister
@@ -79,8 +137,10 @@ func New(cacher Cacher, persister Persister, options ...Option) (*PatternedCache
 	}
 
 	cache := &PatternedCache{
-		cacher:    cacher,
-		persister: persister,
+		cacher:        cacher,
+		persister:     persister,
+		logLevel:      slog.LevelDebug,
+		errorLogLevel: slog.LevelWarn,
 	}
 
 	for _, option := range options {
@@ -88,6 +148,16 @@ func New(cacher Cacher, persister Persister, options ...Option) (*PatternedCache
 	}
 	defaults(cache)
 
+	if w, ok := cache.pattern.(*WriteBehind); ok {
+		w.replay(withScope(context.Background(), cache.scope), cache.cacher, cache.persister)
+	}
+
+	if cache.warmOnStart {
+		if _, err := cache.Warm(context.Background(), cache.warmOptions...); err != nil {
+			cache.scope.logger.Error(context.Background(), "failed to warm cache", "warm", "", err)
+		}
+	}
+
 	return cache, nil
 }
 
@@ -99,6 +169,51 @@ func defaults(c *PatternedCache) {
 	if c.pattern == nil {
 		c.pattern = &CacheAside{}
 	}
+
+	// keys are encoded right before they reach cacher so other wrappers see keys as given
+	if c.keyCodec != nil {
+		c.cacher = EncodedKeys(c.cacher, c.keyCodec)
+	}
+
+	if c.codecs != nil {
+		c.cacher = Encoded(c.cacher, c.codecs)
+	}
+
+	if c.policy != nil {
+		c.cacher = &policyCacher{Cacher: c.cacher, policy: c.policy}
+	}
+
+	if c.ttlFunc != nil {
+		c.cacher = &ttlFuncCacher{Cacher: c.cacher, ttl: c.ttlFunc}
+	}
+
+	// key rules wrap all other wrappers so they see normalized keys
+	if c.keyRules != nil {
+		c.cacher = &keyRulesCacher{Cacher: c.cacher, rules: c.keyRules}
+	}
+
+	if c.tracer != nil && c.persister != nil {
+		c.persister = &tracedPersister{Persister: c.persister, tracer: c.tracer}
+	}
+
+	c.scope = &scope{
+		logger:   internal.NewLogger(c.slogger, c.logLevel, c.errorLogLevel).With("pattern", patternName(c.pattern)),
+		events:   &eventBus{},
+		reporter: c.reporter,
+		degraded: c.degraded,
+	}
+
+	// timeout and retry are innermost so middlewares run once per call
+	if c.retry.Enabled() {
+		c.retry.Transient = IsTransient
+		c.middlewares = append(c.middlewares, c.retrying)
+	}
+
+	c.handle = c.handler()
+
+	if c.negativeTTL > 0 || c.policy.negative() {
+		c.scope.negative = c.negative
+	}
 }
 
 // WithPattern returns option to set cache pattern
@@ -108,17 +223,87 @@ func WithPattern(pattern Pattern) Option {
 	}
 }
 
+// WithMetrics returns option to record results and latencies of operations of patterned cache to metrics,
+// e.g. Stats, gets of patterns reading persistence storage are hits when value is found in either
+func WithMetrics(metrics Metrics) Option {
+	return func(c *PatternedCache) {
+		c.metrics = metrics
+	}
+}
+
+// WithNotFoundError returns option to return ErrNotFound instead of nil value on miss of Get
+func WithNotFoundError() Option {
+	return func(c *PatternedCache) {
+		c.notFound = true
+	}
+}
+
 // Set sets key-value to cache
 func (c *PatternedCache) Set(ctx context.Context, key string, value any, options ...SetOption) error {
-	return c.pattern.Set(ctx, key, value, c.cacher, c.persister, options...)
+	start := time.Now()
+	ctx, end := c.trace(ctx, OpSet, key)
+	_, err := c.handle(withScope(ctx, c.scope), &Call{Op: OpSet, Key: key, Value: value, Options: options})
+	c.scope.logger.Operation(ctx, "set", key, start, err)
+	RecordOperation(c.metrics, OpSet, start, value, err)
+	end(nil, err)
+
+	if err == nil {
+		c.scope.events.publish(Event{Type: EventSet, Key: key, Time: start})
+	}
+
+	return err
 }
 
 // Get retrieves value from cache
 func (c *PatternedCache) Get(ctx context.Context, key string) (any, error) {
-	return c.pattern.Get(ctx, key, c.cacher, c.persister)
+	start := time.Now()
+	ctx, end := c.trace(ctx, OpGet, key)
+	ctx, loads := withLoads(ctx)
+	value, err := c.handle(withScope(ctx, c.scope), &Call{Op: OpGet, Key: key})
+	if errors.Is(err, ErrNotFound) {
+		value, err = nil, nil
+	}
+	c.scope.logger.Operation(ctx, "get", key, start, err)
+	recordGet(c.metrics, loads, key, start, value, err)
+	end(value, err)
+
+	if err == nil {
+		switch {
+		case value != nil && loads.loaded(key):
+			c.scope.events.publish(Event{Type: EventLoad, Key: key, Time: start})
+		case value != nil:
+			c.scope.events.publish(Event{Type: EventHit, Key: key, Time: start})
+		default:
+			c.scope.events.publish(Event{Type: EventMiss, Key: key, Time: start})
+		}
+	}
+
+	if err == nil && value == nil && c.notFound {
+		return nil, ErrNotFound
+	}
+
+	return value, err
 }
 
 // Delete deletes value from cache
 func (c *PatternedCache) Delete(ctx context.Context, key string) error {
-	return c.pattern.Delete(ctx, key, c.cacher, c.persister)
+	start := time.Now()
+	ctx, end := c.trace(ctx, OpDelete, key)
+	_, err := c.handle(withScope(ctx, c.scope), &Call{Op: OpDelete, Key: key})
+	c.scope.logger.Operation(ctx, "delete", key, start, err)
+	RecordOperation(c.metrics, OpDelete, start, nil, err)
+	end(nil, err)
+
+	if err == nil {
+		c.scope.events.publish(Event{Type: EventDelete, Key: key, Time: start})
+	}
+
+	return err
+}
+
+// Subscribe registers subscriber which receives cache events
+// subscribers are called synchronously so they must not block
+// returned function unregisters the subscriber
+func (c *PatternedCache) Subscribe(subscriber func(Event)) func() {
+	return c.scope.events.subscribe(subscriber)
 }
@@ -1,7 +1,9 @@
 package cache
 
 import (
+	"context"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 )
@@ -31,3 +33,144 @@ func TestWithTTL(t *testing.T) {
 		})
 	}
 }
+
+// mapCacher is map based cacher used in tests
+type mapCacher struct {
+	mu     sync.Mutex
+	data   map[string]any
+	setErr error
+	getErr error
+	delErr error
+}
+
+func newMapCacher() *mapCacher {
+	return &mapCacher{data: map[string]any{}}
+}
+
+func (m *mapCacher) Set(_ context.Context, key string, value any, _ ...SetOption) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.setErr != nil {
+		return m.setErr
+	}
+	m.data[key] = value
+	return nil
+}
+
+func (m *mapCacher) Get(_ context.Context, key string) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	return m.data[key], nil
+}
+
+func (m *mapCacher) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.delErr != nil {
+		return m.delErr
+	}
+	delete(m.data, key)
+	return nil
+}
+
+func (m *mapCacher) Load(_ context.Context, data map[string]any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, value := range data {
+		m.data[key] = value
+	}
+	return nil
+}
+
+func (m *mapCacher) Close() error {
+	return nil
+}
+
+// mapPersister is map based persister used in tests
+type mapPersister struct {
+	mu      sync.Mutex
+	data    map[string]any
+	saveErr error
+}
+
+func newMapPersister() *mapPersister {
+	return &mapPersister{data: map[string]any{}}
+}
+
+func (m *mapPersister) Save(_ context.Context, key string, value any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.data[key] = value
+	return nil
+}
+
+func (m *mapPersister) SelectOne(_ context.Context, key string) (any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.data[key], nil
+}
+
+func (m *mapPersister) SelectAll(_ context.Context) (map[string]any, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	all := make(map[string]any, len(m.data))
+	for key, value := range m.data {
+		all[key] = value
+	}
+	return all, nil
+}
+
+func (m *mapPersister) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.data, key)
+	return nil
+}
+
+func (m *mapPersister) Close() error {
+	return nil
+}
+
+func TestSetConfiguration_CostOf(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []SetOption
+		value   any
+		want    int64
+	}{
+		{name: "test given cost", options: []SetOption{WithCost(42)}, value: "value", want: 42},
+		{name: "test bytes length", value: []byte("value"), want: 5},
+		{name: "test string length", value: "value", want: 5},
+		{name: "test nil", value: nil, want: 0},
+		{name: "test not serializable", value: func() {}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setConfig := &SetConfiguration{}
+			for _, option := range tt.options {
+				option(setConfig)
+			}
+			if got := setConfig.CostOf(tt.value); got != tt.want {
+				t.Errorf("CostOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := (&SetConfiguration{}).CostOf(map[string]int{"a": 1}); got <= 0 {
+		t.Errorf("CostOf() = %v, want gob length", got)
+	}
+}
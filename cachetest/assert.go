@@ -0,0 +1,87 @@
+package cachetest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/albinzx/cache"
+)
+
+// calls is implemented by fakes recording calls
+type calls interface {
+	Calls(ops ...cache.Operation) []Call
+}
+
+// AssertCalled fails test if op was not called on key
+func AssertCalled(t testing.TB, fake calls, op cache.Operation, key string) {
+	t.Helper()
+
+	for _, call := range fake.Calls(op) {
+		if call.Key == key {
+			return
+		}
+	}
+
+	t.Errorf("%s of key %q was not called", op, key)
+}
+
+// AssertNotCalled fails test if op was called on key
+func AssertNotCalled(t testing.TB, fake calls, op cache.Operation, key string) {
+	t.Helper()
+
+	for _, call := range fake.Calls(op) {
+		if call.Key == key {
+			t.Errorf("%s of key %q was called", op, key)
+			return
+		}
+	}
+}
+
+// AssertCallCount fails test if op was not called n times
+func AssertCallCount(t testing.TB, fake calls, op cache.Operation, n int) {
+	t.Helper()
+
+	if got := len(fake.Calls(op)); got != n {
+		t.Errorf("%s was called %d times, want %d", op, got, n)
+	}
+}
+
+// AssertExpectations fails test if expectations of fake are not met, see Expect
+func AssertExpectations(t testing.TB, fake interface{ ExpectationsWereMet() error }) {
+	t.Helper()
+
+	if err := fake.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// AssertCached fails test if cacher does not hold value of key
+func AssertCached(t testing.TB, c *Cacher, key string, value any) {
+	t.Helper()
+
+	c.mu.Lock()
+	e, ok := c.lookup(key)
+	c.mu.Unlock()
+
+	if !ok {
+		t.Errorf("key %q is not cached, want %v", key, value)
+		return
+	}
+
+	if !reflect.DeepEqual(e.value, value) {
+		t.Errorf("key %q is cached with %v, want %v", key, e.value, value)
+	}
+}
+
+// AssertNotCached fails test if cacher holds value of key
+func AssertNotCached(t testing.TB, c *Cacher, key string) {
+	t.Helper()
+
+	c.mu.Lock()
+	e, ok := c.lookup(key)
+	c.mu.Unlock()
+
+	if ok {
+		t.Errorf("key %q is cached with %v, want not cached", key, e.value)
+	}
+}
@@ -0,0 +1,125 @@
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+const (
+	// benchKeys is number of distinct keys used by benchmarks
+	benchKeys = 1024
+	// latencySamples is number of latencies sampled per goroutine
+	latencySamples = 1 << 14
+)
+
+// Benchmark runs set, get, miss and mixed parallel benchmarks against cacher returned by factory
+// reporting allocations, operations per second and p99 latency of each benchmark
+func Benchmark(b *testing.B, factory Factory) {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("bench-%d", i)
+	}
+	value := string(make([]byte, 128))
+
+	benchmarks := []struct {
+		name string
+		op   func(ctx context.Context, c cache.Cacher, i int) error
+	}{
+		{name: "set", op: func(ctx context.Context, c cache.Cacher, i int) error {
+			return c.Set(ctx, keys[i%benchKeys], value)
+		}},
+		{name: "get", op: func(ctx context.Context, c cache.Cacher, i int) error {
+			_, err := c.Get(ctx, keys[i%benchKeys])
+			return err
+		}},
+		{name: "miss", op: func(ctx context.Context, c cache.Cacher, i int) error {
+			_, err := c.Get(ctx, "missing")
+			return err
+		}},
+		{name: "mixed", op: func(ctx context.Context, c cache.Cacher, i int) error {
+			// 1 set per 9 gets
+			if i%10 == 0 {
+				return c.Set(ctx, keys[i%benchKeys], value)
+			}
+			_, err := c.Get(ctx, keys[i%benchKeys])
+			return err
+		}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			c := factory(b)
+			b.Cleanup(func() { _ = c.Close() })
+
+			ctx := context.Background()
+			for _, key := range keys {
+				if err := c.Set(ctx, key, value); err != nil {
+					b.Fatalf("Set(%q) error = %v", key, err)
+				}
+			}
+
+			latencies := &latencies{}
+			b.ReportAllocs()
+			b.ResetTimer()
+			start := time.Now()
+
+			b.RunParallel(func(pb *testing.PB) {
+				// latencies are sampled into fixed buffer so recording them does not allocate
+				local := make([]time.Duration, 0, latencySamples)
+				i := 0
+				for pb.Next() {
+					opStart := time.Now()
+					if err := bm.op(ctx, c, i); err != nil {
+						b.Errorf("%s error = %v", bm.name, err)
+						return
+					}
+					if len(local) < latencySamples {
+						local = append(local, time.Since(opStart))
+					} else {
+						local[i%latencySamples] = time.Since(opStart)
+					}
+					i++
+				}
+				latencies.add(local)
+			})
+
+			elapsed := time.Since(start)
+			b.StopTimer()
+			b.ReportMetric(float64(b.N)/elapsed.Seconds(), "ops/s")
+			b.ReportMetric(float64(latencies.percentile(0.99).Nanoseconds()), "p99-ns")
+		})
+	}
+}
+
+// latencies collects latencies of operations of parallel goroutines
+type latencies struct {
+	mu     sync.Mutex
+	values []time.Duration
+}
+
+// add adds latencies of goroutine
+func (l *latencies) add(values []time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.values = append(l.values, values...)
+}
+
+// percentile returns latency at percentile p, e.g. 0.99
+func (l *latencies) percentile(p float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.values) == 0 {
+		return 0
+	}
+
+	sort.Slice(l.values, func(i, j int) bool { return l.values[i] < l.values[j] })
+
+	return l.values[int(p*float64(len(l.values)-1))]
+}
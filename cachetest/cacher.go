@@ -0,0 +1,185 @@
+package cachetest
+
+import (
+	"context"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// entry is value stored in fake cacher
+type entry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// Cacher is in-memory fake cacher which records calls
+// and expires values according to its clock
+type Cacher struct {
+	recorder
+	clock   *Clock
+	ttl     time.Duration
+	entries map[string]entry
+	closed  bool
+}
+
+// Option provides fake cacher options
+type Option func(*Cacher)
+
+// NewCacher returns fake cacher
+func NewCacher(options ...Option) *Cacher {
+	c := &Cacher{entries: make(map[string]entry)}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.clock == nil {
+		c.clock = NewClock(time.Now())
+	}
+
+	return c
+}
+
+// WithClock returns option to set clock used for expiration
+func WithClock(clock *Clock) Option {
+	return func(c *Cacher) {
+		c.clock = clock
+	}
+}
+
+// WithTTL returns option to set global TTL
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cacher) {
+		c.ttl = ttl
+	}
+}
+
+// Clock returns clock of cacher
+func (c *Cacher) Clock() *Clock {
+	return c.clock
+}
+
+// store stores value with ttl, must be called with lock held
+func (c *Cacher) store(key string, value any, ttl time.Duration) {
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expiresAt = c.clock.Now().Add(ttl)
+	}
+
+	c.entries[key] = e
+}
+
+// lookup returns live entry of key, must be called with lock held
+func (c *Cacher) lookup(key string) (entry, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		return e, false
+	}
+
+	if !e.expiresAt.IsZero() && !c.clock.Now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		return entry{}, false
+	}
+
+	return e, true
+}
+
+// Set sets key-value to cache
+func (c *Cacher) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	err := c.begin(ctx, cache.OpSet, key)
+	defer c.mu.Unlock()
+
+	setConfig := &cache.SetConfiguration{TTL: c.ttl}
+	for _, option := range options {
+		option(setConfig)
+	}
+
+	c.record(Call{Op: cache.OpSet, Key: key, Value: value, TTL: setConfig.TTL, Time: c.clock.Now(), Err: err})
+	if err != nil {
+		return err
+	}
+
+	c.store(key, value, setConfig.TTL)
+
+	return nil
+}
+
+// Get gets value from cache, nil is returned if value is not found or expired
+func (c *Cacher) Get(ctx context.Context, key string) (any, error) {
+	err := c.begin(ctx, cache.OpGet, key)
+	defer c.mu.Unlock()
+
+	e, _ := c.lookup(key)
+	c.record(Call{Op: cache.OpGet, Key: key, Value: e.value, Time: c.clock.Now(), Err: err})
+	if err != nil {
+		return nil, err
+	}
+
+	return e.value, nil
+}
+
+// Delete deletes value from cache
+func (c *Cacher) Delete(ctx context.Context, key string) error {
+	err := c.begin(ctx, cache.OpDelete, key)
+	defer c.mu.Unlock()
+
+	c.record(Call{Op: cache.OpDelete, Key: key, Time: c.clock.Now(), Err: err})
+	if err != nil {
+		return err
+	}
+
+	delete(c.entries, key)
+
+	return nil
+}
+
+// Load loads multiple key-values into cache with global TTL
+func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
+	err := c.begin(ctx, cache.OpLoad, "")
+	defer c.mu.Unlock()
+
+	c.record(Call{Op: cache.OpLoad, Value: data, TTL: c.ttl, Time: c.clock.Now(), Err: err})
+	if err != nil {
+		return err
+	}
+
+	for key, value := range data {
+		c.store(key, value, c.ttl)
+	}
+
+	return nil
+}
+
+// Close closes cacher
+func (c *Cacher) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+
+	return nil
+}
+
+// Closed reports whether cacher is closed
+func (c *Cacher) Closed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closed
+}
+
+// Len returns number of live values
+func (c *Cacher) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for key := range c.entries {
+		if _, ok := c.lookup(key); ok {
+			n++
+		}
+	}
+
+	return n
+}
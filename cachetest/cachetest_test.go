@@ -0,0 +1,164 @@
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+func TestCacher_Expiration(t *testing.T) {
+	clock := NewClock(time.Now())
+	c := NewCacher(WithClock(clock), WithTTL(time.Minute))
+	ctx := context.Background()
+
+	_ = c.Set(ctx, "key", "value")
+	_ = c.Set(ctx, "long", "value", cache.WithTTL(time.Hour))
+
+	clock.Advance(time.Minute)
+	AssertNotCached(t, c, "key")
+	AssertCached(t, c, "long", "value")
+
+	if got := c.Len(); got != 1 {
+		t.Errorf("Cacher.Len() = %v, want %v", got, 1)
+	}
+}
+
+func TestCacher_Fail(t *testing.T) {
+	c := NewCacher()
+	ctx := context.Background()
+	errDown := errors.New("down")
+
+	c.Fail(cache.OpGet, errDown, nil)
+	if _, err := c.Get(ctx, "key"); !errors.Is(err, errDown) {
+		t.Errorf("Cacher.Get() error = %v, want %v", err, errDown)
+	}
+	if _, err := c.Get(ctx, "key"); err != nil {
+		t.Errorf("Cacher.Get() error = %v, want nil", err)
+	}
+
+	AssertCallCount(t, c, cache.OpGet, 2)
+	if calls := c.Calls(cache.OpGet); calls[0].Err != errDown {
+		t.Errorf("Cacher.Calls() error = %v, want %v", calls[0].Err, errDown)
+	}
+}
+
+func TestPersister_Delay(t *testing.T) {
+	p := NewPersister(map[string]any{"key": "value"})
+	p.Delay(cache.OpSelectOne, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.SelectOne(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Persister.SelectOne() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	p.Delay(cache.OpSelectOne, 0)
+	if got, err := p.SelectOne(context.Background(), "key"); err != nil || got != "value" {
+		t.Errorf("Persister.SelectOne() = %v, %v, want %v", got, err, "value")
+	}
+}
+
+func TestCacher_Expect(t *testing.T) {
+	ctx := context.Background()
+	c := NewCacher()
+	p := NewPersister(map[string]any{"key": "value"})
+	errDown := errors.New("down")
+
+	c.Expect(cache.OpGet, "key")
+	c.Expect(cache.OpSet, "key").ReturnError(errDown)
+	p.Expect(cache.OpSelectOne, "key")
+
+	pc, _ := cache.New(c, p, cache.WithPattern(&cache.ReadThrough{}))
+	if got, _ := pc.Get(ctx, "key"); got != "value" {
+		t.Errorf("ReadThrough.Get() = %v, want %v", got, "value")
+	}
+
+	AssertExpectations(t, c)
+	AssertExpectations(t, p)
+	AssertNotCached(t, c, "key")
+
+	c.Expect(cache.OpDelete, "")
+	if err := c.ExpectationsWereMet(); err == nil {
+		t.Errorf("Cacher.ExpectationsWereMet() error = nil, want unmet delete")
+	}
+	c.Reset()
+	if err := c.ExpectationsWereMet(); err != nil {
+		t.Errorf("Cacher.ExpectationsWereMet() after reset error = %v", err)
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	ctx := context.Background()
+	c := NewCacher()
+	p := NewPersister(map[string]any{"key": "value"})
+
+	pc, _ := cache.New(c, p, cache.WithPattern(&cache.ReadThrough{}))
+	if got, _ := pc.Get(ctx, "key"); got != "value" {
+		t.Errorf("ReadThrough.Get() = %v, want %v", got, "value")
+	}
+
+	AssertCalled(t, p, cache.OpSelectOne, "key")
+	AssertCalled(t, c, cache.OpSet, "key")
+	AssertCached(t, c, "key", "value")
+
+	p.Fail(cache.OpSave, errors.New("down"))
+	pc, _ = cache.New(c, p, cache.WithPattern(&cache.WriteThrough{}))
+	if err := pc.Set(ctx, "other", "value"); err == nil {
+		t.Errorf("WriteThrough.Set() error = %v, want error", err)
+	}
+
+	AssertNotCached(t, c, "other")
+	AssertNotCalled(t, p, cache.OpDelete, "other")
+}
+
+func TestConformance(t *testing.T) {
+	var clock *Clock
+	Conformance(t, func(testing.TB) cache.Cacher {
+		c := NewCacher()
+		clock = c.Clock()
+		return c
+	}, WithSleep(func(d time.Duration) { clock.Advance(d) }))
+}
+
+func BenchmarkCacher(b *testing.B) {
+	Benchmark(b, func(testing.TB) cache.Cacher { return NewCacher() })
+}
+
+func TestClock_Ticker(t *testing.T) {
+	clock := NewClock(time.Now())
+	ticker := clock.NewTicker(time.Minute)
+	after := clock.After(time.Hour)
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-ticker.C():
+		t.Fatal("ticker fired before interval")
+	default:
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case tick := <-ticker.C():
+		if !tick.Equal(clock.Now()) {
+			t.Errorf("tick = %v, want %v", tick, clock.Now())
+		}
+	default:
+		t.Fatal("ticker did not fire after interval")
+	}
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	select {
+	case <-ticker.C():
+		t.Error("stopped ticker fired")
+	default:
+	}
+	select {
+	case <-after:
+	default:
+		t.Error("After did not fire")
+	}
+}
@@ -0,0 +1,157 @@
+// Package cachetest provides deterministic fakes of cachers and persisters for tests,
+// fakes are safe for concurrent use, record calls, fail and delay calls as scripted by tests
+// and check calls tests expect, so patterns can be tested without a backend
+package cachetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// Clock is manually advanced clock, it can be given to cachers and patterns as cache.Clock,
+// e.g. memory.WithClock, so tests advance time past ttl instead of sleeping
+// tickers and After channels of clock fire when clock is advanced past their time
+type Clock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is ticker or After channel waiting for time of clock
+type waiter struct {
+	at     time.Time
+	period time.Duration
+	ch     chan time.Time
+}
+
+// NewClock returns clock set to now
+func NewClock(now time.Time) *Clock {
+	c := &Clock{now: now}
+	c.changed = sync.NewCond(&c.mu)
+
+	return c
+}
+
+// Now returns current time of clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance moves clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// Set sets current time of clock
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+	c.fire()
+}
+
+// NewTicker returns ticker firing every d of clock, ticks are dropped for slow receivers like of time.Ticker
+func (c *Clock) NewTicker(d time.Duration) cache.Ticker {
+	if d <= 0 {
+		panic("cachetest: non-positive interval of ticker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &waiter{at: c.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	c.add(w)
+
+	return &ticker{clock: c, waiter: w}
+}
+
+// After returns channel receiving time of clock once clock is advanced by d
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &waiter{at: c.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- c.now
+		return w.ch
+	}
+	c.add(w)
+
+	return w.ch
+}
+
+// BlockUntil waits until n tickers and After channels wait for clock, so code under test
+// has started waiting before test advances clock
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.waiters) < n {
+		c.changed.Wait()
+	}
+}
+
+// add adds waiter, clock must be locked
+func (c *Clock) add(w *waiter) {
+	c.waiters = append(c.waiters, w)
+	c.changed.Broadcast()
+}
+
+// fire sends time to waiters whose time has come, clock must be locked
+func (c *Clock) fire() {
+	waiting := c.waiters[:0]
+	for _, w := range c.waiters {
+		if !w.at.After(c.now) {
+			select {
+			case w.ch <- c.now:
+			default:
+			}
+			if w.period == 0 {
+				continue
+			}
+			for !w.at.After(c.now) {
+				w.at = w.at.Add(w.period)
+			}
+		}
+		waiting = append(waiting, w)
+	}
+	clear(c.waiters[len(waiting):])
+	c.waiters = waiting
+	c.changed.Broadcast()
+}
+
+// ticker is ticker of clock
+type ticker struct {
+	clock  *Clock
+	waiter *waiter
+}
+
+// C returns channel of ticks
+func (t *ticker) C() <-chan time.Time {
+	return t.waiter.ch
+}
+
+// Stop stops ticker
+func (t *ticker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, w := range t.clock.waiters {
+		if w == t.waiter {
+			t.clock.waiters = append(t.clock.waiters[:i], t.clock.waiters[i+1:]...)
+			break
+		}
+	}
+	t.clock.changed.Broadcast()
+}
@@ -0,0 +1,209 @@
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// Factory returns new empty cacher under test, cacher is closed when test finishes
+type Factory func(tb testing.TB) cache.Cacher
+
+// conformance holds conformance suite options
+type conformance struct {
+	sleep func(time.Duration)
+	ttl   time.Duration
+}
+
+// ConformanceOption provides conformance suite options
+type ConformanceOption func(*conformance)
+
+// WithSleep returns option to set function waiting for values to expire, default is time.Sleep,
+// e.g. Clock.Advance of fake cacher
+func WithSleep(sleep func(time.Duration)) ConformanceOption {
+	return func(c *conformance) {
+		c.sleep = sleep
+	}
+}
+
+// WithExpiryTTL returns option to set ttl of values of expiration test, default is 50ms
+func WithExpiryTTL(ttl time.Duration) ConformanceOption {
+	return func(c *conformance) {
+		c.ttl = ttl
+	}
+}
+
+// Conformance runs suite of behaviors every cacher must have against cachers returned by factory
+//
+// values are strings so backends returning stored values and backends returning strings behave the same
+func Conformance(t *testing.T, factory Factory, options ...ConformanceOption) {
+	suite := &conformance{sleep: time.Sleep, ttl: 50 * time.Millisecond}
+	for _, option := range options {
+		option(suite)
+	}
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, c cache.Cacher)
+	}{
+		{name: "get missing", run: conformGetMissing},
+		{name: "set get", run: conformSetGet},
+		{name: "overwrite", run: conformOverwrite},
+		{name: "delete", run: conformDelete},
+		{name: "delete missing", run: conformDeleteMissing},
+		{name: "load", run: conformLoad},
+		{name: "multi", run: conformMulti},
+		{name: "expiration", run: suite.conformExpiration},
+		{name: "concurrent", run: conformConcurrent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := factory(t)
+			t.Cleanup(func() { _ = c.Close() })
+
+			tt.run(t, c)
+		})
+	}
+}
+
+// assertGet fails test if value of key is not want
+func assertGet(t testing.TB, c cache.Cacher, key string, want any) {
+	t.Helper()
+
+	got, err := c.Get(context.Background(), key)
+	if err != nil {
+		t.Errorf("Get(%q) error = %v", key, err)
+		return
+	}
+	if got != want {
+		t.Errorf("Get(%q) = %#v, want %#v", key, got, want)
+	}
+}
+
+// mustSet fails test if key-value can not be set
+func mustSet(t testing.TB, c cache.Cacher, key string, value any, options ...cache.SetOption) {
+	t.Helper()
+
+	if err := c.Set(context.Background(), key, value, options...); err != nil {
+		t.Fatalf("Set(%q) error = %v", key, err)
+	}
+}
+
+func conformGetMissing(t *testing.T, c cache.Cacher) {
+	assertGet(t, c, "missing", nil)
+}
+
+func conformSetGet(t *testing.T, c cache.Cacher) {
+	mustSet(t, c, "key", "value")
+	assertGet(t, c, "key", "value")
+}
+
+func conformOverwrite(t *testing.T, c cache.Cacher) {
+	mustSet(t, c, "key", "value")
+	mustSet(t, c, "key", "other")
+	assertGet(t, c, "key", "other")
+}
+
+func conformDelete(t *testing.T, c cache.Cacher) {
+	mustSet(t, c, "key", "value")
+	mustSet(t, c, "kept", "value")
+	if err := c.Delete(context.Background(), "key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	assertGet(t, c, "key", nil)
+	assertGet(t, c, "kept", "value")
+}
+
+func conformDeleteMissing(t *testing.T, c cache.Cacher) {
+	if err := c.Delete(context.Background(), "missing"); err != nil {
+		t.Errorf("Delete() error = %v, want nil", err)
+	}
+}
+
+func conformLoad(t *testing.T, c cache.Cacher) {
+	data := map[string]any{"a": "one", "b": "two", "c": "three"}
+	if err := c.Load(context.Background(), data); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for key, value := range data {
+		assertGet(t, c, key, value)
+	}
+}
+
+// conformMulti runs through cache.GetMany, cache.Exists and cache.DeleteMany, so cachers implementing
+// cache.MultiCacher or cache.Exister behave as cachers called once per key
+func conformMulti(t *testing.T, c cache.Cacher) {
+	ctx := context.Background()
+	mustSet(t, c, "a", "one")
+	mustSet(t, c, "b", "two")
+	mustSet(t, c, "c", "three")
+
+	values, err := cache.GetMany(ctx, c, []string{"a", "b", "missing"})
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	if len(values) != 2 || values["a"] != "one" || values["b"] != "two" {
+		t.Errorf("GetMany() = %#v, want a and b", values)
+	}
+
+	if count, err := cache.Exists(ctx, c, "a", "b", "a", "missing"); err != nil || count != 3 {
+		t.Errorf("Exists() = %v, %v, want %v", count, err, 3)
+	}
+
+	if err := cache.DeleteMany(ctx, c, "a", "b", "missing"); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	assertGet(t, c, "a", nil)
+	assertGet(t, c, "b", nil)
+	assertGet(t, c, "c", "three")
+}
+
+func (s *conformance) conformExpiration(t *testing.T, c cache.Cacher) {
+	mustSet(t, c, "short", "value", cache.WithTTL(s.ttl))
+	mustSet(t, c, "long", "value", cache.WithTTL(time.Hour))
+	assertGet(t, c, "short", "value")
+
+	s.sleep(2 * s.ttl)
+	assertGet(t, c, "short", nil)
+	assertGet(t, c, "long", "value")
+
+	// expired keys do not exist
+	if count, err := cache.Exists(context.Background(), c, "short", "long"); err != nil || count != 1 {
+		t.Errorf("Exists() = %v, %v, want %v", count, err, 1)
+	}
+}
+
+func conformConcurrent(t *testing.T, c cache.Cacher) {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				key := fmt.Sprintf("key-%d", j%10)
+				if err := c.Set(ctx, key, fmt.Sprintf("value-%d", i)); err != nil {
+					t.Errorf("Set() error = %v", err)
+					return
+				}
+				if _, err := c.Get(ctx, key); err != nil {
+					t.Errorf("Get() error = %v", err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for j := 0; j < 10; j++ {
+		value, err := c.Get(ctx, fmt.Sprintf("key-%d", j))
+		if err != nil || value == nil {
+			t.Errorf("Get() = %v, %v, want value", value, err)
+		}
+	}
+}
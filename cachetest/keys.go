@@ -0,0 +1,14 @@
+package cachetest
+
+import "sort"
+
+// sortedKeys returns keys of map in ascending order
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,106 @@
+package cachetest
+
+import (
+	"context"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// Persister is in-memory fake persister which records calls
+type Persister struct {
+	recorder
+	data   map[string]any
+	closed bool
+}
+
+// NewPersister returns fake persister holding data
+func NewPersister(data map[string]any) *Persister {
+	p := &Persister{data: make(map[string]any, len(data))}
+	for key, value := range data {
+		p.data[key] = value
+	}
+
+	return p
+}
+
+// Save stores key value
+func (p *Persister) Save(ctx context.Context, key string, value any) error {
+	err := p.begin(ctx, cache.OpSave, key)
+	defer p.mu.Unlock()
+
+	p.record(Call{Op: cache.OpSave, Key: key, Value: value, Time: time.Now(), Err: err})
+	if err != nil {
+		return err
+	}
+
+	p.data[key] = value
+
+	return nil
+}
+
+// SelectOne retrieves value by key, nil is returned if key is not found
+func (p *Persister) SelectOne(ctx context.Context, key string) (any, error) {
+	err := p.begin(ctx, cache.OpSelectOne, key)
+	defer p.mu.Unlock()
+
+	p.record(Call{Op: cache.OpSelectOne, Key: key, Value: p.data[key], Time: time.Now(), Err: err})
+	if err != nil {
+		return nil, err
+	}
+
+	return p.data[key], nil
+}
+
+// SelectAll retrieves all key-values
+func (p *Persister) SelectAll(ctx context.Context) (map[string]any, error) {
+	err := p.begin(ctx, cache.OpSelectAll, "")
+	defer p.mu.Unlock()
+
+	p.record(Call{Op: cache.OpSelectAll, Time: time.Now(), Err: err})
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any, len(p.data))
+	for key, value := range p.data {
+		data[key] = value
+	}
+
+	return data, nil
+}
+
+// Delete deletes value by key
+func (p *Persister) Delete(ctx context.Context, key string) error {
+	err := p.begin(ctx, cache.OpDelete, key)
+	defer p.mu.Unlock()
+
+	p.record(Call{Op: cache.OpDelete, Key: key, Time: time.Now(), Err: err})
+	if err != nil {
+		return err
+	}
+
+	delete(p.data, key)
+
+	return nil
+}
+
+// Close closes persister
+func (p *Persister) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+
+	return nil
+}
+
+// Value returns persisted value of key
+func (p *Persister) Value(key string) (any, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	value, ok := p.data[key]
+
+	return value, ok
+}
@@ -0,0 +1,219 @@
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// Call is a recorded operation
+type Call struct {
+	Op    cache.Operation
+	Key   string
+	Value any
+	TTL   time.Duration
+	Time  time.Time
+	Err   error
+}
+
+// Expectation is call expected by fake, see Expect
+type Expectation struct {
+	op  cache.Operation
+	key string
+	err error
+	met bool
+}
+
+// ReturnError makes call meeting expectation fail with err, it must be set before the call is made
+func (e *Expectation) ReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// String returns operation and key of expectation
+func (e *Expectation) String() string {
+	if e.key == "" {
+		return string(e.op)
+	}
+
+	return fmt.Sprintf("%s of key %q", e.op, e.key)
+}
+
+// recorder records calls, injects failures and latencies and checks expectations
+type recorder struct {
+	mu           sync.Mutex
+	calls        []Call
+	failures     map[cache.Operation][]error
+	latencies    map[cache.Operation]time.Duration
+	expectations []*Expectation
+	expected     int
+}
+
+// begin waits latency of op and locks recorder, caller must unlock it, returned error is error call fails with,
+// error of context done while waiting, error of expectation met by call or injected error
+func (r *recorder) begin(ctx context.Context, op cache.Operation, key string) error {
+	r.mu.Lock()
+	latency := r.latencies[op]
+	r.mu.Unlock()
+
+	var err error
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			err = ctx.Err()
+		}
+	}
+
+	r.mu.Lock()
+	if err != nil {
+		return err
+	}
+	if err := r.expect(op, key); err != nil {
+		return err
+	}
+
+	return r.failure(op)
+}
+
+// expect marks next expectation met if call matches it and returns its error, must be called with lock held
+func (r *recorder) expect(op cache.Operation, key string) error {
+	if r.expected >= len(r.expectations) {
+		return nil
+	}
+
+	e := r.expectations[r.expected]
+	if e.op != op || (e.key != "" && e.key != key) {
+		return nil
+	}
+	e.met = true
+	r.expected++
+
+	return e.err
+}
+
+// record appends call, must be called with lock held
+func (r *recorder) record(call Call) {
+	r.calls = append(r.calls, call)
+}
+
+// failure returns injected error of operation, must be called with lock held
+func (r *recorder) failure(op cache.Operation) error {
+	errs := r.failures[op]
+	if len(errs) == 0 {
+		return nil
+	}
+
+	err := errs[0]
+	if len(errs) > 1 {
+		// last error is kept so it is returned by all following calls
+		r.failures[op] = errs[1:]
+	}
+
+	return err
+}
+
+// Fail makes following calls of op return errs in order
+// the last error is returned by all calls after it, nil error lets call succeed
+func (r *recorder) Fail(op cache.Operation, errs ...error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures == nil {
+		r.failures = make(map[cache.Operation][]error)
+	}
+
+	if len(errs) == 0 {
+		delete(r.failures, op)
+		return
+	}
+
+	r.failures[op] = errs
+}
+
+// Delay makes following calls of op wait latency before they run, calls whose context is done
+// while waiting fail with its error, zero latency removes delay
+func (r *recorder) Delay(op cache.Operation, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.latencies == nil {
+		r.latencies = make(map[cache.Operation]time.Duration)
+	}
+
+	if latency <= 0 {
+		delete(r.latencies, op)
+		return
+	}
+
+	r.latencies[op] = latency
+}
+
+// Expect adds expectation that op is called on key, empty key matches any key,
+// expectations are met by calls made in order they are expected, other calls may be made between them
+func (r *recorder) Expect(op cache.Operation, key string) *Expectation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e := &Expectation{op: op, key: key}
+	r.expectations = append(r.expectations, e)
+
+	return e
+}
+
+// ExpectationsWereMet returns error describing first expectation which is not met
+func (r *recorder) ExpectationsWereMet() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, e := range r.expectations {
+		if !e.met {
+			return fmt.Errorf("expected %s was not called", e)
+		}
+	}
+
+	return nil
+}
+
+// Calls returns recorded calls, optionally only of given operations
+func (r *recorder) Calls(ops ...cache.Operation) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := make([]Call, 0, len(r.calls))
+	for _, call := range r.calls {
+		if len(ops) == 0 || contains(ops, call.Op) {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// Reset clears recorded calls, injected failures and latencies and expectations
+func (r *recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+	r.failures = nil
+	r.latencies = nil
+	r.expectations = nil
+	r.expected = 0
+}
+
+// contains reports whether op is in ops
+func contains(ops []cache.Operation, op cache.Operation) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,204 @@
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// Record is a recorded operation of recording cacher
+type Record struct {
+	Op        cache.Operation
+	Key       string
+	ValueHash string
+	TTL       time.Duration
+	Err       error
+}
+
+// String returns record as single golden line
+func (r Record) String() string {
+	line := fmt.Sprintf("%s %s", r.Op, r.Key)
+	if r.ValueHash != "" {
+		line += " value=" + r.ValueHash
+	}
+	if r.TTL != 0 {
+		line += " ttl=" + r.TTL.String()
+	}
+	if r.Err != nil {
+		line += " err=" + r.Err.Error()
+	}
+
+	return line
+}
+
+// Recording is cacher recording all operations of wrapped cacher
+type Recording struct {
+	cache.Cacher
+	mu      sync.Mutex
+	records []Record
+}
+
+// NewRecording returns cacher recording operations of c
+func NewRecording(c cache.Cacher) *Recording {
+	return &Recording{Cacher: c}
+}
+
+// hashValue returns short stable hash of value
+func hashValue(value any) string {
+	if value == nil {
+		return ""
+	}
+
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%T:%v", value, value)
+
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// add appends record
+func (r *Recording) add(record Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = append(r.records, record)
+}
+
+// Set sets key-value to cache and records it
+func (r *Recording) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	setConfig := &cache.SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+
+	err := r.Cacher.Set(ctx, key, value, options...)
+	r.add(Record{Op: cache.OpSet, Key: key, ValueHash: hashValue(value), TTL: setConfig.TTL, Err: err})
+
+	return err
+}
+
+// Get gets value from cache and records it
+func (r *Recording) Get(ctx context.Context, key string) (any, error) {
+	value, err := r.Cacher.Get(ctx, key)
+	r.add(Record{Op: cache.OpGet, Key: key, ValueHash: hashValue(value), Err: err})
+
+	return value, err
+}
+
+// Delete deletes value from cache and records it
+func (r *Recording) Delete(ctx context.Context, key string) error {
+	err := r.Cacher.Delete(ctx, key)
+	r.add(Record{Op: cache.OpDelete, Key: key, Err: err})
+
+	return err
+}
+
+// Load loads key-values into cache and records a set of every key
+func (r *Recording) Load(ctx context.Context, data map[string]any) error {
+	err := r.Cacher.Load(ctx, data)
+	for _, key := range sortedKeys(data) {
+		r.add(Record{Op: cache.OpLoad, Key: key, ValueHash: hashValue(data[key]), Err: err})
+	}
+
+	return err
+}
+
+// Records returns recorded operations, optionally only of given operations
+func (r *Recording) Records(ops ...cache.Operation) []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	records := make([]Record, 0, len(r.records))
+	for _, record := range r.records {
+		if len(ops) == 0 || contains(ops, record.Op) {
+			records = append(records, record)
+		}
+	}
+
+	return records
+}
+
+// Golden returns recorded operations one per line
+func (r *Recording) Golden() string {
+	var b strings.Builder
+	for _, record := range r.Records() {
+		b.WriteString(record.String())
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
+
+// Reset clears recorded operations
+func (r *Recording) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records = nil
+}
+
+// find returns records of op on key
+func (r *Recording) find(op cache.Operation, key string) []Record {
+	var found []Record
+	for _, record := range r.Records(op) {
+		if record.Key == key {
+			found = append(found, record)
+		}
+	}
+
+	return found
+}
+
+// AssertSet fails test if key was not set
+func (r *Recording) AssertSet(t testing.TB, key string) {
+	t.Helper()
+
+	if len(r.find(cache.OpSet, key)) == 0 {
+		t.Errorf("key %q was not set", key)
+	}
+}
+
+// AssertSetWith fails test if key was not set with value and ttl
+func (r *Recording) AssertSetWith(t testing.TB, key string, value any, ttl time.Duration) {
+	t.Helper()
+
+	for _, record := range r.find(cache.OpSet, key) {
+		if record.ValueHash == hashValue(value) && record.TTL == ttl {
+			return
+		}
+	}
+
+	t.Errorf("key %q was not set with %v and ttl %v", key, value, ttl)
+}
+
+// AssertNotSet fails test if key was set
+func (r *Recording) AssertNotSet(t testing.TB, key string) {
+	t.Helper()
+
+	if len(r.find(cache.OpSet, key)) > 0 {
+		t.Errorf("key %q was set", key)
+	}
+}
+
+// AssertDeleted fails test if key was not deleted
+func (r *Recording) AssertDeleted(t testing.TB, key string) {
+	t.Helper()
+
+	if len(r.find(cache.OpDelete, key)) == 0 {
+		t.Errorf("key %q was not deleted", key)
+	}
+}
+
+// AssertGolden fails test if recorded operations differ from golden
+func (r *Recording) AssertGolden(t testing.TB, golden string) {
+	t.Helper()
+
+	if got := r.Golden(); got != golden {
+		t.Errorf("recorded operations:\n%s\nwant:\n%s", got, golden)
+	}
+}
@@ -0,0 +1,34 @@
+package cachetest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+func TestRecording(t *testing.T) {
+	r := NewRecording(NewCacher())
+	ctx := context.Background()
+
+	_ = r.Set(ctx, "key", "value", cache.WithTTL(time.Minute))
+	_, _ = r.Get(ctx, "key")
+	_ = r.Delete(ctx, "key")
+	_ = r.Load(ctx, map[string]any{"b": 2, "a": 1})
+
+	r.AssertSet(t, "key")
+	r.AssertSetWith(t, "key", "value", time.Minute)
+	r.AssertNotSet(t, "other")
+	r.AssertDeleted(t, "key")
+
+	value := hashValue("value")
+	r.AssertGolden(t, fmt.Sprintf("set key value=%s ttl=1m0s\nget key value=%s\ndelete key\nload a value=%s\nload b value=%s\n",
+		value, value, hashValue(1), hashValue(2)))
+
+	r.Reset()
+	if got := len(r.Records()); got != 0 {
+		t.Errorf("Recording.Reset() records = %v, want %v", got, 0)
+	}
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// chain is cacher reading through ordered cachers
+type chain struct {
+	cachers []Cacher
+}
+
+// Chain returns cacher reading through cachers in order, e.g. process memory, node-local redis
+// and regional redis, value found in a later cacher is backfilled to earlier cachers
+// with its remaining ttl if the later cacher implements TTLReader
+// sets and loads are written to all cachers from the last to the first and deletes are
+// applied from the last to the first, so earlier cachers never hold values missing in later ones
+// to read through persistence storage, use the chain as cacher of ReadThrough pattern
+func Chain(cachers ...Cacher) Cacher {
+	return &chain{cachers: cachers}
+}
+
+// Set sets key-value to all cachers
+func (c *chain) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	for i := len(c.cachers) - 1; i >= 0; i-- {
+		if err := c.cachers[i].Set(ctx, key, value, options...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get gets value from the first cacher holding it and backfills earlier cachers,
+// ErrNotFound of cachers reporting misses with it is miss of that cacher, it is returned
+// when no cacher holds the value and any of them reported it
+func (c *chain) Get(ctx context.Context, key string) (any, error) {
+	var notFound bool
+	for i, cacher := range c.cachers {
+		var value any
+		var ttl time.Duration
+		var err error
+		if ttlReader, ok := cacher.(TTLReader); ok && i > 0 {
+			value, ttl, err = ttlReader.GetWithTTL(ctx, key)
+		} else {
+			value, err = cacher.Get(ctx, key)
+		}
+		if errors.Is(err, ErrNotFound) {
+			notFound = true
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			continue
+		}
+
+		var options []SetOption
+		if ttl > 0 {
+			options = append(options, WithTTL(ttl))
+		}
+		for j := i - 1; j >= 0; j-- {
+			// failed backfill only makes next read slower
+			_ = c.cachers[j].Set(ctx, key, value, options...)
+		}
+
+		return value, nil
+	}
+
+	if notFound {
+		return nil, ErrNotFound
+	}
+
+	return nil, nil
+}
+
+// Delete deletes value from all cachers
+func (c *chain) Delete(ctx context.Context, key string) error {
+	var errs []error
+	for i := len(c.cachers) - 1; i >= 0; i-- {
+		if err := c.cachers[i].Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Load loads key-values to all cachers
+func (c *chain) Load(ctx context.Context, data map[string]any) error {
+	for i := len(c.cachers) - 1; i >= 0; i-- {
+		if err := c.cachers[i].Load(ctx, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close closes all cachers
+func (c *chain) Close() error {
+	var errs []error
+	for _, cacher := range c.cachers {
+		if err := cacher.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	l1, l2, l3 := newMapCacher(), newMapCacher(), newMapCacher()
+	l3.data["deep"] = "value"
+	l2.data["middle"] = "value"
+	c := Chain(l1, l2, &scanCacher{l3})
+
+	tests := []struct {
+		name     string
+		key      string
+		want     any
+		wantData [3]any
+	}{
+		{name: "test miss", key: "missing"},
+		{name: "test hit in last backfills all", key: "deep", want: "value", wantData: [3]any{"value", "value", "value"}},
+		{name: "test hit in middle backfills first", key: "middle", want: "value", wantData: [3]any{"value", "value", nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Get(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+			for i, m := range []*mapCacher{l1, l2, l3} {
+				if got := m.data[tt.key]; got != tt.wantData[i] {
+					t.Errorf("Get() cacher %d = %v, want %v", i, got, tt.wantData[i])
+				}
+			}
+		})
+	}
+
+	if err := c.Set(ctx, "new", 1); err != nil || l1.data["new"] != 1 || l3.data["new"] != 1 {
+		t.Errorf("Set() error = %v, data = %v %v", err, l1.data["new"], l3.data["new"])
+	}
+	if err := c.Delete(ctx, "new"); err != nil || l1.data["new"] != nil || l3.data["new"] != nil {
+		t.Errorf("Delete() error = %v, data = %v %v", err, l1.data["new"], l3.data["new"])
+	}
+
+	l2.getErr = errors.New("unavailable")
+	if _, err := c.Get(ctx, "deep2"); err == nil {
+		t.Errorf("Get() error = %v, want error", err)
+	}
+}
+
+func TestChain_NotFound(t *testing.T) {
+	ctx := context.Background()
+	l1, l2 := &notFoundCacher{mapCacher: newMapCacher()}, newMapCacher()
+	l2.data["key"] = "value"
+	c := Chain(l1, l2)
+
+	if got, err := c.Get(ctx, "key"); err != nil || got != "value" {
+		t.Errorf("Get() = %v, %v, want %v", got, err, "value")
+	}
+	if got := l1.data["key"]; got != "value" {
+		t.Errorf("Get() backfilled = %v, want %v", got, "value")
+	}
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, ErrNotFound)
+	}
+}
@@ -0,0 +1,121 @@
+package chaos
+
+import (
+	"context"
+
+	"github.com/albinzx/cache"
+)
+
+// Cacher is cacher injecting faults into operations of wrapped cacher
+type Cacher struct {
+	cache.Cacher
+	injector *injector
+}
+
+// NewCacher returns cacher injecting faults into operations of c
+func NewCacher(c cache.Cacher, options ...Option) *Cacher {
+	return &Cacher{Cacher: c, injector: newInjector(options...)}
+}
+
+// Set sets key-value to cache unless failure is injected
+func (c *Cacher) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	if err := c.injector.inject(ctx, cache.OpSet); err != nil {
+		return err
+	}
+
+	return c.Cacher.Set(ctx, key, value, options...)
+}
+
+// Get gets value from cache unless failure is injected
+func (c *Cacher) Get(ctx context.Context, key string) (any, error) {
+	if err := c.injector.inject(ctx, cache.OpGet); err != nil {
+		return nil, err
+	}
+
+	return c.Cacher.Get(ctx, key)
+}
+
+// Delete deletes value from cache unless failure is injected
+func (c *Cacher) Delete(ctx context.Context, key string) error {
+	if err := c.injector.inject(ctx, cache.OpDelete); err != nil {
+		return err
+	}
+
+	return c.Cacher.Delete(ctx, key)
+}
+
+// Load loads key-values into cache unless failure is injected
+// with partial failure only part of key-values is loaded and error is returned
+func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
+	if err := c.injector.inject(ctx, cache.OpLoad); err != nil {
+		return err
+	}
+
+	kept, dropped := c.injector.partial(cache.OpLoad, data)
+	if err := c.Cacher.Load(ctx, kept); err != nil {
+		return err
+	}
+
+	if dropped {
+		return c.injector.err
+	}
+
+	return nil
+}
+
+// Persister is persister injecting faults into operations of wrapped persister
+type Persister struct {
+	cache.Persister
+	injector *injector
+}
+
+// NewPersister returns persister injecting faults into operations of p
+func NewPersister(p cache.Persister, options ...Option) *Persister {
+	return &Persister{Persister: p, injector: newInjector(options...)}
+}
+
+// Save stores key value unless failure is injected
+func (p *Persister) Save(ctx context.Context, key string, value any) error {
+	if err := p.injector.inject(ctx, cache.OpSave); err != nil {
+		return err
+	}
+
+	return p.Persister.Save(ctx, key, value)
+}
+
+// SelectOne retrieves value by key unless failure is injected
+func (p *Persister) SelectOne(ctx context.Context, key string) (any, error) {
+	if err := p.injector.inject(ctx, cache.OpSelectOne); err != nil {
+		return nil, err
+	}
+
+	return p.Persister.SelectOne(ctx, key)
+}
+
+// SelectAll retrieves all key-values unless failure is injected
+// with partial failure only part of key-values is returned together with error
+func (p *Persister) SelectAll(ctx context.Context) (map[string]any, error) {
+	if err := p.injector.inject(ctx, cache.OpSelectAll); err != nil {
+		return nil, err
+	}
+
+	data, err := p.Persister.SelectAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if kept, dropped := p.injector.partial(cache.OpSelectAll, data); dropped {
+		return kept, p.injector.err
+	}
+
+	return data, nil
+}
+
+// Delete deletes value by key unless failure is injected
+func (p *Persister) Delete(ctx context.Context, key string) error {
+	if err := p.injector.inject(ctx, cache.OpDelete); err != nil {
+		return err
+	}
+
+	return p.Persister.Delete(ctx, key)
+}
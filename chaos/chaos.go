@@ -0,0 +1,147 @@
+// Package chaos provides fault injecting cachers and persisters for resilience testing
+package chaos
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+var (
+	// ErrInjected is default error returned by injected failures
+	ErrInjected = errors.New("chaos: injected failure")
+)
+
+// injector injects latency and failures
+type injector struct {
+	mu          sync.Mutex
+	random      *rand.Rand
+	minLatency  time.Duration
+	maxLatency  time.Duration
+	errorRate   float64
+	partialRate float64
+	err         error
+	operations  map[cache.Operation]bool
+}
+
+// Option provides fault injection options
+type Option func(*injector)
+
+// newInjector returns injector with options
+func newInjector(options ...Option) *injector {
+	i := &injector{
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+		err:    ErrInjected,
+	}
+
+	for _, option := range options {
+		option(i)
+	}
+
+	return i
+}
+
+// WithLatency returns option to delay operations by random duration between min and max
+func WithLatency(min, max time.Duration) Option {
+	return func(i *injector) {
+		i.minLatency = min
+		i.maxLatency = max
+	}
+}
+
+// WithErrorRate returns option to fail given fraction of operations, between 0 and 1
+func WithErrorRate(rate float64) Option {
+	return func(i *injector) {
+		i.errorRate = rate
+	}
+}
+
+// WithPartialFailureRate returns option to drop given fraction of entries of
+// bulk operations, e.g. Load, while reporting failure of the operation
+func WithPartialFailureRate(rate float64) Option {
+	return func(i *injector) {
+		i.partialRate = rate
+	}
+}
+
+// WithError returns option to set error returned by injected failures
+func WithError(err error) Option {
+	return func(i *injector) {
+		i.err = err
+	}
+}
+
+// WithOperations returns option to inject faults only into given operations
+// by default faults are injected into all operations
+func WithOperations(operations ...cache.Operation) Option {
+	return func(i *injector) {
+		i.operations = make(map[cache.Operation]bool, len(operations))
+		for _, op := range operations {
+			i.operations[op] = true
+		}
+	}
+}
+
+// WithSeed returns option to seed random source, so injected faults are reproducible
+func WithSeed(seed int64) Option {
+	return func(i *injector) {
+		i.random = rand.New(rand.NewSource(seed))
+	}
+}
+
+// float returns random number in [0, 1)
+func (i *injector) float() float64 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.random.Float64()
+}
+
+// inject delays operation and returns injected error, if any
+func (i *injector) inject(ctx context.Context, op cache.Operation) error {
+	if i.operations != nil && !i.operations[op] {
+		return nil
+	}
+
+	if i.maxLatency > 0 {
+		latency := i.minLatency
+		if spread := i.maxLatency - i.minLatency; spread > 0 {
+			latency += time.Duration(i.float() * float64(spread))
+		}
+
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if i.errorRate > 0 && i.float() < i.errorRate {
+		return i.err
+	}
+
+	return nil
+}
+
+// partial returns subset of data kept by partial failure and whether entries were dropped
+func (i *injector) partial(op cache.Operation, data map[string]any) (map[string]any, bool) {
+	if i.partialRate <= 0 || (i.operations != nil && !i.operations[op]) {
+		return data, false
+	}
+
+	kept := make(map[string]any, len(data))
+	for key, value := range data {
+		if i.float() >= i.partialRate {
+			kept[key] = value
+		}
+	}
+
+	return kept, len(kept) < len(data)
+}
@@ -0,0 +1,75 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/memory"
+)
+
+func TestCacher(t *testing.T) {
+	errDown := errors.New("down")
+	tests := []struct {
+		name    string
+		options []Option
+		wantErr error
+	}{
+		{name: "test no faults", options: nil, wantErr: nil},
+		{name: "test always failing", options: []Option{WithErrorRate(1), WithError(errDown)}, wantErr: errDown},
+		{name: "test failing other operation", options: []Option{WithErrorRate(1), WithOperations(cache.OpDelete)}, wantErr: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCacher(memory.New(), tt.options...)
+			if err := c.Set(context.Background(), "key", "value"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Cacher.Set() error = %v, want %v", err, tt.wantErr)
+			}
+			if _, err := c.Get(context.Background(), "key"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Cacher.Get() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCacher_Latency(t *testing.T) {
+	c := NewCacher(memory.New(), WithLatency(5*time.Millisecond, 10*time.Millisecond))
+
+	start := time.Now()
+	_ = c.Set(context.Background(), "key", "value")
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Errorf("Cacher.Set() elapsed = %v, want at least %v", elapsed, 5*time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.Get(ctx, "key"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Cacher.Get() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestCacher_PartialLoad(t *testing.T) {
+	m := memory.New()
+	c := NewCacher(m, WithPartialFailureRate(0.5), WithSeed(1))
+
+	data := map[string]any{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		data[key] = key
+	}
+
+	if err := c.Load(context.Background(), data); !errors.Is(err, ErrInjected) {
+		t.Fatalf("Cacher.Load() error = %v, want %v", err, ErrInjected)
+	}
+
+	loaded := 0
+	for key := range data {
+		if value, _ := m.Get(context.Background(), key); value != nil {
+			loaded++
+		}
+	}
+	if loaded == 0 || loaded == len(data) {
+		t.Errorf("Cacher.Load() loaded = %v, want partial load of %v", loaded, len(data))
+	}
+}
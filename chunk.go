@@ -0,0 +1,279 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// chunkManifestPrefix starts manifests of chunked values
+const chunkManifestPrefix = "\x00chunked:"
+
+// ErrChunkMissing is returned when chunk of value is missing, e.g. when it is evicted before manifest
+var ErrChunkMissing = errors.New("chunk of value is missing")
+
+// chunkManifest describes value stored in chunks
+type chunkManifest struct {
+	id     string
+	chunks int
+	size   int64
+}
+
+// String returns manifest stored at key of value
+func (m chunkManifest) String() string {
+	return fmt.Sprintf("%s%s:%d:%d", chunkManifestPrefix, m.id, m.chunks, m.size)
+}
+
+// parseChunkManifest returns manifest of stored value, false is returned if value is not manifest
+func parseChunkManifest(value any) (chunkManifest, bool) {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		return chunkManifest{}, false
+	}
+
+	rest, ok := strings.CutPrefix(text, chunkManifestPrefix)
+	if !ok {
+		return chunkManifest{}, false
+	}
+
+	parts := strings.Split(rest, ":")
+	if len(parts) != 3 {
+		return chunkManifest{}, false
+	}
+	chunks, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return chunkManifest{}, false
+	}
+	size, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return chunkManifest{}, false
+	}
+
+	return chunkManifest{id: parts[0], chunks: chunks, size: size}, true
+}
+
+// ChunkedCacher is cacher storing values larger than chunk size in numbered chunks with manifest at their key,
+// e.g. to stay under value size limits of redis proxies
+//
+// chunks are stored before manifest with the same options, so they expire with it, and are deleted with it
+// by Delete or when value is replaced, values are reassembled as []byte by Get and streamed by GetReader
+type ChunkedCacher struct {
+	Cacher
+	size int
+}
+
+// Chunked returns cacher storing values of c in chunks of size bytes
+func Chunked(c Cacher, size int) *ChunkedCacher {
+	return &ChunkedCacher{Cacher: c, size: size}
+}
+
+// chunkKey returns key of chunk n of value of key
+func chunkKey(key string, manifest chunkManifest, n int) string {
+	return key + ":chunk:" + manifest.id + ":" + strconv.Itoa(n)
+}
+
+// SetReader stores content of r at key, content larger than chunk size is stored in chunks,
+// options apply to manifest and chunks so they must not set conditionally
+func (c *ChunkedCacher) SetReader(ctx context.Context, key string, r io.Reader, options ...SetOption) error {
+	buf := make([]byte, c.size)
+	n, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return c.replace(ctx, key, buf[:n], options)
+	}
+	if err != nil {
+		return err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	manifest := chunkManifest{id: hex.EncodeToString(id)}
+
+	for {
+		if err := c.Cacher.Set(ctx, chunkKey(key, manifest, manifest.chunks), append([]byte(nil), buf[:n]...), options...); err != nil {
+			_ = c.deleteChunks(ctx, key, manifest)
+			return err
+		}
+		manifest.chunks++
+		manifest.size += int64(n)
+
+		n, err = io.ReadFull(r, buf)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			_ = c.deleteChunks(ctx, key, manifest)
+			return err
+		}
+	}
+
+	return c.replace(ctx, key, manifest.String(), options)
+}
+
+// replace stores value at key and deletes chunks of value it replaces
+func (c *ChunkedCacher) replace(ctx context.Context, key string, value any, options []SetOption) error {
+	previous, _ := c.Cacher.Get(ctx, key)
+
+	if err := c.Cacher.Set(ctx, key, value, options...); err != nil {
+		return err
+	}
+
+	if manifest, ok := parseChunkManifest(previous); ok {
+		return c.deleteChunks(ctx, key, manifest)
+	}
+
+	return nil
+}
+
+// Set stores value at key, []byte and string values larger than chunk size are stored in chunks
+func (c *ChunkedCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	switch v := value.(type) {
+	case []byte:
+		return c.SetReader(ctx, key, bytes.NewReader(v), options...)
+	case string:
+		if len(v) > c.size {
+			return c.SetReader(ctx, key, strings.NewReader(v), options...)
+		}
+	}
+
+	return c.replace(ctx, key, value, options)
+}
+
+// GetReader returns reader of value of key, chunks are read as reader is read,
+// ErrNotFound is returned if key does not exist and ErrChunkMissing if chunk is missing when it is read
+func (c *ChunkedCacher) GetReader(ctx context.Context, key string) (io.ReadCloser, error) {
+	value, err := c.Cacher.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, ErrNotFound
+	case []byte:
+		if manifest, ok := parseChunkManifest(v); ok {
+			return &chunkReader{ctx: ctx, c: c.Cacher, key: key, manifest: manifest}, nil
+		}
+		return io.NopCloser(bytes.NewReader(v)), nil
+	case string:
+		if manifest, ok := parseChunkManifest(v); ok {
+			return &chunkReader{ctx: ctx, c: c.Cacher, key: key, manifest: manifest}, nil
+		}
+		return io.NopCloser(strings.NewReader(v)), nil
+	default:
+		return nil, fmt.Errorf("%w: %T can not be read", ErrUnexpectedType, value)
+	}
+}
+
+// Get gets value of key, chunked values are reassembled as []byte
+func (c *ChunkedCacher) Get(ctx context.Context, key string) (any, error) {
+	value, err := c.Cacher.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, ok := parseChunkManifest(value)
+	if !ok {
+		return value, nil
+	}
+
+	reader := &chunkReader{ctx: ctx, c: c.Cacher, key: key, manifest: manifest}
+	buf := bytes.NewBuffer(make([]byte, 0, manifest.size))
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, keyError(key, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Delete deletes value of key and its chunks
+func (c *ChunkedCacher) Delete(ctx context.Context, key string) error {
+	previous, _ := c.Cacher.Get(ctx, key)
+
+	if err := c.Cacher.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	if manifest, ok := parseChunkManifest(previous); ok {
+		return c.deleteChunks(ctx, key, manifest)
+	}
+
+	return nil
+}
+
+// Load loads key-values into cache, []byte and string values larger than chunk size are stored in chunks
+func (c *ChunkedCacher) Load(ctx context.Context, data map[string]any) error {
+	var errs []error
+	for key, value := range data {
+		if err := c.Set(ctx, key, value); err != nil {
+			errs = append(errs, keyError(key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteChunks deletes chunks of manifest of value of key
+func (c *ChunkedCacher) deleteChunks(ctx context.Context, key string, manifest chunkManifest) error {
+	keys := make([]string, manifest.chunks)
+	for n := range keys {
+		keys[n] = chunkKey(key, manifest, n)
+	}
+
+	return DeleteMany(ctx, c.Cacher, keys...)
+}
+
+// chunkReader reads chunks of value one at a time
+type chunkReader struct {
+	ctx      context.Context
+	c        Cacher
+	key      string
+	manifest chunkManifest
+	next     int
+	chunk    []byte
+}
+
+// Read reads value, reading next chunk from cache when current one is read
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.chunk) == 0 {
+		if r.next >= r.manifest.chunks {
+			return 0, io.EOF
+		}
+
+		value, err := r.c.Get(r.ctx, chunkKey(r.key, r.manifest, r.next))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return 0, err
+		}
+		switch v := value.(type) {
+		case []byte:
+			r.chunk = v
+		case string:
+			r.chunk = []byte(v)
+		default:
+			return 0, fmt.Errorf("%w: chunk %d of %s", ErrChunkMissing, r.next, r.key)
+		}
+		r.next++
+	}
+
+	n := copy(p, r.chunk)
+	r.chunk = r.chunk[n:]
+
+	return n, nil
+}
+
+// Close closes reader
+func (r *chunkReader) Close() error {
+	return nil
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestChunked(t *testing.T) {
+	ctx := context.Background()
+	m := newMapCacher()
+	c := Chunked(m, 4)
+
+	if err := c.SetReader(ctx, "large", strings.NewReader("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+	// manifest and three chunks
+	if len(m.data) != 4 {
+		t.Errorf("stored keys = %v, want manifest and 3 chunks", m.data)
+	}
+
+	r, err := c.GetReader(ctx, "large")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(r)
+	if err != nil || string(got) != "0123456789" {
+		t.Errorf("GetReader() read = %q, %v, want %q", got, err, "0123456789")
+	}
+	if value, err := c.Get(ctx, "large"); err != nil || !bytes.Equal(value.([]byte), []byte("0123456789")) {
+		t.Errorf("Get() = %v, %v, want reassembled value", value, err)
+	}
+
+	// replacing value deletes its chunks
+	if err := c.Set(ctx, "large", []byte("small")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "large", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.data) != 1 || m.data["large"] != "abc" {
+		t.Errorf("stored keys = %v, want small value only", m.data)
+	}
+
+	_ = c.Set(ctx, "large", "0123456789")
+	if err := c.Delete(ctx, "large"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.data) != 0 {
+		t.Errorf("stored keys after delete = %v, want none", m.data)
+	}
+	if _, err := c.GetReader(ctx, "large"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetReader() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestChunked_MissingChunk(t *testing.T) {
+	ctx := context.Background()
+	m := newMapCacher()
+	c := Chunked(m, 4)
+
+	_ = c.Set(ctx, "large", "0123456789")
+	for key := range m.data {
+		if strings.HasSuffix(key, ":1") {
+			delete(m.data, key)
+		}
+	}
+
+	if _, err := c.Get(ctx, "large"); !errors.Is(err, ErrChunkMissing) {
+		t.Errorf("Get() error = %v, want %v", err, ErrChunkMissing)
+	}
+}
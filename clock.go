@@ -0,0 +1,111 @@
+package cache
+
+import "time"
+
+// Clock tells current time and times periodic work, expiration is checked against clock and background
+// jobs tick on it, so tests can advance time of fake clock, e.g. cachetest.Clock, instead of sleeping
+type Clock interface {
+	// Now returns current time
+	Now() time.Time
+	// NewTicker returns ticker sending time every d, like time.NewTicker
+	NewTicker(d time.Duration) Ticker
+	// After returns channel receiving time once d elapses, like time.After
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker sends time of ticks on its channel until it is stopped
+type Ticker interface {
+	// C returns channel of ticks
+	C() <-chan time.Time
+	// Stop stops ticker, channel is not closed
+	Stop()
+}
+
+// systemClock is clock of system time
+type systemClock struct{}
+
+// Now returns current system time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker returns ticker of system time
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{ticker: time.NewTicker(d)}
+}
+
+// After returns channel receiving system time after d
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// systemTicker is ticker of system time
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+// C returns channel of ticks
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop stops ticker
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// SystemClock is clock of system time, which is default clock
+var SystemClock Clock = systemClock{}
+
+// WithEnvelopeClock returns option to set clock deciding when values of envelopes are fresh and expire,
+// default is SystemClock
+func WithEnvelopeClock(clock Clock) EnvelopeOption {
+	return func(e *EnvelopeCacher) {
+		e.now = clock.Now
+	}
+}
+
+// WithReadThroughClock returns option to set clock deciding when values are refreshed and served stale,
+// default is SystemClock
+func WithReadThroughClock(clock Clock) ReadThroughOption {
+	return func(r *ReadThrough) {
+		r.now = clock.Now
+	}
+}
+
+// WithBreakerClock returns option to set clock timing open timeout of circuit breaker, default is SystemClock
+func WithBreakerClock(clock Clock) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.now = clock.Now
+	}
+}
+
+// WithRefreshAheadClock returns option to set clock ticking refresh intervals and timing reads of keys,
+// default is SystemClock
+func WithRefreshAheadClock(clock Clock) RefreshAheadOption {
+	return func(r *RefreshAhead) {
+		r.clock = clock
+	}
+}
+
+// WithWriteBehindClock returns option to set clock ticking flush interval, timing backoff of retries
+// and dead letters, default is SystemClock
+func WithWriteBehindClock(clock Clock) WriteBehindOption {
+	return func(w *WriteBehind) {
+		w.clock = clock
+	}
+}
+
+// WithTierClock returns option to set clock ticking demote interval of tiered cacher, default is SystemClock
+func WithTierClock(clock Clock) TierOption {
+	return func(t *TieredCacher) {
+		t.clock = clock
+	}
+}
+
+// WithRateLimitClock returns option to set clock refilling tokens and timing waits for them, default is SystemClock
+func WithRateLimitClock(clock Clock) RateLimitOption {
+	return func(r *rateLimited) {
+		r.bucket.SetClock(clock.Now, clock.After)
+	}
+}
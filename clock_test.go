@@ -0,0 +1,225 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fixedClock is clock which tells time it is set to, it ticks on system time
+type fixedClock struct {
+	systemClock
+	now time.Time
+}
+
+func (f *fixedClock) Now() time.Time {
+	return f.now
+}
+
+// tickingClock is fixed clock whose tickers and After channels receive times sent by test
+type tickingClock struct {
+	fixedClock
+	ticks chan time.Time
+}
+
+func newTickingClock(now time.Time) *tickingClock {
+	return &tickingClock{fixedClock: fixedClock{now: now}, ticks: make(chan time.Time)}
+}
+
+func (c *tickingClock) NewTicker(time.Duration) Ticker {
+	return tickingTicker(c.ticks)
+}
+
+func (c *tickingClock) After(time.Duration) <-chan time.Time {
+	return c.ticks
+}
+
+// tickingTicker is ticker of ticking clock
+type tickingTicker chan time.Time
+
+func (t tickingTicker) C() <-chan time.Time {
+	return t
+}
+
+func (t tickingTicker) Stop() {}
+
+func TestWithBreakerClock(t *testing.T) {
+	ctx := context.Background()
+	clock := &fixedClock{now: time.Now()}
+	primary := &failingCacher{mapCacher: newMapCacher(), err: errors.New("connection refused")}
+	b := NewCircuitBreaker(primary, WithFailureThreshold(1), WithOpenTimeout(time.Minute), WithBreakerClock(clock))
+
+	_, _ = b.Get(ctx, "key")
+	primary.err = nil
+	if _, err := b.Get(ctx, "key"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Get() error = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	if _, err := b.Get(ctx, "key"); err != nil {
+		t.Errorf("Get() after open timeout error = %v", err)
+	}
+	if got := b.State(); got != BreakerClosed {
+		t.Errorf("State() = %v, want %v", got, BreakerClosed)
+	}
+}
+
+func TestWithEnvelopeClock(t *testing.T) {
+	ctx := context.Background()
+	clock := &fixedClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	e := Enveloped(newMapCacher(), WithEnvelopeClock(clock))
+
+	_ = e.Set(ctx, "key", "value", WithTTL(time.Hour), WithSoftTTL(time.Minute))
+
+	envelope, err := GetEnvelope(ctx, e, "key")
+	if err != nil || envelope == nil {
+		t.Fatalf("GetEnvelope() = %v, %v", envelope, err)
+	}
+	if want := clock.now.Add(time.Minute); !envelope.FreshUntil.Equal(want) {
+		t.Errorf("FreshUntil = %v, want %v", envelope.FreshUntil, want)
+	}
+}
+
+func TestWithRefreshAheadClock(t *testing.T) {
+	ctx := context.Background()
+	clock := newTickingClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	persister := newMapPersister()
+	persister.data["key"] = "v1"
+	cacher := newMapCacher()
+
+	pattern := NewRefreshAhead(WithRefreshInterval(time.Hour), WithRefreshAheadClock(clock))
+	defer pattern.Stop()
+	c, _ := New(cacher, persister, WithPattern(pattern))
+
+	refreshed := make(chan Event, 1)
+	c.Subscribe(func(e Event) {
+		if e.Type == EventRefresh {
+			refreshed <- e
+		}
+	})
+
+	_, _ = c.Get(ctx, "key")
+	persister.mu.Lock()
+	persister.data["key"] = "v2"
+	persister.mu.Unlock()
+
+	clock.ticks <- clock.now
+	select {
+	case e := <-refreshed:
+		if !e.Time.Equal(clock.now) {
+			t.Errorf("refresh Time = %v, want %v", e.Time, clock.now)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("key is not refreshed on tick of clock")
+	}
+	if got, _ := cacher.Get(ctx, "key"); got != "v2" {
+		t.Errorf("Get() = %v, want %v", got, "v2")
+	}
+}
+
+func TestWithWriteBehindClock(t *testing.T) {
+	clock := newTickingClock(time.Now())
+	persister := newMapPersister()
+	w := NewWriteBehind(WithFlushInterval(time.Hour), WithWriteBehindClock(clock))
+	defer w.Close(context.Background())
+	c, _ := New(newMapCacher(), persister, WithPattern(w))
+
+	_ = c.Set(context.Background(), "key", "value")
+	clock.ticks <- clock.now
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		persister.mu.Lock()
+		value := persister.data["key"]
+		persister.mu.Unlock()
+		if value == "value" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("write is not persisted on tick of clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWithRateLimitClock(t *testing.T) {
+	ctx := context.Background()
+	clock := newTickingClock(time.Now())
+
+	shed := RateLimited(newMapCacher(), 1, 1, WithShedding(), WithRateLimitClock(clock))
+	_, _ = shed.Get(ctx, "key")
+	if _, err := shed.Get(ctx, "key"); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Get() error = %v, want %v", err, ErrRateLimited)
+	}
+	clock.now = clock.now.Add(time.Second)
+	if _, err := shed.Get(ctx, "key"); err != nil {
+		t.Errorf("Get() after refill error = %v", err)
+	}
+
+	queued := RateLimited(newMapCacher(), 1, 1, WithRateLimitClock(clock))
+	_, _ = queued.Get(ctx, "key")
+	done := make(chan error)
+	go func() {
+		_, err := queued.Get(ctx, "key")
+		done <- err
+	}()
+	clock.ticks <- clock.now
+	if err := <-done; err != nil {
+		t.Errorf("Get() after wait error = %v", err)
+	}
+}
+
+func TestWithWriteBehindClock_Backoff(t *testing.T) {
+	clock := newTickingClock(time.Now())
+	persister := &flakyPersister{mapPersister: *newMapPersister(), failures: 1}
+	w := NewWriteBehind(WithFlushInterval(time.Hour), WithRetries(2, time.Hour), WithWriteBehindClock(clock))
+	defer w.Close(context.Background())
+	c, _ := New(newMapCacher(), persister, WithPattern(w))
+
+	_ = c.Set(context.Background(), "key", "value")
+
+	// ticks flush write and end backoff of its retry
+	deadline := time.After(time.Second)
+	for {
+		persister.mu.Lock()
+		value := persister.data["key"]
+		persister.mu.Unlock()
+		if value == "value" {
+			break
+		}
+		select {
+		case clock.ticks <- clock.now:
+		case <-time.After(time.Millisecond):
+		case <-deadline:
+			t.Fatal("write is not retried on tick of clock")
+		}
+	}
+	if got := w.Stats().Retries; got != 1 {
+		t.Errorf("Stats() Retries = %v, want 1", got)
+	}
+}
+
+func TestWithTierClock(t *testing.T) {
+	ctx := context.Background()
+	clock := newTickingClock(time.Now())
+	cold := newMapCacher()
+	cold.data["key"] = "value"
+	tiered := Tiered(newMapCacher(), cold, WithPromoteThreshold(1), WithDemoteThreshold(100),
+		WithDemoteInterval(time.Hour), WithTierClock(clock))
+	defer tiered.Stop()
+
+	_, _ = tiered.Get(ctx, "key")
+	if got := tiered.Stats().Resident; got != 1 {
+		t.Fatalf("Stats() Resident = %v, want 1", got)
+	}
+
+	clock.ticks <- clock.now
+	deadline := time.Now().Add(time.Second)
+	for tiered.Stats().Demotions != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("key is not demoted on tick of clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/albinzx/cache"
+	_ "github.com/albinzx/cache/memory"
+	_ "github.com/albinzx/cache/redis"
+	_ "github.com/albinzx/cache/tiered"
+)
+
+// config is configuration of caches administered by cachectl, in json or yaml
+//
+//	{
+//	  "caches": {
+//	    "orders": {"type": "redis", "addresses": ["localhost:6379"], "name": "orders", "ttl": "10m"}
+//	  }
+//	}
+type config struct {
+	Caches map[string]cache.BackendConfig `json:"caches" yaml:"caches"`
+}
+
+// loadConfig reads config from file, environment variables in the file are expanded
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+	if err := cache.LoadConfig(path, cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// open connects to cache with name, or of data source name
+func (c *config) open(name string) (cache.Cacher, error) {
+	bc, ok := c.Caches[name]
+	if !ok && strings.Contains(name, "://") {
+		dsn, err := cache.ParseDSN(name)
+		if err != nil {
+			return nil, err
+		}
+		bc, ok = dsn.Backend, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("cache %s is not configured", name)
+	}
+
+	cacher, err := cache.NewBackend(bc)
+	if err != nil {
+		return nil, fmt.Errorf("cache %s: %w", name, err)
+	}
+
+	return cacher, nil
+}
@@ -0,0 +1,302 @@
+// Command cachectl administers caches configured in a config file or given by data source name,
+// e.g. "redis://localhost:6379/0?name=orders&marshaller=json", see cache.ParseDSN
+//
+// usage:
+//
+//	cachectl [-config file] <command> [arguments]
+//
+// commands:
+//
+//	get <cache> <key>                   prints value of key
+//	set [-ttl d] <cache> <key> <value>  stores value of key
+//	del <cache> <key>...                deletes keys
+//	keys <cache> [pattern]              prints keys matching glob pattern
+//	ttl <cache> <key>                   prints remaining time to live of key
+//	stats <cache>                       prints latency and number of keys of cache
+//	warm <cache> <file>                 loads key-values of json object file into cache
+//	dump <cache> <file>                 writes snapshot of keys with their ttl to file
+//	restore <cache> <file>              loads snapshot of file into cache
+//	flush <cache> <prefix>              deletes keys of namespace starting with prefix
+//	migrate <from> <to> [pattern]       copies keys matching pattern with their ttl between caches
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/warmup"
+)
+
+// errUsage is returned when command line is invalid
+var errUsage = errors.New("usage: cachectl [-config file] get|set|del|keys|ttl|stats|warm|dump|restore|flush|migrate [arguments]")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run runs command line args and writes output to w
+func run(ctx context.Context, args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("cachectl", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	path := flags.String("config", "cachectl.json", "config file")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() == 0 {
+		return errUsage
+	}
+
+	cfg, err := loadConfig(*path)
+	if err != nil && !(errors.Is(err, os.ErrNotExist) && !isSet(flags, "config")) {
+		return err
+	}
+	if err != nil {
+		// caches are given by data source names without default config file
+		cfg = &config{}
+	}
+
+	command, args := flags.Arg(0), flags.Args()[1:]
+	switch command {
+	case "get":
+		return withCache(cfg, args, 2, func(c cache.Cacher) error { return get(ctx, c, args[1], w) })
+	case "set":
+		return set(ctx, cfg, args)
+	case "del":
+		return withCache(cfg, args, 2, func(c cache.Cacher) error {
+			for _, key := range args[1:] {
+				if err := c.Delete(ctx, key); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	case "keys":
+		return withCache(cfg, args, 1, func(c cache.Cacher) error { return keys(ctx, c, pattern(args, 1), w) })
+	case "ttl":
+		return withCache(cfg, args, 2, func(c cache.Cacher) error { return ttl(ctx, c, args[1], w) })
+	case "stats":
+		return withCache(cfg, args, 1, func(c cache.Cacher) error { return stats(ctx, c, w) })
+	case "warm":
+		return withCache(cfg, args, 2, func(c cache.Cacher) error { return warm(ctx, c, args[1], w) })
+	case "dump":
+		return withCache(cfg, args, 2, func(c cache.Cacher) error { return dump(ctx, c, args[1], w) })
+	case "restore":
+		return withCache(cfg, args, 2, func(c cache.Cacher) error { return restore(ctx, c, args[1], w) })
+	case "flush":
+		return withCache(cfg, args, 2, func(c cache.Cacher) error { return flush(ctx, c, args[1], w) })
+	case "migrate":
+		return migrate(ctx, cfg, args, w)
+	default:
+		return errUsage
+	}
+}
+
+// isSet reports whether flag of name is set on command line
+func isSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+
+	return set
+}
+
+// withCache opens cache named by first argument and runs fn with it
+// at least n arguments are required
+func withCache(cfg *config, args []string, n int, fn func(cache.Cacher) error) error {
+	if len(args) < n {
+		return errUsage
+	}
+
+	c, err := cfg.open(args[0])
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return fn(c)
+}
+
+// pattern returns argument i as key pattern, all keys are matched if it is missing
+func pattern(args []string, i int) string {
+	if len(args) > i {
+		return args[i]
+	}
+
+	return "*"
+}
+
+// get prints value of key
+func get(ctx context.Context, c cache.Cacher, key string, w io.Writer) error {
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return fmt.Errorf("key %s not found", key)
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		_, err = fmt.Fprintf(w, "%s\n", v)
+	default:
+		_, err = fmt.Fprintf(w, "%v\n", v)
+	}
+
+	return err
+}
+
+// set stores value of key
+func set(ctx context.Context, cfg *config, args []string) error {
+	flags := flag.NewFlagSet("set", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	ttl := flags.Duration("ttl", 0, "time to live")
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+
+	args = flags.Args()
+	return withCache(cfg, args, 3, func(c cache.Cacher) error {
+		var options []cache.SetOption
+		if *ttl > 0 {
+			options = append(options, cache.WithTTL(*ttl))
+		}
+		return c.Set(ctx, args[1], args[2], options...)
+	})
+}
+
+// keys prints keys matching pattern
+func keys(ctx context.Context, c cache.Cacher, pattern string, w io.Writer) error {
+	scanner, ok := c.(cache.Scanner)
+	if !ok {
+		return cache.ErrNotScanner
+	}
+
+	it := scanner.Keys(ctx, pattern)
+	for it.Next(ctx) {
+		if _, err := fmt.Fprintln(w, it.Key()); err != nil {
+			return err
+		}
+	}
+
+	return it.Err()
+}
+
+// ttl prints remaining time to live of key
+func ttl(ctx context.Context, c cache.Cacher, key string, w io.Writer) error {
+	value, remaining, err := cache.GetWithTTL(ctx, c, key)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return fmt.Errorf("key %s not found", key)
+	}
+
+	if remaining <= 0 {
+		_, err = fmt.Fprintln(w, "no ttl")
+		return err
+	}
+	_, err = fmt.Fprintln(w, remaining.Round(time.Millisecond))
+
+	return err
+}
+
+// stats prints ping latency and number of keys of cache
+func stats(ctx context.Context, c cache.Cacher, w io.Writer) error {
+	if pinger, ok := c.(cache.Pinger); ok {
+		start := time.Now()
+		if err := pinger.Ping(ctx); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "latency: %v\n", time.Since(start))
+	}
+
+	if scanner, ok := c.(cache.Scanner); ok {
+		count := 0
+		it := scanner.Keys(ctx, "*")
+		for it.Next(ctx) {
+			count++
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "keys: %d\n", count)
+	}
+
+	return nil
+}
+
+// warm loads key-values of json object file into cache
+func warm(ctx context.Context, c cache.Cacher, path string, w io.Writer) error {
+	report, err := warmup.New(c, warmup.WithSources(warmup.FileSource(path))).Run(ctx)
+	fmt.Fprintf(w, "loaded: %d, failed: %d\n", report.Loaded, report.Failed)
+
+	return err
+}
+
+// dump writes snapshot of keys of cache with their ttl to file
+func dump(ctx context.Context, c cache.Cacher, path string, w io.Writer) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	n, err := cache.Backup(ctx, c, file)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	fmt.Fprintf(w, "dumped: %d\n", n)
+
+	return err
+}
+
+// restore loads snapshot of file into cache
+func restore(ctx context.Context, c cache.Cacher, path string, w io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	n, err := cache.Restore(ctx, c, file)
+	fmt.Fprintf(w, "restored: %d\n", n)
+
+	return err
+}
+
+// flush deletes keys of namespace starting with prefix
+func flush(ctx context.Context, c cache.Cacher, prefix string, w io.Writer) error {
+	n, err := cache.DeleteByPrefix(ctx, c, prefix)
+	fmt.Fprintf(w, "deleted: %d\n", n)
+
+	return err
+}
+
+// migrate copies keys matching pattern with their remaining ttl from one cache to another
+func migrate(ctx context.Context, cfg *config, args []string, w io.Writer) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+
+	return withCache(cfg, args, 1, func(from cache.Cacher) error {
+		return withCache(cfg, args[1:], 1, func(to cache.Cacher) error {
+			progress, err := cache.Copy(ctx, from, to, cache.CopyOptions{Pattern: pattern(args, 2)})
+			fmt.Fprintf(w, "migrated: %d\n", progress.Copied)
+
+			return err
+		})
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/memory"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "cachectl.json")
+	_ = os.WriteFile(configPath, []byte(`{"caches": {"local": {"type": "memory", "ttl": "${CACHECTL_TTL}"}, "bad": {"type": "disk"}}}`), 0o600)
+	dataPath := filepath.Join(dir, "data.json")
+	_ = os.WriteFile(dataPath, []byte(`{"a": "one", "b": "two"}`), 0o600)
+	t.Setenv("CACHECTL_TTL", "1m")
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "test stats", args: []string{"-config", configPath, "stats", "local"}, want: "keys: 0\n"},
+		{name: "test warm", args: []string{"-config", configPath, "warm", "local", dataPath}, want: "loaded: 2, failed: 0\n"},
+		{name: "test migrate", args: []string{"-config", configPath, "migrate", "local", "local"}, want: "migrated: 0\n"},
+		{name: "test set", args: []string{"-config", configPath, "set", "-ttl", "1m", "local", "a", "one"}},
+		{name: "test get missing", args: []string{"-config", configPath, "get", "local", "a"}, wantErr: true},
+		{name: "test unknown cache", args: []string{"-config", configPath, "get", "remote", "a"}, wantErr: true},
+		{name: "test unknown backend", args: []string{"-config", configPath, "stats", "bad"}, wantErr: true},
+		{name: "test missing arguments", args: []string{"-config", configPath, "get", "local"}, wantErr: true},
+		{name: "test unknown command", args: []string{"-config", configPath, "explode"}, wantErr: true},
+		{name: "test missing config", args: []string{"-config", filepath.Join(dir, "missing.json"), "stats", "local"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := run(context.Background(), tt.args, w)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Contains(w.Bytes(), []byte(tt.want)) {
+				t.Errorf("run() output = %q, want %q", w.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	c := memory.New()
+	_ = c.Load(context.Background(), map[string]any{"order:1": "one", "user:1": "two"})
+
+	w := &bytes.Buffer{}
+	if err := keys(context.Background(), c, "order:*", w); err != nil {
+		t.Errorf("keys() error = %v", err)
+	}
+	if got := w.String(); got != "order:1\n" {
+		t.Errorf("keys() = %q, want %q", got, "order:1\n")
+	}
+
+	w.Reset()
+	if err := get(context.Background(), c, "user:1", w); err != nil || w.String() != "two\n" {
+		t.Errorf("get() = %q, %v, want %q", w.String(), err, "two\n")
+	}
+	if err := get(context.Background(), c, "user:2", w); err == nil {
+		t.Errorf("get() error = %v, want error", err)
+	}
+}
+
+func TestRun_Maintenance(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	snapshot := filepath.Join(dir, "snapshot")
+	// without default config file caches are given by data source names
+	wd, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	c := memory.New(memory.WithTTL(time.Minute))
+	_ = c.Load(ctx, map[string]any{"order:1": "one", "order:2": "two", "user:1": "three"})
+
+	w := &bytes.Buffer{}
+	if err := ttl(ctx, c, "order:1", w); err != nil || !strings.HasSuffix(w.String(), "s\n") {
+		t.Errorf("ttl() = %q, %v, want remaining ttl", w.String(), err)
+	}
+
+	w.Reset()
+	if err := dump(ctx, c, snapshot, w); err != nil || w.String() != "dumped: 3\n" {
+		t.Errorf("dump() = %q, %v, want 3 keys", w.String(), err)
+	}
+
+	w.Reset()
+	if err := flush(ctx, c, "order:", w); err != nil || w.String() != "deleted: 2\n" {
+		t.Errorf("flush() = %q, %v, want 2 keys", w.String(), err)
+	}
+
+	w.Reset()
+	if err := restore(ctx, c, snapshot, w); err != nil || w.String() != "restored: 3\n" {
+		t.Errorf("restore() = %q, %v, want 3 keys", w.String(), err)
+	}
+	if value, _ := c.Get(ctx, "order:2"); value != "two" {
+		t.Errorf("Get() after restore = %v, want two", value)
+	}
+
+	w.Reset()
+	if err := run(ctx, []string{"stats", "memory://?ttl=1m"}, w); err != nil || !strings.HasSuffix(w.String(), "keys: 0\n") {
+		t.Errorf("run() = %q, %v, want stats of cache of data source name", w.String(), err)
+	}
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// WithMissBatching returns option to collect keys missed by concurrent reads for window, or until there are
+// max keys, and load them from persistence storage at once with SelectMany, values found are backfilled
+// to cache with one Load, so bursts of misses cost one round trip instead of one per key
+// persistence storage should implement BatchPersister, reads which skip or refresh cache are not batched
+// and batches use cache and persistence storage of read which starts them, so pattern must not be shared by caches
+func WithMissBatching(window time.Duration, maxKeys int) ReadThroughOption {
+	return func(r *ReadThrough) {
+		r.batcher = &missBatcher{window: window, maxKeys: maxKeys}
+	}
+}
+
+// missBatcher collects keys missed by reads into batches loaded at once
+type missBatcher struct {
+	window  time.Duration
+	maxKeys int
+
+	mu      sync.Mutex
+	pending *missBatch
+}
+
+// missBatch is batch of missed keys, its result is available when done is closed
+type missBatch struct {
+	// ctx is context of read which starts batch, without its cancellation
+	ctx  context.Context
+	c    Cacher
+	p    Persister
+	keys []string
+	seen map[string]struct{}
+
+	timer  *time.Timer
+	done   chan struct{}
+	values map[string]any
+	failed map[string]error
+}
+
+// getBatched retrieves value from cache and loads missed value in batch with other misses
+func (r *ReadThrough) getBatched(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
+	value, err := getFound(ctx, c, key)
+	if err != nil {
+		loggerFrom(ctx).Error(ctx, "failed to get value from cache", "get", key, err)
+		if d := scopeFrom(ctx).degraded; d != nil && p != nil {
+			return d.read(ctx, key, c, p, err)
+		}
+	}
+	if isNotFound(value) {
+		return nil, ErrNotFound
+	}
+	if value != nil {
+		return value, nil
+	}
+
+	return r.batcher.get(ctx, key, c, p)
+}
+
+// get adds key to pending batch and waits for its value, batch is loaded when it has max keys or after window
+func (b *missBatcher) get(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
+	b.mu.Lock()
+	batch := b.pending
+	if batch == nil {
+		batch = &missBatch{
+			ctx:  context.WithoutCancel(ctx),
+			c:    c,
+			p:    p,
+			seen: map[string]struct{}{},
+			done: make(chan struct{}),
+		}
+		batch.timer = time.AfterFunc(b.window, func() { b.flush(batch) })
+		b.pending = batch
+	}
+	if _, ok := batch.seen[key]; !ok {
+		batch.seen[key] = struct{}{}
+		batch.keys = append(batch.keys, key)
+	}
+
+	full := b.maxKeys > 0 && len(batch.keys) >= b.maxKeys
+	if full {
+		b.pending = nil
+		batch.timer.Stop()
+	}
+	b.mu.Unlock()
+
+	if full {
+		batch.load()
+	}
+
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if err := batch.failed[key]; err != nil {
+		return nil, err
+	}
+	if batch.values[key] != nil {
+		markLoaded(ctx, key)
+	}
+
+	return batch.values[key], nil
+}
+
+// flush loads batch after window unless it has been loaded when it got full
+func (b *missBatcher) flush(batch *missBatch) {
+	b.mu.Lock()
+	if b.pending != batch {
+		b.mu.Unlock()
+		return
+	}
+	b.pending = nil
+	b.mu.Unlock()
+
+	batch.load()
+}
+
+// load retrieves values of keys of batch from persistence storage and stores them to cache
+func (m *missBatch) load() {
+	defer close(m.done)
+
+	values, err := SelectMany(m.ctx, m.p, m.keys)
+	m.failed = failedKeys(err, m.keys)
+	m.values = make(map[string]any, len(values))
+
+	found := make(map[string]any, len(values))
+	for _, key := range m.keys {
+		if _, ok := m.failed[key]; ok {
+			continue
+		}
+		if value := values[key]; value != nil {
+			m.values[key] = value
+			found[key] = value
+			continue
+		}
+		cacheNotFound(m.ctx, m.c, key)
+	}
+
+	if len(found) == 0 {
+		return
+	}
+	if err := m.c.Load(m.ctx, found); err != nil {
+		loggerFrom(m.ctx).Error(m.ctx, "failed to load values to cache", "load", strings.Join(internal.SortedKeys(found), ","), err)
+	}
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadThrough_MissBatching(t *testing.T) {
+	ctx := context.Background()
+	cacher := newMapCacher()
+	persister := &batchPersister{mapPersister: newMapPersister()}
+	for i := 0; i < 4; i++ {
+		_ = persister.Save(ctx, fmt.Sprint("key", i), i)
+	}
+	c, _ := New(cacher, persister, WithPattern(NewReadThrough(WithMissBatching(time.Minute, 5))))
+
+	// fifth key is missing, batch of five keys is loaded without waiting for window
+	var wg sync.WaitGroup
+	values := make([]any, 5)
+	errs := make([]error, 5)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], errs[i] = c.Get(ctx, fmt.Sprint("key", i))
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range values {
+		want := any(i)
+		if i == 4 {
+			want = nil
+		}
+		if errs[i] != nil || values[i] != want {
+			t.Errorf("Get(key%d) = %v, %v, want %v", i, values[i], errs[i], want)
+		}
+	}
+	if persister.selects != 1 {
+		t.Errorf("SelectMany() calls = %d, want 1", persister.selects)
+	}
+	if len(cacher.data) != 4 {
+		t.Errorf("cached values = %v, want values of four keys", cacher.data)
+	}
+
+	// cached value is not batched
+	if value, err := c.Get(ctx, "key0"); err != nil || value != 0 || persister.selects != 1 {
+		t.Errorf("Get(key0) = %v, %v with %d selects, want cached value", value, err, persister.selects)
+	}
+}
+
+func TestReadThrough_MissBatchingWindow(t *testing.T) {
+	ctx := context.Background()
+	persister := &batchPersister{mapPersister: newMapPersister()}
+	_ = persister.Save(ctx, "key", "value")
+	c, _ := New(newMapCacher(), persister, WithPattern(NewReadThrough(WithMissBatching(time.Millisecond, 100))))
+
+	if value, err := c.Get(ctx, "key"); err != nil || value != "value" {
+		t.Errorf("Get() = %v, %v, want value loaded after window", value, err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.Get(cancelled, "other"); err != context.Canceled {
+		t.Errorf("Get() error = %v, want %v", err, context.Canceled)
+	}
+}
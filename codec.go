@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes values to bytes and decodes bytes into values of target type
+type Codec interface {
+	// Marshal encodes value
+	Marshal(value any) ([]byte, error)
+	// Unmarshal decodes data into target, which is pointer to value of expected type
+	Unmarshal(data []byte, target any) error
+}
+
+// Marshaller encodes values to bytes and decodes bytes to values, it is accepted by backends
+// storing bytes, e.g. redis, it has the method set of marshal.Marshaller of github.com/albinzx/marshal,
+// so its marshallers can be used as is, or Codec can be bound to value type with CodecMarshaller
+type Marshaller interface {
+	Marshal(any) ([]byte, error)
+	Unmarshal([]byte) (any, error)
+}
+
+// JSONCodec is codec of encoding/json
+type JSONCodec struct{}
+
+// Marshal encodes value as json
+func (JSONCodec) Marshal(value any) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal decodes json data into target
+func (JSONCodec) Unmarshal(data []byte, target any) error {
+	return json.Unmarshal(data, target)
+}
+
+// ProtoCodec is codec of protocol buffers, values must be proto messages, e.g. *pb.User
+type ProtoCodec struct{}
+
+// Marshal encodes proto message
+func (ProtoCodec) Marshal(value any) ([]byte, error) {
+	message, ok := value.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not proto message", ErrUnexpectedType, value)
+	}
+
+	return proto.Marshal(message)
+}
+
+// Unmarshal decodes data into target proto message, or pointer to proto message pointer which is allocated
+func (ProtoCodec) Unmarshal(data []byte, target any) error {
+	if message, ok := target.(proto.Message); ok {
+		return proto.Unmarshal(data, message)
+	}
+
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() == reflect.Pointer && pointer.Elem().Kind() == reflect.Pointer {
+		message := reflect.New(pointer.Elem().Type().Elem())
+		if m, ok := message.Interface().(proto.Message); ok {
+			if err := proto.Unmarshal(data, m); err != nil {
+				return err
+			}
+			pointer.Elem().Set(message)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: target %T is not proto message", ErrUnexpectedType, target)
+}
+
+// codecMarshaller is marshaller decoding values of one type with codec
+type codecMarshaller struct {
+	codec Codec
+	typ   reflect.Type
+}
+
+// CodecMarshaller returns marshaller decoding values of type of prototype with codec,
+// e.g. CodecMarshaller(JSONCodec{}, User{}), decoded values have type of prototype,
+// nil prototype decodes into any, e.g. map[string]any of json objects
+func CodecMarshaller(codec Codec, prototype any) Marshaller {
+	typ := reflect.TypeOf(&prototype).Elem()
+	if prototype != nil {
+		typ = reflect.TypeOf(prototype)
+	}
+
+	return &codecMarshaller{codec: codec, typ: typ}
+}
+
+// Marshal encodes value with codec
+func (c *codecMarshaller) Marshal(value any) ([]byte, error) {
+	return c.codec.Marshal(value)
+}
+
+// Unmarshal decodes data into new value of marshaller type
+func (c *codecMarshaller) Unmarshal(data []byte) (any, error) {
+	target := reflect.New(c.typ)
+	if err := c.codec.Unmarshal(data, target.Interface()); err != nil {
+		return nil, err
+	}
+
+	return target.Elem().Interface(), nil
+}
+
+// marshallerCodec is codec of marshaller
+type marshallerCodec struct {
+	marshaller Marshaller
+}
+
+// MarshallerCodec returns codec of marshaller, e.g. of github.com/albinzx/marshal,
+// unmarshalled value must be assignable to target
+func MarshallerCodec(marshaller Marshaller) Codec {
+	return &marshallerCodec{marshaller: marshaller}
+}
+
+// Marshal encodes value with marshaller
+func (m *marshallerCodec) Marshal(value any) ([]byte, error) {
+	return m.marshaller.Marshal(value)
+}
+
+// Unmarshal decodes data with marshaller and assigns it to target
+func (m *marshallerCodec) Unmarshal(data []byte, target any) error {
+	value, err := m.marshaller.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	return assign(target, value)
+}
+
+// assign sets value pointed to by target to value
+func assign(target, value any) error {
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() != reflect.Pointer || pointer.IsNil() {
+		return fmt.Errorf("%w: target %T is not a pointer", ErrUnexpectedType, target)
+	}
+
+	decoded := reflect.ValueOf(value)
+	if !decoded.IsValid() {
+		pointer.Elem().SetZero()
+		return nil
+	}
+	if decoded.Kind() == reflect.Pointer && !decoded.Type().AssignableTo(pointer.Elem().Type()) {
+		decoded = decoded.Elem()
+	}
+	if !decoded.Type().AssignableTo(pointer.Elem().Type()) {
+		return fmt.Errorf("%w: %T, want %s", ErrUnexpectedType, value, pointer.Elem().Type())
+	}
+	pointer.Elem().Set(decoded)
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/albinzx/marshal/json"
+)
+
+func TestCodecMarshaller(t *testing.T) {
+	tests := []struct {
+		name      string
+		prototype any
+		value     any
+		want      any
+	}{
+		{name: "test struct", prototype: typedValue{}, value: typedValue{Number: 1, Text: "one"}, want: typedValue{Number: 1, Text: "one"}},
+		{name: "test pointer", prototype: &typedValue{}, value: &typedValue{Number: 1}, want: &typedValue{Number: 1}},
+		{name: "test any", value: map[string]any{"a": "b"}, want: map[string]any{"a": "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := CodecMarshaller(JSONCodec{}, tt.prototype)
+
+			data, err := m.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			got, err := m.Unmarshal(data)
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarshallerCodec(t *testing.T) {
+	jsonMarshaller, _ := json.New(reflect.TypeOf(typedValue{}))
+	codec := MarshallerCodec(jsonMarshaller)
+
+	data, err := codec.Marshal(typedValue{Number: 1, Text: "one"})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var got typedValue
+	if err := codec.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if want := (typedValue{Number: 1, Text: "one"}); got != want {
+		t.Errorf("Unmarshal() = %v, want %v", got, want)
+	}
+
+	var wrong string
+	if err := codec.Unmarshal(data, &wrong); !errors.Is(err, ErrUnexpectedType) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, ErrUnexpectedType)
+	}
+}
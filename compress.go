@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrUnknownCompression is returned when compressed value uses unregistered compression
+	ErrUnknownCompression = errors.New("unknown compression")
+)
+
+// Compression compresses and decompresses values, gzip and flate are provided,
+// other algorithms, e.g. snappy or zstd, are plugged by implementing it with ids above 15
+type Compression interface {
+	// ID identifies compression in header of compressed values, must be unique
+	ID() byte
+	// Compress compresses data
+	Compress([]byte) ([]byte, error)
+	// Decompress decompresses data
+	Decompress([]byte) ([]byte, error)
+}
+
+// GzipCompression is gzip compression
+type GzipCompression struct {
+	// Level is gzip compression level, zero means default compression
+	Level int
+}
+
+// ID returns gzip compression id
+func (g *GzipCompression) ID() byte {
+	return 1
+}
+
+// Compress compresses data with gzip
+func (g *GzipCompression) Compress(data []byte) ([]byte, error) {
+	level := g.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := gzip.NewWriterLevel(buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses gzip data
+func (g *GzipCompression) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// FlateCompression is deflate compression, smaller header than gzip
+type FlateCompression struct {
+	// Level is flate compression level, zero means default compression
+	Level int
+}
+
+// ID returns flate compression id
+func (f *FlateCompression) ID() byte {
+	return 2
+}
+
+// Compress compresses data with deflate
+func (f *FlateCompression) Compress(data []byte) ([]byte, error) {
+	level := f.Level
+	if level == 0 {
+		level = flate.DefaultCompression
+	}
+
+	buf := &bytes.Buffer{}
+	w, err := flate.NewWriter(buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress decompresses deflate data
+func (f *FlateCompression) Decompress(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// compressedMagic starts header of compressed values
+// header is magic, compression id and kind of original value
+var compressedMagic = []byte{0xc7, 0x5a}
+
+const (
+	// kindBytes marks original value as byte slice
+	kindBytes byte = 'b'
+	// kindString marks original value as string
+	kindString byte = 's'
+)
+
+// compressor compresses values of at least minSize bytes and decompresses values with compression header
+type compressor struct {
+	compression  Compression
+	compressions map[byte]Compression
+	minSize      int
+}
+
+// newCompressor returns compressor with compression and additional decompressors
+func newCompressor(compression Compression, minSize int, decompressors []Compression) compressor {
+	compressions := map[byte]Compression{compression.ID(): compression}
+	for _, d := range decompressors {
+		compressions[d.ID()] = d
+	}
+
+	return compressor{compression: compression, compressions: compressions, minSize: minSize}
+}
+
+// compressed is cacher which compresses values
+type compressed struct {
+	Cacher
+	compressor
+}
+
+// Compressed returns cacher which compresses values of at least minSize bytes with compression
+// only []byte and string values are compressed, other values are stored unchanged
+// so compression is usually combined with backend marshaller
+// values are stored with a small header so uncompressed values written
+// before compression was enabled are still returned unchanged
+// decompressors lists additional compressions which may be found in stored values,
+// e.g. previous compression when switching algorithm
+func Compressed(c Cacher, compression Compression, minSize int, decompressors ...Compression) Cacher {
+	return &compressed{Cacher: c, compressor: newCompressor(compression, minSize, decompressors)}
+}
+
+// compress returns compressed value with header if value is big enough
+func (c *compressor) compress(value any) (any, error) {
+	var data []byte
+	var kind byte
+
+	switch v := value.(type) {
+	case []byte:
+		data, kind = v, kindBytes
+	case string:
+		data, kind = []byte(v), kindString
+	default:
+		return value, nil
+	}
+
+	if len(data) < c.minSize {
+		return value, nil
+	}
+
+	payload, err := c.compression.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(compressedMagic)+2+len(payload))
+	out = append(out, compressedMagic...)
+	out = append(out, c.compression.ID(), kind)
+	out = append(out, payload...)
+
+	return out, nil
+}
+
+// decompress returns original value if value has compression header
+func (c *compressor) decompress(value any) (any, error) {
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return value, nil
+	}
+
+	headerSize := len(compressedMagic) + 2
+	if len(data) < headerSize || !bytes.Equal(data[:len(compressedMagic)], compressedMagic) {
+		return value, nil
+	}
+
+	compression, ok := c.compressions[data[len(compressedMagic)]]
+	if !ok {
+		return nil, ErrUnknownCompression
+	}
+
+	decompressed, err := compression.Decompress(data[headerSize:])
+	if err != nil {
+		return nil, err
+	}
+
+	if data[len(compressedMagic)+1] == kindString {
+		return string(decompressed), nil
+	}
+
+	return decompressed, nil
+}
+
+// Set compresses value and sets it to cache
+func (c *compressed) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	value, err := c.compress(value)
+	if err != nil {
+		return err
+	}
+
+	return c.Cacher.Set(ctx, key, value, options...)
+}
+
+// Get gets value from cache and decompresses it
+func (c *compressed) Get(ctx context.Context, key string) (any, error) {
+	value, err := c.Cacher.Get(ctx, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	return c.decompress(value)
+}
+
+// Load compresses values and loads them into cache
+func (c *compressed) Load(ctx context.Context, data map[string]any) error {
+	values := make(map[string]any, len(data))
+	for key, value := range data {
+		compressed, err := c.compress(value)
+		if err != nil {
+			return err
+		}
+		values[key] = compressed
+	}
+
+	return c.Cacher.Load(ctx, values)
+}
+
+// compressedMarshaller is marshaller which compresses marshalled values
+type compressedMarshaller struct {
+	Marshaller
+	compressor
+}
+
+// CompressedMarshaller returns marshaller which compresses values marshalled by m of at least minSize bytes,
+// e.g. to compress json of redis cacher, values marshalled before compression was enabled are still unmarshalled
+// decompressors lists additional compressions which may be found in stored values
+func CompressedMarshaller(m Marshaller, compression Compression, minSize int, decompressors ...Compression) Marshaller {
+	return &compressedMarshaller{Marshaller: m, compressor: newCompressor(compression, minSize, decompressors)}
+}
+
+// Marshal marshals value and compresses it
+func (m *compressedMarshaller) Marshal(value any) ([]byte, error) {
+	data, err := m.Marshaller.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	compressed, err := m.compress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return compressed.([]byte), nil
+}
+
+// Unmarshal decompresses data and unmarshals it
+func (m *compressedMarshaller) Unmarshal(data []byte) (any, error) {
+	decompressed, err := m.decompress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Marshaller.Unmarshal(decompressed.([]byte))
+}
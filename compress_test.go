@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompressed(t *testing.T) {
+	large := strings.Repeat("value", 100)
+	tests := []struct {
+		name         string
+		compression  Compression
+		value        any
+		wantSmaller  bool
+		wantUnchange bool
+	}{
+		{name: "test gzip string", compression: &GzipCompression{}, value: large, wantSmaller: true},
+		{name: "test flate bytes", compression: &FlateCompression{}, value: []byte(large), wantSmaller: true},
+		{name: "test below min size", compression: &GzipCompression{}, value: "small", wantUnchange: true},
+		{name: "test non byte value", compression: &GzipCompression{}, value: 42, wantUnchange: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMapCacher()
+			c := Compressed(m, tt.compression, 64)
+			if err := c.Set(context.Background(), "key", tt.value); err != nil {
+				t.Fatalf("Compressed().Set() error = %v", err)
+			}
+
+			stored := m.data["key"]
+			if tt.wantUnchange && !reflect.DeepEqual(stored, tt.value) {
+				t.Errorf("Compressed().Set() stored = %v, want %v", stored, tt.value)
+			}
+			if tt.wantSmaller {
+				if b, ok := stored.([]byte); !ok || len(b) >= len(large) {
+					t.Errorf("Compressed().Set() stored %T of size %d, want compressed bytes", stored, len(b))
+				}
+			}
+
+			got, err := c.Get(context.Background(), "key")
+			if err != nil {
+				t.Fatalf("Compressed().Get() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("Compressed().Get() = %v, want %v", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestCompressed_Decompressors(t *testing.T) {
+	m := newMapCacher()
+	value := strings.Repeat("value", 100)
+	_ = Compressed(m, &GzipCompression{}, 0).Set(context.Background(), "key", value)
+
+	if _, err := Compressed(m, &FlateCompression{}, 0).Get(context.Background(), "key"); err != ErrUnknownCompression {
+		t.Errorf("Compressed().Get() error = %v, want %v", err, ErrUnknownCompression)
+	}
+
+	// stored as string as returned by redis without marshaller
+	m.data["key"] = string(m.data["key"].([]byte))
+	got, err := Compressed(m, &FlateCompression{}, 0, &GzipCompression{}).Get(context.Background(), "key")
+	if err != nil || got != value {
+		t.Errorf("Compressed().Get() = %v, %v, want %v", got, err, value)
+	}
+
+	_ = Compressed(m, &FlateCompression{}, 0).Load(context.Background(), map[string]any{"bytes": []byte(value)})
+	if b := m.data["bytes"].([]byte); !bytes.HasPrefix(b, compressedMagic) {
+		t.Errorf("Compressed().Load() stored = %v, want compressed header", b[:4])
+	}
+}
+
+func TestCompressedMarshaller(t *testing.T) {
+	json := CodecMarshaller(JSONCodec{}, "")
+	m := CompressedMarshaller(json, &GzipCompression{}, 64)
+
+	value := strings.Repeat("value", 100)
+	data, err := m.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !bytes.HasPrefix(data, compressedMagic) || len(data) >= len(value) {
+		t.Errorf("Marshal() of size %d, want compressed bytes", len(data))
+	}
+
+	for _, data := range [][]byte{data, []byte(`"plain"`)} {
+		got, err := m.Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		if got != value && got != "plain" {
+			t.Errorf("Unmarshal() = %v", got)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotStored is returned by set with IfNotExists or IfExists when its condition is not met
+	ErrNotStored = errors.New("value is not stored")
+)
+
+// SetMode is condition of set on existence of key
+type SetMode int
+
+const (
+	// SetAlways stores value whether key exists or not
+	SetAlways SetMode = iota
+	// SetIfNotExists stores value only if key does not exist, like SET NX of redis
+	SetIfNotExists
+	// SetIfExists stores value only if key exists, like SET XX of redis
+	SetIfExists
+)
+
+// IfNotExists sets value only if key does not exist, otherwise set returns ErrNotStored
+// condition is honored by memory and redis cachers, other cachers ignore it
+func IfNotExists() SetOption {
+	return func(setConfig *SetConfiguration) {
+		setConfig.Mode = SetIfNotExists
+	}
+}
+
+// IfExists sets value only if key exists, otherwise set returns ErrNotStored
+// condition is honored by memory and redis cachers, other cachers ignore it
+func IfExists() SetOption {
+	return func(setConfig *SetConfiguration) {
+		setConfig.Mode = SetIfExists
+	}
+}
+
+// KeepTTL keeps remaining time to live of existing key instead of setting ttl, like SET KEEPTTL of redis
+// value of key which does not exist is set with ttl by memory cacher, redis sets it without expiration
+func KeepTTL() SetOption {
+	return func(setConfig *SetConfiguration) {
+		setConfig.KeepTTL = true
+	}
+}
+
+// SetIf sets key-value to c with options and reports whether value is stored,
+// false is returned when condition given by IfNotExists or IfExists is not met
+func SetIf(ctx context.Context, c Cache, key string, value any, options ...SetOption) (bool, error) {
+	err := c.Set(ctx, key, value, options...)
+	if errors.Is(err, ErrNotStored) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// SetNX sets key-value to c only if key does not exist and reports whether value is stored,
+// so the first of concurrent writers wins
+func SetNX(ctx context.Context, c Cache, key string, value any, options ...SetOption) (bool, error) {
+	return SetIf(ctx, c, key, value, append(options, IfNotExists())...)
+}
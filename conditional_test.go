@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+// conditionalCacher stores values only if mode of set is met
+type conditionalCacher struct {
+	*mapCacher
+}
+
+func (c conditionalCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	setConfig := &SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+
+	c.mu.Lock()
+	_, exists := c.data[key]
+	c.mu.Unlock()
+	if (setConfig.Mode == SetIfNotExists && exists) || (setConfig.Mode == SetIfExists && !exists) {
+		return ErrNotStored
+	}
+
+	return c.mapCacher.Set(ctx, key, value, options...)
+}
+
+func TestSetIf(t *testing.T) {
+	ctx := context.Background()
+	c := conditionalCacher{newMapCacher()}
+
+	if stored, err := SetIf(ctx, c, "key", 1, IfExists()); stored || err != nil {
+		t.Errorf("SetIf() IfExists = %v, %v, want false", stored, err)
+	}
+	if stored, err := SetNX(ctx, c, "key", 1); !stored || err != nil {
+		t.Errorf("SetNX() = %v, %v, want true", stored, err)
+	}
+	if stored, err := SetNX(ctx, c, "key", 2); stored || err != nil {
+		t.Errorf("SetNX() of existing key = %v, %v, want false", stored, err)
+	}
+	if got, _ := c.Get(ctx, "key"); got != 1 {
+		t.Errorf("Get() = %v, want %v", got, 1)
+	}
+}
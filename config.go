@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration is time.Duration configured as string, e.g. "10m"
+type Duration time.Duration
+
+// UnmarshalText parses duration string
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+
+	return nil
+}
+
+// MarshalText returns duration string
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Config is declarative configuration of patterned cache
+type Config struct {
+	// Backend configures cacher
+	Backend BackendConfig `json:"backend" yaml:"backend"`
+	// Persister configures persister, no persister is used if it is nil
+	Persister *PersisterConfig `json:"persister,omitempty" yaml:"persister,omitempty"`
+	// Pattern is one of cache-aside, read-through, write-through, write-behind and write-around,
+	// default is cache-aside
+	Pattern string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// TTLPolicy is ttl policy in format of ParseTTLPolicy
+	TTLPolicy string `json:"ttl_policy,omitempty" yaml:"ttl_policy,omitempty"`
+}
+
+// BackendConfig is configuration of cacher created by registered backend
+type BackendConfig struct {
+	// Type is name of registered backend, e.g. redis or memory
+	Type string `json:"type" yaml:"type"`
+	// Addresses are addresses of backend servers
+	Addresses []string `json:"addresses,omitempty" yaml:"addresses,omitempty"`
+	Username  string   `json:"username,omitempty" yaml:"username,omitempty"`
+	Password  string   `json:"password,omitempty" yaml:"password,omitempty"`
+	DB        int      `json:"db,omitempty" yaml:"db,omitempty"`
+	// Name is name of cache used as key prefix
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// TTL is default time to live of values
+	TTL Duration `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	// Marshaller is name of registered marshaller
+	Marshaller string `json:"marshaller,omitempty" yaml:"marshaller,omitempty"`
+	// Representation is representation of values without marshaller, one of native, bytes and string
+	Representation string `json:"representation,omitempty" yaml:"representation,omitempty"`
+	// Options are backend specific options
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+	// Tiers configure layered cachers of tiered backend, first tier first
+	Tiers []BackendConfig `json:"tiers,omitempty" yaml:"tiers,omitempty"`
+}
+
+// PersisterConfig is configuration of persister created by registered persister factory
+type PersisterConfig struct {
+	// Type is name of registered persister factory
+	Type string `json:"type" yaml:"type"`
+	// DSN is data source name of persistence storage
+	DSN string `json:"dsn,omitempty" yaml:"dsn,omitempty"`
+	// Options are persister specific options
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// BackendFactory creates cacher of config, marshaller is nil if config has no marshaller
+type BackendFactory func(config BackendConfig, marshaller Marshaller) (Cacher, error)
+
+// PersisterFactory creates persister of config
+type PersisterFactory func(config PersisterConfig) (Persister, error)
+
+// registry holds registered backends, persisters and marshallers
+var registry = struct {
+	mu          sync.RWMutex
+	backends    map[string]BackendFactory
+	persisters  map[string]PersisterFactory
+	marshallers map[string]Marshaller
+}{
+	backends:   map[string]BackendFactory{},
+	persisters: map[string]PersisterFactory{},
+	marshallers: map[string]Marshaller{
+		"json": CodecMarshaller(JSONCodec{}, nil),
+		"gob":  CodecMarshaller(GobCodec{}, nil),
+	},
+}
+
+// RegisterBackend registers backend factory with name, backend packages register themselves on import,
+// e.g. import _ "github.com/albinzx/cache/redis"
+func RegisterBackend(name string, factory BackendFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.backends[name] = factory
+}
+
+// RegisterPersister registers persister factory with name
+func RegisterPersister(name string, factory PersisterFactory) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.persisters[name] = factory
+}
+
+// RegisterMarshaller registers marshaller with name, e.g. json marshaller of a value type,
+// json and gob marshallers decoding into any are registered by default
+func RegisterMarshaller(name string, marshaller Marshaller) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.marshallers[name] = marshaller
+}
+
+// Backends returns sorted names of registered backends
+func Backends() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	names := make([]string, 0, len(registry.backends))
+	for name := range registry.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NewBackend creates cacher of config with registered backend
+func NewBackend(config BackendConfig) (Cacher, error) {
+	registry.mu.RLock()
+	factory, ok := registry.backends[config.Type]
+	marshaller, hasMarshaller := registry.marshallers[config.Marshaller]
+	registry.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q, registered backends are %v", config.Type, Backends())
+	}
+	if config.Marshaller != "" && !hasMarshaller {
+		return nil, fmt.Errorf("unknown marshaller %q", config.Marshaller)
+	}
+
+	return factory(config, marshaller)
+}
+
+// NewPersister creates persister of config with registered persister factory
+func NewPersister(config PersisterConfig) (Persister, error) {
+	registry.mu.RLock()
+	factory, ok := registry.persisters[config.Type]
+	registry.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown persister %q", config.Type)
+	}
+
+	return factory(config)
+}
+
+// patterns are patterns by configured name
+var patterns = map[string]func() Pattern{
+	"cache-aside":   func() Pattern { return &CacheAside{} },
+	"read-through":  func() Pattern { return &ReadThrough{} },
+	"write-through": func() Pattern { return &WriteThrough{} },
+	"write-behind":  func() Pattern { return &WriteBehind{} },
+	"write-around":  func() Pattern { return &WriteAround{} },
+}
+
+// Options returns options of configured pattern and ttl policy
+func (c Config) Options() ([]Option, error) {
+	var options []Option
+
+	if c.Pattern != "" {
+		pattern, ok := patterns[c.Pattern]
+		if !ok {
+			return nil, fmt.Errorf("unknown pattern %q", c.Pattern)
+		}
+		options = append(options, WithPattern(pattern()))
+	}
+
+	if c.TTLPolicy != "" {
+		policy, err := ParseTTLPolicy(c.TTLPolicy)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, WithTTLPolicy(policy))
+	}
+
+	return options, nil
+}
+
+// FromConfig creates patterned cache of config, options are applied after configured options
+func FromConfig(config Config, options ...Option) (*PatternedCache, error) {
+	configured, err := config.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	var persister Persister
+	if config.Persister != nil {
+		if persister, err = NewPersister(*config.Persister); err != nil {
+			return nil, err
+		}
+	}
+
+	cacher, err := NewBackend(config.Backend)
+	if err != nil {
+		if persister != nil {
+			_ = persister.Close()
+		}
+		return nil, err
+	}
+
+	return New(cacher, persister, append(configured, options...)...)
+}
+
+// LoadConfig reads config of yaml file, if its extension is .yaml or .yml, or json file
+// environment variables in the file, e.g. ${REDIS_PASSWORD}, are expanded
+// into is typically *Config or a structure of multiple Config
+func LoadConfig(path string, into any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	expanded := []byte(os.ExpandEnv(string(data)))
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(expanded, into)
+	default:
+		err = json.Unmarshal(expanded, into)
+	}
+	if err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+
+	return nil
+}
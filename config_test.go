@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFromConfig(t *testing.T) {
+	var got BackendConfig
+	RegisterBackend("test-map", func(config BackendConfig, _ Marshaller) (Cacher, error) {
+		got = config
+		return newMapCacher(), nil
+	})
+	RegisterPersister("test-map", func(PersisterConfig) (Persister, error) {
+		return newMapPersister(), nil
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cache.yaml")
+	_ = os.WriteFile(path, []byte(`
+backend:
+  type: test-map
+  addresses: ["${TEST_CACHE_ADDR}"]
+  name: orders
+  ttl: 10m
+persister:
+  type: test-map
+pattern: write-through
+ttl_policy: "order.* = 1h"
+`), 0o600)
+	t.Setenv("TEST_CACHE_ADDR", "localhost:6379")
+
+	config := Config{}
+	if err := LoadConfig(path, &config); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	c, err := FromConfig(config)
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+
+	want := BackendConfig{Type: "test-map", Addresses: []string{"localhost:6379"}, Name: "orders", TTL: Duration(10 * time.Minute)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromConfig() backend config = %v, want %v", got, want)
+	}
+	if _, ok := c.pattern.(*WriteThrough); !ok {
+		t.Errorf("FromConfig() pattern = %T, want %T", c.pattern, &WriteThrough{})
+	}
+	if c.policy == nil || c.persister == nil {
+		t.Errorf("FromConfig() policy = %v, persister = %v", c.policy, c.persister)
+	}
+}
+
+func TestFromConfig_Errors(t *testing.T) {
+	RegisterBackend("test-map", func(BackendConfig, Marshaller) (Cacher, error) {
+		return newMapCacher(), nil
+	})
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "test unknown backend", config: Config{Backend: BackendConfig{Type: "unknown"}}},
+		{name: "test unknown marshaller", config: Config{Backend: BackendConfig{Type: "test-map", Marshaller: "unknown"}}},
+		{name: "test unknown persister", config: Config{Backend: BackendConfig{Type: "test-map"}, Persister: &PersisterConfig{Type: "unknown"}}},
+		{name: "test unknown pattern", config: Config{Backend: BackendConfig{Type: "test-map"}, Pattern: "write-sideways"}},
+		{name: "test invalid policy", config: Config{Backend: BackendConfig{Type: "test-map"}, TTLPolicy: "order.*"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := FromConfig(tt.config); err == nil {
+				t.Errorf("FromConfig() error = %v, want error", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	_ = os.WriteFile(path, []byte(`{"backend": {"type": "memory", "ttl": "1m30s"}}`), 0o600)
+
+	config := Config{}
+	if err := LoadConfig(path, &config); err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := time.Duration(config.Backend.TTL); got != 90*time.Second {
+		t.Errorf("LoadConfig() ttl = %v, want %v", got, 90*time.Second)
+	}
+}
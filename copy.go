@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// CopyOptions controls copying keys between cachers
+type CopyOptions struct {
+	// Pattern is glob pattern of copied keys, empty pattern matches all keys
+	Pattern string
+	// Rate is maximum number of keys copied per second, zero means no limit
+	Rate float64
+	// Rename returns key in destination, e.g. to move keys to another namespace, default keeps key
+	Rename func(key string) string
+	// Progress is called with progress after every ProgressInterval scanned keys and when copy finishes
+	Progress func(CopyProgress)
+	// ProgressInterval is number of scanned keys between progress reports, default is 1000
+	ProgressInterval int
+}
+
+// CopyProgress is progress of copy
+type CopyProgress struct {
+	// Scanned is number of keys iterated in source
+	Scanned int
+	// Copied is number of keys stored to destination
+	Copied int
+	// Skipped is number of keys which expired or disappeared while copying
+	Skipped int
+}
+
+// Copy copies keys of src to dst and returns its progress, e.g. to move live cache between backends
+// src must implement Scanner, remaining ttl of keys is preserved if src implements TTLReader,
+// otherwise keys get default ttl of dst
+func Copy(ctx context.Context, src, dst Cacher, options CopyOptions) (CopyProgress, error) {
+	var progress CopyProgress
+
+	scanner, ok := src.(Scanner)
+	if !ok {
+		return progress, ErrNotScanner
+	}
+	ttlReader, hasTTL := src.(TTLReader)
+
+	var limiter *internal.TokenBucket
+	if options.Rate > 0 {
+		limiter = internal.NewTokenBucket(options.Rate, 1)
+	}
+	interval := options.ProgressInterval
+	if interval < 1 {
+		interval = 1000
+	}
+	report := func() {
+		if options.Progress != nil {
+			options.Progress(progress)
+		}
+	}
+	defer report()
+
+	it := scanner.Keys(ctx, options.Pattern)
+	for it.Next(ctx) {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return progress, err
+			}
+		}
+
+		key := it.Key()
+		progress.Scanned++
+
+		var value any
+		var ttl time.Duration
+		var err error
+		if hasTTL {
+			value, ttl, err = ttlReader.GetWithTTL(ctx, key)
+		} else {
+			value, err = src.Get(ctx, key)
+		}
+		if err != nil {
+			return progress, fmt.Errorf("key %s: %w", key, err)
+		}
+
+		if value == nil {
+			progress.Skipped++
+		} else {
+			var setOptions []SetOption
+			if ttl > 0 {
+				setOptions = append(setOptions, WithTTL(ttl))
+			}
+
+			target := key
+			if options.Rename != nil {
+				target = options.Rename(key)
+			}
+			if err := dst.Set(ctx, target, value, setOptions...); err != nil {
+				return progress, fmt.Errorf("key %s: %w", key, err)
+			}
+			progress.Copied++
+		}
+
+		if progress.Scanned%interval == 0 {
+			report()
+		}
+	}
+
+	return progress, it.Err()
+}
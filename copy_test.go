@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCopy(t *testing.T) {
+	src := newMapCacher()
+	src.data = map[string]any{"a": 1, "b": 2, "c": 3}
+
+	tests := []struct {
+		name        string
+		src         Cacher
+		options     CopyOptions
+		want        CopyProgress
+		wantKey     string
+		wantErr     error
+		wantReports int
+	}{
+		{name: "test copy", src: &scanCacher{src}, want: CopyProgress{Scanned: 3, Copied: 3}, wantKey: "a", wantReports: 1},
+		{name: "test copy renamed", src: &scanCacher{src}, options: CopyOptions{Rename: func(key string) string { return "v2:" + key }, ProgressInterval: 1}, want: CopyProgress{Scanned: 3, Copied: 3}, wantKey: "v2:a", wantReports: 4},
+		{name: "test rate limited", src: &scanCacher{src}, options: CopyOptions{Rate: 1000}, want: CopyProgress{Scanned: 3, Copied: 3}, wantKey: "a", wantReports: 1},
+		{name: "test not scanner", src: src, wantErr: ErrNotScanner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := newMapCacher()
+			reports := 0
+			tt.options.Progress = func(CopyProgress) { reports++ }
+
+			got, err := Copy(context.Background(), tt.src, dst, tt.options)
+			if err != tt.wantErr {
+				t.Fatalf("Copy() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Copy() = %v, want %v", got, tt.want)
+			}
+			if tt.wantKey != "" && dst.data[tt.wantKey] != 1 {
+				t.Errorf("Copy() dst = %v, want key %v", dst.data, tt.wantKey)
+			}
+			if reports != tt.wantReports {
+				t.Errorf("Copy() reports = %v, want %v", reports, tt.wantReports)
+			}
+		})
+	}
+}
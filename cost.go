@@ -0,0 +1,30 @@
+package cache
+
+import "encoding/gob"
+
+// costOf returns serialized length of value, length of []byte and string values
+// and length of gob encoding of other values, 0 if value can not be encoded
+func costOf(value any) int64 {
+	if size, ok := sizeOf(value); ok {
+		return int64(size)
+	}
+	if value == nil {
+		return 0
+	}
+
+	var counter byteCounter
+	if err := gob.NewEncoder(&counter).Encode(value); err != nil {
+		return 0
+	}
+
+	return int64(counter)
+}
+
+// byteCounter is writer counting written bytes
+type byteCounter int64
+
+// Write counts data
+func (c *byteCounter) Write(data []byte) (int, error) {
+	*c += byteCounter(len(data))
+	return len(data), nil
+}
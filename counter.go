@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotCounter is returned when cacher can not increment values
+	ErrNotCounter = errors.New("cacher does not support counters")
+	// ErrNotInteger is returned when value incremented by counter is not an integer
+	ErrNotInteger = errors.New("value is not an integer")
+)
+
+// Counter is implemented by cachers which atomically increment integer values, e.g. memory and redis cachers
+// key which does not exist starts from 0 and expires after ttl of options, ttl of existing key is kept
+type Counter interface {
+	// Increment adds delta to value of key and returns new value
+	Increment(ctx context.Context, key string, delta int64, options ...SetOption) (int64, error)
+	// Decrement subtracts delta from value of key and returns new value
+	Decrement(ctx context.Context, key string, delta int64, options ...SetOption) (int64, error)
+}
+
+// Increment adds delta to value of key in c and returns new value, c must implement Counter
+func Increment(ctx context.Context, c Cacher, key string, delta int64, options ...SetOption) (int64, error) {
+	counter, ok := c.(Counter)
+	if !ok {
+		return 0, ErrNotCounter
+	}
+
+	return counter.Increment(ctx, key, delta, options...)
+}
+
+// Decrement subtracts delta from value of key in c and returns new value, c must implement Counter
+func Decrement(ctx context.Context, c Cacher, key string, delta int64, options ...SetOption) (int64, error) {
+	counter, ok := c.(Counter)
+	if !ok {
+		return 0, ErrNotCounter
+	}
+
+	return counter.Decrement(ctx, key, delta, options...)
+}
+
+// Increment adds delta to value of key in cache and returns new value, counters are not persisted,
+// cacher must implement Counter
+func (c *PatternedCache) Increment(ctx context.Context, key string, delta int64, options ...SetOption) (int64, error) {
+	ctx = withScope(ctx, c.scope)
+
+	value, err := Increment(ctx, c.unwrapped(), key, delta, options...)
+	if err != nil && !errors.Is(err, ErrNotCounter) {
+		loggerFrom(ctx).Error(ctx, "failed to increment counter", "increment", key, err)
+	}
+
+	return value, err
+}
+
+// Decrement subtracts delta from value of key in cache and returns new value, counters are not persisted,
+// cacher must implement Counter
+func (c *PatternedCache) Decrement(ctx context.Context, key string, delta int64, options ...SetOption) (int64, error) {
+	return c.Increment(ctx, key, -delta, options...)
+}
@@ -0,0 +1,18 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestIncrement_NotCounter(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := Increment(ctx, newMapCacher(), "key", 1); !errors.Is(err, ErrNotCounter) {
+		t.Errorf("Increment() error = %v, want %v", err, ErrNotCounter)
+	}
+	if _, err := Decrement(ctx, newMapCacher(), "key", 1); !errors.Is(err, ErrNotCounter) {
+		t.Errorf("Decrement() error = %v, want %v", err, ErrNotCounter)
+	}
+}
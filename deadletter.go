@@ -0,0 +1,184 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// DeadLetter is write to persistence storage which failed after all attempts
+type DeadLetter struct {
+	// Op is OpSave or OpDelete
+	Op    Operation
+	Key   string
+	Value any
+	// Err is error of last attempt
+	Err      error
+	Attempts int
+	Time     time.Time
+}
+
+// DeadLetterSink receives writes which failed after all attempts, e.g. to store them for later replay
+type DeadLetterSink interface {
+	// DeadLetter handles failed write, returned error keeps write pending in write journal if there is one
+	DeadLetter(ctx context.Context, letter DeadLetter) error
+}
+
+// DeadLetterFunc is function handling failed writes
+type DeadLetterFunc func(ctx context.Context, letter DeadLetter) error
+
+// DeadLetter calls f
+func (f DeadLetterFunc) DeadLetter(ctx context.Context, letter DeadLetter) error {
+	return f(ctx, letter)
+}
+
+// FileDeadLetters appends failed writes to file as json lines,
+// values are encoded like backups, []byte and string values as is and other values with gob
+type FileDeadLetters struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// fileDeadLetter is json line of dead letter
+type fileDeadLetter struct {
+	Op       Operation `json:"op"`
+	Key      string    `json:"key"`
+	Kind     string    `json:"kind,omitempty"`
+	Value    []byte    `json:"value,omitempty"`
+	Error    string    `json:"error"`
+	Attempts int       `json:"attempts"`
+	Time     time.Time `json:"time"`
+}
+
+// OpenFileDeadLetters opens file appending dead letters to it, creating it if it does not exist
+func OpenFileDeadLetters(path string) (*FileDeadLetters, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileDeadLetters{file: file}, nil
+}
+
+// DeadLetter appends letter to file and syncs it
+func (f *FileDeadLetters) DeadLetter(_ context.Context, letter DeadLetter) error {
+	line := fileDeadLetter{Op: letter.Op, Key: letter.Key, Attempts: letter.Attempts, Time: letter.Time}
+	if letter.Err != nil {
+		line.Error = letter.Err.Error()
+	}
+	if letter.Op == OpSave {
+		kind, data, err := encodeValue(letter.Value)
+		if err != nil {
+			return err
+		}
+		line.Kind, line.Value = string(kind), data
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	if _, err := f.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	return f.file.Sync()
+}
+
+// Close closes file
+func (f *FileDeadLetters) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
+
+// WriteBehindStats are delivery counters of write-behind pattern
+type WriteBehindStats struct {
+	// Retries is number of retried writes
+	Retries int64
+	// DeadLetters is number of writes given to dead letter sink
+	DeadLetters int64
+	// Dropped is number of failed writes which are not given to dead letter sink,
+	// because there is none or it failed
+	Dropped int64
+	// Coalesced is number of queued writes which are not persisted because later write of the same key replaced them
+	Coalesced int64
+}
+
+// WithRetries returns option to retry failed writes to persistence storage up to attempts times in total,
+// waiting backoff before second attempt and doubling it before each next attempt, default is 1 attempt
+func WithRetries(attempts int, backoff time.Duration) WriteBehindOption {
+	return func(w *WriteBehind) {
+		w.attempts = attempts
+		w.backoff = backoff
+	}
+}
+
+// WithDeadLetterSink returns option to give writes which failed after all attempts to sink,
+// by default failed writes are only reported
+func WithDeadLetterSink(sink DeadLetterSink) WriteBehindOption {
+	return func(w *WriteBehind) {
+		w.deadLetters = sink
+	}
+}
+
+// Stats returns delivery counters
+func (w *WriteBehind) Stats() WriteBehindStats {
+	return WriteBehindStats{Retries: w.retries.Load(), DeadLetters: w.deadLettered.Load(), Dropped: w.dropped.Load(), Coalesced: w.coalesced.Load()}
+}
+
+// deliver calls write until it succeeds, attempts are exhausted or ctx is done while waiting for next attempt
+// and returns number of attempts and last error
+func (w *WriteBehind) deliver(ctx context.Context, write func() error) (int, error) {
+	backoff := w.backoff
+	attempt := 1
+	for {
+		err := write()
+		if err == nil || attempt >= w.attempts {
+			return attempt, err
+		}
+
+		w.retries.Add(1)
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-w.clock.After(backoff):
+		}
+		backoff *= 2
+		attempt++
+	}
+}
+
+// deadLetter gives failed write to dead letter sink and reports whether it is handled
+func (w *WriteBehind) deadLetter(ctx context.Context, letter DeadLetter) bool {
+	if w.deadLetters == nil {
+		w.dropped.Add(1)
+		return true
+	}
+
+	if err := w.deadLetters.DeadLetter(ctx, letter); err != nil {
+		w.dropped.Add(1)
+		reporterFrom(ctx, "dead_letter", letter.Key)(err)
+		return false
+	}
+	w.deadLettered.Add(1)
+	emit(ctx, Event{Type: EventDeadLetter, Key: letter.Key, Time: letter.Time, Err: letter.Err})
+
+	return true
+}
@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// flakyPersister fails first saves and deletes
+type flakyPersister struct {
+	mapPersister
+	failures int
+}
+
+func (p *flakyPersister) fail() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func (p *flakyPersister) Save(ctx context.Context, key string, value any) error {
+	if err := p.fail(); err != nil {
+		return err
+	}
+	return p.mapPersister.Save(ctx, key, value)
+}
+
+func (p *flakyPersister) Delete(ctx context.Context, key string) error {
+	if err := p.fail(); err != nil {
+		return err
+	}
+	return p.mapPersister.Delete(ctx, key)
+}
+
+func TestWriteBehind_DeadLetter(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		sinkErr       error
+		wantPersisted bool
+		wantLetters   int
+		wantStats     WriteBehindStats
+	}{
+		{name: "test retried", failures: 2, wantPersisted: true, wantStats: WriteBehindStats{Retries: 2}},
+		{name: "test dead letter", failures: 3, wantLetters: 1, wantStats: WriteBehindStats{Retries: 2, DeadLetters: 1}},
+		{name: "test sink error", failures: 3, sinkErr: errors.New("full"), wantLetters: 1, wantStats: WriteBehindStats{Retries: 2, Dropped: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var letters []DeadLetter
+			sink := DeadLetterFunc(func(_ context.Context, letter DeadLetter) error {
+				mu.Lock()
+				defer mu.Unlock()
+				letters = append(letters, letter)
+				return tt.sinkErr
+			})
+
+			w := NewWriteBehind(WithRetries(3, 0), WithDeadLetterSink(sink))
+			persister := &flakyPersister{mapPersister: *newMapPersister(), failures: tt.failures}
+			c, _ := New(newMapCacher(), persister, WithPattern(w))
+
+			_ = c.Set(context.Background(), "key", "value")
+			_ = c.Drain(context.Background())
+
+			if _, ok := persister.data["key"]; ok != tt.wantPersisted {
+				t.Errorf("persisted = %v, want %v", ok, tt.wantPersisted)
+			}
+			if len(letters) != tt.wantLetters {
+				t.Fatalf("dead letters = %v, want %v", len(letters), tt.wantLetters)
+			}
+			if tt.wantLetters > 0 {
+				if l := letters[0]; l.Op != OpSave || l.Key != "key" || l.Value != "value" || l.Attempts != 3 || l.Err == nil {
+					t.Errorf("dead letter = %+v", l)
+				}
+			}
+			if got := w.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestFileDeadLetters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.letters")
+
+	sink, err := OpenFileDeadLetters(path)
+	if err != nil {
+		t.Fatalf("OpenFileDeadLetters() error = %v", err)
+	}
+	w := NewWriteBehind(WithDeadLetterSink(sink))
+	persister := &flakyPersister{mapPersister: *newMapPersister(), failures: 2}
+	c, _ := New(newMapCacher(), persister, WithPattern(w))
+
+	_ = c.Set(context.Background(), "a", "one")
+	_ = c.Drain(context.Background())
+	_ = c.Delete(context.Background(), "b")
+	_ = c.Drain(context.Background())
+	_ = sink.Close()
+
+	file, _ := os.Open(path)
+	defer file.Close()
+
+	var lines []fileDeadLetter
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var line fileDeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) != 2 {
+		t.Fatalf("lines = %v, want %v", len(lines), 2)
+	}
+	if l := lines[0]; l.Op != OpSave || l.Key != "a" || string(l.Value) != "one" || l.Error != "unavailable" || l.Attempts != 1 {
+		t.Errorf("save line = %+v", l)
+	}
+	if l := lines[1]; l.Op != OpDelete || l.Key != "b" || l.Value != nil {
+		t.Errorf("delete line = %+v", l)
+	}
+	if got := w.Stats(); got.DeadLetters != 2 {
+		t.Errorf("Stats().DeadLetters = %v, want %v", got.DeadLetters, 2)
+	}
+}
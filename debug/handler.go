@@ -0,0 +1,220 @@
+// Package debug provides http handler exposing cache internals for debugging
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/albinzx/cache"
+)
+
+// topKeys is number of hot keys shown
+const topKeys = 20
+
+// Entry is a cache registered to debug handler
+type Entry struct {
+	// Cacher is used for ad-hoc get and delete of keys, may be nil
+	Cacher cache.Cacher
+	// Stats is shown as cache stats, may be nil
+	Stats *cache.Stats
+	// Config is shown as cache configuration, may be nil
+	Config any
+	// HotKeys is shown as most accessed keys, may be nil
+	HotKeys *HotKeys
+}
+
+// Handler is http handler exposing registered caches
+//
+// handler serves following paths relative to its mount point,
+// which must be stripped with http.StripPrefix
+//
+//	GET    /                  names and stats of all caches
+//	GET    /{name}            stats, config and hot keys of cache
+//	GET    /{name}/keys/{key} value of key, requires authorization
+//	DELETE /{name}/keys/{key} deletes key, requires authorization
+type Handler struct {
+	mu        sync.RWMutex
+	entries   map[string]Entry
+	authorize func(*http.Request) bool
+}
+
+// Option provides debug handler options
+type Option func(*Handler)
+
+// WithAuthorizer returns option to authorize ad-hoc access to keys
+// without authorizer, access to keys is always denied
+func WithAuthorizer(authorize func(*http.Request) bool) Option {
+	return func(h *Handler) {
+		h.authorize = authorize
+	}
+}
+
+// NewHandler returns debug handler
+func NewHandler(options ...Option) *Handler {
+	h := &Handler{entries: make(map[string]Entry)}
+
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// Register registers cache with name, registering existing name replaces it
+func (h *Handler) Register(name string, entry Entry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries[name] = entry
+}
+
+// Unregister removes cache with name
+func (h *Handler) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.entries, name)
+}
+
+// entry returns registered cache by name
+func (h *Handler) entry(name string) (Entry, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	entry, ok := h.entries[name]
+
+	return entry, ok
+}
+
+// cacheInfo is debug information of cache
+type cacheInfo struct {
+	Name    string               `json:"name"`
+	Stats   *cache.StatsSnapshot `json:"stats,omitempty"`
+	Config  any                  `json:"config,omitempty"`
+	HotKeys []KeyCount           `json:"hot_keys,omitempty"`
+}
+
+// info returns debug information of cache
+func info(name string, entry Entry, detailed bool) cacheInfo {
+	ci := cacheInfo{Name: name}
+
+	if entry.Stats != nil {
+		snapshot := entry.Stats.Snapshot()
+		ci.Stats = &snapshot
+	}
+
+	if detailed {
+		ci.Config = entry.Config
+		if entry.HotKeys != nil {
+			ci.HotKeys = entry.HotKeys.Top(topKeys)
+		}
+	}
+
+	return ci
+}
+
+// ServeHTTP serves debug information
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.EscapedPath(), "/")
+	if path == "" {
+		h.serveIndex(w, r)
+		return
+	}
+
+	parts := strings.SplitN(path, "/", 3)
+	name, err := url.PathUnescape(parts[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entry, ok := h.entry(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("cache %s not found", name), http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, info(name, entry, true))
+	case len(parts) == 3 && parts[1] == "keys":
+		key, err := url.PathUnescape(parts[2])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.serveKey(w, r, entry, key)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// serveIndex serves names and stats of all caches
+func (h *Handler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.mu.RLock()
+	infos := make([]cacheInfo, 0, len(h.entries))
+	for name, entry := range h.entries {
+		infos = append(infos, info(name, entry, false))
+	}
+	h.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	writeJSON(w, infos)
+}
+
+// serveKey serves ad-hoc get or delete of key
+func (h *Handler) serveKey(w http.ResponseWriter, r *http.Request, entry Entry, key string) {
+	if h.authorize == nil || !h.authorize(r) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	if entry.Cacher == nil {
+		http.Error(w, "cache has no cacher", http.StatusNotImplemented)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		value, err := entry.Cacher.Get(r.Context(), key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if value == nil {
+			http.Error(w, fmt.Sprintf("key %s not found", key), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]any{"key": key, "type": fmt.Sprintf("%T", value), "value": value})
+	case http.MethodDelete:
+		if err := entry.Cacher.Delete(r.Context(), key); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeJSON writes value as indented json
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,74 @@
+package debug
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/memory"
+)
+
+func TestHandler(t *testing.T) {
+	stats := cache.NewStats("orders")
+	stats.Observe(cache.OpGet, cache.ResultHit, time.Millisecond)
+	hotKeys := NewHotKeys(10)
+	c := hotKeys.Track(memory.New())
+	_ = c.Set(context.Background(), "order:1", "value")
+	_, _ = c.Get(context.Background(), "order:1")
+
+	h := NewHandler(WithAuthorizer(func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "secret"
+	}))
+	h.Register("orders", Entry{Cacher: c, Stats: stats, Config: map[string]any{"ttl": "1m"}, HotKeys: hotKeys})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		auth       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "test index", method: http.MethodGet, path: "/", wantStatus: http.StatusOK, wantBody: `"hits": 1`},
+		{name: "test cache", method: http.MethodGet, path: "/orders", wantStatus: http.StatusOK, wantBody: `"key": "order:1"`},
+		{name: "test unknown cache", method: http.MethodGet, path: "/users", wantStatus: http.StatusNotFound},
+		{name: "test unauthorized get", method: http.MethodGet, path: "/orders/keys/order:1", wantStatus: http.StatusForbidden},
+		{name: "test get", method: http.MethodGet, path: "/orders/keys/order:1", auth: "secret", wantStatus: http.StatusOK, wantBody: `"value": "value"`},
+		{name: "test delete", method: http.MethodDelete, path: "/orders/keys/order:1", auth: "secret", wantStatus: http.StatusNoContent},
+		{name: "test get deleted", method: http.MethodGet, path: "/orders/keys/order:1", auth: "secret", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.Header.Set("Authorization", tt.auth)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Handler.ServeHTTP() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Handler.ServeHTTP() body = %v, want contains %v", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHotKeys(t *testing.T) {
+	h := NewHotKeys(2)
+	for _, key := range []string{"a", "a", "a", "b", "c", "c"} {
+		h.Add(key)
+	}
+
+	top := h.Top(1)
+	if len(top) != 1 || top[0].Key != "a" || top[0].Count != 3 {
+		t.Errorf("HotKeys.Top() = %v, want a with 3", top)
+	}
+	if got := len(h.Top(10)); got != 2 {
+		t.Errorf("HotKeys.Top() length = %v, want %v", got, 2)
+	}
+}
@@ -0,0 +1,98 @@
+package debug
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/albinzx/cache"
+)
+
+// KeyCount is number of accesses of key
+type KeyCount struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// HotKeys counts key accesses keeping at most capacity keys
+// when full, the least accessed key is replaced by a new key which inherits its count,
+// so frequently accessed keys are kept while counts of new keys may be overestimated
+type HotKeys struct {
+	mu       sync.Mutex
+	capacity int
+	counts   map[string]int64
+}
+
+// NewHotKeys returns hot keys counter keeping at most capacity keys
+func NewHotKeys(capacity int) *HotKeys {
+	return &HotKeys{capacity: capacity, counts: make(map[string]int64, capacity)}
+}
+
+// Add counts access of key
+func (h *HotKeys) Add(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.counts[key]; ok || len(h.counts) < h.capacity {
+		h.counts[key]++
+		return
+	}
+
+	minKey, minCount := "", int64(-1)
+	for k, count := range h.counts {
+		if minCount < 0 || count < minCount {
+			minKey, minCount = k, count
+		}
+	}
+
+	delete(h.counts, minKey)
+	h.counts[key] = minCount + 1
+}
+
+// Top returns n most accessed keys in descending order of count
+func (h *HotKeys) Top(n int) []KeyCount {
+	h.mu.Lock()
+	top := make([]KeyCount, 0, len(h.counts))
+	for key, count := range h.counts {
+		top = append(top, KeyCount{Key: key, Count: count})
+	}
+	h.mu.Unlock()
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Key < top[j].Key
+	})
+
+	if len(top) > n {
+		top = top[:n]
+	}
+
+	return top
+}
+
+// tracked is cacher counting key accesses
+type tracked struct {
+	cache.Cacher
+	hotKeys *HotKeys
+}
+
+// Track returns cacher counting gets and sets of c in hot keys
+func (h *HotKeys) Track(c cache.Cacher) cache.Cacher {
+	return &tracked{Cacher: c, hotKeys: h}
+}
+
+// Set sets key-value to cache and counts key access
+func (t *tracked) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	t.hotKeys.Add(key)
+
+	return t.Cacher.Set(ctx, key, value, options...)
+}
+
+// Get gets value from cache and counts key access
+func (t *tracked) Get(ctx context.Context, key string) (any, error) {
+	t.hotKeys.Add(key)
+
+	return t.Cacher.Get(ctx, key)
+}
@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// deduplicated is cacher which collapses concurrent gets of the same key
+type deduplicated struct {
+	Cacher
+	group internal.Group
+}
+
+// Deduplicated returns cacher which collapses concurrent gets of the same key
+// into a single get to c, every caller receives the same value
+func Deduplicated(c Cacher) Cacher {
+	return &deduplicated{Cacher: c}
+}
+
+// Get gets value from cache, sharing result with concurrent gets of the same key
+// context of the first caller is used for the shared get
+func (d *deduplicated) Get(ctx context.Context, key string) (any, error) {
+	value, err, _ := d.group.Do(key, func() (any, error) {
+		return d.Cacher.Get(ctx, key)
+	})
+
+	return value, err
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingCacher counts gets and blocks them until released
+type countingCacher struct {
+	*mapCacher
+	gets    atomic.Int32
+	release chan struct{}
+}
+
+func (c *countingCacher) Get(ctx context.Context, key string) (any, error) {
+	c.gets.Add(1)
+	<-c.release
+	return c.mapCacher.Get(ctx, key)
+}
+
+func TestDeduplicated(t *testing.T) {
+	m := &countingCacher{mapCacher: newMapCacher(), release: make(chan struct{})}
+	_ = m.Set(context.Background(), "key", "value")
+	c := Deduplicated(m)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, _ := c.Get(context.Background(), "key"); got != "value" {
+				t.Errorf("Deduplicated().Get() = %v, want %v", got, "value")
+			}
+		}()
+	}
+
+	// give concurrent gets time to join the in-flight get
+	time.Sleep(10 * time.Millisecond)
+	close(m.release)
+	wg.Wait()
+
+	if got := m.gets.Load(); got >= 10 {
+		t.Errorf("Deduplicated().Get() backend gets = %v, want less than %v", got, 10)
+	}
+}
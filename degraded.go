@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// degradedReads reads values from persistence storage when cacher fails
+type degradedReads struct {
+	bucket    *internal.TokenBucket
+	writeBack bool
+}
+
+// DegradedOption provides degraded read options
+type DegradedOption func(*degradedReads)
+
+// WithDegradedRateLimit returns option to read at most readsPerSecond values from persistence storage
+// while cacher fails, with bursts up to burst reads, excess reads fail with ErrRateLimited joined with error of cacher,
+// so outage of cache does not send all its load to persistence storage, by default reads are not limited
+func WithDegradedRateLimit(readsPerSecond float64, burst int) DegradedOption {
+	return func(d *degradedReads) {
+		d.bucket = internal.NewTokenBucket(readsPerSecond, burst)
+	}
+}
+
+// WithDegradedWriteBack returns option to store values read while cacher fails back to cache,
+// by default they are not stored as writes to failing cacher would fail too
+func WithDegradedWriteBack() DegradedOption {
+	return func(d *degradedReads) {
+		d.writeBack = true
+	}
+}
+
+// WithDegradedReads returns option to read values from persistence storage when cacher fails, not misses,
+// e.g. when redis is down, in patterns reading through persistence storage
+// without it, reads of failing cacher fall back to persistence storage like misses, without rate limit
+// and with values written back to failing cacher
+func WithDegradedReads(options ...DegradedOption) Option {
+	return func(c *PatternedCache) {
+		c.degraded = &degradedReads{}
+		for _, option := range options {
+			option(c.degraded)
+		}
+	}
+}
+
+// read retrieves value of key from persistence storage after cacher fails with cacheErr
+func (d *degradedReads) read(ctx context.Context, key string, c Cacher, p Persister, cacheErr error) (any, error) {
+	if d.bucket != nil && !d.bucket.Allow() {
+		return nil, fmt.Errorf("%w: %w", ErrRateLimited, cacheErr)
+	}
+
+	return loadThrough(ctx, key, c, p, d.writeBack)
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithDegradedReads(t *testing.T) {
+	ctx := context.Background()
+	errDown := errors.New("connection refused")
+
+	tests := []struct {
+		name          string
+		options       []Option
+		wantErrs      []error
+		wantWriteBack bool
+	}{
+		{
+			name:          "disabled reads through",
+			wantErrs:      []error{nil, nil, nil},
+			wantWriteBack: true,
+		},
+		{
+			name:     "enabled suppresses write back",
+			options:  []Option{WithDegradedReads()},
+			wantErrs: []error{nil, nil, nil},
+		},
+		{
+			name:          "enabled with write back",
+			options:       []Option{WithDegradedReads(WithDegradedWriteBack())},
+			wantErrs:      []error{nil, nil, nil},
+			wantWriteBack: true,
+		},
+		{
+			name:     "rate limited",
+			options:  []Option{WithDegradedReads(WithDegradedRateLimit(0.001, 2))},
+			wantErrs: []error{nil, nil, ErrRateLimited},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := newMapCacher()
+			cacher.getErr = errDown
+			persister := newMapPersister()
+			persister.data["key"] = "value"
+
+			c, err := New(cacher, persister, append([]Option{WithPattern(NewReadThrough())}, tt.options...)...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			for i, wantErr := range tt.wantErrs {
+				value, err := c.Get(ctx, "key")
+				if !errors.Is(err, wantErr) {
+					t.Fatalf("Get() #%d error = %v, want %v", i, err, wantErr)
+				}
+				if wantErr != nil {
+					if !errors.Is(err, errDown) {
+						t.Errorf("Get() #%d error = %v, want cache error", i, err)
+					}
+					continue
+				}
+				if value != "value" {
+					t.Errorf("Get() #%d = %v, want value", i, value)
+				}
+			}
+
+			if _, ok := cacher.data["key"]; ok != tt.wantWriteBack {
+				t.Errorf("written back = %v, want %v", ok, tt.wantWriteBack)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Package di provides constructors of caches for dependency injection containers,
+// provider set for wire and module for fx
+package di
+
+import (
+	"context"
+
+	"github.com/albinzx/cache"
+)
+
+// Backend provides cacher of config, cleanup closes the cacher
+func Backend(config cache.BackendConfig) (cache.Cacher, func(), error) {
+	cacher, err := cache.NewBackend(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cacher, func() { _ = cacher.Close() }, nil
+}
+
+// Persister provides persister of config, nil persister is provided if config is nil
+// cleanup closes the persister
+func Persister(config *cache.PersisterConfig) (cache.Persister, func(), error) {
+	if config == nil {
+		return nil, func() {}, nil
+	}
+
+	persister, err := cache.NewPersister(*config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return persister, func() { _ = persister.Close() }, nil
+}
+
+// Cache provides patterned cache of config with provided cacher and persister
+// cleanup waits for asynchronous work of the cache, cacher and persister are closed by their own cleanup
+func Cache(config cache.Config, cacher cache.Cacher, persister cache.Persister) (*cache.PatternedCache, func(), error) {
+	options, err := config.Options()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c, err := cache.New(cacher, persister, options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return c, func() { _ = c.Drain(context.Background()) }, nil
+}
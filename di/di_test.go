@@ -0,0 +1,65 @@
+package di
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+
+	"github.com/albinzx/cache"
+	_ "github.com/albinzx/cache/memory"
+)
+
+func TestProviders(t *testing.T) {
+	config := cache.Config{Backend: cache.BackendConfig{Type: "memory"}, Pattern: "write-behind"}
+
+	cacher, closeCacher, err := Backend(config.Backend)
+	if err != nil {
+		t.Fatalf("Backend() error = %v", err)
+	}
+	defer closeCacher()
+
+	persister, closePersister, err := Persister(config.Persister)
+	if err != nil || persister != nil {
+		t.Fatalf("Persister() = %v, %v, want nil", persister, err)
+	}
+	defer closePersister()
+
+	c, drain, err := Cache(config, cacher, persister)
+	if err != nil {
+		t.Fatalf("Cache() error = %v", err)
+	}
+	defer drain()
+
+	if err := c.Set(context.Background(), "key", "value"); err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+
+	if _, _, err := Backend(cache.BackendConfig{Type: "unknown"}); err == nil {
+		t.Errorf("Backend() error = %v, want error", err)
+	}
+	if _, _, err := Cache(cache.Config{Pattern: "unknown"}, cacher, nil); err == nil {
+		t.Errorf("Cache() error = %v, want error", err)
+	}
+}
+
+func TestModule(t *testing.T) {
+	var c *cache.PatternedCache
+
+	app := fxtest.New(t,
+		fx.Supply(cache.Config{Backend: cache.BackendConfig{Type: "memory"}}),
+		Module,
+		fx.Populate(&c),
+	)
+	app.RequireStart()
+
+	if err := c.Set(context.Background(), "key", "value"); err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+	if got, _ := c.Get(context.Background(), "key"); got != "value" {
+		t.Errorf("Get() = %v, want %v", got, "value")
+	}
+
+	app.RequireStop()
+}
@@ -0,0 +1,66 @@
+package di
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/albinzx/cache"
+)
+
+// Module provides patterned cache, cacher and persister of supplied cache.Config,
+// they are shut down in order by lifecycle manager when application stops
+var Module = fx.Module("cache",
+	fx.Provide(
+		configs,
+		manager,
+		backend,
+		persister,
+		patternedCache,
+	),
+)
+
+// configs provides backend and persister configs of cache config
+func configs(config cache.Config) (cache.BackendConfig, *cache.PersisterConfig) {
+	return config.Backend, config.Persister
+}
+
+// manager provides lifecycle manager which shuts down cache components when application stops
+func manager(lc fx.Lifecycle) *cache.Manager {
+	m := cache.NewManager()
+	lc.Append(fx.Hook{OnStop: m.Shutdown})
+
+	return m
+}
+
+// backend provides cacher of config closed by manager
+func backend(m *cache.Manager, config cache.BackendConfig) (cache.Cacher, error) {
+	cacher, err := cache.NewBackend(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return cacher, m.AddCacher(cacher)
+}
+
+// persister provides persister of config closed by manager, nil persister is provided if config is nil
+func persister(m *cache.Manager, config *cache.PersisterConfig) (cache.Persister, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	p, err := cache.NewPersister(*config)
+	if err != nil {
+		return nil, err
+	}
+
+	return p, m.AddPersister(p)
+}
+
+// patternedCache provides patterned cache drained by manager
+func patternedCache(m *cache.Manager, config cache.Config, cacher cache.Cacher, persister cache.Persister) (*cache.PatternedCache, error) {
+	c, _, err := Cache(config, cacher, persister)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, m.AddDrainer(c)
+}
@@ -0,0 +1,15 @@
+package di
+
+import (
+	"github.com/google/wire"
+
+	"github.com/albinzx/cache"
+)
+
+// ProviderSet provides patterned cache, cacher and persister of cache.Config
+var ProviderSet = wire.NewSet(
+	wire.FieldsOf(new(cache.Config), "Backend", "Persister"),
+	Backend,
+	Persister,
+	Cache,
+)
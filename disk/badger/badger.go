@@ -0,0 +1,392 @@
+// Package badger is cacher storing values on local disk in badger database, so cached values survive restarts
+package badger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+	"github.com/dgraph-io/badger/v4"
+)
+
+const (
+	// DefaultGCInterval is default interval of value log garbage collection
+	DefaultGCInterval = 5 * time.Minute
+	// gcDiscardRatio is ratio of discardable data of value log file which is rewritten by garbage collection
+	gcDiscardRatio = 0.5
+)
+
+// Cacher is cache implementation with badger database on local disk
+//
+// values are marshalled if marshaller is set, otherwise []byte and string values are stored as is
+// and read as string, like values of redis cacher, expired values are removed by badger compaction
+// and disk space of removed values is reclaimed by periodic value log garbage collection
+type Cacher struct {
+	db         *badger.DB
+	options    badger.Options
+	ttl        time.Duration
+	prefix     internal.KeyPrefix
+	marshaller cache.Marshaller
+	gcInterval time.Duration
+	notFound   bool
+	metrics    cache.Metrics
+
+	slogger       *slog.Logger
+	logLevel      slog.Level
+	errorLogLevel slog.Level
+	logger        *internal.Logger
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Option provides badger cacher options
+type Option func(*Cacher)
+
+// New opens badger database in directory path, creating it if it does not exist,
+// and returns cacher storing values in it
+func New(path string, options ...Option) (*Cacher, error) {
+	c := &Cacher{
+		options:       badger.DefaultOptions(path).WithLogger(nil),
+		gcInterval:    DefaultGCInterval,
+		logLevel:      slog.LevelDebug,
+		errorLogLevel: slog.LevelWarn,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.ttl < 0 {
+		return nil, fmt.Errorf("%w: negative ttl %v", cache.ErrInvalidOption, c.ttl)
+	}
+
+	db, err := badger.Open(c.options)
+	if err != nil {
+		return nil, err
+	}
+	c.db = db
+
+	if c.prefix == nil {
+		c.prefix = &internal.NoPrefix{}
+	}
+	c.logger = internal.NewLogger(c.slogger, c.logLevel, c.errorLogLevel).With("backend", "badger")
+
+	go c.collect()
+
+	return c, nil
+}
+
+// WithTTL returns option to set global TTL
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cacher) {
+		c.ttl = ttl
+	}
+}
+
+// WithName returns option to add name as prefix to key, so cachers of different names may share directory
+// if name is empty, no prefix will be added
+func WithName(name string) Option {
+	return func(c *Cacher) {
+		if len(name) == 0 {
+			c.prefix = &internal.NoPrefix{}
+			return
+		}
+
+		// prefix is always in lower case
+		c.prefix = &internal.WithPrefix{Name: strings.ToLower(name)}
+	}
+}
+
+// WithMarshaller returns option to set marshaller
+func WithMarshaller(marshaller cache.Marshaller) Option {
+	return func(c *Cacher) {
+		c.marshaller = marshaller
+	}
+}
+
+// WithCodec returns option to encode values with codec and decode them to type of prototype,
+// e.g. WithCodec(cache.JSONCodec{}, User{})
+func WithCodec(codec cache.Codec, prototype any) Option {
+	return WithMarshaller(cache.CodecMarshaller(codec, prototype))
+}
+
+// WithGCInterval returns option to set interval of value log garbage collection,
+// 0 disables it, default is DefaultGCInterval
+func WithGCInterval(interval time.Duration) Option {
+	return func(c *Cacher) {
+		c.gcInterval = interval
+	}
+}
+
+// WithBadgerOptions returns option to tune options of badger database, e.g. to run it in memory
+func WithBadgerOptions(tune func(badger.Options) badger.Options) Option {
+	return func(c *Cacher) {
+		c.options = tune(c.options)
+	}
+}
+
+// WithMetrics returns option to record results and latencies of operations to metrics, e.g. cache.Stats
+func WithMetrics(metrics cache.Metrics) Option {
+	return func(c *Cacher) {
+		c.metrics = metrics
+	}
+}
+
+// WithNotFoundError returns option to return cache.ErrNotFound instead of nil value on miss of Get
+func WithNotFoundError() Option {
+	return func(c *Cacher) {
+		c.notFound = true
+	}
+}
+
+// WithLogger returns option to set structured logger
+// by default nothing is logged
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Cacher) {
+		c.slogger = logger
+	}
+}
+
+// WithLogLevel returns option to set log level of successful operations
+// and of failed operations, defaults are debug and warn level
+func WithLogLevel(level, errorLevel slog.Level) Option {
+	return func(c *Cacher) {
+		c.logLevel = level
+		c.errorLogLevel = errorLevel
+	}
+}
+
+// collect runs value log garbage collection every gc interval until cacher is closed
+func (c *Cacher) collect() {
+	defer close(c.done)
+
+	if c.gcInterval <= 0 {
+		<-c.stop
+		return
+	}
+
+	ticker := time.NewTicker(c.gcInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// every run rewrites at most one file, so it is repeated while files are rewritten
+			for {
+				if err := c.db.RunValueLogGC(gcDiscardRatio); err != nil {
+					if !errors.Is(err, badger.ErrNoRewrite) && !errors.Is(err, badger.ErrRejected) {
+						c.logger.Error(context.Background(), "failed to collect value log garbage", "gc", "", err)
+					}
+					break
+				}
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// encode returns bytes of value stored to database
+func (c *Cacher) encode(value any) ([]byte, error) {
+	if c.marshaller != nil {
+		return c.marshaller.Marshal(value)
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("%w: %T can not be stored without marshaller", cache.ErrUnexpectedType, value)
+	}
+}
+
+// decode returns value of bytes read from database, unmarshalled if marshaller is set
+func (c *Cacher) decode(data []byte) (any, error) {
+	if c.marshaller != nil {
+		return c.marshaller.Unmarshal(data)
+	}
+
+	return string(data), nil
+}
+
+// entry returns entry of key-value expiring after ttl
+func (c *Cacher) entry(key string, value any, ttl time.Duration) (*badger.Entry, error) {
+	data, err := c.encode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := badger.NewEntry([]byte(c.prefix.Prefix(key)), data)
+	if ttl > 0 {
+		entry = entry.WithTTL(ttl)
+	}
+
+	return entry, nil
+}
+
+// Set stores key-value to database
+func (c *Cacher) Set(ctx context.Context, key string, value any, setOptions ...cache.SetOption) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "set", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpSet, start, value, err)
+	}(time.Now())
+
+	setConfig := &cache.SetConfiguration{TTL: c.ttl}
+	for _, option := range setOptions {
+		option(setConfig)
+	}
+
+	entry, err := c.entry(key, value, setConfig.TTL)
+	if err != nil {
+		return err
+	}
+
+	return c.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(entry)
+	})
+}
+
+// Get retrieves value from database, value is nil if key is not found or expired
+func (c *Cacher) Get(ctx context.Context, key string) (value any, err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "get", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpGet, start, value, err)
+	}(time.Now())
+
+	var data []byte
+	err = c.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(c.prefix.Prefix(key)))
+		if err != nil {
+			return err
+		}
+		data, err = item.ValueCopy(nil)
+		return err
+	})
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		if c.notFound {
+			return nil, cache.ErrNotFound
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c.decode(data)
+}
+
+// Delete deletes value from database
+func (c *Cacher) Delete(ctx context.Context, key string) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "delete", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpDelete, start, nil, err)
+	}(time.Now())
+
+	return c.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(c.prefix.Prefix(key)))
+	})
+}
+
+// Load stores key-values to database with global TTL in one write batch
+func (c *Cacher) Load(ctx context.Context, data map[string]any) (err error) {
+	defer func(start time.Time) { cache.RecordOperation(c.metrics, cache.OpLoad, start, nil, err) }(time.Now())
+
+	batch := c.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	for key, value := range data {
+		entry, err := c.entry(key, value, c.ttl)
+		if err != nil {
+			return &cache.KeyError{Key: key, Err: err}
+		}
+		if err := batch.SetEntry(entry); err != nil {
+			return &cache.KeyError{Key: key, Err: err}
+		}
+	}
+
+	return batch.Flush()
+}
+
+// GetMany gets values of keys from database in one read transaction, returned map contains only keys which are found
+func (c *Cacher) GetMany(ctx context.Context, keys []string) (_ map[string]any, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "get_many", strings.Join(keys, ","), start, err) }(time.Now())
+
+	values := make(map[string]any, len(keys))
+	var errs []error
+	err = c.db.View(func(txn *badger.Txn) error {
+		for _, key := range keys {
+			item, err := txn.Get([]byte(c.prefix.Prefix(key)))
+			if errors.Is(err, badger.ErrKeyNotFound) {
+				continue
+			}
+			var data []byte
+			if err == nil {
+				data, err = item.ValueCopy(nil)
+			}
+			var value any
+			if err == nil {
+				value, err = c.decode(data)
+			}
+			if err != nil {
+				errs = append(errs, &cache.KeyError{Key: key, Err: err})
+				continue
+			}
+			values[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return values, errors.Join(errs...)
+}
+
+// DeleteMany deletes values of keys from database in one write batch
+func (c *Cacher) DeleteMany(ctx context.Context, keys ...string) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "delete_many", strings.Join(keys, ","), start, err) }(time.Now())
+
+	batch := c.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	for _, key := range keys {
+		if err := batch.Delete([]byte(c.prefix.Prefix(key))); err != nil {
+			return &cache.KeyError{Key: key, Err: err}
+		}
+	}
+
+	return batch.Flush()
+}
+
+// Ping reports error if database is closed
+func (c *Cacher) Ping(context.Context) error {
+	if c.db.IsClosed() {
+		return errors.New("badger: database is closed")
+	}
+
+	return nil
+}
+
+// Close stops garbage collection and closes database
+func (c *Cacher) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		<-c.done
+		err = c.db.Close()
+	})
+
+	return err
+}
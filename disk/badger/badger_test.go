@@ -0,0 +1,162 @@
+package badger
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/dgraph-io/badger/v4"
+)
+
+type user struct {
+	ID   int
+	Name string
+}
+
+// newCacher returns cacher of in-memory badger database closed on cleanup
+func newCacher(t *testing.T, options ...Option) *Cacher {
+	t.Helper()
+
+	options = append([]Option{WithBadgerOptions(func(o badger.Options) badger.Options {
+		return o.WithInMemory(true)
+	})}, options...)
+	c, err := New("", options...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c
+}
+
+func TestCacher(t *testing.T) {
+	ctx := context.Background()
+	c := newCacher(t, WithName("Test"))
+
+	if err := c.Set(ctx, "key", "value"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := c.Get(ctx, "key"); err != nil || got != "value" {
+		t.Errorf("Get() = %v, %v, want value", got, err)
+	}
+
+	if err := c.Set(ctx, "bytes", []byte("value")); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := c.Get(ctx, "bytes"); err != nil || got != "value" {
+		t.Errorf("Get() = %v, %v, want value", got, err)
+	}
+
+	if err := c.Set(ctx, "user", user{ID: 1}); !errors.Is(err, cache.ErrUnexpectedType) {
+		t.Errorf("Set() error = %v, want %v", err, cache.ErrUnexpectedType)
+	}
+
+	// keys are stored with name prefix
+	_ = c.db.View(func(txn *badger.Txn) error {
+		if _, err := txn.Get([]byte("test.key")); err != nil {
+			t.Errorf("prefixed key error = %v", err)
+		}
+		return nil
+	})
+
+	if err := c.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got, err := c.Get(ctx, "key"); err != nil || got != nil {
+		t.Errorf("Get() deleted = %v, %v, want nil", got, err)
+	}
+}
+
+func TestCacher_NotFound(t *testing.T) {
+	c := newCacher(t, WithNotFoundError())
+
+	if _, err := c.Get(context.Background(), "missing"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, cache.ErrNotFound)
+	}
+}
+
+func TestCacher_Codec(t *testing.T) {
+	ctx := context.Background()
+	c := newCacher(t, WithCodec(cache.JSONCodec{}, user{}))
+
+	want := user{ID: 1, Name: "one"}
+	if err := c.Set(ctx, "user", want); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := c.Get(ctx, "user"); err != nil || got != want {
+		t.Errorf("Get() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestCacher_TTL(t *testing.T) {
+	ctx := context.Background()
+	c := newCacher(t)
+
+	if _, err := New("", WithTTL(-time.Second)); !errors.Is(err, cache.ErrInvalidOption) {
+		t.Errorf("New() error = %v, want %v", err, cache.ErrInvalidOption)
+	}
+
+	if err := c.Set(ctx, "key", "value", cache.WithTTL(time.Second)); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, _ := c.Get(ctx, "key"); got != "value" {
+		t.Errorf("Get() = %v, want value", got)
+	}
+
+	// badger expires entries at second granularity
+	time.Sleep(2 * time.Second)
+	if got, err := c.Get(ctx, "key"); err != nil || got != nil {
+		t.Errorf("Get() expired = %v, %v, want nil", got, err)
+	}
+}
+
+func TestCacher_Many(t *testing.T) {
+	ctx := context.Background()
+	c := newCacher(t)
+
+	var keyErr *cache.KeyError
+	if err := c.Load(ctx, map[string]any{"c": 3}); !errors.As(err, &keyErr) || keyErr.Key != "c" {
+		t.Errorf("Load() error = %v, want key error of c", err)
+	}
+	if err := c.Load(ctx, map[string]any{"a": "1", "b": "2"}); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	got, err := c.GetMany(ctx, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	if want := map[string]any{"a": "1", "b": "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMany() = %v, want %v", got, want)
+	}
+
+	if err := c.DeleteMany(ctx, "a", "c"); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	got, _ = c.GetMany(ctx, []string{"a", "b"})
+	if want := map[string]any{"b": "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMany() = %v, want %v", got, want)
+	}
+}
+
+func TestCacher_Close(t *testing.T) {
+	c := newCacher(t, WithGCInterval(time.Millisecond))
+
+	if err := c.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if err := c.Ping(context.Background()); err == nil {
+		t.Error("Ping() error = nil after Close")
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+var (
+	// ErrNotScanner is returned when cacher can not iterate its keys
+	ErrNotScanner = errors.New("cacher does not support key iteration")
+)
+
+// DumpOptions controls key dump
+type DumpOptions struct {
+	// Pattern is glob pattern of dumped keys, empty pattern matches all keys
+	Pattern string
+	// SampleRate is fraction of keys dumped between 0 and 1, zero dumps all keys
+	SampleRate float64
+	// Limit is maximum number of dumped keys, zero means no limit
+	Limit int
+}
+
+// KeyInfo is dumped information of key
+type KeyInfo struct {
+	Key string `json:"key"`
+	// Type is Go type of value
+	Type string `json:"type"`
+	// Size is size in bytes of value, only known for []byte and string values
+	Size *int `json:"size,omitempty"`
+	// TTL is remaining time to live, only known for cachers implementing TTLReader
+	TTL string `json:"ttl,omitempty"`
+}
+
+// Dump writes information of keys of c as json lines to w and returns number of dumped keys
+// c must implement Scanner, ttl is written if c implements TTLReader
+// keys which disappear while dumping are skipped
+func Dump(ctx context.Context, c Cacher, w io.Writer, options DumpOptions) (int, error) {
+	scanner, ok := c.(Scanner)
+	if !ok {
+		return 0, ErrNotScanner
+	}
+
+	ttlReader, hasTTL := c.(TTLReader)
+	encoder := json.NewEncoder(w)
+	dumped := 0
+
+	it := scanner.Keys(ctx, options.Pattern)
+	for it.Next(ctx) {
+		if options.Limit > 0 && dumped >= options.Limit {
+			break
+		}
+
+		if options.SampleRate > 0 && options.SampleRate < 1 && rand.Float64() >= options.SampleRate {
+			continue
+		}
+
+		key := it.Key()
+		info := KeyInfo{Key: key}
+
+		var value any
+		var err error
+		if hasTTL {
+			var ttl time.Duration
+			value, ttl, err = ttlReader.GetWithTTL(ctx, key)
+			if ttl > 0 {
+				info.TTL = ttl.String()
+			}
+		} else {
+			value, err = c.Get(ctx, key)
+		}
+
+		if err != nil {
+			return dumped, fmt.Errorf("key %s: %w", key, err)
+		}
+		if value == nil {
+			continue
+		}
+
+		info.Type = fmt.Sprintf("%T", value)
+		if size, ok := sizeOf(value); ok {
+			info.Size = &size
+		}
+
+		if err := encoder.Encode(info); err != nil {
+			return dumped, err
+		}
+		dumped++
+	}
+
+	return dumped, it.Err()
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+)
+
+// scanCacher is map cacher which iterates keys and reports fixed ttl
+type scanCacher struct {
+	*mapCacher
+}
+
+func (s *scanCacher) Keys(_ context.Context, _ string) KeyIterator {
+	var keys []string
+	for key := range s.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return NewSliceIterator(keys)
+}
+
+func (s *scanCacher) GetWithTTL(ctx context.Context, key string) (any, time.Duration, error) {
+	value, err := s.Get(ctx, key)
+	return value, time.Minute, err
+}
+
+func TestDump(t *testing.T) {
+	m := newMapCacher()
+	m.data = map[string]any{"a": "value", "b": []byte("bytes"), "c": 42}
+
+	tests := []struct {
+		name     string
+		cacher   Cacher
+		options  DumpOptions
+		want     int
+		wantErr  error
+		wantSize int
+	}{
+		{name: "test full dump", cacher: &scanCacher{m}, want: 3},
+		{name: "test limited dump", cacher: &scanCacher{m}, options: DumpOptions{Limit: 2}, want: 2},
+		{name: "test not scanner", cacher: m, wantErr: ErrNotScanner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			got, err := Dump(context.Background(), tt.cacher, buf, tt.options)
+			if err != tt.wantErr {
+				t.Fatalf("Dump() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Dump() = %v, want %v", got, tt.want)
+			}
+			if tt.want == 0 {
+				return
+			}
+
+			var first KeyInfo
+			if err := json.NewDecoder(buf).Decode(&first); err != nil {
+				t.Fatalf("Dump() output error = %v", err)
+			}
+			if first.Key != "a" || first.Type != "string" || first.Size == nil || *first.Size != 5 || first.TTL != "1m0s" {
+				t.Errorf("Dump() first = %+v, want key a with size 5 and ttl 1m", first)
+			}
+		})
+	}
+}
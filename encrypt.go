@@ -0,0 +1,258 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnknownKey is returned when encrypted value uses key which is not in keyring
+	ErrUnknownKey = errors.New("unknown encryption key")
+	// ErrNotEncryptable is returned when value to encrypt is neither []byte nor string
+	ErrNotEncryptable = errors.New("value must be []byte or string to be encrypted")
+	// ErrCorruptedValue is returned when encrypted value is malformed
+	ErrCorruptedValue = errors.New("corrupted encrypted value")
+)
+
+// Keyring provides encryption keys
+type Keyring interface {
+	// Current returns id and key used to encrypt new values
+	Current() (string, []byte, error)
+	// Key returns key by id to decrypt values
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeyring is keyring with fixed set of keys
+type StaticKeyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewStaticKeyring returns keyring encrypting with key of current id
+// keys maps id to AES key of 16, 24 or 32 bytes, previous keys are kept
+// in keys so values encrypted before rotation can still be decrypted
+func NewStaticKeyring(current string, keys map[string][]byte) (*StaticKeyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, current)
+	}
+
+	for id, key := range keys {
+		if len(id) > 255 {
+			return nil, fmt.Errorf("key id %s is longer than 255 bytes", id)
+		}
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("key %s: %w", id, err)
+		}
+	}
+
+	return &StaticKeyring{current: current, keys: keys}, nil
+}
+
+// Current returns current key
+func (k *StaticKeyring) Current() (string, []byte, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+// Key returns key by id
+func (k *StaticKeyring) Key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+
+	return key, nil
+}
+
+// encryptedMagic starts header of encrypted values
+// header is magic, kind of original value, key id length and key id, followed by nonce and ciphertext
+var encryptedMagic = []byte{0xc7, 0x45}
+
+// encryptor encrypts values with keys of keyring and decrypts values with encryption header
+type encryptor struct {
+	keyring Keyring
+}
+
+// encrypted is cacher which encrypts values
+type encrypted struct {
+	Cacher
+	encryptor
+}
+
+// Encrypted returns cacher which encrypts values with AES-GCM using keys from keyring
+// only []byte and string values can be encrypted, so encryption is usually combined
+// with backend marshaller, to compress values wrap the encrypted cacher with Compressed
+// cache key is authenticated with the value so encrypted values can not be swapped between keys
+func Encrypted(c Cacher, keyring Keyring) Cacher {
+	return &encrypted{Cacher: c, encryptor: encryptor{keyring: keyring}}
+}
+
+// aead returns AES-GCM cipher with key
+func aead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encrypt returns encrypted value with header, key is authenticated with value
+func (e *encryptor) encrypt(key string, value any) ([]byte, error) {
+	var data []byte
+	var kind byte
+
+	switch v := value.(type) {
+	case []byte:
+		data, kind = v, kindBytes
+	case string:
+		data, kind = []byte(v), kindString
+	default:
+		return nil, ErrNotEncryptable
+	}
+
+	id, secret, err := e.keyring.Current()
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := aead(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(encryptedMagic)+2+len(id)+gcm.NonceSize()+len(data)+gcm.Overhead())
+	out = append(out, encryptedMagic...)
+	out = append(out, kind, byte(len(id)))
+	out = append(out, id...)
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+
+	return gcm.Seal(out, nonce, data, []byte(key)), nil
+}
+
+// decrypt returns original value if value has encryption header
+func (e *encryptor) decrypt(key string, value any) (any, error) {
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return value, nil
+	}
+
+	if !bytes.HasPrefix(data, encryptedMagic) {
+		return value, nil
+	}
+
+	data = data[len(encryptedMagic):]
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return nil, ErrCorruptedValue
+	}
+
+	kind, id := data[0], string(data[2:2+int(data[1])])
+	data = data[2+int(data[1]):]
+
+	secret, err := e.keyring.Key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := aead(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrCorruptedValue
+	}
+
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if kind == kindString {
+		return string(plain), nil
+	}
+
+	return plain, nil
+}
+
+// Set encrypts value and sets it to cache
+func (e *encrypted) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	data, err := e.encrypt(key, value)
+	if err != nil {
+		return err
+	}
+
+	return e.Cacher.Set(ctx, key, data, options...)
+}
+
+// Get gets value from cache and decrypts it
+func (e *encrypted) Get(ctx context.Context, key string) (any, error) {
+	value, err := e.Cacher.Get(ctx, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	return e.decrypt(key, value)
+}
+
+// Load encrypts values and loads them into cache
+func (e *encrypted) Load(ctx context.Context, data map[string]any) error {
+	values := make(map[string]any, len(data))
+	for key, value := range data {
+		encrypted, err := e.encrypt(key, value)
+		if err != nil {
+			return err
+		}
+		values[key] = encrypted
+	}
+
+	return e.Cacher.Load(ctx, values)
+}
+
+// encryptedMarshaller is marshaller which encrypts marshalled values
+type encryptedMarshaller struct {
+	Marshaller
+	encryptor
+}
+
+// EncryptedMarshaller returns marshaller which encrypts values marshalled by m with AES-GCM using keys from keyring,
+// e.g. to encrypt json of redis cacher, values marshalled before encryption was enabled are still unmarshalled
+// unlike Encrypted, cache key is not authenticated as marshaller does not know it
+func EncryptedMarshaller(m Marshaller, keyring Keyring) Marshaller {
+	return &encryptedMarshaller{Marshaller: m, encryptor: encryptor{keyring: keyring}}
+}
+
+// Marshal marshals value and encrypts it
+func (m *encryptedMarshaller) Marshal(value any) ([]byte, error) {
+	data, err := m.Marshaller.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.encrypt("", data)
+}
+
+// Unmarshal decrypts data and unmarshals it
+func (m *encryptedMarshaller) Unmarshal(data []byte) (any, error) {
+	decrypted, err := m.decrypt("", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Marshaller.Unmarshal(decrypted.([]byte))
+}
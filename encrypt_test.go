@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncrypted(t *testing.T) {
+	keyring, err := NewStaticKeyring("v1", map[string][]byte{"v1": bytes.Repeat([]byte{1}, 32)})
+	if err != nil {
+		t.Fatalf("NewStaticKeyring() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   any
+		wantErr error
+	}{
+		{name: "test string", value: "secret"},
+		{name: "test bytes", value: []byte("secret")},
+		{name: "test non byte value", value: 42, wantErr: ErrNotEncryptable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMapCacher()
+			c := Encrypted(m, keyring)
+			if err := c.Set(context.Background(), "key", tt.value); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Encrypted().Set() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if stored := m.data["key"].([]byte); bytes.Contains(stored, []byte("secret")) {
+				t.Errorf("Encrypted().Set() stored plain text %s", stored)
+			}
+
+			got, err := c.Get(context.Background(), "key")
+			if err != nil || !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("Encrypted().Get() = %v, %v, want %v", got, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestEncrypted_Rotation(t *testing.T) {
+	v1 := bytes.Repeat([]byte{1}, 16)
+	v2 := bytes.Repeat([]byte{2}, 16)
+	old, _ := NewStaticKeyring("v1", map[string][]byte{"v1": v1})
+	rotated, _ := NewStaticKeyring("v2", map[string][]byte{"v1": v1, "v2": v2})
+	removed, _ := NewStaticKeyring("v2", map[string][]byte{"v2": v2})
+
+	m := newMapCacher()
+	_ = Encrypted(m, old).Set(context.Background(), "key", "secret")
+
+	if got, err := Encrypted(m, rotated).Get(context.Background(), "key"); err != nil || got != "secret" {
+		t.Errorf("Encrypted().Get() after rotation = %v, %v, want %v", got, err, "secret")
+	}
+	if _, err := Encrypted(m, removed).Get(context.Background(), "key"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Encrypted().Get() with removed key error = %v, want %v", err, ErrUnknownKey)
+	}
+
+	// value can not be moved to another key
+	m.data["other"] = m.data["key"]
+	if _, err := Encrypted(m, rotated).Get(context.Background(), "other"); err == nil {
+		t.Errorf("Encrypted().Get() of swapped value error = %v, want error", err)
+	}
+}
+
+func TestEncrypted_Compressed(t *testing.T) {
+	keyring, _ := NewStaticKeyring("v1", map[string][]byte{"v1": bytes.Repeat([]byte{1}, 32)})
+	value := strings.Repeat("secret", 100)
+
+	m := newMapCacher()
+	c := Compressed(Encrypted(m, keyring), &GzipCompression{}, 0)
+	_ = c.Set(context.Background(), "key", value)
+
+	if got, err := c.Get(context.Background(), "key"); err != nil || got != value {
+		t.Errorf("Compressed(Encrypted()).Get() = %v, want %v", err, value)
+	}
+	if stored := m.data["key"].([]byte); len(stored) >= len(value) {
+		t.Errorf("Compressed(Encrypted()).Set() stored size = %v, want compressed", len(stored))
+	}
+}
+
+func TestNewStaticKeyring(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		keys    map[string][]byte
+		wantErr bool
+	}{
+		{name: "test valid keyring", current: "v1", keys: map[string][]byte{"v1": make([]byte, 32)}},
+		{name: "test unknown current key", current: "v2", keys: map[string][]byte{"v1": make([]byte, 32)}, wantErr: true},
+		{name: "test invalid key size", current: "v1", keys: map[string][]byte{"v1": make([]byte, 10)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewStaticKeyring(tt.current, tt.keys); (err != nil) != tt.wantErr {
+				t.Errorf("NewStaticKeyring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncryptedMarshaller(t *testing.T) {
+	keys := map[string][]byte{"v1": bytes.Repeat([]byte{1}, 32), "v2": bytes.Repeat([]byte{2}, 32)}
+	previous, _ := NewStaticKeyring("v1", keys)
+	current, _ := NewStaticKeyring("v2", keys)
+	json := CodecMarshaller(JSONCodec{}, "")
+
+	data, err := EncryptedMarshaller(json, previous).Marshal("secret")
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if bytes.Contains(data, []byte("secret")) {
+		t.Errorf("Marshal() = %q, want encrypted value", data)
+	}
+
+	m := EncryptedMarshaller(json, current)
+	for _, data := range [][]byte{data, []byte(`"secret"`)} {
+		if got, err := m.Unmarshal(data); err != nil || got != "secret" {
+			t.Errorf("Unmarshal() = %v, %v, want %v", got, err, "secret")
+		}
+	}
+}
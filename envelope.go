@@ -0,0 +1,297 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrEnvelopeFormat is returned when enveloped value is corrupted
+	ErrEnvelopeFormat = errors.New("invalid envelope format")
+)
+
+// envelopeMagic starts enveloped value, it is followed by kind of value,
+// fresh and expiry time in unix milliseconds and encoded value
+var envelopeMagic = []byte{0xc7, 0x46}
+
+// taggedEnvelopeMagic starts enveloped value with entity tag,
+// which follows expiry time as length prefixed string
+var taggedEnvelopeMagic = []byte{0xc7, 0x47}
+
+// WithSoftTTL sets time value stays fresh, after it value is stale but still stored until its TTL
+// it is recorded by enveloped cachers, see Enveloped
+func WithSoftTTL(ttl time.Duration) SetOption {
+	return func(setConfig *SetConfiguration) {
+		setConfig.SoftTTL = ttl
+	}
+}
+
+// Envelope is value with its logical freshness and physical expiry
+type Envelope struct {
+	Value any
+	// FreshUntil is time value becomes stale, zero means value is always fresh
+	FreshUntil time.Time
+	// ExpiresAt is time value is removed, zero means value never expires
+	ExpiresAt time.Time
+	// ETag is entity tag of value, see ETagOf, empty if value was enveloped without it
+	ETag string
+}
+
+// Fresh reports whether value is fresh at now
+func (e *Envelope) Fresh(now time.Time) bool {
+	return e.FreshUntil.IsZero() || now.Before(e.FreshUntil)
+}
+
+// Stale reports whether value is stale but not expired at now
+func (e *Envelope) Stale(now time.Time) bool {
+	return !e.Fresh(now) && (e.ExpiresAt.IsZero() || now.Before(e.ExpiresAt))
+}
+
+// MarshalBinary returns envelope encoded with value kept as is if it is []byte or string, otherwise gob encoded
+// entity tag of value is computed and stored if it is empty
+func (e *Envelope) MarshalBinary() ([]byte, error) {
+	kind, data, err := encodeValue(e.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	etag := e.ETag
+	if etag == "" {
+		etag = etagOf(data)
+	}
+
+	encoded := append([]byte{}, taggedEnvelopeMagic...)
+	encoded = append(encoded, kind)
+	encoded = binary.AppendVarint(encoded, unixMilli(e.FreshUntil))
+	encoded = binary.AppendVarint(encoded, unixMilli(e.ExpiresAt))
+	encoded = binary.AppendUvarint(encoded, uint64(len(etag)))
+	encoded = append(encoded, etag...)
+
+	return append(encoded, data...), nil
+}
+
+// UnmarshalBinary decodes envelope encoded by MarshalBinary
+func (e *Envelope) UnmarshalBinary(data []byte) error {
+	if !isEnvelope(data) {
+		return ErrEnvelopeFormat
+	}
+
+	tagged := data[1] == taggedEnvelopeMagic[1]
+	kind := data[len(envelopeMagic)]
+	data = data[len(envelopeMagic)+1:]
+	fresh, n := binary.Varint(data)
+	if n <= 0 {
+		return ErrEnvelopeFormat
+	}
+	data = data[n:]
+	expires, n := binary.Varint(data)
+	if n <= 0 {
+		return ErrEnvelopeFormat
+	}
+	data = data[n:]
+
+	var etag string
+	if tagged {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return ErrEnvelopeFormat
+		}
+		etag = string(data[n : n+int(size)])
+		data = data[n+int(size):]
+	}
+
+	value, err := decodeValue(kind, data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrEnvelopeFormat, err)
+	}
+
+	*e = Envelope{Value: value, FreshUntil: fromUnixMilli(fresh), ExpiresAt: fromUnixMilli(expires), ETag: etag}
+
+	return nil
+}
+
+// isEnvelope reports whether data starts with envelope header, with or without entity tag
+func isEnvelope(data []byte) bool {
+	if len(data) <= len(envelopeMagic) {
+		return false
+	}
+
+	header := string(data[:len(envelopeMagic)])
+
+	return header == string(envelopeMagic) || header == string(taggedEnvelopeMagic)
+}
+
+// unixMilli returns unix milliseconds of t, 0 for zero time
+func unixMilli(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixMilli()
+}
+
+// fromUnixMilli returns time of unix milliseconds, zero time for 0
+func fromUnixMilli(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+
+	return time.UnixMilli(ms)
+}
+
+// OpenEnvelope returns envelope of value got from cacher,
+// value which is not enveloped is returned in envelope which is always fresh
+func OpenEnvelope(value any) (*Envelope, error) {
+	var data []byte
+	switch v := value.(type) {
+	case *Envelope:
+		return v, nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	}
+
+	if !isEnvelope(data) {
+		return &Envelope{Value: value}, nil
+	}
+
+	e := &Envelope{}
+	if err := e.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// EnvelopeReader is implemented by cachers which can return values with their freshness
+type EnvelopeReader interface {
+	// GetEnvelope retrieves value with its freshness from cache, nil envelope means value is not found
+	GetEnvelope(context.Context, string) (*Envelope, error)
+}
+
+// GetEnvelope retrieves value of key with its freshness from c,
+// values of cachers which do not implement EnvelopeReader are opened with OpenEnvelope
+func GetEnvelope(ctx context.Context, c Cacher, key string) (*Envelope, error) {
+	if reader, ok := c.(EnvelopeReader); ok {
+		return reader.GetEnvelope(ctx, key)
+	}
+
+	value, err := getFound(ctx, c, key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	return OpenEnvelope(value)
+}
+
+// EnvelopeCacher is cacher storing values in envelopes with soft and hard ttl
+type EnvelopeCacher struct {
+	Cacher
+	now       func() time.Time
+	retention time.Duration
+}
+
+// EnvelopeOption provides envelope cacher options
+type EnvelopeOption func(*EnvelopeCacher)
+
+// WithStaleRetention returns option to keep values stored for retention after their TTL,
+// so they can be served stale by reads allowing it, see AllowStale, other reads miss values past their TTL
+func WithStaleRetention(retention time.Duration) EnvelopeOption {
+	return func(e *EnvelopeCacher) {
+		e.retention = retention
+	}
+}
+
+// Enveloped returns cacher storing values in envelopes recording time they stay fresh, given by WithSoftTTL,
+// and time they expire, given by WithTTL, values are stored physically until they expire
+// and for stale retention after it, see WithStaleRetention
+func Enveloped(c Cacher, options ...EnvelopeOption) *EnvelopeCacher {
+	e := &EnvelopeCacher{Cacher: c, now: time.Now}
+
+	for _, option := range options {
+		option(e)
+	}
+
+	return e
+}
+
+// envelope returns envelope of value with times of set options
+func (e *EnvelopeCacher) envelope(value any, setConfig *SetConfiguration) *Envelope {
+	now := e.now()
+	envelope := &Envelope{Value: value}
+	if setConfig.TTL > 0 {
+		envelope.ExpiresAt = now.Add(setConfig.TTL + e.retention)
+	}
+	if setConfig.SoftTTL > 0 && (setConfig.TTL <= 0 || setConfig.SoftTTL < setConfig.TTL) {
+		envelope.FreshUntil = now.Add(setConfig.SoftTTL)
+	} else if setConfig.TTL > 0 {
+		envelope.FreshUntil = now.Add(setConfig.TTL)
+	}
+
+	return envelope
+}
+
+// Set stores enveloped value, value with TTL is stored physically for stale retention longer
+func (e *EnvelopeCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	setConfig := &SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+
+	data, err := e.envelope(value, setConfig).MarshalBinary()
+	if err != nil {
+		return keyError(key, err)
+	}
+	if e.retention > 0 && setConfig.TTL > 0 {
+		options = append(options[:len(options):len(options)], WithTTL(setConfig.TTL+e.retention))
+	}
+
+	return e.Cacher.Set(ctx, key, data, options...)
+}
+
+// Get retrieves value whether it is fresh or stale
+// with stale retention, values which are not fresh are returned only if context allows their staleness
+func (e *EnvelopeCacher) Get(ctx context.Context, key string) (any, error) {
+	envelope, err := e.GetEnvelope(ctx, key)
+	if err != nil || envelope == nil {
+		return nil, err
+	}
+	if now := e.now(); e.retention > 0 && !envelope.Fresh(now) && !allowsStale(ctx, now.Sub(envelope.FreshUntil)) {
+		return nil, nil
+	}
+
+	return envelope.Value, nil
+}
+
+// GetEnvelope retrieves value with its freshness
+func (e *EnvelopeCacher) GetEnvelope(ctx context.Context, key string) (*Envelope, error) {
+	value, err := getFound(ctx, e.Cacher, key)
+	if err != nil || value == nil {
+		return nil, err
+	}
+
+	envelope, err := OpenEnvelope(value)
+	if err != nil {
+		return nil, keyError(key, err)
+	}
+
+	return envelope, nil
+}
+
+// Load stores enveloped values which are always fresh
+func (e *EnvelopeCacher) Load(ctx context.Context, data map[string]any) error {
+	enveloped := make(map[string]any, len(data))
+	for key, value := range data {
+		encoded, err := (&Envelope{Value: value}).MarshalBinary()
+		if err != nil {
+			return keyError(key, err)
+		}
+		enveloped[key] = encoded
+	}
+
+	return e.Cacher.Load(ctx, enveloped)
+}
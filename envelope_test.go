@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEnveloped(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		value       any
+		options     []SetOption
+		at          time.Duration
+		wantFresh   bool
+		wantStale   bool
+		wantExpires time.Time
+	}{
+		{name: "test fresh", value: "value", options: []SetOption{WithTTL(10 * time.Minute), WithSoftTTL(time.Minute)}, wantFresh: true, wantExpires: now.Add(10 * time.Minute)},
+		{name: "test stale", value: []byte("value"), options: []SetOption{WithTTL(10 * time.Minute), WithSoftTTL(time.Minute)}, at: 2 * time.Minute, wantStale: true, wantExpires: now.Add(10 * time.Minute)},
+		{name: "test fresh until ttl", value: "value", options: []SetOption{WithTTL(time.Minute)}, at: 59 * time.Second, wantFresh: true, wantExpires: now.Add(time.Minute)},
+		{name: "test soft ttl without ttl", value: map[string]any{"a": "b"}, options: []SetOption{WithSoftTTL(time.Minute)}, at: 2 * time.Minute, wantStale: true},
+		{name: "test always fresh", value: "value", at: time.Hour, wantFresh: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMapCacher()
+			c := Enveloped(m)
+			c.now = func() time.Time { return now }
+
+			if err := c.Set(ctx, "key", tt.value, tt.options...); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if got, _ := c.Get(ctx, "key"); !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("Get() = %v, want %v", got, tt.value)
+			}
+
+			envelope, err := GetEnvelope(ctx, c, "key")
+			if err != nil {
+				t.Fatalf("GetEnvelope() error = %v", err)
+			}
+			if got := envelope.Fresh(now.Add(tt.at)); got != tt.wantFresh {
+				t.Errorf("Envelope.Fresh() = %v, want %v", got, tt.wantFresh)
+			}
+			if got := envelope.Stale(now.Add(tt.at)); got != tt.wantStale {
+				t.Errorf("Envelope.Stale() = %v, want %v", got, tt.wantStale)
+			}
+			if !envelope.ExpiresAt.Equal(tt.wantExpires) {
+				t.Errorf("Envelope.ExpiresAt = %v, want %v", envelope.ExpiresAt, tt.wantExpires)
+			}
+
+			// values read without envelope cacher can be opened
+			opened, err := GetEnvelope(ctx, m, "key")
+			if err != nil || !reflect.DeepEqual(opened, envelope) {
+				t.Errorf("GetEnvelope() = %v, %v, want %v", opened, err, envelope)
+			}
+		})
+	}
+}
+
+func TestEnveloped_StaleRetention(t *testing.T) {
+	ctx := context.Background()
+	now := time.UnixMilli(1_700_000_000_000)
+	c := Enveloped(newMapCacher(), WithStaleRetention(time.Hour))
+	c.now = func() time.Time { return now }
+
+	_ = c.Set(ctx, "key", "value", WithTTL(time.Minute))
+	envelope, err := GetEnvelope(ctx, c, "key")
+	if err != nil || !envelope.FreshUntil.Equal(now.Add(time.Minute)) || !envelope.ExpiresAt.Equal(now.Add(time.Hour+time.Minute)) {
+		t.Fatalf("GetEnvelope() = %+v, %v, want fresh for ttl and expiring after retention", envelope, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if got, _ := c.Get(ctx, "key"); got != nil {
+		t.Errorf("Get() = %v, want value past ttl to miss", got)
+	}
+	if got, _ := c.Get(AllowStale(ctx, 5*time.Minute), "key"); got != "value" {
+		t.Errorf("Get() with AllowStale = %v, want value", got)
+	}
+}
+
+func TestOpenEnvelope(t *testing.T) {
+	if got, err := OpenEnvelope("plain"); err != nil || got.Value != "plain" || !got.Fresh(time.Now()) {
+		t.Errorf("OpenEnvelope() = %v, %v, want fresh plain value", got, err)
+	}
+
+	corrupted := append(append([]byte{}, envelopeMagic...), kindString)
+	if _, err := OpenEnvelope(corrupted); !errors.Is(err, ErrEnvelopeFormat) {
+		t.Errorf("OpenEnvelope() error = %v, want %v", err, ErrEnvelopeFormat)
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+var (
+	// ErrNotModified is returned by GetIfChanged when value still has the given entity tag
+	ErrNotModified = errors.New("value is not modified")
+)
+
+// ETagOf returns entity tag of value, hex encoded hash of its encoding,
+// []byte and string values of the same content have the same tag
+func ETagOf(value any) (string, error) {
+	_, data, err := encodeValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	return etagOf(data), nil
+}
+
+// etagOf returns entity tag of encoded value
+func etagOf(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:16])
+}
+
+// Tag returns entity tag of envelope, computing it from value if it is not stored
+func (e *Envelope) Tag() (string, error) {
+	if e.ETag != "" {
+		return e.ETag, nil
+	}
+
+	return ETagOf(e.Value)
+}
+
+// GetIfChanged retrieves value of key from c with its entity tag
+// if value still has etag, ErrNotModified is returned with the tag and no value
+// nil value with no error means value is not found
+// tags of values stored by enveloped cachers are read from envelope, see Enveloped,
+// tags of other values are computed from them
+func GetIfChanged(ctx context.Context, c Cacher, key string, etag string) (any, string, error) {
+	envelope, err := GetEnvelope(ctx, c, key)
+	if err != nil || envelope == nil {
+		return nil, "", err
+	}
+
+	tag, err := envelope.Tag()
+	if err != nil {
+		return nil, "", keyError(key, err)
+	}
+	if etag != "" && tag == etag {
+		return nil, tag, ErrNotModified
+	}
+
+	return envelope.Value, tag, nil
+}
+
+// GetIfChanged retrieves value with its entity tag, ErrNotModified is returned if value still has etag
+func (e *EnvelopeCacher) GetIfChanged(ctx context.Context, key string, etag string) (any, string, error) {
+	return GetIfChanged(ctx, e, key, etag)
+}
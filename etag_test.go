@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGetIfChanged(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range map[string]Cacher{"test enveloped": Enveloped(newMapCacher()), "test plain": newMapCacher()} {
+		t.Run(name, func(t *testing.T) {
+			_ = c.Set(ctx, "key", "value")
+
+			value, etag, err := GetIfChanged(ctx, c, "key", "")
+			if err != nil || value != "value" {
+				t.Fatalf("GetIfChanged() = %v, %v, want %v", value, err, "value")
+			}
+			if want, _ := ETagOf("value"); etag != want {
+				t.Errorf("GetIfChanged() etag = %v, want %v", etag, want)
+			}
+
+			if value, got, err := GetIfChanged(ctx, c, "key", etag); !errors.Is(err, ErrNotModified) || value != nil || got != etag {
+				t.Errorf("GetIfChanged() = %v, %v, %v, want %v", value, got, err, ErrNotModified)
+			}
+
+			_ = c.Set(ctx, "key", "changed")
+			if value, got, err := GetIfChanged(ctx, c, "key", etag); err != nil || value != "changed" || got == etag {
+				t.Errorf("GetIfChanged() = %v, %v, %v, want changed value with new tag", value, got, err)
+			}
+
+			if value, got, err := GetIfChanged(ctx, c, "missing", etag); err != nil || value != nil || got != "" {
+				t.Errorf("GetIfChanged() = %v, %v, %v, want not found", value, got, err)
+			}
+		})
+	}
+}
+
+func TestEnvelope_Untagged(t *testing.T) {
+	// envelopes stored before entity tags are still opened
+	untagged := append(append([]byte{}, envelopeMagic...), kindString, 0, 0)
+	untagged = append(untagged, "value"...)
+
+	envelope, err := OpenEnvelope(untagged)
+	if err != nil || envelope.Value != "value" || envelope.ETag != "" {
+		t.Fatalf("OpenEnvelope() = %+v, %v, want untagged value", envelope, err)
+	}
+	if got, _ := envelope.Tag(); got != etagOf([]byte("value")) {
+		t.Errorf("Envelope.Tag() = %v, want computed tag", got)
+	}
+}
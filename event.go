@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType is type of cache event
+type EventType int
+
+const (
+	// EventSet is emitted when value is stored
+	EventSet EventType = iota + 1
+	// EventHit is emitted when value is found
+	EventHit
+	// EventMiss is emitted when value is not found
+	EventMiss
+	// EventDelete is emitted when value is deleted
+	EventDelete
+	// EventExpire is emitted when value expires, for backends reporting expiration
+	EventExpire
+	// EventEvictOnError is emitted when cached value is evicted
+	// because it failed to be saved to persistence storage
+	EventEvictOnError
+	// EventDeadLetter is emitted when write to persistence storage failed after all attempts
+	// and is given to dead letter sink
+	EventDeadLetter
+	// EventBypass is emitted when latency guard starts bypassing slow cache
+	// or circuit breaker opens on failing cache
+	EventBypass
+	// EventRecover is emitted when latency guard stops bypassing recovered cache
+	// or circuit breaker closes
+	EventRecover
+	// EventRefresh is emitted when stale value is refreshed in background
+	EventRefresh
+	// EventLoad is emitted instead of EventHit when value missing in cache is loaded
+	// from persistence storage by pattern, e.g. ReadThrough
+	EventLoad
+)
+
+// String returns name of event type
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventHit:
+		return "hit"
+	case EventMiss:
+		return "miss"
+	case EventDelete:
+		return "delete"
+	case EventExpire:
+		return "expire"
+	case EventEvictOnError:
+		return "evict_on_error"
+	case EventDeadLetter:
+		return "dead_letter"
+	case EventBypass:
+		return "bypass"
+	case EventRecover:
+		return "recover"
+	case EventRefresh:
+		return "refresh"
+	case EventLoad:
+		return "load"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is emitted on cache operation
+type Event struct {
+	Type EventType
+	Key  string
+	Time time.Time
+	// Err is the error causing the event, e.g. persistence error on EventEvictOnError
+	Err error
+}
+
+// eventBus dispatches events to subscribers
+type eventBus struct {
+	mu          sync.RWMutex
+	id          int
+	subscribers map[int]func(Event)
+}
+
+// subscribe registers subscriber and returns function to unregister it
+func (b *eventBus) subscribe(subscriber func(Event)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribers == nil {
+		b.subscribers = make(map[int]func(Event))
+	}
+
+	b.id++
+	id := b.id
+	b.subscribers[id] = subscriber
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		delete(b.subscribers, id)
+	}
+}
+
+// publish dispatches event to all subscribers
+func (b *eventBus) publish(event Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	subscribers := make([]func(Event), 0, len(b.subscribers))
+	for _, subscriber := range b.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	b.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber(event)
+	}
+}
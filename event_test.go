@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPatternedCache_Subscribe(t *testing.T) {
+	tests := []struct {
+		name      string
+		pattern   Pattern
+		saveErr   error
+		operation func(*PatternedCache)
+		want      []EventType
+	}{
+		{
+			name:    "test set and hit",
+			pattern: &CacheAside{},
+			operation: func(c *PatternedCache) {
+				_ = c.Set(context.Background(), "key", "value")
+				_, _ = c.Get(context.Background(), "key")
+			},
+			want: []EventType{EventSet, EventHit},
+		},
+		{
+			name:    "test miss and delete",
+			pattern: &CacheAside{},
+			operation: func(c *PatternedCache) {
+				_, _ = c.Get(context.Background(), "key")
+				_ = c.Delete(context.Background(), "key")
+			},
+			want: []EventType{EventMiss, EventDelete},
+		},
+		{
+			name:    "test load and hit",
+			pattern: &ReadThrough{},
+			operation: func(c *PatternedCache) {
+				_ = c.persister.Save(context.Background(), "key", "value")
+				_, _ = c.Get(context.Background(), "key")
+				_, _ = c.Get(context.Background(), "key")
+			},
+			want: []EventType{EventLoad, EventHit},
+		},
+		{
+			name:    "test load and miss of many",
+			pattern: &ReadThrough{},
+			operation: func(c *PatternedCache) {
+				_ = c.persister.Save(context.Background(), "key", "value")
+				_, _ = c.GetMany(context.Background(), []string{"key", "other"})
+			},
+			want: []EventType{EventLoad, EventMiss},
+		},
+		{
+			name:    "test evict on error",
+			pattern: &WriteThrough{},
+			saveErr: errors.New("failed"),
+			operation: func(c *PatternedCache) {
+				_ = c.Set(context.Background(), "key", "value")
+			},
+			want: []EventType{EventEvictOnError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newMapPersister()
+			p.saveErr = tt.saveErr
+			c, _ := New(newMapCacher(), p, WithPattern(tt.pattern))
+
+			var got []EventType
+			c.Subscribe(func(e Event) {
+				got = append(got, e.Type)
+			})
+			tt.operation(c)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Subscribe() events = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternedCache_Unsubscribe(t *testing.T) {
+	c, _ := New(newMapCacher(), nil)
+
+	count := 0
+	unsubscribe := c.Subscribe(func(e Event) {
+		count++
+	})
+	_ = c.Set(context.Background(), "key", "value")
+	unsubscribe()
+	_ = c.Set(context.Background(), "key", "value")
+
+	if count != 1 {
+		t.Errorf("Subscribe() count = %v, want %v", count, 1)
+	}
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// ExistenceIndex is cuckoo filter of keys existing in persistence storage,
+// unlike BloomFilter it forgets deleted keys, so it suits keyspaces with frequent deletions
+// persister wrapped with it keeps index up to date and answers selects of keys
+// which do not exist without round trip, so read-through misses do not reach persistence storage
+// when index is full it stops answering misses, MayExist returns true until it is reset
+type ExistenceIndex struct {
+	filter     *internal.Cuckoo
+	full       atomic.Bool
+	suppressed atomic.Int64
+}
+
+// NewExistenceIndex returns existence index sized for about capacity keys
+func NewExistenceIndex(capacity int) *ExistenceIndex {
+	return &ExistenceIndex{filter: internal.NewCuckoo(capacity)}
+}
+
+// MayExist returns false if key certainly does not exist and true if it may exist
+func (e *ExistenceIndex) MayExist(key string) bool {
+	return e.full.Load() || e.filter.Contains(key)
+}
+
+// Add adds keys to index
+func (e *ExistenceIndex) Add(keys ...string) {
+	for _, key := range keys {
+		if !e.filter.Add(key) {
+			e.full.Store(true)
+		}
+	}
+}
+
+// Remove removes keys from index
+func (e *ExistenceIndex) Remove(keys ...string) {
+	for _, key := range keys {
+		e.filter.Remove(key)
+	}
+}
+
+// Len returns number of keys in index
+func (e *ExistenceIndex) Len() int {
+	return e.filter.Len()
+}
+
+// Suppressed returns number of selects answered by index
+func (e *ExistenceIndex) Suppressed() int64 {
+	return e.suppressed.Load()
+}
+
+// Reset removes all keys from index and adds all keys of persistence storage
+func (e *ExistenceIndex) Reset(ctx context.Context, p Persister) error {
+	data, err := p.SelectAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	e.filter.Reset()
+	e.full.Store(false)
+	for key := range data {
+		e.Add(key)
+	}
+
+	return nil
+}
+
+// Persister returns persister maintaining index with saves and deletes to p
+// and returning nil value without calling p for keys which do not exist
+// index should be populated with Reset before it is used with existing persistence storage
+func (e *ExistenceIndex) Persister(p Persister) Persister {
+	return &existencePersister{Persister: p, index: e}
+}
+
+// existencePersister is persister answering selects of missing keys with existence index
+type existencePersister struct {
+	Persister
+	index *ExistenceIndex
+}
+
+// Save saves key-value to persistence storage and adds key to index
+// key is added before save, so concurrent select does not miss it
+func (p *existencePersister) Save(ctx context.Context, key string, value any) error {
+	p.index.Add(key)
+
+	return p.Persister.Save(ctx, key, value)
+}
+
+// SelectOne selects value from persistence storage if key may exist
+func (p *existencePersister) SelectOne(ctx context.Context, key string) (any, error) {
+	if !p.index.MayExist(key) {
+		p.index.suppressed.Add(1)
+		return nil, nil
+	}
+
+	return p.Persister.SelectOne(ctx, key)
+}
+
+// Delete deletes value from persistence storage and removes key from index
+func (p *existencePersister) Delete(ctx context.Context, key string) error {
+	if err := p.Persister.Delete(ctx, key); err != nil {
+		return err
+	}
+	p.index.Remove(key)
+
+	return nil
+}
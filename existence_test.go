@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExistenceIndex(t *testing.T) {
+	ctx := context.Background()
+	persister := newMapPersister()
+	persister.data["stored"] = "value"
+
+	index := NewExistenceIndex(100)
+	if err := index.Reset(ctx, persister); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+
+	c, _ := New(newMapCacher(), index.Persister(persister), WithPattern(&WriteThrough{}))
+
+	if got, _ := c.Get(ctx, "stored"); got != "value" {
+		t.Errorf("Get(stored) = %v, want %v", got, "value")
+	}
+
+	_ = c.Set(ctx, "written", "value")
+	if !index.MayExist("written") {
+		t.Errorf("MayExist(written) = false, want true")
+	}
+
+	// deleted key is answered by index without select
+	_ = c.Delete(ctx, "stored")
+	persister.data["stored"] = "stale"
+	if index.MayExist("stored") {
+		t.Errorf("MayExist(stored) = true, want false")
+	}
+	if got, _ := c.Get(ctx, "stored"); got != nil {
+		t.Errorf("Get(stored) = %v, want nil", got)
+	}
+	if got := index.Suppressed(); got != 1 {
+		t.Errorf("Suppressed() = %v, want %v", got, 1)
+	}
+	if got := index.Len(); got != 1 {
+		t.Errorf("Len() = %v, want %v", got, 1)
+	}
+}
+
+func TestExistenceIndex_Full(t *testing.T) {
+	index := NewExistenceIndex(4)
+	for i := 0; i < 100; i++ {
+		index.Add(string(rune('a' + i)))
+	}
+
+	if !index.MayExist("unknown") {
+		t.Errorf("MayExist() of full index = false, want true")
+	}
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotExpiryNotifier is returned when cacher can not report expiration of keys
+	ErrNotExpiryNotifier = errors.New("cacher does not report expiration")
+	// ErrNotEvictionNotifier is returned when cacher can not report eviction of keys
+	ErrNotEvictionNotifier = errors.New("cacher does not report eviction")
+)
+
+// EvictionReason is why cacher removed key by itself
+type EvictionReason int
+
+const (
+	// EvictionExpired is removal of key whose ttl elapsed
+	EvictionExpired EvictionReason = iota + 1
+	// EvictionCapacity is removal of key to make room for other keys, e.g. least recently used key
+	// of bounded memory cacher or key evicted by maxmemory policy of redis
+	EvictionCapacity
+)
+
+// String returns name of reason
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionExpired:
+		return "expired"
+	case EvictionCapacity:
+		return "capacity"
+	default:
+		return "unknown"
+	}
+}
+
+// Eviction is key removed by cacher by itself, keys removed by delete are not evicted
+type Eviction struct {
+	Key string
+	// Value is value of key, nil if cacher does not keep it after eviction, e.g. redis
+	Value  any
+	Reason EvictionReason
+}
+
+// EvictionNotifier is implemented by cachers which report keys they remove by themselves with reason,
+// e.g. memory cacher or redis keyspace notifications
+type EvictionNotifier interface {
+	// NotifyEvicted calls fn with every evicted key until returned function is called
+	NotifyEvicted(ctx context.Context, fn func(Eviction)) (func(), error)
+}
+
+// ExpiryNotifier is implemented by cachers which report expiration of keys,
+// e.g. redis keyspace notifications or eviction callbacks of memory cacher
+type ExpiryNotifier interface {
+	// NotifyExpired calls fn with every key which expires until returned function is called
+	NotifyExpired(ctx context.Context, fn func(key string)) (func(), error)
+}
+
+// OnExpire registers fn called with keys which expire from cache and returns function to unregister it,
+// cacher must implement ExpiryNotifier, keys are reported when backend detects expiration,
+// which may be later than their ttl
+func (c *PatternedCache) OnExpire(ctx context.Context, fn func(key string)) (func(), error) {
+	notifier, ok := c.unwrapped().(ExpiryNotifier)
+	if !ok {
+		return nil, ErrNotExpiryNotifier
+	}
+
+	return notifier.NotifyExpired(ctx, fn)
+}
+
+// OnEvict registers fn called with keys which are evicted from cache, with their values and reason,
+// and returns function to unregister it, cacher must implement EvictionNotifier, e.g. to release resources
+// of values or to keep indexes derived from cached keys when keys expire
+func (c *PatternedCache) OnEvict(ctx context.Context, fn func(Eviction)) (func(), error) {
+	notifier, ok := c.unwrapped().(EvictionNotifier)
+	if !ok {
+		return nil, ErrNotEvictionNotifier
+	}
+
+	return notifier.NotifyEvicted(ctx, fn)
+}
+
+// unwrapped returns cacher given to New without wrappers added by options
+func (c *PatternedCache) unwrapped() Cacher {
+	cacher := c.cacher
+	for {
+		switch w := cacher.(type) {
+		case *policyCacher:
+			cacher = w.Cacher
+		case *ttlFuncCacher:
+			cacher = w.Cacher
+		case *registryCacher:
+			cacher = w.Cacher
+		case *keyRulesCacher:
+			cacher = w.Cacher
+		case *keyEncodingCacher:
+			cacher = w.Cacher
+		default:
+			return cacher
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// notifyingCacher is map cacher which reports expiration of keys with expire
+type notifyingCacher struct {
+	*mapCacher
+	listener func(string)
+}
+
+func (n *notifyingCacher) NotifyExpired(_ context.Context, fn func(string)) (func(), error) {
+	n.listener = fn
+	return func() { n.listener = nil }, nil
+}
+
+func (n *notifyingCacher) NotifyEvicted(_ context.Context, fn func(Eviction)) (func(), error) {
+	n.listener = func(key string) { fn(Eviction{Key: key, Reason: EvictionExpired}) }
+	return func() { n.listener = nil }, nil
+}
+
+func (n *notifyingCacher) expire(key string) {
+	delete(n.data, key)
+	if n.listener != nil {
+		n.listener(key)
+	}
+}
+
+func TestPatternedCache_OnExpire(t *testing.T) {
+	notifier := &notifyingCacher{mapCacher: newMapCacher()}
+
+	tests := []struct {
+		name    string
+		cacher  Cacher
+		options []Option
+		wantErr error
+	}{
+		{name: "test notifier", cacher: notifier},
+		{name: "test notifier wrapped by option", cacher: notifier, options: []Option{WithTTLFunc(func(string, any) time.Duration { return time.Minute })}},
+		{name: "test not notifier", cacher: newMapCacher(), wantErr: ErrNotExpiryNotifier},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := New(tt.cacher, nil, tt.options...)
+
+			var expired []string
+			unsubscribe, err := c.OnExpire(context.Background(), func(key string) { expired = append(expired, key) })
+			if err != tt.wantErr {
+				t.Fatalf("OnExpire() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			notifier.expire("a")
+			unsubscribe()
+			notifier.expire("b")
+
+			if len(expired) != 1 || expired[0] != "a" {
+				t.Errorf("OnExpire() expired = %v, want %v", expired, []string{"a"})
+			}
+		})
+	}
+}
+
+func TestPatternedCache_OnEvict(t *testing.T) {
+	notifier := &notifyingCacher{mapCacher: newMapCacher()}
+	c, _ := New(notifier, nil)
+
+	var evicted []Eviction
+	unsubscribe, err := c.OnEvict(context.Background(), func(eviction Eviction) { evicted = append(evicted, eviction) })
+	if err != nil {
+		t.Fatalf("OnEvict() error = %v", err)
+	}
+	notifier.expire("a")
+	unsubscribe()
+	notifier.expire("b")
+
+	if want := []Eviction{{Key: "a", Reason: EvictionExpired}}; len(evicted) != 1 || evicted[0] != want[0] {
+		t.Fatalf("OnEvict() evicted = %v, want %v", evicted, want)
+	}
+	if evicted[0].Reason.String() != "expired" {
+		t.Errorf("Reason.String() = %v, want expired", evicted[0].Reason)
+	}
+
+	c, _ = New(newMapCacher(), nil)
+	if _, err := c.OnEvict(context.Background(), func(Eviction) {}); err != ErrNotEvictionNotifier {
+		t.Errorf("OnEvict() error = %v, want %v", err, ErrNotEvictionNotifier)
+	}
+}
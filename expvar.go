@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"expvar"
+	"sync"
+)
+
+var (
+	// expvarOnce guards creation of expvar map
+	expvarOnce sync.Once
+	// expvarStats is expvar map of published stats keyed by cache name
+	expvarStats *expvar.Map
+)
+
+// PublishExpvar publishes live snapshot of stats under "cache" expvar keyed by stats name,
+// so it is served at /debug/vars, publishing stats with name already published replaces it
+func PublishExpvar(stats *Stats) {
+	expvarOnce.Do(func() {
+		expvarStats = expvar.NewMap("cache")
+	})
+
+	name := stats.Name()
+	if name == "" {
+		name = "default"
+	}
+
+	expvarStats.Set(name, expvar.Func(func() any {
+		return stats.Snapshot()
+	}))
+}
@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	stats := NewStats("orders")
+	PublishExpvar(stats)
+	PublishExpvar(NewStats(""))
+	stats.Observe(OpGet, ResultHit, time.Millisecond)
+
+	published := map[string]StatsSnapshot{}
+	if err := json.Unmarshal([]byte(expvar.Get("cache").String()), &published); err != nil {
+		t.Fatalf("expvar cache = %v, error = %v", expvar.Get("cache"), err)
+	}
+
+	if got := published["orders"].Hits; got != 1 {
+		t.Errorf("PublishExpvar() hits = %v, want %v", got, 1)
+	}
+	if _, ok := published["default"]; !ok {
+		t.Errorf("PublishExpvar() published = %v, want default", published)
+	}
+}
@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Fetch returns value of key from cache, or calls loader on miss and stores its value to cache
+// concurrent fetches of the same key which miss share a single call of loader, so a missing key
+// does not cause a stampede on persistence storage, context of the first caller is used for the shared load
+// value of loader is stored to cache only, persistence storage is not written by pattern,
+// errors of loader are returned and not cached, nil value of loader is not stored
+func (c *PatternedCache) Fetch(ctx context.Context, key string, loader func(context.Context) (any, error), options ...SetOption) (any, error) {
+	value, err := c.Get(ctx, key)
+	if err == nil && value != nil {
+		return value, nil
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		c.scope.logger.Error(ctx, "failed to get value from cache", "fetch", key, err)
+	}
+
+	value, err, _ = c.group.Do(key, func() (any, error) {
+		start := time.Now()
+
+		// value may be stored by fetch which completed after get above missed
+		if !skipped(ctx) && !refreshed(ctx) {
+			if value, err := getFound(ctx, c.cacher, key); err == nil && value != nil {
+				return value, nil
+			}
+		}
+
+		value, err := loader(ctx)
+		if err != nil || value == nil || skipped(ctx) {
+			return value, err
+		}
+
+		if err := c.cacher.Set(withScope(ctx, c.scope), key, value, options...); err != nil {
+			c.scope.logger.Error(ctx, "failed to set value to cache", "fetch", key, err)
+		} else {
+			c.scope.events.publish(Event{Type: EventSet, Key: key, Time: start})
+		}
+
+		return value, nil
+	})
+	if err == nil && value == nil && c.notFound {
+		return nil, ErrNotFound
+	}
+
+	return value, err
+}
+
+// GetOrSet is alias of Fetch
+func (c *PatternedCache) GetOrSet(ctx context.Context, key string, loader func(context.Context) (any, error), options ...SetOption) (any, error) {
+	return c.Fetch(ctx, key, loader, options...)
+}
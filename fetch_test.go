@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCacher()
+	pc, err := New(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loads int
+	loader := func(context.Context) (any, error) {
+		loads++
+		return "loaded", nil
+	}
+
+	for i := 0; i < 2; i++ {
+		value, err := pc.Fetch(ctx, "key", loader, WithTTL(time.Minute))
+		if err != nil || value != "loaded" {
+			t.Fatalf("Fetch() = %v, %v, want loaded", value, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loader called %d times, want 1", loads)
+	}
+	if c.data["key"] != "loaded" {
+		t.Errorf("cached value = %v, want loaded", c.data["key"])
+	}
+
+	failure := errors.New("source down")
+	_, err = pc.Fetch(ctx, "other", func(context.Context) (any, error) { return nil, failure })
+	if !errors.Is(err, failure) {
+		t.Errorf("Fetch() error = %v, want %v", err, failure)
+	}
+	if _, ok := c.data["other"]; ok {
+		t.Error("error of loader is cached")
+	}
+}
+
+func TestFetchSingleflight(t *testing.T) {
+	ctx := context.Background()
+	pc, err := New(newMapCacher(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	loader := func(context.Context) (any, error) {
+		loads.Add(1)
+		<-release
+		return "loaded", nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, err := pc.Fetch(ctx, "key", loader); err != nil || value != "loaded" {
+				t.Errorf("Fetch() = %v, %v, want loaded", value, err)
+			}
+		}()
+	}
+
+	// give concurrent fetches time to join the in-flight load
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loader called %d times, want 1", n)
+	}
+}
+
+func TestFetchCacheError(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCacher()
+	c.getErr = errors.New("cache down")
+	pc, err := New(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := pc.Fetch(ctx, "key", func(context.Context) (any, error) { return "loaded", nil })
+	if err != nil || value != "loaded" {
+		t.Errorf("Fetch() = %v, %v, want loaded", value, err)
+	}
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFieldCacher is returned when cacher can not access fields of values
+var ErrNotFieldCacher = errors.New("cacher does not support fields")
+
+// FieldCacher is implemented by cachers which store structured values by fields, e.g. redis cacher
+// with hash storage, so one field of large value is read or updated without the whole value
+type FieldCacher interface {
+	// GetField retrieves value of field of key, nil is returned if key or field does not exist
+	GetField(ctx context.Context, key, field string) (any, error)
+	// SetField sets value of field of key, key which does not exist is created with ttl of options,
+	// ttl of existing key is kept
+	SetField(ctx context.Context, key, field string, value any, options ...SetOption) error
+	// DeleteField deletes field of key
+	DeleteField(ctx context.Context, key, field string) error
+}
+
+// GetField retrieves value of field of key from c, c must implement FieldCacher
+func GetField(ctx context.Context, c Cacher, key, field string) (any, error) {
+	fields, ok := c.(FieldCacher)
+	if !ok {
+		return nil, ErrNotFieldCacher
+	}
+
+	return fields.GetField(ctx, key, field)
+}
+
+// SetField sets value of field of key in c, c must implement FieldCacher
+func SetField(ctx context.Context, c Cacher, key, field string, value any, options ...SetOption) error {
+	fields, ok := c.(FieldCacher)
+	if !ok {
+		return ErrNotFieldCacher
+	}
+
+	return fields.SetField(ctx, key, field, value, options...)
+}
+
+// DeleteField deletes field of key from c, c must implement FieldCacher
+func DeleteField(ctx context.Context, c Cacher, key, field string) error {
+	fields, ok := c.(FieldCacher)
+	if !ok {
+		return ErrNotFieldCacher
+	}
+
+	return fields.DeleteField(ctx, key, field)
+}
@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFields_NotFieldCacher(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := GetField(ctx, newMapCacher(), "key", "field"); !errors.Is(err, ErrNotFieldCacher) {
+		t.Errorf("GetField() error = %v, want %v", err, ErrNotFieldCacher)
+	}
+	if err := SetField(ctx, newMapCacher(), "key", "field", "value"); !errors.Is(err, ErrNotFieldCacher) {
+		t.Errorf("SetField() error = %v, want %v", err, ErrNotFieldCacher)
+	}
+	if err := DeleteField(ctx, newMapCacher(), "key", "field"); !errors.Is(err, ErrNotFieldCacher) {
+		t.Errorf("DeleteField() error = %v, want %v", err, ErrNotFieldCacher)
+	}
+}
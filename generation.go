@@ -0,0 +1,294 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// generationPrefix is default prefix of keys storing generations of namespaces
+const generationPrefix = "__generation."
+
+// ErrNotAllInvalidator is returned when cacher can not invalidate all keys at once
+var ErrNotAllInvalidator = errors.New("cacher does not support invalidating all keys")
+
+// AllInvalidator is implemented by cachers which invalidate all their keys at once, e.g. GenerationCacher
+// with WithGenerationName
+type AllInvalidator interface {
+	// InvalidateAll makes all keys unreachable
+	InvalidateAll(ctx context.Context) error
+}
+
+// generation is generation of namespace read from cache
+type generation struct {
+	value  uint64
+	readAt time.Time
+}
+
+// GenerationCacher is cacher invalidating namespaces by bumping their generation
+// keys of namespace are stored with its current generation, e.g. "orders.42" as "orders@7.42",
+// so after FlushNamespace old keys are unreachable at once and expire by their TTL
+// generations are stored in cache, so all instances sharing it see flushes
+type GenerationCacher struct {
+	Cacher
+	namespace func(key string) (string, string, bool)
+	name      string
+	prefix    string
+	refresh   time.Duration
+	now       func() time.Time
+
+	mu          sync.Mutex
+	generations map[string]generation
+}
+
+// GenerationOption provides generation options
+type GenerationOption func(*GenerationCacher)
+
+// WithGenerationNamespace returns option to set function splitting key into namespace and rest of key,
+// false is returned for keys without namespace, which are stored as is
+// default namespace is part of key before first dot, e.g. "orders" of "orders.42"
+func WithGenerationNamespace(namespace func(key string) (string, string, bool)) GenerationOption {
+	return func(g *GenerationCacher) {
+		g.namespace = namespace
+	}
+}
+
+// WithGenerationName returns option to mix generation of name into all keys, e.g. "42" is stored as "orders@7:42",
+// so InvalidateAll makes all keys unreachable at once by bumping it, keys of namespaces have both generations
+func WithGenerationName(name string) GenerationOption {
+	return func(g *GenerationCacher) {
+		g.name = name
+	}
+}
+
+// WithGenerationPrefix returns option to set prefix of keys storing generations, default is "__generation."
+func WithGenerationPrefix(prefix string) GenerationOption {
+	return func(g *GenerationCacher) {
+		g.prefix = prefix
+	}
+}
+
+// WithGenerationRefresh returns option to set how long generation read from cache is used
+// before it is read again, flushes by other instances are seen after it, default is 1 second
+func WithGenerationRefresh(refresh time.Duration) GenerationOption {
+	return func(g *GenerationCacher) {
+		g.refresh = refresh
+	}
+}
+
+// Generations returns cacher storing keys of namespaces with their generation in c
+// generation keys should not expire, generation of namespace whose generation key is lost
+// is started from current time, so old keys stay unreachable
+func Generations(c Cacher, options ...GenerationOption) *GenerationCacher {
+	g := &GenerationCacher{
+		Cacher: c,
+		namespace: func(key string) (string, string, bool) {
+			return strings.Cut(key, ".")
+		},
+		prefix:      generationPrefix,
+		refresh:     time.Second,
+		now:         time.Now,
+		generations: map[string]generation{},
+	}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	return g
+}
+
+// Generation returns current generation of namespace
+func (g *GenerationCacher) Generation(ctx context.Context, namespace string) (uint64, error) {
+	g.mu.Lock()
+	cached, ok := g.generations[namespace]
+	g.mu.Unlock()
+	if ok && g.now().Sub(cached.readAt) < g.refresh {
+		return cached.value, nil
+	}
+
+	value, err := g.read(ctx, namespace)
+	if err != nil {
+		return 0, err
+	}
+	if value == 0 {
+		value = uint64(g.now().UnixNano())
+		if err := g.Cacher.Set(ctx, g.prefix+namespace, strconv.FormatUint(value, 10)); err != nil {
+			return 0, err
+		}
+	}
+	g.remember(namespace, value)
+
+	return value, nil
+}
+
+// read reads generation of namespace from cache, 0 means it is not stored
+func (g *GenerationCacher) read(ctx context.Context, namespace string) (uint64, error) {
+	stored, err := g.Cacher.Get(ctx, g.prefix+namespace)
+	if err != nil || stored == nil {
+		return 0, err
+	}
+
+	var text string
+	switch v := stored.(type) {
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	value, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return 0, keyError(g.prefix+namespace, fmt.Errorf("invalid generation: %w", err))
+	}
+
+	return value, nil
+}
+
+// remember caches generation of namespace
+func (g *GenerationCacher) remember(namespace string, value uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.generations[namespace] = generation{value: value, readAt: g.now()}
+}
+
+// FlushNamespace makes all keys of namespace unreachable by bumping its generation
+// old keys are not deleted and expire by their TTL
+func (g *GenerationCacher) FlushNamespace(ctx context.Context, namespace string) error {
+	return g.bump(ctx, namespace)
+}
+
+// InvalidateAll makes all keys unreachable by bumping generation of name, see WithGenerationName
+// old keys are not deleted and expire by their TTL
+func (g *GenerationCacher) InvalidateAll(ctx context.Context) error {
+	if g.name == "" {
+		return fmt.Errorf("%w: generation name is not set", ErrInvalidOption)
+	}
+
+	return g.bump(ctx, g.allNamespace())
+}
+
+// allNamespace returns namespace of generation of name, which is not namespace of any key
+func (g *GenerationCacher) allNamespace() string {
+	return "@" + g.name
+}
+
+// bump bumps generation of namespace
+func (g *GenerationCacher) bump(ctx context.Context, namespace string) error {
+	current, err := g.read(ctx, namespace)
+	if err != nil {
+		return err
+	}
+
+	next := uint64(g.now().UnixNano())
+	if next <= current {
+		next = current + 1
+	}
+	if err := g.Cacher.Set(ctx, g.prefix+namespace, strconv.FormatUint(next, 10)); err != nil {
+		return err
+	}
+	g.remember(namespace, next)
+
+	return nil
+}
+
+// key returns key of current generation of namespace of key and of name
+func (g *GenerationCacher) key(ctx context.Context, key string) (string, error) {
+	if namespace, rest, ok := g.namespace(key); ok {
+		value, err := g.Generation(ctx, namespace)
+		if err != nil {
+			return "", err
+		}
+		key = namespace + "@" + strconv.FormatUint(value, 10) + "." + rest
+	}
+
+	if g.name == "" {
+		return key, nil
+	}
+
+	value, err := g.Generation(ctx, g.allNamespace())
+	if err != nil {
+		return "", err
+	}
+
+	return g.name + "@" + strconv.FormatUint(value, 10) + ":" + key, nil
+}
+
+// Set sets key-value of current generation to cache
+func (g *GenerationCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	generated, err := g.key(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return g.Cacher.Set(ctx, generated, value, options...)
+}
+
+// Get gets value of current generation from cache
+func (g *GenerationCacher) Get(ctx context.Context, key string) (any, error) {
+	generated, err := g.key(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.Cacher.Get(ctx, generated)
+}
+
+// Delete deletes value of current generation from cache
+func (g *GenerationCacher) Delete(ctx context.Context, key string) error {
+	generated, err := g.key(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	return g.Cacher.Delete(ctx, generated)
+}
+
+// Load loads key-values of current generation into cache
+func (g *GenerationCacher) Load(ctx context.Context, data map[string]any) error {
+	var errs []error
+	generated := make(map[string]any, len(data))
+	for key, value := range data {
+		k, err := g.key(ctx, key)
+		if err != nil {
+			errs = append(errs, keyError(key, err))
+			continue
+		}
+		generated[k] = value
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return g.Cacher.Load(ctx, generated)
+}
+
+// InvalidateAll makes all keys of c unreachable at once, c must implement AllInvalidator
+func InvalidateAll(ctx context.Context, c Cacher) error {
+	invalidator, ok := c.(AllInvalidator)
+	if !ok {
+		return ErrNotAllInvalidator
+	}
+
+	return invalidator.InvalidateAll(ctx)
+}
+
+// InvalidateAll makes all keys of cache unreachable at once, persistence storage is not changed,
+// cacher must implement AllInvalidator, e.g. Generations(c, WithGenerationName(name))
+func (c *PatternedCache) InvalidateAll(ctx context.Context) error {
+	ctx = withScope(ctx, c.scope)
+
+	err := InvalidateAll(ctx, c.unwrapped())
+	if err != nil && !errors.Is(err, ErrNotAllInvalidator) {
+		loggerFrom(ctx).Error(ctx, "failed to invalidate all keys", "invalidate_all", "", err)
+	}
+
+	return err
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGenerations(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	shared := newMapCacher()
+
+	a := Generations(shared)
+	a.now = func() time.Time { return now }
+	b := Generations(shared, WithGenerationRefresh(time.Minute))
+	b.now = func() time.Time { return now }
+
+	_ = a.Set(ctx, "orders.1", "one")
+	_ = a.Set(ctx, "users.1", "user")
+	_ = a.Set(ctx, "plain", "value")
+
+	if got, _ := b.Get(ctx, "orders.1"); got != "one" {
+		t.Fatalf("Get() = %v, want %v", got, "one")
+	}
+	if got := shared.data["plain"]; got != "value" {
+		t.Errorf("key without namespace = %v, want stored as is", got)
+	}
+	stored := len(shared.data)
+
+	if err := a.FlushNamespace(ctx, "orders"); err != nil {
+		t.Fatalf("FlushNamespace() error = %v", err)
+	}
+	if got, _ := a.Get(ctx, "orders.1"); got != nil {
+		t.Errorf("Get() after flush = %v, want nil", got)
+	}
+	if got, _ := a.Get(ctx, "users.1"); got != "user" {
+		t.Errorf("Get() of other namespace = %v, want %v", got, "user")
+	}
+	if len(shared.data) != stored {
+		t.Errorf("FlushNamespace() stored keys = %v, want %v", len(shared.data), stored)
+	}
+
+	// other instance sees flush after refresh
+	if got, _ := b.Get(ctx, "orders.1"); got != "one" {
+		t.Errorf("Get() of other instance before refresh = %v, want %v", got, "one")
+	}
+	now = now.Add(time.Minute)
+	if got, _ := b.Get(ctx, "orders.1"); got != nil {
+		t.Errorf("Get() of other instance after refresh = %v, want nil", got)
+	}
+
+	// lost generation is started again without exposing old keys
+	_ = a.Set(ctx, "orders.1", "two")
+	delete(shared.data, generationPrefix+"orders")
+	now = now.Add(time.Minute)
+	if got, _ := a.Get(ctx, "orders.1"); got != nil {
+		t.Errorf("Get() after lost generation = %v, want nil", got)
+	}
+}
+
+func TestGenerations_InvalidateAll(t *testing.T) {
+	ctx := context.Background()
+	shared := newMapCacher()
+	g := Generations(shared, WithGenerationName("orders"))
+	c, _ := New(g, nil)
+
+	_ = c.Set(ctx, "orders.1", "one")
+	_ = c.Set(ctx, "plain", "value")
+	if got, _ := c.Get(ctx, "plain"); got != "value" {
+		t.Fatalf("Get() = %v, want %v", got, "value")
+	}
+
+	if err := c.InvalidateAll(ctx); err != nil {
+		t.Fatalf("InvalidateAll() error = %v", err)
+	}
+	for _, key := range []string{"orders.1", "plain"} {
+		if got, _ := c.Get(ctx, key); got != nil {
+			t.Errorf("Get(%s) after invalidation = %v, want nil", key, got)
+		}
+	}
+
+	_ = c.Set(ctx, "plain", "new")
+	if got, _ := c.Get(ctx, "plain"); got != "new" {
+		t.Errorf("Get() = %v, want %v", got, "new")
+	}
+
+	if err := Generations(newMapCacher()).InvalidateAll(ctx); !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("InvalidateAll() error = %v, want %v", err, ErrInvalidOption)
+	}
+	if err := InvalidateAll(ctx, newMapCacher()); !errors.Is(err, ErrNotAllInvalidator) {
+		t.Errorf("InvalidateAll() error = %v, want %v", err, ErrNotAllInvalidator)
+	}
+}
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// GobCodec is codec of encoding/gob, compact and Go native encoding for Go services sharing a cache
+// values are encoded as interface values, so their types must be registered with RegisterGob
+// on both encoding and decoding side, basic types, e.g. int, string and []byte, need no registration
+type GobCodec struct{}
+
+// RegisterGob registers types of values with gob, e.g. RegisterGob(User{}, &Order{})
+func RegisterGob(values ...any) {
+	for _, value := range values {
+		gob.Register(value)
+	}
+}
+
+// Marshal encodes value with gob
+func (GobCodec) Marshal(value any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal decodes gob data into target
+func (GobCodec) Unmarshal(data []byte, target any) error {
+	var value any
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return err
+	}
+
+	return assign(target, value)
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGobCodec(t *testing.T) {
+	RegisterGob(typedValue{})
+
+	tests := []struct {
+		name      string
+		prototype any
+		value     any
+		wantErr   bool
+	}{
+		{name: "test registered struct", prototype: typedValue{}, value: typedValue{Number: 1, Text: "one"}},
+		{name: "test basic type", prototype: "", value: "text"},
+		{name: "test any", value: []byte("bytes")},
+		{name: "test unregistered type", value: struct{ A int }{A: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := CodecMarshaller(GobCodec{}, tt.prototype)
+
+			data, err := m.Marshal(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Marshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := m.Unmarshal(data)
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("Unmarshal() = %#v, want %#v", got, tt.value)
+			}
+		})
+	}
+}
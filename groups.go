@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotGrouper is returned when cacher can not keep groups of keys
+var ErrNotGrouper = errors.New("cacher does not support groups")
+
+// Grouper is implemented by cachers which keep groups of keys, e.g. memory and redis cachers,
+// groups record one-to-many relations, e.g. keys of orders of user in group "user:42:orders"
+// members are kept in order they are added, members whose values expire or are deleted stay in group
+// until they are removed and are skipped when values of group are read
+type Grouper interface {
+	// AddToGroup adds key to group, or moves it to the end if it is a member, group is kept as long as
+	// its members, it expires after the longest ttl of options of its additions and does not expire
+	// once key is added without ttl, options without ttl use ttl of cacher
+	AddToGroup(ctx context.Context, group, key string, options ...SetOption) error
+	// RemoveFromGroup removes keys from group, values of keys are kept
+	RemoveFromGroup(ctx context.Context, group string, keys ...string) error
+	// GroupMembers returns keys of group in order they are added, nil is returned if group does not exist
+	GroupMembers(ctx context.Context, group string) ([]string, error)
+	// InvalidateGroup deletes values of members of group and group
+	InvalidateGroup(ctx context.Context, group string) error
+}
+
+// AddToGroup adds key to group of c, c must implement Grouper
+func AddToGroup(ctx context.Context, c Cacher, group, key string, options ...SetOption) error {
+	grouper, ok := c.(Grouper)
+	if !ok {
+		return ErrNotGrouper
+	}
+
+	return grouper.AddToGroup(ctx, group, key, options...)
+}
+
+// GetGroup returns values of members of group of c which are found, c must implement Grouper
+func GetGroup(ctx context.Context, c Cacher, group string) (map[string]any, error) {
+	grouper, ok := c.(Grouper)
+	if !ok {
+		return nil, ErrNotGrouper
+	}
+
+	members, err := grouper.GroupMembers(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetMany(ctx, c, members)
+}
+
+// AddToGroup adds key to group, cacher must implement Grouper
+func (c *PatternedCache) AddToGroup(ctx context.Context, group, key string, options ...SetOption) error {
+	ctx = withScope(ctx, c.scope)
+
+	err := AddToGroup(ctx, c.unwrapped(), group, key, options...)
+	if err != nil && !errors.Is(err, ErrNotGrouper) {
+		loggerFrom(ctx).Error(ctx, "failed to add key to group", "add_to_group", key, err)
+	}
+
+	return err
+}
+
+// GetGroup returns values of members of group, values missing from cache are read by pattern like GetMany,
+// cacher must implement Grouper
+func (c *PatternedCache) GetGroup(ctx context.Context, group string) (map[string]any, error) {
+	grouper, ok := c.unwrapped().(Grouper)
+	if !ok {
+		return nil, ErrNotGrouper
+	}
+
+	scoped := withScope(ctx, c.scope)
+	members, err := grouper.GroupMembers(scoped, group)
+	if err != nil {
+		loggerFrom(scoped).Error(scoped, "failed to get members of group", "group_members", group, err)
+		return nil, err
+	}
+
+	return c.GetMany(ctx, members)
+}
+
+// InvalidateGroup deletes values of members of group and group from cache, values are not deleted
+// from persistence storage, cacher must implement Grouper
+func (c *PatternedCache) InvalidateGroup(ctx context.Context, group string) error {
+	grouper, ok := c.unwrapped().(Grouper)
+	if !ok {
+		return ErrNotGrouper
+	}
+
+	ctx = withScope(ctx, c.scope)
+	if err := grouper.InvalidateGroup(ctx, group); err != nil {
+		loggerFrom(ctx).Error(ctx, "failed to invalidate group", "invalidate_group", group, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGroups_NotGrouper(t *testing.T) {
+	ctx := context.Background()
+	c, _ := New(newMapCacher(), nil)
+
+	if err := c.AddToGroup(ctx, "group", "key"); !errors.Is(err, ErrNotGrouper) {
+		t.Errorf("AddToGroup() error = %v, want %v", err, ErrNotGrouper)
+	}
+	if _, err := c.GetGroup(ctx, "group"); !errors.Is(err, ErrNotGrouper) {
+		t.Errorf("GetGroup() error = %v, want %v", err, ErrNotGrouper)
+	}
+	if err := c.InvalidateGroup(ctx, "group"); !errors.Is(err, ErrNotGrouper) {
+		t.Errorf("InvalidateGroup() error = %v, want %v", err, ErrNotGrouper)
+	}
+	if _, err := GetGroup(ctx, newMapCacher(), "group"); !errors.Is(err, ErrNotGrouper) {
+		t.Errorf("GetGroup() error = %v, want %v", err, ErrNotGrouper)
+	}
+}
@@ -0,0 +1,4 @@
+// Package cachepb contains generated protobuf messages and gRPC service of cache server
+package cachepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative cache.proto
@@ -0,0 +1,185 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/grpc/cachepb"
+	"github.com/albinzx/cache/internal"
+	gogrpc "google.golang.org/grpc"
+)
+
+// Cacher is cache implementation with gRPC cache service
+type Cacher struct {
+	conn       *gogrpc.ClientConn
+	client     cachepb.CacheClient
+	name       string
+	ttl        time.Duration
+	marshaller cache.Marshaller
+	closeConn  bool
+
+	slogger       *slog.Logger
+	logLevel      slog.Level
+	errorLogLevel slog.Level
+	logger        *internal.Logger
+}
+
+// Option provides gRPC cacher options
+type Option func(*Cacher)
+
+// New returns new gRPC cacher of cache with name served through connection
+func New(conn *gogrpc.ClientConn, name string, options ...Option) *Cacher {
+	gcache := &Cacher{
+		conn:          conn,
+		client:        cachepb.NewCacheClient(conn),
+		name:          name,
+		closeConn:     true,
+		logLevel:      slog.LevelDebug,
+		errorLogLevel: slog.LevelWarn,
+	}
+
+	for _, option := range options {
+		option(gcache)
+	}
+
+	gcache.logger = internal.NewLogger(gcache.slogger, gcache.logLevel, gcache.errorLogLevel).With("backend", "grpc")
+
+	return gcache
+}
+
+func (c *Cacher) Set(ctx context.Context, key string, value any, setOptions ...cache.SetOption) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "set", key, start, err) }(time.Now())
+
+	setConfig := &cache.SetConfiguration{
+		TTL: c.ttl}
+	for _, option := range setOptions {
+		option(setConfig)
+	}
+
+	encoded, err := c.encode(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.client.Set(ctx, &cachepb.SetRequest{Name: c.name, Key: key, Value: encoded, TtlMillis: setConfig.TTL.Milliseconds()})
+
+	return err
+}
+
+func (c *Cacher) Get(ctx context.Context, key string) (_ any, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "get", key, start, err) }(time.Now())
+
+	resp, err := c.client.Get(ctx, &cachepb.GetRequest{Name: c.name, Key: key})
+	if err != nil || !resp.GetFound() {
+		return nil, err
+	}
+
+	if c.marshaller != nil {
+		return c.marshaller.Unmarshal(resp.GetValue())
+	}
+
+	return resp.GetValue(), nil
+}
+
+func (c *Cacher) Delete(ctx context.Context, key string) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "delete", key, start, err) }(time.Now())
+
+	_, err = c.client.Delete(ctx, &cachepb.DeleteRequest{Name: c.name, Key: key})
+
+	return err
+}
+
+func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
+	values := make(map[string][]byte, len(data))
+
+	for key, value := range data {
+		encoded, err := c.encode(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = encoded
+	}
+
+	_, err := c.client.Load(ctx, &cachepb.LoadRequest{Name: c.name, Values: values})
+
+	return err
+}
+
+// Ping checks connectivity of cache service and its cache
+func (c *Cacher) Ping(ctx context.Context) error {
+	_, err := c.client.Ping(ctx, &cachepb.PingRequest{Name: c.name})
+
+	return err
+}
+
+func (c *Cacher) Close() error {
+	if c.closeConn {
+		return c.conn.Close()
+	}
+
+	return nil
+}
+
+// encode returns value as bytes, marshalled if marshaller is set
+func (c *Cacher) encode(value any) ([]byte, error) {
+	if c.marshaller != nil {
+		return c.marshaller.Marshal(value)
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, ErrNotBytes
+	}
+}
+
+// WithSharedConnection returns option to keep connection open when this cacher is closed
+func WithSharedConnection() Option {
+	return func(cache *Cacher) {
+		cache.closeConn = false
+	}
+}
+
+// WithTTL returns option to set global TTL
+func WithTTL(ttl time.Duration) Option {
+	return func(cache *Cacher) {
+		cache.ttl = ttl
+	}
+}
+
+// WithMarshaller returns option to set marshaller
+// without marshaller, values must be bytes or string and are retrieved as bytes
+func WithMarshaller(marshaller cache.Marshaller) Option {
+	return func(cache *Cacher) {
+		cache.marshaller = marshaller
+	}
+}
+
+// WithCodec returns option to encode values with codec and decode them to type of prototype,
+// e.g. WithCodec(cache.JSONCodec{}, User{})
+func WithCodec(codec cache.Codec, prototype any) Option {
+	return WithMarshaller(cache.CodecMarshaller(codec, prototype))
+}
+
+// WithLogger returns option to set structured logger
+// by default nothing is logged
+func WithLogger(logger *slog.Logger) Option {
+	return func(cache *Cacher) {
+		cache.slogger = logger
+	}
+}
+
+// WithLogLevel returns option to set log level of successful operations
+// and of failed operations, defaults are debug and warn level
+func WithLogLevel(level, errorLevel slog.Level) Option {
+	return func(cache *Cacher) {
+		cache.logLevel = level
+		cache.errorLogLevel = errorLevel
+	}
+}
@@ -0,0 +1,141 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/grpc/cachepb"
+	"github.com/albinzx/cache/memory"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestCacher returns cacher of cache with name served by in-memory gRPC server
+func newTestCacher(t *testing.T, name string, options ...Option) *Cacher {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := gogrpc.NewServer()
+	s := NewServer()
+	s.Register("orders", memory.New())
+	s.Register("strict", memory.New(memory.WithNotFoundError()))
+	cachepb.RegisterCacheServer(server, s)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := gogrpc.NewClient("passthrough:///bufconn",
+		gogrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		gogrpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	c := New(conn, name, options...)
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c
+}
+
+func TestCacher(t *testing.T) {
+	c := newTestCacher(t, "orders", WithTTL(time.Minute))
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "a", "one"); err != nil {
+		t.Errorf("Set() error = %v", err)
+	}
+	if err := c.Set(ctx, "b", 2); !errors.Is(err, ErrNotBytes) {
+		t.Errorf("Set() error = %v, want %v", err, ErrNotBytes)
+	}
+	if err := c.Load(ctx, map[string]any{"b": []byte("two"), "c": "three"}); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want any
+	}{
+		{key: "a", want: []byte("one")},
+		{key: "b", want: []byte("two")},
+		{key: "x", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := c.Get(ctx, tt.key)
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := c.Delete(ctx, "a"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if got, _ := c.Get(ctx, "a"); got != nil {
+		t.Errorf("Get() = %v, want %v", got, nil)
+	}
+	if err := c.Ping(ctx); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+}
+
+func TestCacher_UnknownCache(t *testing.T) {
+	c := newTestCacher(t, "users")
+
+	if _, err := c.Get(context.Background(), "a"); status.Code(err) != codes.NotFound {
+		t.Errorf("Get() error = %v, want %v", err, codes.NotFound)
+	}
+}
+
+func TestCacher_NotFoundError(t *testing.T) {
+	c := newTestCacher(t, "strict")
+
+	// missing key of cache returning cache.ErrNotFound is not found, not error of unknown cache
+	if got, err := c.Get(context.Background(), "a"); err != nil || got != nil {
+		t.Errorf("Get() = %v, %v, want nil", got, err)
+	}
+}
+
+func TestCacher_LoadNotBytes(t *testing.T) {
+	c := newTestCacher(t, "orders")
+
+	if err := c.Load(context.Background(), map[string]any{"a": "one", "b": 2}); !errors.Is(err, ErrNotBytes) {
+		t.Errorf("Load() error = %v, want %v", err, ErrNotBytes)
+	}
+	if got, _ := c.Get(context.Background(), "a"); got != nil {
+		t.Errorf("Get() = %v, want %v", got, nil)
+	}
+}
+
+func Test_statusOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "test not found", err: fmt.Errorf("get: %w", cache.ErrNotFound), want: codes.NotFound},
+		{name: "test invalid key", err: cache.ErrInvalidKey, want: codes.InvalidArgument},
+		{name: "test quota exceeded", err: cache.ErrQuotaExceeded, want: codes.ResourceExhausted},
+		{name: "test circuit open", err: cache.ErrCircuitOpen, want: codes.Unavailable},
+		{name: "test deadline", err: context.DeadlineExceeded, want: codes.DeadlineExceeded},
+		{name: "test other", err: errors.New("connection refused"), want: codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(statusOf(tt.err)); got != tt.want {
+				t.Errorf("statusOf() code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
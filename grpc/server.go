@@ -0,0 +1,204 @@
+// Package grpc exposes caches as gRPC service and provides cacher client of the service,
+// so caches can run as dedicated sidecar or service
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/grpc/cachepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotBytes is returned when value is neither bytes nor string and no marshaller is set
+	ErrNotBytes = errors.New("value is not bytes")
+)
+
+// loader is implemented by caches which store multiple key-values at once, e.g. Cacher
+type loader interface {
+	Load(context.Context, map[string]any) error
+}
+
+// healthChecker is implemented by caches which check health of their components, e.g. PatternedCache
+type healthChecker interface {
+	Health(context.Context) error
+}
+
+// Server is gRPC cache service serving registered caches
+type Server struct {
+	cachepb.UnimplementedCacheServer
+
+	mu     sync.RWMutex
+	caches map[string]cache.Cache
+}
+
+// NewServer returns gRPC cache service, register it with cachepb.RegisterCacheServer
+func NewServer() *Server {
+	return &Server{caches: make(map[string]cache.Cache)}
+}
+
+// Register registers cache with name, registering existing name replaces it
+func (s *Server) Register(name string, c cache.Cache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.caches[name] = c
+}
+
+// Unregister removes cache with name
+func (s *Server) Unregister(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.caches, name)
+}
+
+// cache returns registered cache by name
+func (s *Server) cache(name string) (cache.Cache, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.caches[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "cache %s not found", name)
+	}
+
+	return c, nil
+}
+
+// Get retrieves value of key
+func (s *Server) Get(ctx context.Context, req *cachepb.GetRequest) (*cachepb.GetResponse, error) {
+	c, err := s.cache(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	// missing key is not an error, so NotFound is left to unknown caches
+	value, err := c.Get(ctx, req.GetKey())
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		return nil, statusOf(err)
+	}
+	if value == nil {
+		return &cachepb.GetResponse{}, nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return &cachepb.GetResponse{Found: true, Value: v}, nil
+	case string:
+		return &cachepb.GetResponse{Found: true, Value: []byte(v)}, nil
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, "%v: %T", ErrNotBytes, value)
+	}
+}
+
+// Set stores key-value
+func (s *Server) Set(ctx context.Context, req *cachepb.SetRequest) (*cachepb.SetResponse, error) {
+	c, err := s.cache(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	var options []cache.SetOption
+	if req.GetTtlMillis() > 0 {
+		options = append(options, cache.WithTTL(time.Duration(req.GetTtlMillis())*time.Millisecond))
+	}
+
+	if err := c.Set(ctx, req.GetKey(), req.GetValue(), options...); err != nil {
+		return nil, statusOf(err)
+	}
+
+	return &cachepb.SetResponse{}, nil
+}
+
+// Delete deletes value of key
+func (s *Server) Delete(ctx context.Context, req *cachepb.DeleteRequest) (*cachepb.DeleteResponse, error) {
+	c, err := s.cache(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Delete(ctx, req.GetKey()); err != nil {
+		return nil, statusOf(err)
+	}
+
+	return &cachepb.DeleteResponse{}, nil
+}
+
+// Load stores multiple key-values, at once if cache supports it
+func (s *Server) Load(ctx context.Context, req *cachepb.LoadRequest) (*cachepb.LoadResponse, error) {
+	c, err := s.cache(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any, len(req.GetValues()))
+	for key, value := range req.GetValues() {
+		data[key] = value
+	}
+
+	if l, ok := c.(loader); ok {
+		err = l.Load(ctx, data)
+	} else {
+		var errs []error
+		for key, value := range data {
+			if err := c.Set(ctx, key, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+		err = errors.Join(errs...)
+	}
+	if err != nil {
+		return nil, statusOf(err)
+	}
+
+	return &cachepb.LoadResponse{}, nil
+}
+
+// Ping checks connectivity of cache, caches which can not be checked are assumed healthy
+func (s *Server) Ping(ctx context.Context, req *cachepb.PingRequest) (*cachepb.PingResponse, error) {
+	c, err := s.cache(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	switch checker := c.(type) {
+	case cache.Pinger:
+		err = checker.Ping(ctx)
+	case healthChecker:
+		err = checker.Health(ctx)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	return &cachepb.PingResponse{}, nil
+}
+
+// statusOf returns gRPC status of cache error, errors of missing keys and invalid requests
+// keep their meaning for clients and other errors are internal
+func statusOf(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, cache.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, cache.ErrInvalidKey), errors.Is(err, cache.ErrInvalidOption), errors.Is(err, cache.ErrNoTenant):
+		code = codes.InvalidArgument
+	case errors.Is(err, cache.ErrQuotaExceeded), errors.Is(err, cache.ErrTenantQuotaExceeded), errors.Is(err, cache.ErrRateLimited):
+		code = codes.ResourceExhausted
+	case errors.Is(err, cache.ErrCircuitOpen):
+		code = codes.Unavailable
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,187 @@
+// Package grpccache provides gRPC interceptors caching responses of idempotent unary RPCs in any cacher
+package grpccache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/albinzx/cache"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// key prefixes of client and server responses, server responses are cached with their type
+const (
+	clientPrefix = "grpccache:"
+	serverPrefix = "grpccache:server:"
+)
+
+// Interceptor caches responses of allowed methods keyed by method and marshalled request
+type Interceptor struct {
+	cache   cache.Cacher
+	methods map[string]time.Duration
+	metrics cache.Metrics
+}
+
+// Option provides interceptor options
+type Option func(*Interceptor)
+
+// WithMethod returns option to cache responses of full method name, e.g. "/catalog.v1.Catalog/GetProduct",
+// for ttl, only responses of configured methods are cached so methods must be idempotent
+func WithMethod(method string, ttl time.Duration) Option {
+	return func(i *Interceptor) {
+		i.methods[method] = ttl
+	}
+}
+
+// WithMetrics returns option to record hits and misses of cached methods as get operations
+func WithMetrics(metrics cache.Metrics) Option {
+	return func(i *Interceptor) {
+		i.metrics = metrics
+	}
+}
+
+// New returns interceptor caching responses in c
+func New(c cache.Cacher, options ...Option) *Interceptor {
+	i := &Interceptor{cache: c, methods: make(map[string]time.Duration)}
+
+	for _, option := range options {
+		option(i)
+	}
+
+	return i
+}
+
+// UnaryClientInterceptor returns client interceptor serving cached responses without calling server
+func (i *Interceptor) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ttl, ok := i.methods[method]
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		reqMessage, ok1 := req.(proto.Message)
+		replyMessage, ok2 := reply.(proto.Message)
+		if !ok1 || !ok2 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		key, err := cacheKey(clientPrefix, method, reqMessage)
+		if err != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		if i.lookup(ctx, key, replyMessage) {
+			return nil
+		}
+
+		if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+			return err
+		}
+
+		i.store(ctx, key, replyMessage, ttl)
+
+		return nil
+	}
+}
+
+// UnaryServerInterceptor returns server interceptor serving cached responses without calling handler,
+// responses are cached with their type so response messages must be registered in global proto registry
+func (i *Interceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ttl, ok := i.methods[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		reqMessage, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		key, err := cacheKey(serverPrefix, info.FullMethod, reqMessage)
+		if err != nil {
+			return handler(ctx, req)
+		}
+
+		cached := &anypb.Any{}
+		if i.lookup(ctx, key, cached) {
+			if resp, err := cached.UnmarshalNew(); err == nil {
+				return resp, nil
+			}
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		if respMessage, ok := resp.(proto.Message); ok {
+			if wrapped, err := anypb.New(respMessage); err == nil {
+				i.store(ctx, key, wrapped, ttl)
+			}
+		}
+
+		return resp, nil
+	}
+}
+
+// lookup unmarshals cached response of key into reply and reports whether it is found
+func (i *Interceptor) lookup(ctx context.Context, key string, reply proto.Message) bool {
+	start := time.Now()
+	value, err := i.cache.Get(ctx, key)
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	}
+
+	found := err == nil && data != nil && proto.Unmarshal(data, reply) == nil
+	if i.metrics != nil {
+		result := cache.ResultMiss
+		if err != nil {
+			result = cache.ResultError
+		} else if found {
+			result = cache.ResultHit
+		}
+		i.metrics.Observe(cache.OpGet, result, time.Since(start))
+	}
+
+	return found
+}
+
+// store caches marshalled response with key
+func (i *Interceptor) store(ctx context.Context, key string, reply proto.Message, ttl time.Duration) {
+	data, err := proto.Marshal(reply)
+	if err != nil {
+		return
+	}
+
+	start := time.Now()
+	err = i.cache.Set(ctx, key, data, cache.WithTTL(ttl))
+	if i.metrics != nil {
+		result := cache.ResultOK
+		if err != nil {
+			result = cache.ResultError
+		}
+		i.metrics.Observe(cache.OpSet, result, time.Since(start))
+	}
+}
+
+// cacheKey returns key of prefix, method and hash of deterministically marshalled request
+func cacheKey(prefix, method string, req proto.Message) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return prefix + method + ":" + hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,118 @@
+package grpccache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/grpc/cachepb"
+	"github.com/albinzx/cache/memory"
+	"google.golang.org/grpc"
+)
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	stats := cache.NewStats("grpc")
+	interceptor := New(memory.New(), WithMethod(cachepb.Cache_Get_FullMethodName, time.Minute), WithMetrics(stats)).UnaryClientInterceptor()
+
+	calls := 0
+	invoker := func(_ context.Context, method string, req, reply any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		calls++
+		if req.(*cachepb.GetRequest).GetKey() == "fail" {
+			return errors.New("failed")
+		}
+		reply.(*cachepb.GetResponse).Value = []byte("value of " + req.(*cachepb.GetRequest).GetKey())
+		return nil
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		key       string
+		want      string
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "test miss", method: cachepb.Cache_Get_FullMethodName, key: "a", want: "value of a", wantCalls: 1},
+		{name: "test hit", method: cachepb.Cache_Get_FullMethodName, key: "a", want: "value of a", wantCalls: 0},
+		{name: "test other request", method: cachepb.Cache_Get_FullMethodName, key: "b", want: "value of b", wantCalls: 1},
+		{name: "test error not cached", method: cachepb.Cache_Get_FullMethodName, key: "fail", wantErr: true, wantCalls: 1},
+		{name: "test error again", method: cachepb.Cache_Get_FullMethodName, key: "fail", wantErr: true, wantCalls: 1},
+		{name: "test method not allowed", method: cachepb.Cache_Ping_FullMethodName, key: "a", want: "value of a", wantCalls: 1},
+		{name: "test method not allowed again", method: cachepb.Cache_Ping_FullMethodName, key: "a", want: "value of a", wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := calls
+			reply := &cachepb.GetResponse{}
+
+			err := interceptor(context.Background(), tt.method, &cachepb.GetRequest{Name: "orders", Key: tt.key}, reply, nil, invoker)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("interceptor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := string(reply.GetValue()); got != tt.want {
+				t.Errorf("interceptor() reply = %v, want %v", got, tt.want)
+			}
+			if got := calls - before; got != tt.wantCalls {
+				t.Errorf("interceptor() calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+
+	if got := stats.Snapshot().Hits; got != 1 {
+		t.Errorf("Stats hits = %v, want %v", got, 1)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := New(memory.New(), WithMethod(cachepb.Cache_Get_FullMethodName, time.Minute)).UnaryServerInterceptor()
+
+	calls := 0
+	handler := func(_ context.Context, req any) (any, error) {
+		calls++
+		if req.(*cachepb.GetRequest).GetKey() == "fail" {
+			return nil, errors.New("failed")
+		}
+		return &cachepb.GetResponse{Value: []byte("value of " + req.(*cachepb.GetRequest).GetKey())}, nil
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		key       string
+		want      string
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "test miss", method: cachepb.Cache_Get_FullMethodName, key: "a", want: "value of a", wantCalls: 1},
+		{name: "test hit", method: cachepb.Cache_Get_FullMethodName, key: "a", want: "value of a", wantCalls: 0},
+		{name: "test other request", method: cachepb.Cache_Get_FullMethodName, key: "b", want: "value of b", wantCalls: 1},
+		{name: "test error not cached", method: cachepb.Cache_Get_FullMethodName, key: "fail", wantErr: true, wantCalls: 1},
+		{name: "test error again", method: cachepb.Cache_Get_FullMethodName, key: "fail", wantErr: true, wantCalls: 1},
+		{name: "test method not allowed", method: cachepb.Cache_Ping_FullMethodName, key: "a", want: "value of a", wantCalls: 1},
+		{name: "test method not allowed again", method: cachepb.Cache_Ping_FullMethodName, key: "a", want: "value of a", wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := calls
+
+			resp, err := interceptor(context.Background(), &cachepb.GetRequest{Name: "orders", Key: tt.key}, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("interceptor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				reply, ok := resp.(*cachepb.GetResponse)
+				if !ok {
+					t.Fatalf("interceptor() response = %T, want *cachepb.GetResponse", resp)
+				}
+				if got := string(reply.GetValue()); got != tt.want {
+					t.Errorf("interceptor() reply = %v, want %v", got, tt.want)
+				}
+			}
+			if got := calls - before; got != tt.wantCalls {
+				t.Errorf("interceptor() calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Pinger is implemented by cachers and persisters which can check their connectivity,
+// e.g. redis cacher pings redis, memory cacher always succeeds and sql persister pings database
+type Pinger interface {
+	// Ping checks connection to the underlying storage
+	Ping(context.Context) error
+}
+
+// ComponentHealth is health of one component of patterned cache
+type ComponentHealth struct {
+	// Name is name of component, cacher or persister
+	Name string
+	// Checked reports whether component implements Pinger, unchecked components are assumed healthy
+	Checked bool
+	// Latency is duration of ping
+	Latency time.Duration
+	// Err is error of ping, nil if component is healthy
+	Err error
+}
+
+// Healthy reports whether component is healthy
+func (h ComponentHealth) Healthy() bool {
+	return h.Err == nil
+}
+
+// HealthReport is health of components of patterned cache
+type HealthReport struct {
+	// Components are health of cacher and of persister if cache has one
+	Components []ComponentHealth
+}
+
+// Healthy reports whether all components are healthy
+func (r HealthReport) Healthy() bool {
+	return r.Err() == nil
+}
+
+// Err returns error joining errors of all unhealthy components
+func (r HealthReport) Err() error {
+	var errs []error
+	for _, component := range r.Components {
+		if component.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", component.Name, component.Err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckHealth pings cacher and persister and reports their status and latency
+func (c *PatternedCache) CheckHealth(ctx context.Context) HealthReport {
+	report := HealthReport{Components: []ComponentHealth{checkHealth(ctx, "cacher", c.unwrapped())}}
+	if c.persister != nil {
+		report.Components = append(report.Components, checkHealth(ctx, "persister", c.persister))
+	}
+
+	return report
+}
+
+// checkHealth pings component if it implements Pinger
+func checkHealth(ctx context.Context, name string, component any) ComponentHealth {
+	health := ComponentHealth{Name: name}
+
+	pinger, ok := component.(Pinger)
+	if !ok {
+		return health
+	}
+
+	start := time.Now()
+	health.Checked = true
+	health.Err = pinger.Ping(ctx)
+	health.Latency = time.Since(start)
+
+	return health
+}
+
+// Health checks connectivity of cacher and persister
+// components which do not implement Pinger are assumed healthy
+// returned error joins errors of all unhealthy components
+func (c *PatternedCache) Health(ctx context.Context) error {
+	return c.CheckHealth(ctx).Err()
+}
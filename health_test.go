@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// pingCacher is cacher with configurable ping result
+type pingCacher struct {
+	*mapCacher
+	err error
+}
+
+func (p *pingCacher) Ping(context.Context) error {
+	return p.err
+}
+
+func TestPatternedCache_Health(t *testing.T) {
+	tests := []struct {
+		name    string
+		cacher  Cacher
+		wantErr bool
+	}{
+		{
+			name:    "test healthy cacher",
+			cacher:  &pingCacher{mapCacher: newMapCacher()},
+			wantErr: false,
+		},
+		{
+			name:    "test unhealthy cacher",
+			cacher:  &pingCacher{mapCacher: newMapCacher(), err: errors.New("down")},
+			wantErr: true,
+		},
+		{
+			name:    "test cacher without ping",
+			cacher:  newMapCacher(),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := New(tt.cacher, newMapPersister())
+			if err := c.Health(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("PatternedCache.Health() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPatternedCache_CheckHealth(t *testing.T) {
+	down := errors.New("down")
+	policy, _ := NewTTLPolicy()
+	// cacher wrapped by ttl policy is still pinged
+	c, _ := New(&pingCacher{mapCacher: newMapCacher(), err: down}, newMapPersister(), WithTTLPolicy(policy))
+
+	report := c.CheckHealth(context.Background())
+	if report.Healthy() || !errors.Is(report.Err(), down) {
+		t.Errorf("CheckHealth() error = %v, want %v", report.Err(), down)
+	}
+	if len(report.Components) != 2 {
+		t.Fatalf("CheckHealth() components = %+v, want cacher and persister", report.Components)
+	}
+
+	cacher, persister := report.Components[0], report.Components[1]
+	if cacher.Name != "cacher" || !cacher.Checked || cacher.Healthy() {
+		t.Errorf("CheckHealth() cacher = %+v, want checked and unhealthy", cacher)
+	}
+	if persister.Name != "persister" || persister.Checked || !persister.Healthy() {
+		t.Errorf("CheckHealth() persister = %+v, want unchecked and healthy", persister)
+	}
+}
@@ -0,0 +1,480 @@
+// Package httpcache caches http responses in any cacher, with client side http.RoundTripper
+// and server side handler middleware
+// transport follows caching rules of RFC 7234 for a private cache
+package httpcache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+)
+
+// StatusHeader is response header telling whether response is served from cache,
+// its value is one of HIT, MISS, REVALIDATED or STALE
+const StatusHeader = "X-Cache"
+
+const (
+	statusHit         = "HIT"
+	statusMiss        = "MISS"
+	statusRevalidated = "REVALIDATED"
+	statusStale       = "STALE"
+)
+
+// cacheableStatus are status codes cacheable by default
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// Transport is http.RoundTripper serving GET requests from cache while responses are fresh
+// and revalidating stale responses with their ETag or Last-Modified validators
+// within stale-while-revalidate stale responses are served while they are revalidated in background,
+// and within stale-if-error stale responses are served when revalidation fails
+// successful unsafe requests, e.g. POST, invalidate cached response of their URL
+// cached responses are stored as bytes, wrap cacher with cache.Instrument to measure it
+type Transport struct {
+	cache          cache.Cacher
+	transport      http.RoundTripper
+	staleRetention time.Duration
+	now            func() time.Time
+	group          internal.Group
+	background     sync.WaitGroup
+}
+
+// Option provides transport options
+type Option func(*Transport)
+
+// WithTransport returns option to set underlying round tripper, default is http.DefaultTransport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(t *Transport) {
+		t.transport = transport
+	}
+}
+
+// WithStaleRetention returns option to set how long stale responses with validators
+// are kept for revalidation after they expire, default is 24 hours
+func WithStaleRetention(retention time.Duration) Option {
+	return func(t *Transport) {
+		t.staleRetention = retention
+	}
+}
+
+// New returns caching transport storing responses in c
+func New(c cache.Cacher, options ...Option) *Transport {
+	t := &Transport{cache: c, transport: http.DefaultTransport, staleRetention: 24 * time.Hour, now: time.Now}
+
+	for _, option := range options {
+		option(t)
+	}
+
+	return t
+}
+
+// Client returns http client using transport
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// entry is cached response
+type entry struct {
+	// Stored is time response is received or revalidated
+	Stored time.Time `json:"stored"`
+	// Vary are request header values selected by Vary response header
+	Vary map[string]string `json:"vary,omitempty"`
+	// Response is response dumped with httputil.DumpResponse
+	Response []byte `json:"response"`
+}
+
+// RoundTrip serves request from cache or from underlying transport
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := cacheKey(req)
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := t.transport.RoundTrip(req)
+		if err == nil && isUnsafe(req.Method) && resp.StatusCode < 400 {
+			t.invalidate(req.Context(), req)
+		}
+		return resp, err
+	}
+
+	reqControl := parseCacheControl(req.Header)
+	if _, ok := reqControl["no-store"]; ok {
+		return t.transport.RoundTrip(req)
+	}
+
+	cached, e := t.lookup(req.Context(), key, req)
+	if cached != nil {
+		age := t.age(cached, e)
+		fresh := t.fresh(cached, age, reqControl)
+		cached.Header.Set("Age", strconv.Itoa(int(age.Seconds())))
+		if fresh {
+			cached.Header.Set(StatusHeader, statusHit)
+			return cached, nil
+		}
+
+		freshness := ParseFreshness(cached.Header)
+		if withinStale(cached, age, freshness.StaleWhileRevalidate) {
+			t.revalidateInBackground(req, key)
+			cached.Header.Set(StatusHeader, statusStale)
+			return cached, nil
+		}
+
+		if _, ok := reqControl["only-if-cached"]; !ok {
+			staleIfError := withinStale(cached, age, freshness.StaleIfError)
+			resp, err := t.revalidate(req, key, cached, e)
+			if staleIfError && (err != nil || isServerError(resp.StatusCode)) {
+				if stale, e := t.lookup(req.Context(), key, req); stale != nil {
+					if resp != nil {
+						resp.Body.Close()
+					}
+					stale.Header.Set("Age", strconv.Itoa(int(t.age(stale, e).Seconds())))
+					stale.Header.Set(StatusHeader, statusStale)
+					return stale, nil
+				}
+			}
+			return resp, err
+		}
+	}
+
+	if _, ok := reqControl["only-if-cached"]; ok {
+		return &http.Response{
+			Status:     "504 Gateway Timeout",
+			StatusCode: http.StatusGatewayTimeout,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{StatusHeader: {statusMiss}},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.store(req, key, resp, statusMiss)
+}
+
+// withinStale reports whether stale cached response is within window after its expiration
+// and may be served stale
+func withinStale(resp *http.Response, age, window time.Duration) bool {
+	if window <= 0 {
+		return false
+	}
+
+	control := parseCacheControl(resp.Header)
+	if _, ok := control["must-revalidate"]; ok {
+		return false
+	}
+	if _, ok := control["no-cache"]; ok {
+		return false
+	}
+
+	return age < freshnessLifetime(resp.Header, control)+window
+}
+
+// revalidateInBackground revalidates cached response of request without blocking the caller,
+// concurrent revalidations of the same response are collapsed into one
+func (t *Transport) revalidateInBackground(req *http.Request, key string) {
+	background := req.Clone(context.WithoutCancel(req.Context()))
+
+	t.background.Add(1)
+	go func() {
+		defer t.background.Done()
+
+		_, _, _ = t.group.Do(key, func() (any, error) {
+			var resp *http.Response
+			var err error
+			if cached, e := t.lookup(background.Context(), key, background); cached != nil {
+				resp, err = t.revalidate(background, key, cached, e)
+			} else if resp, err = t.transport.RoundTrip(background); err == nil {
+				resp, err = t.store(background, key, resp, statusMiss)
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+			return nil, err
+		})
+	}()
+}
+
+// revalidate sends conditional request for stale cached response
+func (t *Transport) revalidate(req *http.Request, key string, cached *http.Response, e *entry) (*http.Response, error) {
+	etag := cached.Header.Get("ETag")
+	lastModified := cached.Header.Get("Last-Modified")
+
+	conditional := req
+	if etag != "" || lastModified != "" {
+		conditional = req.Clone(req.Context())
+		if etag != "" {
+			conditional.Header.Set("If-None-Match", etag)
+		}
+		if lastModified != "" {
+			conditional.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(conditional)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusNotModified || conditional == req {
+		cached.Body.Close()
+		return t.store(req, key, resp, statusMiss)
+	}
+	resp.Body.Close()
+
+	// headers of not modified response update cached response
+	for name, values := range resp.Header {
+		cached.Header[name] = values
+	}
+	cached.Header.Del("Age")
+
+	return t.store(req, key, cached, statusRevalidated)
+}
+
+// store caches response if it is cacheable and returns response with status header
+func (t *Transport) store(req *http.Request, key string, resp *http.Response, status string) (*http.Response, error) {
+	resp.Header.Set(StatusHeader, status)
+
+	ttl, ok := t.storable(req, resp)
+	if !ok {
+		return resp, nil
+	}
+
+	dump, err := httputil.DumpResponse(resp, true)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &entry{Stored: t.now(), Response: dump}
+	for _, name := range varyHeaders(resp.Header) {
+		if e.Vary == nil {
+			e.Vary = map[string]string{}
+		}
+		e.Vary[name] = req.Header.Get(name)
+	}
+
+	if data, err := json.Marshal(e); err == nil {
+		_ = t.cache.Set(req.Context(), key, data, cache.WithTTL(ttl))
+	}
+
+	return resp, nil
+}
+
+// storable returns time to live of response in cache, false is returned if response must not be stored
+func (t *Transport) storable(req *http.Request, resp *http.Response) (time.Duration, bool) {
+	if req.Method != http.MethodGet || !cacheableStatus[resp.StatusCode] {
+		return 0, false
+	}
+
+	control := parseCacheControl(resp.Header)
+	if _, ok := control["no-store"]; ok {
+		return 0, false
+	}
+	if resp.Header.Get("Vary") == "*" {
+		return 0, false
+	}
+
+	lifetime := freshnessLifetime(resp.Header, control) + ParseFreshness(resp.Header).staleWindow()
+	if resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "" {
+		return lifetime + t.staleRetention, lifetime+t.staleRetention > 0
+	}
+
+	return lifetime, lifetime > 0
+}
+
+// lookup returns cached response of request, nil is returned if none matches request
+func (t *Transport) lookup(ctx context.Context, key string, req *http.Request) (*http.Response, *entry) {
+	value, err := t.cache.Get(ctx, key)
+	if err != nil || value == nil {
+		return nil, nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, nil
+	}
+
+	e := &entry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, nil
+	}
+
+	for name, value := range e.Vary {
+		if req.Header.Get(name) != value {
+			return nil, nil
+		}
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(e.Response)), req)
+	if err != nil {
+		return nil, nil
+	}
+
+	return resp, e
+}
+
+// fresh reports whether cached response can be served without revalidation
+func (t *Transport) fresh(resp *http.Response, age time.Duration, reqControl map[string]string) bool {
+	control := parseCacheControl(resp.Header)
+	if _, ok := control["no-cache"]; ok {
+		return false
+	}
+	if _, ok := reqControl["no-cache"]; ok {
+		return false
+	}
+	if strings.Contains(resp.Header.Get("Pragma"), "no-cache") && len(control) == 0 {
+		return false
+	}
+
+	lifetime := freshnessLifetime(resp.Header, control)
+	if maxAge, ok := seconds(reqControl, "max-age"); ok && maxAge < lifetime {
+		lifetime = maxAge
+	}
+	if minFresh, ok := seconds(reqControl, "min-fresh"); ok {
+		age += minFresh
+	}
+	if lifetime > age {
+		return true
+	}
+
+	if _, ok := control["must-revalidate"]; ok {
+		return false
+	}
+	if maxStale, ok := reqControl["max-stale"]; ok {
+		if maxStale == "" {
+			return true
+		}
+		if stale, ok := seconds(reqControl, "max-stale"); ok {
+			return lifetime+stale > age
+		}
+	}
+
+	return false
+}
+
+// age returns age of cached response
+func (t *Transport) age(resp *http.Response, e *entry) time.Duration {
+	age := t.now().Sub(e.Stored)
+	if header, err := strconv.Atoi(resp.Header.Get("Age")); err == nil && header > 0 {
+		age += time.Duration(header) * time.Second
+	}
+
+	return age
+}
+
+// invalidate deletes cached response of request URL
+func (t *Transport) invalidate(ctx context.Context, req *http.Request) {
+	get := req.Clone(ctx)
+	get.Method = http.MethodGet
+	_ = t.cache.Delete(ctx, cacheKey(get))
+}
+
+// cacheKey returns cache key of request, HEAD requests share key of GET
+func cacheKey(req *http.Request) string {
+	return "httpcache:" + req.URL.String()
+}
+
+// isUnsafe reports whether method may change resource
+func isUnsafe(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// freshnessLifetime returns freshness lifetime of response of max-age or Expires
+func freshnessLifetime(header http.Header, control map[string]string) time.Duration {
+	if maxAge, ok := seconds(control, "max-age"); ok {
+		return maxAge
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(header.Get("Date"))
+		if err != nil {
+			return 0
+		}
+		return expiresAt.Sub(date)
+	}
+
+	return 0
+}
+
+// seconds returns directive of control as duration in seconds
+func seconds(control map[string]string, directive string) (time.Duration, bool) {
+	value, ok := control[directive]
+	if !ok {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+
+	return time.Duration(n) * time.Second, true
+}
+
+// parseCacheControl returns directives of Cache-Control header
+func parseCacheControl(header http.Header) map[string]string {
+	control := map[string]string{}
+
+	for _, part := range strings.Split(header.Get("Cache-Control"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		control[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	return control
+}
+
+// varyHeaders returns canonical names of headers of Vary response header
+func varyHeaders(header http.Header) []string {
+	var names []string
+
+	for _, vary := range header.Values("Vary") {
+		for _, name := range strings.Split(vary, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,100 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/memory"
+)
+
+func TestTransport(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/fresh":
+			w.Header().Set("Cache-Control", "max-age=60")
+		case "/etag":
+			w.Header().Set("Cache-Control", "max-age=0")
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/no-store":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+		}
+		_, _ = io.WriteString(w, "body of "+r.URL.Path+" "+r.Header.Get("Accept-Language"))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	transport := New(memory.New())
+	transport.now = func() time.Time { return now }
+	client := transport.Client()
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		header       http.Header
+		advance      time.Duration
+		wantStatus   string
+		wantBody     string
+		wantRequests int32
+	}{
+		{name: "test fresh miss", path: "/fresh", wantStatus: statusMiss, wantBody: "body of /fresh ", wantRequests: 1},
+		{name: "test fresh hit", path: "/fresh", wantStatus: statusHit, wantBody: "body of /fresh ", wantRequests: 0},
+		{name: "test request no-cache", path: "/fresh", header: http.Header{"Cache-Control": {"no-cache"}}, wantStatus: statusMiss, wantBody: "body of /fresh ", wantRequests: 1},
+		{name: "test expired", path: "/fresh", advance: time.Minute, wantStatus: statusMiss, wantBody: "body of /fresh ", wantRequests: 1},
+		{name: "test etag miss", path: "/etag", wantStatus: statusMiss, wantBody: "body of /etag ", wantRequests: 1},
+		{name: "test etag revalidated", path: "/etag", wantStatus: statusRevalidated, wantBody: "body of /etag ", wantRequests: 1},
+		{name: "test no-store", path: "/no-store", wantStatus: statusMiss, wantBody: "body of /no-store ", wantRequests: 1},
+		{name: "test no-store again", path: "/no-store", wantStatus: statusMiss, wantBody: "body of /no-store ", wantRequests: 1},
+		{name: "test vary miss", path: "/vary", header: http.Header{"Accept-Language": {"en"}}, wantStatus: statusMiss, wantBody: "body of /vary en", wantRequests: 1},
+		{name: "test vary hit", path: "/vary", header: http.Header{"Accept-Language": {"en"}}, wantStatus: statusHit, wantBody: "body of /vary en", wantRequests: 0},
+		{name: "test vary other", path: "/vary", header: http.Header{"Accept-Language": {"id"}}, wantStatus: statusMiss, wantBody: "body of /vary id", wantRequests: 1},
+		{name: "test post invalidates", method: http.MethodPost, path: "/fresh", wantBody: "body of /fresh ", wantRequests: 1},
+		{name: "test invalidated", path: "/fresh", wantStatus: statusMiss, wantBody: "body of /fresh ", wantRequests: 1},
+		{name: "test only-if-cached", path: "/missing", header: http.Header{"Cache-Control": {"only-if-cached"}}, wantStatus: statusMiss, wantRequests: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			before := requests.Load()
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			req, _ := http.NewRequest(method, server.URL+tt.path, nil)
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+
+			if got := resp.Header.Get(StatusHeader); got != tt.wantStatus {
+				t.Errorf("Do() status header = %v, want %v", got, tt.wantStatus)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("Do() body = %q, want %q", body, tt.wantBody)
+			}
+			if got := requests.Load() - before; got != tt.wantRequests {
+				t.Errorf("Do() requests = %v, want %v", got, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+)
+
+// route is ttl of responses of paths matching pattern
+type route struct {
+	pattern string
+	ttl     time.Duration
+}
+
+// Middleware caches responses of handlers keyed by method, path, query and vary headers
+//
+// only GET and HEAD responses with status 200 are cached, responses with Cache-Control
+// no-store or private or setting cookies are not cached, and requests with Authorization header
+// are not cached unless Authorization is a vary header
+// responses are fresh for ttl of their route, after that responses with Cache-Control
+// stale-while-revalidate are served stale while handler refreshes them in background,
+// and responses with stale-if-error are served stale when handler fails with server error
+// successful unsafe requests, e.g. POST, invalidate cached responses of their path
+type Middleware struct {
+	cache      cache.Cacher
+	defaultTTL time.Duration
+	routes     []route
+	vary       []string
+	now        func() time.Time
+	group      internal.Group
+	background sync.WaitGroup
+}
+
+// MiddlewareOption provides middleware options
+type MiddlewareOption func(*Middleware)
+
+// WithDefaultTTL returns option to set ttl of paths not matching any route, default is 0 which disables caching
+func WithDefaultTTL(ttl time.Duration) MiddlewareOption {
+	return func(m *Middleware) {
+		m.defaultTTL = ttl
+	}
+}
+
+// WithRoute returns option to set ttl of paths matching glob pattern, e.g. "/products/*"
+// routes are matched in order and zero ttl disables caching of matched paths
+func WithRoute(pattern string, ttl time.Duration) MiddlewareOption {
+	return func(m *Middleware) {
+		m.routes = append(m.routes, route{pattern: pattern, ttl: ttl})
+	}
+}
+
+// WithVary returns option to cache separate responses per values of request headers
+func WithVary(headers ...string) MiddlewareOption {
+	return func(m *Middleware) {
+		for _, header := range headers {
+			m.vary = append(m.vary, http.CanonicalHeaderKey(header))
+		}
+	}
+}
+
+// NewMiddleware returns middleware caching responses in c
+func NewMiddleware(c cache.Cacher, options ...MiddlewareOption) *Middleware {
+	m := &Middleware{cache: c, now: time.Now}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	return m
+}
+
+// cachedResponse is cached response of handler
+type cachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+	// Stored is time response is stored
+	Stored time.Time `json:"stored"`
+	// Freshness is soft ttl and stale windows of response
+	Freshness Freshness `json:"freshness"`
+}
+
+// age returns age of cached response
+func (c *cachedResponse) age(now time.Time) time.Duration {
+	return now.Sub(c.Stored)
+}
+
+// write writes cached response with status header
+func (c *cachedResponse) write(w http.ResponseWriter, r *http.Request, status string) {
+	for name, values := range c.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(StatusHeader, status)
+	w.WriteHeader(c.Status)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(c.Body)
+	}
+}
+
+// Handler returns handler serving cached responses of next
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+			if isUnsafe(r.Method) && recorder.status < 400 {
+				_ = m.Invalidate(r.Context(), r.URL.Path)
+			}
+			return
+		}
+
+		ttl := m.ttl(r.URL.Path)
+		if ttl <= 0 || (r.Header.Get("Authorization") != "" && !m.varies("Authorization")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := m.variantKey(r)
+		cached, ok := m.lookup(r.Context(), key)
+		var stale *cachedResponse
+		if ok {
+			age := cached.age(m.now())
+			switch {
+			case age < cached.Freshness.MaxAge:
+				cached.write(w, r, statusHit)
+				return
+			case age < cached.Freshness.MaxAge+cached.Freshness.StaleWhileRevalidate:
+				m.refreshInBackground(next, r, key, ttl)
+				cached.write(w, r, statusStale)
+				return
+			case age < cached.Freshness.MaxAge+cached.Freshness.StaleIfError:
+				stale = cached
+			}
+		}
+
+		if stale == nil {
+			w.Header().Set(StatusHeader, statusMiss)
+			recorder := &bodyRecorder{statusRecorder: statusRecorder{ResponseWriter: w, status: http.StatusOK}}
+			next.ServeHTTP(recorder, r)
+			m.store(r, key, recorder.status, w.Header(), recorder.body.Bytes(), ttl)
+			return
+		}
+
+		// response is buffered so stale response can be served if handler fails
+		buffer := newResponseBuffer()
+		next.ServeHTTP(buffer, r)
+		if isServerError(buffer.status) {
+			stale.write(w, r, statusStale)
+			return
+		}
+
+		for name, values := range buffer.header {
+			w.Header()[name] = values
+		}
+		w.Header().Set(StatusHeader, statusMiss)
+		w.WriteHeader(buffer.status)
+		_, _ = w.Write(buffer.body.Bytes())
+		m.store(r, key, buffer.status, buffer.header, buffer.body.Bytes(), ttl)
+	})
+}
+
+// refreshInBackground serves request with next without blocking the caller and caches its response,
+// concurrent refreshes of the same key are collapsed into one
+func (m *Middleware) refreshInBackground(next http.Handler, r *http.Request, key string, ttl time.Duration) {
+	background := r.Clone(context.WithoutCancel(r.Context()))
+
+	m.background.Add(1)
+	go func() {
+		defer m.background.Done()
+
+		_, _, _ = m.group.Do(key, func() (any, error) {
+			buffer := newResponseBuffer()
+			next.ServeHTTP(buffer, background)
+			m.store(background, key, buffer.status, buffer.header, buffer.body.Bytes(), ttl)
+			return nil, nil
+		})
+	}()
+}
+
+// Invalidate deletes cached responses of all variants of path
+func (m *Middleware) Invalidate(ctx context.Context, path string) error {
+	base := baseKey(path)
+
+	keys, err := m.variants(ctx, base)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, key := range append(keys, base) {
+		if err := m.cache.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ttl returns ttl of first route matching path or default ttl
+func (m *Middleware) ttl(p string) time.Duration {
+	for _, r := range m.routes {
+		if ok, _ := path.Match(r.pattern, p); ok {
+			return r.ttl
+		}
+	}
+
+	return m.defaultTTL
+}
+
+// varies reports whether responses vary by header
+func (m *Middleware) varies(header string) bool {
+	for _, vary := range m.vary {
+		if vary == header {
+			return true
+		}
+	}
+
+	return false
+}
+
+// variantKey returns cache key of request including query and vary header values
+func (m *Middleware) variantKey(r *http.Request) string {
+	var variant strings.Builder
+	variant.WriteString(r.URL.RawQuery)
+	for _, header := range m.vary {
+		variant.WriteString("\n")
+		variant.WriteString(header)
+		variant.WriteString(":")
+		variant.WriteString(strings.Join(r.Header.Values(header), ","))
+	}
+
+	return baseKey(r.URL.Path) + ":" + internal.HashKey(variant.String())
+}
+
+// baseKey returns key of variant index of path
+func baseKey(path string) string {
+	return "httpmiddleware:" + path
+}
+
+// lookup returns cached response of key
+func (m *Middleware) lookup(ctx context.Context, key string) (*cachedResponse, bool) {
+	data, ok := bytesOf(m.cache.Get(ctx, key))
+	if !ok {
+		return nil, false
+	}
+
+	cached := &cachedResponse{}
+	if err := json.Unmarshal(data, cached); err != nil {
+		return nil, false
+	}
+
+	return cached, true
+}
+
+// store caches response of request with key if it is cacheable,
+// response is fresh for ttl and its stale windows are given by its Cache-Control header
+func (m *Middleware) store(r *http.Request, key string, status int, header http.Header, body []byte, ttl time.Duration) {
+	if r.Method != http.MethodGet || status != http.StatusOK || !storableHeader(header) {
+		return
+	}
+
+	header = header.Clone()
+	header.Del(StatusHeader)
+	freshness := ParseFreshness(header)
+	freshness.MaxAge = ttl
+	if freshness.MustRevalidate {
+		freshness.StaleWhileRevalidate, freshness.StaleIfError = 0, 0
+	}
+
+	resp := &cachedResponse{Status: status, Header: header, Body: body, Stored: m.now(), Freshness: freshness}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	ctx := r.Context()
+	if err := m.cache.Set(ctx, key, data, cache.WithTTL(freshness.HardTTL())); err != nil {
+		return
+	}
+
+	base := baseKey(r.URL.Path)
+	keys, _ := m.variants(ctx, base)
+	for _, k := range keys {
+		if k == key {
+			return
+		}
+	}
+	if index, err := json.Marshal(append(keys, key)); err == nil {
+		// index outlives its variants so they can always be invalidated
+		_ = m.cache.Set(ctx, base, index, cache.WithTTL(2*freshness.HardTTL()))
+	}
+}
+
+// variants returns keys of cached variants of path
+func (m *Middleware) variants(ctx context.Context, base string) ([]string, error) {
+	value, err := m.cache.Get(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+
+	data, ok := bytesOf(value, nil)
+	if !ok {
+		return nil, nil
+	}
+
+	var keys []string
+	_ = json.Unmarshal(data, &keys)
+
+	return keys, nil
+}
+
+// bytesOf returns bytes of bytes or string value got without error
+func bytesOf(value any, err error) ([]byte, bool) {
+	if err != nil {
+		return nil, false
+	}
+
+	return internal.BytesOf(value)
+}
+
+// storableHeader reports whether response with header can be cached
+func storableHeader(header http.Header) bool {
+	control := parseCacheControl(header)
+	_, noStore := control["no-store"]
+	_, private := control["private"]
+
+	return !noStore && !private && header.Get("Set-Cookie") == ""
+}
+
+// statusRecorder records status of response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records status and writes it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// responseBuffer is response writer buffering response
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// newResponseBuffer returns empty response buffer
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: http.Header{}, status: http.StatusOK}
+}
+
+// Header returns header of response
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+// WriteHeader records status
+func (b *responseBuffer) WriteHeader(status int) {
+	b.status = status
+}
+
+// Write buffers body
+func (b *responseBuffer) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+// bodyRecorder records status and body of response
+type bodyRecorder struct {
+	statusRecorder
+	body bytes.Buffer
+}
+
+// Write records body and writes it
+func (r *bodyRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
@@ -0,0 +1,90 @@
+package httpcache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/memory"
+)
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path == "/private" {
+			w.Header().Set("Cache-Control", "private")
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, r.URL.Path+" "+r.Header.Get("Accept-Language"))
+	})
+
+	m := NewMiddleware(memory.New(),
+		WithRoute("/live/*", 0),
+		WithDefaultTTL(time.Minute),
+		WithVary("Accept-Language"),
+	)
+	h := m.Handler(handler)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		header     http.Header
+		invalidate string
+		wantStatus string
+		wantBody   string
+		wantCalls  int
+	}{
+		{name: "test miss", path: "/products", wantStatus: statusMiss, wantBody: "/products ", wantCalls: 1},
+		{name: "test hit", path: "/products", wantStatus: statusHit, wantBody: "/products ", wantCalls: 0},
+		{name: "test vary miss", path: "/products", header: http.Header{"Accept-Language": {"id"}}, wantStatus: statusMiss, wantBody: "/products id", wantCalls: 1},
+		{name: "test vary hit", path: "/products", header: http.Header{"Accept-Language": {"id"}}, wantStatus: statusHit, wantBody: "/products id", wantCalls: 0},
+		{name: "test query miss", path: "/products?page=2", wantStatus: statusMiss, wantBody: "/products ", wantCalls: 1},
+		{name: "test route disabled", path: "/live/scores", wantBody: "/live/scores ", wantCalls: 1},
+		{name: "test authorization not cached", path: "/products", header: http.Header{"Authorization": {"secret"}}, wantBody: "/products ", wantCalls: 1},
+		{name: "test private miss", path: "/private", wantStatus: statusMiss, wantBody: "/private ", wantCalls: 1},
+		{name: "test private not cached", path: "/private", wantStatus: statusMiss, wantBody: "/private ", wantCalls: 1},
+		{name: "test post invalidates", method: http.MethodPost, path: "/products", wantBody: "/products ", wantCalls: 1},
+		{name: "test invalidated", path: "/products", header: http.Header{"Accept-Language": {"id"}}, wantStatus: statusMiss, wantBody: "/products id", wantCalls: 1},
+		{name: "test invalidate hook", path: "/products", header: http.Header{"Accept-Language": {"id"}}, invalidate: "/products", wantStatus: statusMiss, wantBody: "/products id", wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.invalidate != "" {
+				if err := m.Invalidate(context.Background(), tt.invalidate); err != nil {
+					t.Errorf("Invalidate() error = %v", err)
+				}
+			}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			r := httptest.NewRequest(method, tt.path, nil)
+			for name, values := range tt.header {
+				r.Header[name] = values
+			}
+			w := httptest.NewRecorder()
+			before := calls
+
+			h.ServeHTTP(w, r)
+
+			if got := w.Header().Get(StatusHeader); got != tt.wantStatus {
+				t.Errorf("ServeHTTP() status header = %v, want %v", got, tt.wantStatus)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("ServeHTTP() body = %q, want %q", got, tt.wantBody)
+			}
+			if got := w.Header().Get("Content-Type"); got != "text/plain" {
+				t.Errorf("ServeHTTP() content type = %v, want %v", got, "text/plain")
+			}
+			if got := calls - before; got != tt.wantCalls {
+				t.Errorf("ServeHTTP() calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
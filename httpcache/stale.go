@@ -0,0 +1,88 @@
+package httpcache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Freshness is freshness of response given by Cache-Control directives of RFC 7234 and RFC 5861
+//
+// response is fresh for MaxAge, which is soft ttl of cached response, after that it may be served stale
+// while it is revalidated in background for StaleWhileRevalidate, or when revalidation fails
+// for StaleIfError, so cached response must be kept for HardTTL
+type Freshness struct {
+	// MaxAge is s-maxage, or max-age if response has no s-maxage
+	MaxAge time.Duration
+	// StaleWhileRevalidate is stale-while-revalidate
+	StaleWhileRevalidate time.Duration
+	// StaleIfError is stale-if-error
+	StaleIfError time.Duration
+	// MustRevalidate is set by must-revalidate or proxy-revalidate, stale response must not be served
+	MustRevalidate bool
+}
+
+// ParseFreshness returns freshness of Cache-Control directives of header
+func ParseFreshness(header http.Header) Freshness {
+	control := parseCacheControl(header)
+
+	var f Freshness
+	if maxAge, ok := seconds(control, "s-maxage"); ok {
+		f.MaxAge = maxAge
+	} else {
+		f.MaxAge, _ = seconds(control, "max-age")
+	}
+	f.StaleWhileRevalidate, _ = seconds(control, "stale-while-revalidate")
+	f.StaleIfError, _ = seconds(control, "stale-if-error")
+	_, mustRevalidate := control["must-revalidate"]
+	_, proxyRevalidate := control["proxy-revalidate"]
+	f.MustRevalidate = mustRevalidate || proxyRevalidate
+
+	return f
+}
+
+// SoftTTL returns how long response is fresh
+func (f Freshness) SoftTTL() time.Duration {
+	return f.MaxAge
+}
+
+// HardTTL returns how long response must be kept to be served stale
+func (f Freshness) HardTTL() time.Duration {
+	return f.MaxAge + f.staleWindow()
+}
+
+// staleWindow returns how long after expiration response may be served stale
+func (f Freshness) staleWindow() time.Duration {
+	if f.MustRevalidate {
+		return 0
+	}
+
+	return max(f.StaleWhileRevalidate, f.StaleIfError)
+}
+
+// CacheControl returns Cache-Control header value of freshness
+func (f Freshness) CacheControl() string {
+	directives := []string{"max-age=" + strconv.Itoa(int(f.MaxAge.Seconds()))}
+	if f.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate="+strconv.Itoa(int(f.StaleWhileRevalidate.Seconds())))
+	}
+	if f.StaleIfError > 0 {
+		directives = append(directives, "stale-if-error="+strconv.Itoa(int(f.StaleIfError.Seconds())))
+	}
+	if f.MustRevalidate {
+		directives = append(directives, "must-revalidate")
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// isServerError reports whether status allows serving stale response with stale-if-error
+func isServerError(status int) bool {
+	switch status {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
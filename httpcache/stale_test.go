@@ -0,0 +1,161 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/memory"
+)
+
+func TestParseFreshness(t *testing.T) {
+	tests := []struct {
+		name        string
+		control     string
+		want        Freshness
+		wantHardTTL time.Duration
+	}{
+		{name: "test max-age", control: "max-age=60", want: Freshness{MaxAge: time.Minute}, wantHardTTL: time.Minute},
+		{name: "test s-maxage", control: "max-age=60, s-maxage=10", want: Freshness{MaxAge: 10 * time.Second}, wantHardTTL: 10 * time.Second},
+		{name: "test stale", control: "max-age=60, stale-while-revalidate=30, stale-if-error=120", want: Freshness{MaxAge: time.Minute, StaleWhileRevalidate: 30 * time.Second, StaleIfError: 2 * time.Minute}, wantHardTTL: 3 * time.Minute},
+		{name: "test must-revalidate", control: "max-age=60, stale-if-error=120, must-revalidate", want: Freshness{MaxAge: time.Minute, StaleIfError: 2 * time.Minute, MustRevalidate: true}, wantHardTTL: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseFreshness(http.Header{"Cache-Control": {tt.control}})
+			if got != tt.want {
+				t.Errorf("ParseFreshness() = %+v, want %+v", got, tt.want)
+			}
+			if got := got.HardTTL(); got != tt.wantHardTTL {
+				t.Errorf("HardTTL() = %v, want %v", got, tt.wantHardTTL)
+			}
+			if got := ParseFreshness(http.Header{"Cache-Control": {got.CacheControl()}}); got != tt.want {
+				t.Errorf("CacheControl() parsed = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransport_Stale(t *testing.T) {
+	var requests, version atomic.Int32
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60, stale-while-revalidate=60, stale-if-error=600")
+		_, _ = io.WriteString(w, "v"+string(rune('0'+version.Add(1))))
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	transport := New(memory.New())
+	transport.now = func() time.Time { return now }
+	client := transport.Client()
+
+	tests := []struct {
+		name         string
+		advance      time.Duration
+		failing      bool
+		wantStatus   string
+		wantBody     string
+		wantRequests int32
+	}{
+		{name: "test miss", wantStatus: statusMiss, wantBody: "v1", wantRequests: 1},
+		{name: "test stale while revalidate", advance: 90 * time.Second, wantStatus: statusStale, wantBody: "v1", wantRequests: 1},
+		{name: "test revalidated in background", wantStatus: statusHit, wantBody: "v2", wantRequests: 0},
+		{name: "test stale if error", advance: 5 * time.Minute, failing: true, wantStatus: statusStale, wantBody: "v2", wantRequests: 1},
+		{name: "test error after stale if error", advance: 10 * time.Minute, failing: true, wantStatus: statusMiss, wantRequests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			failing.Store(tt.failing)
+			before := requests.Load()
+
+			resp, err := client.Get(server.URL + "/stale")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			transport.background.Wait()
+
+			if got := resp.Header.Get(StatusHeader); got != tt.wantStatus {
+				t.Errorf("Get() status header = %v, want %v", got, tt.wantStatus)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("Get() body = %q, want %q", body, tt.wantBody)
+			}
+			if got := requests.Load() - before; got != tt.wantRequests {
+				t.Errorf("Get() requests = %v, want %v", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Stale(t *testing.T) {
+	calls, version := 0, 0
+	failing := false
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		version++
+		w.Header().Set("Cache-Control", "stale-while-revalidate=60, stale-if-error=600")
+		_, _ = io.WriteString(w, "v"+string(rune('0'+version)))
+	})
+
+	now := time.Now()
+	m := NewMiddleware(memory.New(), WithDefaultTTL(time.Minute))
+	m.now = func() time.Time { return now }
+	h := m.Handler(handler)
+
+	tests := []struct {
+		name       string
+		advance    time.Duration
+		failing    bool
+		wantStatus string
+		wantCode   int
+		wantBody   string
+		wantCalls  int
+	}{
+		{name: "test miss", wantStatus: statusMiss, wantCode: http.StatusOK, wantBody: "v1", wantCalls: 1},
+		{name: "test stale while revalidate", advance: 90 * time.Second, wantStatus: statusStale, wantCode: http.StatusOK, wantBody: "v1", wantCalls: 1},
+		{name: "test refreshed in background", wantStatus: statusHit, wantCode: http.StatusOK, wantBody: "v2", wantCalls: 0},
+		{name: "test stale if error", advance: 5 * time.Minute, failing: true, wantStatus: statusStale, wantCode: http.StatusOK, wantBody: "v2", wantCalls: 1},
+		{name: "test recovered", wantStatus: statusMiss, wantCode: http.StatusOK, wantBody: "v3", wantCalls: 1},
+		{name: "test error after stale if error", advance: 15 * time.Minute, failing: true, wantStatus: statusMiss, wantCode: http.StatusInternalServerError, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			failing = tt.failing
+			before := calls
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stale", nil))
+			m.background.Wait()
+
+			if got := w.Header().Get(StatusHeader); got != tt.wantStatus {
+				t.Errorf("ServeHTTP() status header = %v, want %v", got, tt.wantStatus)
+			}
+			if w.Code != tt.wantCode {
+				t.Errorf("ServeHTTP() code = %v, want %v", w.Code, tt.wantCode)
+			}
+			if got := w.Body.String(); got != tt.wantBody {
+				t.Errorf("ServeHTTP() body = %q, want %q", got, tt.wantBody)
+			}
+			if got := calls - before; got != tt.wantCalls {
+				t.Errorf("ServeHTTP() calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// Tracer starts spans around cache operations
+type Tracer interface {
+	// Start starts span of operation on key
+	Start(ctx context.Context, op Operation, key string) (context.Context, Span)
+}
+
+// Span is a traced cache operation
+type Span interface {
+	// End ends span with result and error of operation
+	End(result Result, err error)
+}
+
+// instrumented is cacher decorated with metrics, logging and tracing
+type instrumented struct {
+	Cacher
+	metrics Metrics
+	logger  *internal.Logger
+	tracer  Tracer
+	backend string
+}
+
+// Instrument returns cacher which records metrics, logs and traces every operation of c
+// any of metrics, logger and tracer may be nil to disable it
+func Instrument(c Cacher, metrics Metrics, logger *slog.Logger, tracer Tracer) Cacher {
+	return &instrumented{
+		Cacher:  c,
+		metrics: metrics,
+		logger:  internal.NewLogger(logger, slog.LevelDebug, slog.LevelWarn),
+		tracer:  tracer,
+	}
+}
+
+// observe starts instrumentation of operation and returns function to finish it
+func (i *instrumented) observe(ctx context.Context, op Operation, key string) (context.Context, func(any, error)) {
+	start := time.Now()
+
+	var span Span
+	if i.tracer != nil {
+		ctx, span = i.tracer.Start(ctx, op, key)
+		setAttribute(span, AttrBackend, i.backend)
+		setAttribute(span, AttrKeyPrefix, keyPrefix(key))
+	}
+
+	return ctx, func(value any, err error) {
+		result := resultOf(op, value, err)
+
+		if i.metrics != nil {
+			i.metrics.Observe(op, result, time.Since(start))
+		}
+
+		i.logger.Operation(ctx, string(op), key, start, err)
+
+		if span != nil {
+			span.End(result, err)
+		}
+	}
+}
+
+// observeSize records size of value if metrics record sizes and size is known
+func (i *instrumented) observeSize(op Operation, value any, err error) {
+	if err != nil || value == nil {
+		return
+	}
+
+	if metrics, ok := i.metrics.(SizeMetrics); ok {
+		if size, ok := sizeOf(value); ok {
+			metrics.ObserveSize(op, size)
+		}
+	}
+}
+
+// Set sets key-value to cache
+func (i *instrumented) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	ctx, done := i.observe(ctx, OpSet, key)
+	err := i.Cacher.Set(ctx, key, value, options...)
+	done(nil, err)
+	i.observeSize(OpSet, value, err)
+
+	return err
+}
+
+// Get gets value from cache
+func (i *instrumented) Get(ctx context.Context, key string) (any, error) {
+	ctx, done := i.observe(ctx, OpGet, key)
+	value, err := i.Cacher.Get(ctx, key)
+	done(value, err)
+	i.observeSize(OpGet, value, err)
+
+	return value, err
+}
+
+// Delete deletes value from cache
+func (i *instrumented) Delete(ctx context.Context, key string) error {
+	ctx, done := i.observe(ctx, OpDelete, key)
+	err := i.Cacher.Delete(ctx, key)
+	done(nil, err)
+
+	return err
+}
+
+// Load loads multiple key-values into cache
+func (i *instrumented) Load(ctx context.Context, data map[string]any) error {
+	ctx, done := i.observe(ctx, OpLoad, "")
+	err := i.Cacher.Load(ctx, data)
+	done(nil, err)
+
+	return err
+}
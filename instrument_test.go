@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingTracer records ended spans
+type recordingTracer struct {
+	ended []Result
+}
+
+func (r *recordingTracer) Start(ctx context.Context, _ Operation, _ string) (context.Context, Span) {
+	return ctx, r
+}
+
+func (r *recordingTracer) End(result Result, _ error) {
+	r.ended = append(r.ended, result)
+}
+
+func TestInstrument(t *testing.T) {
+	m := newMapCacher()
+	stats := &Stats{}
+	tracer := &recordingTracer{}
+	buf := &bytes.Buffer{}
+	c := Instrument(m, stats, slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), tracer)
+
+	ctx := context.Background()
+	_ = c.Set(ctx, "key", "value")
+	_, _ = c.Get(ctx, "key")
+	_, _ = c.Get(ctx, "missing")
+	_ = c.Delete(ctx, "key")
+	_ = c.Load(ctx, map[string]any{"key": "value"})
+	m.getErr = errors.New("failed")
+	_, _ = c.Get(ctx, "key")
+
+	got := stats.Snapshot()
+	if got.Hits != 1 || got.Misses != 1 || got.Sets != 1 || got.Deletes != 1 || got.Loads != 1 || got.Errors != 1 {
+		t.Errorf("Instrument() stats = %+v, want one of each", got)
+	}
+	if got.Latency[OpGet].Count != 3 {
+		t.Errorf("Instrument() get latency count = %v, want %v", got.Latency[OpGet].Count, 3)
+	}
+	if got.Size[OpSet].Sum != int64(len("value")) {
+		t.Errorf("Instrument() set size sum = %v, want %v", got.Size[OpSet].Sum, len("value"))
+	}
+	if got := len(tracer.ended); got != 6 {
+		t.Errorf("Instrument() spans = %v, want %v", got, 6)
+	}
+	if got := strings.Count(buf.String(), "cache operation"); got != 6 {
+		t.Errorf("Instrument() log records = %v, want %v", got, 6)
+	}
+}
+
+func TestInstrument_Nil(t *testing.T) {
+	c := Instrument(newMapCacher(), nil, nil, nil)
+	if err := c.Set(context.Background(), "key", "value"); err != nil {
+		t.Errorf("Instrument().Set() error = %v", err)
+	}
+	if got, _ := c.Get(context.Background(), "key"); got != "value" {
+		t.Errorf("Instrument().Get() = %v, want %v", got, "value")
+	}
+}
+
+func TestStatsSnapshot_HitRatio(t *testing.T) {
+	tests := []struct {
+		name string
+		s    StatsSnapshot
+		want float64
+	}{
+		{name: "test no gets", s: StatsSnapshot{}, want: 0},
+		{name: "test hits and misses", s: StatsSnapshot{Hits: 3, Misses: 1}, want: 0.75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.HitRatio(); got != tt.want {
+				t.Errorf("StatsSnapshot.HitRatio() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStats_Distribution(t *testing.T) {
+	stats := NewStats("orders")
+	for i := 1; i <= 100; i++ {
+		stats.Observe(OpGet, ResultHit, time.Duration(i)*time.Millisecond)
+	}
+
+	got := stats.Snapshot()
+	if got.Name != "orders" {
+		t.Errorf("Stats.Snapshot() name = %v, want %v", got.Name, "orders")
+	}
+
+	latency := got.Latency[OpGet]
+	if latency.P50 > latency.P95 || latency.P95 > latency.P99 || latency.P99 > int64(100*time.Millisecond) {
+		t.Errorf("Stats.Snapshot() latency = %+v, want ordered percentiles up to 100ms", latency)
+	}
+	if latency.P99 < int64(64*time.Millisecond) {
+		t.Errorf("Stats.Snapshot() p99 = %v, want at least 64ms", time.Duration(latency.P99))
+	}
+}
+
+func TestWithMetrics(t *testing.T) {
+	ctx := context.Background()
+	stats := NewStats("patterned")
+	c, err := New(newMapCacher(), nil, WithMetrics(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = c.Set(ctx, "a", "one")
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "b")
+	_, _ = c.GetMany(ctx, []string{"a", "b"})
+	_ = c.Delete(ctx, "a")
+
+	snapshot := stats.Snapshot()
+	if snapshot.Sets != 1 || snapshot.Hits != 2 || snapshot.Misses != 2 || snapshot.Deletes != 1 {
+		t.Errorf("snapshot = %+v, want 1 set, 2 hits, 2 misses and 1 delete", snapshot)
+	}
+	if snapshot.Size[OpSet].Count != 1 {
+		t.Errorf("size of set observed %d times, want 1", snapshot.Size[OpSet].Count)
+	}
+}
+
+func TestWithMetrics_ReadThrough(t *testing.T) {
+	ctx := context.Background()
+	stats := NewStats("patterned")
+	persister := newMapPersister()
+	persister.data["a"] = "one"
+	persister.data["b"] = "two"
+	c, err := New(newMapCacher(), persister, WithPattern(&ReadThrough{}), WithMetrics(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.GetMany(ctx, []string{"a", "b"})
+
+	snapshot := stats.Snapshot()
+	if snapshot.Hits != 2 || snapshot.Misses != 2 {
+		t.Errorf("snapshot = %+v, want 2 hits and 2 misses of loaded values", snapshot)
+	}
+}
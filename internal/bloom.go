@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"hash/maphash"
+	"math"
+	"sync"
+)
+
+// Bloom is bloom filter of keys, it has no false negatives
+// and false positive rate close to the one it is sized for until capacity keys are added
+type Bloom struct {
+	mu     sync.RWMutex
+	seed   maphash.Seed
+	bits   []uint64
+	size   uint64
+	hashes int
+}
+
+// NewBloom returns bloom filter sized for capacity keys with false positive rate
+func NewBloom(capacity int, falsePositive float64) *Bloom {
+	if capacity < 1 {
+		capacity = 1
+	}
+	if falsePositive <= 0 || falsePositive >= 1 {
+		falsePositive = 0.01
+	}
+
+	size := uint64(math.Ceil(-float64(capacity) * math.Log(falsePositive) / (math.Ln2 * math.Ln2)))
+	if size < 64 {
+		size = 64
+	}
+	hashes := int(math.Round(float64(size) / float64(capacity) * math.Ln2))
+	if hashes < 1 {
+		hashes = 1
+	}
+
+	return &Bloom{seed: maphash.MakeSeed(), bits: make([]uint64, (size+63)/64), size: size, hashes: hashes}
+}
+
+// positions returns base and step of double hashing of key
+func (b *Bloom) positions(key string) (uint64, uint64) {
+	h := maphash.String(b.seed, key)
+
+	return h, h>>32 | 1
+}
+
+// Add adds key to filter
+func (b *Bloom) Add(key string) {
+	h, step := b.positions(key)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i := 0; i < b.hashes; i++ {
+		bit := (h + uint64(i)*step) % b.size
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// MayContain returns false if key is certainly not added and true if it may be added
+func (b *Bloom) MayContain(key string) bool {
+	h, step := b.positions(key)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for i := 0; i < b.hashes; i++ {
+		bit := (h + uint64(i)*step) % b.size
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Reset removes all keys from filter
+func (b *Bloom) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	clear(b.bits)
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBloom(t *testing.T) {
+	b := NewBloom(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		b.Add("key" + strconv.Itoa(i))
+	}
+
+	for i := 0; i < 1000; i++ {
+		if !b.MayContain("key" + strconv.Itoa(i)) {
+			t.Fatalf("Bloom.MayContain(key%v) = false, want true", i)
+		}
+	}
+
+	positives := 0
+	for i := 0; i < 10000; i++ {
+		if b.MayContain("other" + strconv.Itoa(i)) {
+			positives++
+		}
+	}
+	if positives > 300 {
+		t.Errorf("Bloom.MayContain() false positives = %v of 10000, want at most %v", positives, 300)
+	}
+
+	b.Reset()
+	if b.MayContain("key0") {
+		t.Errorf("Bloom.MayContain() after Reset() = true, want false")
+	}
+}
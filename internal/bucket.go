@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket is token bucket rate limiter
+// tokens are refilled continuously at rate per second up to burst
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+	after  func(time.Duration) <-chan time.Time
+}
+
+// NewTokenBucket returns full token bucket
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+		after:  time.After,
+	}
+}
+
+// SetClock sets functions telling time and waiting for tokens, bucket is refilled from now
+func (b *TokenBucket) SetClock(now func() time.Time, after func(time.Duration) <-chan time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.now, b.after = now, after
+	b.last = now()
+}
+
+// refill adds tokens accumulated since last refill, must be called with lock held
+func (b *TokenBucket) refill() {
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+}
+
+// Allow takes a token if one is available without waiting
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+// Wait takes a token, waiting until one is available or context is done
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	b.refill()
+	b.tokens--
+	deficit := -b.tokens
+	b.mu.Unlock()
+
+	if deficit <= 0 {
+		return nil
+	}
+
+	select {
+	case <-b.after(time.Duration(deficit / b.rate * float64(time.Second))):
+		return nil
+	case <-ctx.Done():
+		// give back reserved token as operation will not be executed
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket_Allow(t *testing.T) {
+	now := time.Now()
+	b := NewTokenBucket(1, 2)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	for i, want := range []bool{true, true, false} {
+		if got := b.Allow(); got != want {
+			t.Errorf("TokenBucket.Allow() #%d = %v, want %v", i, got, want)
+		}
+	}
+
+	now = now.Add(time.Second)
+	if got := b.Allow(); !got {
+		t.Errorf("TokenBucket.Allow() after refill = %v, want %v", got, true)
+	}
+}
+
+func TestTokenBucket_Wait(t *testing.T) {
+	b := NewTokenBucket(1000, 1)
+	if err := b.Wait(context.Background()); err != nil {
+		t.Errorf("TokenBucket.Wait() error = %v", err)
+	}
+	if err := b.Wait(context.Background()); err != nil {
+		t.Errorf("TokenBucket.Wait() error = %v", err)
+	}
+
+	slow := NewTokenBucket(0.001, 1)
+	slow.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := slow.Wait(ctx); err == nil {
+		t.Errorf("TokenBucket.Wait() error = %v, want deadline exceeded", err)
+	}
+}
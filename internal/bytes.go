@@ -0,0 +1,13 @@
+package internal
+
+// BytesOf returns bytes of bytes or string value, string is copied so returned bytes are owned by caller
+func BytesOf(value any) ([]byte, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return v, true
+	case string:
+		return []byte(v), true
+	default:
+		return nil, false
+	}
+}
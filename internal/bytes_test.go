@@ -0,0 +1,27 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBytesOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  any
+		want   []byte
+		wantOk bool
+	}{
+		{name: "test bytes", value: []byte("value"), want: []byte("value"), wantOk: true},
+		{name: "test string", value: "value", want: []byte("value"), wantOk: true},
+		{name: "test other", value: 1, want: nil, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := BytesOf(tt.value)
+			if !reflect.DeepEqual(got, tt.want) || ok != tt.wantOk {
+				t.Errorf("BytesOf() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// ErrLineTooLong is returned by ReadLine when line is longer than its limit
+var ErrLineTooLong = errors.New("line too long")
+
+// ReadLine reads line of request without trailing CRLF, ErrLineTooLong is returned once line exceeds max bytes,
+// so client can not grow it without bound
+func ReadLine(r *bufio.Reader, max int) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(line)+len(chunk) > max {
+			return "", ErrLineTooLong
+		}
+		line = append(line, chunk...)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return "", err
+		}
+	}
+
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+// ConnServer accepts connections and serves each of them in its own goroutine until closed
+type ConnServer struct {
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	serving  sync.WaitGroup
+}
+
+// Serve accepts connections on listener and serves them with serve, connection is closed after serve returns
+// it always returns non-nil error, errClosed after Close
+func (s *ConnServer) Serve(listener net.Listener, serve func(net.Conn), errClosed error) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return errClosed
+	}
+	s.listener = listener
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return errClosed
+			}
+			return err
+		}
+
+		if !s.track(conn) {
+			_ = conn.Close()
+			return errClosed
+		}
+
+		go func() {
+			defer s.untrack(conn)
+			serve(conn)
+		}()
+	}
+}
+
+// Close closes listener and all connections and waits for them to finish
+func (s *ConnServer) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.serving.Wait()
+
+	return err
+}
+
+// track registers connection, false is returned if server is closed
+func (s *ConnServer) track(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+	if s.conns == nil {
+		s.conns = make(map[net.Conn]struct{})
+	}
+	s.conns[conn] = struct{}{}
+	s.serving.Add(1)
+
+	return true
+}
+
+// untrack closes and unregisters connection
+func (s *ConnServer) untrack(conn net.Conn) {
+	_ = conn.Close()
+
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+
+	s.serving.Done()
+}
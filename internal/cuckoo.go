@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
+const (
+	// cuckooBucketSize is number of fingerprints per bucket
+	cuckooBucketSize = 4
+	// cuckooMaxKicks is number of relocations tried before insert fails
+	cuckooMaxKicks = 500
+)
+
+// Cuckoo is cuckoo filter of keys with 16-bit fingerprints,
+// unlike bloom filter it supports removal of keys
+// key is added once, removing key may hide other key with the same fingerprint and bucket,
+// which is as likely as false positive
+type Cuckoo struct {
+	mu      sync.RWMutex
+	seed    maphash.Seed
+	buckets [][cuckooBucketSize]uint16
+	mask    uint64
+	count   int
+}
+
+// NewCuckoo returns cuckoo filter for about capacity keys
+// it is sized for load of 80%, inserts start failing at about 95%
+func NewCuckoo(capacity int) *Cuckoo {
+	n := uint64(capacity*5/4+cuckooBucketSize-1) / cuckooBucketSize
+	if n < 1 {
+		n = 1
+	}
+	// number of buckets is power of two so alternate bucket can be computed from fingerprint
+	n = 1 << bits.Len64(n-1)
+
+	return &Cuckoo{seed: maphash.MakeSeed(), buckets: make([][cuckooBucketSize]uint16, n), mask: n - 1}
+}
+
+// locate returns fingerprint and buckets of key
+func (c *Cuckoo) locate(key string) (uint16, uint64, uint64) {
+	h := maphash.String(c.seed, key)
+	fp := uint16(h >> 48)
+	if fp == 0 {
+		fp = 1
+	}
+	i := h & c.mask
+
+	return fp, i, c.alternate(i, fp)
+}
+
+// alternate returns other bucket of fingerprint in bucket i
+func (c *Cuckoo) alternate(i uint64, fp uint16) uint64 {
+	return (i ^ (uint64(fp) * 0x5bd1e995)) & c.mask
+}
+
+// has reports whether bucket i holds fingerprint, must be called with lock held
+func (c *Cuckoo) has(i uint64, fp uint16) bool {
+	for _, f := range c.buckets[i] {
+		if f == fp {
+			return true
+		}
+	}
+
+	return false
+}
+
+// put puts fingerprint to free slot of bucket i, must be called with lock held
+func (c *Cuckoo) put(i uint64, fp uint16) bool {
+	for slot, f := range c.buckets[i] {
+		if f == 0 {
+			c.buckets[i][slot] = fp
+			return true
+		}
+	}
+
+	return false
+}
+
+// Add adds key to filter, false is returned if filter is full
+func (c *Cuckoo) Add(key string) bool {
+	fp, i1, i2 := c.locate(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.has(i1, fp) || c.has(i2, fp) {
+		return true
+	}
+	if c.put(i1, fp) || c.put(i2, fp) {
+		c.count++
+		return true
+	}
+
+	// relocate random fingerprints to their alternate buckets, restoring filter if it fails
+	i := i1
+	if rand.Intn(2) == 1 {
+		i = i2
+	}
+	type kick struct {
+		bucket uint64
+		slot   int
+	}
+	kicks := make([]kick, 0, cuckooMaxKicks)
+	for n := 0; n < cuckooMaxKicks; n++ {
+		slot := rand.Intn(cuckooBucketSize)
+		fp, c.buckets[i][slot] = c.buckets[i][slot], fp
+		kicks = append(kicks, kick{bucket: i, slot: slot})
+
+		i = c.alternate(i, fp)
+		if c.put(i, fp) {
+			c.count++
+			return true
+		}
+	}
+	for n := len(kicks) - 1; n >= 0; n-- {
+		k := kicks[n]
+		fp, c.buckets[k.bucket][k.slot] = c.buckets[k.bucket][k.slot], fp
+	}
+
+	return false
+}
+
+// Contains returns false if key is certainly not in filter and true if it may be
+func (c *Cuckoo) Contains(key string) bool {
+	fp, i1, i2 := c.locate(key)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.has(i1, fp) || c.has(i2, fp)
+}
+
+// Remove removes key from filter, false is returned if it is not found
+func (c *Cuckoo) Remove(key string) bool {
+	fp, i1, i2 := c.locate(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, i := range []uint64{i1, i2} {
+		for slot, f := range c.buckets[i] {
+			if f == fp {
+				c.buckets[i][slot] = 0
+				c.count--
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Len returns number of keys in filter
+func (c *Cuckoo) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.count
+}
+
+// Reset removes all keys from filter
+func (c *Cuckoo) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.buckets)
+	c.count = 0
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestCuckoo(t *testing.T) {
+	c := NewCuckoo(1000)
+	for i := 0; i < 1000; i++ {
+		if !c.Add("key" + strconv.Itoa(i)) {
+			t.Fatalf("Cuckoo.Add(key%v) = false, want true", i)
+		}
+	}
+	// keys sharing fingerprint and bucket are added once
+	if got := c.Len(); got < 995 || got > 1000 {
+		t.Errorf("Cuckoo.Len() = %v, want about %v", got, 1000)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if !c.Contains("key" + strconv.Itoa(i)) {
+			t.Fatalf("Cuckoo.Contains(key%v) = false, want true", i)
+		}
+	}
+
+	positives := 0
+	for i := 0; i < 10000; i++ {
+		if c.Contains("other" + strconv.Itoa(i)) {
+			positives++
+		}
+	}
+	if positives > 100 {
+		t.Errorf("Cuckoo.Contains() false positives = %v of 10000, want at most %v", positives, 100)
+	}
+
+	for i := 0; i < 500; i++ {
+		c.Remove("key" + strconv.Itoa(i))
+	}
+	removed := 0
+	for i := 0; i < 500; i++ {
+		if !c.Contains("key" + strconv.Itoa(i)) {
+			removed++
+		}
+	}
+	if removed < 490 {
+		t.Errorf("Cuckoo.Remove() removed = %v of 500, want at least %v", removed, 490)
+	}
+	if !c.Contains("key999") {
+		t.Errorf("Cuckoo.Contains(key999) = false, want true")
+	}
+
+	c.Reset()
+	if c.Contains("key999") || c.Len() != 0 {
+		t.Errorf("Cuckoo.Reset() kept keys")
+	}
+}
+
+func TestCuckoo_Full(t *testing.T) {
+	c := NewCuckoo(4)
+	full := false
+	for i := 0; i < 100 && !full; i++ {
+		full = !c.Add("key" + strconv.Itoa(i))
+	}
+	if !full {
+		t.Fatalf("Cuckoo.Add() never failed")
+	}
+	if got := c.Len(); got > 8 {
+		t.Errorf("Cuckoo.Len() = %v, want at most %v", got, 8)
+	}
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+)
+
+// Histogram counts observations in buckets with fixed upper bounds
+type Histogram struct {
+	bounds []int64
+	counts []atomic.Int64
+	count  atomic.Int64
+	sum    atomic.Int64
+	max    atomic.Int64
+}
+
+// NewHistogram returns histogram with ascending bucket upper bounds
+// observations above the last bound are counted in an overflow bucket
+func NewHistogram(bounds []int64) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]atomic.Int64, len(bounds)+1),
+	}
+}
+
+// ExponentialBounds returns n bounds starting at start, each factor times the previous
+func ExponentialBounds(start int64, factor float64, n int) []int64 {
+	bounds := make([]int64, n)
+	bound := float64(start)
+	for i := range bounds {
+		bounds[i] = int64(bound)
+		bound *= factor
+	}
+
+	return bounds
+}
+
+// Observe records value
+func (h *Histogram) Observe(value int64) {
+	i := sort.Search(len(h.bounds), func(i int) bool { return h.bounds[i] >= value })
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(value)
+
+	for {
+		max := h.max.Load()
+		if value <= max || h.max.CompareAndSwap(max, value) {
+			break
+		}
+	}
+}
+
+// Count returns number of observations
+func (h *Histogram) Count() int64 {
+	return h.count.Load()
+}
+
+// Sum returns sum of observations
+func (h *Histogram) Sum() int64 {
+	return h.sum.Load()
+}
+
+// Buckets returns bucket upper bounds and cumulative counts of observations
+// less than or equal to each bound
+func (h *Histogram) Buckets() ([]int64, []int64) {
+	cumulative := make([]int64, len(h.bounds))
+	var total int64
+	for i := range h.bounds {
+		total += h.counts[i].Load()
+		cumulative[i] = total
+	}
+
+	return h.bounds, cumulative
+}
+
+// Quantile returns estimated value below which q of observations fall, q is between 0 and 1
+// estimate is the upper bound of the bucket containing the quantile, capped by maximum observation
+func (h *Histogram) Quantile(q float64) int64 {
+	count := h.count.Load()
+	if count == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(q * float64(count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	max := h.max.Load()
+	var cumulative int64
+	for i := range h.bounds {
+		cumulative += h.counts[i].Load()
+		if cumulative >= rank {
+			if h.bounds[i] > max {
+				return max
+			}
+			return h.bounds[i]
+		}
+	}
+
+	return max
+}
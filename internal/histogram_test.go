@@ -0,0 +1,37 @@
+package internal
+
+import "testing"
+
+func TestHistogram_Quantile(t *testing.T) {
+	h := NewHistogram(ExponentialBounds(1, 2, 10))
+	for i := int64(1); i <= 100; i++ {
+		h.Observe(i)
+	}
+
+	tests := []struct {
+		name string
+		q    float64
+		want int64
+	}{
+		{name: "test p50", q: 0.5, want: 64},
+		{name: "test p99", q: 0.99, want: 100},
+		{name: "test p10", q: 0.1, want: 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.Quantile(tt.q); got != tt.want {
+				t.Errorf("Histogram.Quantile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := h.Count(); got != 100 {
+		t.Errorf("Histogram.Count() = %v, want %v", got, 100)
+	}
+	if got := h.Sum(); got != 5050 {
+		t.Errorf("Histogram.Sum() = %v, want %v", got, 5050)
+	}
+	if got := NewHistogram(ExponentialBounds(1, 2, 10)).Quantile(0.5); got != 0 {
+		t.Errorf("Histogram.Quantile() of empty histogram = %v, want %v", got, 0)
+	}
+}
@@ -0,0 +1,15 @@
+package internal
+
+import "sort"
+
+// SortedKeys returns keys of map in ascending order
+// so operations on multiple keys are issued in deterministic order
+func SortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"time"
+)
+
+// Logger emits structured records of cache operations
+// a nil logger discards all records
+type Logger struct {
+	logger     *slog.Logger
+	level      slog.Level
+	errorLevel slog.Level
+}
+
+// NewLogger returns logger which logs successful operations at level
+// and failed operations at errorLevel
+// if logger is nil, nil is returned and all records are discarded
+func NewLogger(logger *slog.Logger, level, errorLevel slog.Level) *Logger {
+	if logger == nil {
+		return nil
+	}
+
+	return &Logger{logger: logger, level: level, errorLevel: errorLevel}
+}
+
+// With returns logger with attributes added to every record
+func (l *Logger) With(args ...any) *Logger {
+	if l == nil {
+		return nil
+	}
+
+	return &Logger{logger: l.logger.With(args...), level: l.level, errorLevel: l.errorLevel}
+}
+
+// Operation logs the outcome of an operation on key started at start
+func (l *Logger) Operation(ctx context.Context, op, key string, start time.Time, err error) {
+	if l == nil {
+		return
+	}
+
+	level := l.level
+	if err != nil {
+		level = l.errorLevel
+	}
+
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("op", op),
+		slog.String("key_hash", HashKey(key)),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	l.logger.LogAttrs(ctx, level, "cache operation", attrs...)
+}
+
+// Error logs a failure that does not fail the operation itself,
+// e.g. a failed cache backfill after reading from persistence storage
+func (l *Logger) Error(ctx context.Context, msg, op, key string, err error) {
+	if l == nil {
+		return
+	}
+
+	l.logger.LogAttrs(ctx, l.errorLevel, msg,
+		slog.String("op", op),
+		slog.String("key_hash", HashKey(key)),
+		slog.String("error", err.Error()))
+}
+
+// HashKey returns hex encoded FNV-1a hash of key
+// keys may contain sensitive data so only hash is logged
+func HashKey(key string) string {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+
+	return fmt.Sprintf("%016x", h.Sum64())
+}
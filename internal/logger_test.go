@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogger_Operation(t *testing.T) {
+	type args struct {
+		op  string
+		key string
+		err error
+	}
+	tests := []struct {
+		name      string
+		level     slog.Level
+		args      args
+		want      []string
+		wantEmpty bool
+	}{
+		{
+			name:  "test successful operation",
+			level: slog.LevelInfo,
+			args:  args{op: "get", key: "key"},
+			want:  []string{"level=INFO", "op=get", "key_hash=" + HashKey("key"), "duration="},
+		},
+		{
+			name:  "test failed operation",
+			level: slog.LevelInfo,
+			args:  args{op: "set", key: "key", err: errors.New("failed")},
+			want:  []string{"level=WARN", "op=set", "error=failed"},
+		},
+		{
+			name:      "test operation below handler level",
+			level:     slog.LevelDebug,
+			args:      args{op: "get", key: "key"},
+			wantEmpty: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := NewLogger(slog.New(slog.NewTextHandler(buf, nil)), tt.level, slog.LevelWarn)
+			l.Operation(context.Background(), tt.args.op, tt.args.key, time.Now(), tt.args.err)
+
+			got := buf.String()
+			if tt.wantEmpty && got != "" {
+				t.Errorf("Logger.Operation() = %v, want empty", got)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("Logger.Operation() = %v, want contains %v", got, want)
+				}
+			}
+			if strings.Contains(got, "key="+tt.args.key) {
+				t.Errorf("Logger.Operation() = %v, want key not logged", got)
+			}
+		})
+	}
+}
+
+func TestLogger_Nil(t *testing.T) {
+	var l *Logger
+	l.Operation(context.Background(), "get", "key", time.Now(), nil)
+	l.Error(context.Background(), "failed", "get", "key", errors.New("failed"))
+
+	if got := l.With("backend", "redis"); got != nil {
+		t.Errorf("Logger.With() = %v, want nil", got)
+	}
+
+	if got := NewLogger(nil, slog.LevelDebug, slog.LevelWarn); got != nil {
+		t.Errorf("NewLogger() = %v, want nil", got)
+	}
+}
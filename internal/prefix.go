@@ -1,10 +1,15 @@
 package internal
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // KeyPrefix adds prefix to key
 type KeyPrefix interface {
 	Prefix(string) string
+	// Unprefix removes prefix from prefixed key
+	Unprefix(string) string
 }
 
 // WithPrefix add name as prefix to key
@@ -17,6 +22,11 @@ func (wp *WithPrefix) Prefix(key string) string {
 	return fmt.Sprintf("%s.%s", wp.Name, key)
 }
 
+// Unprefix returns key without name prefix
+func (wp *WithPrefix) Unprefix(key string) string {
+	return strings.TrimPrefix(key, wp.Name+".")
+}
+
 // NoPrefix adds no prefix
 type NoPrefix struct {
 }
@@ -25,3 +35,8 @@ type NoPrefix struct {
 func (np *NoPrefix) Prefix(key string) string {
 	return key
 }
+
+// Unprefix returns original key
+func (np *NoPrefix) Unprefix(key string) string {
+	return key
+}
@@ -62,3 +62,22 @@ func TestNoPrefix_Prefix(t *testing.T) {
 		})
 	}
 }
+
+func TestWithPrefix_Unprefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix KeyPrefix
+		key    string
+		want   string
+	}{
+		{name: "test with prefix", prefix: &WithPrefix{Name: "test"}, key: "test.key", want: "key"},
+		{name: "test without prefix", prefix: &NoPrefix{}, key: "test.key", want: "test.key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.prefix.Unprefix(tt.key); got != tt.want {
+				t.Errorf("KeyPrefix.Unprefix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
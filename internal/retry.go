@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Retry runs operations with deadline per attempt and retries transient failures
+// with exponential backoff and jitter, zero Retry runs operations once without deadline
+type Retry struct {
+	// Timeout is deadline of each attempt, zero means attempts have no deadline of their own
+	Timeout time.Duration
+	// Attempts is maximum number of attempts in total, less than 2 means operations are not retried
+	Attempts int
+	// Backoff is wait before second attempt, it is doubled before each next attempt
+	Backoff time.Duration
+	// Transient reports whether failure is worth retrying
+	Transient func(error) bool
+}
+
+// Enabled reports whether retry changes how operations run
+func (r *Retry) Enabled() bool {
+	return r.Timeout > 0 || r.Attempts > 1
+}
+
+// Do calls fn until it succeeds, fails with error which is not transient, attempts are exhausted or ctx is done,
+// attempt which runs out of its own deadline fails with context.DeadlineExceeded which is transient when ctx is not done
+func (r *Retry) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	backoff := r.Backoff
+	for attempt := 1; ; attempt++ {
+		err := r.attempt(ctx, fn)
+		if err == nil || attempt >= r.Attempts || ctx.Err() != nil || r.Transient == nil || !r.Transient(err) {
+			return err
+		}
+
+		timer := time.NewTimer(jitter(backoff))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// attempt calls fn with deadline of attempt
+func (r *Retry) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.Timeout <= 0 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	return fn(ctx)
+}
+
+// jitter returns random duration between half of backoff and backoff, so clients failing together do not retry together
+func jitter(backoff time.Duration) time.Duration {
+	if backoff <= 1 {
+		return backoff
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry_Do(t *testing.T) {
+	errTransient := errors.New("transient")
+	errPermanent := errors.New("permanent")
+	transient := func(err error) bool {
+		return errors.Is(err, errTransient) || errors.Is(err, context.DeadlineExceeded)
+	}
+
+	tests := []struct {
+		name      string
+		retry     Retry
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "succeeds",
+			retry:     Retry{Attempts: 3, Transient: transient},
+			errs:      []error{nil},
+			wantCalls: 1,
+		},
+		{
+			name:      "retries transient failure",
+			retry:     Retry{Attempts: 3, Backoff: time.Millisecond, Transient: transient},
+			errs:      []error{errTransient, errTransient, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "exhausts attempts",
+			retry:     Retry{Attempts: 2, Backoff: time.Millisecond, Transient: transient},
+			errs:      []error{errTransient, errTransient, nil},
+			wantErr:   errTransient,
+			wantCalls: 2,
+		},
+		{
+			name:      "does not retry permanent failure",
+			retry:     Retry{Attempts: 3, Transient: transient},
+			errs:      []error{errPermanent, nil},
+			wantErr:   errPermanent,
+			wantCalls: 1,
+		},
+		{
+			name:      "without attempts runs once",
+			retry:     Retry{Transient: transient},
+			errs:      []error{errTransient, nil},
+			wantErr:   errTransient,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := tt.retry.Do(context.Background(), func(context.Context) error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Do() calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetry_DoTimeout(t *testing.T) {
+	retry := Retry{Timeout: 10 * time.Millisecond, Attempts: 2, Transient: func(err error) bool {
+		return errors.Is(err, context.DeadlineExceeded)
+	}}
+
+	calls := 0
+	err := retry.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Do() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Do() calls = %d, want 2", calls)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		if got := jitter(100 * time.Millisecond); got < 50*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("jitter() = %v, want between 50ms and 100ms", got)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package internal
+
+import "sync"
+
+// call is an in-flight or completed Group.Do call
+type call struct {
+	wg    sync.WaitGroup
+	value any
+	err   error
+}
+
+// Group collapses concurrent calls with the same key into one execution
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// Do executes fn once for all concurrent callers with the same key
+// and returns its result to every caller, shared reports whether
+// the result was given to multiple callers
+func (g *Group) Do(key string, fn func() (any, error)) (value any, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+
+		return c.value, c.err, true
+	}
+
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+
+	c.value, c.err = fn()
+
+	return c.value, c.err, false
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGroup_Do(t *testing.T) {
+	g := &Group{}
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]any, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, _ = g.Do("key", func() (any, error) {
+				if calls.Add(1) == 1 {
+					close(started)
+				}
+				<-release
+				return "value", nil
+			})
+		}(i)
+	}
+
+	<-started
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got < 1 || got > 5 {
+		t.Errorf("Group.Do() calls = %v, want between 1 and 5", got)
+	}
+	for i, got := range results {
+		if got != "value" {
+			t.Errorf("Group.Do() result #%d = %v, want %v", i, got, "value")
+		}
+	}
+
+	value, err, shared := g.Do("key", func() (any, error) { return "other", nil })
+	if value != "other" || err != nil || shared {
+		t.Errorf("Group.Do() = %v, %v, %v, want other, nil, false", value, err, shared)
+	}
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// sketchDepth is number of hash rows of count-min sketch
+const sketchDepth = 4
+
+// Sketch is count-min sketch estimating access frequency of keys
+// counters saturate at 15 and are halved after every width*10 increments,
+// so old popularity decays as in TinyLFU
+type Sketch struct {
+	mu        sync.Mutex
+	seeds     [sketchDepth]maphash.Seed
+	counters  [sketchDepth][]uint8
+	additions int
+	resetAt   int
+}
+
+// NewSketch returns sketch with width counters per row
+func NewSketch(width int) *Sketch {
+	if width < 16 {
+		width = 16
+	}
+
+	s := &Sketch{resetAt: width * 10}
+	for i := range s.counters {
+		s.seeds[i] = maphash.MakeSeed()
+		s.counters[i] = make([]uint8, width)
+	}
+
+	return s
+}
+
+// index returns counter index of key in row
+func (s *Sketch) index(row int, key string) int {
+	return int(maphash.String(s.seeds[row], key) % uint64(len(s.counters[row])))
+}
+
+// Add increments frequency of key
+func (s *Sketch) Add(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for row := range s.counters {
+		i := s.index(row, key)
+		if s.counters[row][i] < 15 {
+			s.counters[row][i]++
+		}
+	}
+
+	s.additions++
+	if s.additions >= s.resetAt {
+		s.reset()
+	}
+}
+
+// reset halves all counters, must be called with lock held
+func (s *Sketch) reset() {
+	for row := range s.counters {
+		for i := range s.counters[row] {
+			s.counters[row][i] /= 2
+		}
+	}
+
+	s.additions /= 2
+}
+
+// Estimate returns estimated frequency of key
+func (s *Sketch) Estimate(key string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	min := uint8(15)
+	for row := range s.counters {
+		if c := s.counters[row][s.index(row, key)]; c < min {
+			min = c
+		}
+	}
+
+	return int(min)
+}
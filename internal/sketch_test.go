@@ -0,0 +1,28 @@
+package internal
+
+import "testing"
+
+func TestSketch(t *testing.T) {
+	s := NewSketch(1024)
+	for i := 0; i < 5; i++ {
+		s.Add("hot")
+	}
+	s.Add("cold")
+
+	if got := s.Estimate("hot"); got < 5 {
+		t.Errorf("Sketch.Estimate(hot) = %v, want at least %v", got, 5)
+	}
+	if got := s.Estimate("cold"); got < 1 || got > 5 {
+		t.Errorf("Sketch.Estimate(cold) = %v, want between 1 and 5", got)
+	}
+	if got := s.Estimate("unseen"); got > 1 {
+		t.Errorf("Sketch.Estimate(unseen) = %v, want at most %v", got, 1)
+	}
+
+	for i := 0; i < 20; i++ {
+		s.Add("hot")
+	}
+	if got := s.Estimate("hot"); got > 15 {
+		t.Errorf("Sketch.Estimate(hot) = %v, want saturated at %v", got, 15)
+	}
+}
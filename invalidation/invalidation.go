@@ -0,0 +1,220 @@
+// Package invalidation keeps local caches of multiple nodes coherent,
+// writes to local cache of one node are broadcast so other nodes evict stale values
+package invalidation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+
+	"github.com/albinzx/cache"
+)
+
+// Type is type of invalidation message
+type Type int
+
+const (
+	// Set is broadcast when keys are stored
+	Set Type = iota + 1
+	// Delete is broadcast when keys are deleted
+	Delete
+)
+
+// Message is invalidation broadcast between nodes
+type Message struct {
+	Type Type     `json:"type"`
+	Keys []string `json:"keys"`
+	// Source is id of node which published message
+	Source string `json:"source"`
+}
+
+// Publisher broadcasts invalidation messages
+type Publisher interface {
+	Publish(ctx context.Context, message Message) error
+}
+
+// Subscriber receives invalidation messages
+type Subscriber interface {
+	// Subscribe calls handler with every received message until returned function is called
+	Subscribe(ctx context.Context, handler func(Message)) (func(), error)
+}
+
+// Bus publishes and receives invalidation messages
+type Bus interface {
+	Publisher
+	Subscriber
+}
+
+// Local is in-process bus, e.g. for tests or multiple caches in one process
+type Local struct {
+	mu       sync.RWMutex
+	id       int
+	handlers map[int]func(Message)
+}
+
+// NewLocal returns in-process bus
+func NewLocal() *Local {
+	return &Local{handlers: map[int]func(Message){}}
+}
+
+// Publish calls all subscribed handlers with message
+func (l *Local) Publish(_ context.Context, message Message) error {
+	l.mu.RLock()
+	handlers := make([]func(Message), 0, len(l.handlers))
+	for _, handler := range l.handlers {
+		handlers = append(handlers, handler)
+	}
+	l.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(message)
+	}
+
+	return nil
+}
+
+// Subscribe registers handler of published messages
+func (l *Local) Subscribe(_ context.Context, handler func(Message)) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.id++
+	id := l.id
+	l.handlers[id] = handler
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		delete(l.handlers, id)
+	}, nil
+}
+
+// Cacher is local cacher which broadcasts its writes and evicts keys written by other nodes
+type Cacher struct {
+	cache.Cacher
+	bus         Bus
+	node        string
+	unsubscribe func()
+	onError     func(error)
+}
+
+// Option provides cacher options
+type Option func(*Cacher)
+
+// WithNode returns option to set id of node, default is random id
+func WithNode(node string) Option {
+	return func(c *Cacher) {
+		c.node = node
+	}
+}
+
+// WithErrorHandler returns option to handle failures to publish or evict,
+// local writes succeed even if they fail to be broadcast
+func WithErrorHandler(onError func(error)) Option {
+	return func(c *Cacher) {
+		c.onError = onError
+	}
+}
+
+// New returns cacher which keeps local cacher coherent with caches of other nodes subscribed to bus
+// messages of other nodes evict their keys from local cacher, values are fetched again from shared cache
+func New(ctx context.Context, local cache.Cacher, bus Bus, options ...Option) (*Cacher, error) {
+	c := &Cacher{Cacher: local, bus: bus, onError: func(error) {}}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.node == "" {
+		id := make([]byte, 8)
+		_, _ = rand.Read(id)
+		c.node = hex.EncodeToString(id)
+	}
+
+	unsubscribe, err := bus.Subscribe(ctx, c.receive)
+	if err != nil {
+		return nil, err
+	}
+	c.unsubscribe = unsubscribe
+
+	return c, nil
+}
+
+// Node returns id of node
+func (c *Cacher) Node() string {
+	return c.node
+}
+
+// Set sets key-value to local cache and broadcasts it
+func (c *Cacher) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	if err := c.Cacher.Set(ctx, key, value, options...); err != nil {
+		return err
+	}
+	c.publish(ctx, Set, key)
+
+	return nil
+}
+
+// Keep sets key-value to local cache without broadcasting it,
+// e.g. value read from shared cache which other nodes need not evict
+func (c *Cacher) Keep(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	return c.Cacher.Set(ctx, key, value, options...)
+}
+
+// Delete deletes value from local cache and broadcasts it
+func (c *Cacher) Delete(ctx context.Context, key string) error {
+	if err := c.Cacher.Delete(ctx, key); err != nil {
+		return err
+	}
+	c.publish(ctx, Delete, key)
+
+	return nil
+}
+
+// Load loads key-values to local cache and broadcasts them
+func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
+	if err := c.Cacher.Load(ctx, data); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	c.publish(ctx, Set, keys...)
+
+	return nil
+}
+
+// Close unsubscribes from bus and closes local cacher
+func (c *Cacher) Close() error {
+	c.unsubscribe()
+
+	return c.Cacher.Close()
+}
+
+// publish broadcasts keys written by this node
+func (c *Cacher) publish(ctx context.Context, typ Type, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	if err := c.bus.Publish(ctx, Message{Type: typ, Keys: keys, Source: c.node}); err != nil {
+		c.onError(err)
+	}
+}
+
+// receive evicts keys written by other nodes
+func (c *Cacher) receive(message Message) {
+	if message.Source == c.node {
+		return
+	}
+
+	for _, key := range message.Keys {
+		if err := c.Cacher.Delete(context.Background(), key); err != nil {
+			c.onError(err)
+		}
+	}
+}
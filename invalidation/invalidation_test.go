@@ -0,0 +1,90 @@
+package invalidation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/albinzx/cache/memory"
+)
+
+func TestCacher(t *testing.T) {
+	ctx := context.Background()
+	bus := NewLocal()
+
+	a, err := New(ctx, memory.New(), bus, WithNode("a"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer a.Close()
+	b, err := New(ctx, memory.New(), bus, WithNode("b"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer b.Close()
+
+	tests := []struct {
+		name  string
+		write func() error
+		wantA any
+		wantB any
+	}{
+		{name: "test set on a", write: func() error { return a.Set(ctx, "key", "a") }, wantA: "a"},
+		{name: "test set on b evicts a", write: func() error { return b.Set(ctx, "key", "b") }, wantB: "b"},
+		{name: "test load on a evicts b", write: func() error { return a.Load(ctx, map[string]any{"key": "c"}) }, wantA: "c"},
+		{name: "test delete on b", write: func() error { return b.Delete(ctx, "key") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.write(); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			if got, _ := a.Get(ctx, "key"); got != tt.wantA {
+				t.Errorf("a.Get() = %v, want %v", got, tt.wantA)
+			}
+			if got, _ := b.Get(ctx, "key"); got != tt.wantB {
+				t.Errorf("b.Get() = %v, want %v", got, tt.wantB)
+			}
+		})
+	}
+}
+
+func TestKeep(t *testing.T) {
+	ctx := context.Background()
+	bus := NewLocal()
+
+	a, _ := New(ctx, memory.New(), bus, WithNode("a"))
+	defer a.Close()
+	b, _ := New(ctx, memory.New(), bus, WithNode("b"))
+	defer b.Close()
+
+	_ = b.Keep(ctx, "key", "b")
+	_ = a.Keep(ctx, "key", "a")
+	if got, _ := b.Get(ctx, "key"); got != "b" {
+		t.Errorf("b.Get() = %v, want b kept without broadcast of a", got)
+	}
+}
+
+func TestKeyspaceMessage(t *testing.T) {
+	k := NewKeyspace(nil, 0, "orders.")
+
+	tests := []struct {
+		name    string
+		channel string
+		event   string
+		want    Message
+		wantOK  bool
+	}{
+		{name: "test set", channel: "__keyspace@0__:orders.42", event: "set", want: Message{Type: Set, Keys: []string{"42"}}, wantOK: true},
+		{name: "test expired", channel: "__keyspace@0__:orders.42", event: "expired", want: Message{Type: Delete, Keys: []string{"42"}}, wantOK: true},
+		{name: "test other prefix", channel: "__keyspace@0__:users.42", event: "del"},
+		{name: "test other db", channel: "__keyspace@1__:orders.42", event: "del"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := k.message(tt.channel, tt.event)
+			if ok != tt.wantOK || got.Type != tt.want.Type || len(got.Keys) != len(tt.want.Keys) || (ok && got.Keys[0] != tt.want.Keys[0]) {
+				t.Errorf("message() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package invalidation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Keyspace is bus receiving redis keyspace notifications of keys written to shared redis,
+// so writes of nodes which do not publish messages, e.g. other services, also evict local keys
+// redis must be configured to notify generic and string commands, e.g. notify-keyspace-events "Kg$x"
+// messages have no source, so node which writes key evicts its own local copy too
+type Keyspace struct {
+	client  goredis.UniversalClient
+	db      int
+	pattern string
+	prefix  string
+}
+
+// NewKeyspace returns bus receiving keyspace notifications of keys of database db starting with prefix,
+// e.g. name prefix of redis cacher, keys of received messages have prefix removed
+func NewKeyspace(client goredis.UniversalClient, db int, prefix string) *Keyspace {
+	return &Keyspace{
+		client:  client,
+		db:      db,
+		pattern: fmt.Sprintf("__keyspace@%d__:%s*", db, prefix),
+		prefix:  prefix,
+	}
+}
+
+// Publish does nothing, messages are published by redis when keys are written
+func (k *Keyspace) Publish(context.Context, Message) error {
+	return nil
+}
+
+// Subscribe subscribes to keyspace notifications and calls handler with message of every notified key
+// on redis cluster only notifications of the node serving the subscription are received
+func (k *Keyspace) Subscribe(ctx context.Context, handler func(Message)) (func(), error) {
+	pubsub := k.client.PSubscribe(ctx, k.pattern)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range pubsub.Channel() {
+			if message, ok := k.message(msg.Channel, msg.Payload); ok {
+				handler(message)
+			}
+		}
+	}()
+
+	return func() {
+		_ = pubsub.Close()
+		<-done
+	}, nil
+}
+
+// message returns message of keyspace notification on channel with event as payload
+func (k *Keyspace) message(channel, event string) (Message, bool) {
+	key, ok := strings.CutPrefix(channel, fmt.Sprintf("__keyspace@%d__:%s", k.db, k.prefix))
+	if !ok || key == "" {
+		return Message{}, false
+	}
+
+	switch event {
+	case "del", "unlink", "expired", "evicted":
+		return Message{Type: Delete, Keys: []string{key}}, true
+	default:
+		return Message{Type: Set, Keys: []string{key}}, true
+	}
+}
@@ -0,0 +1,56 @@
+package invalidation
+
+import (
+	"context"
+	"encoding/json"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Redis is bus using redis pub/sub
+type Redis struct {
+	client  goredis.UniversalClient
+	channel string
+}
+
+// NewRedis returns bus publishing messages to redis channel
+func NewRedis(client goredis.UniversalClient, channel string) *Redis {
+	return &Redis{client: client, channel: channel}
+}
+
+// Publish publishes json encoded message to channel
+func (r *Redis) Publish(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return r.client.Publish(ctx, r.channel, data).Err()
+}
+
+// Subscribe subscribes to channel and calls handler with every decoded message
+// messages which can not be decoded are ignored
+func (r *Redis) Subscribe(ctx context.Context, handler func(Message)) (func(), error) {
+	pubsub := r.client.Subscribe(ctx, r.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range pubsub.Channel() {
+			var message Message
+			if err := json.Unmarshal([]byte(msg.Payload), &message); err != nil {
+				continue
+			}
+			handler(message)
+		}
+	}()
+
+	return func() {
+		_ = pubsub.Close()
+		<-done
+	}, nil
+}
@@ -0,0 +1,274 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+var (
+	// ErrJournalClosed is returned when journal is used after it is closed
+	ErrJournalClosed = errors.New("journal is closed")
+)
+
+// JournalEntry is pending write to persistence storage
+type JournalEntry struct {
+	// Seq is sequence number of entry, increasing in order entries are appended
+	Seq uint64
+	// Op is OpSave or OpDelete
+	Op    Operation
+	Key   string
+	Value any
+}
+
+// WriteJournal durably records pending writes of write-behind pattern,
+// so writes which are not persisted when process stops are replayed on start
+type WriteJournal interface {
+	// Append records pending write and returns its sequence number
+	Append(op Operation, key string, value any) (uint64, error)
+	// Ack marks write of sequence number as done
+	Ack(seq uint64) error
+	// Pending returns writes which are not acked ordered by sequence number
+	Pending() ([]JournalEntry, error)
+}
+
+// WriteQueue is durable queue of writes of write-behind pattern consumed by workers which persist them,
+// e.g. redis.StreamQueue, so writes survive restarts and can be persisted by any process
+type WriteQueue interface {
+	// Push durably queues write of key, op is OpSave or OpDelete
+	Push(ctx context.Context, op Operation, key string, value any) error
+}
+
+// WithWriteQueue returns option to push writes to durable queue instead of persisting them in background,
+// write fails if it can not be pushed, journal, retries and dead letter sink of write-behind are not used
+// since writes are persisted by consumers of queue
+func WithWriteQueue(queue WriteQueue) WriteBehindOption {
+	return func(w *WriteBehind) {
+		w.writeQueue = queue
+	}
+}
+
+const (
+	// journalSave marks record of pending save
+	journalSave byte = 's'
+	// journalDelete marks record of pending delete
+	journalDelete byte = 'd'
+	// journalAck marks record of acked write
+	journalAck byte = 'a'
+)
+
+// FileJournal is write journal in append-only file
+//
+// every record is synced to disk before Append returns, the file is truncated when
+// all writes are acked so it does not grow while persistence storage keeps up,
+// values are encoded like backups so custom types must be registered with gob
+type FileJournal struct {
+	mu      sync.Mutex
+	file    *os.File
+	seq     uint64
+	pending map[uint64]JournalEntry
+}
+
+// OpenFileJournal opens journal file at path, creating it if it does not exist,
+// pending writes recorded by previous process are returned by Pending,
+// incomplete record at end of file, e.g. of crash during write, is discarded
+func OpenFileJournal(path string) (*FileJournal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &FileJournal{file: file, pending: map[uint64]JournalEntry{}}
+	valid, err := j.read()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// read loads pending writes of file and returns length of its complete records
+func (j *FileJournal) read() (int64, error) {
+	r := bufio.NewReader(j.file)
+	var valid int64
+	for {
+		size, err := binary.ReadUvarint(r)
+		if err != nil {
+			return valid, nil
+		}
+		record := make([]byte, size)
+		if _, err := io.ReadFull(r, record); err != nil {
+			return valid, nil
+		}
+
+		if err := j.apply(record); err != nil {
+			return 0, fmt.Errorf("journal record at %d: %w", valid, err)
+		}
+		valid += int64(binary.PutUvarint(make([]byte, binary.MaxVarintLen64), size)) + int64(size)
+	}
+}
+
+// apply applies record read from file to pending writes
+func (j *FileJournal) apply(record []byte) error {
+	if len(record) == 0 {
+		return ErrBackupFormat
+	}
+	typ := record[0]
+	seq, n := binary.Uvarint(record[1:])
+	if n <= 0 {
+		return ErrBackupFormat
+	}
+	if seq > j.seq {
+		j.seq = seq
+	}
+	record = record[1+n:]
+
+	if typ == journalAck {
+		delete(j.pending, seq)
+		return nil
+	}
+
+	size, n := binary.Uvarint(record)
+	if n <= 0 || uint64(len(record)-n) < size {
+		return ErrBackupFormat
+	}
+	key := string(record[n : n+int(size)])
+	record = record[n+int(size):]
+
+	entry := JournalEntry{Seq: seq, Key: key}
+	switch typ {
+	case journalSave:
+		if len(record) == 0 {
+			return ErrBackupFormat
+		}
+		value, err := decodeValue(record[0], record[1:])
+		if err != nil {
+			return err
+		}
+		entry.Op, entry.Value = OpSave, value
+	case journalDelete:
+		entry.Op = OpDelete
+	default:
+		return fmt.Errorf("%w: unknown record type %q", ErrBackupFormat, typ)
+	}
+	j.pending[seq] = entry
+
+	return nil
+}
+
+// write appends record to file and syncs it
+func (j *FileJournal) write(record []byte) error {
+	if j.file == nil {
+		return ErrJournalClosed
+	}
+
+	data := binary.AppendUvarint(nil, uint64(len(record)))
+	if _, err := j.file.Write(append(data, record...)); err != nil {
+		return err
+	}
+
+	return j.file.Sync()
+}
+
+// Append records pending save or delete of key
+func (j *FileJournal) Append(op Operation, key string, value any) (uint64, error) {
+	typ := journalDelete
+	var kind byte
+	var data []byte
+	if op == OpSave {
+		var err error
+		if kind, data, err = encodeValue(value); err != nil {
+			return 0, err
+		}
+		typ = journalSave
+	} else if op != OpDelete {
+		return 0, fmt.Errorf("journal can not record %s", op)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	seq := j.seq + 1
+	record := binary.AppendUvarint([]byte{typ}, seq)
+	record = binary.AppendUvarint(record, uint64(len(key)))
+	record = append(record, key...)
+	if typ == journalSave {
+		record = append(append(record, kind), data...)
+	}
+
+	if err := j.write(record); err != nil {
+		return 0, err
+	}
+	j.seq = seq
+	j.pending[seq] = JournalEntry{Seq: seq, Op: op, Key: key, Value: value}
+
+	return seq, nil
+}
+
+// Ack marks write done, file is truncated when no write is pending
+func (j *FileJournal) Ack(seq uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, ok := j.pending[seq]; !ok {
+		return nil
+	}
+	delete(j.pending, seq)
+
+	if len(j.pending) == 0 {
+		if j.file == nil {
+			return ErrJournalClosed
+		}
+		if err := j.file.Truncate(0); err != nil {
+			return err
+		}
+		_, err := j.file.Seek(0, io.SeekStart)
+		return err
+	}
+
+	return j.write(binary.AppendUvarint([]byte{journalAck}, seq))
+}
+
+// Pending returns writes which are not acked ordered by sequence number
+func (j *FileJournal) Pending() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]JournalEntry, 0, len(j.pending))
+	for _, entry := range j.pending {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Seq < entries[b].Seq })
+
+	return entries, nil
+}
+
+// Close closes journal file, pending writes stay in file
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+
+	return err
+}
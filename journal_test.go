@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "writes.journal")
+
+	j, err := OpenFileJournal(path)
+	if err != nil {
+		t.Fatalf("OpenFileJournal() error = %v", err)
+	}
+	saved, _ := j.Append(OpSave, "a", "one")
+	_, _ = j.Append(OpSave, "b", []byte("two"))
+	_, _ = j.Append(OpDelete, "c", nil)
+	_, _ = j.Append(OpSave, "d", map[string]any{"n": "four"})
+	if err := j.Ack(saved); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	_ = j.Close()
+
+	// torn record of crash during append is discarded
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	_, _ = file.Write([]byte{20, journalSave, 9})
+	_ = file.Close()
+
+	j, err = OpenFileJournal(path)
+	if err != nil {
+		t.Fatalf("OpenFileJournal() error = %v", err)
+	}
+	defer j.Close()
+
+	pending, _ := j.Pending()
+	want := []JournalEntry{
+		{Seq: 2, Op: OpSave, Key: "b", Value: []byte("two")},
+		{Seq: 3, Op: OpDelete, Key: "c"},
+		{Seq: 4, Op: OpSave, Key: "d", Value: map[string]any{"n": "four"}},
+	}
+	if !reflect.DeepEqual(pending, want) {
+		t.Fatalf("Pending() = %v, want %v", pending, want)
+	}
+
+	// pending writes are replayed when patterned cache is created
+	persister := newMapPersister()
+	persister.data["c"] = "stale"
+	c, _ := New(newMapCacher(), persister, WithPattern(NewWriteBehind(WithWriteJournal(j))))
+	if err := c.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	wantPersisted := map[string]any{"b": []byte("two"), "d": map[string]any{"n": "four"}}
+	if !reflect.DeepEqual(persister.data, wantPersisted) {
+		t.Errorf("replayed = %v, want %v", persister.data, wantPersisted)
+	}
+	if pending, _ := j.Pending(); len(pending) != 0 {
+		t.Errorf("Pending() = %v, want none", pending)
+	}
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Errorf("journal size = %v, want %v", info.Size(), 0)
+	}
+
+	// writes of write-behind are recorded until they are persisted
+	if err := c.Set(context.Background(), "e", "five"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	_ = c.Drain(context.Background())
+	if got := persister.data["e"]; got != "five" {
+		t.Errorf("Set() persisted = %v, want %v", got, "five")
+	}
+	if pending, _ := j.Pending(); len(pending) != 0 {
+		t.Errorf("Pending() = %v, want none", pending)
+	}
+}
+
+// sliceQueue is write queue keeping pushed writes in memory
+type sliceQueue struct {
+	writes []JournalEntry
+	err    error
+}
+
+func (q *sliceQueue) Push(_ context.Context, op Operation, key string, value any) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.writes = append(q.writes, JournalEntry{Op: op, Key: key, Value: value})
+	return nil
+}
+
+func TestWriteBehind_WriteQueue(t *testing.T) {
+	ctx := context.Background()
+	queue := &sliceQueue{}
+	persister := newMapPersister()
+	c, _ := New(newMapCacher(), persister, WithPattern(NewWriteBehind(WithWriteQueue(queue))))
+
+	_ = c.Set(ctx, "a", "one")
+	_ = c.Delete(ctx, "b")
+	_ = c.Drain(ctx)
+
+	want := []JournalEntry{{Op: OpSave, Key: "a", Value: "one"}, {Op: OpDelete, Key: "b"}}
+	if !reflect.DeepEqual(queue.writes, want) {
+		t.Errorf("pushed = %v, want %v", queue.writes, want)
+	}
+	if len(persister.data) != 0 {
+		t.Errorf("persisted = %v, want writes left to consumers of queue", persister.data)
+	}
+
+	queue.err = errors.New("queue is down")
+	if err := c.Set(ctx, "c", "three"); !errors.Is(err, queue.err) {
+		t.Errorf("Set() error = %v, want %v", err, queue.err)
+	}
+}
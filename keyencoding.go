@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+)
+
+// KeyEncoding encodes keys so arbitrary binary or unicode keys are safe for backends
+// with character restrictions, e.g. memcached or file systems
+type KeyEncoding interface {
+	// EncodeKey returns encoded key
+	EncodeKey(key string) string
+	// DecodeKey returns key of encoded key
+	DecodeKey(encoded string) (string, error)
+}
+
+// Base64Keys encodes keys with unpadded url-safe base64, encoded keys contain only letters, digits, '-' and '_'
+type Base64Keys struct{}
+
+// EncodeKey returns base64 of key
+func (Base64Keys) EncodeKey(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// DecodeKey returns key of base64
+func (Base64Keys) DecodeKey(encoded string) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	return string(key), nil
+}
+
+// EscapedKeys encodes keys with url query escaping, letters, digits, '-', '_', '.' and '~' are kept as is
+type EscapedKeys struct{}
+
+// EncodeKey returns escaped key
+func (EscapedKeys) EncodeKey(key string) string {
+	return url.QueryEscape(key)
+}
+
+// DecodeKey returns key of escaped key
+func (EscapedKeys) DecodeKey(encoded string) (string, error) {
+	return url.QueryUnescape(encoded)
+}
+
+// keyEncodingCacher is cacher encoding keys before they reach cacher
+type keyEncodingCacher struct {
+	Cacher
+	encoding KeyEncoding
+}
+
+// Set sets value of encoded key
+func (k *keyEncodingCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	return k.Cacher.Set(ctx, k.encoding.EncodeKey(key), value, options...)
+}
+
+// Get gets value of encoded key
+func (k *keyEncodingCacher) Get(ctx context.Context, key string) (any, error) {
+	return k.Cacher.Get(ctx, k.encoding.EncodeKey(key))
+}
+
+// Delete deletes value of encoded key
+func (k *keyEncodingCacher) Delete(ctx context.Context, key string) error {
+	return k.Cacher.Delete(ctx, k.encoding.EncodeKey(key))
+}
+
+// Load loads values of encoded keys
+func (k *keyEncodingCacher) Load(ctx context.Context, data map[string]any) error {
+	encoded := make(map[string]any, len(data))
+	for key, value := range data {
+		encoded[k.encoding.EncodeKey(key)] = value
+	}
+
+	return k.Cacher.Load(ctx, encoded)
+}
+
+// EncodedKeys returns cacher encoding keys with encoding before they reach c
+func EncodedKeys(c Cacher, encoding KeyEncoding) Cacher {
+	return &keyEncodingCacher{Cacher: c, encoding: encoding}
+}
+
+// WithKeyEncoding returns option to encode keys with encoding before they reach cacher,
+// other options such as ttl policy and codec registry see keys before encoding
+func WithKeyEncoding(encoding KeyEncoding) Option {
+	return func(c *PatternedCache) {
+		c.keyCodec = encoding
+	}
+}
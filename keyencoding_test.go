@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithKeyEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding KeyEncoding
+		key      string
+		want     string
+	}{
+		{name: "test base64 binary key", encoding: Base64Keys{}, key: "\x00\xffkey", want: "AP9rZXk"},
+		{name: "test base64 unicode key", encoding: Base64Keys{}, key: "東京", want: "5p2x5Lqs"},
+		{name: "test escaped readable key", encoding: EscapedKeys{}, key: "user:1", want: "user%3A1"},
+		{name: "test escaped spaces", encoding: EscapedKeys{}, key: "a b\n", want: "a+b%0A"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMapCacher()
+			c, _ := New(m, nil, WithKeyEncoding(tt.encoding))
+			ctx := context.Background()
+
+			if err := c.Set(ctx, tt.key, "value"); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if got := m.data[tt.want]; got != "value" {
+				t.Errorf("Set() stored keys = %v, want %q", m.data, tt.want)
+			}
+			if got, _ := c.Get(ctx, tt.key); got != "value" {
+				t.Errorf("Get() = %v, want %v", got, "value")
+			}
+			if got, err := tt.encoding.DecodeKey(tt.want); err != nil || got != tt.key {
+				t.Errorf("DecodeKey() = %q, %v, want %q", got, err, tt.key)
+			}
+		})
+	}
+}
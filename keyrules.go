@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidKey is wrapped by errors of keys rejected by key rules
+	ErrInvalidKey = errors.New("invalid key")
+)
+
+// InvalidKeyError is error of key rejected by key rules
+type InvalidKeyError struct {
+	// Key is rejected key, truncated if it is long
+	Key string
+	// Reason is why key is rejected
+	Reason string
+}
+
+// Error returns key and reason
+func (e *InvalidKeyError) Error() string {
+	return fmt.Sprintf("invalid key %q: %s", e.Key, e.Reason)
+}
+
+// Unwrap returns ErrInvalidKey
+func (e *InvalidKeyError) Unwrap() error {
+	return ErrInvalidKey
+}
+
+// maxReportedKey is maximum length of key reported in InvalidKeyError
+const maxReportedKey = 64
+
+// KeyRules are rules keys must follow, applied before keys reach cacher and its prefixing
+type KeyRules struct {
+	// MaxLength is maximum length of key in bytes after normalizing, 0 means no limit
+	MaxLength int
+	// Allowed reports whether rune is allowed in key, nil allows printable runes except whitespace
+	Allowed func(rune) bool
+	// Normalize transforms key before it is validated, e.g. strings.TrimSpace or NormalizeKey
+	Normalize func(string) string
+}
+
+// Apply returns normalized key or *InvalidKeyError if key breaks rules
+// empty keys and keys which are not valid utf-8 are always rejected
+func (r *KeyRules) Apply(key string) (string, error) {
+	if r.Normalize != nil {
+		key = r.Normalize(key)
+	}
+
+	if key == "" {
+		return "", invalidKey(key, "empty key")
+	}
+
+	if r.MaxLength > 0 && len(key) > r.MaxLength {
+		return "", invalidKey(key, fmt.Sprintf("length %d exceeds %d", len(key), r.MaxLength))
+	}
+
+	if !utf8.ValidString(key) {
+		return "", invalidKey(key, "not valid utf-8")
+	}
+
+	allowed := r.Allowed
+	if allowed == nil {
+		allowed = printable
+	}
+	for i, char := range key {
+		if !allowed(char) {
+			return "", invalidKey(key, fmt.Sprintf("character %q at %d is not allowed", char, i))
+		}
+	}
+
+	return key, nil
+}
+
+// invalidKey returns error of key truncated to maxReportedKey
+func invalidKey(key, reason string) error {
+	if len(key) > maxReportedKey {
+		key = key[:maxReportedKey] + "..."
+	}
+
+	return &InvalidKeyError{Key: key, Reason: reason}
+}
+
+// printable reports whether char is printable and not whitespace
+func printable(char rune) bool {
+	return unicode.IsPrint(char) && !unicode.IsSpace(char)
+}
+
+// AllowedKeyChars returns function allowing ascii letters, digits and extra characters
+func AllowedKeyChars(extra string) func(rune) bool {
+	return func(char rune) bool {
+		return char < utf8.RuneSelf && (char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9') ||
+			strings.ContainsRune(extra, char)
+	}
+}
+
+// NormalizeKey trims surrounding whitespace of key and replaces inner whitespace runs with underscore
+func NormalizeKey(key string) string {
+	return strings.Join(strings.Fields(key), "_")
+}
+
+// keyRulesCacher is cacher applying key rules before keys reach cacher
+type keyRulesCacher struct {
+	Cacher
+	rules *KeyRules
+}
+
+// Set sets key-value to cache if key follows rules
+func (k *keyRulesCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	key, err := k.rules.Apply(key)
+	if err != nil {
+		return err
+	}
+
+	return k.Cacher.Set(ctx, key, value, options...)
+}
+
+// Get gets value from cache if key follows rules
+func (k *keyRulesCacher) Get(ctx context.Context, key string) (any, error) {
+	key, err := k.rules.Apply(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.Cacher.Get(ctx, key)
+}
+
+// Delete deletes value from cache if key follows rules
+func (k *keyRulesCacher) Delete(ctx context.Context, key string) error {
+	key, err := k.rules.Apply(key)
+	if err != nil {
+		return err
+	}
+
+	return k.Cacher.Delete(ctx, key)
+}
+
+// Load loads key-values into cache, nothing is loaded if any key breaks rules
+func (k *keyRulesCacher) Load(ctx context.Context, data map[string]any) error {
+	normalized := make(map[string]any, len(data))
+	for key, value := range data {
+		key, err := k.rules.Apply(key)
+		if err != nil {
+			return err
+		}
+		normalized[key] = value
+	}
+
+	return k.Cacher.Load(ctx, normalized)
+}
+
+// ValidKeys returns cacher normalizing keys and rejecting keys breaking rules with *InvalidKeyError
+func ValidKeys(c Cacher, rules KeyRules) Cacher {
+	return &keyRulesCacher{Cacher: c, rules: &rules}
+}
+
+// WithKeyRules returns option to normalize keys and reject keys breaking rules with *InvalidKeyError
+func WithKeyRules(rules KeyRules) Option {
+	return func(c *PatternedCache) {
+		c.keyRules = &rules
+	}
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestKeyRules_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   KeyRules
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{name: "test valid key", key: "user:1", want: "user:1"},
+		{name: "test unicode key", key: "kota:jakarta-東京", want: "kota:jakarta-東京"},
+		{name: "test empty key", key: "", wantErr: true},
+		{name: "test space", key: "user 1", wantErr: true},
+		{name: "test newline", key: "user:1\n", wantErr: true},
+		{name: "test invalid utf-8", key: "user:\xff", wantErr: true},
+		{name: "test too long", rules: KeyRules{MaxLength: 4}, key: "user:1", wantErr: true},
+		{name: "test normalized", rules: KeyRules{Normalize: NormalizeKey}, key: " user \t 1\n", want: "user_1"},
+		{name: "test normalized to empty", rules: KeyRules{Normalize: strings.TrimSpace}, key: "  ", wantErr: true},
+		{name: "test allowed chars", rules: KeyRules{Allowed: AllowedKeyChars(":_-")}, key: "user:1_a-b", want: "user:1_a-b"},
+		{name: "test not allowed char", rules: KeyRules{Allowed: AllowedKeyChars(":")}, key: "user/1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.rules.Apply(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Apply() error = %v, want %v", err, ErrInvalidKey)
+			}
+			if got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithKeyRules(t *testing.T) {
+	m := newMapCacher()
+	c, _ := New(m, nil, WithKeyRules(KeyRules{MaxLength: 16, Normalize: NormalizeKey}))
+	ctx := context.Background()
+
+	if err := c.Set(ctx, " user 1 ", "value"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got := m.data["user_1"]; got != "value" {
+		t.Errorf("Set() stored = %v, want %v", got, "value")
+	}
+	if got, _ := c.Get(ctx, "user 1"); got != "value" {
+		t.Errorf("Get() = %v, want %v", got, "value")
+	}
+
+	var invalid *InvalidKeyError
+	if err := c.Set(ctx, strings.Repeat("k", 1<<20), "value"); !errors.As(err, &invalid) {
+		t.Fatalf("Set() error = %v, want %T", err, invalid)
+	}
+	if got := len(invalid.Key); got > maxReportedKey+3 {
+		t.Errorf("InvalidKeyError.Key length = %v, want at most %v", got, maxReportedKey+3)
+	}
+	if len(m.data) != 1 {
+		t.Errorf("Set() stored %v keys, want %v", len(m.data), 1)
+	}
+}
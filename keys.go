@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// KeyIterator iterates keys of cache
+type KeyIterator interface {
+	// Next advances to next key, false is returned when there are no more keys or on error
+	Next(context.Context) bool
+	// Key returns current key
+	Key() string
+	// Err returns error stopping iteration
+	Err() error
+}
+
+// Scanner is implemented by cachers which can iterate their keys
+type Scanner interface {
+	// Keys returns iterator of keys matching glob pattern, empty pattern matches all keys
+	Keys(ctx context.Context, pattern string) KeyIterator
+}
+
+// TTLReader is implemented by cachers which can report remaining time to live of values
+type TTLReader interface {
+	// GetWithTTL retrieves value and its remaining time to live from cache
+	// zero ttl means value never expires, nil value means value is not found
+	GetWithTTL(context.Context, string) (any, time.Duration, error)
+}
+
+// Exister is implemented by cachers which check existence of keys without reading their values
+type Exister interface {
+	// Exists returns number of keys which exist, key given more times is counted every time
+	Exists(ctx context.Context, keys ...string) (int64, error)
+}
+
+// Exists returns number of keys which exist in c, key given more times is counted every time,
+// values of keys are read from cachers which do not implement Exister
+func Exists(ctx context.Context, c Cacher, keys ...string) (int64, error) {
+	if exister, ok := c.(Exister); ok {
+		return exister.Exists(ctx, keys...)
+	}
+
+	values, err := GetMany(ctx, c, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for _, key := range keys {
+		if _, ok := values[key]; ok {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// SliceIterator is key iterator over slice of keys
+type SliceIterator struct {
+	keys  []string
+	index int
+}
+
+// NewSliceIterator returns iterator over keys
+func NewSliceIterator(keys []string) *SliceIterator {
+	return &SliceIterator{keys: keys, index: -1}
+}
+
+// Next advances to next key
+func (s *SliceIterator) Next(context.Context) bool {
+	s.index++
+	return s.index < len(s.keys)
+}
+
+// Key returns current key
+func (s *SliceIterator) Key() string {
+	return s.keys[s.index]
+}
+
+// Err always returns nil
+func (s *SliceIterator) Err() error {
+	return nil
+}
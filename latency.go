@@ -0,0 +1,264 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// GuardState is state of latency guard
+type GuardState int
+
+const (
+	// GuardActive means cache is read normally
+	GuardActive GuardState = iota
+	// GuardBypassed means cache is too slow and reads bypass it
+	GuardBypassed
+	// GuardProbing means cooldown is over and a read probes whether cache recovered
+	GuardProbing
+)
+
+// String returns name of guard state
+func (s GuardState) String() string {
+	switch s {
+	case GuardActive:
+		return "active"
+	case GuardBypassed:
+		return "bypassed"
+	case GuardProbing:
+		return "probing"
+	default:
+		return "unknown"
+	}
+}
+
+// guardChange is change of guard state
+type guardChange struct {
+	from GuardState
+	to   GuardState
+}
+
+// LatencyGuard is cacher bypassing reads of cache while it is slower than threshold
+// reads of bypassed cache are misses, so patterns serve them from persistence storage,
+// writes still reach cache so it stays consistent with persistence storage
+type LatencyGuard struct {
+	Cacher
+	threshold  time.Duration
+	percentile float64
+	minSamples int
+	cooldown   time.Duration
+	onChange   func(GuardState)
+	now        func() time.Time
+
+	mu        sync.Mutex
+	state     GuardState
+	changes   []guardChange
+	samples   []time.Duration
+	next      int
+	bypassEnd time.Time
+}
+
+// LatencyGuardOption provides latency guard options
+type LatencyGuardOption func(*LatencyGuard)
+
+// WithLatencyPercentile returns option to set percentile of latency compared with threshold,
+// between 0 and 1, default is 0.95
+func WithLatencyPercentile(percentile float64) LatencyGuardOption {
+	return func(g *LatencyGuard) {
+		g.percentile = percentile
+	}
+}
+
+// WithLatencyWindow returns option to set number of latest operations latency percentile is computed of,
+// and minimum number of them before cache is bypassed, default is 100 and 20
+func WithLatencyWindow(size, minSamples int) LatencyGuardOption {
+	return func(g *LatencyGuard) {
+		g.samples = make([]time.Duration, 0, size)
+		g.minSamples = minSamples
+	}
+}
+
+// WithBypassCooldown returns option to set how long reads bypass cache before it is probed, default is 10 seconds
+func WithBypassCooldown(cooldown time.Duration) LatencyGuardOption {
+	return func(g *LatencyGuard) {
+		g.cooldown = cooldown
+	}
+}
+
+// WithGuardStateChange returns option to call onChange when guard state changes,
+// it is called synchronously so it must not block
+func WithGuardStateChange(onChange func(GuardState)) LatencyGuardOption {
+	return func(g *LatencyGuard) {
+		g.onChange = onChange
+	}
+}
+
+// GuardLatency returns cacher bypassing reads of c while percentile of its latency is above threshold
+// after cooldown a single read probes c, cache is read again if the probe is faster than threshold
+// state changes are emitted as EventBypass and EventRecover to subscribers of patterned cache
+func GuardLatency(c Cacher, threshold time.Duration, options ...LatencyGuardOption) *LatencyGuard {
+	g := &LatencyGuard{
+		Cacher:     c,
+		threshold:  threshold,
+		percentile: 0.95,
+		minSamples: 20,
+		cooldown:   10 * time.Second,
+		now:        time.Now,
+	}
+
+	for _, option := range options {
+		option(g)
+	}
+
+	if g.samples == nil {
+		g.samples = make([]time.Duration, 0, 100)
+	}
+
+	return g
+}
+
+// State returns current state of guard
+func (g *LatencyGuard) State() GuardState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.state
+}
+
+// bypass reports whether read is bypassed and whether it probes cache,
+// the first read after cooldown is let through as probe
+func (g *LatencyGuard) bypass(ctx context.Context) (bool, bool) {
+	g.mu.Lock()
+	defer g.notify(ctx)
+	defer g.mu.Unlock()
+
+	switch g.state {
+	case GuardBypassed:
+		if g.now().Before(g.bypassEnd) {
+			return true, false
+		}
+		g.change(GuardProbing)
+		return false, true
+	case GuardProbing:
+		return true, false
+	default:
+		return false, false
+	}
+}
+
+// observe records latency of operation and changes state if cache became slow or recovered
+func (g *LatencyGuard) observe(ctx context.Context, latency time.Duration, probe bool) {
+	g.mu.Lock()
+	defer g.notify(ctx)
+	defer g.mu.Unlock()
+
+	if probe {
+		if latency > g.threshold {
+			g.trip()
+			return
+		}
+		g.samples, g.next = g.samples[:0], 0
+		g.change(GuardActive)
+		return
+	}
+
+	if g.state != GuardActive {
+		return
+	}
+
+	if len(g.samples) < cap(g.samples) {
+		g.samples = append(g.samples, latency)
+	} else {
+		g.samples[g.next] = latency
+		g.next = (g.next + 1) % len(g.samples)
+	}
+
+	if len(g.samples) >= g.minSamples && g.latency() > g.threshold {
+		g.trip()
+	}
+}
+
+// latency returns percentile of sampled latencies, must be called with lock held
+func (g *LatencyGuard) latency() time.Duration {
+	sorted := append([]time.Duration(nil), g.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	i := int(g.percentile * float64(len(sorted)))
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
+
+// trip starts bypassing cache, must be called with lock held
+func (g *LatencyGuard) trip() {
+	g.bypassEnd = g.now().Add(g.cooldown)
+	g.change(GuardBypassed)
+}
+
+// change sets state and queues notification about it, must be called with lock held
+func (g *LatencyGuard) change(state GuardState) {
+	if g.state == state {
+		return
+	}
+
+	g.changes = append(g.changes, guardChange{from: g.state, to: state})
+	g.state = state
+}
+
+// notify notifies about queued state changes, it is called without lock held
+// so listeners can use guard
+func (g *LatencyGuard) notify(ctx context.Context) {
+	g.mu.Lock()
+	changes := g.changes
+	g.changes = nil
+	g.mu.Unlock()
+
+	for _, change := range changes {
+		if g.onChange != nil {
+			g.onChange(change.to)
+		}
+
+		// failed probe keeps cache bypassed, it is not a new bypass
+		switch {
+		case change.to == GuardBypassed && change.from == GuardActive:
+			emit(ctx, Event{Type: EventBypass, Time: g.now()})
+		case change.to == GuardActive:
+			emit(ctx, Event{Type: EventRecover, Time: g.now()})
+		}
+	}
+}
+
+// Set sets key-value to cache and records its latency
+func (g *LatencyGuard) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	start := g.now()
+	err := g.Cacher.Set(ctx, key, value, options...)
+	g.observe(ctx, g.now().Sub(start), false)
+
+	return err
+}
+
+// Get gets value from cache unless it is bypassed and records its latency
+func (g *LatencyGuard) Get(ctx context.Context, key string) (any, error) {
+	bypass, probe := g.bypass(ctx)
+	if bypass {
+		return nil, nil
+	}
+
+	start := g.now()
+	value, err := g.Cacher.Get(ctx, key)
+	g.observe(ctx, g.now().Sub(start), probe)
+
+	return value, err
+}
+
+// Delete deletes value from cache and records its latency
+func (g *LatencyGuard) Delete(ctx context.Context, key string) error {
+	start := g.now()
+	err := g.Cacher.Delete(ctx, key)
+	g.observe(ctx, g.now().Sub(start), false)
+
+	return err
+}
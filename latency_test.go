@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// slowCacher advances its clock by delay on every operation
+type slowCacher struct {
+	*mapCacher
+	now   time.Time
+	delay time.Duration
+}
+
+func (s *slowCacher) Now() time.Time {
+	return s.now
+}
+
+func (s *slowCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	s.now = s.now.Add(s.delay)
+	return s.mapCacher.Set(ctx, key, value, options...)
+}
+
+func (s *slowCacher) Get(ctx context.Context, key string) (any, error) {
+	s.now = s.now.Add(s.delay)
+	return s.mapCacher.Get(ctx, key)
+}
+
+func TestGuardLatency(t *testing.T) {
+	ctx := context.Background()
+	slow := &slowCacher{mapCacher: newMapCacher(), now: time.Now(), delay: time.Millisecond}
+
+	var states []GuardState
+	guard := GuardLatency(slow, 10*time.Millisecond, WithLatencyWindow(10, 5), WithBypassCooldown(time.Second),
+		WithGuardStateChange(func(state GuardState) { states = append(states, state) }))
+	guard.now = slow.Now
+
+	persister := newMapPersister()
+	persister.data["key"] = "persisted"
+	c, _ := New(guard, persister, WithPattern(&ReadThrough{}))
+
+	var events []EventType
+	c.Subscribe(func(e Event) { events = append(events, e.Type) })
+
+	_ = slow.mapCacher.Set(ctx, "key", "cached")
+	for i := 0; i < 5; i++ {
+		if got, _ := c.Get(ctx, "key"); got != "cached" {
+			t.Fatalf("Get() = %v, want %v", got, "cached")
+		}
+	}
+
+	// degraded cache is bypassed after enough slow reads
+	slow.delay = 50 * time.Millisecond
+	for i := 0; i < 5; i++ {
+		_, _ = c.Get(ctx, "key")
+	}
+	if got := guard.State(); got != GuardBypassed {
+		t.Fatalf("State() = %v, want %v", got, GuardBypassed)
+	}
+	if got, _ := c.Get(ctx, "key"); got != "persisted" {
+		t.Errorf("Get() of bypassed cache = %v, want %v", got, "persisted")
+	}
+
+	// failed probe keeps cache bypassed
+	slow.now = slow.now.Add(time.Second)
+	_, _ = c.Get(ctx, "key")
+	if got := guard.State(); got != GuardBypassed {
+		t.Fatalf("State() after failed probe = %v, want %v", got, GuardBypassed)
+	}
+
+	// successful probe recovers cache
+	slow.delay = time.Millisecond
+	slow.now = slow.now.Add(time.Second)
+	_ = slow.mapCacher.Set(ctx, "key", "cached")
+	if got, _ := c.Get(ctx, "key"); got != "cached" {
+		t.Errorf("Get() of probe = %v, want %v", got, "cached")
+	}
+	if got := guard.State(); got != GuardActive {
+		t.Fatalf("State() after probe = %v, want %v", got, GuardActive)
+	}
+
+	wantStates := []GuardState{GuardBypassed, GuardProbing, GuardBypassed, GuardProbing, GuardActive}
+	if len(states) != len(wantStates) {
+		t.Fatalf("state changes = %v, want %v", states, wantStates)
+	}
+	for i := range states {
+		if states[i] != wantStates[i] {
+			t.Errorf("state changes = %v, want %v", states, wantStates)
+			break
+		}
+	}
+
+	var bypasses, recoveries int
+	for _, e := range events {
+		switch e {
+		case EventBypass:
+			bypasses++
+		case EventRecover:
+			recoveries++
+		}
+	}
+	if bypasses != 1 || recoveries != 1 {
+		t.Errorf("events = %v bypass and %v recover, want 1 and 1", bypasses, recoveries)
+	}
+}
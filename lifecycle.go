@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	// ErrShutdown is returned when component is added to manager which is already shut down
+	ErrShutdown = errors.New("manager is shut down")
+)
+
+// Drainer is implemented by components which perform asynchronous work, e.g. WriteBehind pattern
+type Drainer interface {
+	// Drain waits for pending asynchronous work to finish
+	Drain(context.Context) error
+}
+
+// Stopper is implemented by components running in background, e.g. refresh scheduler
+type Stopper interface {
+	// Stop stops background work and waits for it to finish
+	Stop()
+}
+
+// Drain waits for pending asynchronous work of pattern to finish, e.g. of WriteBehind
+func (c *PatternedCache) Drain(ctx context.Context) error {
+	if drainer, ok := c.pattern.(Drainer); ok {
+		return drainer.Drain(ctx)
+	}
+
+	return nil
+}
+
+// Manager owns cache components and shuts them down in order
+// subscriptions are cancelled first, then stoppers are stopped, drainers are drained,
+// and finally cachers and persisters are closed
+type Manager struct {
+	mu            sync.Mutex
+	subscriptions []func()
+	stoppers      []Stopper
+	drainers      []Drainer
+	cachers       []io.Closer
+	persisters    []io.Closer
+	closed        bool
+	err           error
+}
+
+// NewManager returns new lifecycle manager
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Add registers patterned cache with its pattern, cacher and persister,
+// pattern is stopped if it runs in background, e.g. RefreshAhead
+func (m *Manager) Add(c *PatternedCache) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrShutdown
+	}
+
+	if stopper, ok := c.pattern.(Stopper); ok {
+		m.stoppers = append(m.stoppers, stopper)
+	}
+	m.drainers = append(m.drainers, c)
+	m.cachers = append(m.cachers, c.cacher)
+	if c.persister != nil {
+		m.persisters = append(m.persisters, c.persister)
+	}
+
+	return nil
+}
+
+// AddCacher registers cacher closed on shutdown
+func (m *Manager) AddCacher(c Cacher) error {
+	return m.add(func() { m.cachers = append(m.cachers, c) })
+}
+
+// AddPersister registers persister closed on shutdown
+func (m *Manager) AddPersister(p Persister) error {
+	return m.add(func() { m.persisters = append(m.persisters, p) })
+}
+
+// AddDrainer registers drainer drained on shutdown
+func (m *Manager) AddDrainer(d Drainer) error {
+	return m.add(func() { m.drainers = append(m.drainers, d) })
+}
+
+// AddStopper registers stopper stopped on shutdown
+func (m *Manager) AddStopper(s Stopper) error {
+	return m.add(func() { m.stoppers = append(m.stoppers, s) })
+}
+
+// AddSubscription registers unsubscribe function, e.g. returned by PatternedCache.Subscribe, called on shutdown
+func (m *Manager) AddSubscription(unsubscribe func()) error {
+	return m.add(func() { m.subscriptions = append(m.subscriptions, unsubscribe) })
+}
+
+// add runs register if manager is not shut down
+func (m *Manager) add(register func()) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return ErrShutdown
+	}
+	register()
+
+	return nil
+}
+
+// Shutdown cancels subscriptions, stops background work, drains asynchronous work
+// and closes cachers and persisters, components are shut down in reverse order of registration
+// if context is done while stopping or draining, remaining components are still closed
+// returned error joins errors of all components, subsequent calls return the same error
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return m.err
+	}
+	m.closed = true
+
+	var errs []error
+
+	for i := len(m.subscriptions) - 1; i >= 0; i-- {
+		m.subscriptions[i]()
+	}
+
+	for i := len(m.stoppers) - 1; i >= 0; i-- {
+		if err := wait(ctx, m.stoppers[i].Stop); err != nil {
+			errs = append(errs, fmt.Errorf("stop: %w", err))
+		}
+	}
+
+	for i := len(m.drainers) - 1; i >= 0; i-- {
+		if err := m.drainers[i].Drain(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("drain: %w", err))
+		}
+	}
+
+	for i := len(m.cachers) - 1; i >= 0; i-- {
+		if err := m.cachers[i].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("cacher: %w", err))
+		}
+	}
+
+	for i := len(m.persisters) - 1; i >= 0; i-- {
+		if err := m.persisters[i].Close(); err != nil {
+			errs = append(errs, fmt.Errorf("persister: %w", err))
+		}
+	}
+
+	m.err = errors.Join(errs...)
+
+	return m.err
+}
+
+// wait runs blocking function and waits for it to return or context to be done
+func wait(ctx context.Context, fn func()) error {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// orderRecorder records shutdown order of components
+type orderRecorder struct {
+	mu    sync.Mutex
+	order []string
+}
+
+func (r *orderRecorder) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.order = append(r.order, name)
+}
+
+// recordedCacher records close
+type recordedCacher struct {
+	*mapCacher
+	recorder *orderRecorder
+}
+
+func (c *recordedCacher) Close() error {
+	c.recorder.record("cacher")
+	return nil
+}
+
+// recordedPersister records slow save and close
+type recordedPersister struct {
+	*mapPersister
+	recorder *orderRecorder
+}
+
+func (p *recordedPersister) Save(ctx context.Context, key string, value any) error {
+	time.Sleep(10 * time.Millisecond)
+	p.recorder.record("save")
+	return p.mapPersister.Save(ctx, key, value)
+}
+
+func (p *recordedPersister) Close() error {
+	p.recorder.record("persister")
+	return errors.New("failed")
+}
+
+type stopperFunc func()
+
+func (f stopperFunc) Stop() { f() }
+
+func TestManager_Shutdown(t *testing.T) {
+	recorder := &orderRecorder{}
+	c, _ := New(&recordedCacher{newMapCacher(), recorder}, &recordedPersister{newMapPersister(), recorder}, WithPattern(&WriteBehind{}))
+
+	m := NewManager()
+	_ = m.Add(c)
+	_ = m.AddStopper(stopperFunc(func() { recorder.record("stopper") }))
+	_ = m.AddSubscription(c.Subscribe(func(Event) { recorder.record("event") }))
+
+	_ = c.Set(context.Background(), "key", "value")
+
+	err := m.Shutdown(context.Background())
+	if err == nil || err.Error() != "persister: failed" {
+		t.Errorf("Shutdown() error = %v, want %v", err, "persister: failed")
+	}
+
+	want := []string{"event", "stopper", "save", "cacher", "persister"}
+	if !reflect.DeepEqual(recorder.order, want) {
+		t.Errorf("Shutdown() order = %v, want %v", recorder.order, want)
+	}
+
+	if err := m.AddCacher(newMapCacher()); !errors.Is(err, ErrShutdown) {
+		t.Errorf("AddCacher() error = %v, want %v", err, ErrShutdown)
+	}
+
+	if again := m.Shutdown(context.Background()); again != err {
+		t.Errorf("Shutdown() error = %v, want %v", again, err)
+	}
+}
+
+func TestManager_ShutdownTimeout(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	m := NewManager()
+	_ = m.AddStopper(stopperFunc(func() { <-block }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := m.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
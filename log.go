@@ -0,0 +1,24 @@
+package cache
+
+import (
+	"log/slog"
+)
+
+// WithLogger returns option to set structured logger, records of operations and of failures
+// which patterns recover from, e.g. failed backfill of cache, carry pattern field,
+// backends have their own WithLogger option and their records carry backend field
+// by default nothing is logged
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *PatternedCache) {
+		c.slogger = logger
+	}
+}
+
+// WithLogLevel returns option to set log level of successful operations
+// and of failed operations, defaults are debug and warn level
+func WithLogLevel(level, errorLevel slog.Level) Option {
+	return func(c *PatternedCache) {
+		c.logLevel = level
+		c.errorLogLevel = errorLevel
+	}
+}
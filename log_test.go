@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	c, _ := New(newMapCacher(), newMapPersister(), WithPattern(&ReadThrough{}), WithLogger(logger))
+	_, _ = c.Get(context.Background(), "key")
+
+	for _, want := range []string{"pattern=ReadThrough", "op=get"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log = %q, want %q", buf.String(), want)
+		}
+	}
+}
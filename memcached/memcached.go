@@ -0,0 +1,342 @@
+// Package memcached is cacher of memcached
+package memcached
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+// relativeExpiration is longest expiration memcached treats as relative, longer expirations are unix times
+const relativeExpiration = 30 * 24 * time.Hour
+
+// Cacher is cache implementation with memcached
+//
+// memcached stores bytes, so values are marshalled if marshaller is set, otherwise []byte and string
+// values are stored as is and read as string, like values of redis cacher
+// keys with name prefix must be at most 250 bytes without spaces or control characters
+type Cacher struct {
+	client      *memcache.Client
+	servers     []string
+	ttl         time.Duration
+	prefix      internal.KeyPrefix
+	marshaller  cache.Marshaller
+	closeClient bool
+	notFound    bool
+	metrics     cache.Metrics
+
+	slogger       *slog.Logger
+	logLevel      slog.Level
+	errorLogLevel slog.Level
+	logger        *internal.Logger
+}
+
+// Option provides memcached cacher options
+type Option func(*Cacher)
+
+// New returns new memcached cacher, default server is localhost:11211
+func New(options ...Option) *Cacher {
+	c := &Cacher{
+		closeClient:   true,
+		logLevel:      slog.LevelDebug,
+		errorLogLevel: slog.LevelWarn,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	defaults(c)
+
+	return c
+}
+
+// defaults sets default memcached cacher option
+func defaults(c *Cacher) {
+	if c.client == nil {
+		servers := c.servers
+		if len(servers) == 0 {
+			servers = []string{"localhost:11211"}
+		}
+		c.client = memcache.New(servers...)
+	}
+
+	if c.prefix == nil {
+		c.prefix = &internal.NoPrefix{}
+	}
+
+	c.logger = internal.NewLogger(c.slogger, c.logLevel, c.errorLogLevel).With("backend", "memcached")
+}
+
+// WithServers returns option to set addresses of memcached servers, keys are distributed among them
+func WithServers(servers ...string) Option {
+	return func(c *Cacher) {
+		c.servers = servers
+	}
+}
+
+// WithSharedClient returns option with shared memcached client
+// if closeClient is true, client will be closed when this cacher is closed
+func WithSharedClient(client *memcache.Client, closeClient bool) Option {
+	return func(c *Cacher) {
+		c.client = client
+		c.closeClient = closeClient
+	}
+}
+
+// WithTTL returns option to set global TTL
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cacher) {
+		c.ttl = ttl
+	}
+}
+
+// WithName returns option to add name as prefix to key
+// if name is empty, no prefix will be added
+func WithName(name string) Option {
+	return func(c *Cacher) {
+		if len(name) == 0 {
+			c.prefix = &internal.NoPrefix{}
+			return
+		}
+
+		// prefix is always in lower case
+		c.prefix = &internal.WithPrefix{Name: strings.ToLower(name)}
+	}
+}
+
+// WithMarshaller returns option to set marshaller
+func WithMarshaller(marshaller cache.Marshaller) Option {
+	return func(c *Cacher) {
+		c.marshaller = marshaller
+	}
+}
+
+// WithCodec returns option to encode values with codec and decode them to type of prototype,
+// e.g. WithCodec(cache.JSONCodec{}, User{})
+func WithCodec(codec cache.Codec, prototype any) Option {
+	return WithMarshaller(cache.CodecMarshaller(codec, prototype))
+}
+
+// WithMetrics returns option to record results and latencies of operations to metrics, e.g. cache.Stats
+func WithMetrics(metrics cache.Metrics) Option {
+	return func(c *Cacher) {
+		c.metrics = metrics
+	}
+}
+
+// WithNotFoundError returns option to return cache.ErrNotFound instead of nil value on miss of Get
+func WithNotFoundError() Option {
+	return func(c *Cacher) {
+		c.notFound = true
+	}
+}
+
+// WithLogger returns option to set structured logger
+// by default nothing is logged
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Cacher) {
+		c.slogger = logger
+	}
+}
+
+// WithLogLevel returns option to set log level of successful operations
+// and of failed operations, defaults are debug and warn level
+func WithLogLevel(level, errorLevel slog.Level) Option {
+	return func(c *Cacher) {
+		c.logLevel = level
+		c.errorLogLevel = errorLevel
+	}
+}
+
+// expiration returns memcached expiration of ttl, 0 means no expiration
+func expiration(ttl time.Duration) int32 {
+	switch {
+	case ttl <= 0:
+		return 0
+	case ttl < time.Second:
+		return 1
+	case ttl > relativeExpiration:
+		return int32(time.Now().Add(ttl).Unix())
+	default:
+		return int32(ttl / time.Second)
+	}
+}
+
+// encode returns bytes of value stored to memcached
+func (c *Cacher) encode(value any) ([]byte, error) {
+	if c.marshaller != nil {
+		return c.marshaller.Marshal(value)
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, fmt.Errorf("%w: %T can not be stored without marshaller", cache.ErrUnexpectedType, value)
+	}
+}
+
+// decode returns value of bytes read from memcached, unmarshalled if marshaller is set
+func (c *Cacher) decode(data []byte) (any, error) {
+	if c.marshaller != nil {
+		return c.marshaller.Unmarshal(data)
+	}
+
+	return string(data), nil
+}
+
+// item returns item of key-value expiring after ttl
+func (c *Cacher) item(key string, value any, ttl time.Duration) (*memcache.Item, error) {
+	data, err := c.encode(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &memcache.Item{Key: c.prefix.Prefix(key), Value: data, Expiration: expiration(ttl)}, nil
+}
+
+// Set stores key-value to memcached
+func (c *Cacher) Set(ctx context.Context, key string, value any, setOptions ...cache.SetOption) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "set", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpSet, start, value, err)
+	}(time.Now())
+
+	setConfig := &cache.SetConfiguration{TTL: c.ttl}
+	for _, option := range setOptions {
+		option(setConfig)
+	}
+
+	item, err := c.item(key, value, setConfig.TTL)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(item)
+}
+
+// Get retrieves value from memcached, value is nil if key is not found
+func (c *Cacher) Get(ctx context.Context, key string) (value any, err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "get", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpGet, start, value, err)
+	}(time.Now())
+
+	item, err := c.client.Get(c.prefix.Prefix(key))
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		if c.notFound {
+			return nil, cache.ErrNotFound
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c.decode(item.Value)
+}
+
+// Delete deletes value from memcached, deleting missing key is not an error
+func (c *Cacher) Delete(ctx context.Context, key string) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "delete", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpDelete, start, nil, err)
+	}(time.Now())
+
+	if err := c.client.Delete(c.prefix.Prefix(key)); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		return err
+	}
+
+	return nil
+}
+
+// Load stores key-values to memcached with global TTL, memcached has no multi set so values are set one by one
+// returned error joins cache.KeyError of keys which fail to be stored
+func (c *Cacher) Load(ctx context.Context, data map[string]any) (err error) {
+	defer func(start time.Time) { cache.RecordOperation(c.metrics, cache.OpLoad, start, nil, err) }(time.Now())
+
+	var errs []error
+	for key, value := range data {
+		item, err := c.item(key, value, c.ttl)
+		if err == nil {
+			err = c.client.Set(item)
+		}
+		if err != nil {
+			errs = append(errs, &cache.KeyError{Key: key, Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetMany gets values of keys from memcached in one round trip per server, returned map contains only keys which are found
+func (c *Cacher) GetMany(ctx context.Context, keys []string) (_ map[string]any, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "get_many", strings.Join(keys, ","), start, err) }(time.Now())
+
+	values := make(map[string]any, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix.Prefix(key)
+	}
+
+	items, err := c.client.GetMulti(prefixed)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for i, key := range keys {
+		item, ok := items[prefixed[i]]
+		if !ok {
+			continue
+		}
+		value, err := c.decode(item.Value)
+		if err != nil {
+			errs = append(errs, &cache.KeyError{Key: key, Err: err})
+			continue
+		}
+		values[key] = value
+	}
+
+	return values, errors.Join(errs...)
+}
+
+// DeleteMany deletes values of keys from memcached, memcached has no multi delete so keys are deleted one by one
+func (c *Cacher) DeleteMany(ctx context.Context, keys ...string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := c.Delete(ctx, key); err != nil {
+			errs = append(errs, &cache.KeyError{Key: key, Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Ping checks connection to all memcached servers
+func (c *Cacher) Ping(context.Context) error {
+	return c.client.Ping()
+}
+
+// Close closes memcached client if cacher owns it
+func (c *Cacher) Close() error {
+	if !c.closeClient {
+		return nil
+	}
+
+	return c.client.Close()
+}
@@ -0,0 +1,153 @@
+package memcached
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/memory"
+	"github.com/albinzx/cache/server/memcached"
+)
+
+type user struct {
+	ID   int
+	Name string
+}
+
+// newServer starts memcached server of memory cacher and returns its address
+func newServer(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	s := memcached.New(memory.New())
+	go func() { _ = s.Serve(listener) }()
+	t.Cleanup(func() { _ = s.Close() })
+
+	return listener.Addr().String()
+}
+
+func TestCacher(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithServers(newServer(t)), WithName("Test"))
+	defer c.Close()
+
+	if err := c.Ping(ctx); err != nil {
+		t.Fatalf("Ping() error = %v", err)
+	}
+
+	if err := c.Set(ctx, "key", "value"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := c.Get(ctx, "key"); err != nil || got != "value" {
+		t.Errorf("Get() = %v, %v, want value", got, err)
+	}
+
+	if err := c.Set(ctx, "bytes", []byte("value")); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := c.Get(ctx, "bytes"); err != nil || got != "value" {
+		t.Errorf("Get() = %v, %v, want value", got, err)
+	}
+
+	if err := c.Set(ctx, "user", user{ID: 1}); !errors.Is(err, cache.ErrUnexpectedType) {
+		t.Errorf("Set() error = %v, want %v", err, cache.ErrUnexpectedType)
+	}
+
+	if err := c.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := c.Delete(ctx, "key"); err != nil {
+		t.Errorf("Delete() missing error = %v", err)
+	}
+	if got, err := c.Get(ctx, "key"); err != nil || got != nil {
+		t.Errorf("Get() deleted = %v, %v, want nil", got, err)
+	}
+
+	// other cacher without name does not see prefixed keys
+	other := New(WithServers(c.servers...))
+	if got, err := other.Get(ctx, "bytes"); err != nil || got != nil {
+		t.Errorf("Get() without prefix = %v, %v, want nil", got, err)
+	}
+	if got, err := other.Get(ctx, "test.bytes"); err != nil || got != "value" {
+		t.Errorf("Get() prefixed = %v, %v, want value", got, err)
+	}
+}
+
+func TestCacher_NotFound(t *testing.T) {
+	c := New(WithServers(newServer(t)), WithNotFoundError())
+
+	if _, err := c.Get(context.Background(), "missing"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, cache.ErrNotFound)
+	}
+}
+
+func TestCacher_Codec(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithServers(newServer(t)), WithCodec(cache.JSONCodec{}, user{}))
+
+	want := user{ID: 1, Name: "one"}
+	if err := c.Set(ctx, "user", want); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, err := c.Get(ctx, "user"); err != nil || got != want {
+		t.Errorf("Get() = %v, %v, want %v", got, err, want)
+	}
+}
+
+func TestCacher_Many(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithServers(newServer(t)), WithTTL(time.Minute))
+
+	if err := c.Load(ctx, map[string]any{"a": "1", "b": "2", "c": 3}); err == nil {
+		t.Error("Load() error = nil, want error of unmarshalled value")
+	} else {
+		var keyErr *cache.KeyError
+		if !errors.As(err, &keyErr) || keyErr.Key != "c" {
+			t.Errorf("Load() error = %v, want key error of c", err)
+		}
+	}
+
+	got, err := c.GetMany(ctx, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	if want := map[string]any{"a": "1", "b": "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMany() = %v, want %v", got, want)
+	}
+
+	if err := c.DeleteMany(ctx, "a", "c"); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	got, _ = c.GetMany(ctx, []string{"a", "b"})
+	if want := map[string]any{"b": "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMany() = %v, want %v", got, want)
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want func(int32) bool
+	}{
+		{name: "test no ttl", ttl: 0, want: func(e int32) bool { return e == 0 }},
+		{name: "test sub second", ttl: time.Millisecond, want: func(e int32) bool { return e == 1 }},
+		{name: "test relative", ttl: time.Hour, want: func(e int32) bool { return e == 3600 }},
+		{name: "test absolute", ttl: 60 * 24 * time.Hour, want: func(e int32) bool { return int64(e) > time.Now().Unix() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expiration(tt.ttl); !tt.want(got) {
+				t.Errorf("expiration(%v) = %d", tt.ttl, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// memoizeConfig holds configuration of memoized function
+type memoizeConfig struct {
+	ttl        time.Duration
+	prefix     string
+	key        func(any) string
+	marshaller Marshaller
+	shared     bool
+}
+
+// MemoizeOption provides options for memoized function
+type MemoizeOption func(*memoizeConfig)
+
+// WithMemoizeTTL returns option to set time to live of memoized results, default is cacher TTL
+func WithMemoizeTTL(ttl time.Duration) MemoizeOption {
+	return func(config *memoizeConfig) {
+		config.ttl = ttl
+	}
+}
+
+// WithMemoizePrefix returns option to set prefix of cache keys, e.g. to memoize multiple functions in one cacher
+func WithMemoizePrefix(prefix string) MemoizeOption {
+	return func(config *memoizeConfig) {
+		config.prefix = prefix
+	}
+}
+
+// WithMemoizeKey returns option to set function returning cache key of argument, default formats argument with %v
+func WithMemoizeKey(key func(arg any) string) MemoizeOption {
+	return func(config *memoizeConfig) {
+		config.key = key
+	}
+}
+
+// WithMemoizeMarshaller returns option to store results marshalled, e.g. in remote cacher,
+// marshaller must unmarshal to result type
+func WithMemoizeMarshaller(marshaller Marshaller) MemoizeOption {
+	return func(config *memoizeConfig) {
+		config.marshaller = marshaller
+	}
+}
+
+// WithoutMemoizeSharing returns option to call function for every concurrent miss of the same argument,
+// by default concurrent calls share a single call
+func WithoutMemoizeSharing() MemoizeOption {
+	return func(config *memoizeConfig) {
+		config.shared = false
+	}
+}
+
+// Memoize returns function which caches results of fn in cacher
+// errors of fn are not cached, and cacher errors fall back to calling fn
+func Memoize[K any, V any](c Cacher, fn func(context.Context, K) (V, error), options ...MemoizeOption) func(context.Context, K) (V, error) {
+	config := &memoizeConfig{
+		key:    func(arg any) string { return fmt.Sprint(arg) },
+		shared: true,
+	}
+	for _, option := range options {
+		option(config)
+	}
+
+	if config.marshaller != nil {
+		c = EncodedAs[V](c, MarshallerCodec(config.marshaller))
+	}
+	codec := &Typed[V]{marshaller: config.marshaller}
+	group := &internal.Group{}
+
+	call := func(ctx context.Context, key string, arg K) (V, error) {
+		value, err := fn(ctx, arg)
+		if err != nil {
+			return value, err
+		}
+
+		var setOptions []SetOption
+		if config.ttl > 0 {
+			setOptions = append(setOptions, WithTTL(config.ttl))
+		}
+		_ = c.Set(ctx, key, value, setOptions...)
+
+		return value, nil
+	}
+
+	return func(ctx context.Context, arg K) (V, error) {
+		key := config.prefix + config.key(arg)
+
+		if cached, err := c.Get(ctx, key); err == nil && cached != nil {
+			if value, err := codec.decode(key, cached); err == nil {
+				return value, nil
+			}
+		}
+
+		if !config.shared {
+			return call(ctx, key, arg)
+		}
+
+		value, err, _ := group.Do(key, func() (any, error) {
+			return call(ctx, key, arg)
+		})
+		if err != nil {
+			var zero V
+			return zero, err
+		}
+
+		return value.(V), nil
+	}
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoize(t *testing.T) {
+	var calls atomic.Int32
+	cacher := newMapCacher()
+	square := Memoize(cacher, func(_ context.Context, n int) (int, error) {
+		calls.Add(1)
+		if n < 0 {
+			return 0, errors.New("negative")
+		}
+		return n * n, nil
+	}, WithMemoizePrefix("square:"))
+
+	tests := []struct {
+		name      string
+		arg       int
+		want      int
+		wantErr   bool
+		wantCalls int32
+	}{
+		{name: "test miss", arg: 3, want: 9, wantCalls: 1},
+		{name: "test hit", arg: 3, want: 9, wantCalls: 0},
+		{name: "test other argument", arg: 4, want: 16, wantCalls: 1},
+		{name: "test error", arg: -1, wantErr: true, wantCalls: 1},
+		{name: "test error not cached", arg: -1, wantErr: true, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := calls.Load()
+			got, err := square(context.Background(), tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Memoize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Memoize() = %v, want %v", got, tt.want)
+			}
+			if got := calls.Load() - before; got != tt.wantCalls {
+				t.Errorf("Memoize() calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+
+	if got := cacher.data["square:3"]; got != 9 {
+		t.Errorf("Memoize() cached = %v, want %v", got, 9)
+	}
+}
+
+func TestMemoize_Shared(t *testing.T) {
+	var calls atomic.Int32
+	slow := Memoize(newMapCacher(), func(_ context.Context, n int) (string, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return strconv.Itoa(n), nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, _ := slow(context.Background(), 7); got != "7" {
+				t.Errorf("Memoize() = %v, want %v", got, "7")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Memoize() calls = %v, want %v", got, 1)
+	}
+}
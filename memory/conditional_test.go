@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+func TestCacher_SetIf(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range map[string]*Cacher{"unbounded": New(), "bounded": New(WithMaxEntries(10))} {
+		t.Run(name, func(t *testing.T) {
+			if err := c.Set(ctx, "key", 1, cache.IfExists()); !errors.Is(err, cache.ErrNotStored) {
+				t.Errorf("Set() IfExists of missing key error = %v, want %v", err, cache.ErrNotStored)
+			}
+			if stored, err := cache.SetNX(ctx, c, "key", 1, cache.WithTTL(time.Hour)); !stored || err != nil {
+				t.Fatalf("SetNX() = %v, %v, want true", stored, err)
+			}
+			if stored, err := cache.SetNX(ctx, c, "key", 2); stored || err != nil {
+				t.Errorf("SetNX() of existing key = %v, %v, want false", stored, err)
+			}
+			if stored, err := cache.SetIf(ctx, c, "key", 3, cache.IfExists(), cache.KeepTTL()); !stored || err != nil {
+				t.Fatalf("SetIf() IfExists = %v, %v, want true", stored, err)
+			}
+
+			got, ttl, err := c.GetWithTTL(ctx, "key")
+			if err != nil || got != 3 {
+				t.Errorf("GetWithTTL() = %v, %v, want %v", got, err, 3)
+			}
+			if ttl <= 0 || ttl > time.Hour {
+				t.Errorf("GetWithTTL() ttl = %v, want kept ttl", ttl)
+			}
+		})
+	}
+}
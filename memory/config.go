@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+func init() {
+	cache.RegisterBackend("memory", fromConfig)
+}
+
+// fromConfig returns memory cacher of backend config, values are stored marshalled if marshaller is set
+// options max, max_bytes and shards bound cacher and option cleanup sets cleanup interval
+func fromConfig(config cache.BackendConfig, marshaller cache.Marshaller) (cache.Cacher, error) {
+	represent, err := cache.ParseRepresentation(config.Representation)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []Option{WithTTL(time.Duration(config.TTL)), WithRepresentation(represent)}
+	if marshaller != nil {
+		options = append(options, WithMarshaller(marshaller))
+	}
+
+	for name, value := range config.Options {
+		option, err := optionOf(name, value)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, option)
+	}
+
+	return NewE(options...)
+}
+
+// optionOf returns option of backend specific option name and value
+func optionOf(name, value string) (Option, error) {
+	if name == "cleanup" {
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cleanup %q: %v", cache.ErrInvalidOption, value, err)
+		}
+		return WithCleanupInterval(interval), nil
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %q is not a number", cache.ErrInvalidOption, name, value)
+	}
+
+	switch name {
+	case "max":
+		return WithMaxEntries(int(n)), nil
+	case "max_bytes":
+		return WithMaxBytes(n), nil
+	case "shards":
+		return WithShards(int(n)), nil
+	default:
+		return nil, fmt.Errorf("%w: unknown option %q", cache.ErrInvalidOption, name)
+	}
+}
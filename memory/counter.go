@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// Increment adds delta to value of key and returns new value, key which does not exist starts from 0
+// and expires after ttl of options, ttl of existing key is kept
+// counters are stored as int64, or as decimal string or bytes with string or bytes representation or with marshaller
+func (c *Cacher) Increment(ctx context.Context, key string, delta int64, setOptions ...cache.SetOption) (value int64, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "increment", key, start, err) }(time.Now())
+
+	setConfig := &cache.SetConfiguration{TTL: c.ttl}
+	for _, option := range setOptions {
+		option(setConfig)
+	}
+
+	c.counters.Lock()
+	defer c.counters.Unlock()
+
+	ttl := setConfig.TTL
+	if current, expiration, ok := c.store.get(key); ok {
+		if value, err = integer(current); err != nil {
+			return 0, fmt.Errorf("%s: %w", key, err)
+		}
+		ttl = remaining(expiration, c.clock.Now())
+	}
+	value += delta
+
+	var stored any = value
+	if c.marshaller != nil {
+		// like redis, counters are not marshalled
+		stored = []byte(strconv.FormatInt(value, 10))
+	} else if c.represent != cache.RepresentNative {
+		if stored, err = c.represent.Convert(strconv.FormatInt(value, 10)); err != nil {
+			return 0, err
+		}
+	}
+	if err := c.store.set(key, stored, ttl, c.cost(setConfig, stored)); err != nil {
+		return 0, err
+	}
+
+	return value, nil
+}
+
+// Decrement subtracts delta from value of key and returns new value
+func (c *Cacher) Decrement(ctx context.Context, key string, delta int64, setOptions ...cache.SetOption) (int64, error) {
+	return c.Increment(ctx, key, -delta, setOptions...)
+}
+
+// integer returns value as int64, value must be integer or its decimal string or bytes
+func integer(value any) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case string:
+		return parseInteger(v)
+	case []byte:
+		return parseInteger(string(v))
+	default:
+		return 0, fmt.Errorf("%w: %T", cache.ErrNotInteger, value)
+	}
+}
+
+// parseInteger parses decimal integer s
+func parseInteger(s string) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", cache.ErrNotInteger, s)
+	}
+
+	return n, nil
+}
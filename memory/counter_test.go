@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+func TestCacher_Increment(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.Increment(ctx, "hits", 2, cache.WithTTL(time.Hour))
+		}()
+	}
+	wg.Wait()
+
+	if got, err := c.Decrement(ctx, "hits", 1); err != nil || got != 99 {
+		t.Errorf("Decrement() = %v, %v, want %v", got, err, 99)
+	}
+	if _, ttl, _ := c.GetWithTTL(ctx, "hits"); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("GetWithTTL() ttl = %v, want ttl of first increment", ttl)
+	}
+
+	_ = c.Set(ctx, "name", "one")
+	if _, err := cache.Increment(ctx, c, "name", 1); !errors.Is(err, cache.ErrNotInteger) {
+		t.Errorf("Increment() error = %v, want %v", err, cache.ErrNotInteger)
+	}
+}
+
+func TestCacher_Increment_Representation(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithRepresentation(cache.RepresentString))
+
+	_ = c.Set(ctx, "hits", "41")
+	if got, err := c.Increment(ctx, "hits", 1); err != nil || got != 42 {
+		t.Errorf("Increment() = %v, %v, want %v", got, err, 42)
+	}
+	if got, _ := c.Get(ctx, "hits"); got != "42" {
+		t.Errorf("Get() = %v, want %v", got, "42")
+	}
+}
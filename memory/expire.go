@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/albinzx/cache"
+)
+
+// expiry dispatches keys evicted by expiration or by bound to listeners
+type expiry struct {
+	mu        sync.RWMutex
+	id        int
+	listeners map[int]func(cache.Eviction)
+	// deleting counts in-flight deletes of keys, eviction callback
+	// of go-cache is also called on delete which is not expiration
+	deleting map[string]int
+}
+
+// evicted is eviction callback of go-cache
+func (e *expiry) evicted(key string, value any) {
+	e.mu.RLock()
+	deleting := e.deleting[key] > 0
+	e.mu.RUnlock()
+
+	if !deleting {
+		e.notify(cache.Eviction{Key: key, Value: value, Reason: cache.EvictionExpired})
+	}
+}
+
+// notify calls listeners with eviction
+func (e *expiry) notify(eviction cache.Eviction) {
+	e.mu.RLock()
+	listeners := make([]func(cache.Eviction), 0, len(e.listeners))
+	for _, listener := range e.listeners {
+		listeners = append(listeners, listener)
+	}
+	e.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(eviction)
+	}
+}
+
+// listen calls fn with evictions until returned function is called
+func (e *expiry) listen(fn func(cache.Eviction)) func() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.id++
+	id := e.id
+	e.listeners[id] = fn
+
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		delete(e.listeners, id)
+	}
+}
+
+// delete runs fn deleting key without reporting it as expired
+func (e *expiry) delete(key string, fn func()) {
+	e.mu.Lock()
+	e.deleting[key]++
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		if e.deleting[key]--; e.deleting[key] == 0 {
+			delete(e.deleting, key)
+		}
+		e.mu.Unlock()
+	}()
+
+	fn()
+}
+
+// NotifyExpired calls fn with keys removed by expiration until returned function is called
+// expired keys are removed every cleanup interval, see WithCleanupInterval
+func (c *Cacher) NotifyExpired(_ context.Context, fn func(key string)) (func(), error) {
+	return c.expiry.listen(func(eviction cache.Eviction) {
+		if eviction.Reason == cache.EvictionExpired {
+			fn(eviction.Key)
+		}
+	}), nil
+}
+
+// NotifyEvicted calls fn with keys and values removed by expiration or by bound of entries or bytes
+// until returned function is called, expired keys are removed every cleanup interval, see WithCleanupInterval
+func (c *Cacher) NotifyEvicted(_ context.Context, fn func(cache.Eviction)) (func(), error) {
+	return c.expiry.listen(fn), nil
+}
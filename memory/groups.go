@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// group is group of keys, members are ordered by sequence of their last addition
+type group struct {
+	members    map[string]uint64
+	expiration time.Time
+}
+
+// expired reports whether group is expired at now
+func (g *group) expired(now time.Time) bool {
+	return !g.expiration.IsZero() && !now.Before(g.expiration)
+}
+
+// groupIndex holds groups of keys
+type groupIndex struct {
+	mu     sync.Mutex
+	seq    uint64
+	groups map[string]*group
+	now    func() time.Time
+}
+
+// newGroupIndex returns empty group index
+func newGroupIndex(now func() time.Time) *groupIndex {
+	return &groupIndex{groups: map[string]*group{}, now: now}
+}
+
+// add adds key to group and extends expiration of group to ttl, so group is kept as long as its members,
+// 0 ttl keeps group until it is invalidated
+func (i *groupIndex) add(name, key string, ttl time.Duration) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	now := i.now()
+	g, ok := i.groups[name]
+	created := !ok || g.expired(now)
+	if created {
+		g = &group{members: map[string]uint64{}}
+		i.groups[name] = g
+	}
+
+	i.seq++
+	g.members[key] = i.seq
+
+	expiration := now.Add(ttl)
+	switch {
+	case ttl <= 0:
+		g.expiration = time.Time{}
+	case created || (!g.expiration.IsZero() && g.expiration.Before(expiration)):
+		g.expiration = expiration
+	}
+}
+
+// remove removes keys from group
+func (i *groupIndex) remove(name string, keys []string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	g, ok := i.groups[name]
+	if !ok {
+		return
+	}
+	for _, key := range keys {
+		delete(g.members, key)
+	}
+	if len(g.members) == 0 {
+		delete(i.groups, name)
+	}
+}
+
+// members returns keys of group in order of addition, expired group is dropped
+func (i *groupIndex) members(name string) []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.ordered(name)
+}
+
+// drop removes group and returns its keys in order of addition
+func (i *groupIndex) drop(name string) []string {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	keys := i.ordered(name)
+	delete(i.groups, name)
+
+	return keys
+}
+
+// ordered returns keys of group in order of addition, expired group is dropped, index must be locked
+func (i *groupIndex) ordered(name string) []string {
+	g, ok := i.groups[name]
+	if !ok {
+		return nil
+	}
+	if g.expired(i.now()) {
+		delete(i.groups, name)
+		return nil
+	}
+
+	keys := make([]string, 0, len(g.members))
+	for key := range g.members {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool { return g.members[keys[a]] < g.members[keys[b]] })
+
+	return keys
+}
+
+// reset removes all groups
+func (i *groupIndex) reset() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.groups = map[string]*group{}
+}
+
+// AddToGroup adds key to group, or moves it to the end if it is a member, group expires after the longest
+// ttl of options of its additions and does not expire once key is added without ttl,
+// options without ttl use ttl of cacher
+func (c *Cacher) AddToGroup(ctx context.Context, group, key string, setOptions ...cache.SetOption) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "add_to_group", key, start, err) }(time.Now())
+
+	setConfig := &cache.SetConfiguration{TTL: c.ttl}
+	for _, option := range setOptions {
+		option(setConfig)
+	}
+
+	c.groups.add(group, key, setConfig.TTL)
+
+	return nil
+}
+
+// RemoveFromGroup removes keys from group, values of keys are kept
+func (c *Cacher) RemoveFromGroup(ctx context.Context, group string, keys ...string) error {
+	c.groups.remove(group, keys)
+
+	return nil
+}
+
+// GroupMembers returns keys of group in order they are added, nil is returned if group does not exist
+func (c *Cacher) GroupMembers(ctx context.Context, group string) ([]string, error) {
+	return c.groups.members(group), nil
+}
+
+// InvalidateGroup deletes values of members of group and group
+func (c *Cacher) InvalidateGroup(ctx context.Context, group string) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "invalidate_group", group, start, err) }(time.Now())
+
+	return c.DeleteMany(ctx, c.groups.drop(group)...)
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+func TestCacher_Groups(t *testing.T) {
+	ctx := context.Background()
+	m := New()
+	c, _ := cache.New(m, nil)
+
+	for _, key := range []string{"order:1", "order:2", "order:3"} {
+		_ = c.Set(ctx, key, key+" value")
+		if err := c.AddToGroup(ctx, "user:42:orders", key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = c.AddToGroup(ctx, "user:42:orders", "order:1")
+	_ = m.RemoveFromGroup(ctx, "user:42:orders", "order:2")
+	_ = c.Delete(ctx, "order:3")
+
+	members, _ := m.GroupMembers(ctx, "user:42:orders")
+	if want := []string{"order:3", "order:1"}; !reflect.DeepEqual(members, want) {
+		t.Errorf("GroupMembers() = %v, want %v", members, want)
+	}
+
+	values, err := c.GetGroup(ctx, "user:42:orders")
+	if want := map[string]any{"order:1": "order:1 value"}; err != nil || !reflect.DeepEqual(values, want) {
+		t.Errorf("GetGroup() = %v, %v, want %v", values, err, want)
+	}
+
+	if err := c.InvalidateGroup(ctx, "user:42:orders"); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := m.Get(ctx, "order:1"); value != nil {
+		t.Errorf("Get() of member after invalidation = %v, want nil", value)
+	}
+	if members, _ := m.GroupMembers(ctx, "user:42:orders"); members != nil {
+		t.Errorf("GroupMembers() after invalidation = %v, want nil", members)
+	}
+}
+
+func TestCacher_GroupTTL(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+
+	_ = c.AddToGroup(ctx, "group", "key", cache.WithTTL(time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	if members, _ := c.GroupMembers(ctx, "group"); members != nil {
+		t.Errorf("GroupMembers() of expired group = %v, want nil", members)
+	}
+}
+
+func TestCacher_GroupTTLExtended(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+
+	// shorter ttl of later addition does not shorten group
+	_ = c.AddToGroup(ctx, "group", "a", cache.WithTTL(time.Minute))
+	_ = c.AddToGroup(ctx, "group", "b", cache.WithTTL(time.Millisecond))
+	// ttl of addition does not expire group of member without ttl
+	_ = c.AddToGroup(ctx, "persistent", "a")
+	_ = c.AddToGroup(ctx, "persistent", "b", cache.WithTTL(time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	if members, _ := c.GroupMembers(ctx, "group"); len(members) != 2 {
+		t.Errorf("GroupMembers() = %v, want a and b", members)
+	}
+	if members, _ := c.GroupMembers(ctx, "persistent"); len(members) != 2 {
+		t.Errorf("GroupMembers() = %v, want a and b", members)
+	}
+}
@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"container/list"
+	"errors"
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+var (
+	// ErrTooLarge is returned when value costs more bytes than shard of bounded cacher holds
+	ErrTooLarge = errors.New("memory: value exceeds capacity")
+)
+
+// defaultShards is default number of shards of bounded store
+const defaultShards = 16
+
+// bounded is store of shards evicting least recently used keys when they exceed entries or bytes bound
+// bounds are split evenly among shards and every shard has its own lock
+type bounded struct {
+	seed    maphash.Seed
+	shards  []*shard
+	clock   cache.Clock
+	expired func(string, any)
+	evicted func(string, any)
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// shard is part of bounded store with its own lock and least recently used order
+type shard struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List
+	bytes      int64
+	maxEntries int
+	maxBytes   int64
+}
+
+// entry is value of key in shard
+type entry struct {
+	key        string
+	value      any
+	expiration time.Time
+	cost       int64
+}
+
+// newBounded returns bounded store of shards holding at most maxEntries keys and maxBytes bytes, 0 is no bound,
+// expired keys are removed every cleanup interval and reported to expired, keys evicted to make room
+// are reported to evicted, expiration is checked at time of clock which ticks cleanup
+// janitor removing expired keys runs until store is flushed, so store must be flushed when it is not used
+func newBounded(shards, maxEntries int, maxBytes int64, cleanup time.Duration, clock cache.Clock, expired, evicted func(string, any)) *bounded {
+	if shards <= 0 {
+		shards = defaultShards
+	}
+	if maxEntries > 0 && shards > maxEntries {
+		shards = maxEntries
+	}
+	if maxBytes > 0 && int64(shards) > maxBytes {
+		shards = int(maxBytes)
+	}
+
+	b := &bounded{
+		seed:    maphash.MakeSeed(),
+		shards:  make([]*shard, shards),
+		clock:   clock,
+		expired: expired,
+		evicted: evicted,
+		stop:    make(chan struct{}),
+	}
+	for i := range b.shards {
+		b.shards[i] = &shard{
+			items:      map[string]*list.Element{},
+			order:      list.New(),
+			maxEntries: share(maxEntries, shards, i),
+			maxBytes:   share(maxBytes, shards, i),
+		}
+	}
+
+	go b.janitor(cleanup)
+
+	return b
+}
+
+// share returns part of bound n of shard i of shards, remainder of division is spread over the first shards
+// so parts add up to n
+func share[T int | int64](n T, shards, i int) T {
+	part := n / T(shards)
+	if T(i) < n%T(shards) {
+		part++
+	}
+
+	return part
+}
+
+// shard returns shard of key
+func (b *bounded) shard(key string) *shard {
+	return b.shards[maphash.String(b.seed, key)%uint64(len(b.shards))]
+}
+
+func (b *bounded) set(key string, value any, ttl time.Duration, cost int64) error {
+	_, err := b.setIf(key, value, ttl, cost, cache.SetAlways, false)
+	return err
+}
+
+func (b *bounded) setIf(key string, value any, ttl time.Duration, cost int64, mode cache.SetMode, keepTTL bool) (bool, error) {
+	s := b.shard(key)
+	now := b.clock.Now()
+	e := &entry{key: key, value: value, cost: int64(len(key)) + cost}
+	if ttl > 0 {
+		e.expiration = now.Add(ttl)
+	}
+
+	s.mu.Lock()
+	element, exists := s.items[key]
+	if exists && element.Value.(*entry).expired(now) {
+		exists = false
+	}
+	if (mode == cache.SetIfNotExists && exists) || (mode == cache.SetIfExists && !exists) {
+		s.mu.Unlock()
+		return false, nil
+	}
+	if keepTTL && exists {
+		e.expiration = element.Value.(*entry).expiration
+	}
+
+	if s.maxBytes > 0 && e.cost > s.maxBytes {
+		s.remove(key)
+		s.mu.Unlock()
+		return false, ErrTooLarge
+	}
+
+	if element, ok := s.items[key]; ok {
+		s.bytes -= element.Value.(*entry).cost
+		element.Value = e
+		s.order.MoveToFront(element)
+	} else {
+		s.items[key] = s.order.PushFront(e)
+	}
+	s.bytes += e.cost
+
+	var evicted []*entry
+	for (s.maxEntries > 0 && len(s.items) > s.maxEntries) || (s.maxBytes > 0 && s.bytes > s.maxBytes) {
+		oldest := s.order.Back().Value.(*entry)
+		s.remove(oldest.key)
+		evicted = append(evicted, oldest)
+	}
+	s.mu.Unlock()
+
+	b.notify(b.evicted, evicted)
+
+	return true, nil
+}
+
+func (b *bounded) get(key string) (any, time.Time, bool) {
+	s := b.shard(key)
+
+	s.mu.Lock()
+	element, ok := s.items[key]
+	if !ok {
+		s.mu.Unlock()
+		return nil, time.Time{}, false
+	}
+
+	e := element.Value.(*entry)
+	if e.expired(b.clock.Now()) {
+		s.remove(key)
+		s.mu.Unlock()
+		b.notify(b.expired, []*entry{e})
+		return nil, time.Time{}, false
+	}
+	s.order.MoveToFront(element)
+	s.mu.Unlock()
+
+	return e.value, e.expiration, true
+}
+
+func (b *bounded) expire(key string, ttl time.Duration) bool {
+	s := b.shard(key)
+	now := b.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.items[key]
+	if !ok || element.Value.(*entry).expired(now) {
+		return false
+	}
+
+	e := element.Value.(*entry)
+	e.expiration = time.Time{}
+	if ttl > 0 {
+		e.expiration = now.Add(ttl)
+	}
+
+	return true
+}
+
+func (b *bounded) delete(key string) {
+	s := b.shard(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(key)
+}
+
+func (b *bounded) keys() []string {
+	now := b.clock.Now()
+
+	var keys []string
+	for _, s := range b.shards {
+		s.mu.Lock()
+		for key, element := range s.items {
+			if !element.Value.(*entry).expired(now) {
+				keys = append(keys, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+
+	return keys
+}
+
+// flush deletes all keys and stops removing expired keys
+func (b *bounded) flush() {
+	for _, s := range b.shards {
+		s.mu.Lock()
+		s.items = map[string]*list.Element{}
+		s.order.Init()
+		s.bytes = 0
+		s.mu.Unlock()
+	}
+
+	b.once.Do(func() { close(b.stop) })
+}
+
+// janitor removes expired keys every cleanup interval until store is flushed
+func (b *bounded) janitor(cleanup time.Duration) {
+	ticker := b.clock.NewTicker(cleanup)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			for _, s := range b.shards {
+				b.notify(b.expired, s.removeExpired(b.clock.Now()))
+			}
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// notify calls fn with keys and values of entries, outside of shard lock
+func (b *bounded) notify(fn func(string, any), entries []*entry) {
+	if fn == nil {
+		return
+	}
+
+	for _, e := range entries {
+		fn(e.key, e.value)
+	}
+}
+
+// remove removes key from shard, shard must be locked
+func (s *shard) remove(key string) {
+	element, ok := s.items[key]
+	if !ok {
+		return
+	}
+
+	s.order.Remove(element)
+	delete(s.items, key)
+	s.bytes -= element.Value.(*entry).cost
+}
+
+// removeExpired removes and returns entries expired at now
+func (s *shard) removeExpired(now time.Time) []*entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []*entry
+	for key, element := range s.items {
+		if e := element.Value.(*entry); e.expired(now) {
+			s.remove(key)
+			expired = append(expired, e)
+		}
+	}
+
+	return expired
+}
+
+// expired reports whether entry is expired at now
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiration.IsZero() && now.After(e.expiration)
+}
@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+)
+
+func TestBoundedConformance(t *testing.T) {
+	cachetest.Conformance(t, func(testing.TB) cache.Cacher { return New(WithMaxEntries(1000)) })
+}
+
+func TestWithMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	var evicted []string
+	c := New(WithMaxEntries(2), WithShards(1), WithOnEvict(func(key string, _ any) { evicted = append(evicted, key) }))
+	defer c.Close()
+
+	_ = c.Set(ctx, "a", "one")
+	_ = c.Set(ctx, "b", "two")
+	// a is used so b is least recently used
+	_, _ = c.Get(ctx, "a")
+	_ = c.Set(ctx, "c", "three")
+
+	if got, _ := c.Get(ctx, "b"); got != nil {
+		t.Errorf("Get() = %v, want evicted", got)
+	}
+	for _, key := range []string{"a", "c"} {
+		if got, _ := c.Get(ctx, key); got == nil {
+			t.Errorf("Get(%v) = nil, want kept", key)
+		}
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("evicted = %v, want [b]", evicted)
+	}
+}
+
+func TestWithMaxEntries_Shards(t *testing.T) {
+	b := newBounded(4, 10, 6, time.Minute, cache.SystemClock, nil, nil)
+	defer b.flush()
+
+	var entries int
+	var bytes int64
+	for _, s := range b.shards {
+		entries += s.maxEntries
+		bytes += s.maxBytes
+	}
+	// remainders of bounds are spread over shards instead of rounding every shard up
+	if entries != 10 || bytes != 6 {
+		t.Errorf("bounds of shards = %v entries, %v bytes, want %v, %v", entries, bytes, 10, 6)
+	}
+}
+
+func TestWithMaxBytes(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithMaxBytes(20), WithShards(1))
+	defer c.Close()
+
+	_ = c.Set(ctx, "a", strings.Repeat("x", 10))
+	_ = c.Set(ctx, "b", strings.Repeat("y", 10))
+	if got, _ := c.Get(ctx, "a"); got != nil {
+		t.Errorf("Get() = %v, want evicted", got)
+	}
+	if got, _ := c.Get(ctx, "b"); got == nil {
+		t.Errorf("Get() = nil, want kept")
+	}
+
+	if err := c.Set(ctx, "c", strings.Repeat("z", 20)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Set() error = %v, want %v", err, ErrTooLarge)
+	}
+	if err := c.Set(ctx, "d", "small", cache.WithCost(100)); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Set() error = %v, want %v", err, ErrTooLarge)
+	}
+}
+
+func TestBounded_Expiration(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithMaxEntries(10), WithCleanupInterval(10*time.Millisecond))
+	defer c.Close()
+
+	expired := make(chan string, 1)
+	stop, _ := c.NotifyExpired(ctx, func(key string) { expired <- key })
+	defer stop()
+
+	_ = c.Set(ctx, "a", "one", cache.WithTTL(20*time.Millisecond))
+	if _, ttl, _ := c.GetWithTTL(ctx, "a"); ttl <= 0 {
+		t.Errorf("GetWithTTL() ttl = %v, want positive", ttl)
+	}
+
+	select {
+	case key := <-expired:
+		if key != "a" {
+			t.Errorf("expired = %v, want %v", key, "a")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("key did not expire")
+	}
+	if got, _ := c.Get(ctx, "a"); got != nil {
+		t.Errorf("Get() = %v, want nil", got)
+	}
+}
+
+func TestBounded_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithMaxEntries(64))
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				key := fmt.Sprint(i, ".", j)
+				_ = c.Set(ctx, key, j)
+				_, _ = c.Get(ctx, key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	keys := 0
+	for it := c.Keys(ctx, ""); it.Next(ctx); {
+		keys++
+	}
+	if keys > 64 {
+		t.Errorf("keys = %v, want at most %v", keys, 64)
+	}
+}
+
+func TestWithOnEviction(t *testing.T) {
+	ctx := context.Background()
+	evictions := make(chan cache.Eviction, 2)
+	c := New(WithMaxEntries(1), WithCleanupInterval(10*time.Millisecond), WithOnEviction(func(eviction cache.Eviction) {
+		evictions <- eviction
+	}))
+	defer c.Close()
+
+	_ = c.Set(ctx, "a", "one")
+	_ = c.Set(ctx, "b", "two", cache.WithTTL(20*time.Millisecond))
+	_ = c.Delete(ctx, "a")
+
+	want := []cache.Eviction{
+		{Key: "a", Value: "one", Reason: cache.EvictionCapacity},
+		{Key: "b", Value: "two", Reason: cache.EvictionExpired},
+	}
+	for _, w := range want {
+		select {
+		case got := <-evictions:
+			if got != w {
+				t.Errorf("eviction = %+v, want %+v", got, w)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("key %s was not evicted", w.Key)
+		}
+	}
+}
@@ -2,81 +2,323 @@ package memory
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/albinzx/cache"
-	mem "github.com/patrickmn/go-cache"
+	"github.com/albinzx/cache/internal"
 )
 
 // Cacher is cache implementation using memory
+// it is unbounded unless WithMaxEntries or WithMaxBytes is set, then it evicts least recently used keys
 type Cacher struct {
-	cache *mem.Cache
-	ttl   time.Duration
+	store   store
+	clock   cache.Clock
+	ttl     time.Duration
+	cleanup time.Duration
+	expiry  *expiry
+	tags    *tagIndex
+	groups  *groupIndex
+	// counters serializes increments, which read value before set
+	counters sync.Mutex
+
+	maxEntries int
+	maxBytes   int64
+	shards     int
+	onEvict    func(key string, value any)
+	onEviction func(cache.Eviction)
+
+	represent  cache.Representation
+	marshaller cache.Marshaller
+	notFound   bool
+	metrics    cache.Metrics
+
+	slogger       *slog.Logger
+	logLevel      slog.Level
+	errorLogLevel slog.Level
+	logger        *internal.Logger
 }
 
 // defaults sets default cacher option
 func defaults(cacher *Cacher) {
-	if cacher.cache == nil {
-		if cacher.ttl > time.Second {
-			cacher.cache = mem.New(cacher.ttl, 10*time.Minute)
-		} else {
-			cacher.cache = mem.New(mem.NoExpiration, 10*time.Minute)
+	if cacher.cleanup <= 0 {
+		cacher.cleanup = 10 * time.Minute
+	}
+
+	// values of go-cache expire by system time, so cacher with clock stores values in bounded store reading it
+	bounded := cacher.maxEntries > 0 || cacher.maxBytes > 0 || cacher.clock != nil
+	if cacher.clock == nil {
+		cacher.clock = cache.SystemClock
+	}
+
+	cacher.expiry = &expiry{listeners: map[int]func(cache.Eviction){}, deleting: map[string]int{}}
+	cacher.tags = newTagIndex()
+	cacher.groups = newGroupIndex(cacher.clock.Now)
+	if cacher.onEviction != nil {
+		cacher.expiry.listen(cacher.onEviction)
+	}
+
+	expired := func(key string, value any) {
+		cacher.tags.untag(key)
+		cacher.expiry.evicted(key, cacher.decoded(value))
+	}
+	evicted := func(key string, value any) {
+		cacher.tags.untag(key)
+		value = cacher.decoded(value)
+		if cacher.onEvict != nil {
+			cacher.onEvict(key, value)
 		}
+		cacher.expiry.notify(cache.Eviction{Key: key, Value: value, Reason: cache.EvictionCapacity})
+	}
+
+	if bounded {
+		cacher.store = newBounded(cacher.shards, cacher.maxEntries, cacher.maxBytes, cacher.cleanup, cacher.clock, expired, evicted)
+	} else {
+		cacher.store = newUnbounded(cacher.ttl, cacher.cleanup, expired)
 	}
+
+	cacher.logger = internal.NewLogger(cacher.slogger, cacher.logLevel, cacher.errorLogLevel).With("backend", "memory")
 }
 
 // Option provides cacher options
 type Option func(*Cacher)
 
-// New returns new memory cacher
+// New returns new memory cacher, Close must be called when cacher is no longer used
+// to stop its background removal of expired keys
 func New(options ...Option) *Cacher {
-	mcache := &Cacher{}
+	mcache := configure(options)
 
-	for _, option := range options {
-		option(mcache)
+	defaults(mcache)
+
+	return mcache
+}
+
+// NewE returns new memory cacher, or error wrapping cache.ErrInvalidOption if options are invalid
+func NewE(options ...Option) (*Cacher, error) {
+	mcache := configure(options)
+
+	if err := mcache.validate(); err != nil {
+		return nil, err
 	}
 
 	defaults(mcache)
 
+	return mcache, nil
+}
+
+// configure returns cacher with options applied
+func configure(options []Option) *Cacher {
+	mcache := &Cacher{
+		logLevel:      slog.LevelDebug,
+		errorLogLevel: slog.LevelWarn,
+	}
+
+	for _, option := range options {
+		option(mcache)
+	}
+
 	return mcache
 }
 
-func (c *Cacher) Set(ctx context.Context, key string, value any, setOptions ...cache.SetOption) error {
+// validate returns error if options of cacher are invalid
+func (c *Cacher) validate() error {
+	if c.ttl < 0 {
+		return fmt.Errorf("%w: negative ttl %v", cache.ErrInvalidOption, c.ttl)
+	}
+
+	if c.cleanup < 0 {
+		return fmt.Errorf("%w: negative cleanup interval %v", cache.ErrInvalidOption, c.cleanup)
+	}
+
+	if c.maxEntries < 0 || c.maxBytes < 0 {
+		return fmt.Errorf("%w: negative bound of %v entries and %v bytes", cache.ErrInvalidOption, c.maxEntries, c.maxBytes)
+	}
+
+	if c.represent != cache.RepresentNative && c.marshaller != nil {
+		return fmt.Errorf("%w: %v representation can not be used with marshaller", cache.ErrInvalidOption, c.represent)
+	}
+
+	return nil
+}
+
+func (c *Cacher) Set(ctx context.Context, key string, value any, setOptions ...cache.SetOption) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "set", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpSet, start, value, err)
+	}(time.Now())
+
 	setConfig := &cache.SetConfiguration{
 		TTL: c.ttl}
 	for _, option := range setOptions {
 		option(setConfig)
 	}
 
-	c.cache.Set(key, value, setConfig.TTL)
+	value, err = c.encode(value)
+	if err != nil {
+		return err
+	}
+
+	if setConfig.Mode != cache.SetAlways || setConfig.KeepTTL {
+		stored, err := c.store.setIf(key, value, setConfig.TTL, c.cost(setConfig, value), setConfig.Mode, setConfig.KeepTTL)
+		if err != nil {
+			return err
+		}
+		if !stored {
+			return cache.ErrNotStored
+		}
+	} else if err := c.store.set(key, value, setConfig.TTL, c.cost(setConfig, value)); err != nil {
+		return err
+	}
+	c.tags.tag(key, setConfig.Tags)
 
 	return nil
 }
 
-func (c *Cacher) Get(ctx context.Context, key string) (any, error) {
-	if value, ok := c.cache.Get(key); ok {
-		return value, nil
+// encode returns value to store, marshalled if marshaller is set, otherwise in representation
+func (c *Cacher) encode(value any) (any, error) {
+	if c.marshaller != nil {
+		return c.marshaller.Marshal(value)
+	}
+
+	return c.represent.Convert(value)
+}
+
+// decode returns stored value, unmarshalled if marshaller is set, otherwise in representation
+func (c *Cacher) decode(value any) (any, error) {
+	if c.marshaller == nil {
+		return c.represent.Convert(value)
+	}
+
+	data, ok := value.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not marshalled", cache.ErrUnexpectedType, value)
+	}
+
+	return c.marshaller.Unmarshal(data)
+}
+
+// decoded returns stored value of eviction, unmarshalled if marshaller is set, or as stored if it fails
+func (c *Cacher) decoded(value any) any {
+	if c.marshaller == nil {
+		return value
+	}
+
+	if decoded, err := c.decode(value); err == nil {
+		return decoded
+	}
+
+	return value
+}
+
+// cost returns cost of value in bytes, it is only computed if bytes are bounded
+func (c *Cacher) cost(setConfig *cache.SetConfiguration, value any) int64 {
+	if c.maxBytes <= 0 {
+		return 0
+	}
+
+	return setConfig.CostOf(value)
+}
+
+func (c *Cacher) Get(ctx context.Context, key string) (value any, err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "get", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpGet, start, value, err)
+	}(time.Now())
+
+	if value, _, ok := c.store.get(key); ok {
+		return c.decode(value)
+	}
+
+	if c.notFound {
+		return nil, cache.ErrNotFound
 	}
 
 	return nil, nil
 }
 
-func (c *Cacher) Delete(ctx context.Context, key string) error {
-	c.cache.Delete((key))
+// GetMany gets values of keys from cache, returned map contains only keys which are found
+func (c *Cacher) GetMany(ctx context.Context, keys []string) (values map[string]any, err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "get_many", strings.Join(keys, ","), start, err)
+		cache.RecordMany(c.metrics, cache.OpGet, start, keys, values, err)
+	}(time.Now())
+
+	var errs []error
+	values = make(map[string]any, len(keys))
+	for _, key := range keys {
+		value, _, ok := c.store.get(key)
+		if !ok {
+			continue
+		}
+		value, err := c.decode(value)
+		if err != nil {
+			errs = append(errs, &cache.KeyError{Key: key, Err: err})
+			continue
+		}
+		values[key] = value
+	}
+
+	return values, errors.Join(errs...)
+}
+
+func (c *Cacher) Delete(ctx context.Context, key string) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "delete", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpDelete, start, nil, err)
+	}(time.Now())
+
+	c.expiry.delete(key, func() { c.store.delete(key) })
+	c.tags.untag(key)
 
 	return nil
 }
 
-func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
-	for key, val := range data {
-		c.cache.Set(key, val, c.ttl)
+// DeleteMany deletes values of keys from cache
+func (c *Cacher) DeleteMany(ctx context.Context, keys ...string) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "delete_many", strings.Join(keys, ","), start, err)
+		cache.RecordMany(c.metrics, cache.OpDelete, start, keys, nil, err)
+	}(time.Now())
+
+	for _, key := range keys {
+		c.expiry.delete(key, func() { c.store.delete(key) })
+		c.tags.untag(key)
 	}
 
 	return nil
 }
 
+func (c *Cacher) Load(ctx context.Context, data map[string]any) (err error) {
+	defer func(start time.Time) { cache.RecordOperation(c.metrics, cache.OpLoad, start, nil, err) }(time.Now())
+
+	var errs []error
+	for key, val := range data {
+		val, err := c.encode(val)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		if err := c.store.set(key, val, c.ttl, c.cost(&cache.SetConfiguration{}, val)); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		c.tags.untag(key)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close deletes all keys and stops background removal of expired keys, cacher must not be used after it
 func (c *Cacher) Close() error {
-	c.cache.Flush()
+	c.store.flush()
+	c.tags.reset()
+	c.groups.reset()
 	return nil
 }
 
@@ -86,3 +328,217 @@ func WithTTL(ttl time.Duration) Option {
 		cache.ttl = ttl
 	}
 }
+
+// WithRepresentation returns option to store byte and string values in representation,
+// default is native representation which keeps values as stored
+// with bytes or string representation, values of other types are rejected with cache.ErrUnexpectedType
+func WithRepresentation(represent cache.Representation) Option {
+	return func(cache *Cacher) {
+		cache.represent = represent
+	}
+}
+
+// WithMarshaller returns option to store values marshalled by marshaller, like redis cacher does,
+// so values returned by Get have types of unmarshalled values regardless of backend
+// and values are copied on set and get, it can not be used with bytes or string representation
+func WithMarshaller(marshaller cache.Marshaller) Option {
+	return func(cache *Cacher) {
+		cache.marshaller = marshaller
+	}
+}
+
+// WithCodec returns option to encode values with codec and decode them to type of prototype,
+// e.g. WithCodec(cache.JSONCodec{}, User{})
+func WithCodec(codec cache.Codec, prototype any) Option {
+	return WithMarshaller(cache.CodecMarshaller(codec, prototype))
+}
+
+// WithNotFoundError returns option to return cache.ErrNotFound instead of nil value on miss of Get,
+// so stored nil values can be told apart from missing keys
+func WithNotFoundError() Option {
+	return func(cache *Cacher) {
+		cache.notFound = true
+	}
+}
+
+// WithMetrics returns option to record results and latencies of operations to metrics, e.g. cache.Stats
+func WithMetrics(metrics cache.Metrics) Option {
+	return func(cache *Cacher) {
+		cache.metrics = metrics
+	}
+}
+
+// WithClock returns option to set clock values expire by, e.g. fake clock of tests, default is cache.SystemClock,
+// cacher with clock stores values like bounded cacher, without bounds unless they are set
+func WithClock(clock cache.Clock) Option {
+	return func(cache *Cacher) {
+		cache.clock = clock
+	}
+}
+
+// WithMaxEntries returns option to bound number of keys, least recently used keys are evicted
+// when it is exceeded, bound is split evenly among shards so keys are evicted per shard,
+// parts of shards add up to n
+func WithMaxEntries(n int) Option {
+	return func(cache *Cacher) {
+		cache.maxEntries = n
+	}
+}
+
+// WithMaxBytes returns option to bound bytes of keys and values, least recently used keys are evicted
+// when it is exceeded, values cost their length if they are []byte or string, their cost given by
+// cache.WithCost, or length of their gob encoding, bound is split evenly among shards
+// values which cost more than shard holds are rejected with ErrTooLarge
+func WithMaxBytes(n int64) Option {
+	return func(cache *Cacher) {
+		cache.maxBytes = n
+	}
+}
+
+// WithShards returns option to set number of independently locked shards of bounded cacher, default is 16
+func WithShards(n int) Option {
+	return func(cache *Cacher) {
+		cache.shards = n
+	}
+}
+
+// WithOnEvict returns option to call fn with keys and values evicted by bound of entries or bytes
+func WithOnEvict(fn func(key string, value any)) Option {
+	return func(cache *Cacher) {
+		cache.onEvict = fn
+	}
+}
+
+// WithOnEviction returns option to call fn with keys and values removed by expiration or by bound
+// of entries or bytes, with reason of removal, keys removed by delete are not reported
+func WithOnEviction(fn func(cache.Eviction)) Option {
+	return func(cache *Cacher) {
+		cache.onEviction = fn
+	}
+}
+
+// WithCleanupInterval returns option to set interval of removing expired values, default is 10 minutes
+func WithCleanupInterval(interval time.Duration) Option {
+	return func(cache *Cacher) {
+		cache.cleanup = interval
+	}
+}
+
+// WithLogger returns option to set structured logger
+// by default nothing is logged
+func WithLogger(logger *slog.Logger) Option {
+	return func(cache *Cacher) {
+		cache.slogger = logger
+	}
+}
+
+// WithLogLevel returns option to set log level of successful operations
+// and of failed operations, defaults are debug and warn level
+func WithLogLevel(level, errorLevel slog.Level) Option {
+	return func(cache *Cacher) {
+		cache.logLevel = level
+		cache.errorLogLevel = errorLevel
+	}
+}
+
+// Ping always succeeds as memory is always available
+func (c *Cacher) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Keys returns iterator of keys matching glob pattern
+// keys are snapshot when iterator is created
+func (c *Cacher) Keys(ctx context.Context, pattern string) cache.KeyIterator {
+	var keys []string
+	for _, key := range c.store.keys() {
+		if pattern == "" {
+			keys = append(keys, key)
+		} else if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return cache.NewSliceIterator(keys)
+}
+
+// Exists returns number of keys which exist, key given more times is counted every time
+func (c *Cacher) Exists(ctx context.Context, keys ...string) (int64, error) {
+	var count int64
+	for _, key := range keys {
+		if _, _, ok := c.store.get(key); ok {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// GetWithTTL retrieves value and its remaining time to live from cache
+func (c *Cacher) GetWithTTL(ctx context.Context, key string) (any, time.Duration, error) {
+	value, expiration, ok := c.store.get(key)
+	if !ok {
+		return nil, 0, nil
+	}
+
+	value, err := c.decode(value)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if expiration.IsZero() {
+		return value, 0, nil
+	}
+
+	return value, expiration.Sub(c.clock.Now()), nil
+}
+
+// Expire sets time to live of value, non-positive ttl deletes it, cache.ErrNotFound is returned if key does not exist
+func (c *Cacher) Expire(ctx context.Context, key string, ttl time.Duration) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "expire", key, start, err) }(time.Now())
+
+	if ttl <= 0 {
+		if _, _, ok := c.store.get(key); !ok {
+			return cache.ErrNotFound
+		}
+		return c.Delete(ctx, key)
+	}
+
+	if !c.store.expire(key, ttl) {
+		return cache.ErrNotFound
+	}
+
+	return nil
+}
+
+// Persist removes time to live of value so it never expires, cache.ErrNotFound is returned if key does not exist
+func (c *Cacher) Persist(ctx context.Context, key string) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "persist", key, start, err) }(time.Now())
+
+	if !c.store.expire(key, noExpiration) {
+		return cache.ErrNotFound
+	}
+
+	return nil
+}
+
+// DeleteByPrefix deletes keys starting with prefix and returns number of deleted keys
+func (c *Cacher) DeleteByPrefix(ctx context.Context, prefix string) (deleted int, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "delete_by_prefix", prefix, start, err) }(time.Now())
+
+	for _, key := range c.store.keys() {
+		if strings.HasPrefix(key, prefix) {
+			c.expiry.delete(key, func() { c.store.delete(key) })
+			c.tags.untag(key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// Flush deletes all keys
+func (c *Cacher) Flush(ctx context.Context) error {
+	_, err := c.DeleteByPrefix(ctx, "")
+	return err
+}
@@ -0,0 +1,163 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+)
+
+func TestConformance(t *testing.T) {
+	cachetest.Conformance(t, func(testing.TB) cache.Cacher { return New() })
+}
+
+func TestConformance_WithClock(t *testing.T) {
+	clock := cachetest.NewClock(time.Now())
+	cachetest.Conformance(t, func(testing.TB) cache.Cacher { return New(WithClock(clock)) }, cachetest.WithSleep(clock.Advance))
+}
+
+func TestWithClock(t *testing.T) {
+	ctx := context.Background()
+	clock := cachetest.NewClock(time.Now())
+	c := New(WithClock(clock), WithTTL(time.Minute))
+
+	_ = c.Set(ctx, "key", "value")
+	_ = c.AddToGroup(ctx, "group", "key", cache.WithTTL(time.Minute))
+
+	clock.Advance(59 * time.Second)
+	if _, ttl, _ := c.GetWithTTL(ctx, "key"); ttl != time.Second {
+		t.Errorf("GetWithTTL() ttl = %v, want %v", ttl, time.Second)
+	}
+
+	clock.Advance(2 * time.Second)
+	if value, _ := c.Get(ctx, "key"); value != nil {
+		t.Errorf("Get() = %v, want nil after ttl", value)
+	}
+	if members, _ := c.GroupMembers(ctx, "group"); members != nil {
+		t.Errorf("GroupMembers() = %v, want nil after ttl", members)
+	}
+}
+
+func BenchmarkCacher(b *testing.B) {
+	cachetest.Benchmark(b, func(testing.TB) cache.Cacher { return New() })
+}
+
+func TestWithMetrics(t *testing.T) {
+	ctx := context.Background()
+	stats := cache.NewStats("memory")
+	c := New(WithMetrics(stats))
+
+	_ = c.Set(ctx, "a", "one")
+	_, _ = c.Get(ctx, "a")
+	_, _ = c.Get(ctx, "b")
+	_ = c.Delete(ctx, "a")
+	_ = c.Load(ctx, map[string]any{"b": "two"})
+
+	snapshot := stats.Snapshot()
+	if snapshot.Sets != 1 || snapshot.Hits != 1 || snapshot.Misses != 1 || snapshot.Deletes != 1 || snapshot.Loads != 1 {
+		t.Errorf("snapshot = %+v", snapshot)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	c := New(WithLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+
+	// failed operation is logged with its error
+	_ = c.Expire(context.Background(), "missing", time.Minute)
+
+	for _, want := range []string{"op=expire", "error=" + strconv.Quote(cache.ErrNotFound.Error())} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log = %q, want %q", buf.String(), want)
+		}
+	}
+}
+
+func TestCacher_Backup(t *testing.T) {
+	ctx := context.Background()
+	src := New()
+	defer src.Close()
+	_ = src.Set(ctx, "a", "one", cache.WithTTL(time.Hour))
+	_ = src.Set(ctx, "b", []byte("two"))
+
+	buf := &bytes.Buffer{}
+	if written, err := src.Backup(ctx, buf); err != nil || written != 2 {
+		t.Fatalf("Backup() = %v, %v, want 2", written, err)
+	}
+
+	dst := New()
+	defer dst.Close()
+	if restored, err := dst.Restore(ctx, buf); err != nil || restored != 2 {
+		t.Fatalf("Restore() = %v, %v, want 2", restored, err)
+	}
+	if value, ttl, _ := dst.GetWithTTL(ctx, "a"); value != "one" || ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("GetWithTTL() = %v, %v, want one with remaining ttl", value, ttl)
+	}
+	if value, _ := dst.Get(ctx, "b"); !bytes.Equal(value.([]byte), []byte("two")) {
+		t.Errorf("Get() = %v, want two", value)
+	}
+}
+
+func TestWithMarshaller(t *testing.T) {
+	type user struct {
+		Name string
+	}
+
+	ctx := context.Background()
+	c := New(WithCodec(cache.JSONCodec{}, user{}))
+
+	stored := &user{Name: "alice"}
+	if err := c.Set(ctx, "user", stored); err != nil {
+		t.Fatal(err)
+	}
+	stored.Name = "bob"
+
+	value, err := c.Get(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := value.(user); !ok || got.Name != "alice" {
+		t.Errorf("Get() = %#v, want user alice decoded from stored copy", value)
+	}
+
+	values, err := c.GetMany(ctx, []string{"user", "missing"})
+	if err != nil || len(values) != 1 || values["user"].(user).Name != "alice" {
+		t.Errorf("GetMany() = %v, %v, want user alice", values, err)
+	}
+
+	if _, err := NewE(WithMarshaller(cache.CodecMarshaller(cache.JSONCodec{}, user{})), WithRepresentation(cache.RepresentBytes)); !errors.Is(err, cache.ErrInvalidOption) {
+		t.Errorf("NewE() error = %v, want %v", err, cache.ErrInvalidOption)
+	}
+}
+
+func TestCacher_Exists(t *testing.T) {
+	ctx := context.Background()
+	clock := cachetest.NewClock(time.Now())
+
+	for name, c := range map[string]*Cacher{"unbounded": New(WithClock(clock)), "bounded": New(WithClock(clock), WithMaxEntries(10), WithShards(1))} {
+		t.Run(name, func(t *testing.T) {
+			var _ cache.Exister = c
+
+			_ = c.Set(ctx, "a", "value")
+			_ = c.Set(ctx, "short", "value", cache.WithTTL(time.Second))
+			if got, _ := c.Exists(ctx, "a", "short", "a", "missing"); got != 3 {
+				t.Errorf("Exists() = %d, want 3", got)
+			}
+
+			clock.Advance(2 * time.Second)
+			if got, _ := c.Exists(ctx, "a", "short"); got != 1 {
+				t.Errorf("Exists() after expiration = %d, want 1", got)
+			}
+			if got, _ := c.Exists(ctx); got != 0 {
+				t.Errorf("Exists() of no keys = %d, want 0", got)
+			}
+		})
+	}
+}
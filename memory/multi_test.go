@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/albinzx/cache"
+)
+
+func TestNotFoundError(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithNotFoundError())
+
+	if _, err := c.Get(ctx, "missing"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	// stored nil value is told apart from missing key
+	if err := c.Set(ctx, "nil", nil); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if value, err := c.Get(ctx, "nil"); err != nil || value != nil {
+		t.Errorf("Get(nil) = %v, %v, want nil, nil", value, err)
+	}
+
+	if value, err := New().Get(ctx, "missing"); err != nil || value != nil {
+		t.Errorf("Get(missing) without option = %v, %v, want nil, nil", value, err)
+	}
+}
+
+func TestGetManyDeleteMany(t *testing.T) {
+	ctx := context.Background()
+	c := New(WithNotFoundError())
+	_ = c.Load(ctx, map[string]any{"a": 1, "b": 2})
+
+	values, err := c.GetMany(ctx, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	if len(values) != 2 || values["a"] != 1 || values["b"] != 2 {
+		t.Errorf("GetMany() = %v, want a and b", values)
+	}
+
+	if err := c.DeleteMany(ctx, "a", "c"); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	if _, err := c.Get(ctx, "a"); !errors.Is(err, cache.ErrNotFound) {
+		t.Errorf("Get(a) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetManyDeleteMany_Metrics(t *testing.T) {
+	ctx := context.Background()
+	stats := cache.NewStats("memory")
+	c := New(WithMetrics(stats))
+	_ = c.Load(ctx, map[string]any{"a": 1, "b": 2})
+
+	_, _ = c.GetMany(ctx, []string{"a", "b", "c"})
+	_ = c.DeleteMany(ctx, "a", "c")
+
+	snapshot := stats.Snapshot()
+	if snapshot.Hits != 2 || snapshot.Misses != 1 || snapshot.Deletes != 2 {
+		t.Errorf("snapshot = %+v, want 2 hits, 1 miss and 2 deletes", snapshot)
+	}
+}
@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCacher_DeleteByPrefix(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+	_ = c.Load(ctx, map[string]any{"orders.1": 1, "orders.2": 2, "users.1": "one"})
+
+	deleted, err := c.DeleteByPrefix(ctx, "orders.")
+	if err != nil || deleted != 2 {
+		t.Fatalf("DeleteByPrefix() = %v, %v, want %v", deleted, err, 2)
+	}
+	if got, _ := c.Get(ctx, "users.1"); got != "one" {
+		t.Errorf("Get() = %v, want %v", got, "one")
+	}
+
+	_ = c.Flush(ctx)
+	if got, _ := c.Get(ctx, "users.1"); got != nil {
+		t.Errorf("Get() after Flush() = %v, want nil", got)
+	}
+}
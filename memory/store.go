@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+	mem "github.com/patrickmn/go-cache"
+)
+
+// store stores values of memory cacher
+type store interface {
+	// set stores value of key with cost in bytes, expiring after ttl, 0 ttl is default expiration of store
+	set(key string, value any, ttl time.Duration, cost int64) error
+	// setIf stores value of key if condition of mode is met and reports whether it is stored,
+	// remaining ttl of existing key is kept if keepTTL is set
+	setIf(key string, value any, ttl time.Duration, cost int64, mode cache.SetMode, keepTTL bool) (bool, error)
+	// expire sets ttl of existing key and reports whether key exists, negative ttl is no expiration
+	expire(key string, ttl time.Duration) bool
+	// get returns value of key and its expiration, zero expiration means value does not expire
+	get(key string) (any, time.Time, bool)
+	// delete deletes key
+	delete(key string)
+	// keys returns keys which are not expired
+	keys() []string
+	// flush deletes all keys
+	flush()
+}
+
+// noExpiration is ttl of values which never expire
+const noExpiration = mem.NoExpiration
+
+// remaining returns ttl at now of value expiring at expiration, which is no expiration for zero expiration
+func remaining(expiration, now time.Time) time.Duration {
+	if expiration.IsZero() {
+		return noExpiration
+	}
+	if ttl := expiration.Sub(now); ttl > 0 {
+		return ttl
+	}
+
+	return time.Nanosecond
+}
+
+// unbounded is store of go-cache without size bound
+type unbounded struct {
+	cache *mem.Cache
+	// mu serializes sets keeping ttl and expires, which read value or expiration before set
+	mu sync.Mutex
+}
+
+// newUnbounded returns go-cache store with default expiration and cleanup interval,
+// expired is called with keys removed by expiration and by delete
+func newUnbounded(ttl, cleanup time.Duration, expired func(string, any)) *unbounded {
+	var c *mem.Cache
+	if ttl > time.Second {
+		c = mem.New(ttl, cleanup)
+	} else {
+		c = mem.New(mem.NoExpiration, cleanup)
+	}
+	c.OnEvicted(expired)
+
+	return &unbounded{cache: c}
+}
+
+func (u *unbounded) set(key string, value any, ttl time.Duration, _ int64) error {
+	u.cache.Set(key, value, ttl)
+	return nil
+}
+
+func (u *unbounded) setIf(key string, value any, ttl time.Duration, _ int64, mode cache.SetMode, keepTTL bool) (bool, error) {
+	if !keepTTL || mode == cache.SetIfNotExists {
+		switch mode {
+		case cache.SetIfNotExists:
+			return u.cache.Add(key, value, ttl) == nil, nil
+		case cache.SetIfExists:
+			return u.cache.Replace(key, value, ttl) == nil, nil
+		default:
+			u.cache.Set(key, value, ttl)
+			return true, nil
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	_, expiration, exists := u.cache.GetWithExpiration(key)
+	if mode == cache.SetIfExists && !exists {
+		return false, nil
+	}
+	if exists {
+		ttl = remaining(expiration, time.Now())
+	}
+	u.cache.Set(key, value, ttl)
+
+	return true, nil
+}
+
+func (u *unbounded) expire(key string, ttl time.Duration) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	value, ok := u.cache.Get(key)
+	if !ok {
+		return false
+	}
+	u.cache.Set(key, value, ttl)
+
+	return true
+}
+
+func (u *unbounded) get(key string) (any, time.Time, bool) {
+	return u.cache.GetWithExpiration(key)
+}
+
+func (u *unbounded) delete(key string) {
+	u.cache.Delete(key)
+}
+
+func (u *unbounded) keys() []string {
+	items := u.cache.Items()
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func (u *unbounded) flush() {
+	u.cache.Flush()
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+// tagIndex indexes keys by tags they are set with
+type tagIndex struct {
+	mu   sync.Mutex
+	keys map[string]map[string]struct{}
+	tags map[string][]string
+}
+
+// newTagIndex returns empty tag index
+func newTagIndex() *tagIndex {
+	return &tagIndex{keys: map[string]map[string]struct{}{}, tags: map[string][]string{}}
+}
+
+// tag replaces tags of key with tags
+func (t *tagIndex) tag(key string, tags []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.remove(key)
+	if len(tags) == 0 {
+		return
+	}
+
+	for _, tag := range tags {
+		keys, ok := t.keys[tag]
+		if !ok {
+			keys = map[string]struct{}{}
+			t.keys[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	t.tags[key] = append([]string(nil), tags...)
+}
+
+// untag removes key from index, e.g. when it is deleted or expired
+func (t *tagIndex) untag(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.remove(key)
+}
+
+// remove removes key from tags it is set with, index must be locked
+func (t *tagIndex) remove(key string) {
+	for _, tag := range t.tags[key] {
+		delete(t.keys[tag], key)
+		if len(t.keys[tag]) == 0 {
+			delete(t.keys, tag)
+		}
+	}
+	delete(t.tags, key)
+}
+
+// reset removes all keys from index
+func (t *tagIndex) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.keys = map[string]map[string]struct{}{}
+	t.tags = map[string][]string{}
+}
+
+// tagged returns keys set with any of tags
+func (t *tagIndex) tagged(tags []string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := map[string]struct{}{}
+	var keys []string
+	for _, tag := range tags {
+		for key := range t.keys[tag] {
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	return keys
+}
+
+// InvalidateTags deletes values set with any of tags
+func (c *Cacher) InvalidateTags(ctx context.Context, tags ...string) error {
+	return c.DeleteMany(ctx, c.tags.tagged(tags)...)
+}
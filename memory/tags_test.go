@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/albinzx/cache"
+)
+
+func TestCacher_InvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	c := New()
+
+	_ = c.Set(ctx, "a", "one", cache.WithTags("user:1"))
+	_ = c.Set(ctx, "b", "two", cache.WithTags("user:1", "category:x"))
+	_ = c.Set(ctx, "c", "three", cache.WithTags("category:x"))
+	_ = c.Set(ctx, "d", "four", cache.WithTags("user:2"))
+	// set without tags removes key from its former tags
+	_ = c.Set(ctx, "d", "four")
+
+	if err := c.InvalidateTags(ctx, "user:1", "user:2"); err != nil {
+		t.Fatalf("InvalidateTags() error = %v", err)
+	}
+
+	for key, want := range map[string]any{"a": nil, "b": nil, "c": "three", "d": "four"} {
+		if got, _ := c.Get(ctx, key); got != want {
+			t.Errorf("Get(%v) = %v, want %v", key, got, want)
+		}
+	}
+	if got := c.tags.tagged([]string{"category:x"}); len(got) != 1 || got[0] != "c" {
+		t.Errorf("tagged = %v, want [c]", got)
+	}
+}
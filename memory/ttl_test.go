@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+func TestCacher_Expire(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range map[string]*Cacher{"unbounded": New(), "bounded": New(WithMaxEntries(10))} {
+		t.Run(name, func(t *testing.T) {
+			_ = c.Set(ctx, "key", "value")
+
+			if err := cache.Expire(ctx, c, "key", time.Hour); err != nil {
+				t.Fatalf("Expire() error = %v", err)
+			}
+			if value, ttl, _ := c.GetWithTTL(ctx, "key"); value != "value" || ttl <= 0 || ttl > time.Hour {
+				t.Errorf("GetWithTTL() = %v, %v, want value expiring in an hour", value, ttl)
+			}
+
+			if err := cache.Persist(ctx, c, "key"); err != nil {
+				t.Fatalf("Persist() error = %v", err)
+			}
+			if _, ttl, _ := c.GetWithTTL(ctx, "key"); ttl != 0 {
+				t.Errorf("GetWithTTL() ttl = %v, want no expiration", ttl)
+			}
+
+			if err := c.Expire(ctx, "key", 0); err != nil {
+				t.Fatalf("Expire() error = %v", err)
+			}
+			if err := c.Expire(ctx, "key", time.Hour); !errors.Is(err, cache.ErrNotFound) {
+				t.Errorf("Expire() of deleted key error = %v, want %v", err, cache.ErrNotFound)
+			}
+			if err := c.Persist(ctx, "key"); !errors.Is(err, cache.ErrNotFound) {
+				t.Errorf("Persist() of deleted key error = %v, want %v", err, cache.ErrNotFound)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// Operation is name of cache operation
+type Operation string
+
+const (
+	// OpSet is set operation
+	OpSet Operation = "set"
+	// OpGet is get operation
+	OpGet Operation = "get"
+	// OpDelete is delete operation
+	OpDelete Operation = "delete"
+	// OpLoad is load operation
+	OpLoad Operation = "load"
+	// OpSave is persister save operation
+	OpSave Operation = "save"
+	// OpSelectOne is persister select one operation
+	OpSelectOne Operation = "select_one"
+	// OpSelectAll is persister select all operation
+	OpSelectAll Operation = "select_all"
+)
+
+// Result is outcome of cache operation
+type Result string
+
+const (
+	// ResultOK is result of successful set, delete or load
+	ResultOK Result = "ok"
+	// ResultHit is result of get which finds value
+	ResultHit Result = "hit"
+	// ResultMiss is result of get which finds no value
+	ResultMiss Result = "miss"
+	// ResultError is result of failed operation
+	ResultError Result = "error"
+)
+
+// resultOf returns result of operation with value and error
+func resultOf(op Operation, value any, err error) Result {
+	switch {
+	case op == OpGet && errors.Is(err, ErrNotFound):
+		return ResultMiss
+	case err != nil:
+		return ResultError
+	case op != OpGet:
+		return ResultOK
+	case value == nil:
+		return ResultMiss
+	default:
+		return ResultHit
+	}
+}
+
+// Metrics records measurements of cache operations
+type Metrics interface {
+	// Observe records result and duration of operation
+	Observe(op Operation, result Result, duration time.Duration)
+}
+
+// SizeMetrics is implemented by metrics which record value sizes
+type SizeMetrics interface {
+	// ObserveSize records size in bytes of value set or got by operation
+	ObserveSize(op Operation, size int)
+}
+
+// RecordOperation records result and duration since start of operation returning value and err to metrics,
+// and size of value if metrics record sizes, nil metrics records nothing
+// it lets backends and wrappers outside this package record metrics like Instrument
+func RecordOperation(metrics Metrics, op Operation, start time.Time, value any, err error) {
+	recordResult(metrics, op, resultOf(op, value, err), start, value, err)
+}
+
+// RecordMany records operation of multiple keys since start like RecordOperation once per key,
+// values are values of keys got by operation and err fails keys of its KeyError, or all keys if it has none
+func RecordMany(metrics Metrics, op Operation, start time.Time, keys []string, values map[string]any, err error) {
+	if metrics == nil {
+		return
+	}
+
+	failed := KeyErrors(err, keys)
+	for _, key := range keys {
+		RecordOperation(metrics, op, start, values[key], failedErr(failed, key, err))
+	}
+}
+
+// recordGet records get of key returning value and err like RecordOperation,
+// value loaded from persistence storage is recorded as miss of cache
+func recordGet(metrics Metrics, l *loads, key string, start time.Time, value any, err error) {
+	result := resultOf(OpGet, value, err)
+	if result == ResultHit && l.loaded(key) {
+		result = ResultMiss
+	}
+
+	recordResult(metrics, OpGet, result, start, value, err)
+}
+
+// recordResult records result and duration since start of operation and size of value
+func recordResult(metrics Metrics, op Operation, result Result, start time.Time, value any, err error) {
+	if metrics == nil {
+		return
+	}
+
+	metrics.Observe(op, result, time.Since(start))
+
+	if sizes, ok := metrics.(SizeMetrics); ok && err == nil && value != nil && op != OpDelete {
+		if size, ok := sizeOf(value); ok {
+			sizes.ObserveSize(op, size)
+		}
+	}
+}
+
+// sizeOf returns size in bytes of serialized value
+// only sizes of []byte and string values are known
+func sizeOf(value any) (int, bool) {
+	switch v := value.(type) {
+	case []byte:
+		return len(v), true
+	case string:
+		return len(v), true
+	default:
+		return 0, false
+	}
+}
+
+var (
+	// latencyBounds are latency histogram bounds in nanoseconds, from 1µs to about 67s
+	latencyBounds = internal.ExponentialBounds(int64(time.Microsecond), 2, 27)
+	// sizeBounds are value size histogram bounds in bytes, from 16B to 64MB
+	sizeBounds = internal.ExponentialBounds(16, 2, 23)
+)
+
+// operations are operations with histograms in Stats
+var operations = []Operation{OpSet, OpGet, OpDelete, OpLoad}
+
+// Stats is Metrics implementation counting operation results
+// and recording latency and value size distributions in memory
+type Stats struct {
+	name    string
+	hits    atomic.Int64
+	misses  atomic.Int64
+	sets    atomic.Int64
+	deletes atomic.Int64
+	loads   atomic.Int64
+	errors  atomic.Int64
+	opens   atomic.Int64
+
+	once    sync.Once
+	latency map[Operation]*internal.Histogram
+	size    map[Operation]*internal.Histogram
+}
+
+// NewStats returns stats of cache with name
+func NewStats(name string) *Stats {
+	return &Stats{name: name}
+}
+
+// Name returns name of cache
+func (s *Stats) Name() string {
+	return s.name
+}
+
+// histograms returns latency and size histograms, creating them on first use
+func (s *Stats) histograms() (map[Operation]*internal.Histogram, map[Operation]*internal.Histogram) {
+	s.once.Do(func() {
+		s.latency = make(map[Operation]*internal.Histogram, len(operations))
+		s.size = make(map[Operation]*internal.Histogram, len(operations))
+		for _, op := range operations {
+			s.latency[op] = internal.NewHistogram(latencyBounds)
+			s.size[op] = internal.NewHistogram(sizeBounds)
+		}
+	})
+
+	return s.latency, s.size
+}
+
+// StatsSnapshot is point in time copy of Stats
+type StatsSnapshot struct {
+	Name    string `json:"name,omitempty"`
+	Hits    int64  `json:"hits"`
+	Misses  int64  `json:"misses"`
+	Sets    int64  `json:"sets"`
+	Deletes int64  `json:"deletes"`
+	Loads   int64  `json:"loads"`
+	Errors  int64  `json:"errors"`
+	// BreakerOpens is number of times circuit breaker opened
+	BreakerOpens int64 `json:"breaker_opens,omitempty"`
+	// Latency is latency distribution in nanoseconds per operation
+	Latency map[Operation]Distribution `json:"latency,omitempty"`
+	// Size is value size distribution in bytes per operation
+	Size map[Operation]Distribution `json:"size,omitempty"`
+}
+
+// Distribution summarizes observed values
+type Distribution struct {
+	Count int64 `json:"count"`
+	Sum   int64 `json:"sum"`
+	P50   int64 `json:"p50"`
+	P95   int64 `json:"p95"`
+	P99   int64 `json:"p99"`
+	// Buckets are cumulative counts of observations, e.g. for prometheus histograms
+	Buckets []Bucket `json:"-"`
+}
+
+// Bucket is number of observations less than or equal to upper bound
+type Bucket struct {
+	UpperBound int64
+	Count      int64
+}
+
+// distributionOf returns distribution of histogram
+func distributionOf(h *internal.Histogram) Distribution {
+	bounds, counts := h.Buckets()
+	buckets := make([]Bucket, len(bounds))
+	for i := range bounds {
+		buckets[i] = Bucket{UpperBound: bounds[i], Count: counts[i]}
+	}
+
+	return Distribution{
+		Count:   h.Count(),
+		Sum:     h.Sum(),
+		P50:     h.Quantile(0.50),
+		P95:     h.Quantile(0.95),
+		P99:     h.Quantile(0.99),
+		Buckets: buckets,
+	}
+}
+
+// Observe counts result of operation and records its latency
+func (s *Stats) Observe(op Operation, result Result, duration time.Duration) {
+	switch {
+	case result == ResultError:
+		s.errors.Add(1)
+	case result == ResultHit:
+		s.hits.Add(1)
+	case result == ResultMiss:
+		s.misses.Add(1)
+	case op == OpSet:
+		s.sets.Add(1)
+	case op == OpDelete:
+		s.deletes.Add(1)
+	case op == OpLoad:
+		s.loads.Add(1)
+	}
+
+	latency, _ := s.histograms()
+	if h, ok := latency[op]; ok {
+		h.Observe(int64(duration))
+	}
+}
+
+// ObserveSize records value size of operation
+func (s *Stats) ObserveSize(op Operation, size int) {
+	_, sizes := s.histograms()
+	if h, ok := sizes[op]; ok {
+		h.Observe(int64(size))
+	}
+}
+
+// ObserveBreakerState counts openings of circuit breaker
+func (s *Stats) ObserveBreakerState(state BreakerState) {
+	if state == BreakerOpen {
+		s.opens.Add(1)
+	}
+}
+
+// Snapshot returns current counters and distributions
+func (s *Stats) Snapshot() StatsSnapshot {
+	snapshot := StatsSnapshot{
+		Name:         s.name,
+		Hits:         s.hits.Load(),
+		Misses:       s.misses.Load(),
+		Sets:         s.sets.Load(),
+		Deletes:      s.deletes.Load(),
+		Loads:        s.loads.Load(),
+		Errors:       s.errors.Load(),
+		BreakerOpens: s.opens.Load(),
+		Latency:      make(map[Operation]Distribution),
+		Size:         make(map[Operation]Distribution),
+	}
+
+	latency, sizes := s.histograms()
+	for _, op := range operations {
+		if h := latency[op]; h.Count() > 0 {
+			snapshot.Latency[op] = distributionOf(h)
+		}
+		if h := sizes[op]; h.Count() > 0 {
+			snapshot.Size[op] = distributionOf(h)
+		}
+	}
+
+	return snapshot
+}
+
+// HitRatio returns ratio of hits to all gets, or zero if there is no get
+func (s StatsSnapshot) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+
+	return 0
+}
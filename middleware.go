@@ -0,0 +1,72 @@
+package cache
+
+import "context"
+
+// Call is operation of patterned cache passed through middleware
+type Call struct {
+	// Op is OpSet, OpGet or OpDelete
+	Op  Operation
+	Key string
+	// Value is value of set, nil for other operations
+	Value any
+	// Options are options of set
+	Options []SetOption
+}
+
+// Handler executes call and returns value of get
+type Handler func(ctx context.Context, call *Call) (any, error)
+
+// Middleware wraps handler with cross-cutting behavior, e.g. key validation or tenant scoping,
+// it may change call before passing it to next, or return without calling next
+type Middleware func(next Handler) Handler
+
+// WithMiddleware returns option to run sets, gets and deletes of patterned cache through middlewares,
+// the first middleware is the outermost, the innermost calls pattern
+// middlewares run inside logging, metrics and tracing of patterned cache, so they see failures they cause,
+// batch operations run through middlewares once per key unless pattern implements BatchPattern
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *PatternedCache) {
+		c.middlewares = append(c.middlewares, middlewares...)
+	}
+}
+
+// Hooks returns middleware calling before ahead of every call and after with its result,
+// error returned by before fails call without running it, nil hooks are skipped
+func Hooks(before func(ctx context.Context, call *Call) error, after func(ctx context.Context, call *Call, value any, err error)) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (any, error) {
+			if before != nil {
+				if err := before(ctx, call); err != nil {
+					return nil, err
+				}
+			}
+
+			value, err := next(ctx, call)
+			if after != nil {
+				after(ctx, call, value, err)
+			}
+
+			return value, err
+		}
+	}
+}
+
+// handler returns handler of calls running through middlewares to pattern
+func (c *PatternedCache) handler() Handler {
+	h := func(ctx context.Context, call *Call) (any, error) {
+		switch call.Op {
+		case OpSet:
+			return nil, c.pattern.Set(ctx, call.Key, call.Value, c.cacher, c.persister, call.Options...)
+		case OpDelete:
+			return nil, c.pattern.Delete(ctx, call.Key, c.cacher, c.persister)
+		default:
+			return c.pattern.Get(ctx, call.Key, c.cacher, c.persister)
+		}
+	}
+
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+
+	return h
+}
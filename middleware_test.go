@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	ctx := context.Background()
+	errInvalidKey := errors.New("invalid key")
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) (any, error) {
+				calls = append(calls, name+":"+string(call.Op))
+				return next(ctx, call)
+			}
+		}
+	}
+	validate := Hooks(func(_ context.Context, call *Call) error {
+		if strings.Contains(call.Key, " ") {
+			return errInvalidKey
+		}
+		call.Key = "tenant." + call.Key
+		return nil
+	}, nil)
+
+	cacher := newMapCacher()
+	c, _ := New(cacher, nil, WithMiddleware(trace("outer"), trace("inner"), validate))
+
+	_ = c.Set(ctx, "key", "value")
+	if got, _ := cacher.Get(ctx, "tenant.key"); got != "value" {
+		t.Errorf("cached value = %v, want %v", got, "value")
+	}
+	if got, _ := c.Get(ctx, "key"); got != "value" {
+		t.Errorf("Get() = %v, want %v", got, "value")
+	}
+	if err := c.Delete(ctx, "bad key"); !errors.Is(err, errInvalidKey) {
+		t.Errorf("Delete() error = %v, want %v", err, errInvalidKey)
+	}
+
+	want := []string{"outer:set", "inner:set", "outer:get", "inner:get", "outer:delete", "inner:delete"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
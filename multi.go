@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+// MultiCacher is implemented by cachers which get and delete multiple keys in one round trip, e.g. redis
+// and memory cachers, cachers which do not implement it are called once per key by GetMany and DeleteMany
+//
+// multi-key operations are optional interface rather than methods of Cacher, so existing cachers and
+// wrappers outside this package keep implementing Cacher, PatternedCache.GetMany and DeleteMany
+// use them when cacher implements them
+type MultiCacher interface {
+	// GetMany gets values of keys from cache, returned map contains only keys which are found
+	GetMany(ctx context.Context, keys []string) (map[string]any, error)
+	// DeleteMany deletes values of keys from cache
+	DeleteMany(ctx context.Context, keys ...string) error
+}
+
+// GetMany gets values of keys from c at once if it implements MultiCacher, otherwise one key at a time
+// returned map contains only keys which are found, keys which fail to be retrieved are missing
+// and returned error joins their KeyError, values read before error of MultiCacher are returned with it
+func GetMany(ctx context.Context, c Cacher, keys []string) (map[string]any, error) {
+	if len(keys) == 0 {
+		return map[string]any{}, nil
+	}
+
+	if multi, ok := c.(MultiCacher); ok {
+		values, err := multi.GetMany(ctx, keys)
+		if errors.Is(err, ErrNotFound) && !isKeyErrors(err) {
+			err = nil
+		}
+		if values == nil {
+			values = map[string]any{}
+		}
+		return values, err
+	}
+
+	var errs []error
+	values := make(map[string]any, len(keys))
+	for _, key := range keys {
+		value, err := c.Get(ctx, key)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, keyError(key, err))
+			continue
+		}
+		if value != nil {
+			values[key] = value
+		}
+	}
+
+	return values, errors.Join(errs...)
+}
+
+// DeleteMany deletes values of keys from c at once if it implements MultiCacher, otherwise one key at a time
+// returned error joins KeyError of keys which fail to be deleted one at a time
+func DeleteMany(ctx context.Context, c Cacher, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if multi, ok := c.(MultiCacher); ok {
+		return multi.DeleteMany(ctx, keys...)
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := c.Delete(ctx, key); err != nil {
+			errs = append(errs, keyError(key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// getFound gets value of key from c, ErrNotFound of cachers reporting misses with it is nil value
+func getFound(ctx context.Context, c Cacher, key string) (any, error) {
+	value, err := c.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	return value, err
+}
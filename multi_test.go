@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// notFoundCacher reports misses with ErrNotFound and counts calls of multi-key operations
+type notFoundCacher struct {
+	*mapCacher
+	getMany    int
+	deleteMany int
+}
+
+func (n *notFoundCacher) Get(ctx context.Context, key string) (any, error) {
+	value, err := n.mapCacher.Get(ctx, key)
+	if err == nil && value == nil {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+func (n *notFoundCacher) GetMany(ctx context.Context, keys []string) (map[string]any, error) {
+	n.getMany++
+	values := map[string]any{}
+	for _, key := range keys {
+		if value, _ := n.mapCacher.Get(ctx, key); value != nil {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func (n *notFoundCacher) DeleteMany(ctx context.Context, keys ...string) error {
+	n.deleteMany++
+	for _, key := range keys {
+		_ = n.mapCacher.Delete(ctx, key)
+	}
+	return nil
+}
+
+func TestGetManyFallback(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCacher()
+	c.data["a"] = "one"
+
+	values, err := GetMany(ctx, c, []string{"a", "b"})
+	if err != nil || len(values) != 1 || values["a"] != "one" {
+		t.Errorf("GetMany() = %v, %v, want only a", values, err)
+	}
+
+	c.getErr = errors.New("down")
+	_, err = GetMany(ctx, c, []string{"a"})
+	if errs := KeyErrors(err, []string{"a"}); errs["a"] == nil {
+		t.Errorf("GetMany() error = %v, want KeyError of a", err)
+	}
+
+	c.getErr = nil
+	if err := DeleteMany(ctx, c, "a", "b"); err != nil || len(c.data) != 0 {
+		t.Errorf("DeleteMany() = %v, data %v", err, c.data)
+	}
+}
+
+func TestPatternedCacheMultiCacher(t *testing.T) {
+	ctx := context.Background()
+	c := &notFoundCacher{mapCacher: newMapCacher()}
+	p := newMapPersister()
+	p.data["b"] = "two"
+
+	pc, err := New(c, p, WithPattern(&ReadThrough{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.data["a"] = "one"
+
+	values, err := pc.GetMany(ctx, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	if len(values) != 2 || values["a"] != "one" || values["b"] != "two" {
+		t.Errorf("GetMany() = %v, want a and b", values)
+	}
+	if c.getMany != 1 {
+		t.Errorf("cacher GetMany called %d times, want 1", c.getMany)
+	}
+
+	// ErrNotFound of cacher is a miss, read through from persistence storage
+	if value, err := pc.Get(ctx, "b"); err != nil || value != "two" {
+		t.Errorf("Get(b) = %v, %v, want two", value, err)
+	}
+	if value, err := pc.Get(ctx, "c"); err != nil || value != nil {
+		t.Errorf("Get(c) = %v, %v, want nil, nil", value, err)
+	}
+
+	if err := pc.DeleteMany(ctx, "a", "b"); err != nil {
+		t.Fatalf("DeleteMany() error = %v", err)
+	}
+	if c.deleteMany != 1 {
+		t.Errorf("cacher DeleteMany called %d times, want 1", c.deleteMany)
+	}
+}
+
+func TestWithNotFoundError(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCacher()
+	c.data["a"] = "one"
+
+	pc, err := New(c, nil, WithNotFoundError())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := pc.Get(ctx, "a"); err != nil || value != "one" {
+		t.Errorf("Get(a) = %v, %v, want one", value, err)
+	}
+	if _, err := pc.Get(ctx, "b"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(b) error = %v, want ErrNotFound", err)
+	}
+}
+
+// failingMultiCacher gets some keys at once and fails others
+type failingMultiCacher struct {
+	*notFoundCacher
+}
+
+func (f *failingMultiCacher) GetMany(ctx context.Context, keys []string) (map[string]any, error) {
+	return map[string]any{"a": "one"}, errors.Join(&KeyError{Key: "b", Err: errors.New("down")})
+}
+
+func TestGetMany_Partial(t *testing.T) {
+	c := &failingMultiCacher{&notFoundCacher{mapCacher: newMapCacher()}}
+
+	values, err := GetMany(context.Background(), c, []string{"a", "b"})
+	if len(values) != 1 || values["a"] != "one" {
+		t.Errorf("GetMany() = %v, want a", values)
+	}
+	if errs := KeyErrors(err, []string{"a", "b"}); len(errs) != 1 || errs["b"] == nil {
+		t.Errorf("GetMany() error = %v, want KeyError of b", err)
+	}
+}
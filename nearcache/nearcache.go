@@ -0,0 +1,194 @@
+// Package nearcache composes small bounded in-process cache in front of redis,
+// kept coherent across nodes by invalidation messages
+package nearcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/invalidation"
+	"github.com/albinzx/cache/memory"
+	"github.com/albinzx/cache/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Cacher is redis cacher with near cache of recently read values
+//
+// reads are served from near cache and fall back to redis, values read from redis are kept near
+// without broadcasting, writes go to redis first and then to near cache and are broadcast
+// so other nodes evict their near copies, a value read concurrently with a write of another node
+// may stay stale in near cache until its local ttl passes
+type Cacher struct {
+	near   *invalidation.Cacher
+	remote cache.Cacher
+
+	name          string
+	capacity      int
+	localTTL      time.Duration
+	remoteTTL     time.Duration
+	channel       string
+	bus           invalidation.Bus
+	redisOptions  []redis.Option
+	invalidations []invalidation.Option
+}
+
+// Option provides near cache options
+type Option func(*Cacher)
+
+// WithName returns option to set name of cache used as redis key prefix and in default channel name
+func WithName(name string) Option {
+	return func(c *Cacher) {
+		c.name = name
+	}
+}
+
+// WithCapacity returns option to set maximum number of values in near cache, default is 10000,
+// oldest values are evicted when near cache is full
+func WithCapacity(capacity int) Option {
+	return func(c *Cacher) {
+		c.capacity = capacity
+	}
+}
+
+// WithLocalTTL returns option to set time to live of values in near cache, default is 1 minute,
+// it bounds how long a missed invalidation can serve stale value
+func WithLocalTTL(ttl time.Duration) Option {
+	return func(c *Cacher) {
+		c.localTTL = ttl
+	}
+}
+
+// WithTTL returns option to set global TTL of values in redis
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cacher) {
+		c.remoteTTL = ttl
+	}
+}
+
+// WithChannel returns option to set redis channel of invalidation messages, default is "nearcache:" followed by name
+func WithChannel(channel string) Option {
+	return func(c *Cacher) {
+		c.channel = channel
+	}
+}
+
+// WithBus returns option to broadcast invalidations over bus instead of redis channel
+func WithBus(bus invalidation.Bus) Option {
+	return func(c *Cacher) {
+		c.bus = bus
+	}
+}
+
+// WithRedisOptions returns option to add options of redis cacher, e.g. redis.WithMarshaller
+func WithRedisOptions(options ...redis.Option) Option {
+	return func(c *Cacher) {
+		c.redisOptions = append(c.redisOptions, options...)
+	}
+}
+
+// WithInvalidationOptions returns option to add options of invalidation, e.g. invalidation.WithErrorHandler
+func WithInvalidationOptions(options ...invalidation.Option) Option {
+	return func(c *Cacher) {
+		c.invalidations = append(c.invalidations, options...)
+	}
+}
+
+// New returns near cache in front of redis of client, client is not closed by Close
+func New(ctx context.Context, client goredis.UniversalClient, options ...Option) (*Cacher, error) {
+	c := &Cacher{capacity: 10000, localTTL: time.Minute}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.channel == "" {
+		c.channel = "nearcache:" + c.name
+	}
+	if c.bus == nil {
+		c.bus = invalidation.NewRedis(client, c.channel)
+	}
+
+	c.remote = redis.New(append([]redis.Option{
+		redis.WithSharedRedisClient(client, false),
+		redis.WithName(c.name),
+		redis.WithTTL(c.remoteTTL),
+	}, c.redisOptions...)...)
+
+	local := cache.Quotas(memory.New(memory.WithTTL(c.localTTL)),
+		cache.WithDefaultQuota(cache.Quota{MaxKeys: c.capacity, Policy: cache.BreachEvictOldest}),
+		cache.WithNamespaceFunc(func(string) string { return "" }),
+	)
+
+	near, err := invalidation.New(ctx, local, c.bus, c.invalidations...)
+	if err != nil {
+		return nil, errors.Join(err, local.Close(), c.remote.Close())
+	}
+	c.near = near
+
+	return c, nil
+}
+
+// Get gets value from near cache, or from redis keeping it near
+func (c *Cacher) Get(ctx context.Context, key string) (any, error) {
+	if value, err := c.near.Get(ctx, key); err == nil && value != nil {
+		return value, nil
+	}
+
+	value, err := c.remote.Get(ctx, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	// read values are not broadcast, other nodes hold the same value
+	_ = c.near.Cacher.Set(ctx, key, value)
+
+	return value, nil
+}
+
+// Set sets key-value to redis and near cache and broadcasts it
+func (c *Cacher) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	if err := c.remote.Set(ctx, key, value, options...); err != nil {
+		return err
+	}
+
+	return c.near.Set(ctx, key, value, c.nearOptions(options)...)
+}
+
+// Delete deletes value from redis and near cache and broadcasts it
+func (c *Cacher) Delete(ctx context.Context, key string) error {
+	if err := c.remote.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	return c.near.Delete(ctx, key)
+}
+
+// Load loads key-values into redis and near cache and broadcasts them
+func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
+	if err := c.remote.Load(ctx, data); err != nil {
+		return err
+	}
+
+	return c.near.Load(ctx, data)
+}
+
+// Close unsubscribes from invalidations and closes near cache, redis client is not closed
+func (c *Cacher) Close() error {
+	return errors.Join(c.near.Close(), c.remote.Close())
+}
+
+// nearOptions returns set options of near cache, values do not stay near longer than local ttl
+func (c *Cacher) nearOptions(options []cache.SetOption) []cache.SetOption {
+	setConfig := &cache.SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+
+	if setConfig.TTL > 0 && setConfig.TTL < c.localTTL {
+		return []cache.SetOption{cache.WithTTL(setConfig.TTL)}
+	}
+
+	return []cache.SetOption{cache.WithTTL(c.localTTL)}
+}
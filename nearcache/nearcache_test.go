@@ -0,0 +1,75 @@
+package nearcache
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/invalidation"
+	"github.com/albinzx/cache/memory"
+	"github.com/albinzx/cache/server/resp"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func TestCacher(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	server := resp.New(memory.New())
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	client := goredis.NewClient(&goredis.Options{Addr: listener.Addr().String(), Protocol: 2, DisableIndentity: true})
+	defer client.Close()
+
+	ctx := context.Background()
+	bus := invalidation.NewLocal()
+	a, err := New(ctx, client, WithName("users"), WithBus(bus), WithCapacity(2), WithLocalTTL(time.Minute))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer a.Close()
+	b, _ := New(ctx, client, WithName("users"), WithBus(bus))
+	defer b.Close()
+
+	tests := []struct {
+		name  string
+		run   func() error
+		cache *Cacher
+		key   string
+		want  any
+	}{
+		{name: "test set near", run: func() error { return a.Set(ctx, "1", "one") }, cache: a, key: "1", want: "one"},
+		{name: "test read through other node", cache: b, key: "1", want: "one"},
+		{name: "test kept near", run: func() error { return client.Set(ctx, "users.1", "remote", 0).Err() }, cache: b, key: "1", want: "one"},
+		{name: "test write evicts other node", run: func() error { return a.Set(ctx, "1", "uno") }, cache: b, key: "1", want: "uno"},
+		{name: "test delete evicts other node", run: func() error { return b.Delete(ctx, "1") }, cache: a, key: "1", want: nil},
+		{name: "test capacity evicts oldest near", run: func() error {
+			for _, key := range []string{"2", "3", "4"} {
+				if err := a.Set(ctx, key, "value"); err != nil {
+					return err
+				}
+			}
+			return client.Set(ctx, "users.2", "remote", 0).Err()
+		}, cache: a, key: "2", want: "remote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.run != nil {
+				if err := tt.run(); err != nil {
+					t.Fatalf("run error = %v", err)
+				}
+			}
+
+			got, err := tt.cache.Get(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
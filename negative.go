@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// notFoundMarker is cached in place of values which are not found in persistence storage,
+// it is a string so it survives backends storing strings and bytes
+const notFoundMarker = "\x00cache:not-found"
+
+// WithNegativeCaching returns option to cache misses of persistence storage for ttl, so reads of
+// missing keys do not reach persistence storage until marker expires or key is set,
+// NegativeTTL of matching rule of ttl policy overrides ttl, so misses can be cached only for some keys
+// with zero ttl, markers are stored by patterns reading through persistence storage and read as ErrNotFound,
+// cacher must be able to store string marker, e.g. marshaller of redis cacher must marshal strings
+func WithNegativeCaching(ttl time.Duration) Option {
+	return func(c *PatternedCache) {
+		c.negativeTTL = ttl
+	}
+}
+
+// negative returns time to live of cached miss of key, false is returned if misses of key are not cached
+func (c *PatternedCache) negative(key string) (time.Duration, bool) {
+	ttl := c.negativeTTL
+	if c.policy != nil {
+		if rule, ok := c.policy.rule(key); ok && rule.NegativeTTL > 0 {
+			ttl = rule.NegativeTTL
+		}
+	}
+
+	return ttl, ttl > 0
+}
+
+// isNotFound reports whether cached value is marker of miss of persistence storage
+func isNotFound(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return v == notFoundMarker
+	case []byte:
+		return string(v) == notFoundMarker
+	default:
+		return false
+	}
+}
+
+// cacheNotFound stores marker of key which is not found in persistence storage, if misses are cached
+func cacheNotFound(ctx context.Context, c Cacher, key string) {
+	s := scopeFrom(ctx)
+	if s.negative == nil {
+		return
+	}
+
+	ttl, ok := s.negative(key)
+	if !ok {
+		return
+	}
+
+	if err := c.Set(ctx, key, notFoundMarker, WithTTL(ttl)); err != nil {
+		loggerFrom(ctx).Error(ctx, "failed to cache miss", "set", key, err)
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// countingSelectPersister counts selects of persistence storage
+type countingSelectPersister struct {
+	*mapPersister
+	selects int
+}
+
+func (c *countingSelectPersister) SelectOne(ctx context.Context, key string) (any, error) {
+	c.selects++
+	return c.mapPersister.SelectOne(ctx, key)
+}
+
+func TestWithNegativeCaching(t *testing.T) {
+	ctx := context.Background()
+
+	for _, pattern := range []Pattern{&ReadThrough{}, &WriteThrough{}} {
+		t.Run(patternName(pattern), func(t *testing.T) {
+			persister := &countingSelectPersister{mapPersister: newMapPersister()}
+			c, _ := New(newMapCacher(), persister, WithPattern(pattern), WithNegativeCaching(time.Minute), WithNotFoundError())
+
+			for i := 0; i < 3; i++ {
+				if _, err := c.Get(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+					t.Errorf("Get() error = %v, want %v", err, ErrNotFound)
+				}
+			}
+			if persister.selects != 1 {
+				t.Errorf("selects = %v, want %v", persister.selects, 1)
+			}
+
+			values, err := c.GetMany(ctx, []string{"missing"})
+			if err != nil || len(values) != 0 || persister.selects != 1 {
+				t.Errorf("GetMany() = %v, %v after %d selects, want no values after 1 select", values, err, persister.selects)
+			}
+
+			// set replaces cached miss
+			_ = c.Set(ctx, "missing", "value")
+			if got, err := c.Get(ctx, "missing"); err != nil || got != "value" {
+				t.Errorf("Get() after Set() = %v, %v, want %v", got, err, "value")
+			}
+		})
+	}
+}
+
+func TestWithNegativeCaching_Policy(t *testing.T) {
+	ctx := context.Background()
+	policy, _ := NewTTLPolicy(TTLRule{Match: "user.*", NegativeTTL: time.Minute}, TTLRule{Match: "*"})
+	persister := &countingSelectPersister{mapPersister: newMapPersister()}
+	c, _ := New(newMapCacher(), persister, WithPattern(&ReadThrough{}), WithTTLPolicy(policy))
+
+	for i := 0; i < 2; i++ {
+		if got, err := c.Get(ctx, "user.1"); got != nil || err != nil {
+			t.Errorf("Get() = %v, %v, want nil", got, err)
+		}
+		_, _ = c.Get(ctx, "order.1")
+	}
+	if persister.selects != 3 {
+		t.Errorf("selects = %v, want %v", persister.selects, 3)
+	}
+}
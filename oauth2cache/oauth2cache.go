@@ -0,0 +1,113 @@
+// Package oauth2cache caches oauth2 tokens in any cacher, so replicas sharing a remote cacher,
+// e.g. redis, share tokens instead of each fetching its own
+package oauth2cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/albinzx/cache"
+	"golang.org/x/oauth2"
+)
+
+// tokenSource is token source reading tokens through patterned cache
+type tokenSource struct {
+	cache *cache.PatternedCache
+	key   string
+}
+
+// config holds configuration of token source
+type config struct {
+	expiryDelta time.Duration
+	options     []cache.Option
+}
+
+// Option provides token source options
+type Option func(*config)
+
+// WithExpiryDelta returns option to evict tokens from cache before they expire, default is 1 minute
+func WithExpiryDelta(delta time.Duration) Option {
+	return func(c *config) {
+		c.expiryDelta = delta
+	}
+}
+
+// WithCacheOptions returns option to set options of underlying patterned cache, e.g. logger
+func WithCacheOptions(options ...cache.Option) Option {
+	return func(c *config) {
+		c.options = append(c.options, options...)
+	}
+}
+
+// New returns token source caching tokens of src in cacher with key,
+// time to live of cached token is derived from its expiry, tokens without expiry keep cacher TTL
+// concurrent fetches of token in the same process are collapsed into one
+func New(c cache.Cacher, key string, src oauth2.TokenSource, options ...Option) (oauth2.TokenSource, error) {
+	config := &config{expiryDelta: time.Minute}
+	for _, option := range options {
+		option(config)
+	}
+
+	load := func(context.Context, string) (any, error) {
+		token, err := src.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(token)
+	}
+
+	ttl := func(_ string, value any) time.Duration {
+		token, err := decode(value)
+		if err != nil || token.Expiry.IsZero() {
+			return 0
+		}
+		if ttl := time.Until(token.Expiry) - config.expiryDelta; ttl > 0 {
+			return ttl
+		}
+
+		return -1
+	}
+
+	patterned, err := cache.NewReadOnly(c, load, append(config.options, cache.WithTTLFunc(ttl))...)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauth2.ReuseTokenSource(nil, &tokenSource{cache: patterned, key: key}), nil
+}
+
+// Token returns cached token, fetching it from source if it is not cached
+func (t *tokenSource) Token() (*oauth2.Token, error) {
+	value, err := t.cache.Get(context.Background(), t.key)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, errors.New("oauth2cache: token source returned no token")
+	}
+
+	return decode(value)
+}
+
+// decode returns token of cached json value
+func decode(value any) (*oauth2.Token, error) {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, cache.ErrUnexpectedType
+	}
+
+	token := &oauth2.Token{}
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
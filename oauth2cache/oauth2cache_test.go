@@ -0,0 +1,63 @@
+package oauth2cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/memory"
+	"golang.org/x/oauth2"
+)
+
+// countingSource returns new token on every call
+type countingSource struct {
+	calls  int
+	expiry time.Duration
+}
+
+func (s *countingSource) Token() (*oauth2.Token, error) {
+	s.calls++
+	return &oauth2.Token{AccessToken: "token", TokenType: "Bearer", Expiry: time.Now().Add(s.expiry)}, nil
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiry    time.Duration
+		wantCalls int
+		wantTTL   bool
+	}{
+		{name: "test token cached", expiry: time.Hour, wantCalls: 1, wantTTL: true},
+		{name: "test token expiring within delta not cached", expiry: 30 * time.Second, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := memory.New()
+			src := &countingSource{expiry: tt.expiry}
+
+			// token sources of two replicas sharing cacher
+			for i := 0; i < 2; i++ {
+				ts, err := New(cacher, "token", src)
+				if err != nil {
+					t.Fatalf("New() error = %v", err)
+				}
+				token, err := ts.Token()
+				if err != nil {
+					t.Fatalf("Token() error = %v", err)
+				}
+				if token.AccessToken != "token" {
+					t.Errorf("Token() = %v, want %v", token.AccessToken, "token")
+				}
+			}
+
+			if src.calls != tt.wantCalls {
+				t.Errorf("Token() calls = %v, want %v", src.calls, tt.wantCalls)
+			}
+
+			_, ttl, _ := cacher.GetWithTTL(context.Background(), "token")
+			if got := ttl > 0; got != tt.wantTTL {
+				t.Errorf("Token() ttl = %v, want positive %v", ttl, tt.wantTTL)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Package otelcache traces cache operations with OpenTelemetry
+package otelcache
+
+import (
+	"context"
+
+	"github.com/albinzx/cache"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation is name of instrumentation library
+const instrumentation = "github.com/albinzx/cache/otelcache"
+
+// Tracer is cache.Tracer starting OpenTelemetry spans
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// config holds tracer options
+type config struct {
+	provider trace.TracerProvider
+}
+
+// Option provides tracer options
+type Option func(*config)
+
+// WithTracerProvider returns option to set tracer provider, default is global tracer provider
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// NewTracer returns tracer starting OpenTelemetry spans of cache operations,
+// e.g. for cache.WithTracer of patterned cache
+func NewTracer(options ...Option) *Tracer {
+	c := &config{}
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.provider == nil {
+		c.provider = otel.GetTracerProvider()
+	}
+
+	return &Tracer{tracer: c.provider.Tracer(instrumentation)}
+}
+
+// Start starts client span named after operation, key is not recorded as it may be sensitive,
+// only its prefix is recorded by cacher as cache.AttrKeyPrefix
+func (t *Tracer) Start(ctx context.Context, op cache.Operation, _ string) (context.Context, cache.Span) {
+	ctx, s := t.tracer.Start(ctx, "cache."+string(op),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("cache.operation", string(op))),
+	)
+
+	return ctx, &span{span: s}
+}
+
+// span is cache.AttributeSpan of OpenTelemetry span
+type span struct {
+	span trace.Span
+}
+
+// SetAttribute sets string attribute of span
+func (s *span) SetAttribute(key, value string) {
+	s.span.SetAttributes(attribute.String(key, value))
+}
+
+// End records result and error of operation and ends span
+func (s *span) End(result cache.Result, err error) {
+	s.span.SetAttributes(attribute.String("cache.result", string(result)))
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+
+	s.span.End()
+}
+
+// Wrap returns cacher tracing every operation of c with OpenTelemetry spans having backend attribute,
+// e.g. otelcache.Wrap(redis.New(), "redis")
+func Wrap(c cache.Cacher, backend string, options ...Option) cache.Cacher {
+	return cache.Traced(c, NewTracer(options...), backend)
+}
@@ -0,0 +1,126 @@
+package otelcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/memory"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recorder is tracer provider recording spans
+type recorder struct {
+	noop.TracerProvider
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+// Tracer returns tracer recording spans to recorder
+func (r *recorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return &recordingTracer{recorder: r}
+}
+
+// recordingTracer is tracer recording spans
+type recordingTracer struct {
+	noop.Tracer
+	recorder *recorder
+}
+
+// Start starts recorded span
+func (t *recordingTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+	s := &recordedSpan{name: name, kind: config.SpanKind(), attrs: map[attribute.Key]string{}}
+	s.SetAttributes(config.Attributes()...)
+
+	t.recorder.mu.Lock()
+	t.recorder.spans = append(t.recorder.spans, s)
+	t.recorder.mu.Unlock()
+
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+// recordedSpan is span recording attributes, status and errors
+type recordedSpan struct {
+	noop.Span
+	name   string
+	kind   trace.SpanKind
+	attrs  map[attribute.Key]string
+	status codes.Code
+	errs   []error
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...attribute.KeyValue) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value.Emit()
+	}
+}
+
+func (s *recordedSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.errs = append(s.errs, err)
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) {
+	s.status = code
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) {
+	s.ended = true
+}
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	r := &recorder{}
+	c := Wrap(memory.New(), "memory", WithTracerProvider(r))
+
+	_ = c.Set(ctx, "user.1", "one")
+	_, _ = c.Get(ctx, "user.1")
+	_, _ = c.Get(ctx, "user.2")
+
+	tests := []struct {
+		name   string
+		result cache.Result
+	}{
+		{name: "cache.set", result: cache.ResultOK},
+		{name: "cache.get", result: cache.ResultHit},
+		{name: "cache.get", result: cache.ResultMiss},
+	}
+	if len(r.spans) != len(tests) {
+		t.Fatalf("spans = %d, want %d", len(r.spans), len(tests))
+	}
+	for i, tt := range tests {
+		s := r.spans[i]
+		if s.name != tt.name || s.kind != trace.SpanKindClient || !s.ended {
+			t.Errorf("span %d = %s %v ended %v, want %s client span", i, s.name, s.kind, s.ended, tt.name)
+		}
+		if s.attrs["cache.result"] != string(tt.result) {
+			t.Errorf("span %d result = %s, want %s", i, s.attrs["cache.result"], tt.result)
+		}
+		if s.attrs[cache.AttrBackend] != "memory" || s.attrs[cache.AttrKeyPrefix] != "user" {
+			t.Errorf("span %d attributes = %v", i, s.attrs)
+		}
+	}
+}
+
+func TestSpan_Error(t *testing.T) {
+	r := &recorder{}
+	tracer := NewTracer(WithTracerProvider(r))
+
+	_, s := tracer.Start(context.Background(), cache.OpGet, "key")
+	failed := errors.New("failed")
+	s.End(cache.ResultError, failed)
+
+	recorded := r.spans[0]
+	if recorded.attrs["cache.operation"] != string(cache.OpGet) {
+		t.Errorf("operation = %s, want %s", recorded.attrs["cache.operation"], cache.OpGet)
+	}
+	if recorded.status != codes.Error || len(recorded.errs) != 1 || recorded.errs[0] != failed {
+		t.Errorf("span status = %v, errors = %v, want error status and recorded error", recorded.status, recorded.errs)
+	}
+}
@@ -2,7 +2,9 @@ package cache
 
 import (
 	"context"
-	"log"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Pattern interface {
@@ -27,8 +29,13 @@ func (r *CacheAside) Set(ctx context.Context, key string, value any, c Cacher, _
 }
 
 // Get retrieves value from cache
+// if cache is skipped or refreshed with context, nil is returned so caller loads the value
 func (r *CacheAside) Get(ctx context.Context, key string, c Cacher, _ Persister) (any, error) {
-	return c.Get(ctx, key)
+	if skipped(ctx) || refreshed(ctx) {
+		return nil, nil
+	}
+
+	return getFound(ctx, c, key)
 }
 
 // Delete deletes value from cache
@@ -43,7 +50,21 @@ func (r *CacheAside) Delete(ctx context.Context, key string, c Cacher, _ Persist
 // ReadThrough is a cache pattern that reads from cache first
 // and if not found, reads from persistence storage
 // and stores the value to cache
+//
+// with WithRefreshAfter or WithEarlyRefresh, stale values are served while they are refreshed
+// in background, so expiring keys do not send every reader to persistence storage at once,
+// reads with AllowStale serve stale values within their max staleness the same way,
+// with WithMissBatching, keys missed by concurrent reads are loaded from persistence storage at once
 type ReadThrough struct {
+	soft   time.Duration
+	beta   float64
+	delta  atomic.Int64
+	now    func() time.Time
+	random func() float64
+
+	refreshing sync.Map
+	pending    sync.WaitGroup
+	batcher    *missBatcher
 }
 
 // Set stores key-value to cache
@@ -60,25 +81,15 @@ func (r *ReadThrough) Set(ctx context.Context, key string, value any, c Cacher,
 // and stores the value to cache
 // if value is nil, it means the key is not found in both cache and persistence storage
 func (r *ReadThrough) Get(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
-	value, err := c.Get(ctx, key)
-	if err != nil {
-		log.Printf("failed to get value to cache: %v", err)
+	if _, stale := staleAllowed(ctx); r.soft > 0 || r.beta > 0 || stale {
+		return r.getFresh(ctx, key, c, p)
 	}
 
-	if value == nil && p != nil {
-		value, err = p.SelectOne(ctx, key)
-		if err != nil {
-			return nil, err
-		}
-
-		if value != nil {
-			if err := c.Set(ctx, key, value); err != nil {
-				log.Printf("failed to set value to cache: %v", err)
-			}
-		}
+	if r.batcher != nil && p != nil && !skipped(ctx) && !refreshed(ctx) {
+		return r.getBatched(ctx, key, c, p)
 	}
 
-	return value, nil
+	return readThrough(ctx, key, c, p)
 }
 
 // Delete deletes value from cache
@@ -103,10 +114,12 @@ func (w *WriteThrough) Set(ctx context.Context, key string, value any, c Cacher,
 
 	if p != nil {
 		if err := p.Save(ctx, key, value); err != nil {
-			log.Printf("failed to save value to persistence storage: %v", err)
+			loggerFrom(ctx).Error(ctx, "failed to save value to persistence storage", "save", key, err)
 
 			if derr := c.Delete(ctx, key); derr != nil {
-				log.Printf("failed to delete value from cache: %v", derr)
+				loggerFrom(ctx).Error(ctx, "failed to delete value from cache", "delete", key, derr)
+			} else {
+				emit(ctx, Event{Type: EventEvictOnError, Key: key, Time: time.Now(), Err: err})
 			}
 
 			return err
@@ -120,25 +133,7 @@ func (w *WriteThrough) Set(ctx context.Context, key string, value any, c Cacher,
 // if not found, retrieves value from persistence storage
 // and stores the value to cache
 func (w *WriteThrough) Get(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
-	value, err := c.Get(ctx, key)
-	if err != nil {
-		log.Printf("failed to get value to cache: %v", err)
-	}
-
-	if value == nil && p != nil {
-		value, err = p.SelectOne(ctx, key)
-		if err != nil {
-			return nil, err
-		}
-
-		if value != nil {
-			if err := c.Set(ctx, key, value); err != nil {
-				log.Printf("failed to set value to cache: %v", err)
-			}
-		}
-	}
-
-	return value, nil
+	return readThrough(ctx, key, c, p)
 }
 
 // Delete deletes value from cache and persistence storage
@@ -156,27 +151,24 @@ func (w *WriteThrough) Delete(ctx context.Context, key string, c Cacher, p Persi
 	return nil
 }
 
-// WriteBehind is a cache pattern that writes to cache first
-// and then writes to persistence storage asynchronously
-type WriteBehind struct {
+// WriteAround is a cache pattern that writes to persistence storage but not to cache
+// write to cache is done with lazy loading on read
+type WriteAround struct {
 }
 
-// Set stores key-value to cache and asynchronously to persistence storage
-func (w *WriteBehind) Set(ctx context.Context, key string, value any, c Cacher, p Persister, options ...SetOption) error {
-	if err := c.Set(ctx, key, value, options...); err != nil {
-		return err
-	}
-
+// Set stores key-value to persistence storage
+// cached miss of key is deleted from cache so value is read on next get
+func (w *WriteAround) Set(ctx context.Context, key string, value any, c Cacher, p Persister, _ ...SetOption) error {
 	if p != nil {
-		go func() {
-			if err := p.Save(ctx, key, value); err != nil {
-				log.Printf("failed to save value to persistence storage: %v", err)
+		if err := p.Save(ctx, key, value); err != nil {
+			loggerFrom(ctx).Error(ctx, "failed to save value to persistence storage", "save", key, err)
 
-				if derr := c.Delete(ctx, key); derr != nil {
-					log.Printf("failed to delete value from cache: %v", derr)
-				}
-			}
-		}()
+			return err
+		}
+	}
+
+	if scopeFrom(ctx).negative != nil {
+		return c.Delete(ctx, key)
 	}
 
 	return nil
@@ -185,101 +177,77 @@ func (w *WriteBehind) Set(ctx context.Context, key string, value any, c Cacher,
 // Get retrieves value from cache
 // if not found, retrieves value from persistence storage
 // and stores the value to cache
-func (w *WriteBehind) Get(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
-	value, err := c.Get(ctx, key)
-	if err != nil {
-		log.Printf("failed to get value to cache: %v", err)
-	}
+func (w *WriteAround) Get(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
+	return readThrough(ctx, key, c, p)
+}
 
-	if value == nil && p != nil {
-		value, err = p.SelectOne(ctx, key)
-		if err != nil {
-			return nil, err
-		}
+// Delete deletes value from persistence storage and cache
+func (w *WriteAround) Delete(ctx context.Context, key string, c Cacher, p Persister) error {
+	if p != nil {
+		if err := p.Delete(ctx, key); err != nil {
+			loggerFrom(ctx).Error(ctx, "failed to delete value from persistence storage", "delete", key, err)
 
-		if value != nil {
-			if err := c.Set(ctx, key, value); err != nil {
-				log.Printf("failed to set value to cache: %v", err)
-			}
+			return err
 		}
 	}
 
-	return value, nil
-}
-
-// Delete deletes value from cache and asynchronously from persistence storage
-func (w *WriteBehind) Delete(ctx context.Context, key string, c Cacher, p Persister) error {
 	if err := c.Delete(ctx, key); err != nil {
 		return err
 	}
 
-	if p != nil {
-		go func() {
-			if err := p.Delete(ctx, key); err != nil {
-				log.Printf("failed to delete value from persistence storage: %v", err)
-			}
-		}()
-	}
-
 	return nil
 }
 
-// WriteAround is a cache pattern that writes to persistence storage but not to cache
-// write to cache is done with lazy loading on read
-type WriteAround struct {
-}
-
-// Set stores key-value to persistence storage
-func (w *WriteAround) Set(ctx context.Context, key string, value any, _ Cacher, p Persister, _ ...SetOption) error {
-	if p != nil {
-		if err := p.Save(ctx, key, value); err != nil {
-			log.Printf("failed to save value to persistence storage: %v", err)
-
-			return err
-		}
-	}
-
-	return nil
-}
-
-// Get retrieves value from cache
+// readThrough retrieves value from cache
 // if not found, retrieves value from persistence storage
 // and stores the value to cache
-func (w *WriteAround) Get(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
-	value, err := c.Get(ctx, key)
-	if err != nil {
-		log.Printf("failed to get value to cache: %v", err)
-	}
+// cache is not read if context skips or refreshes cache, and not written if context skips cache
+func readThrough(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
+	var value any
+	var err error
 
-	if value == nil && p != nil {
-		value, err = p.SelectOne(ctx, key)
+	if !skipped(ctx) && !refreshed(ctx) {
+		value, err = getFound(ctx, c, key)
 		if err != nil {
-			return nil, err
-		}
-
-		if value != nil {
-			if err := c.Set(ctx, key, value); err != nil {
-				log.Printf("failed to set value to cache: %v", err)
+			loggerFrom(ctx).Error(ctx, "failed to get value from cache", "get", key, err)
+			if d := scopeFrom(ctx).degraded; d != nil && p != nil {
+				return d.read(ctx, key, c, p, err)
 			}
 		}
+		if isNotFound(value) {
+			return nil, ErrNotFound
+		}
+	}
+
+	if value == nil && p != nil {
+		return loadThrough(ctx, key, c, p, true)
 	}
 
 	return value, nil
 }
 
-// Delete deletes value from persistence storage and cache
-func (w *WriteAround) Delete(ctx context.Context, key string, c Cacher, p Persister) error {
-	if p != nil {
-		if err := p.Delete(ctx, key); err != nil {
-			log.Printf("failed to delete value from persistence storage: %v", err)
+// loadThrough retrieves value from persistence storage and stores it to cache if writeBack is set,
+// cache is not written if context skips cache
+func loadThrough(ctx context.Context, key string, c Cacher, p Persister, writeBack bool) (any, error) {
+	value, err := p.SelectOne(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if value != nil {
+		markLoaded(ctx, key)
+	}
 
-			return err
-		}
+	if !writeBack || skipped(ctx) {
+		return value, nil
 	}
 
-	if err := c.Delete(ctx, key); err != nil {
-		return err
+	if value != nil {
+		if err := c.Set(ctx, key, value); err != nil {
+			loggerFrom(ctx).Error(ctx, "failed to set value to cache", "set", key, err)
+		}
+	} else {
+		cacheNotFound(ctx, c, key)
 	}
 
-	return nil
+	return value, nil
 }
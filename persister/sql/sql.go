@@ -0,0 +1,438 @@
+// Package sql is persistence storage of key-values in table of relational database over database/sql,
+// so patterns reading and writing through persistence storage work against relational store
+package sql
+
+import (
+	"bytes"
+	"context"
+	dbsql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/albinzx/cache"
+)
+
+// Dialect is SQL dialect of database, it decides placeholders and upsert statement
+type Dialect string
+
+const (
+	// Postgres is dialect of PostgreSQL
+	Postgres Dialect = "postgres"
+	// MySQL is dialect of MySQL and MariaDB
+	MySQL Dialect = "mysql"
+	// SQLite is dialect of SQLite
+	SQLite Dialect = "sqlite"
+)
+
+// batchSize is max number of keys of one statement saving or selecting multiple keys,
+// it keeps number of arguments below limits of databases
+const batchSize = 200
+
+// identifier matches table and column names, optionally qualified by schema
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Persister stores key-values in table with key and value columns, key column must be
+// primary key or unique so saves of existing keys update their values
+//
+// e.g. CREATE TABLE cache (key VARCHAR(255) PRIMARY KEY, value BLOB)
+type Persister struct {
+	db          *dbsql.DB
+	dialect     Dialect
+	table       string
+	keyColumn   string
+	valueColumn string
+	marshaller  cache.Marshaller
+
+	mu    sync.Mutex
+	stmts map[string]*dbsql.Stmt
+}
+
+// Option provides sql persister options
+type Option func(*Persister)
+
+// WithTable returns option to set table name, default is "cache"
+func WithTable(table string) Option {
+	return func(p *Persister) {
+		p.table = table
+	}
+}
+
+// WithColumns returns option to set key and value column names, default is "key" and "value"
+func WithColumns(key, value string) Option {
+	return func(p *Persister) {
+		p.keyColumn = key
+		p.valueColumn = value
+	}
+}
+
+// WithMarshaller returns option to marshal values to bytes stored in value column,
+// by default values are stored as is, so they must be values accepted by driver, e.g. []byte or string,
+// and values are selected as returned by driver
+func WithMarshaller(marshaller cache.Marshaller) Option {
+	return func(p *Persister) {
+		p.marshaller = marshaller
+	}
+}
+
+// New returns persister storing key-values in db of dialect,
+// error wraps cache.ErrInvalidOption if dialect or names are invalid
+func New(db *dbsql.DB, dialect Dialect, options ...Option) (*Persister, error) {
+	p := &Persister{
+		db:          db,
+		dialect:     dialect,
+		table:       "cache",
+		keyColumn:   "key",
+		valueColumn: "value",
+		stmts:       map[string]*dbsql.Stmt{},
+	}
+
+	for _, option := range options {
+		option(p)
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// validate returns error if dialect is unknown or names are not identifiers
+func (p *Persister) validate() error {
+	if p.db == nil {
+		return fmt.Errorf("%w: db is nil", cache.ErrInvalidOption)
+	}
+
+	switch p.dialect {
+	case Postgres, MySQL, SQLite:
+	default:
+		return fmt.Errorf("%w: unknown dialect %q", cache.ErrInvalidOption, p.dialect)
+	}
+
+	for _, name := range []string{p.table, p.keyColumn, p.valueColumn} {
+		if !identifier.MatchString(name) {
+			return fmt.Errorf("%w: invalid identifier %q", cache.ErrInvalidOption, name)
+		}
+	}
+
+	return nil
+}
+
+// quote returns quoted identifier of name
+func (p *Persister) quote(name string) string {
+	q := `"`
+	if p.dialect == MySQL {
+		q = "`"
+	}
+
+	quoted := []byte(q)
+	for i := 0; i < len(name); i++ {
+		if name[i] == '.' {
+			quoted = append(quoted, q+"."+q...)
+			continue
+		}
+		quoted = append(quoted, name[i])
+	}
+
+	return string(append(quoted, q...))
+}
+
+// placeholder returns placeholder of n-th argument
+func (p *Persister) placeholder(n int) string {
+	if p.dialect == Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+
+	return "?"
+}
+
+// upsertQuery returns statement inserting key-value or updating value of existing key
+func (p *Persister) upsertQuery() string {
+	table, key, value := p.quote(p.table), p.quote(p.keyColumn), p.quote(p.valueColumn)
+	insert := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (%s, %s)", table, key, value, p.placeholder(1), p.placeholder(2))
+
+	if p.dialect == MySQL {
+		return fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s = VALUES(%s)", insert, value, value)
+	}
+
+	return fmt.Sprintf("%s ON CONFLICT (%s) DO UPDATE SET %s = excluded.%s", insert, key, value, value)
+}
+
+// upsertManyQuery returns statement inserting n key-values or updating values of existing keys
+func (p *Persister) upsertManyQuery(n int) string {
+	table, key, value := p.quote(p.table), p.quote(p.keyColumn), p.quote(p.valueColumn)
+
+	rows := make([]string, n)
+	for i := range rows {
+		rows[i] = fmt.Sprintf("(%s, %s)", p.placeholder(2*i+1), p.placeholder(2*i+2))
+	}
+	insert := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES %s", table, key, value, strings.Join(rows, ", "))
+
+	if p.dialect == MySQL {
+		return fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s = VALUES(%s)", insert, value, value)
+	}
+
+	return fmt.Sprintf("%s ON CONFLICT (%s) DO UPDATE SET %s = excluded.%s", insert, key, value, value)
+}
+
+// selectManyQuery returns statement selecting key-values of n keys
+func (p *Persister) selectManyQuery(n int) string {
+	placeholders := make([]string, n)
+	for i := range placeholders {
+		placeholders[i] = p.placeholder(i + 1)
+	}
+
+	return fmt.Sprintf("SELECT %s, %s FROM %s WHERE %s IN (%s)", p.quote(p.keyColumn), p.quote(p.valueColumn),
+		p.quote(p.table), p.quote(p.keyColumn), strings.Join(placeholders, ", "))
+}
+
+// selectOneQuery returns statement selecting value of key
+func (p *Persister) selectOneQuery() string {
+	return fmt.Sprintf("SELECT %s FROM %s WHERE %s = %s",
+		p.quote(p.valueColumn), p.quote(p.table), p.quote(p.keyColumn), p.placeholder(1))
+}
+
+// selectAllQuery returns statement selecting all key-values
+func (p *Persister) selectAllQuery() string {
+	return fmt.Sprintf("SELECT %s, %s FROM %s", p.quote(p.keyColumn), p.quote(p.valueColumn), p.quote(p.table))
+}
+
+// deleteQuery returns statement deleting key
+func (p *Persister) deleteQuery() string {
+	return fmt.Sprintf("DELETE FROM %s WHERE %s = %s", p.quote(p.table), p.quote(p.keyColumn), p.placeholder(1))
+}
+
+// stmt returns prepared statement of query, statements are prepared once and reused
+func (p *Persister) stmt(ctx context.Context, query string) (*dbsql.Stmt, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stmts == nil {
+		return nil, dbsql.ErrConnDone
+	}
+	if stmt, ok := p.stmts[query]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := p.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	p.stmts[query] = stmt
+
+	return stmt, nil
+}
+
+// encode returns value stored in value column
+func (p *Persister) encode(value any) (any, error) {
+	if p.marshaller != nil {
+		return p.marshaller.Marshal(value)
+	}
+
+	if !driver.IsValue(value) {
+		return nil, fmt.Errorf("%w: %T can not be stored without marshaller", cache.ErrUnexpectedType, value)
+	}
+
+	return value, nil
+}
+
+// decode returns value of value column
+func (p *Persister) decode(value any) (any, error) {
+	if p.marshaller != nil {
+		switch v := value.(type) {
+		case []byte:
+			return p.marshaller.Unmarshal(v)
+		case string:
+			return p.marshaller.Unmarshal([]byte(v))
+		default:
+			return nil, fmt.Errorf("%w: %T can not be unmarshalled", cache.ErrUnexpectedType, value)
+		}
+	}
+
+	// drivers may reuse byte slices between rows
+	if b, ok := value.([]byte); ok {
+		return bytes.Clone(b), nil
+	}
+
+	return value, nil
+}
+
+// Save stores key value to table, value of existing key is updated
+func (p *Persister) Save(ctx context.Context, key string, value any) error {
+	encoded, err := p.encode(value)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := p.stmt(ctx, p.upsertQuery())
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, key, encoded)
+
+	return err
+}
+
+// SelectOne retrieves value by key from table, value is nil if key is not found
+func (p *Persister) SelectOne(ctx context.Context, key string) (any, error) {
+	stmt, err := p.stmt(ctx, p.selectOneQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	if err := stmt.QueryRowContext(ctx, key).Scan(&value); err != nil {
+		if errors.Is(err, dbsql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return p.decode(value)
+}
+
+// SelectAll retrieves all key-values from table
+func (p *Persister) SelectAll(ctx context.Context) (map[string]any, error) {
+	stmt, err := p.stmt(ctx, p.selectAllQuery())
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := map[string]any{}
+	for rows.Next() {
+		var key string
+		var value any
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		if data[key], err = p.decode(value); err != nil {
+			return nil, &cache.KeyError{Key: key, Err: err}
+		}
+	}
+
+	return data, rows.Err()
+}
+
+// SaveAll stores key-values to table in statements of up to 200 keys, values of existing keys are updated
+// returned error joins cache.KeyError of values which fail to be encoded
+func (p *Persister) SaveAll(ctx context.Context, data map[string]any) error {
+	var errs []error
+	args := make([]any, 0, 2*min(len(data), batchSize))
+	flush := func() error {
+		if len(args) == 0 {
+			return nil
+		}
+		_, err := p.db.ExecContext(ctx, p.upsertManyQuery(len(args)/2), args...)
+		args = args[:0]
+		return err
+	}
+
+	for key, value := range data {
+		encoded, err := p.encode(value)
+		if err != nil {
+			errs = append(errs, &cache.KeyError{Key: key, Err: err})
+			continue
+		}
+		if args = append(args, key, encoded); len(args) == 2*batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+// SelectMany retrieves values of keys from table in statements of up to 200 keys,
+// returned map contains only keys which are found, returned error joins cache.KeyError of values
+// which fail to be decoded
+func (p *Persister) SelectMany(ctx context.Context, keys []string) (map[string]any, error) {
+	data := make(map[string]any, len(keys))
+
+	var errs []error
+	for start := 0; start < len(keys); start += batchSize {
+		args := make([]any, 0, batchSize)
+		for _, key := range keys[start:min(start+batchSize, len(keys))] {
+			args = append(args, key)
+		}
+
+		decodeErrs, err := p.selectMany(ctx, args, data)
+		if err != nil {
+			return data, err
+		}
+		errs = append(errs, decodeErrs...)
+	}
+
+	return data, errors.Join(errs...)
+}
+
+// selectMany selects key-values of keys given as args into data and returns errors of values
+// which fail to be decoded
+func (p *Persister) selectMany(ctx context.Context, args []any, data map[string]any) ([]error, error) {
+	rows, err := p.db.QueryContext(ctx, p.selectManyQuery(len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var errs []error
+	for rows.Next() {
+		var key string
+		var value any
+		if err := rows.Scan(&key, &value); err != nil {
+			return errs, err
+		}
+		if value, err = p.decode(value); err != nil {
+			errs = append(errs, &cache.KeyError{Key: key, Err: err})
+			continue
+		}
+		data[key] = value
+	}
+
+	return errs, rows.Err()
+}
+
+// Delete deletes value by key from table
+func (p *Persister) Delete(ctx context.Context, key string) error {
+	stmt, err := p.stmt(ctx, p.deleteQuery())
+	if err != nil {
+		return err
+	}
+
+	_, err = stmt.ExecContext(ctx, key)
+
+	return err
+}
+
+// Ping pings database
+func (p *Persister) Ping(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+// Close closes prepared statements, db is owned by caller and is not closed
+func (p *Persister) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var errs []error
+	for _, stmt := range p.stmts {
+		errs = append(errs, stmt.Close())
+	}
+	p.stmts = nil
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,254 @@
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/albinzx/cache"
+)
+
+// fakeDriver stores rows of one table in map and records prepared queries
+type fakeDriver struct {
+	mu       sync.Mutex
+	rows     map[string]driver.Value
+	prepared []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct {
+	d *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+
+	c.d.prepared = append(c.d.prepared, query)
+	return &fakeStmt{d: c.d, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+
+	switch {
+	case strings.HasPrefix(s.query, "INSERT"):
+		for i := 0; i < len(args); i += 2 {
+			s.d.rows[args[i].(string)] = args[i+1]
+		}
+	case strings.HasPrefix(s.query, "DELETE"):
+		delete(s.d.rows, args[0].(string))
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+
+	if strings.Contains(s.query, " IN (") {
+		rows := &fakeRows{columns: []string{"key", "value"}}
+		for _, arg := range args {
+			if value, ok := s.d.rows[arg.(string)]; ok {
+				rows.rows = append(rows.rows, []driver.Value{arg, value})
+			}
+		}
+		return rows, nil
+	}
+
+	if len(args) == 1 {
+		rows := &fakeRows{columns: []string{"value"}}
+		if value, ok := s.d.rows[args[0].(string)]; ok {
+			rows.rows = [][]driver.Value{{value}}
+		}
+		return rows, nil
+	}
+
+	rows := &fakeRows{columns: []string{"key", "value"}}
+	for key, value := range s.d.rows {
+		rows.rows = append(rows.rows, []driver.Value{key, value})
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var (
+	registered sync.Once
+	fake       = &fakeDriver{}
+)
+
+func openFake(t *testing.T) *fakeDriver {
+	registered.Do(func() { dbsql.Register("fakesql", fake) })
+
+	fake.mu.Lock()
+	fake.rows = map[string]driver.Value{}
+	fake.prepared = nil
+	fake.mu.Unlock()
+
+	return fake
+}
+
+type stringMarshaller struct{}
+
+func (stringMarshaller) Marshal(value any) ([]byte, error)  { return []byte(value.(string)), nil }
+func (stringMarshaller) Unmarshal(data []byte) (any, error) { return "m:" + string(data), nil }
+
+func TestPersister(t *testing.T) {
+	d := openFake(t)
+	db, _ := dbsql.Open("fakesql", "")
+	defer db.Close()
+
+	p, err := New(db, SQLite)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer p.Close()
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "a"} {
+		if err := p.Save(ctx, key, []byte(key+"1")); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if got, _ := p.SelectOne(ctx, "a"); !reflect.DeepEqual(got, []byte("a1")) {
+		t.Errorf("SelectOne() = %v, want %v", got, []byte("a1"))
+	}
+	if got, err := p.SelectOne(ctx, "missing"); got != nil || err != nil {
+		t.Errorf("SelectOne() = %v, %v, want nil", got, err)
+	}
+	if err := p.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	all, _ := p.SelectAll(ctx)
+	if want := map[string]any{"b": []byte("b1")}; !reflect.DeepEqual(all, want) {
+		t.Errorf("SelectAll() = %v, want %v", all, want)
+	}
+
+	// statements are prepared once
+	prepared := append([]string(nil), d.prepared...)
+	sort.Strings(prepared)
+	want := []string{
+		`DELETE FROM "cache" WHERE "key" = ?`,
+		`INSERT INTO "cache" ("key", "value") VALUES (?, ?) ON CONFLICT ("key") DO UPDATE SET "value" = excluded."value"`,
+		`SELECT "key", "value" FROM "cache"`,
+		`SELECT "value" FROM "cache" WHERE "key" = ?`,
+	}
+	if !reflect.DeepEqual(prepared, want) {
+		t.Errorf("prepared = %q, want %q", prepared, want)
+	}
+
+	if err := p.Save(ctx, "c", struct{}{}); !errors.Is(err, cache.ErrUnexpectedType) {
+		t.Errorf("Save() error = %v, want %v", err, cache.ErrUnexpectedType)
+	}
+}
+
+func TestPersister_Many(t *testing.T) {
+	openFake(t)
+	db, _ := dbsql.Open("fakesql", "")
+	defer db.Close()
+
+	p, _ := New(db, Postgres)
+	defer p.Close()
+	ctx := context.Background()
+
+	data := map[string]any{"bad": struct{}{}}
+	for i := 0; i < batchSize+10; i++ {
+		data[fmt.Sprintf("key%d", i)] = []byte("value")
+	}
+	err := p.SaveAll(ctx, data)
+	if failed := cache.KeyErrors(err, []string{"bad"}); len(failed) != 1 {
+		t.Errorf("SaveAll() error = %v, want error of key bad", err)
+	}
+
+	got, err := p.SelectMany(ctx, []string{"key0", fmt.Sprintf("key%d", batchSize+9), "missing"})
+	want := map[string]any{"key0": []byte("value"), fmt.Sprintf("key%d", batchSize+9): []byte("value")}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("SelectMany() = %v, %v, want %v", got, err, want)
+	}
+
+	if want := `SELECT "key", "value" FROM "cache" WHERE "key" IN ($1, $2)`; p.selectManyQuery(2) != want {
+		t.Errorf("selectManyQuery() = %q, want %q", p.selectManyQuery(2), want)
+	}
+}
+
+func TestPersister_Marshaller(t *testing.T) {
+	openFake(t)
+	db, _ := dbsql.Open("fakesql", "")
+	defer db.Close()
+
+	p, _ := New(db, Postgres, WithTable("app.entries"), WithColumns("k", "v"), WithMarshaller(stringMarshaller{}))
+	ctx := context.Background()
+
+	_ = p.Save(ctx, "a", "one")
+	if got, _ := p.SelectOne(ctx, "a"); got != "m:one" {
+		t.Errorf("SelectOne() = %v, want %v", got, "m:one")
+	}
+	if got, want := p.upsertQuery(), `INSERT INTO "app"."entries" ("k", "v") VALUES ($1, $2) ON CONFLICT ("k") DO UPDATE SET "v" = excluded."v"`; got != want {
+		t.Errorf("upsertQuery() = %v, want %v", got, want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	db, _ := dbsql.Open("fakesql", "")
+	defer db.Close()
+
+	tests := []struct {
+		name    string
+		dialect Dialect
+		options []Option
+		wantErr bool
+	}{
+		{name: "test mysql", dialect: MySQL},
+		{name: "test unknown dialect", dialect: "oracle", wantErr: true},
+		{name: "test invalid table", dialect: MySQL, options: []Option{WithTable("cache; DROP TABLE users")}, wantErr: true},
+		{name: "test invalid column", dialect: MySQL, options: []Option{WithColumns("key", "")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(db, tt.dialect, tt.options...)
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, cache.ErrInvalidOption)) {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	p, _ := New(db, MySQL)
+	if got, want := p.upsertQuery(), "INSERT INTO `cache` (`key`, `value`) VALUES (?, ?) ON DUPLICATE KEY UPDATE `value` = VALUES(`value`)"; got != want {
+		t.Errorf("upsertQuery() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// BatchPersister is implemented by persistence storages which save and select multiple keys in one round trip
+// persistence storages which do not implement it are called once per key by SaveAll and SelectMany
+type BatchPersister interface {
+	// SaveAll stores key-values to persistence storage, returned error joins KeyError of keys which fail
+	// to be saved, error which is not KeyError fails all keys
+	SaveAll(ctx context.Context, data map[string]any) error
+	// SelectMany retrieves values of keys from persistence storage, returned map contains only keys which are found
+	SelectMany(ctx context.Context, keys []string) (map[string]any, error)
+}
+
+// SaveAll stores key-values to p at once if it implements BatchPersister, otherwise one key at a time
+// returned error joins KeyError of keys which fail to be saved one at a time
+func SaveAll(ctx context.Context, p Persister, data map[string]any) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	if batch, ok := p.(BatchPersister); ok {
+		return batch.SaveAll(ctx, data)
+	}
+
+	var errs []error
+	for _, key := range internal.SortedKeys(data) {
+		if err := p.Save(ctx, key, data[key]); err != nil {
+			errs = append(errs, keyError(key, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SelectMany retrieves values of keys from p at once if it implements BatchPersister, otherwise one key at a time
+// returned map contains only keys which are found, keys which fail to be retrieved one at a time
+// are missing and returned error joins their KeyError
+func SelectMany(ctx context.Context, p Persister, keys []string) (map[string]any, error) {
+	if len(keys) == 0 {
+		return map[string]any{}, nil
+	}
+
+	if batch, ok := p.(BatchPersister); ok {
+		values, err := batch.SelectMany(ctx, keys)
+		if values == nil {
+			values = map[string]any{}
+		}
+		return values, err
+	}
+
+	var errs []error
+	values := make(map[string]any, len(keys))
+	for _, key := range keys {
+		value, err := p.SelectOne(ctx, key)
+		if err != nil {
+			errs = append(errs, keyError(key, err))
+			continue
+		}
+		if value != nil {
+			values[key] = value
+		}
+	}
+
+	return values, errors.Join(errs...)
+}
+
+// failedKeys returns errors of keys failed by batch operation returning err,
+// error which is not KeyError fails all keys
+func failedKeys(err error, keys []string) map[string]error {
+	failed := KeyErrors(err, keys)
+	if err != nil && !isKeyErrors(err) {
+		for _, key := range keys {
+			failed[key] = err
+		}
+	}
+
+	return failed
+}
@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TTLRule configures expiration of keys matching the rule
+type TTLRule struct {
+	// Match is glob pattern of matched keys, e.g. "session.*"
+	Match string
+	// Regexp is regular expression of matched keys, used when Match is empty
+	Regexp string
+	// TTL is time to live of matched keys
+	TTL time.Duration
+	// Jitter is maximum random duration added to TTL, so keys set together do not expire together
+	Jitter time.Duration
+	// NegativeTTL is time to live of cached misses of matched keys, see WithNegativeCaching
+	NegativeTTL time.Duration
+
+	regexp *regexp.Regexp
+}
+
+// matches reports whether key matches rule
+func (r *TTLRule) matches(key string) bool {
+	if r.regexp != nil {
+		return r.regexp.MatchString(key)
+	}
+
+	ok, _ := path.Match(r.Match, key)
+
+	return ok
+}
+
+// TTLPolicy selects expiration of keys by the first matching rule
+type TTLPolicy struct {
+	rules []TTLRule
+}
+
+// NewTTLPolicy returns policy with rules, rules are matched in order
+func NewTTLPolicy(rules ...TTLRule) (*TTLPolicy, error) {
+	policy := &TTLPolicy{rules: make([]TTLRule, len(rules))}
+
+	for i, rule := range rules {
+		switch {
+		case rule.Match != "":
+			if _, err := path.Match(rule.Match, ""); err != nil {
+				return nil, fmt.Errorf("rule %s: %w", rule.Match, err)
+			}
+		case rule.Regexp != "":
+			re, err := regexp.Compile(rule.Regexp)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %w", rule.Regexp, err)
+			}
+			rule.regexp = re
+		default:
+			return nil, fmt.Errorf("rule %d has neither match nor regexp", i)
+		}
+
+		policy.rules[i] = rule
+	}
+
+	return policy, nil
+}
+
+// ParseTTLPolicy returns policy from lines of "pattern = ttl" rules, e.g. "session.* = 30m"
+// empty lines and lines starting with # are ignored
+func ParseTTLPolicy(text string) (*TTLPolicy, error) {
+	var rules []TTLRule
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule %q", line)
+		}
+
+		ttl, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule %q: %w", line, err)
+		}
+
+		rules = append(rules, TTLRule{Match: strings.TrimSpace(pattern), TTL: ttl})
+	}
+
+	return NewTTLPolicy(rules...)
+}
+
+// rule returns first rule matching key
+func (p *TTLPolicy) rule(key string) (*TTLRule, bool) {
+	for i := range p.rules {
+		if p.rules[i].matches(key) {
+			return &p.rules[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// TTL returns time to live of key including jitter, false is returned if no rule matches
+func (p *TTLPolicy) TTL(key string) (time.Duration, bool) {
+	rule, ok := p.rule(key)
+	if !ok {
+		return 0, false
+	}
+
+	ttl := rule.TTL
+	if rule.Jitter > 0 {
+		ttl += time.Duration(rand.Int63n(int64(rule.Jitter)))
+	}
+
+	return ttl, true
+}
+
+// NegativeTTL returns time to live of cached miss of key, false is returned if no rule matches
+func (p *TTLPolicy) NegativeTTL(key string) (time.Duration, bool) {
+	rule, ok := p.rule(key)
+	if !ok {
+		return 0, false
+	}
+
+	return rule.NegativeTTL, true
+}
+
+// negative reports whether any rule caches misses, nil policy caches none
+func (p *TTLPolicy) negative() bool {
+	if p == nil {
+		return false
+	}
+
+	for _, rule := range p.rules {
+		if rule.NegativeTTL > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policyCacher is cacher applying ttl policy to sets
+type policyCacher struct {
+	Cacher
+	policy *TTLPolicy
+}
+
+// Set sets key-value to cache with ttl of policy
+// ttl given in options overrides the policy
+func (p *policyCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	if ttl, ok := p.policy.TTL(key); ok {
+		options = append([]SetOption{WithTTL(ttl)}, options...)
+	}
+
+	return p.Cacher.Set(ctx, key, value, options...)
+}
+
+// WithTTLPolicy returns option to apply ttl policy to every value stored to cache,
+// including values stored by patterns, ttl given to Set overrides the policy
+// values loaded with Cacher.Load keep cacher TTL
+func WithTTLPolicy(policy *TTLPolicy) Option {
+	return func(c *PatternedCache) {
+		c.policy = policy
+	}
+}
+
+// ttlFuncCacher is cacher applying ttl derived from value to sets
+type ttlFuncCacher struct {
+	Cacher
+	ttl func(key string, value any) time.Duration
+}
+
+// Set sets key-value to cache with ttl derived from value
+// ttl given in options overrides the derived ttl
+func (t *ttlFuncCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	ttl := t.ttl(key, value)
+	if ttl < 0 {
+		// value is already expired
+		return nil
+	}
+
+	if ttl > 0 {
+		options = append([]SetOption{WithTTL(ttl)}, options...)
+	}
+
+	return t.Cacher.Set(ctx, key, value, options...)
+}
+
+// WithTTLFunc returns option to derive time to live of every value stored to cache from the value,
+// e.g. expiry timestamp of a token, derived ttl takes precedence over ttl policy
+// zero ttl keeps the default ttl and negative ttl means value is expired and is not stored
+// ttl given to Set overrides the derived ttl and values loaded with Cacher.Load keep cacher TTL
+func WithTTLFunc(ttl func(key string, value any) time.Duration) Option {
+	return func(c *PatternedCache) {
+		c.ttlFunc = ttl
+	}
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// ttlCacher records ttl of sets
+type ttlCacher struct {
+	*mapCacher
+	ttls map[string]time.Duration
+}
+
+func (t *ttlCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	setConfig := &SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+	t.ttls[key] = setConfig.TTL
+	return t.mapCacher.Set(ctx, key, value, options...)
+}
+
+func TestWithTTLPolicy(t *testing.T) {
+	policy, err := ParseTTLPolicy(`
+		# sessions are short lived
+		session.* = 30m
+		config.*  = 24h
+	`)
+	if err != nil {
+		t.Fatalf("ParseTTLPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		options []SetOption
+		want    time.Duration
+	}{
+		{name: "test session rule", key: "session.1", want: 30 * time.Minute},
+		{name: "test config rule", key: "config.app", want: 24 * time.Hour},
+		{name: "test no rule", key: "user.1", want: 0},
+		{name: "test explicit ttl", key: "session.2", options: []SetOption{WithTTL(time.Second)}, want: time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := &ttlCacher{mapCacher: newMapCacher(), ttls: map[string]time.Duration{}}
+			c, _ := New(cacher, nil, WithTTLPolicy(policy))
+			_ = c.Set(context.Background(), tt.key, "value", tt.options...)
+
+			if got := cacher.ttls[tt.key]; got != tt.want {
+				t.Errorf("PatternedCache.Set() ttl = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTTLPolicy(t *testing.T) {
+	policy, err := NewTTLPolicy(
+		TTLRule{Regexp: `^user\.\d+$`, TTL: time.Minute, Jitter: time.Second, NegativeTTL: 5 * time.Second},
+		TTLRule{Match: "*", TTL: time.Hour},
+	)
+	if err != nil {
+		t.Fatalf("NewTTLPolicy() error = %v", err)
+	}
+
+	if ttl, _ := policy.TTL("user.42"); ttl < time.Minute || ttl >= time.Minute+time.Second {
+		t.Errorf("TTLPolicy.TTL() = %v, want between 1m and 1m1s", ttl)
+	}
+	if ttl, _ := policy.NegativeTTL("user.42"); ttl != 5*time.Second {
+		t.Errorf("TTLPolicy.NegativeTTL() = %v, want %v", ttl, 5*time.Second)
+	}
+	if ttl, _ := policy.TTL("user.name"); ttl != time.Hour {
+		t.Errorf("TTLPolicy.TTL() = %v, want %v", ttl, time.Hour)
+	}
+
+	if _, err := NewTTLPolicy(TTLRule{Regexp: "("}); err == nil {
+		t.Errorf("NewTTLPolicy() error = %v, want error", err)
+	}
+	if _, err := ParseTTLPolicy("session.*"); err == nil {
+		t.Errorf("ParseTTLPolicy() error = %v, want error", err)
+	}
+}
+
+func TestWithTTLFunc(t *testing.T) {
+	policy, _ := NewTTLPolicy(TTLRule{Match: "*", TTL: time.Hour})
+	expiry := func(_ string, value any) time.Duration {
+		return time.Duration(value.(int)) * time.Second
+	}
+
+	tests := []struct {
+		name       string
+		value      int
+		options    []SetOption
+		want       time.Duration
+		wantStored bool
+	}{
+		{name: "test derived ttl", value: 10, want: 10 * time.Second, wantStored: true},
+		{name: "test zero ttl keeps policy", value: 0, want: time.Hour, wantStored: true},
+		{name: "test expired value", value: -1, wantStored: false},
+		{name: "test explicit ttl", value: 10, options: []SetOption{WithTTL(time.Second)}, want: time.Second, wantStored: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := &ttlCacher{mapCacher: newMapCacher(), ttls: map[string]time.Duration{}}
+			c, _ := New(cacher, nil, WithTTLPolicy(policy), WithTTLFunc(expiry))
+			_ = c.Set(context.Background(), "key", tt.value, tt.options...)
+
+			if _, stored := cacher.data["key"]; stored != tt.wantStored {
+				t.Errorf("PatternedCache.Set() stored = %v, want %v", stored, tt.wantStored)
+			}
+			if got := cacher.ttls["key"]; got != tt.want {
+				t.Errorf("PatternedCache.Set() ttl = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
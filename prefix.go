@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"strings"
+)
+
+// prefixBatch is number of keys deleted at once by DeleteByPrefix of cachers which scan keys
+const prefixBatch = 100
+
+// PrefixDeleter is implemented by cachers which delete all keys starting with prefix,
+// keys and prefix are keys as given to cacher, without name prefix of cacher
+type PrefixDeleter interface {
+	// DeleteByPrefix deletes keys starting with prefix and returns number of deleted keys
+	DeleteByPrefix(ctx context.Context, prefix string) (int, error)
+}
+
+// DeleteByPrefix deletes keys of c starting with prefix and returns number of deleted keys,
+// c must implement PrefixDeleter or Scanner, keys of scanners are deleted in batches while they are scanned
+func DeleteByPrefix(ctx context.Context, c Cacher, prefix string) (int, error) {
+	if deleter, ok := c.(PrefixDeleter); ok {
+		return deleter.DeleteByPrefix(ctx, prefix)
+	}
+
+	scanner, ok := c.(Scanner)
+	if !ok {
+		return 0, ErrNotScanner
+	}
+
+	deleted := 0
+	batch := make([]string, 0, prefixBatch)
+	flush := func() error {
+		if err := DeleteMany(ctx, c, batch...); err != nil {
+			return err
+		}
+		deleted += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	it := scanner.Keys(ctx, GlobPrefix(prefix))
+	for it.Next(ctx) {
+		if !strings.HasPrefix(it.Key(), prefix) {
+			continue
+		}
+		if batch = append(batch, it.Key()); len(batch) == prefixBatch {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return deleted, err
+	}
+
+	return deleted, flush()
+}
+
+// globEscaper escapes glob pattern metacharacters
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// GlobPrefix returns glob pattern matching keys starting with prefix, metacharacters of prefix are escaped
+func GlobPrefix(prefix string) string {
+	return globEscaper.Replace(prefix) + "*"
+}
+
+// DeleteByPrefix deletes keys starting with prefix from cache and returns number of deleted keys,
+// values are not deleted from persistence storage, cacher must implement PrefixDeleter or Scanner
+func (c *PatternedCache) DeleteByPrefix(ctx context.Context, prefix string) (int, error) {
+	ctx = withScope(ctx, c.scope)
+
+	deleted, err := DeleteByPrefix(ctx, c.unwrapped(), prefix)
+	if err != nil {
+		loggerFrom(ctx).Error(ctx, "failed to delete keys by prefix", "delete_by_prefix", prefix, err)
+	}
+
+	return deleted, err
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestDeleteByPrefix(t *testing.T) {
+	ctx := context.Background()
+	m := newMapCacher()
+	for i := 0; i < 250; i++ {
+		m.data[fmt.Sprint("orders.", i)] = i
+	}
+	m.data["users.1"] = "one"
+
+	deleted, err := DeleteByPrefix(ctx, &scanCacher{m}, "orders.")
+	if err != nil || deleted != 250 {
+		t.Fatalf("DeleteByPrefix() = %v, %v, want %v", deleted, err, 250)
+	}
+	if len(m.data) != 1 || m.data["users.1"] != "one" {
+		t.Errorf("data = %v, want only users.1", m.data)
+	}
+
+	if _, err := DeleteByPrefix(ctx, newMapCacher(), "orders."); !errors.Is(err, ErrNotScanner) {
+		t.Errorf("DeleteByPrefix() error = %v, want %v", err, ErrNotScanner)
+	}
+}
+
+func TestGlobPrefix(t *testing.T) {
+	if got, want := GlobPrefix(`a*b?[c]\`), `a\*b\?\[c\]\\*`; got != want {
+		t.Errorf("GlobPrefix() = %v, want %v", got, want)
+	}
+}
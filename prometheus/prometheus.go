@@ -0,0 +1,184 @@
+// Package prometheus exposes cache stats in prometheus text exposition format,
+// without depending on prometheus client library
+package prometheus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// contentType is content type of prometheus text exposition format
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// counter is counter metric family of stats
+type counter struct {
+	name  string
+	help  string
+	value func(cache.StatsSnapshot) int64
+}
+
+// counters are counter metric families of stats
+var counters = []counter{
+	{name: "cache_hits_total", help: "Number of gets which found value.", value: func(s cache.StatsSnapshot) int64 { return s.Hits }},
+	{name: "cache_misses_total", help: "Number of gets which found no value.", value: func(s cache.StatsSnapshot) int64 { return s.Misses }},
+	{name: "cache_sets_total", help: "Number of successful sets.", value: func(s cache.StatsSnapshot) int64 { return s.Sets }},
+	{name: "cache_deletes_total", help: "Number of successful deletes.", value: func(s cache.StatsSnapshot) int64 { return s.Deletes }},
+	{name: "cache_loads_total", help: "Number of successful loads.", value: func(s cache.StatsSnapshot) int64 { return s.Loads }},
+	{name: "cache_errors_total", help: "Number of failed operations.", value: func(s cache.StatsSnapshot) int64 { return s.Errors }},
+}
+
+// Collector collects stats of caches and exposes them to prometheus
+type Collector struct {
+	mu    sync.RWMutex
+	stats map[string]*cache.Stats
+}
+
+// NewCollector returns collector exposing stats, stats are labeled by their name
+func NewCollector(stats ...*cache.Stats) *Collector {
+	c := &Collector{stats: map[string]*cache.Stats{}}
+	for _, s := range stats {
+		c.Register(s)
+	}
+
+	return c
+}
+
+// Register adds stats to collector, stats with name already registered replace it
+func (c *Collector) Register(stats *cache.Stats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats[stats.Name()] = stats
+}
+
+// Unregister removes stats of name from collector
+func (c *Collector) Unregister(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.stats, name)
+}
+
+// snapshots returns snapshots of registered stats sorted by name
+func (c *Collector) snapshots() []cache.StatsSnapshot {
+	c.mu.RLock()
+	snapshots := make([]cache.StatsSnapshot, 0, len(c.stats))
+	for _, stats := range c.stats {
+		snapshots = append(snapshots, stats.Snapshot())
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name < snapshots[j].Name })
+
+	return snapshots
+}
+
+// WriteTo writes metrics of registered stats in prometheus text exposition format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	snapshots := c.snapshots()
+	out := &countingWriter{w: bufio.NewWriter(w)}
+
+	for _, counter := range counters {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s counter\n", counter.name, counter.help, counter.name)
+		for _, s := range snapshots {
+			fmt.Fprintf(out, "%s{cache=\"%s\"} %d\n", counter.name, label(s.Name), counter.value(s))
+		}
+	}
+
+	writeHistograms(out, "cache_operation_duration_seconds", "Latency of operations in seconds.", snapshots,
+		func(s cache.StatsSnapshot) map[cache.Operation]cache.Distribution { return s.Latency },
+		func(v int64) float64 { return time.Duration(v).Seconds() })
+	writeHistograms(out, "cache_value_size_bytes", "Size of values set and got in bytes.", snapshots,
+		func(s cache.StatsSnapshot) map[cache.Operation]cache.Distribution { return s.Size },
+		func(v int64) float64 { return float64(v) })
+
+	if out.err != nil {
+		return out.n, out.err
+	}
+
+	return out.n, out.w.Flush()
+}
+
+// writeHistograms writes histogram family of distributions of snapshots per operation,
+// values of distributions are converted to unit of family by unit
+func writeHistograms(out io.Writer, name, help string, snapshots []cache.StatsSnapshot,
+	distributions func(cache.StatsSnapshot) map[cache.Operation]cache.Distribution, unit func(int64) float64) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	for _, s := range snapshots {
+		byOp := distributions(s)
+		ops := make([]string, 0, len(byOp))
+		for op := range byOp {
+			ops = append(ops, string(op))
+		}
+		sort.Strings(ops)
+
+		for _, op := range ops {
+			d := byOp[cache.Operation(op)]
+			labels := fmt.Sprintf("cache=\"%s\",op=\"%s\"", label(s.Name), op)
+			for _, bucket := range d.Buckets {
+				fmt.Fprintf(out, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, float(unit(bucket.UpperBound)), bucket.Count)
+			}
+			fmt.Fprintf(out, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, d.Count)
+			fmt.Fprintf(out, "%s_sum{%s} %s\n", name, labels, float(unit(d.Sum)))
+			fmt.Fprintf(out, "%s_count{%s} %d\n", name, labels, d.Count)
+		}
+	}
+}
+
+// ServeHTTP serves metrics of registered stats, e.g. at /metrics
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	_, _ = c.WriteTo(w)
+}
+
+// Handler returns handler serving metrics of stats
+func Handler(stats ...*cache.Stats) http.Handler {
+	return NewCollector(stats...)
+}
+
+// escaper escapes label values
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label returns escaped label value of cache name, unnamed stats are labeled "default" as in expvar
+func label(name string) string {
+	if name == "" {
+		return "default"
+	}
+
+	return escaper.Replace(name)
+}
+
+// float formats float value of sample
+func float(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter counts written bytes and keeps first error
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+// Write writes p unless previous write failed
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+
+	return n, err
+}
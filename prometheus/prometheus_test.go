@@ -0,0 +1,61 @@
+package prometheus
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+func TestCollector(t *testing.T) {
+	orders := cache.NewStats("orders")
+	orders.Observe(cache.OpGet, cache.ResultHit, time.Millisecond)
+	orders.Observe(cache.OpGet, cache.ResultMiss, 3*time.Millisecond)
+	orders.Observe(cache.OpSet, cache.ResultOK, time.Millisecond)
+	orders.ObserveSize(cache.OpSet, 100)
+
+	rec := httptest.NewRecorder()
+	Handler(orders, cache.NewStats(`quoted "name"`)).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != contentType {
+		t.Errorf("Content-Type = %q, want %q", got, contentType)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE cache_hits_total counter\n",
+		`cache_hits_total{cache="orders"} 1`,
+		`cache_misses_total{cache="orders"} 1`,
+		`cache_sets_total{cache="orders"} 1`,
+		`cache_hits_total{cache="quoted \"name\""} 0`,
+		"# TYPE cache_operation_duration_seconds histogram\n",
+		`cache_operation_duration_seconds_bucket{cache="orders",op="get",le="+Inf"} 2`,
+		`cache_operation_duration_seconds_count{cache="orders",op="get"} 2`,
+		`cache_operation_duration_seconds_sum{cache="orders",op="get"} 0.004`,
+		`cache_value_size_bytes_count{cache="orders",op="set"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics do not contain %q:\n%s", want, body)
+		}
+	}
+
+	// buckets are cumulative, 1ms is within bucket of 1.024ms
+	if !strings.Contains(body, `cache_operation_duration_seconds_bucket{cache="orders",op="get",le="0.001024"} 1`) {
+		t.Errorf("metrics do not contain cumulative bucket:\n%s", body)
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	c := NewCollector(cache.NewStats("a"), cache.NewStats("b"))
+	c.Unregister("a")
+
+	var out strings.Builder
+	if _, err := c.WriteTo(&out); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), `cache="a"`) || !strings.Contains(out.String(), `cache="b"`) {
+		t.Errorf("metrics = %s, want only b", out.String())
+	}
+}
@@ -0,0 +1,325 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrQuotaExceeded is returned when set would exceed quota of namespace
+	ErrQuotaExceeded = errors.New("namespace quota exceeded")
+)
+
+// BreachPolicy is behavior of quota cacher when set would exceed quota
+type BreachPolicy int
+
+const (
+	// BreachReject rejects set with ErrQuotaExceeded
+	BreachReject BreachPolicy = iota
+	// BreachEvictOldest deletes oldest keys of namespace until value fits,
+	// values bigger than maximum value size are still rejected
+	BreachEvictOldest
+	// BreachNotify stores value and only reports breach
+	BreachNotify
+)
+
+// Quota limits keys of a namespace, zero limit means no limit
+type Quota struct {
+	// MaxKeys is maximum number of keys
+	MaxKeys int
+	// MaxBytes is maximum total size of values, size is cost given by WithCost or serialized length of value
+	MaxBytes int64
+	// MaxValueSize is maximum size of a value
+	MaxValueSize int64
+	// Policy is behavior on breach, default is BreachReject
+	Policy BreachPolicy
+}
+
+// QuotaUsage is usage of namespace
+type QuotaUsage struct {
+	Keys  int
+	Bytes int64
+}
+
+// QuotaBreach is reported when set exceeds quota of namespace
+type QuotaBreach struct {
+	Namespace string
+	Key       string
+	// Reason is exceeded limit, e.g. "max keys"
+	Reason string
+	// Evicted are keys deleted to make room for value with BreachEvictOldest
+	Evicted []string
+}
+
+// namespaceUsage tracks keys of namespace in order of their first set
+type namespaceUsage struct {
+	bytes int64
+	order *list.List
+	keys  map[string]*list.Element
+}
+
+// usedKey is tracked key with size of its value
+type usedKey struct {
+	key  string
+	size int64
+}
+
+// QuotaCacher is cacher enforcing quotas of namespaces
+// usage is tracked in process until keys are deleted, so expired keys still count
+type QuotaCacher struct {
+	Cacher
+	namespace    func(key string) string
+	quotas       map[string]Quota
+	defaultQuota *Quota
+	onBreach     func(QuotaBreach)
+
+	mu    sync.Mutex
+	usage map[string]*namespaceUsage
+}
+
+// QuotaOption provides quota options
+type QuotaOption func(*QuotaCacher)
+
+// WithQuota returns option to set quota of namespace
+func WithQuota(namespace string, quota Quota) QuotaOption {
+	return func(q *QuotaCacher) {
+		q.quotas[namespace] = quota
+	}
+}
+
+// WithDefaultQuota returns option to set quota of namespaces without their own quota,
+// by default they are not limited
+func WithDefaultQuota(quota Quota) QuotaOption {
+	return func(q *QuotaCacher) {
+		q.defaultQuota = &quota
+	}
+}
+
+// WithNamespaceFunc returns option to set function returning namespace of key,
+// default is part of key before first dot, e.g. "orders" of "orders.42"
+func WithNamespaceFunc(namespace func(key string) string) QuotaOption {
+	return func(q *QuotaCacher) {
+		q.namespace = namespace
+	}
+}
+
+// WithBreachHandler returns option to report breaches of quotas, including rejected and evicting sets
+func WithBreachHandler(onBreach func(QuotaBreach)) QuotaOption {
+	return func(q *QuotaCacher) {
+		q.onBreach = onBreach
+	}
+}
+
+// Quotas returns cacher enforcing quotas of namespaces of keys in c
+func Quotas(c Cacher, options ...QuotaOption) *QuotaCacher {
+	q := &QuotaCacher{
+		Cacher: c,
+		namespace: func(key string) string {
+			namespace, _, _ := strings.Cut(key, ".")
+			return namespace
+		},
+		quotas:   map[string]Quota{},
+		onBreach: func(QuotaBreach) {},
+		usage:    map[string]*namespaceUsage{},
+	}
+
+	for _, option := range options {
+		option(q)
+	}
+
+	return q
+}
+
+// quota returns quota of namespace
+func (q *QuotaCacher) quota(namespace string) (Quota, bool) {
+	if quota, ok := q.quotas[namespace]; ok {
+		return quota, true
+	}
+	if q.defaultQuota != nil {
+		return *q.defaultQuota, true
+	}
+
+	return Quota{}, false
+}
+
+// admit checks quota of namespace of key for value of size and tracks it,
+// it returns keys which must be deleted to make room for value
+func (q *QuotaCacher) admit(key string, size int64) ([]string, error) {
+	namespace := q.namespace(key)
+	quota, ok := q.quota(namespace)
+	if !ok {
+		return nil, nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage, ok := q.usage[namespace]
+	if !ok {
+		usage = &namespaceUsage{order: list.New(), keys: map[string]*list.Element{}}
+		q.usage[namespace] = usage
+	}
+
+	previous := int64(0)
+	element, exists := usage.keys[key]
+	if exists {
+		previous = element.Value.(*usedKey).size
+	}
+
+	breach := QuotaBreach{Namespace: namespace, Key: key}
+	exceeded := func() string {
+		switch {
+		case quota.MaxValueSize > 0 && size > quota.MaxValueSize:
+			return "max value size"
+		case quota.MaxKeys > 0 && !exists && usage.order.Len() >= quota.MaxKeys:
+			return "max keys"
+		case quota.MaxBytes > 0 && usage.bytes-previous+size > quota.MaxBytes:
+			return "max bytes"
+		default:
+			return ""
+		}
+	}
+
+	reason := exceeded()
+	if reason != "" {
+		breach.Reason = reason
+
+		switch {
+		case quota.Policy == BreachNotify:
+		case quota.Policy == BreachEvictOldest && reason != "max value size":
+			for reason != "" {
+				oldest := usage.order.Front()
+				if oldest == nil || oldest == element {
+					// only the key itself is left
+					break
+				}
+				evicted := usage.order.Remove(oldest).(*usedKey)
+				delete(usage.keys, evicted.key)
+				usage.bytes -= evicted.size
+				breach.Evicted = append(breach.Evicted, evicted.key)
+				reason = exceeded()
+			}
+			if reason != "" {
+				q.onBreach(breach)
+				return breach.Evicted, fmt.Errorf("%w: %s of %s", ErrQuotaExceeded, reason, namespace)
+			}
+		default:
+			q.onBreach(breach)
+			return nil, fmt.Errorf("%w: %s of %s", ErrQuotaExceeded, reason, namespace)
+		}
+		q.onBreach(breach)
+	}
+
+	if exists {
+		element.Value.(*usedKey).size = size
+	} else {
+		usage.keys[key] = usage.order.PushBack(&usedKey{key: key, size: size})
+	}
+	usage.bytes += size - previous
+
+	return breach.Evicted, nil
+}
+
+// release stops tracking key
+func (q *QuotaCacher) release(key string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage, ok := q.usage[q.namespace(key)]
+	if !ok {
+		return
+	}
+	if element, ok := usage.keys[key]; ok {
+		usage.bytes -= usage.order.Remove(element).(*usedKey).size
+		delete(usage.keys, key)
+	}
+}
+
+// evict deletes keys evicted to make room for new values
+func (q *QuotaCacher) evict(ctx context.Context, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := q.Cacher.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Set sets key-value to cache if it fits quota of its namespace
+func (q *QuotaCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	setConfig := &SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+
+	evicted, err := q.admit(key, setConfig.CostOf(value))
+	if evictErr := q.evict(ctx, evicted); evictErr != nil {
+		return evictErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := q.Cacher.Set(ctx, key, value, options...); err != nil {
+		q.release(key)
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes value from cache and releases its quota
+func (q *QuotaCacher) Delete(ctx context.Context, key string) error {
+	if err := q.Cacher.Delete(ctx, key); err != nil {
+		return err
+	}
+	q.release(key)
+
+	return nil
+}
+
+// Load loads key-values which fit quotas of their namespaces into cache,
+// returned error joins errors of rejected keys
+func (q *QuotaCacher) Load(ctx context.Context, data map[string]any) error {
+	admitted := make(map[string]any, len(data))
+	var errs []error
+	for key, value := range data {
+		evicted, err := q.admit(key, costOf(value))
+		if evictErr := q.evict(ctx, evicted); evictErr != nil {
+			errs = append(errs, evictErr)
+		}
+		if err != nil {
+			errs = append(errs, keyError(key, err))
+			continue
+		}
+		admitted[key] = value
+	}
+
+	if err := q.Cacher.Load(ctx, admitted); err != nil {
+		for key := range admitted {
+			q.release(key)
+		}
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Usage returns usage of namespace
+func (q *QuotaCacher) Usage(namespace string) QuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	usage, ok := q.usage[namespace]
+	if !ok {
+		return QuotaUsage{}
+	}
+
+	return QuotaUsage{Keys: usage.order.Len(), Bytes: usage.bytes}
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestQuotas(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		quota        Quota
+		cost         int64
+		order        []string
+		wantErrs     int
+		wantUsage    QuotaUsage
+		wantEvicted  []string
+		wantBreaches int
+	}{
+		{name: "test reject max keys", quota: Quota{MaxKeys: 2}, order: []string{"a.1", "a.2", "a.3", "a.1"}, wantErrs: 1, wantUsage: QuotaUsage{Keys: 2, Bytes: 10}, wantBreaches: 1},
+		{name: "test reject max bytes", quota: Quota{MaxBytes: 9}, order: []string{"a.1", "a.2", "a.3"}, wantErrs: 2, wantUsage: QuotaUsage{Keys: 1, Bytes: 5}, wantBreaches: 2},
+		{name: "test reject max value size", quota: Quota{MaxValueSize: 4, Policy: BreachEvictOldest}, order: []string{"a.1"}, wantErrs: 1, wantBreaches: 1},
+		{name: "test evict oldest", quota: Quota{MaxKeys: 2, Policy: BreachEvictOldest}, order: []string{"a.1", "a.2", "a.3"}, wantUsage: QuotaUsage{Keys: 2, Bytes: 10}, wantEvicted: []string{"a.1"}, wantBreaches: 1},
+		{name: "test notify", quota: Quota{MaxKeys: 1, Policy: BreachNotify}, order: []string{"a.1", "a.2"}, wantUsage: QuotaUsage{Keys: 2, Bytes: 10}, wantBreaches: 1},
+		{name: "test reject max bytes by cost", quota: Quota{MaxBytes: 100}, cost: 60, order: []string{"a.1", "a.2"}, wantErrs: 1, wantUsage: QuotaUsage{Keys: 1, Bytes: 60}, wantBreaches: 1},
+		{name: "test other namespace not limited", quota: Quota{MaxKeys: 1}, order: []string{"b.1", "b.2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMapCacher()
+			breaches := 0
+			q := Quotas(m, WithQuota("a", tt.quota), WithBreachHandler(func(QuotaBreach) { breaches++ }))
+
+			errs := 0
+			for _, key := range tt.order {
+				var options []SetOption
+				if tt.cost > 0 {
+					options = append(options, WithCost(tt.cost))
+				}
+				if err := q.Set(ctx, key, "value", options...); err != nil {
+					if !errors.Is(err, ErrQuotaExceeded) {
+						t.Errorf("Set() error = %v, want %v", err, ErrQuotaExceeded)
+					}
+					errs++
+				}
+			}
+
+			if errs != tt.wantErrs {
+				t.Errorf("Set() errors = %v, want %v", errs, tt.wantErrs)
+			}
+			if got := q.Usage("a"); got != tt.wantUsage {
+				t.Errorf("Usage() = %v, want %v", got, tt.wantUsage)
+			}
+			for _, key := range tt.wantEvicted {
+				if _, ok := m.data[key]; ok {
+					t.Errorf("Set() did not evict %v", key)
+				}
+			}
+			if breaches != tt.wantBreaches {
+				t.Errorf("Set() breaches = %v, want %v", breaches, tt.wantBreaches)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/albinzx/cache/internal"
+)
+
+var (
+	// ErrRateLimited is returned when operation is shed by rate limiter
+	ErrRateLimited = errors.New("cache rate limit exceeded")
+)
+
+// rateLimited is cacher decorated with token bucket rate limiter
+type rateLimited struct {
+	Cacher
+	bucket *internal.TokenBucket
+	shed   bool
+}
+
+// RateLimitOption provides rate limiter options
+type RateLimitOption func(*rateLimited)
+
+// WithShedding returns option to fail excess operations with ErrRateLimited
+// instead of queuing them until a token is available
+func WithShedding() RateLimitOption {
+	return func(r *rateLimited) {
+		r.shed = true
+	}
+}
+
+// RateLimited returns cacher which allows at most opsPerSecond operations to c
+// with bursts up to burst operations, by default excess operations wait for their turn
+// it panics if opsPerSecond is not positive or burst is less than 1, as limiter would never allow operation
+func RateLimited(c Cacher, opsPerSecond float64, burst int, options ...RateLimitOption) Cacher {
+	if opsPerSecond <= 0 {
+		panic("cache: non-positive rate of RateLimited")
+	}
+	if burst < 1 {
+		panic("cache: burst of RateLimited less than 1")
+	}
+
+	r := &rateLimited{
+		Cacher: c,
+		bucket: internal.NewTokenBucket(opsPerSecond, burst),
+	}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// acquire takes a token for one operation
+func (r *rateLimited) acquire(ctx context.Context) error {
+	if r.shed {
+		if !r.bucket.Allow() {
+			return ErrRateLimited
+		}
+
+		return nil
+	}
+
+	return r.bucket.Wait(ctx)
+}
+
+// Set sets key-value to cache
+func (r *rateLimited) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	if err := r.acquire(ctx); err != nil {
+		return err
+	}
+
+	return r.Cacher.Set(ctx, key, value, options...)
+}
+
+// Get gets value from cache
+func (r *rateLimited) Get(ctx context.Context, key string) (any, error) {
+	if err := r.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.Cacher.Get(ctx, key)
+}
+
+// Delete deletes value from cache
+func (r *rateLimited) Delete(ctx context.Context, key string) error {
+	if err := r.acquire(ctx); err != nil {
+		return err
+	}
+
+	return r.Cacher.Delete(ctx, key)
+}
+
+// Load loads multiple key-values into cache
+func (r *rateLimited) Load(ctx context.Context, data map[string]any) error {
+	if err := r.acquire(ctx); err != nil {
+		return err
+	}
+
+	return r.Cacher.Load(ctx, data)
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRateLimited(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []RateLimitOption
+		wantErr error
+	}{
+		{
+			name:    "test shedding excess operation",
+			options: []RateLimitOption{WithShedding()},
+			wantErr: ErrRateLimited,
+		},
+		{
+			name:    "test queuing excess operation",
+			options: nil,
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := RateLimited(newMapCacher(), 0.001, 1, tt.options...)
+			if err := c.Set(context.Background(), "key", "value"); err != nil {
+				t.Errorf("RateLimited().Set() error = %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if _, err := c.Get(ctx, "key"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RateLimited().Get() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRateLimited_InvalidLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		opsPerSecond float64
+		burst        int
+	}{
+		{name: "test zero rate", opsPerSecond: 0, burst: 1},
+		{name: "test negative rate", opsPerSecond: -1, burst: 1},
+		{name: "test zero burst", opsPerSecond: 1, burst: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RateLimited(%v, %v) did not panic", tt.opsPerSecond, tt.burst)
+				}
+			}()
+			RateLimited(newMapCacher(), tt.opsPerSecond, tt.burst)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/albinzx/cache/internal"
+)
+
+var (
+	// ErrReadOnly is returned when writing to read only persistence storage
+	ErrReadOnly = errors.New("persistence storage is read only")
+)
+
+// LoadFunc loads value of key, nil value means key is not found
+type LoadFunc func(ctx context.Context, key string) (any, error)
+
+// loaderPersister is read only persister which loads values with load function
+type loaderPersister struct {
+	load  LoadFunc
+	group internal.Group
+}
+
+// Save returns ErrReadOnly
+func (l *loaderPersister) Save(context.Context, string, any) error {
+	return ErrReadOnly
+}
+
+// SelectOne loads value of key, sharing result with concurrent loads of the same key
+// context of the first caller is used for the shared load
+func (l *loaderPersister) SelectOne(ctx context.Context, key string) (any, error) {
+	value, err, _ := l.group.Do(key, func() (any, error) {
+		return l.load(ctx, key)
+	})
+
+	return value, err
+}
+
+// SelectAll returns no values since load function can not enumerate keys
+func (l *loaderPersister) SelectAll(context.Context) (map[string]any, error) {
+	return map[string]any{}, nil
+}
+
+// Delete returns ErrReadOnly
+func (l *loaderPersister) Delete(context.Context, string) error {
+	return ErrReadOnly
+}
+
+// Close does nothing
+func (l *loaderPersister) Close() error {
+	return nil
+}
+
+// NewReadOnly creates a new read-through cache which loads missing values with load function
+// concurrent loads of the same key are collapsed into a single load
+// pattern is always ReadThrough, so Set and Delete only change cache
+func NewReadOnly(cacher Cacher, load LoadFunc, options ...Option) (*PatternedCache, error) {
+	options = append(options, WithPattern(&ReadThrough{}))
+
+	return New(cacher, &loaderPersister{load: load}, options...)
+}
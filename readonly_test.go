@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewReadOnly(t *testing.T) {
+	var loads atomic.Int32
+	cacher := newMapCacher()
+	c, _ := NewReadOnly(cacher, func(_ context.Context, key string) (any, error) {
+		loads.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return "value of " + key, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, _ := c.Get(context.Background(), "key"); got != "value of key" {
+				t.Errorf("Get() = %v, want %v", got, "value of key")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := loads.Load(); got != 1 {
+		t.Errorf("Get() loads = %v, want %v", got, 1)
+	}
+	if got := cacher.data["key"]; got != "value of key" {
+		t.Errorf("Get() cached = %v, want %v", got, "value of key")
+	}
+
+	if err := c.Delete(context.Background(), "key"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is error of panic recovered in asynchronous work
+type PanicError struct {
+	// Value is value passed to panic
+	Value any
+	// Stack is stack trace of panicking goroutine
+	Stack []byte
+}
+
+// Error returns panic value as error message
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// RecoverTo recovers panic and passes it as *PanicError to report, report may be nil
+// it must be deferred directly, e.g. defer cache.RecoverTo(report)
+func RecoverTo(report func(error)) {
+	if r := recover(); r != nil {
+		if report != nil {
+			report(&PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}
+}
+
+// WithErrorReporter returns option to report errors and recovered panics of asynchronous work,
+// e.g. write-behind persistence, by default they are only logged
+func WithErrorReporter(report func(error)) Option {
+	return func(c *PatternedCache) {
+		c.reporter = report
+	}
+}
+
+// reporterFrom returns function reporting asynchronous errors to reporter and logger of context scope
+func reporterFrom(ctx context.Context, op, key string) func(error) {
+	s := scopeFrom(ctx)
+
+	return func(err error) {
+		s.logger.Error(ctx, "asynchronous operation failed", op, key, err)
+
+		if s.reporter != nil {
+			s.reporter(err)
+		}
+	}
+}
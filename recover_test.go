@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// panicPersister panics on save and delete
+type panicPersister struct {
+	mapPersister
+}
+
+func (p *panicPersister) Save(context.Context, string, any) error {
+	panic("save")
+}
+
+func (p *panicPersister) Delete(context.Context, string) error {
+	panic("delete")
+}
+
+func TestWithErrorReporter(t *testing.T) {
+	tests := []struct {
+		name      string
+		persister Persister
+		operation func(*PatternedCache)
+		want      string
+	}{
+		{
+			name:      "test save error",
+			persister: &mapPersister{data: map[string]any{}, saveErr: errors.New("failed")},
+			operation: func(c *PatternedCache) {
+				_ = c.Set(context.Background(), "key", "value")
+			},
+			want: "failed",
+		},
+		{
+			name:      "test save panic",
+			persister: &panicPersister{},
+			operation: func(c *PatternedCache) {
+				_ = c.Set(context.Background(), "key", "value")
+			},
+			want: "panic: save",
+		},
+		{
+			name:      "test delete panic",
+			persister: &panicPersister{},
+			operation: func(c *PatternedCache) {
+				_ = c.Delete(context.Background(), "key")
+			},
+			want: "panic: delete",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reported := make(chan error, 1)
+			c, _ := New(newMapCacher(), tt.persister, WithPattern(&WriteBehind{}), WithErrorReporter(func(err error) {
+				reported <- err
+			}))
+
+			tt.operation(c)
+
+			select {
+			case err := <-reported:
+				if err.Error() != tt.want {
+					t.Errorf("WithErrorReporter() error = %v, want %v", err, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("WithErrorReporter() error not reported")
+			}
+		})
+	}
+}
+
+func TestRecoverTo(t *testing.T) {
+	var got error
+	func() {
+		defer RecoverTo(func(err error) { got = err })
+		panic("boom")
+	}()
+
+	var perr *PanicError
+	if !errors.As(got, &perr) || perr.Value != "boom" || len(perr.Stack) == 0 {
+		t.Errorf("RecoverTo() error = %v, want %v", got, "panic: boom")
+	}
+
+	func() {
+		defer RecoverTo(nil)
+		panic("boom")
+	}()
+}
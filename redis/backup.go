@@ -0,0 +1,19 @@
+package redis
+
+import (
+	"context"
+	"io"
+
+	"github.com/albinzx/cache"
+)
+
+// Backup writes all keys with their values and remaining ttl to w and returns number of written keys,
+// see cache.Backup, values other than []byte and string are gob encoded so custom types must be registered with gob
+func (c *Cacher) Backup(ctx context.Context, w io.Writer) (int, error) {
+	return cache.Backup(ctx, c, w)
+}
+
+// Restore stores all keys of backup read from r and returns number of restored keys, see cache.Restore
+func (c *Cacher) Restore(ctx context.Context, r io.Reader) (int, error) {
+	return cache.Restore(ctx, c, r)
+}
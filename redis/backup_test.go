@@ -0,0 +1,102 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+	"github.com/go-redis/redismock/v9"
+)
+
+func TestCacher_BackupRestore(t *testing.T) {
+	ctx := context.Background()
+
+	client, mock := redismock.NewClientMock()
+	mock.ExpectScan(0, "test.*", scanCount).SetVal([]string{"test.a", "test.b", "test.gone"}, 0)
+	mock.ExpectPTTL("test.a").SetVal(time.Minute)
+	mock.ExpectGet("test.a").SetVal("one")
+	mock.ExpectPTTL("test.b").SetVal(-1)
+	mock.ExpectGet("test.b").SetVal("two")
+	// key expired between scan and get is skipped
+	mock.ExpectPTTL("test.gone").SetVal(-2)
+	mock.ExpectGet("test.gone").RedisNil()
+
+	c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}}
+	var buf bytes.Buffer
+	written, err := c.Backup(ctx, &buf)
+	if err != nil || written != 2 {
+		t.Fatalf("Backup() = %d, %v, want 2", written, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	client, mock = redismock.NewClientMock()
+	// remaining ttl is ttl of backup less time passed since backup
+	mock.CustomMatch(remainingTTL(time.Minute)).ExpectSet("copy.a", "one", time.Minute).SetVal("OK")
+	mock.ExpectSet("copy.b", "two", 0).SetVal("OK")
+
+	restored := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "copy"}}
+	n, err := restored.Restore(ctx, &buf)
+	if err != nil || n != 2 {
+		t.Errorf("Restore() = %d, %v, want 2", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCacher_BackupInternalKeysAndHashes(t *testing.T) {
+	ctx := context.Background()
+
+	client, mock := redismock.NewClientMock()
+	// sets of tags and groups are not backed up and hash is read as map
+	mock.ExpectScan(0, "test.*", scanCount).SetVal([]string{"test.a", "test.__tag:t", "test.__group:g", "test.h"}, 0)
+	mock.ExpectPTTL("test.a").SetVal(-1)
+	mock.ExpectGet("test.a").SetVal("one")
+	mock.ExpectPTTL("test.h").SetVal(-1)
+	mock.ExpectGet("test.h").SetErr(errWrongType)
+	mock.ExpectHGetAll("test.h").SetVal(map[string]string{"name": `"two"`})
+
+	c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}, hashStorage: true}
+	var buf bytes.Buffer
+	written, err := c.Backup(ctx, &buf)
+	if err != nil || written != 2 {
+		t.Fatalf("Backup() = %d, %v, want 2", written, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	client, mock = redismock.NewClientMock()
+	mock.ExpectSet("copy.a", "one", 0).SetVal("OK")
+	mock.ExpectTxPipeline()
+	mock.ExpectDel("copy.h").SetVal(0)
+	mock.ExpectHSet("copy.h", "name", []byte(`"two"`)).SetVal(1)
+	mock.ExpectTxPipelineExec()
+
+	restored := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "copy"}, hashStorage: true}
+	n, err := restored.Restore(ctx, &buf)
+	if err != nil || n != 2 {
+		t.Errorf("Restore() = %d, %v, want 2", n, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// remainingTTL returns matcher of SET with ttl in milliseconds not longer than ttl
+func remainingTTL(ttl time.Duration) redismock.CustomMatch {
+	return func(expected, actual []interface{}) error {
+		if len(actual) != 5 || fmt.Sprint(actual[:3]) != fmt.Sprint(expected[:3]) || actual[3] != "px" {
+			return fmt.Errorf("command %v, want %v", actual, expected)
+		}
+		if px, ok := actual[4].(int64); !ok || px <= 0 || px > ttl.Milliseconds() {
+			return fmt.Errorf("ttl %v, want at most %v", actual[4], ttl)
+		}
+		return nil
+	}
+}
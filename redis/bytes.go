@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/albinzx/cache"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// WithBytes returns option to get values as []byte instead of string when marshaller is not set
+func WithBytes() Option {
+	return WithRepresentation(cache.RepresentBytes)
+}
+
+// SetBytes stores data in cache as is, bypassing marshaller
+func (c *Cacher) SetBytes(ctx context.Context, key string, data []byte, setOptions ...cache.SetOption) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "set", key, start, err) }(time.Now())
+
+	setConfig := &cache.SetConfiguration{
+		TTL: c.ttl}
+	for _, option := range setOptions {
+		option(setConfig)
+	}
+
+	return c.client.Set(ctx, c.prefix.Prefix(key), data, setConfig.TTL).Err()
+}
+
+// GetBytes retrieves data from cache as is, bypassing marshaller
+// nil data is returned when key does not exist
+func (c *Cacher) GetBytes(ctx context.Context, key string) (_ []byte, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "get", key, start, err) }(time.Now())
+
+	value := c.client.Get(ctx, c.prefix.Prefix(key))
+	if errors.Is(value.Err(), goredis.Nil) {
+		return nil, nil
+	}
+	if value.Err() != nil {
+		return nil, value.Err()
+	}
+
+	return []byte(value.Val()), nil
+}
@@ -0,0 +1,85 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+	"github.com/go-redis/redismock/v9"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func TestCacher_GetBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		expect  func(redismock.ClientMock)
+		want    []byte
+		wantErr bool
+	}{
+		{
+			name:   "test get missing",
+			key:    "key",
+			expect: func(mock redismock.ClientMock) { mock.ExpectGet("key").SetErr(goredis.Nil) },
+			want:   nil,
+		},
+		{
+			name:   "test get bytes",
+			key:    "key",
+			expect: func(mock redismock.ClientMock) { mock.ExpectGet("key").SetVal("value") },
+			want:   []byte("value"),
+		},
+		{
+			name:   "test get empty bytes",
+			key:    "key",
+			expect: func(mock redismock.ClientMock) { mock.ExpectGet("key").SetVal("") },
+			want:   []byte{},
+		},
+		{
+			name:    "test get error",
+			key:     "key",
+			expect:  func(mock redismock.ClientMock) { mock.ExpectGet("key").SetErr(errors.New("failed")) },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+			c := New(WithRedisClient(client))
+
+			got, err := c.GetBytes(context.Background(), tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Cacher.GetBytes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cacher.GetBytes() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetBytes() expectation were not met, %v", err)
+			}
+		})
+	}
+}
+
+func TestCacher_SetBytes(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.ExpectSet("key", []byte("value"), time.Second).SetVal("OK")
+	mock.ExpectGet("key").SetVal("value")
+	c := &Cacher{client: client, ttl: time.Second, prefix: &internal.NoPrefix{}}
+	WithBytes()(c)
+
+	if err := c.SetBytes(context.Background(), "key", []byte("value")); err != nil {
+		t.Errorf("Cacher.SetBytes() error = %v", err)
+	}
+	if got, _ := c.Get(context.Background(), "key"); !reflect.DeepEqual(got, []byte("value")) {
+		t.Errorf("Cacher.Get() = %v, want %v", got, []byte("value"))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("SetBytes() expectation were not met, %v", err)
+	}
+}
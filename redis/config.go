@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/albinzx/cache"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func init() {
+	cache.RegisterBackend("redis", fromConfig)
+}
+
+// fromConfig returns redis cacher of backend config
+// multiple addresses connect to redis cluster
+func fromConfig(config cache.BackendConfig, marshaller cache.Marshaller) (cache.Cacher, error) {
+	if len(config.Addresses) == 0 {
+		return nil, fmt.Errorf("%w: redis backend has no addresses", cache.ErrInvalidOption)
+	}
+
+	represent, err := cache.ParseRepresentation(config.Representation)
+	if err != nil {
+		return nil, err
+	}
+
+	client := goredis.NewUniversalClient(&goredis.UniversalOptions{
+		Addrs:    config.Addresses,
+		Username: config.Username,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	options := []Option{WithRedisClient(client), WithName(config.Name), WithTTL(time.Duration(config.TTL)), WithRepresentation(represent)}
+	if marshaller != nil {
+		options = append(options, WithMarshaller(marshaller))
+	}
+
+	return NewE(options...)
+}
@@ -0,0 +1,36 @@
+package redis
+
+import (
+	"net"
+	"testing"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+	"github.com/albinzx/cache/memory"
+	"github.com/albinzx/cache/server/resp"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// newServedCacher returns redis cacher connected to resp server of memory cacher
+func newServedCacher(tb testing.TB) cache.Cacher {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("Listen() error = %v", err)
+	}
+
+	server := resp.New(memory.New())
+	go func() { _ = server.Serve(listener) }()
+	tb.Cleanup(func() { _ = server.Close() })
+
+	client := goredis.NewClient(&goredis.Options{Addr: listener.Addr().String(), Protocol: 2, DisableIndentity: true})
+
+	return New(WithRedisClient(client))
+}
+
+func TestConformance(t *testing.T) {
+	cachetest.Conformance(t, newServedCacher)
+}
+
+func BenchmarkCacher(b *testing.B) {
+	cachetest.Benchmark(b, newServedCacher)
+}
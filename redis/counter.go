@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// incrementScript increments key by ARGV[1] and sets ttl of ARGV[2] milliseconds on key without ttl,
+// so window of counter starts when it is created
+var incrementScript = goredis.NewScript(`
+local value = redis.call("INCRBY", KEYS[1], ARGV[1])
+if tonumber(ARGV[2]) > 0 and redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return value
+`)
+
+// Increment adds delta to value of key with INCRBY and returns new value, key which does not exist starts from 0
+// and expires after ttl of options, ttl of existing key is kept
+// counters are stored as decimal strings, so they can be read by Get, but not with marshaller
+func (c *Cacher) Increment(ctx context.Context, key string, delta int64, setOptions ...cache.SetOption) (value int64, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "increment", key, start, err) }(time.Now())
+
+	setConfig := &cache.SetConfiguration{TTL: c.ttl}
+	for _, option := range setOptions {
+		option(setConfig)
+	}
+
+	value, err = incrementScript.Run(ctx, c.client, []string{c.prefix.Prefix(key)}, delta, setConfig.TTL.Milliseconds()).Int64()
+	if err != nil && isNotInteger(err) {
+		return 0, fmt.Errorf("%s: %w", key, cache.ErrNotInteger)
+	}
+
+	return value, err
+}
+
+// Decrement subtracts delta from value of key and returns new value
+func (c *Cacher) Decrement(ctx context.Context, key string, delta int64, setOptions ...cache.SetOption) (int64, error) {
+	return c.Increment(ctx, key, -delta, setOptions...)
+}
+
+// isNotInteger reports whether err is redis error of value which is not an integer
+func isNotInteger(err error) bool {
+	var redisErr goredis.Error
+	return errors.As(err, &redisErr) && strings.Contains(redisErr.Error(), "not an integer")
+}
@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+	"github.com/go-redis/redismock/v9"
+)
+
+func TestCacher_Increment(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		options []cache.SetOption
+		expect  func(redismock.ClientMock)
+		want    int64
+		wantErr error
+	}{
+		{
+			name: "test increment without ttl",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(incrementScript.Hash(), []string{"key"}, int64(2), int64(0)).SetVal(int64(2))
+			},
+			want: 2,
+		},
+		{
+			name: "test ttl of first increment is global ttl",
+			ttl:  time.Minute,
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(incrementScript.Hash(), []string{"key"}, int64(2), int64(60000)).SetVal(int64(2))
+			},
+			want: 2,
+		},
+		{
+			name:    "test ttl of first increment is ttl option",
+			ttl:     time.Minute,
+			options: []cache.SetOption{cache.WithTTL(time.Second)},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(incrementScript.Hash(), []string{"key"}, int64(2), int64(1000)).SetVal(int64(5))
+			},
+			want: 5,
+		},
+		{
+			name: "test not integer",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(incrementScript.Hash(), []string{"key"}, int64(2), int64(0)).
+					SetErr(redisError("ERR value is not an integer or out of range"))
+			},
+			wantErr: cache.ErrNotInteger,
+		},
+		{
+			name: "test redis error",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectEvalSha(incrementScript.Hash(), []string{"key"}, int64(2), int64(0)).SetErr(failed)
+			},
+			wantErr: failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}, ttl: tt.ttl}
+			got, err := c.Increment(context.Background(), "key", 2, tt.options...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Increment() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Increment() = %d, want %d", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_Decrement(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	// decrement is INCRBY of negative delta
+	mock.ExpectEvalSha(incrementScript.Hash(), []string{"test.key"}, int64(-3), int64(1000)).SetVal(int64(-3))
+
+	c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}}
+	got, err := c.Decrement(context.Background(), "key", 3, cache.WithTTL(time.Second))
+	if err != nil || got != -3 {
+		t.Errorf("Decrement() = %d, %v, want -3", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
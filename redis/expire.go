@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/albinzx/cache"
+)
+
+// expiredChannels is pattern of channels of expired key events of all databases
+const expiredChannels = "__keyevent@*__:expired"
+
+// evictedChannels is pattern of channels of key events of keys evicted by maxmemory policy of all databases
+const evictedChannels = "__keyevent@*__:evicted"
+
+// NotifyExpired calls fn with keys of this cacher which expire until returned function is called
+// it uses keyspace notifications, which must be enabled on redis server with
+// notify-keyspace-events including "Ex", notifications are delivered at most once
+// and redis reports expiration when expired key is accessed or found by its active expiration
+func (c *Cacher) NotifyExpired(ctx context.Context, fn func(key string)) (func(), error) {
+	return c.notify(ctx, func(eviction cache.Eviction) { fn(eviction.Key) }, expiredChannels)
+}
+
+// NotifyEvicted calls fn with keys of this cacher which expire or are evicted by maxmemory policy
+// until returned function is called, values of keys are not known, it uses keyspace notifications,
+// which must be enabled on redis server with notify-keyspace-events including "Exe"
+func (c *Cacher) NotifyEvicted(ctx context.Context, fn func(cache.Eviction)) (func(), error) {
+	return c.notify(ctx, fn, expiredChannels, evictedChannels)
+}
+
+// notify subscribes to key event channels and calls fn with evictions of keys of this cacher
+func (c *Cacher) notify(ctx context.Context, fn func(cache.Eviction), channels ...string) (func(), error) {
+	pubsub := c.client.PSubscribe(ctx, channels...)
+	for range channels {
+		if _, err := pubsub.Receive(ctx); err != nil {
+			_ = pubsub.Close()
+			return nil, err
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for msg := range pubsub.Channel() {
+			key := c.prefix.Unprefix(msg.Payload)
+			if c.prefix.Prefix(key) != msg.Payload {
+				// key of another cacher
+				continue
+			}
+
+			reason := cache.EvictionExpired
+			if strings.HasSuffix(msg.Channel, ":evicted") {
+				reason = cache.EvictionCapacity
+			}
+			fn(cache.Eviction{Key: key, Reason: reason})
+		}
+	}()
+
+	return func() {
+		_ = pubsub.Close()
+		<-done
+	}, nil
+}
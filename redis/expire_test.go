@@ -0,0 +1,167 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// newPubSubServer starts server replying to PSUBSCRIBE with reply and then publishing messages of channels,
+// messages are pairs of channel and payload, it returns client of server
+func newPubSubServer(t *testing.T, reply func(pattern string, count int) string, messages ...string) goredis.UniversalClient {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		for {
+			args, err := readCommand(r)
+			if err != nil {
+				return
+			}
+			if !strings.EqualFold(args[0], "psubscribe") {
+				// other commands, e.g. HELLO of handshake, are unknown
+				_, _ = io.WriteString(conn, "-ERR unknown command\r\n")
+				continue
+			}
+			for i, pattern := range args[1:] {
+				_, _ = io.WriteString(conn, reply(pattern, i+1))
+			}
+			for i := 0; i+1 < len(messages); i += 2 {
+				_, _ = io.WriteString(conn, bulkArray("pmessage", args[1], messages[i], messages[i+1]))
+			}
+		}
+	}()
+
+	client := goredis.NewClient(&goredis.Options{Addr: listener.Addr().String(), Protocol: 2, DisableIndentity: true})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+// readCommand reads command sent as array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		if _, err := r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+
+	return args, nil
+}
+
+// bulkArray returns array of bulk strings
+func bulkArray(values ...string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(values))
+	for _, value := range values {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(value), value)
+	}
+
+	return b.String()
+}
+
+// subscribed replies to PSUBSCRIBE of pattern as redis
+func subscribed(pattern string, count int) string {
+	return fmt.Sprintf("*3\r\n$10\r\npsubscribe\r\n$%d\r\n%s\r\n:%d\r\n", len(pattern), pattern, count)
+}
+
+func TestCacher_NotifyEvicted(t *testing.T) {
+	client := newPubSubServer(t, subscribed,
+		"__keyevent@0__:expired", "test.a",
+		"__keyevent@0__:expired", "other.b",
+		"__keyevent@0__:evicted", "test.c",
+	)
+	c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}}
+
+	evictions := make(chan cache.Eviction, 3)
+	stop, err := c.NotifyEvicted(context.Background(), func(eviction cache.Eviction) { evictions <- eviction })
+	if err != nil {
+		t.Fatalf("NotifyEvicted() error = %v", err)
+	}
+	defer stop()
+
+	// key of another cacher is skipped
+	want := []cache.Eviction{{Key: "a", Reason: cache.EvictionExpired}, {Key: "c", Reason: cache.EvictionCapacity}}
+	var got []cache.Eviction
+	for range want {
+		select {
+		case eviction := <-evictions:
+			got = append(got, eviction)
+		case <-time.After(time.Second):
+			t.Fatalf("evictions = %v, want %v", got, want)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("evictions = %v, want %v", got, want)
+	}
+}
+
+func TestCacher_NotifyExpired(t *testing.T) {
+	client := newPubSubServer(t, subscribed, "__keyevent@0__:expired", "a")
+	c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+
+	keys := make(chan string, 1)
+	stop, err := c.NotifyExpired(context.Background(), func(key string) { keys <- key })
+	if err != nil {
+		t.Fatalf("NotifyExpired() error = %v", err)
+	}
+
+	select {
+	case key := <-keys:
+		if key != "a" {
+			t.Errorf("expired key = %s, want a", key)
+		}
+	case <-time.After(time.Second):
+		t.Error("expired key is not notified")
+	}
+
+	// stop waits for notifications to finish
+	stop()
+}
+
+func TestCacher_NotifyError(t *testing.T) {
+	client := newPubSubServer(t, func(string, int) string { return "-ERR unknown command 'psubscribe'\r\n" })
+	c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+
+	if _, err := c.NotifyExpired(context.Background(), func(string) {}); err == nil {
+		t.Error("NotifyExpired() error = nil, want error of subscription")
+	}
+}
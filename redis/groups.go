@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// groupPrefix starts keys of sorted sets of keys of groups
+const groupPrefix = "__group:"
+
+// groupKey returns key of sorted set of keys of group
+func (c *Cacher) groupKey(group string) string {
+	return c.prefix.Prefix(groupPrefix + group)
+}
+
+// AddToGroup adds key to sorted set of group scored by time of addition with ZADD, so members are ordered
+// by their last addition, group expires after the longest ttl of options of its additions and does not expire
+// once key is added without ttl, options without ttl use ttl of cacher
+func (c *Cacher) AddToGroup(ctx context.Context, group, key string, setOptions ...cache.SetOption) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "add_to_group", key, start, err) }(time.Now())
+
+	setConfig := &cache.SetConfiguration{TTL: c.ttl}
+	for _, option := range setOptions {
+		option(setConfig)
+	}
+
+	score := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	return addMemberScript.Run(ctx, c.client, []string{c.groupKey(group)}, setConfig.TTL.Milliseconds(), "ZADD", score, key).Err()
+}
+
+// RemoveFromGroup removes keys from sorted set of group with ZREM, values of keys are kept
+func (c *Cacher) RemoveFromGroup(ctx context.Context, group string, keys ...string) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "remove_from_group", strings.Join(keys, ","), start, err)
+	}(time.Now())
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	members := make([]any, len(keys))
+	for i, key := range keys {
+		members[i] = key
+	}
+
+	return c.client.ZRem(ctx, c.groupKey(group), members...).Err()
+}
+
+// GroupMembers returns keys of group in order they are added, nil is returned if group does not exist
+func (c *Cacher) GroupMembers(ctx context.Context, group string) ([]string, error) {
+	keys, err := c.client.ZRange(ctx, c.groupKey(group), 0, -1).Result()
+	if err != nil || len(keys) == 0 {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// InvalidateGroup deletes values of members of group and sorted set of group
+func (c *Cacher) InvalidateGroup(ctx context.Context, group string) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "invalidate_group", group, start, err) }(time.Now())
+
+	keys, err := c.GroupMembers(ctx, group)
+	if err != nil {
+		return err
+	}
+	if err := c.DeleteMany(ctx, keys...); err != nil {
+		return err
+	}
+
+	return c.client.Del(ctx, c.groupKey(group)).Err()
+}
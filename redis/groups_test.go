@@ -0,0 +1,123 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+	"github.com/go-redis/redismock/v9"
+)
+
+func TestCacher_AddToGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		options []cache.SetOption
+		wantTTL int64
+	}{
+		{name: "test group without ttl", wantTTL: 0},
+		{name: "test group with global ttl", ttl: time.Minute, wantTTL: 60000},
+		{name: "test group with ttl option", ttl: time.Minute, options: []cache.SetOption{cache.WithTTL(time.Second)}, wantTTL: 1000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			// script extends ttl of group to ttl of member, score is time of addition
+			mock.Regexp().ExpectEvalSha(addMemberScript.Hash(), []string{"test.__group:g"}, tt.wantTTL, "ZADD", `^\d+$`, "key").SetVal(int64(1))
+
+			c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}, ttl: tt.ttl}
+			if err := c.AddToGroup(context.Background(), "g", "key", tt.options...); err != nil {
+				t.Errorf("AddToGroup() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_RemoveFromGroup(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.ExpectZRem("__group:g", "a", "b").SetVal(2)
+
+	c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+	if err := c.RemoveFromGroup(context.Background(), "g", "a", "b"); err != nil {
+		t.Errorf("RemoveFromGroup() error = %v", err)
+	}
+	// removing no keys sends no command
+	if err := c.RemoveFromGroup(context.Background(), "g"); err != nil {
+		t.Errorf("RemoveFromGroup() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCacher_GroupMembers(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.ExpectZRange("__group:g", 0, -1).SetVal([]string{"a", "b"})
+	mock.ExpectZRange("__group:missing", 0, -1).SetVal([]string{})
+
+	c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+	if got, err := c.GroupMembers(context.Background(), "g"); err != nil || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("GroupMembers() = %v, %v, want [a b]", got, err)
+	}
+	if got, err := c.GroupMembers(context.Background(), "missing"); err != nil || got != nil {
+		t.Errorf("GroupMembers() of missing group = %v, %v, want nil", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCacher_InvalidateGroup(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		expect  func(redismock.ClientMock)
+		wantErr error
+	}{
+		{
+			name: "test invalidate group",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectZRange("__group:g", 0, -1).SetVal([]string{"a", "b"})
+				mock.ExpectDel("a", "b").SetVal(2)
+				mock.ExpectDel("__group:g").SetVal(1)
+			},
+		},
+		{
+			name: "test invalidate missing group",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectZRange("__group:g", 0, -1).SetVal([]string{})
+				mock.ExpectDel("__group:g").SetVal(0)
+			},
+		},
+		{
+			name: "test delete error keeps group",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectZRange("__group:g", 0, -1).SetVal([]string{"a"})
+				mock.ExpectDel("a").SetErr(failed)
+			},
+			wantErr: failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+			if err := c.InvalidateGroup(context.Background(), "g"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("InvalidateGroup() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
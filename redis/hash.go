@@ -0,0 +1,167 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// setFieldScript sets field ARGV[1] of hash KEYS[1] to ARGV[2] and sets ttl of ARGV[3] milliseconds
+// on hash which is created, so ttl of existing hash is kept
+var setFieldScript = goredis.NewScript(`
+local exists = redis.call("EXISTS", KEYS[1])
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+if exists == 0 and tonumber(ARGV[3]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[3])
+end
+return exists
+`)
+
+// WithHashStorage returns option to store maps with string keys and structs as redis hashes
+// with field of every key or exported struct field, so fields are read and updated by GetField,
+// SetField and DeleteField without the whole value, field values are stored as JSON
+// Get and GetWithTTL return hash as map[string]any, or as value unmarshalled from JSON object of fields
+// if marshaller is set, hashes are read by cachers without the option too, so they are backed up and copied,
+// values set with NX, XX or KEEPTTL are not stored as hashes and GetMany does not return hashes
+func WithHashStorage() Option {
+	return func(cache *Cacher) {
+		cache.hashStorage = true
+	}
+}
+
+// isStructured reports whether value is map with string keys or struct, or pointer to one
+func isStructured(value any) bool {
+	v := reflect.ValueOf(value)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		return v.Type().Key().Kind() == reflect.String
+	case reflect.Struct:
+		return true
+	default:
+		return false
+	}
+}
+
+// setHash replaces key with hash of fields of value
+func (c *Cacher) setHash(ctx context.Context, key string, value any, setConfig *cache.SetConfiguration) error {
+	object, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(object, &fields); err != nil {
+		return err
+	}
+
+	values := make([]any, 0, 2*len(fields))
+	for field, value := range fields {
+		values = append(values, field, []byte(value))
+	}
+
+	prefixed := c.prefix.Prefix(key)
+	_, err = c.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, prefixed)
+		if len(values) > 0 {
+			pipe.HSet(ctx, prefixed, values...)
+		}
+		if setConfig.TTL > 0 {
+			pipe.PExpire(ctx, prefixed, setConfig.TTL)
+		}
+		c.addTags(ctx, pipe, key, setConfig.TTL, setConfig.Tags)
+		return nil
+	})
+
+	return err
+}
+
+// getHash returns value of hash of key, nil is returned if key does not exist
+func (c *Cacher) getHash(ctx context.Context, key string) (any, error) {
+	fields, err := c.client.HGetAll(ctx, c.prefix.Prefix(key)).Result()
+	if err != nil || len(fields) == 0 {
+		return nil, err
+	}
+
+	raw := make(map[string]json.RawMessage, len(fields))
+	for field, value := range fields {
+		raw[field] = json.RawMessage(value)
+	}
+	object, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.marshaller != nil {
+		return c.marshaller.Unmarshal(object)
+	}
+
+	var value map[string]any
+	if err := json.Unmarshal(object, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// GetField retrieves value of field of hash of key with HGET, nil is returned if key or field does not exist
+func (c *Cacher) GetField(ctx context.Context, key, field string) (value any, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "get_field", key, start, err) }(time.Now())
+
+	data, err := c.client.HGet(ctx, c.prefix.Prefix(key), field).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		if c.notFound {
+			return nil, cache.ErrNotFound
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, &cache.KeyError{Key: key, Err: err}
+	}
+
+	return value, nil
+}
+
+// SetField sets value of field of hash of key with HSET, key which does not exist is created
+// with ttl of options, ttl of existing key is kept
+func (c *Cacher) SetField(ctx context.Context, key, field string, value any, setOptions ...cache.SetOption) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "set_field", key, start, err) }(time.Now())
+
+	setConfig := &cache.SetConfiguration{TTL: c.ttl}
+	for _, option := range setOptions {
+		option(setConfig)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return &cache.KeyError{Key: key, Err: err}
+	}
+
+	return setFieldScript.Run(ctx, c.client, []string{c.prefix.Prefix(key)}, field, data, setConfig.TTL.Milliseconds()).Err()
+}
+
+// DeleteField deletes field of hash of key with HDEL
+func (c *Cacher) DeleteField(ctx context.Context, key, field string) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "delete_field", key, start, err) }(time.Now())
+
+	return c.client.HDel(ctx, c.prefix.Prefix(key), field).Err()
+}
+
+// isWrongType reports whether err is redis error of operation on key holding other type of value
+func isWrongType(err error) bool {
+	var redisErr goredis.Error
+	return errors.As(err, &redisErr) && strings.HasPrefix(redisErr.Error(), "WRONGTYPE")
+}
@@ -0,0 +1,283 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+	"github.com/go-redis/redismock/v9"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// redisError is error replied by redis server
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+func (redisError) RedisError() {}
+
+// errWrongType is reply of redis to operation on key holding other type of value
+const errWrongType = redisError("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+type hashed struct {
+	Name string `json:"name"`
+}
+
+func TestWithHashStorage(t *testing.T) {
+	if c := New(WithHashStorage()); !c.hashStorage {
+		t.Error("WithHashStorage() did not enable hash storage")
+	}
+}
+
+func Test_isStructured(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  bool
+	}{
+		{name: "test struct", value: hashed{}, want: true},
+		{name: "test struct pointer", value: &hashed{}, want: true},
+		{name: "test map of strings", value: map[string]int{}, want: true},
+		{name: "test map of ints", value: map[int]int{}, want: false},
+		{name: "test string", value: "value", want: false},
+		{name: "test nil pointer", value: (*hashed)(nil), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStructured(tt.value); got != tt.want {
+				t.Errorf("isStructured() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCacher_setHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   any
+		options []cache.SetOption
+		expect  func(redismock.ClientMock)
+	}{
+		{
+			name:  "test struct",
+			value: hashed{Name: "one"},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectTxPipeline()
+				mock.ExpectDel("key").SetVal(1)
+				mock.ExpectHSet("key", "name", []byte(`"one"`)).SetVal(1)
+				mock.ExpectTxPipelineExec()
+			},
+		},
+		{
+			name:    "test map with ttl",
+			value:   map[string]int{"id": 1},
+			options: []cache.SetOption{cache.WithTTL(time.Second)},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectTxPipeline()
+				mock.ExpectDel("key").SetVal(1)
+				mock.ExpectHSet("key", "id", []byte(`1`)).SetVal(1)
+				mock.ExpectPExpire("key", time.Second).SetVal(true)
+				mock.ExpectTxPipelineExec()
+			},
+		},
+		{
+			name:  "test empty map",
+			value: map[string]int{},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectTxPipeline()
+				mock.ExpectDel("key").SetVal(1)
+				mock.ExpectTxPipelineExec()
+			},
+		},
+		{
+			name:  "test string is not hashed",
+			value: "value",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSet("key", "value", 0).SetVal("OK")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}, hashStorage: true}
+			if err := c.Set(context.Background(), "key", tt.value, tt.options...); err != nil {
+				t.Errorf("Set() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_getHash(t *testing.T) {
+	tests := []struct {
+		name        string
+		hashStorage bool
+		marshaller  cache.Marshaller
+		expect      func(redismock.ClientMock)
+		want        any
+		wantErr     bool
+	}{
+		{
+			name:        "test hash as map",
+			hashStorage: true,
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectGet("key").SetErr(errWrongType)
+				mock.ExpectHGetAll("key").SetVal(map[string]string{"name": `"one"`, "id": "1"})
+			},
+			want: map[string]any{"name": "one", "id": float64(1)},
+		},
+		{
+			name:        "test hash with marshaller",
+			hashStorage: true,
+			marshaller:  cache.CodecMarshaller(cache.JSONCodec{}, hashed{}),
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectGet("key").SetErr(errWrongType)
+				mock.ExpectHGetAll("key").SetVal(map[string]string{"name": `"one"`})
+			},
+			want: hashed{Name: "one"},
+		},
+		{
+			name:        "test hash deleted after type check",
+			hashStorage: true,
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectGet("key").SetErr(errWrongType)
+				mock.ExpectHGetAll("key").SetVal(map[string]string{})
+			},
+			want: nil,
+		},
+		{
+			name: "test hash without hash storage",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectGet("key").SetErr(errWrongType)
+				mock.ExpectHGetAll("key").SetVal(map[string]string{"name": `"one"`})
+			},
+			want: map[string]any{"name": "one"},
+		},
+		{
+			name: "test key of other type",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectGet("key").SetErr(errWrongType)
+				mock.ExpectHGetAll("key").SetErr(errWrongType)
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}, hashStorage: tt.hashStorage, marshaller: tt.marshaller}
+			got, err := c.Get(context.Background(), "key")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %#v, want %#v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_GetField(t *testing.T) {
+	tests := []struct {
+		name     string
+		notFound bool
+		expect   func(redismock.ClientMock)
+		want     any
+		wantErr  error
+	}{
+		{
+			name:   "test field",
+			expect: func(mock redismock.ClientMock) { mock.ExpectHGet("key", "name").SetVal(`"one"`) },
+			want:   "one",
+		},
+		{
+			name:   "test missing field",
+			expect: func(mock redismock.ClientMock) { mock.ExpectHGet("key", "name").SetErr(goredis.Nil) },
+		},
+		{
+			name:     "test missing field with not found error",
+			notFound: true,
+			expect:   func(mock redismock.ClientMock) { mock.ExpectHGet("key", "name").SetErr(goredis.Nil) },
+			wantErr:  cache.ErrNotFound,
+		},
+		{
+			name:    "test invalid field",
+			expect:  func(mock redismock.ClientMock) { mock.ExpectHGet("key", "name").SetVal(`{`) },
+			wantErr: &cache.KeyError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}, notFound: tt.notFound}
+			got, err := c.GetField(context.Background(), "key", "name")
+			if !matchErr(err, tt.wantErr) {
+				t.Errorf("GetField() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetField() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_SetField(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	// ttl is passed to script, which sets it only on hash it creates
+	mock.ExpectEvalSha(setFieldScript.Hash(), []string{"test.key"}, "name", []byte(`"one"`), int64(1000)).SetVal(int64(0))
+
+	c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}, ttl: time.Minute}
+	if err := c.SetField(context.Background(), "key", "name", "one", cache.WithTTL(time.Second)); err != nil {
+		t.Errorf("SetField() error = %v", err)
+	}
+
+	var keyErr *cache.KeyError
+	if err := c.SetField(context.Background(), "key", "name", make(chan int)); !errors.As(err, &keyErr) {
+		t.Errorf("SetField() error = %v, want key error", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCacher_DeleteField(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.ExpectHDel("key", "name").SetVal(1)
+
+	c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+	if err := c.DeleteField(context.Background(), "key", "name"); err != nil {
+		t.Errorf("DeleteField() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// matchErr reports whether err matches want, key errors are matched by type
+func matchErr(err, want error) bool {
+	if _, ok := want.(*cache.KeyError); ok {
+		var keyErr *cache.KeyError
+		return errors.As(err, &keyErr)
+	}
+
+	return errors.Is(err, want)
+}
@@ -0,0 +1,109 @@
+package redis
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// scanCount is number of keys requested per SCAN call
+const scanCount = 100
+
+// keyIterator iterates keys with SCAN and removes name prefix
+type keyIterator struct {
+	it     *goredis.ScanIterator
+	prefix internal.KeyPrefix
+}
+
+// Next advances to next key, internal keys of tags and groups are skipped
+func (k *keyIterator) Next(ctx context.Context) bool {
+	for k.it.Next(ctx) {
+		if !internalKey(k.Key()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Key returns current key without name prefix
+func (k *keyIterator) Key() string {
+	return k.prefix.Unprefix(k.it.Val())
+}
+
+// Err returns error stopping iteration
+func (k *keyIterator) Err() error {
+	return k.it.Err()
+}
+
+// internalKey reports whether key without name prefix is set of keys of tag or group rather than cached value
+func internalKey(key string) bool {
+	return strings.HasPrefix(key, tagPrefix) || strings.HasPrefix(key, groupPrefix)
+}
+
+// DeleteByPrefix deletes keys starting with prefix within name prefix and returns number of deleted keys
+// keys are scanned incrementally with SCAN and every scanned page is removed with UNLINK,
+// so redis is not blocked, on redis cluster keys of every master are deleted, internal keys of tags and groups are kept
+func (c *Cacher) DeleteByPrefix(ctx context.Context, prefix string) (deleted int, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "delete_by_prefix", prefix, start, err) }(time.Now())
+
+	return c.unlink(ctx, c.prefix.Prefix(cache.GlobPrefix(prefix)), func(key string) bool {
+		return !internalKey(c.prefix.Unprefix(key))
+	})
+}
+
+// unlink unlinks keys matching pattern which are kept by filter on every master of redis cluster
+// or on single redis and returns number of unlinked keys
+func (c *Cacher) unlink(ctx context.Context, pattern string, filter func(string) bool) (deleted int, err error) {
+	if cluster, ok := c.client.(*goredis.ClusterClient); ok {
+		var mu sync.Mutex
+		err = cluster.ForEachMaster(ctx, func(ctx context.Context, client *goredis.Client) error {
+			n, err := unlinkMatching(ctx, client, pattern, filter)
+			mu.Lock()
+			deleted += n
+			mu.Unlock()
+			return err
+		})
+		return deleted, err
+	}
+
+	return unlinkMatching(ctx, c.client, pattern, filter)
+}
+
+// unlinkMatching unlinks keys matching pattern page by page and returns number of unlinked keys,
+// only keys kept by filter are unlinked if filter is not nil
+func unlinkMatching(ctx context.Context, client goredis.UniversalClient, pattern string, filter func(string) bool) (int, error) {
+	deleted := 0
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, scanCount).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if filter != nil {
+			keys = slices.DeleteFunc(keys, func(key string) bool { return !filter(key) })
+		}
+		if len(keys) > 0 {
+			n, err := client.Unlink(ctx, keys...).Result()
+			deleted += int(n)
+			if err != nil {
+				return deleted, err
+			}
+		}
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
+
+// Flush deletes all keys within name prefix including sets of tags and groups, or all keys of database if cacher has no name
+func (c *Cacher) Flush(ctx context.Context) error {
+	_, err := c.unlink(ctx, c.prefix.Prefix("*"), nil)
+	return err
+}
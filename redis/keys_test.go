@@ -0,0 +1,117 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/albinzx/cache/internal"
+	"github.com/go-redis/redismock/v9"
+)
+
+func TestCacher_DeleteByPrefix(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name        string
+		prefix      string
+		expect      func(redismock.ClientMock)
+		wantDeleted int
+		wantErr     error
+	}{
+		{
+			name:   "test delete scanned pages",
+			prefix: "user:",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectScan(0, "test.user:*", scanCount).SetVal([]string{"test.user:1", "test.user:2"}, 7)
+				mock.ExpectUnlink("test.user:1", "test.user:2").SetVal(2)
+				mock.ExpectScan(7, "test.user:*", scanCount).SetVal([]string{}, 9)
+				mock.ExpectScan(9, "test.user:*", scanCount).SetVal([]string{"test.user:3"}, 0)
+				mock.ExpectUnlink("test.user:3").SetVal(1)
+			},
+			wantDeleted: 3,
+		},
+		{
+			name:   "test sets of tags and groups are kept",
+			prefix: "",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectScan(0, "test.*", scanCount).SetVal([]string{"test.a", "test.__tag:t"}, 7)
+				mock.ExpectUnlink("test.a").SetVal(1)
+				mock.ExpectScan(7, "test.*", scanCount).SetVal([]string{"test.__group:g"}, 0)
+			},
+			wantDeleted: 1,
+		},
+		{
+			name:   "test glob metacharacters of prefix are escaped",
+			prefix: "a*[",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectScan(0, `test.a\*\[*`, scanCount).SetVal([]string{}, 0)
+			},
+		},
+		{
+			name:   "test scan error",
+			prefix: "user:",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectScan(0, "test.user:*", scanCount).SetVal([]string{"test.user:1"}, 7)
+				mock.ExpectUnlink("test.user:1").SetVal(1)
+				mock.ExpectScan(7, "test.user:*", scanCount).SetErr(failed)
+			},
+			wantDeleted: 1,
+			wantErr:     failed,
+		},
+		{
+			name:   "test unlink error",
+			prefix: "user:",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectScan(0, "test.user:*", scanCount).SetVal([]string{"test.user:1"}, 7)
+				mock.ExpectUnlink("test.user:1").SetErr(failed)
+			},
+			wantErr: failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}}
+			deleted, err := c.DeleteByPrefix(context.Background(), tt.prefix)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteByPrefix() error = %v, want %v", err, tt.wantErr)
+			}
+			if deleted != tt.wantDeleted {
+				t.Errorf("DeleteByPrefix() = %d, want %d", deleted, tt.wantDeleted)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_Flush(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  internal.KeyPrefix
+		pattern string
+	}{
+		{name: "test flush keys of name", prefix: &internal.WithPrefix{Name: "test"}, pattern: "test.*"},
+		{name: "test flush all keys", prefix: &internal.NoPrefix{}, pattern: "*"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			// sets of tags are flushed with values
+			mock.ExpectScan(0, tt.pattern, scanCount).SetVal([]string{"a", "__tag:t"}, 0)
+			mock.ExpectUnlink("a", "__tag:t").SetVal(2)
+
+			c := &Cacher{client: client, prefix: tt.prefix}
+			if err := c.Flush(context.Background()); err != nil {
+				t.Errorf("Flush() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
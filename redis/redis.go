@@ -3,12 +3,13 @@ package redis
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/albinzx/cache"
 	"github.com/albinzx/cache/internal"
-	"github.com/albinzx/marshal"
 	goredis "github.com/redis/go-redis/v9"
 )
 
@@ -17,19 +18,34 @@ type Cacher struct {
 	client      goredis.UniversalClient
 	ttl         time.Duration
 	prefix      internal.KeyPrefix
-	marshaller  marshal.Marshaller
+	marshaller  cache.Marshaller
+	represent   cache.Representation
 	closeClient bool
+	pingOnStart time.Duration
+	notFound    bool
+	hashStorage bool
+	metrics     cache.Metrics
+	retry       internal.Retry
+
+	slogger       *slog.Logger
+	logLevel      slog.Level
+	errorLogLevel slog.Level
+	logger        *internal.Logger
 }
 
 // defaults sets default redis cacher option
 func defaults(cache *Cacher) {
 	if cache.client == nil {
-		cache.client = goredis.NewClient(&goredis.Options{})
+		cache.client = goredis.NewClient(&goredis.Options{ContextTimeoutEnabled: true})
 	}
 
 	if cache.prefix == nil {
 		cache.prefix = &internal.NoPrefix{}
 	}
+
+	cache.retry.Transient = isTransient
+
+	cache.logger = internal.NewLogger(cache.slogger, cache.logLevel, cache.errorLogLevel).With("backend", "redis")
 }
 
 // Option provides redis cacher options
@@ -37,24 +53,96 @@ type Option func(*Cacher)
 
 // New returns new redis cacher
 func New(options ...Option) *Cacher {
-	rcache := &Cacher{closeClient: true}
+	rcache := configure(options)
 
-	for _, option := range options {
-		option(rcache)
+	defaults(rcache)
+
+	return rcache
+}
+
+// NewE returns new redis cacher, or error wrapping cache.ErrInvalidOption if options are invalid
+// if WithPingOnStart is set, redis is pinged and its error is returned when it is not reachable
+func NewE(options ...Option) (*Cacher, error) {
+	rcache := configure(options)
+
+	if err := rcache.validate(); err != nil {
+		if rcache.client != nil {
+			_ = rcache.Close()
+		}
+		return nil, err
 	}
 
 	defaults(rcache)
 
+	if rcache.pingOnStart > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), rcache.pingOnStart)
+		defer cancel()
+
+		if err := rcache.Ping(ctx); err != nil {
+			_ = rcache.Close()
+			return nil, fmt.Errorf("redis: ping on start: %w", err)
+		}
+	}
+
+	return rcache, nil
+}
+
+// configure returns cacher with options applied
+func configure(options []Option) *Cacher {
+	rcache := &Cacher{
+		closeClient:   true,
+		logLevel:      slog.LevelDebug,
+		errorLogLevel: slog.LevelWarn,
+	}
+
+	for _, option := range options {
+		option(rcache)
+	}
+
 	return rcache
 }
 
-func (c *Cacher) Set(ctx context.Context, key string, value any, setOptions ...cache.SetOption) error {
+// validate returns error if options of cacher are invalid
+func (c *Cacher) validate() error {
+	if c.ttl < 0 {
+		return fmt.Errorf("%w: negative ttl %v", cache.ErrInvalidOption, c.ttl)
+	}
+
+	if c.pingOnStart < 0 {
+		return fmt.Errorf("%w: negative ping timeout %v", cache.ErrInvalidOption, c.pingOnStart)
+	}
+
+	if c.retry.Timeout < 0 {
+		return fmt.Errorf("%w: negative timeout %v", cache.ErrInvalidOption, c.retry.Timeout)
+	}
+
+	if c.retry.Backoff < 0 {
+		return fmt.Errorf("%w: negative retry backoff %v", cache.ErrInvalidOption, c.retry.Backoff)
+	}
+
+	if c.represent != cache.RepresentNative && c.marshaller != nil {
+		return fmt.Errorf("%w: %v representation can not be used with marshaller", cache.ErrInvalidOption, c.represent)
+	}
+
+	return nil
+}
+
+func (c *Cacher) Set(ctx context.Context, key string, value any, setOptions ...cache.SetOption) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "set", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpSet, start, value, err)
+	}(time.Now())
+
 	setConfig := &cache.SetConfiguration{
 		TTL: c.ttl}
 	for _, option := range setOptions {
 		option(setConfig)
 	}
 
+	if c.hashStorage && setConfig.Mode == cache.SetAlways && !setConfig.KeepTTL && isStructured(value) {
+		return c.retry.Do(ctx, func(ctx context.Context) error { return c.setHash(ctx, key, value, setConfig) })
+	}
+
 	if c.marshaller != nil {
 		// if marshaller is set, marshal value
 		// before storing to redis
@@ -65,38 +153,220 @@ func (c *Cacher) Set(ctx context.Context, key string, value any, setOptions ...c
 		value = marshalled
 	}
 
+	return c.retry.Do(ctx, func(ctx context.Context) error { return c.set(ctx, key, value, setConfig) })
+}
+
+// set stores marshalled value with options of set configuration
+func (c *Cacher) set(ctx context.Context, key string, value any, setConfig *cache.SetConfiguration) error {
+	if setConfig.Mode != cache.SetAlways || setConfig.KeepTTL {
+		return c.setIf(ctx, key, value, setConfig)
+	}
+
+	if len(setConfig.Tags) > 0 {
+		return c.setTagged(ctx, key, value, setConfig.TTL, setConfig.Tags)
+	}
+
 	return c.client.Set(ctx, c.prefix.Prefix(key), value, setConfig.TTL).Err()
 }
 
-func (c *Cacher) Get(ctx context.Context, key string) (any, error) {
-	value := c.client.Get(ctx, c.prefix.Prefix(key))
+// setIf sets value with SET NX, XX or KEEPTTL, tags are added only if value is stored
+func (c *Cacher) setIf(ctx context.Context, key string, value any, setConfig *cache.SetConfiguration) error {
+	args := goredis.SetArgs{KeepTTL: setConfig.KeepTTL}
+	if !setConfig.KeepTTL {
+		args.TTL = setConfig.TTL
+	}
+	switch setConfig.Mode {
+	case cache.SetIfNotExists:
+		args.Mode = "NX"
+	case cache.SetIfExists:
+		args.Mode = "XX"
+	}
+
+	err := c.client.SetArgs(ctx, c.prefix.Prefix(key), value, args).Err()
+	if errors.Is(err, goredis.Nil) {
+		return cache.ErrNotStored
+	}
+	if err != nil || len(setConfig.Tags) == 0 {
+		return err
+	}
+
+	// ttl of value set with KEEPTTL is unknown, so sets of its tags are kept persistent
+	_, err = c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		c.addTags(ctx, pipe, key, args.TTL, setConfig.Tags)
+		return nil
+	})
 
+	return err
+}
+
+func (c *Cacher) Get(ctx context.Context, key string) (value any, err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "get", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpGet, start, value, err)
+	}(time.Now())
+
+	err = c.retry.Do(ctx, func(ctx context.Context) error {
+		value, err = c.get(ctx, key)
+		return err
+	})
+	if err == nil && value == nil && c.notFound {
+		return nil, cache.ErrNotFound
+	}
+
+	return value, err
+}
+
+// get returns value of key stored as string or as hash, hash is read even if hash storage is not enabled,
+// so hashes stored by other cacher are read
+func (c *Cacher) get(ctx context.Context, key string) (any, error) {
+	value, err := c.decode(c.client.Get(ctx, c.prefix.Prefix(key)))
+	if isWrongType(err) {
+		return c.getHash(ctx, key)
+	}
+
+	return value, err
+}
+
+// decode returns value of get command, unmarshalled if marshaller is set
+func (c *Cacher) decode(value *goredis.StringCmd) (any, error) {
 	if errors.Is(value.Err(), goredis.Nil) {
 		return nil, nil
 	}
+	if value.Err() != nil {
+		return nil, value.Err()
+	}
+
+	return c.decodeReply(value.Val())
+}
 
+// decodeReply returns value of reply, unmarshalled if marshaller is set
+func (c *Cacher) decodeReply(reply string) (any, error) {
 	if c.marshaller != nil {
 		// if marshaller is set, unmarshal value
-		marshalled, err := value.Bytes()
+		unmarshalled, err := c.marshaller.Unmarshal([]byte(reply))
 		if err != nil {
 			return nil, err
 		}
-		unmarshalled, err := c.marshaller.Unmarshal(marshalled)
+		return unmarshalled, nil
+	}
+
+	if c.represent == cache.RepresentBytes {
+		return []byte(reply), nil
+	}
+
+	// if marshaller is not set, return value as string
+	return reply, nil
+}
+
+func (c *Cacher) Delete(ctx context.Context, key string) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "delete", key, start, err)
+		cache.RecordOperation(c.metrics, cache.OpDelete, start, nil, err)
+	}(time.Now())
+
+	return c.retry.Do(ctx, func(ctx context.Context) error { return c.client.Del(ctx, c.prefix.Prefix(key)).Err() })
+}
+
+// GetMany gets values of keys from cache in one round trip, returned map contains only keys which are found
+// keys are read with MGET, or with pipelined GET on redis cluster where keys may live in different slots,
+// returned error joins KeyError of keys which fail to be read or decoded
+func (c *Cacher) GetMany(ctx context.Context, keys []string) (values map[string]any, err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "get_many", strings.Join(keys, ","), start, err)
+		cache.RecordMany(c.metrics, cache.OpGet, start, keys, values, err)
+	}(time.Now())
+
+	values = make(map[string]any, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix.Prefix(key)
+	}
+
+	var errs []error
+	replies := make([]any, len(keys))
+	if c.cluster() {
+		cmds := make([]*goredis.StringCmd, len(keys))
+		// errors of pipeline are errors of its commands, which are returned per key
+		_, _ = c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for i, key := range prefixed {
+				cmds[i] = pipe.Get(ctx, key)
+			}
+			return nil
+		})
+		for i, cmd := range cmds {
+			switch err := cmd.Err(); {
+			case err == nil:
+				replies[i] = cmd.Val()
+			case !errors.Is(err, goredis.Nil):
+				errs = append(errs, &cache.KeyError{Key: keys[i], Err: err})
+			}
+		}
+	} else {
+		replies, err = c.client.MGet(ctx, prefixed...).Result()
 		if err != nil {
 			return nil, err
 		}
-		return unmarshalled, nil
 	}
 
-	// if marshaller is not set, return value as string
-	return value.Val(), nil
+	for i, reply := range replies {
+		text, ok := reply.(string)
+		if !ok {
+			continue
+		}
+		value, err := c.decodeReply(text)
+		if err != nil {
+			errs = append(errs, &cache.KeyError{Key: keys[i], Err: err})
+			continue
+		}
+		values[keys[i]] = value
+	}
+
+	return values, errors.Join(errs...)
 }
 
-func (c *Cacher) Delete(ctx context.Context, key string) error {
-	return c.client.Del(ctx, c.prefix.Prefix(key)).Err()
+// DeleteMany deletes values of keys from cache in one round trip
+// keys are deleted with one DEL, or with pipelined DEL on redis cluster where keys may live in different slots
+func (c *Cacher) DeleteMany(ctx context.Context, keys ...string) (err error) {
+	defer func(start time.Time) {
+		c.logger.Operation(ctx, "delete_many", strings.Join(keys, ","), start, err)
+		cache.RecordMany(c.metrics, cache.OpDelete, start, keys, nil, err)
+	}(time.Now())
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix.Prefix(key)
+	}
+
+	if !c.cluster() {
+		return c.client.Del(ctx, prefixed...).Err()
+	}
+
+	_, err = c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, key := range prefixed {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+
+	return err
 }
 
-func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
+// cluster reports whether client is redis cluster client, which rejects commands on keys of different slots
+func (c *Cacher) cluster() bool {
+	_, ok := c.client.(*goredis.ClusterClient)
+	return ok
+}
+
+func (c *Cacher) Load(ctx context.Context, data map[string]any) (err error) {
+	defer func(start time.Time) { cache.RecordOperation(c.metrics, cache.OpLoad, start, nil, err) }(time.Now())
 
 	if c.marshaller != nil {
 		// if marshaller is set, marshal all values
@@ -110,10 +380,10 @@ func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
 			}
 		}
 
-		_, err := c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		_, err = c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
 
-			for key, val := range bytesMap {
-				pipe.Set(ctx, c.prefix.Prefix(key), val, c.ttl)
+			for _, key := range internal.SortedKeys(bytesMap) {
+				pipe.Set(ctx, c.prefix.Prefix(key), bytesMap[key], c.ttl)
 			}
 
 			return nil
@@ -123,10 +393,10 @@ func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
 	}
 
 	// if marshaller is not set, store values as is to redis
-	_, err := c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+	_, err = c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
 
-		for key, val := range data {
-			pipe.Set(ctx, c.prefix.Prefix(key), val, c.ttl)
+		for _, key := range internal.SortedKeys(data) {
+			pipe.Set(ctx, c.prefix.Prefix(key), data[key], c.ttl)
 		}
 
 		return nil
@@ -167,6 +437,20 @@ func WithTTL(ttl time.Duration) Option {
 	}
 }
 
+// WithMetrics returns option to record results and latencies of operations to metrics, e.g. cache.Stats
+func WithMetrics(metrics cache.Metrics) Option {
+	return func(cache *Cacher) {
+		cache.metrics = metrics
+	}
+}
+
+// WithNotFoundError returns option to return cache.ErrNotFound instead of nil value on miss of Get
+func WithNotFoundError() Option {
+	return func(cache *Cacher) {
+		cache.notFound = true
+	}
+}
+
 // WithName returns option to add name as prefix to key
 // if name is empty, no prefix will be added
 func WithName(name string) Option {
@@ -183,9 +467,218 @@ func WithName(name string) Option {
 	}
 }
 
+// WithPingOnStart returns option to ping redis with timeout when cacher is created by NewE
+func WithPingOnStart(timeout time.Duration) Option {
+	return func(cache *Cacher) {
+		cache.pingOnStart = timeout
+	}
+}
+
 // WithMarshaller returns option to set marshaller
-func WithMarshaller(marshaller marshal.Marshaller) Option {
+func WithMarshaller(marshaller cache.Marshaller) Option {
 	return func(cache *Cacher) {
 		cache.marshaller = marshaller
 	}
 }
+
+// WithRepresentation returns option to set representation of values when marshaller is not set,
+// default is native representation which is string
+func WithRepresentation(represent cache.Representation) Option {
+	return func(cache *Cacher) {
+		cache.represent = represent
+	}
+}
+
+// WithCodec returns option to encode values with codec and decode them to type of prototype,
+// e.g. WithCodec(cache.JSONCodec{}, User{})
+func WithCodec(codec cache.Codec, prototype any) Option {
+	return WithMarshaller(cache.CodecMarshaller(codec, prototype))
+}
+
+// WithLogger returns option to set structured logger
+// by default nothing is logged
+func WithLogger(logger *slog.Logger) Option {
+	return func(cache *Cacher) {
+		cache.slogger = logger
+	}
+}
+
+// WithLogLevel returns option to set log level of successful operations
+// and of failed operations, defaults are debug and warn level
+func WithLogLevel(level, errorLevel slog.Level) Option {
+	return func(cache *Cacher) {
+		cache.logLevel = level
+		cache.errorLogLevel = errorLevel
+	}
+}
+
+// WithTimeout returns option to run every set, get and delete with its own deadline,
+// which applies to each attempt when operations are retried, by default operations use deadline of caller
+// go-redis honors deadlines only if ContextTimeoutEnabled is set in options of client, as in default client
+func WithTimeout(timeout time.Duration) Option {
+	return func(cache *Cacher) {
+		cache.retry.Timeout = timeout
+	}
+}
+
+// WithRetry returns option to retry sets, gets and deletes failing with transient network errors
+// or while redis is loading or failing over, up to maxAttempts in total, waiting backoff with jitter
+// before second attempt and doubling it before each next attempt
+// conditional sets may report cache.ErrNotStored when attempt which timed out stored value
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(cache *Cacher) {
+		cache.retry.Attempts = maxAttempts
+		cache.retry.Backoff = backoff
+	}
+}
+
+// transientReplies are prefixes of redis error replies of servers which are loading or failing over
+var transientReplies = []string{"LOADING", "READONLY", "TRYAGAIN", "CLUSTERDOWN", "MASTERDOWN"}
+
+// isTransient reports whether err is network failure or error reply which may succeed when retried
+func isTransient(err error) bool {
+	if cache.IsTransient(err) {
+		return true
+	}
+
+	var reply goredis.Error
+	if !errors.As(err, &reply) {
+		return false
+	}
+	for _, prefix := range transientReplies {
+		if strings.HasPrefix(reply.Error(), prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Ping checks connection to redis
+func (c *Cacher) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// Keys returns iterator of keys matching glob pattern using SCAN
+// pattern is matched within name prefix and returned keys have the prefix removed
+// on redis cluster only keys of the node serving the scan are iterated
+func (c *Cacher) Keys(ctx context.Context, pattern string) cache.KeyIterator {
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	return &keyIterator{
+		it:     c.client.Scan(ctx, 0, c.prefix.Prefix(pattern), scanCount).Iterator(),
+		prefix: c.prefix,
+	}
+}
+
+// Exists returns number of keys which exist with EXISTS, key given more times is counted every time
+// keys are checked with pipelined EXISTS on redis cluster where keys may live in different slots
+func (c *Cacher) Exists(ctx context.Context, keys ...string) (count int64, err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "exists", strings.Join(keys, ","), start, err) }(time.Now())
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix.Prefix(key)
+	}
+
+	if !c.cluster() {
+		return c.client.Exists(ctx, prefixed...).Result()
+	}
+
+	cmds := make([]*goredis.IntCmd, len(keys))
+	if _, err := c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, key := range prefixed {
+			cmds[i] = pipe.Exists(ctx, key)
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	for _, cmd := range cmds {
+		count += cmd.Val()
+	}
+
+	return count, nil
+}
+
+// GetWithTTL retrieves value and its remaining time to live from cache, value stored as hash is read with HGETALL
+// and nil is returned for internal keys of tags and groups
+func (c *Cacher) GetWithTTL(ctx context.Context, key string) (any, time.Duration, error) {
+	if internalKey(key) {
+		return nil, 0, nil
+	}
+
+	var get *goredis.StringCmd
+	var ttl *goredis.DurationCmd
+
+	// ttl is read first, so it is known when get fails on hash
+	_, err := c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		ttl = pipe.PTTL(ctx, c.prefix.Prefix(key))
+		get = pipe.Get(ctx, c.prefix.Prefix(key))
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) && !isWrongType(err) {
+		return nil, 0, err
+	}
+
+	value, err := c.decode(get)
+	if isWrongType(err) {
+		value, err = c.getHash(ctx, key)
+	}
+	if err != nil || value == nil {
+		return nil, 0, err
+	}
+
+	// negative ttl means key has no expiration
+	if ttl.Val() < 0 {
+		return value, 0, nil
+	}
+
+	return value, ttl.Val(), nil
+}
+
+// Expire sets time to live of value with PEXPIRE, non-positive ttl deletes it,
+// cache.ErrNotFound is returned if key does not exist
+func (c *Cacher) Expire(ctx context.Context, key string, ttl time.Duration) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "expire", key, start, err) }(time.Now())
+
+	if ttl <= 0 {
+		deleted, err := c.client.Del(ctx, c.prefix.Prefix(key)).Result()
+		if err == nil && deleted == 0 {
+			return cache.ErrNotFound
+		}
+		return err
+	}
+
+	ok, err := c.client.PExpire(ctx, c.prefix.Prefix(key), ttl).Result()
+	if err == nil && !ok {
+		return cache.ErrNotFound
+	}
+
+	return err
+}
+
+// Persist removes time to live of value with PERSIST so it never expires,
+// cache.ErrNotFound is returned if key does not exist
+func (c *Cacher) Persist(ctx context.Context, key string) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "persist", key, start, err) }(time.Now())
+
+	ok, err := c.client.Persist(ctx, c.prefix.Prefix(key)).Result()
+	if err != nil || ok {
+		return err
+	}
+
+	// persist also fails for key without ttl
+	exists, err := c.client.Exists(ctx, c.prefix.Prefix(key)).Result()
+	if err == nil && exists == 0 {
+		return cache.ErrNotFound
+	}
+
+	return err
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -423,6 +424,138 @@ func TestCacher_Close(t *testing.T) {
 	}
 }
 
+func TestCacher_Ping(t *testing.T) {
+	tests := []struct {
+		name    string
+		init    func() *Cacher
+		wantErr bool
+	}{
+		{
+			name: "test ping success",
+			init: func() *Cacher {
+				client, mock := redismock.NewClientMock()
+				mock.ExpectPing().SetVal("PONG")
+				return &Cacher{client: client}
+			},
+			wantErr: false,
+		},
+		{
+			name: "test ping failure",
+			init: func() *Cacher {
+				client, mock := redismock.NewClientMock()
+				mock.ExpectPing().SetErr(errors.New("connection refused"))
+				return &Cacher{client: client}
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.init()
+			if err := c.Ping(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("Cacher.Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCacher_Keys(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	// sets of tags and groups are not listed as keys
+	mock.ExpectScan(0, "test.*", scanCount).SetVal([]string{"test.key1", "test.__tag:t", "test.key2", "test.__group:g"}, 0)
+	c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}}
+
+	var got []string
+	it := c.Keys(context.Background(), "")
+	for it.Next(context.Background()) {
+		got = append(got, it.Key())
+	}
+
+	if err := it.Err(); err != nil {
+		t.Errorf("Cacher.Keys() error = %v", err)
+	}
+	if want := []string{"key1", "key2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cacher.Keys() = %v, want %v", got, want)
+	}
+}
+
+func TestCacher_GetWithTTL(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		init      func(redismock.ClientMock)
+		wantValue any
+		wantTTL   time.Duration
+	}{
+		{
+			name: "test value with ttl",
+			key:  "key",
+			init: func(mock redismock.ClientMock) {
+				mock.ExpectPTTL("key").SetVal(time.Minute)
+				mock.ExpectGet("key").SetVal("value")
+			},
+			wantValue: "value",
+			wantTTL:   time.Minute,
+		},
+		{
+			name: "test value without expiration",
+			key:  "key",
+			init: func(mock redismock.ClientMock) {
+				mock.ExpectPTTL("key").SetVal(-1)
+				mock.ExpectGet("key").SetVal("value")
+			},
+			wantValue: "value",
+			wantTTL:   0,
+		},
+		{
+			name: "test missing value",
+			key:  "key",
+			init: func(mock redismock.ClientMock) {
+				mock.ExpectPTTL("key").SetVal(-2)
+				mock.ExpectGet("key").RedisNil()
+			},
+			wantValue: nil,
+			wantTTL:   0,
+		},
+		{
+			name: "test value stored as hash",
+			key:  "key",
+			init: func(mock redismock.ClientMock) {
+				mock.ExpectPTTL("key").SetVal(time.Minute)
+				mock.ExpectGet("key").SetErr(errWrongType)
+				mock.ExpectHGetAll("key").SetVal(map[string]string{"name": `"one"`})
+			},
+			wantValue: map[string]any{"name": "one"},
+			wantTTL:   time.Minute,
+		},
+		{
+			name:      "test internal key is not read",
+			key:       "__tag:t",
+			init:      func(mock redismock.ClientMock) {},
+			wantValue: nil,
+			wantTTL:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.init(mock)
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+
+			value, ttl, err := c.GetWithTTL(context.Background(), tt.key)
+			if err != nil {
+				t.Errorf("Cacher.GetWithTTL() error = %v", err)
+			}
+			if !reflect.DeepEqual(value, tt.wantValue) || ttl != tt.wantTTL {
+				t.Errorf("Cacher.GetWithTTL() = %v, %v, want %v, %v", value, ttl, tt.wantValue, tt.wantTTL)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
 func TestWithRedisClient(t *testing.T) {
 	type args struct {
 		client goredis.UniversalClient
@@ -570,3 +703,278 @@ func TestWithMarshaller(t *testing.T) {
 		})
 	}
 }
+
+func TestNewE(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+		ping    func(redismock.ClientMock)
+		wantErr error
+	}{
+		{name: "test valid options", options: []Option{WithTTL(time.Second)}},
+		{name: "test negative ttl", options: []Option{WithTTL(-time.Second)}, wantErr: cache.ErrInvalidOption},
+		{name: "test negative ping timeout", options: []Option{WithPingOnStart(-time.Second)}, wantErr: cache.ErrInvalidOption},
+		{name: "test bytes with marshaller", options: []Option{WithBytes(), WithMarshaller(&str.Marshaller{})}, wantErr: cache.ErrInvalidOption},
+		{name: "test ping on start", options: []Option{WithPingOnStart(time.Second)}, ping: func(mock redismock.ClientMock) { mock.ExpectPing().SetVal("PONG") }},
+		{name: "test ping on start failed", options: []Option{WithPingOnStart(time.Second)}, ping: func(mock redismock.ClientMock) { mock.ExpectPing().SetErr(errUnreachable) }, wantErr: errUnreachable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			if tt.ping != nil {
+				tt.ping(mock)
+			}
+
+			got, err := NewE(append([]Option{WithRedisClient(client)}, tt.options...)...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewE() error = %v, want %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.wantErr != nil) {
+				t.Errorf("NewE() = %v, want nil %v", got, tt.wantErr != nil)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("NewE() expectation were not met, %v", err)
+			}
+		})
+	}
+}
+
+var errUnreachable = errors.New("unreachable")
+
+func TestCacher_setIf(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		options   []cache.SetOption
+		expect    func(redismock.ClientMock)
+		wantSaved bool
+		wantErr   error
+	}{
+		{
+			name:    "test set if not exists",
+			options: []cache.SetOption{cache.IfNotExists(), cache.WithTTL(time.Second)},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSetArgs("key", "value", goredis.SetArgs{Mode: "NX", TTL: time.Second}).SetVal("OK")
+			},
+			wantSaved: true,
+		},
+		{
+			name:    "test set if not exists of existing key",
+			options: []cache.SetOption{cache.IfNotExists()},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSetArgs("key", "value", goredis.SetArgs{Mode: "NX"}).SetErr(goredis.Nil)
+			},
+		},
+		{
+			name:    "test set if exists with global ttl",
+			ttl:     time.Minute,
+			options: []cache.SetOption{cache.IfExists()},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSetArgs("key", "value", goredis.SetArgs{Mode: "XX", TTL: time.Minute}).SetVal("OK")
+			},
+			wantSaved: true,
+		},
+		{
+			name:    "test set if exists of missing key",
+			options: []cache.SetOption{cache.IfExists()},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSetArgs("key", "value", goredis.SetArgs{Mode: "XX"}).SetErr(goredis.Nil)
+			},
+		},
+		{
+			name:    "test keep ttl ignores ttl",
+			ttl:     time.Minute,
+			options: []cache.SetOption{cache.IfExists(), cache.KeepTTL()},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSetArgs("key", "value", goredis.SetArgs{Mode: "XX", KeepTTL: true}).SetVal("OK")
+			},
+			wantSaved: true,
+		},
+		{
+			name:    "test redis error",
+			options: []cache.SetOption{cache.IfNotExists()},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSetArgs("key", "value", goredis.SetArgs{Mode: "NX"}).SetErr(failed)
+			},
+			wantErr: failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}, ttl: tt.ttl}
+			saved, err := cache.SetIf(context.Background(), c, "key", "value", tt.options...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("SetIf() error = %v, want %v", err, tt.wantErr)
+			}
+			if saved != tt.wantSaved {
+				t.Errorf("SetIf() = %v, want %v", saved, tt.wantSaved)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_Expire(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		expect  func(redismock.ClientMock)
+		wantErr error
+	}{
+		{
+			name:   "test expire",
+			ttl:    time.Second,
+			expect: func(mock redismock.ClientMock) { mock.ExpectPExpire("key", time.Second).SetVal(true) },
+		},
+		{
+			name:    "test expire missing key",
+			ttl:     time.Second,
+			expect:  func(mock redismock.ClientMock) { mock.ExpectPExpire("key", time.Second).SetVal(false) },
+			wantErr: cache.ErrNotFound,
+		},
+		{
+			name:    "test expire error",
+			ttl:     time.Second,
+			expect:  func(mock redismock.ClientMock) { mock.ExpectPExpire("key", time.Second).SetErr(failed) },
+			wantErr: failed,
+		},
+		{
+			name:   "test expire without ttl deletes key",
+			expect: func(mock redismock.ClientMock) { mock.ExpectDel("key").SetVal(1) },
+		},
+		{
+			name:    "test expire without ttl of missing key",
+			expect:  func(mock redismock.ClientMock) { mock.ExpectDel("key").SetVal(0) },
+			wantErr: cache.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+			if err := c.Expire(context.Background(), "key", tt.ttl); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expire() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_Persist(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		expect  func(redismock.ClientMock)
+		wantErr error
+	}{
+		{
+			name:   "test persist",
+			expect: func(mock redismock.ClientMock) { mock.ExpectPersist("key").SetVal(true) },
+		},
+		{
+			name: "test persist key without ttl",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectPersist("key").SetVal(false)
+				mock.ExpectExists("key").SetVal(1)
+			},
+		},
+		{
+			name: "test persist missing key",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectPersist("key").SetVal(false)
+				mock.ExpectExists("key").SetVal(0)
+			},
+			wantErr: cache.ErrNotFound,
+		},
+		{
+			name:    "test persist error",
+			expect:  func(mock redismock.ClientMock) { mock.ExpectPersist("key").SetErr(failed) },
+			wantErr: failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+			if err := c.Persist(context.Background(), "key"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Persist() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_Exists(t *testing.T) {
+	ctx := context.Background()
+
+	client, mock := redismock.NewClientMock()
+	// key given twice is counted twice
+	mock.ExpectExists("test.a", "test.b", "test.a").SetVal(2)
+
+	c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}}
+	if got, err := c.Exists(ctx, "a", "b", "a"); err != nil || got != 2 {
+		t.Errorf("Exists() = %d, %v, want 2", got, err)
+	}
+	// no keys sends no command
+	if got, err := c.Exists(ctx); err != nil || got != 0 {
+		t.Errorf("Exists() = %d, %v, want 0", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCacher_ExistsCluster(t *testing.T) {
+	client, mock := redismock.NewClusterMock()
+	// keys of different slots are checked one by one in pipeline
+	mock.ExpectExists("a").SetVal(1)
+	mock.ExpectExists("b").SetVal(0)
+	mock.ExpectExists("c").SetVal(1)
+
+	c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+	if got, err := c.Exists(context.Background(), "a", "b", "c"); err != nil || got != 2 {
+		t.Errorf("Exists() = %d, %v, want 2", got, err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCacher_GetManyCluster(t *testing.T) {
+	client, mock := redismock.NewClusterMock()
+	// keys of different slots are read one by one in pipeline, failed keys are returned as KeyError,
+	// mock stops pipeline at first failure so failing key is the last one
+	mock.ExpectGet("a").SetVal("one")
+	mock.ExpectGet("c").SetVal("three")
+	mock.ExpectGet("b").SetErr(errors.New("MOVED 3999 127.0.0.1:6381"))
+
+	c := &Cacher{client: client, prefix: &internal.NoPrefix{}}
+	values, err := c.GetMany(context.Background(), []string{"a", "c", "b"})
+	if want := map[string]any{"a": "one", "c": "three"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("GetMany() = %v, want %v", values, want)
+	}
+	if failed := cache.KeyErrors(err, []string{"a", "b", "c"}); len(failed) != 1 || failed["b"] == nil {
+		t.Errorf("GetMany() error = %v, want error of b", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
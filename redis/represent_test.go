@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/memory"
+	"github.com/go-redis/redismock/v9"
+)
+
+func TestRepresentation_conformance(t *testing.T) {
+	tests := []struct {
+		name      string
+		represent cache.Representation
+		value     any
+		want      any
+	}{
+		{name: "test bytes of string", represent: cache.RepresentBytes, value: "value", want: []byte("value")},
+		{name: "test bytes of bytes", represent: cache.RepresentBytes, value: []byte("value"), want: []byte("value")},
+		{name: "test string of string", represent: cache.RepresentString, value: "value", want: "value"},
+		{name: "test string of bytes", represent: cache.RepresentString, value: []byte("value"), want: "value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			client, mock := redismock.NewClientMock()
+			mock.ExpectSet("key", tt.value, 0).SetVal("OK")
+			mock.ExpectGet("key").SetVal("value")
+
+			backends := map[string]cache.Cacher{
+				"redis":  New(WithRedisClient(client), WithRepresentation(tt.represent)),
+				"memory": memory.New(memory.WithRepresentation(tt.represent)),
+			}
+			for name, c := range backends {
+				if err := c.Set(ctx, "key", tt.value); err != nil {
+					t.Fatalf("%s Set() error = %v", name, err)
+				}
+				if got, _ := c.Get(ctx, "key"); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%s Get() = %#v, want %#v", name, got, tt.want)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Get() expectation were not met, %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+	"github.com/albinzx/cache/server/resp"
+	"github.com/go-redis/redismock/v9"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func Test_isTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "test nil", err: nil, want: false},
+		{name: "test miss", err: goredis.Nil, want: false},
+		{name: "test eof", err: io.EOF, want: true},
+		{name: "test network error", err: &net.OpError{Op: "dial", Err: errors.New("refused")}, want: true},
+		{name: "test deadline", err: context.DeadlineExceeded, want: true},
+		{name: "test loading", err: redisError("LOADING Redis is loading the dataset in memory"), want: true},
+		{name: "test failing over", err: redisError("READONLY You can't write against a read only replica."), want: true},
+		{name: "test cluster down", err: redisError("CLUSTERDOWN The cluster is down"), want: true},
+		{name: "test wrong type", err: errWrongType, want: false},
+		{name: "test other error", err: errors.New("failed"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(tt.err); got != tt.want {
+				t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		expect  func(redismock.ClientMock)
+		want    any
+		wantErr error
+	}{
+		{
+			name: "test transient errors are retried",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectGet("key").SetErr(io.EOF)
+				mock.ExpectGet("key").SetErr(redisError("LOADING Redis is loading the dataset in memory"))
+				mock.ExpectGet("key").SetVal("value")
+			},
+			want: "value",
+		},
+		{
+			name: "test attempts are exhausted",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectGet("key").SetErr(io.EOF)
+				mock.ExpectGet("key").SetErr(io.EOF)
+				mock.ExpectGet("key").SetErr(io.EOF)
+			},
+			wantErr: io.EOF,
+		},
+		{
+			name: "test miss is not retried",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectGet("key").SetErr(goredis.Nil)
+			},
+		},
+		{
+			name: "test other error is not retried",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectGet("key").SetErr(failed)
+			},
+			wantErr: failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := New(WithRedisClient(client), WithRetry(3, time.Millisecond))
+			got, err := c.Get(context.Background(), "key")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+			// unexpected retry would fail with call without expectation
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	slow := cachetest.NewCacher()
+	slow.Delay(cache.OpGet, 500*time.Millisecond)
+	server := resp.New(slow)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(func() { _ = server.Close() })
+
+	client := goredis.NewClient(&goredis.Options{Addr: listener.Addr().String(), Protocol: 2, DisableIndentity: true, ContextTimeoutEnabled: true})
+	c := New(WithRedisClient(client), WithTimeout(20*time.Millisecond))
+	defer c.Close()
+
+	start := time.Now()
+	if _, err := c.Get(context.Background(), "key"); err == nil {
+		t.Error("Get() error = nil, want timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Get() took %v, want deadline of timeout", elapsed)
+	}
+}
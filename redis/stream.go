@@ -0,0 +1,378 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultStreamGroup is default consumer group of stream queue
+	DefaultStreamGroup = "write-behind"
+	// DefaultStreamBlock is default time consumer waits for new writes in one read
+	DefaultStreamBlock = 5 * time.Second
+	// DefaultStreamMinIdle is default time write stays unacked before other consumer claims it
+	DefaultStreamMinIdle = time.Minute
+	// DefaultStreamCount is default number of writes consumer reads at once
+	DefaultStreamCount = 100
+	// DefaultStreamErrorBackoff is default time consumer waits after failed read before it reads again,
+	// it is doubled after each next failed read up to block time
+	DefaultStreamErrorBackoff = 100 * time.Millisecond
+)
+
+// stream entry fields
+const (
+	fieldOp    = "op"
+	fieldKey   = "key"
+	fieldValue = "value"
+)
+
+// StreamQueue is durable write queue of write-behind pattern in redis stream, see cache.WithWriteQueue
+//
+// writes are added to stream by XADD and persisted by consumers of consumer group, which may run
+// in any process, consumer acks and deletes write when it is persisted, writes of consumer which stops
+// before it acks them are claimed by other consumers after min idle time, so pending writes survive restarts
+// writes failing all attempts and entries which can not be decoded are given to dead letter sink
+// and dropped if there is none, if sink fails they stay pending and are retried after min idle time
+type StreamQueue struct {
+	client     goredis.UniversalClient
+	stream     string
+	group      string
+	consumer   string
+	marshaller cache.Marshaller
+	maxLen     int64
+	block      time.Duration
+	minIdle    time.Duration
+	count      int64
+	errBackoff time.Duration
+
+	attempts    int
+	backoff     time.Duration
+	deadLetters cache.DeadLetterSink
+	onError     func(error)
+}
+
+// StreamOption provides stream queue options
+type StreamOption func(*StreamQueue)
+
+// WithStreamGroup returns option to set consumer group and name of consumer,
+// default is DefaultStreamGroup and consumer named by host name and process id
+func WithStreamGroup(group, consumer string) StreamOption {
+	return func(q *StreamQueue) {
+		q.group = group
+		q.consumer = consumer
+	}
+}
+
+// WithStreamMarshaller returns option to marshal values added to stream,
+// by default values must be accepted by redis client, e.g. []byte or string, and are persisted as string
+func WithStreamMarshaller(marshaller cache.Marshaller) StreamOption {
+	return func(q *StreamQueue) {
+		q.marshaller = marshaller
+	}
+}
+
+// WithStreamMaxLen returns option to trim stream to about maxLen entries on XADD, by default it is not trimmed
+// trimming drops oldest entries even if they are not persisted
+func WithStreamMaxLen(maxLen int64) StreamOption {
+	return func(q *StreamQueue) {
+		q.maxLen = maxLen
+	}
+}
+
+// WithStreamMinIdle returns option to set time write stays unacked before other consumer claims it,
+// default is DefaultStreamMinIdle
+func WithStreamMinIdle(minIdle time.Duration) StreamOption {
+	return func(q *StreamQueue) {
+		q.minIdle = minIdle
+	}
+}
+
+// WithStreamRetries returns option to persist write up to attempts times in total, waiting backoff
+// before second attempt and doubling it before each next attempt, default is 1 attempt
+func WithStreamRetries(attempts int, backoff time.Duration) StreamOption {
+	return func(q *StreamQueue) {
+		q.attempts = attempts
+		q.backoff = backoff
+	}
+}
+
+// WithStreamDeadLetterSink returns option to give writes which failed after all attempts to sink
+func WithStreamDeadLetterSink(sink cache.DeadLetterSink) StreamOption {
+	return func(q *StreamQueue) {
+		q.deadLetters = sink
+	}
+}
+
+// WithStreamErrorBackoff returns option to set time consumer waits after failed read of stream,
+// e.g. while redis is down, it is doubled after each next failed read up to block time,
+// default is DefaultStreamErrorBackoff
+func WithStreamErrorBackoff(backoff time.Duration) StreamOption {
+	return func(q *StreamQueue) {
+		q.errBackoff = backoff
+	}
+}
+
+// WithStreamErrorHandler returns option to report errors of consumer, by default they are ignored
+func WithStreamErrorHandler(handler func(error)) StreamOption {
+	return func(q *StreamQueue) {
+		q.onError = handler
+	}
+}
+
+// NewStreamQueue returns write queue in stream of client
+func NewStreamQueue(client goredis.UniversalClient, stream string, options ...StreamOption) *StreamQueue {
+	q := &StreamQueue{
+		client:  client,
+		stream:  stream,
+		group:   DefaultStreamGroup,
+		block:   DefaultStreamBlock,
+		minIdle: DefaultStreamMinIdle,
+		count:   DefaultStreamCount,
+		onError: func(error) {},
+
+		errBackoff: DefaultStreamErrorBackoff,
+	}
+
+	for _, option := range options {
+		option(q)
+	}
+	if q.consumer == "" {
+		host, _ := os.Hostname()
+		q.consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if q.attempts < 1 {
+		q.attempts = 1
+	}
+
+	return q
+}
+
+// Push adds write to stream
+func (q *StreamQueue) Push(ctx context.Context, op cache.Operation, key string, value any) error {
+	values := []any{fieldOp, string(op), fieldKey, key}
+	if op == cache.OpSave {
+		if q.marshaller != nil {
+			marshalled, err := q.marshaller.Marshal(value)
+			if err != nil {
+				return &cache.KeyError{Key: key, Err: err}
+			}
+			value = marshalled
+		}
+		values = append(values, fieldValue, value)
+	}
+
+	args := &goredis.XAddArgs{Stream: q.stream, Values: values}
+	if q.maxLen > 0 {
+		args.MaxLen = q.maxLen
+		args.Approx = true
+	}
+
+	return q.client.XAdd(ctx, args).Err()
+}
+
+// Consume persists writes of stream to p until ctx is done, consumer group is created if it does not exist
+// writes claimed from stopped consumers are persisted before new writes,
+// consumer backs off after failed reads, see WithStreamErrorBackoff
+func (q *StreamQueue) Consume(ctx context.Context, p cache.Persister) error {
+	err := q.client.XGroupCreateMkStream(ctx, q.stream, q.group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+
+	backoff := q.errBackoff
+	for ctx.Err() == nil {
+		if err := q.read(ctx, p); err != nil {
+			q.report(ctx, err)
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			backoff = min(2*backoff, max(q.block, q.errBackoff))
+			continue
+		}
+		backoff = q.errBackoff
+	}
+
+	return ctx.Err()
+}
+
+// read persists claimed writes and new writes of one read of stream
+func (q *StreamQueue) read(ctx context.Context, p cache.Persister) error {
+	if err := q.claim(ctx, p); err != nil {
+		return err
+	}
+
+	streams, err := q.client.XReadGroup(ctx, &goredis.XReadGroupArgs{
+		Group:    q.group,
+		Consumer: q.consumer,
+		Streams:  []string{q.stream, ">"},
+		Count:    q.count,
+		Block:    q.block,
+	}).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, stream := range streams {
+		q.persist(ctx, p, stream.Messages)
+	}
+
+	return nil
+}
+
+// claim persists writes which stay unacked by other consumers for min idle time
+func (q *StreamQueue) claim(ctx context.Context, p cache.Persister) error {
+	start := "0-0"
+	for {
+		messages, next, err := q.client.XAutoClaim(ctx, &goredis.XAutoClaimArgs{
+			Stream:   q.stream,
+			Group:    q.group,
+			MinIdle:  q.minIdle,
+			Start:    start,
+			Count:    q.count,
+			Consumer: q.consumer,
+		}).Result()
+		if err != nil {
+			return err
+		}
+		q.persist(ctx, p, messages)
+
+		if next == "0-0" || len(messages) == 0 {
+			return nil
+		}
+		start = next
+	}
+}
+
+// persist persists writes of messages in order, writes which are persisted or dead lettered are acked and deleted
+func (q *StreamQueue) persist(ctx context.Context, p cache.Persister, messages []goredis.XMessage) {
+	for _, message := range messages {
+		if ctx.Err() != nil {
+			return
+		}
+
+		letter, err := q.decode(message)
+		if err == nil {
+			letter.Attempts, letter.Err = q.deliver(ctx, p, letter)
+			if letter.Err != nil && ctx.Err() != nil {
+				return
+			}
+			if letter.Err != nil {
+				letter.Time = time.Now()
+				q.report(ctx, &cache.KeyError{Key: letter.Key, Err: letter.Err})
+				if !q.deadLetter(ctx, letter) {
+					continue
+				}
+			}
+		} else {
+			// undecodable write can never be persisted, so it is kept only by dead letter sink
+			q.report(ctx, err)
+			letter.Value, _ = message.Values[fieldValue].(string)
+			letter.Err, letter.Time = err, time.Now()
+			if !q.deadLetter(ctx, letter) {
+				continue
+			}
+		}
+
+		if err := q.ack(ctx, message.ID); err != nil {
+			q.report(ctx, err)
+		}
+	}
+}
+
+// decode returns write of message
+func (q *StreamQueue) decode(message goredis.XMessage) (cache.DeadLetter, error) {
+	op, _ := message.Values[fieldOp].(string)
+	key, _ := message.Values[fieldKey].(string)
+	letter := cache.DeadLetter{Op: cache.Operation(op), Key: key}
+
+	switch letter.Op {
+	case cache.OpSave:
+		value, _ := message.Values[fieldValue].(string)
+		if q.marshaller == nil {
+			letter.Value = value
+			return letter, nil
+		}
+
+		unmarshalled, err := q.marshaller.Unmarshal([]byte(value))
+		if err != nil {
+			return letter, &cache.KeyError{Key: key, Err: err}
+		}
+		letter.Value = unmarshalled
+	case cache.OpDelete:
+	default:
+		return letter, fmt.Errorf("%w: stream entry %s has operation %q", cache.ErrUnexpectedType, message.ID, op)
+	}
+
+	return letter, nil
+}
+
+// deliver persists write until it succeeds or attempts are exhausted and returns number of attempts and last error
+func (q *StreamQueue) deliver(ctx context.Context, p cache.Persister, letter cache.DeadLetter) (int, error) {
+	backoff := q.backoff
+	attempt := 1
+	for {
+		var err error
+		if letter.Op == cache.OpSave {
+			err = p.Save(ctx, letter.Key, letter.Value)
+		} else {
+			err = p.Delete(ctx, letter.Key)
+		}
+		if err == nil || attempt >= q.attempts {
+			return attempt, err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return attempt, err
+		}
+		backoff *= 2
+		attempt++
+	}
+}
+
+// deadLetter gives failed write to dead letter sink and reports whether it is handled,
+// write is dropped if there is no sink
+func (q *StreamQueue) deadLetter(ctx context.Context, letter cache.DeadLetter) bool {
+	if q.deadLetters == nil {
+		return true
+	}
+
+	if err := q.deadLetters.DeadLetter(ctx, letter); err != nil {
+		q.report(ctx, &cache.KeyError{Key: letter.Key, Err: err})
+		return false
+	}
+
+	return true
+}
+
+// ack acks and deletes entry of id
+func (q *StreamQueue) ack(ctx context.Context, id string) error {
+	_, err := q.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.XAck(ctx, q.stream, q.group, id)
+		pipe.XDel(ctx, q.stream, id)
+		return nil
+	})
+
+	return err
+}
+
+// report reports error of consumer unless consumer is stopped
+func (q *StreamQueue) report(ctx context.Context, err error) {
+	if ctx.Err() == nil {
+		q.onError(err)
+	}
+}
@@ -0,0 +1,177 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+	"github.com/go-redis/redismock/v9"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func TestStreamQueue_Push(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      cache.Operation
+		value   any
+		options []StreamOption
+		args    *goredis.XAddArgs
+	}{
+		{
+			name:  "test push save",
+			op:    cache.OpSave,
+			value: "value",
+			args:  &goredis.XAddArgs{Stream: "writes", Values: []any{"op", "save", "key", "key", "value", "value"}},
+		},
+		{
+			name: "test push delete",
+			op:   cache.OpDelete,
+			args: &goredis.XAddArgs{Stream: "writes", Values: []any{"op", "delete", "key", "key"}},
+		},
+		{
+			name:    "test push with max len",
+			op:      cache.OpSave,
+			value:   "value",
+			options: []StreamOption{WithStreamMaxLen(10)},
+			args:    &goredis.XAddArgs{Stream: "writes", MaxLen: 10, Approx: true, Values: []any{"op", "save", "key", "key", "value", "value"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			mock.ExpectXAdd(tt.args).SetVal("1-0")
+
+			q := NewStreamQueue(client, "writes", tt.options...)
+			if err := q.Push(context.Background(), tt.op, "key", tt.value); err != nil {
+				t.Errorf("Push() error = %v", err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// expectAck expects ack and delete of stream entry
+func expectAck(mock redismock.ClientMock, id string) {
+	mock.ExpectTxPipeline()
+	mock.ExpectXAck("writes", DefaultStreamGroup, id).SetVal(1)
+	mock.ExpectXDel("writes", id).SetVal(1)
+	mock.ExpectTxPipelineExec()
+}
+
+func TestStreamQueue_persist(t *testing.T) {
+	ctx := context.Background()
+	messages := []goredis.XMessage{
+		{ID: "1-0", Values: map[string]any{"op": "save", "key": "a", "value": "one"}},
+		{ID: "2-0", Values: map[string]any{"op": "delete", "key": "b"}},
+		{ID: "3-0", Values: map[string]any{"op": "explode", "key": "c", "value": "three"}},
+	}
+
+	tests := []struct {
+		name        string
+		sinkErr     error
+		wantAcks    []string
+		wantLetters int
+	}{
+		{name: "test undecodable write is dead lettered", wantAcks: []string{"1-0", "2-0", "3-0"}, wantLetters: 1},
+		{name: "test undecodable write stays pending when sink fails", sinkErr: errors.New("full"), wantAcks: []string{"1-0", "2-0"}, wantLetters: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			for _, id := range tt.wantAcks {
+				expectAck(mock, id)
+			}
+
+			var letters []cache.DeadLetter
+			sink := cache.DeadLetterFunc(func(_ context.Context, letter cache.DeadLetter) error {
+				letters = append(letters, letter)
+				return tt.sinkErr
+			})
+			p := cachetest.NewPersister(map[string]any{"b": "two"})
+
+			q := NewStreamQueue(client, "writes", WithStreamDeadLetterSink(sink))
+			q.persist(ctx, p, messages)
+
+			if value, _ := p.Value("a"); value != "one" {
+				t.Errorf("persisted a = %v, want %v", value, "one")
+			}
+			if _, ok := p.Value("b"); ok {
+				t.Errorf("b is persisted, want deleted")
+			}
+			if len(letters) != tt.wantLetters {
+				t.Fatalf("dead letters = %v, want %v", len(letters), tt.wantLetters)
+			}
+			if letters[0].Key != "c" || letters[0].Value != "three" || !errors.Is(letters[0].Err, cache.ErrUnexpectedType) {
+				t.Errorf("dead letter = %+v, want undecodable write of c", letters[0])
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestStreamQueue_Consume(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.ExpectXGroupCreateMkStream("writes", DefaultStreamGroup, "0").SetVal("OK")
+	mock.ExpectXAutoClaim(&goredis.XAutoClaimArgs{
+		Stream: "writes", Group: DefaultStreamGroup, MinIdle: DefaultStreamMinIdle, Start: "0-0", Count: DefaultStreamCount, Consumer: "consumer",
+	}).SetVal(nil, "0-0")
+	mock.ExpectXReadGroup(&goredis.XReadGroupArgs{
+		Group: DefaultStreamGroup, Consumer: "consumer", Streams: []string{"writes", ">"}, Count: DefaultStreamCount, Block: DefaultStreamBlock,
+	}).SetVal([]goredis.XStream{{Stream: "writes", Messages: []goredis.XMessage{
+		{ID: "1-0", Values: map[string]any{"op": "save", "key": "a", "value": "one"}},
+	}}})
+	expectAck(mock, "1-0")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := cachetest.NewPersister(nil)
+
+	// next read fails as there are no more expectations, which stops consumer
+	q := NewStreamQueue(client, "writes", WithStreamGroup(DefaultStreamGroup, "consumer"),
+		WithStreamErrorHandler(func(error) { cancel() }))
+	if err := q.Consume(ctx, p); !errors.Is(err, context.Canceled) {
+		t.Errorf("Consume() error = %v, want %v", err, context.Canceled)
+	}
+
+	if value, _ := p.Value("a"); value != "one" {
+		t.Errorf("persisted a = %v, want %v", value, "one")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStreamQueue_ConsumeBackoff(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	mock.ExpectXGroupCreateMkStream("writes", DefaultStreamGroup, "0").SetVal("OK")
+
+	var mu sync.Mutex
+	reported := 0
+	q := NewStreamQueue(client, "writes", WithStreamErrorBackoff(20*time.Millisecond), WithStreamErrorHandler(func(error) {
+		mu.Lock()
+		reported++
+		mu.Unlock()
+	}))
+
+	// every read fails as there are no more expectations, like reads of redis which is down
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := q.Consume(ctx, cachetest.NewPersister(nil)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Consume() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// reads back off 20ms, 40ms, 80ms
+	if reported < 1 || reported > 4 {
+		t.Errorf("reported errors = %v, want 1 to 4", reported)
+	}
+}
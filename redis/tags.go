@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// tagPrefix starts keys of sets of keys tagged with tag
+const tagPrefix = "__tag:"
+
+// addMemberSource adds member to set KEYS[1] with command ARGV[2] of arguments following it, SADD for tags
+// or ZADD for groups, and keeps set as long as its members, ttl of ARGV[1] milliseconds extends shorter ttl
+// of set, member without ttl makes set persistent
+const addMemberSource = `
+local ttl = redis.call("PTTL", KEYS[1])
+redis.call(ARGV[2], KEYS[1], unpack(ARGV, 3))
+local member = tonumber(ARGV[1])
+if member <= 0 then
+	if ttl >= 0 then
+		redis.call("PERSIST", KEYS[1])
+	end
+elseif ttl == -2 or (ttl >= 0 and ttl < member) then
+	redis.call("PEXPIRE", KEYS[1], member)
+end
+return 1
+`
+
+// addMemberScript adds members to sets of tags and groups, see addMemberSource
+var addMemberScript = goredis.NewScript(addMemberSource)
+
+// tagKey returns key of set of keys tagged with tag
+func (c *Cacher) tagKey(tag string) string {
+	return c.prefix.Prefix(tagPrefix + tag)
+}
+
+// addTags adds key expiring after ttl to sets of tags in pipeline, so sets expire with their last member
+// keys which are deleted stay in sets until their tags are invalidated or sets expire,
+// script is sent with EVAL as errors of pipeline are not known to fall back from EVALSHA
+func (c *Cacher) addTags(ctx context.Context, pipe goredis.Pipeliner, key string, ttl time.Duration, tags []string) {
+	for _, tag := range tags {
+		addMemberScript.Eval(ctx, pipe, []string{c.tagKey(tag)}, ttl.Milliseconds(), "SADD", key)
+	}
+}
+
+// setTagged stores key-value and adds key to sets of its tags in one round trip
+func (c *Cacher) setTagged(ctx context.Context, key string, value any, ttl time.Duration, tags []string) error {
+	_, err := c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, c.prefix.Prefix(key), value, ttl)
+		c.addTags(ctx, pipe, key, ttl, tags)
+		return nil
+	})
+
+	return err
+}
+
+// InvalidateTags deletes values set with any of tags and sets of keys of tags
+func (c *Cacher) InvalidateTags(ctx context.Context, tags ...string) (err error) {
+	defer func(start time.Time) { c.logger.Operation(ctx, "invalidate_tags", "", start, err) }(time.Now())
+
+	for _, tag := range tags {
+		keys, err := c.client.SMembers(ctx, c.tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+		if err := c.DeleteMany(ctx, keys...); err != nil {
+			return err
+		}
+		if err := c.client.Del(ctx, c.tagKey(tag)).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
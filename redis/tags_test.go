@@ -0,0 +1,140 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+	"github.com/go-redis/redismock/v9"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func TestCacher_SetTagged(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       any
+		hashStorage bool
+		options     []cache.SetOption
+		expect      func(redismock.ClientMock)
+		wantErr     error
+	}{
+		{
+			name:    "test tag sets expire with value",
+			value:   "value",
+			options: []cache.SetOption{cache.WithTTL(time.Second), cache.WithTags("a", "b")},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSet("key", "value", time.Second).SetVal("OK")
+				mock.ExpectEval(addMemberSource, []string{"__tag:a"}, int64(1000), "SADD", "key").SetVal(int64(1))
+				mock.ExpectEval(addMemberSource, []string{"__tag:b"}, int64(1000), "SADD", "key").SetVal(int64(1))
+			},
+		},
+		{
+			name:    "test tag set of value without ttl is persistent",
+			value:   "value",
+			options: []cache.SetOption{cache.WithTags("a")},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSet("key", "value", 0).SetVal("OK")
+				mock.ExpectEval(addMemberSource, []string{"__tag:a"}, int64(0), "SADD", "key").SetVal(int64(1))
+			},
+		},
+		{
+			name:    "test tags of value set if not exists",
+			value:   "value",
+			options: []cache.SetOption{cache.WithTTL(time.Second), cache.IfNotExists(), cache.WithTags("a")},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSetArgs("key", "value", goredis.SetArgs{Mode: "NX", TTL: time.Second}).SetVal("OK")
+				mock.ExpectEval(addMemberSource, []string{"__tag:a"}, int64(1000), "SADD", "key").SetVal(int64(1))
+			},
+		},
+		{
+			name:    "test tags of value which is not stored",
+			value:   "value",
+			options: []cache.SetOption{cache.IfNotExists(), cache.WithTags("a")},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSetArgs("key", "value", goredis.SetArgs{Mode: "NX"}).SetErr(goredis.Nil)
+			},
+			wantErr: cache.ErrNotStored,
+		},
+		{
+			name:        "test tags of hash",
+			value:       hashed{Name: "one"},
+			hashStorage: true,
+			options:     []cache.SetOption{cache.WithTTL(time.Second), cache.WithTags("a")},
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectTxPipeline()
+				mock.ExpectDel("key").SetVal(1)
+				mock.ExpectHSet("key", "name", []byte(`"one"`)).SetVal(1)
+				mock.ExpectPExpire("key", time.Second).SetVal(true)
+				mock.ExpectEval(addMemberSource, []string{"__tag:a"}, int64(1000), "SADD", "key").SetVal(int64(1))
+				mock.ExpectTxPipelineExec()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.NoPrefix{}, hashStorage: tt.hashStorage}
+			if err := c.Set(context.Background(), "key", tt.value, tt.options...); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Set() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCacher_InvalidateTags(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		expect  func(redismock.ClientMock)
+		wantErr error
+	}{
+		{
+			name: "test invalidate tags",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSMembers("test.__tag:a").SetVal([]string{"x", "y"})
+				mock.ExpectDel("test.x", "test.y").SetVal(2)
+				mock.ExpectDel("test.__tag:a").SetVal(1)
+				mock.ExpectSMembers("test.__tag:b").SetVal(nil)
+				mock.ExpectDel("test.__tag:b").SetVal(0)
+			},
+		},
+		{
+			name: "test members error",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSMembers("test.__tag:a").SetErr(failed)
+			},
+			wantErr: failed,
+		},
+		{
+			name: "test delete error keeps tag set",
+			expect: func(mock redismock.ClientMock) {
+				mock.ExpectSMembers("test.__tag:a").SetVal([]string{"x"})
+				mock.ExpectDel("test.x").SetErr(failed)
+			},
+			wantErr: failed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			c := &Cacher{client: client, prefix: &internal.WithPrefix{Name: "test"}}
+			if err := c.InvalidateTags(context.Background(), "a", "b"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("InvalidateTags() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
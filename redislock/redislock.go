@@ -0,0 +1,159 @@
+// Package redislock provides distributed lock on redis, e.g. to coordinate refresh of cached values
+// or write-behind flushes across replicas
+// lock is acquired with SET NX of random token and released or extended only by holder of token
+package redislock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotAcquired is returned when lock is held by another holder
+	ErrNotAcquired = errors.New("redislock: lock is not acquired")
+	// ErrNotHeld is returned when released or extended lock is expired or acquired by another holder
+	ErrNotHeld = errors.New("redislock: lock is not held")
+)
+
+// DefaultPrefix is default prefix of keys of locks
+const DefaultPrefix = "lock:"
+
+// releaseScript deletes lock if it is held with token
+var releaseScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendScript sets ttl of lock in milliseconds if it is held with token
+var extendScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// Locker acquires locks on redis
+type Locker struct {
+	client goredis.UniversalClient
+	prefix string
+	retry  time.Duration
+}
+
+// Option provides locker options
+type Option func(*Locker)
+
+// WithPrefix sets prefix of keys of locks, default is DefaultPrefix
+func WithPrefix(prefix string) Option {
+	return func(l *Locker) {
+		l.prefix = prefix
+	}
+}
+
+// WithRetry makes Acquire retry every interval until lock is acquired or context is done,
+// by default Acquire returns ErrNotAcquired at once
+func WithRetry(interval time.Duration) Option {
+	return func(l *Locker) {
+		l.retry = interval
+	}
+}
+
+// New returns locker of locks on redis client
+func New(client goredis.UniversalClient, options ...Option) *Locker {
+	l := &Locker{client: client, prefix: DefaultPrefix}
+	for _, option := range options {
+		option(l)
+	}
+
+	return l
+}
+
+// Acquire acquires lock of key expiring after ttl, ErrNotAcquired is returned if lock is held by another holder
+func (l *Locker) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &Lock{client: l.client, key: key, name: l.prefix + key, token: token}
+	for {
+		acquired, err := l.client.SetNX(ctx, lock.name, token, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if acquired {
+			return lock, nil
+		}
+		if l.retry <= 0 {
+			return nil, ErrNotAcquired
+		}
+
+		timer := time.NewTimer(l.retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(ErrNotAcquired, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// newToken returns random token of lock holder
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Lock is acquired lock
+type Lock struct {
+	client goredis.UniversalClient
+	key    string
+	name   string
+	token  string
+}
+
+// Key returns key of lock
+func (l *Lock) Key() string {
+	return l.key
+}
+
+// Token returns random token of lock holder
+func (l *Lock) Token() string {
+	return l.token
+}
+
+// Release releases lock, ErrNotHeld is returned if lock is expired or acquired by another holder
+func (l *Lock) Release(ctx context.Context) error {
+	released, err := releaseScript.Run(ctx, l.client, []string{l.name}, l.token).Int64()
+	if err != nil {
+		return err
+	}
+	if released == 0 {
+		return ErrNotHeld
+	}
+
+	return nil
+}
+
+// Extend sets ttl of lock, ErrNotHeld is returned if lock is expired or acquired by another holder
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	extended, err := extendScript.Run(ctx, l.client, []string{l.name}, l.token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return ErrNotHeld
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package redislock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+)
+
+func TestLocker_Acquire(t *testing.T) {
+	failed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		options []Option
+		timeout time.Duration
+		expect  func(mock redismock.ClientMock)
+		wantErr error
+	}{
+		{
+			name: "test acquired",
+			expect: func(mock redismock.ClientMock) {
+				mock.Regexp().ExpectSetNX("lock:key", `^[0-9a-f]{32}$`, time.Second).SetVal(true)
+			},
+		},
+		{
+			name: "test held by another holder",
+			expect: func(mock redismock.ClientMock) {
+				mock.Regexp().ExpectSetNX("lock:key", `^[0-9a-f]{32}$`, time.Second).SetVal(false)
+			},
+			wantErr: ErrNotAcquired,
+		},
+		{
+			name: "test redis error",
+			expect: func(mock redismock.ClientMock) {
+				mock.Regexp().ExpectSetNX("lock:key", `^[0-9a-f]{32}$`, time.Second).SetErr(failed)
+			},
+			wantErr: failed,
+		},
+		{
+			name:    "test retry until acquired",
+			options: []Option{WithRetry(time.Millisecond)},
+			expect: func(mock redismock.ClientMock) {
+				mock.Regexp().ExpectSetNX("lock:key", `^[0-9a-f]{32}$`, time.Second).SetVal(false)
+				mock.Regexp().ExpectSetNX("lock:key", `^[0-9a-f]{32}$`, time.Second).SetVal(true)
+			},
+		},
+		{
+			name:    "test retry until context is done",
+			options: []Option{WithRetry(time.Hour)},
+			timeout: 10 * time.Millisecond,
+			expect: func(mock redismock.ClientMock) {
+				mock.Regexp().ExpectSetNX("lock:key", `^[0-9a-f]{32}$`, time.Second).SetVal(false)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "test prefix",
+			options: []Option{WithPrefix("locks.")},
+			expect: func(mock redismock.ClientMock) {
+				mock.Regexp().ExpectSetNX("locks.key", `^[0-9a-f]{32}$`, time.Second).SetVal(true)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			tt.expect(mock)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			lock, err := New(client, tt.options...).Acquire(ctx, "key", time.Second)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Acquire() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (lock.Key() != "key" || len(lock.Token()) != 32) {
+				t.Errorf("Acquire() lock = %q with token %q", lock.Key(), lock.Token())
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLock_Release(t *testing.T) {
+	tests := []struct {
+		name     string
+		released int64
+		wantErr  error
+	}{
+		{name: "test released by holder", released: 1},
+		{name: "test lost lock", released: 0, wantErr: ErrNotHeld},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			// release script deletes lock only if it still holds token of holder
+			mock.ExpectEvalSha(releaseScript.Hash(), []string{"lock:key"}, "token").SetVal(tt.released)
+
+			lock := &Lock{client: client, key: "key", name: "lock:key", token: "token"}
+			if err := lock.Release(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Release() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLock_Extend(t *testing.T) {
+	tests := []struct {
+		name     string
+		extended int64
+		wantErr  error
+	}{
+		{name: "test extended by holder", extended: 1},
+		{name: "test lost lock", extended: 0, wantErr: ErrNotHeld},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, mock := redismock.NewClientMock()
+			mock.ExpectEvalSha(extendScript.Hash(), []string{"lock:key"}, "token", int64(2000)).SetVal(tt.extended)
+
+			lock := &Lock{client: client, key: "key", name: "lock:key", token: "token"}
+			if err := lock.Extend(context.Background(), 2*time.Second); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Extend() error = %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+// Package refresh refreshes cached values on schedule, independent of read traffic
+package refresh
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// Loader loads fresh value of key
+type Loader func(ctx context.Context, key string) (any, error)
+
+// PersisterLoader returns loader reading values from persister
+func PersisterLoader(p cache.Persister) Loader {
+	return p.SelectOne
+}
+
+// job refreshes keys on interval
+type job struct {
+	name     string
+	interval time.Duration
+	keys     func(context.Context) ([]string, error)
+	load     Loader
+	cancel   context.CancelFunc
+}
+
+// Scheduler refreshes registered keys of cache on their intervals
+type Scheduler struct {
+	cacher     cache.Cacher
+	onError    func(key string, err error)
+	setOptions []cache.SetOption
+	clock      cache.Clock
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// Option provides scheduler options
+type Option func(*Scheduler)
+
+// WithErrorHandler returns option to handle failed refresh of key
+// by default failed refreshes are ignored and retried on next interval
+func WithErrorHandler(onError func(key string, err error)) Option {
+	return func(s *Scheduler) {
+		s.onError = onError
+	}
+}
+
+// WithSetOptions returns option to set options used when storing refreshed values
+func WithSetOptions(options ...cache.SetOption) Option {
+	return func(s *Scheduler) {
+		s.setOptions = options
+	}
+}
+
+// WithClock returns option to set clock ticking refresh intervals, default is cache.SystemClock
+func WithClock(clock cache.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+// New returns scheduler pushing refreshed values into c
+func New(c cache.Cacher, options ...Option) *Scheduler {
+	s := &Scheduler{cacher: c, jobs: make(map[string]*job), clock: cache.SystemClock}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Register registers key to be refreshed with load every interval
+// registering already registered key replaces it
+func (s *Scheduler) Register(key string, interval time.Duration, load Loader) {
+	s.add(&job{
+		name:     key,
+		interval: interval,
+		keys:     func(context.Context) ([]string, error) { return []string{key}, nil },
+		load:     load,
+	})
+}
+
+// RegisterKeys registers keys generated by keys to be refreshed with load every interval
+// keys is called on every refresh so key set may change over time
+// name identifies registration, registering already registered name replaces it
+func (s *Scheduler) RegisterKeys(name string, interval time.Duration, keys func(context.Context) ([]string, error), load Loader) {
+	s.add(&job{name: name, interval: interval, keys: keys, load: load})
+}
+
+// Unregister stops refreshing key or keys registered with name
+func (s *Scheduler) Unregister(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if j, ok := s.jobs[name]; ok {
+		if j.cancel != nil {
+			j.cancel()
+		}
+		delete(s.jobs, name)
+	}
+}
+
+// add registers job and runs it if scheduler is started
+func (s *Scheduler) add(j *job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.jobs[j.name]; ok && old.cancel != nil {
+		old.cancel()
+	}
+
+	s.jobs[j.name] = j
+	if s.ctx != nil {
+		s.run(j)
+	}
+}
+
+// Start starts refreshing registered keys, every key is refreshed immediately
+// and then on its interval until Stop is called or context is done
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx != nil {
+		return
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, j := range s.jobs {
+		s.run(j)
+	}
+}
+
+// run runs job in background, must be called with lock held
+func (s *Scheduler) run(j *job) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	j.cancel = cancel
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+
+		ticker := s.clock.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			s.refresh(ctx, j)
+
+			select {
+			case <-ticker.C():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// refresh loads and stores fresh values of job keys
+// panic of loader or cacher is recovered and reported as failure of the job
+func (s *Scheduler) refresh(ctx context.Context, j *job) {
+	defer cache.RecoverTo(func(err error) { s.fail(j.name, err) })
+
+	keys, err := j.keys(ctx)
+	if err != nil {
+		s.fail(j.name, err)
+		return
+	}
+
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return
+		}
+
+		value, err := j.load(ctx, key)
+		if err != nil {
+			s.fail(key, err)
+			continue
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if err := s.cacher.Set(ctx, key, value, s.setOptions...); err != nil {
+			s.fail(key, err)
+		}
+	}
+}
+
+// fail reports failed refresh
+func (s *Scheduler) fail(key string, err error) {
+	if s.onError != nil {
+		s.onError(key, err)
+	}
+}
+
+// Stop stops refreshing and waits for running refreshes to finish
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.ctx, s.cancel = nil, nil
+	s.mu.Unlock()
+
+	s.running.Wait()
+}
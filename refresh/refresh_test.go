@@ -0,0 +1,82 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+)
+
+func TestScheduler(t *testing.T) {
+	c := cachetest.NewCacher()
+	p := cachetest.NewPersister(map[string]any{"config": "v1", "a": 1, "b": 2})
+
+	var failures atomic.Int32
+	s := New(c, WithErrorHandler(func(string, error) { failures.Add(1) }))
+	s.Register("config", 5*time.Millisecond, PersisterLoader(p))
+	s.RegisterKeys("letters", time.Hour, func(context.Context) ([]string, error) {
+		return []string{"a", "b"}, nil
+	}, PersisterLoader(p))
+	s.Register("broken", time.Hour, func(context.Context, string) (any, error) {
+		return nil, errors.New("down")
+	})
+
+	s.Start(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	_ = p.Save(context.Background(), "config", "v2")
+	time.Sleep(20 * time.Millisecond)
+	s.Stop()
+
+	cachetest.AssertCached(t, c, "config", "v2")
+	cachetest.AssertCached(t, c, "a", 1)
+	cachetest.AssertCached(t, c, "b", 2)
+	if got := failures.Load(); got != 1 {
+		t.Errorf("Scheduler failures = %v, want %v", got, 1)
+	}
+
+	s.Unregister("config")
+	_ = p.Save(context.Background(), "config", "v3")
+	s.Start(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	s.Stop()
+
+	cachetest.AssertCached(t, c, "config", "v2")
+}
+
+func TestScheduler_WithClock(t *testing.T) {
+	c := cachetest.NewCacher()
+	p := cachetest.NewPersister(map[string]any{"config": "v1"})
+	clock := cachetest.NewClock(time.Now())
+
+	s := New(c, WithClock(clock))
+	s.Register("config", time.Hour, PersisterLoader(p))
+	s.Start(context.Background())
+	defer s.Stop()
+
+	clock.BlockUntil(1)
+	waitCached(t, c, "config", "v1")
+
+	_ = p.Save(context.Background(), "config", "v2")
+	clock.Advance(time.Hour)
+	waitCached(t, c, "config", "v2")
+}
+
+// waitCached waits until value of key in c is want
+func waitCached(t *testing.T, c cache.Cacher, key string, want any) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		if value, _ := c.Get(context.Background(), key); value == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("value of %v is not %v", key, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
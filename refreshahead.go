@@ -0,0 +1,280 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRefreshInterval is default interval of refresh-ahead scheduler
+	DefaultRefreshInterval = time.Minute
+	// DefaultRefreshConcurrency is default number of keys refreshed at the same time
+	DefaultRefreshConcurrency = 4
+	// DefaultRefreshKeys is default maximum number of keys refreshed per interval
+	DefaultRefreshKeys = 1000
+)
+
+// KeyAccess is reads of key since previous refresh
+type KeyAccess struct {
+	Key string
+	// Hits is number of reads since previous refresh
+	Hits int64
+	// LastAccess is time of the last read
+	LastAccess time.Time
+}
+
+// RefreshPolicy selects keys refreshed ahead of expiration from reads since previous refresh
+type RefreshPolicy func(accesses []KeyAccess) []string
+
+// TopKeys returns refresh policy selecting at most n keys read at least minHits times since previous refresh,
+// most read keys first
+func TopKeys(n int, minHits int64) RefreshPolicy {
+	return func(accesses []KeyAccess) []string {
+		sort.Slice(accesses, func(i, j int) bool {
+			if accesses[i].Hits != accesses[j].Hits {
+				return accesses[i].Hits > accesses[j].Hits
+			}
+			return accesses[i].Key < accesses[j].Key
+		})
+
+		var keys []string
+		for _, access := range accesses {
+			if len(keys) == n || access.Hits < minHits {
+				break
+			}
+			keys = append(keys, access.Key)
+		}
+
+		return keys
+	}
+}
+
+// RefreshAhead is a cache pattern that reads through persistence storage like ReadThrough
+// and reloads frequently read keys from persistence storage in background every interval,
+// so they are replaced before they expire and reads of reference data do not miss
+//
+// reads are tracked per key, keys which are not read during an interval are forgotten,
+// refreshes use context of the first read detached from its cancellation
+type RefreshAhead struct {
+	interval    time.Duration
+	concurrency int
+	policy      RefreshPolicy
+	setOptions  []SetOption
+	clock       Clock
+
+	mu       sync.Mutex
+	accesses map[string]*KeyAccess
+	ctx      context.Context
+	c        Cacher
+	p        Persister
+	start    sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+	stopped  bool
+}
+
+// RefreshAheadOption provides refresh-ahead options
+type RefreshAheadOption func(*RefreshAhead)
+
+// WithRefreshInterval returns option to set interval of refreshes, default is DefaultRefreshInterval,
+// it should be shorter than ttl of refreshed values
+func WithRefreshInterval(interval time.Duration) RefreshAheadOption {
+	return func(r *RefreshAhead) {
+		r.interval = interval
+	}
+}
+
+// WithRefreshConcurrency returns option to set maximum number of keys refreshed at the same time,
+// default is DefaultRefreshConcurrency
+func WithRefreshConcurrency(concurrency int) RefreshAheadOption {
+	return func(r *RefreshAhead) {
+		r.concurrency = concurrency
+	}
+}
+
+// WithRefreshPolicy returns option to select refreshed keys, default is TopKeys(DefaultRefreshKeys, 1)
+func WithRefreshPolicy(policy RefreshPolicy) RefreshAheadOption {
+	return func(r *RefreshAhead) {
+		r.policy = policy
+	}
+}
+
+// WithRefreshSetOptions returns option to set options used when storing refreshed values, e.g. WithTTL
+func WithRefreshSetOptions(options ...SetOption) RefreshAheadOption {
+	return func(r *RefreshAhead) {
+		r.setOptions = options
+	}
+}
+
+// NewRefreshAhead returns refresh-ahead pattern
+func NewRefreshAhead(options ...RefreshAheadOption) *RefreshAhead {
+	r := &RefreshAhead{}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// Set stores key-value to cache
+func (r *RefreshAhead) Set(ctx context.Context, key string, value any, c Cacher, p Persister, options ...SetOption) error {
+	return c.Set(ctx, key, value, options...)
+}
+
+// Get retrieves value from cache, if not found, retrieves value from persistence storage
+// and stores the value to cache, read of key is tracked for refresh
+func (r *RefreshAhead) Get(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
+	value, err := readThrough(ctx, key, c, p)
+	if p != nil {
+		r.track(ctx, key, c, p)
+	}
+
+	return value, err
+}
+
+// Delete deletes value from cache and stops tracking its reads
+func (r *RefreshAhead) Delete(ctx context.Context, key string, c Cacher, p Persister) error {
+	r.mu.Lock()
+	delete(r.accesses, key)
+	r.mu.Unlock()
+
+	return c.Delete(ctx, key)
+}
+
+// track records read of key and starts scheduler on first read
+func (r *RefreshAhead) track(ctx context.Context, key string, c Cacher, p Persister) {
+	r.start.Do(func() {
+		if r.interval <= 0 {
+			r.interval = DefaultRefreshInterval
+		}
+		if r.concurrency <= 0 {
+			r.concurrency = DefaultRefreshConcurrency
+		}
+		if r.policy == nil {
+			r.policy = TopKeys(DefaultRefreshKeys, 1)
+		}
+		if r.clock == nil {
+			r.clock = SystemClock
+		}
+
+		r.mu.Lock()
+		r.accesses = map[string]*KeyAccess{}
+		r.ctx, r.c, r.p = context.WithoutCancel(ctx), c, p
+		r.stop, r.done = make(chan struct{}), make(chan struct{})
+		stopped := r.stopped
+		r.mu.Unlock()
+
+		if stopped {
+			close(r.done)
+			return
+		}
+		go r.run()
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	access, ok := r.accesses[key]
+	if !ok {
+		access = &KeyAccess{Key: key}
+		r.accesses[key] = access
+	}
+	access.Hits++
+	access.LastAccess = r.clock.Now()
+}
+
+// run refreshes selected keys every interval until stopped
+func (r *RefreshAhead) run() {
+	defer close(r.done)
+
+	ticker := r.clock.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			r.refreshAll(r.selectKeys())
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// selectKeys returns keys selected by policy and starts new interval, keys not read during interval are forgotten
+func (r *RefreshAhead) selectKeys() []string {
+	r.mu.Lock()
+	accesses := make([]KeyAccess, 0, len(r.accesses))
+	for key, access := range r.accesses {
+		if access.Hits == 0 {
+			delete(r.accesses, key)
+			continue
+		}
+		accesses = append(accesses, *access)
+		access.Hits = 0
+	}
+	r.mu.Unlock()
+
+	return r.policy(accesses)
+}
+
+// refreshAll reloads keys from persistence storage with limited concurrency
+func (r *RefreshAhead) refreshAll(keys []string) {
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, r.concurrency)
+
+	for _, key := range keys {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			r.refresh(key)
+		}(key)
+	}
+	wg.Wait()
+}
+
+// refresh reloads value of key from persistence storage and stores it to cache
+func (r *RefreshAhead) refresh(key string) {
+	ctx := r.ctx
+	report := reporterFrom(ctx, "refresh", key)
+	defer RecoverTo(report)
+
+	value, err := r.p.SelectOne(ctx, key)
+	if err != nil {
+		report(err)
+		return
+	}
+
+	if value == nil {
+		err = r.c.Delete(ctx, key)
+	} else {
+		err = r.c.Set(ctx, key, value, r.setOptions...)
+	}
+	if err != nil {
+		loggerFrom(ctx).Error(ctx, "failed to refresh value of cache", "refresh", key, err)
+		return
+	}
+	emit(ctx, Event{Type: EventRefresh, Key: key, Time: r.clock.Now()})
+}
+
+// Stop stops refresh scheduler and waits for running refreshes to finish
+func (r *RefreshAhead) Stop() {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return
+	}
+	r.stopped = true
+	started := r.stop != nil
+	r.mu.Unlock()
+
+	if started {
+		close(r.stop)
+		<-r.done
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRefreshAhead(t *testing.T) {
+	ctx := context.Background()
+	persister := newMapPersister()
+	persister.data["hot"] = "v1"
+	persister.data["cold"] = "v1"
+	cacher := newMapCacher()
+
+	pattern := NewRefreshAhead(WithRefreshInterval(10*time.Millisecond), WithRefreshPolicy(TopKeys(1, 2)))
+	defer pattern.Stop()
+	c, _ := New(cacher, persister, WithPattern(pattern))
+
+	refreshed := make(chan string, 10)
+	c.Subscribe(func(e Event) {
+		if e.Type == EventRefresh {
+			refreshed <- e.Key
+		}
+	})
+
+	for i := 0; i < 3; i++ {
+		_, _ = c.Get(ctx, "hot")
+	}
+	_, _ = c.Get(ctx, "cold")
+
+	persister.mu.Lock()
+	persister.data["hot"] = "v2"
+	persister.data["cold"] = "v2"
+	persister.mu.Unlock()
+
+	select {
+	case key := <-refreshed:
+		if key != "hot" {
+			t.Errorf("refreshed key = %v, want %v", key, "hot")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("key is not refreshed")
+	}
+
+	if got, _ := cacher.Get(ctx, "hot"); got != "v2" {
+		t.Errorf("Get(hot) = %v, want %v", got, "v2")
+	}
+	if got, _ := cacher.Get(ctx, "cold"); got != "v1" {
+		t.Errorf("Get(cold) = %v, want %v", got, "v1")
+	}
+}
+
+func TestTopKeys(t *testing.T) {
+	accesses := []KeyAccess{{Key: "a", Hits: 1}, {Key: "b", Hits: 5}, {Key: "c", Hits: 3}, {Key: "d", Hits: 3}}
+
+	if got, want := TopKeys(2, 1)(accesses), []string{"b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TopKeys() = %v, want %v", got, want)
+	}
+	if got, want := TopKeys(10, 3)(accesses), []string{"b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TopKeys() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,232 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnknownCodec is returned when stored value is encoded with codec which is not registered
+	ErrUnknownCodec = errors.New("unknown codec")
+)
+
+// registryMagic starts header of values encoded by codec registry
+// header is magic and length of codec name followed by codec name and encoded value
+var registryMagic = []byte{0xc7, 0x43}
+
+// registeredCodec is codec registered with name and type of decoded values
+type registeredCodec struct {
+	name  string
+	codec Codec
+	typ   reflect.Type
+}
+
+// prefixCodec is codec selected for keys with prefix
+type prefixCodec struct {
+	prefix string
+	name   string
+}
+
+// CodecRegistry selects codec of values by key prefix or by value type
+// encoded values record name of their codec, so they are decoded with the same codec
+// and to the registered type, even after registrations change
+type CodecRegistry struct {
+	mu       sync.RWMutex
+	byName   map[string]*registeredCodec
+	byType   map[reflect.Type]*registeredCodec
+	prefixes []prefixCodec
+}
+
+// NewCodecRegistry returns empty codec registry
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{
+		byName: map[string]*registeredCodec{},
+		byType: map[reflect.Type]*registeredCodec{},
+	}
+}
+
+// Register registers codec with name for values of type of prototype,
+// e.g. Register("user.json", User{}, JSONCodec{}), name is stored with values so it must stay stable
+func (r *CodecRegistry) Register(name string, prototype any, codec Codec) error {
+	if name == "" || len(name) > 255 {
+		return fmt.Errorf("invalid codec name %q", name)
+	}
+	if prototype == nil {
+		return fmt.Errorf("codec %s: prototype is nil", name)
+	}
+
+	r.register(name, reflect.TypeOf(prototype), codec)
+
+	return nil
+}
+
+// register registers codec with name for values of type typ
+func (r *CodecRegistry) register(name string, typ reflect.Type, codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	registered := &registeredCodec{name: name, codec: codec, typ: typ}
+	r.byName[name] = registered
+	r.byType[typ] = registered
+}
+
+// RegisterPrefix selects codec registered with name for keys with prefix, regardless of value type
+// the longest matching prefix is selected
+func (r *CodecRegistry) RegisterPrefix(prefix, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byName[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCodec, name)
+	}
+	r.prefixes = append(r.prefixes, prefixCodec{prefix: prefix, name: name})
+
+	return nil
+}
+
+// codecOf returns codec of key and value, nil is returned if none is registered
+func (r *CodecRegistry) codecOf(key string, value any) *registeredCodec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var selected *prefixCodec
+	for i, p := range r.prefixes {
+		if strings.HasPrefix(key, p.prefix) && (selected == nil || len(p.prefix) > len(selected.prefix)) {
+			selected = &r.prefixes[i]
+		}
+	}
+	if selected != nil {
+		return r.byName[selected.name]
+	}
+
+	return r.byType[reflect.TypeOf(value)]
+}
+
+// Encode returns value of key encoded with its codec and header, value is returned unchanged
+// if no codec is registered for it
+func (r *CodecRegistry) Encode(key string, value any) (any, error) {
+	registered := r.codecOf(key, value)
+	if registered == nil {
+		return value, nil
+	}
+
+	data, err := registered.codec.Marshal(value)
+	if err != nil {
+		return nil, keyError(key, err)
+	}
+
+	out := make([]byte, 0, len(registryMagic)+1+len(registered.name)+len(data))
+	out = append(out, registryMagic...)
+	out = append(out, byte(len(registered.name)))
+	out = append(out, registered.name...)
+
+	return append(out, data...), nil
+}
+
+// Decode returns value decoded with codec recorded in its header,
+// value without header is returned unchanged
+func (r *CodecRegistry) Decode(key string, value any) (any, error) {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return value, nil
+	}
+
+	if len(data) < len(registryMagic)+1 || !bytes.Equal(data[:len(registryMagic)], registryMagic) {
+		return value, nil
+	}
+	size := int(data[len(registryMagic)])
+	start := len(registryMagic) + 1
+	if len(data) < start+size {
+		return value, nil
+	}
+	name := string(data[start : start+size])
+
+	r.mu.RLock()
+	registered, ok := r.byName[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, keyError(key, fmt.Errorf("%w: %s", ErrUnknownCodec, name))
+	}
+
+	target := reflect.New(registered.typ)
+	if err := registered.codec.Unmarshal(data[start+size:], target.Interface()); err != nil {
+		return nil, keyError(key, err)
+	}
+
+	return target.Elem().Interface(), nil
+}
+
+// registryCacher is cacher encoding values with codec registry
+type registryCacher struct {
+	Cacher
+	registry *CodecRegistry
+}
+
+// Set encodes value and sets it to cache
+func (r *registryCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	encoded, err := r.registry.Encode(key, value)
+	if err != nil {
+		return err
+	}
+
+	return r.Cacher.Set(ctx, key, encoded, options...)
+}
+
+// Get gets value from cache and decodes it
+func (r *registryCacher) Get(ctx context.Context, key string) (any, error) {
+	value, err := r.Cacher.Get(ctx, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	return r.registry.Decode(key, value)
+}
+
+// Load encodes values and loads them into cache
+func (r *registryCacher) Load(ctx context.Context, data map[string]any) error {
+	encoded := make(map[string]any, len(data))
+	for key, value := range data {
+		v, err := r.registry.Encode(key, value)
+		if err != nil {
+			return err
+		}
+		encoded[key] = v
+	}
+
+	return r.Cacher.Load(ctx, encoded)
+}
+
+// Encoded returns cacher encoding values with codecs selected by registry
+func Encoded(c Cacher, registry *CodecRegistry) Cacher {
+	return &registryCacher{Cacher: c, registry: registry}
+}
+
+// EncodedAs returns cacher encoding values of type T with codec and decoding them back to T,
+// encoded values carry codec header, so they are told apart from values which are bytes themselves,
+// e.g. EncodedAs[[]byte], T must be concrete type, values of other types are stored as is
+func EncodedAs[T any](c Cacher, codec Codec) Cacher {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+
+	registry := NewCodecRegistry()
+	registry.register("typed."+typ.String(), typ, codec)
+
+	return Encoded(c, registry)
+}
+
+// WithCodecRegistry returns option to encode values with codecs selected by registry
+// before they are stored to cacher
+func WithCodecRegistry(registry *CodecRegistry) Option {
+	return func(c *PatternedCache) {
+		c.codecs = registry
+	}
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestCodecRegistry(t *testing.T) {
+	registry := NewCodecRegistry()
+	if err := registry.Register("typed.json", typedValue{}, JSONCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("string.proto", &wrapperspb.StringValue{}, ProtoCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Register("map.gob", map[string]any{}, GobCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterPrefix("legacy.", "map.gob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RegisterPrefix("x.", "unknown"); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("RegisterPrefix() error = %v, want %v", err, ErrUnknownCodec)
+	}
+
+	m := newMapCacher()
+	c, _ := New(m, nil, WithCodecRegistry(registry))
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		key         string
+		value       any
+		wantEncoded bool
+	}{
+		{name: "test json by type", key: "user", value: typedValue{Number: 1, Text: "one"}, wantEncoded: true},
+		{name: "test proto by type", key: "name", value: wrapperspb.String("value"), wantEncoded: true},
+		{name: "test gob by prefix", key: "legacy.settings", value: map[string]any{"a": "b"}, wantEncoded: true},
+		{name: "test unregistered type", key: "count", value: 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.Set(ctx, tt.key, tt.value); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if _, encoded := m.data[tt.key].([]byte); encoded != tt.wantEncoded {
+				t.Errorf("Set() stored = %T, want encoded %v", m.data[tt.key], tt.wantEncoded)
+			}
+
+			got, err := c.Get(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if message, ok := got.(*wrapperspb.StringValue); ok {
+				if message.GetValue() != "value" {
+					t.Errorf("Get() = %v, want %v", got, tt.value)
+				}
+			} else if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("Get() = %#v, want %#v", got, tt.value)
+			}
+		})
+	}
+
+	m.data["stale"] = append(append([]byte{}, registryMagic...), append([]byte{7}, "removed"...)...)
+	if _, err := c.Get(ctx, "stale"); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("Get() error = %v, want %v", err, ErrUnknownCodec)
+	}
+}
@@ -0,0 +1,215 @@
+// Package replicate writes every value to a primary cacher and its replicas, e.g. redis clusters
+// in different zones, and reads from the primary failing over to replicas when it fails
+package replicate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/albinzx/cache"
+)
+
+// Mode is how writes are replicated to replicas
+type Mode int
+
+const (
+	// Sync writes primary and replicas concurrently and waits for all of them,
+	// write fails if any backend fails
+	Sync Mode = iota
+	// Async writes primary and returns, replicas are written in background
+	// and their errors are reported to error handler
+	Async
+)
+
+// ReplicaError is error of replica
+type ReplicaError struct {
+	// Replica is index of replica as given to New
+	Replica int
+	Err     error
+}
+
+// Error returns error message
+func (e *ReplicaError) Error() string {
+	return fmt.Sprintf("replicate: replica %d: %v", e.Replica, e.Err)
+}
+
+// Unwrap returns error of replica
+func (e *ReplicaError) Unwrap() error {
+	return e.Err
+}
+
+// Cacher is cacher writing to primary and replicas and reading from primary,
+// reads fail over to replicas in order when primary fails, a miss of primary is not failed over
+type Cacher struct {
+	primary  cache.Cacher
+	replicas []cache.Cacher
+	mode     Mode
+
+	onError func(error)
+	pending sync.WaitGroup
+}
+
+// Option provides replicated cacher options
+type Option func(*Cacher)
+
+// WithMode returns option to set replication mode, default is Sync
+func WithMode(mode Mode) Option {
+	return func(c *Cacher) {
+		c.mode = mode
+	}
+}
+
+// WithErrorHandler returns option to report errors which do not fail operations, i.e. errors of
+// asynchronous writes and of primary reads served by replica, errors are cache.KeyError of the key,
+// by default they are ignored
+func WithErrorHandler(handler func(error)) Option {
+	return func(c *Cacher) {
+		c.onError = handler
+	}
+}
+
+// New returns cacher writing to primary and replicas
+func New(primary cache.Cacher, replicas []cache.Cacher, options ...Option) *Cacher {
+	c := &Cacher{primary: primary, replicas: replicas, onError: func(error) {}}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// Primary returns primary cacher
+func (c *Cacher) Primary() cache.Cacher {
+	return c.primary
+}
+
+// Replicas returns replica cachers
+func (c *Cacher) Replicas() []cache.Cacher {
+	return c.replicas
+}
+
+// report reports error on key
+func (c *Cacher) report(key string, err error) {
+	if err != nil {
+		c.onError(&cache.KeyError{Key: key, Err: err})
+	}
+}
+
+// write applies op to primary and replicas by mode and returns joined errors of backends waited for
+func (c *Cacher) write(ctx context.Context, key string, op func(context.Context, cache.Cacher) error) error {
+	if c.mode == Async {
+		ctx := context.WithoutCancel(ctx)
+		for i, replica := range c.replicas {
+			c.pending.Add(1)
+			go func(i int, replica cache.Cacher) {
+				defer c.pending.Done()
+				if err := op(ctx, replica); err != nil {
+					c.report(key, &ReplicaError{Replica: i, Err: err})
+				}
+			}(i, replica)
+		}
+
+		return op(ctx, c.primary)
+	}
+
+	errs := make([]error, len(c.replicas)+1)
+	var wg sync.WaitGroup
+	for i, replica := range c.replicas {
+		wg.Add(1)
+		go func(i int, replica cache.Cacher) {
+			defer wg.Done()
+			if err := op(ctx, replica); err != nil {
+				errs[i+1] = &ReplicaError{Replica: i, Err: err}
+			}
+		}(i, replica)
+	}
+	errs[0] = op(ctx, c.primary)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Set sets key-value to primary and replicas
+func (c *Cacher) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	return c.write(ctx, key, func(ctx context.Context, backend cache.Cacher) error {
+		return backend.Set(ctx, key, value, options...)
+	})
+}
+
+// Get gets value from primary, or from replicas in order if primary fails
+// error joins errors of all backends when all of them fail
+func (c *Cacher) Get(ctx context.Context, key string) (any, error) {
+	value, err := c.primary.Get(ctx, key)
+	if err == nil || errors.Is(err, cache.ErrNotFound) || len(c.replicas) == 0 {
+		return value, err
+	}
+
+	errs := []error{err}
+	for i, replica := range c.replicas {
+		value, rerr := replica.Get(ctx, key)
+		if rerr == nil || errors.Is(rerr, cache.ErrNotFound) {
+			c.report(key, err)
+			return value, rerr
+		}
+		errs = append(errs, &ReplicaError{Replica: i, Err: rerr})
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// Delete deletes value from primary and replicas
+func (c *Cacher) Delete(ctx context.Context, key string) error {
+	return c.write(ctx, key, func(ctx context.Context, backend cache.Cacher) error {
+		return backend.Delete(ctx, key)
+	})
+}
+
+// Load loads key-values to primary and replicas
+func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
+	return c.write(ctx, "", func(ctx context.Context, backend cache.Cacher) error {
+		return backend.Load(ctx, data)
+	})
+}
+
+// Wait waits until asynchronous writes to replicas are done or ctx is done
+func (c *Cacher) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		c.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Ping pings primary and replicas implementing cache.Pinger
+func (c *Cacher) Ping(ctx context.Context) error {
+	var errs []error
+	for _, backend := range append([]cache.Cacher{c.primary}, c.replicas...) {
+		if pinger, ok := backend.(cache.Pinger); ok {
+			errs = append(errs, pinger.Ping(ctx))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close waits for asynchronous writes and closes primary and replicas
+func (c *Cacher) Close() error {
+	c.pending.Wait()
+
+	errs := []error{c.primary.Close()}
+	for _, replica := range c.replicas {
+		errs = append(errs, replica.Close())
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,120 @@
+package replicate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+	"github.com/albinzx/cache/memory"
+)
+
+func TestConformance(t *testing.T) {
+	cachetest.Conformance(t, func(testing.TB) cache.Cacher {
+		return New(memory.New(), []cache.Cacher{memory.New()})
+	})
+}
+
+func TestSyncWrite(t *testing.T) {
+	ctx := context.Background()
+	primary, replica := cachetest.NewCacher(), cachetest.NewCacher()
+	c := New(primary, []cache.Cacher{replica})
+
+	if err := c.Set(ctx, "key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	cachetest.AssertCached(t, primary, "key", "value")
+	cachetest.AssertCached(t, replica, "key", "value")
+
+	failure := errors.New("zone down")
+	replica.Fail(cache.OpSet, failure)
+	err := c.Set(ctx, "other", "value")
+	var replicaErr *ReplicaError
+	if !errors.As(err, &replicaErr) || replicaErr.Replica != 0 || !errors.Is(err, failure) {
+		t.Errorf("Set() error = %v, want error of replica 0", err)
+	}
+	cachetest.AssertCached(t, primary, "other", "value")
+
+	if err := c.Delete(ctx, "key"); err != nil {
+		t.Fatal(err)
+	}
+	cachetest.AssertNotCached(t, primary, "key")
+	cachetest.AssertNotCached(t, replica, "key")
+}
+
+func TestAsyncWrite(t *testing.T) {
+	ctx := context.Background()
+	primary, replica := cachetest.NewCacher(), cachetest.NewCacher()
+
+	var mu sync.Mutex
+	var reported []error
+	c := New(primary, []cache.Cacher{replica}, WithMode(Async), WithErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, err)
+	}))
+
+	if err := c.Set(ctx, "key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cachetest.AssertCached(t, replica, "key", "value")
+
+	failure := errors.New("zone down")
+	replica.Fail(cache.OpSet, failure)
+	if err := c.Set(ctx, "other", "value"); err != nil {
+		t.Fatalf("Set() error = %v, want replica error not to fail write", err)
+	}
+	if err := c.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cachetest.AssertCached(t, primary, "other", "value")
+
+	mu.Lock()
+	defer mu.Unlock()
+	var keyErr *cache.KeyError
+	if len(reported) != 1 || !errors.As(reported[0], &keyErr) || keyErr.Key != "other" || !errors.Is(reported[0], failure) {
+		t.Errorf("reported errors = %v, want error of key other", reported)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	ctx := context.Background()
+	primary, first, second := cachetest.NewCacher(), cachetest.NewCacher(), cachetest.NewCacher()
+
+	var reported []error
+	c := New(primary, []cache.Cacher{first, second}, WithErrorHandler(func(err error) {
+		reported = append(reported, err)
+	}))
+	if err := c.Set(ctx, "key", "value"); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("zone down")
+	primary.Fail(cache.OpGet, failure)
+	first.Fail(cache.OpGet, failure)
+	if value, err := c.Get(ctx, "key"); err != nil || value != "value" {
+		t.Errorf("Get() = %v, %v, want value of second replica", value, err)
+	}
+	if len(reported) != 1 {
+		t.Errorf("reported errors = %v, want error of primary", reported)
+	}
+
+	second.Fail(cache.OpGet, failure)
+	if _, err := c.Get(ctx, "key"); !errors.Is(err, failure) {
+		t.Errorf("Get() error = %v, want %v", err, failure)
+	}
+
+	// miss of primary is not failed over
+	primary.Reset()
+	second.Reset()
+	_ = primary.Delete(ctx, "key")
+	if value, err := c.Get(ctx, "key"); err != nil || value != nil {
+		t.Errorf("Get() = %v, %v, want miss of primary", value, err)
+	}
+	cachetest.AssertCallCount(t, second, cache.OpGet, 0)
+}
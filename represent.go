@@ -0,0 +1,68 @@
+package cache
+
+import "fmt"
+
+// Representation is representation of values returned by backends
+// when values are not decoded by marshaller
+type Representation int
+
+const (
+	// RepresentNative returns values in native representation of backend,
+	// memory returns values as stored and redis returns strings
+	RepresentNative Representation = iota
+	// RepresentBytes returns byte and string values as []byte
+	RepresentBytes
+	// RepresentString returns byte and string values as string
+	RepresentString
+)
+
+// String returns name of representation
+func (r Representation) String() string {
+	switch r {
+	case RepresentNative:
+		return "native"
+	case RepresentBytes:
+		return "bytes"
+	case RepresentString:
+		return "string"
+	default:
+		return fmt.Sprintf("Representation(%d)", int(r))
+	}
+}
+
+// Convert returns value in representation, nil stays nil
+// ErrUnexpectedType is returned when value is neither []byte nor string for bytes or string representation
+func (r Representation) Convert(value any) (any, error) {
+	if value == nil || r == RepresentNative {
+		return value, nil
+	}
+
+	switch v := value.(type) {
+	case []byte:
+		if r == RepresentString {
+			return string(v), nil
+		}
+		return append([]byte{}, v...), nil
+	case string:
+		if r == RepresentBytes {
+			return []byte(v), nil
+		}
+		return v, nil
+	default:
+		return nil, fmt.Errorf("%w: %T can not be represented as %v", ErrUnexpectedType, value, r)
+	}
+}
+
+// ParseRepresentation returns representation of name, empty name is native representation
+func ParseRepresentation(name string) (Representation, error) {
+	switch name {
+	case "", "native":
+		return RepresentNative, nil
+	case "bytes":
+		return RepresentBytes, nil
+	case "string":
+		return RepresentString, nil
+	default:
+		return RepresentNative, fmt.Errorf("%w: unknown representation %q", ErrInvalidOption, name)
+	}
+}
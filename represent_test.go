@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRepresentation_Convert(t *testing.T) {
+	tests := []struct {
+		name    string
+		r       Representation
+		value   any
+		want    any
+		wantErr error
+	}{
+		{name: "test native keeps value", r: RepresentNative, value: 42, want: 42},
+		{name: "test nil", r: RepresentBytes, value: nil, want: nil},
+		{name: "test string to bytes", r: RepresentBytes, value: "value", want: []byte("value")},
+		{name: "test bytes to bytes", r: RepresentBytes, value: []byte("value"), want: []byte("value")},
+		{name: "test bytes to string", r: RepresentString, value: []byte("value"), want: "value"},
+		{name: "test string to string", r: RepresentString, value: "value", want: "value"},
+		{name: "test unexpected type", r: RepresentString, value: 42, wantErr: ErrUnexpectedType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.r.Convert(tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Convert() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+)
+
+// IsTransient reports whether err is network failure which may succeed when retried,
+// e.g. timeout, reset or refused connection, or deadline of attempt
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// WithTimeout returns option to run every set, get and delete of patterned cache with its own deadline,
+// which applies to each attempt when operations are retried, by default operations use deadline of caller
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *PatternedCache) {
+		c.retry.Timeout = timeout
+	}
+}
+
+// WithRetry returns option to retry sets, gets and deletes of patterned cache failing with transient errors,
+// see IsTransient, up to maxAttempts in total, waiting backoff with jitter before second attempt
+// and doubling it before each next attempt, retries run inside middlewares and stop when context is done
+// conditional sets may report ErrNotStored when attempt which timed out stored value
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(c *PatternedCache) {
+		c.retry.Attempts = maxAttempts
+		c.retry.Backoff = backoff
+	}
+}
+
+// retrying is middleware running calls with timeout and retry of patterned cache
+func (c *PatternedCache) retrying(next Handler) Handler {
+	return func(ctx context.Context, call *Call) (any, error) {
+		var value any
+		err := c.retry.Do(ctx, func(ctx context.Context) error {
+			var err error
+			value, err = next(ctx, call)
+			return err
+		})
+
+		return value, err
+	}
+}
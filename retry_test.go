@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyCacher fails sets with err until it is called failures times
+type flakyCacher struct {
+	*mapCacher
+	err      error
+	failures int
+	calls    int
+}
+
+func (f *flakyCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	f.calls++
+	if f.calls <= f.failures {
+		return f.err
+	}
+	return f.mapCacher.Set(ctx, key, value, options...)
+}
+
+// blockingCacher blocks gets until context is done
+type blockingCacher struct {
+	*mapCacher
+}
+
+func (b *blockingCacher) Get(ctx context.Context, _ string) (any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "not found", err: ErrNotFound, want: false},
+		{name: "deadline", err: context.DeadlineExceeded, want: true},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "eof", err: fmt.Errorf("read: %w", io.EOF), want: true},
+		{name: "reset", err: syscall.ECONNRESET, want: true},
+		{name: "dial", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, want: true},
+		{name: "other", err: errors.New("WRONGTYPE"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransient(tt.err); got != tt.want {
+				t.Errorf("IsTransient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		err       error
+		failures  int
+		options   []Option
+		wantErr   error
+		wantCalls int
+	}{
+		{
+			name:      "without retry",
+			err:       io.EOF,
+			failures:  1,
+			wantErr:   io.EOF,
+			wantCalls: 1,
+		},
+		{
+			name:      "retries transient error",
+			err:       io.EOF,
+			failures:  2,
+			options:   []Option{WithRetry(3, time.Millisecond)},
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after max attempts",
+			err:       syscall.ECONNRESET,
+			failures:  3,
+			options:   []Option{WithRetry(2, time.Millisecond)},
+			wantErr:   syscall.ECONNRESET,
+			wantCalls: 2,
+		},
+		{
+			name:      "does not retry other errors",
+			err:       ErrNotStored,
+			failures:  1,
+			options:   []Option{WithRetry(3, time.Millisecond)},
+			wantErr:   ErrNotStored,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := &flakyCacher{mapCacher: newMapCacher(), err: tt.err, failures: tt.failures}
+			c, _ := New(cacher, nil, tt.options...)
+
+			if err := c.Set(ctx, "key", "value"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Set() error = %v, want %v", err, tt.wantErr)
+			}
+			if cacher.calls != tt.wantCalls {
+				t.Errorf("Set() calls = %d, want %d", cacher.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	c, _ := New(&blockingCacher{mapCacher: newMapCacher()}, nil, WithTimeout(10*time.Millisecond), WithRetry(2, time.Millisecond))
+
+	start := time.Now()
+	if _, err := c.Get(context.Background(), "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Get() took %v, want it to time out", elapsed)
+	}
+}
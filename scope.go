@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// scopeKey is context key of scope used by patterns
+type scopeKey struct{}
+
+// scope holds facilities of a patterned cache which are used by patterns
+type scope struct {
+	logger   *internal.Logger
+	events   *eventBus
+	reporter func(error)
+	// negative returns time to live of cached miss of key, nil if misses are not cached
+	negative func(key string) (time.Duration, bool)
+	// degraded reads values from persistence storage when cacher fails, nil if it is not enabled
+	degraded *degradedReads
+}
+
+// noScope is used when context carries no scope
+var noScope = &scope{}
+
+// withScope returns context carrying scope for pattern operations
+func withScope(ctx context.Context, s *scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, s)
+}
+
+// scopeFrom returns scope carried by context
+func scopeFrom(ctx context.Context) *scope {
+	if s, ok := ctx.Value(scopeKey{}).(*scope); ok {
+		return s
+	}
+
+	return noScope
+}
+
+// loggerFrom returns logger carried by context
+// nil logger is returned if context has no logger, which discards all records
+func loggerFrom(ctx context.Context) *internal.Logger {
+	return scopeFrom(ctx).logger
+}
+
+// emit publishes event to subscribers of scope carried by context
+func emit(ctx context.Context, event Event) {
+	scopeFrom(ctx).events.publish(event)
+}
+
+// loadsKey is context key of keys loaded from persistence storage by pattern
+type loadsKey struct{}
+
+// loads is set of keys loaded from persistence storage by pattern during a read of patterned cache,
+// so read tells values loaded on miss from values found in cache
+type loads struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// withLoads returns context recording keys loaded from persistence storage to returned loads
+func withLoads(ctx context.Context) (context.Context, *loads) {
+	l := &loads{keys: map[string]struct{}{}}
+
+	return context.WithValue(ctx, loadsKey{}, l), l
+}
+
+// markLoaded records keys loaded from persistence storage to loads carried by context
+func markLoaded(ctx context.Context, keys ...string) {
+	l, ok := ctx.Value(loadsKey{}).(*loads)
+	if !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		l.keys[key] = struct{}{}
+	}
+}
+
+// loaded reports whether key is loaded from persistence storage
+func (l *loads) loaded(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, ok := l.keys[key]
+
+	return ok
+}
@@ -0,0 +1,339 @@
+// Package http exposes caches over a small REST API, so non-Go services can share them
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+const (
+	// TTLHeader is request header of time to live of stored values,
+	// either duration, e.g. "30s", or number of seconds
+	TTLHeader = "X-Cache-TTL"
+	// FailedKeyHeader is response header of batch get with key which failed to be retrieved escaped as path segment,
+	// it is given once per failed key while values of other keys are returned
+	FailedKeyHeader = "X-Cache-Failed-Key"
+)
+
+// writeMethods are methods of requests which modify cache, other methods are authorized as reads
+var writeMethods = map[string]bool{
+	nethttp.MethodPut:    true,
+	nethttp.MethodPost:   true,
+	nethttp.MethodPatch:  true,
+	nethttp.MethodDelete: true,
+}
+
+// batchGetter is implemented by caches which retrieve multiple keys at once, e.g. PatternedCache
+type batchGetter interface {
+	GetMany(ctx context.Context, keys []string) (map[string]any, error)
+}
+
+// Server is http handler exposing registered caches
+//
+// server serves following paths
+//
+//	GET    /cache/{name}/{key}          value of key
+//	PUT    /cache/{name}/{key}          stores request body as value of key
+//	DELETE /cache/{name}/{key}          deletes key
+//	GET    /cache/{name}?key=a&key=b    json object of values of found keys
+//	PUT    /cache/{name}                stores json object of key-values
+//	DELETE /cache/{name}?key=a&key=b    deletes keys
+//
+// values are stored as bytes and returned as is, values stored by other clients
+// which are not bytes or string are returned as json, batch get returns values of found keys
+// and lists keys which failed in FailedKeyHeader
+type Server struct {
+	mu          sync.RWMutex
+	caches      map[string]cache.Cache
+	authorize   func(r *nethttp.Request, name string, write bool) bool
+	maxBodySize int64
+}
+
+// Option provides server options
+type Option func(*Server)
+
+// WithAuthorizer returns option to authorize requests to cache with name,
+// write reports whether request modifies cache, without authorizer all requests are allowed
+// requests are authorized before cache is looked up, also for names which are not registered
+func WithAuthorizer(authorize func(r *nethttp.Request, name string, write bool) bool) Option {
+	return func(s *Server) {
+		s.authorize = authorize
+	}
+}
+
+// WithMaxBodySize returns option to limit size of request body, default is 1 MiB
+func WithMaxBodySize(size int64) Option {
+	return func(s *Server) {
+		s.maxBodySize = size
+	}
+}
+
+// New returns http cache server
+func New(options ...Option) *Server {
+	s := &Server{caches: make(map[string]cache.Cache), maxBodySize: 1 << 20}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Register registers cache with name, registering existing name replaces it
+func (s *Server) Register(name string, c cache.Cache) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.caches[name] = c
+}
+
+// Unregister removes cache with name
+func (s *Server) Unregister(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.caches, name)
+}
+
+// cache returns registered cache by name
+func (s *Server) cache(name string) (cache.Cache, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.caches[name]
+
+	return c, ok
+}
+
+// ServeHTTP serves cache requests
+func (s *Server) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	path, ok := strings.CutPrefix(r.URL.EscapedPath(), "/cache/")
+	if !ok || path == "" {
+		nethttp.Error(w, "not found", nethttp.StatusNotFound)
+		return
+	}
+
+	parts := strings.SplitN(path, "/", 2)
+	name, err := url.PathUnescape(parts[0])
+	if err != nil {
+		nethttp.Error(w, err.Error(), nethttp.StatusBadRequest)
+		return
+	}
+
+	// requests are authorized before cache lookup, so unauthorized clients can not probe cache names
+	if s.authorize != nil && !s.authorize(r, name, writeMethods[r.Method]) {
+		nethttp.Error(w, "forbidden", nethttp.StatusForbidden)
+		return
+	}
+
+	c, ok := s.cache(name)
+	if !ok {
+		nethttp.Error(w, fmt.Sprintf("cache %s not found", name), nethttp.StatusNotFound)
+		return
+	}
+
+	if len(parts) == 1 || parts[1] == "" {
+		s.serveBatch(w, r, c)
+		return
+	}
+
+	key, err := url.PathUnescape(parts[1])
+	if err != nil {
+		nethttp.Error(w, err.Error(), nethttp.StatusBadRequest)
+		return
+	}
+	s.serveKey(w, r, c, key)
+}
+
+// serveKey serves get, set or delete of key
+func (s *Server) serveKey(w nethttp.ResponseWriter, r *nethttp.Request, c cache.Cache, key string) {
+	switch r.Method {
+	case nethttp.MethodGet:
+		value, err := c.Get(r.Context(), key)
+		if err != nil && !errors.Is(err, cache.ErrNotFound) {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadGateway)
+			return
+		}
+		if value == nil {
+			nethttp.Error(w, fmt.Sprintf("key %s not found", key), nethttp.StatusNotFound)
+			return
+		}
+		writeValue(w, value)
+	case nethttp.MethodPut:
+		options, err := setOptions(r)
+		if err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(nethttp.MaxBytesReader(w, r.Body, s.maxBodySize))
+		if err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusRequestEntityTooLarge)
+			return
+		}
+		if err := c.Set(r.Context(), key, body, options...); err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(nethttp.StatusNoContent)
+	case nethttp.MethodDelete:
+		if err := c.Delete(r.Context(), key); err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(nethttp.StatusNoContent)
+	default:
+		nethttp.Error(w, "method not allowed", nethttp.StatusMethodNotAllowed)
+	}
+}
+
+// serveBatch serves get, set or delete of multiple keys
+func (s *Server) serveBatch(w nethttp.ResponseWriter, r *nethttp.Request, c cache.Cache) {
+	switch r.Method {
+	case nethttp.MethodGet:
+		keys := r.URL.Query()["key"]
+		values, err := getMany(r, c, keys)
+		result := cache.NewBatchResult(keys, values, err)
+		for key, err := range result.Errors {
+			if errors.Is(err, cache.ErrNotFound) {
+				delete(result.Errors, key)
+			}
+		}
+		// values of found keys are returned with keys which failed unless no key is found
+		if len(result.Errors) > 0 && len(result.Values) == 0 {
+			nethttp.Error(w, result.Err().Error(), nethttp.StatusBadGateway)
+			return
+		}
+		for _, key := range result.Failed() {
+			w.Header().Add(FailedKeyHeader, url.PathEscape(key))
+		}
+		encoded := make(map[string]any, len(result.Values))
+		for key, value := range result.Values {
+			encoded[key] = jsonValue(value)
+		}
+		writeJSON(w, encoded)
+	case nethttp.MethodPut:
+		options, err := setOptions(r)
+		if err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadRequest)
+			return
+		}
+		var values map[string]string
+		if err := json.NewDecoder(nethttp.MaxBytesReader(w, r.Body, s.maxBodySize)).Decode(&values); err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadRequest)
+			return
+		}
+		var errs []error
+		for key, value := range values {
+			if err := c.Set(r.Context(), key, []byte(value), options...); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(nethttp.StatusNoContent)
+	case nethttp.MethodDelete:
+		var errs []error
+		for _, key := range r.URL.Query()["key"] {
+			if err := c.Delete(r.Context(), key); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			nethttp.Error(w, err.Error(), nethttp.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(nethttp.StatusNoContent)
+	default:
+		nethttp.Error(w, "method not allowed", nethttp.StatusMethodNotAllowed)
+	}
+}
+
+// getMany retrieves values of keys, at once if cache supports it, returned error joins cache.KeyError of keys
+// which failed, missing keys reported with cache.ErrNotFound are not errors
+func getMany(r *nethttp.Request, c cache.Cache, keys []string) (map[string]any, error) {
+	if batch, ok := c.(batchGetter); ok {
+		return batch.GetMany(r.Context(), keys)
+	}
+
+	values := make(map[string]any, len(keys))
+	var errs []error
+	for _, key := range keys {
+		value, err := c.Get(r.Context(), key)
+		if errors.Is(err, cache.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, &cache.KeyError{Key: key, Err: err})
+			continue
+		}
+		if value != nil {
+			values[key] = value
+		}
+	}
+
+	return values, errors.Join(errs...)
+}
+
+// setOptions returns set options of request ttl header
+func setOptions(r *nethttp.Request) ([]cache.SetOption, error) {
+	header := r.Header.Get(TTLHeader)
+	if header == "" {
+		return nil, nil
+	}
+
+	ttl, err := time.ParseDuration(header)
+	if err != nil {
+		seconds, serr := strconv.Atoi(header)
+		if serr != nil {
+			return nil, fmt.Errorf("invalid %s header: %w", TTLHeader, err)
+		}
+		ttl = time.Duration(seconds) * time.Second
+	}
+
+	return []cache.SetOption{cache.WithTTL(ttl)}, nil
+}
+
+// writeValue writes bytes or string value as is, and other values as json
+func writeValue(w nethttp.ResponseWriter, value any) {
+	switch v := value.(type) {
+	case []byte:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(v)
+	case string:
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = io.WriteString(w, v)
+	default:
+		writeJSON(w, v)
+	}
+}
+
+// jsonValue returns bytes value as string so it is encoded as json string
+func jsonValue(value any) any {
+	if v, ok := value.([]byte); ok {
+		return string(v)
+	}
+
+	return value
+}
+
+// writeJSON writes value as json
+func writeJSON(w nethttp.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		nethttp.Error(w, err.Error(), nethttp.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/memory"
+)
+
+func TestServer(t *testing.T) {
+	c := memory.New()
+	_ = c.Set(context.Background(), "struct", map[string]int{"n": 1})
+
+	s := New(WithAuthorizer(func(r *nethttp.Request, _ string, write bool) bool {
+		return !write || r.Header.Get("Authorization") == "secret"
+	}))
+	s.Register("orders", c)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		ttl        string
+		auth       string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "test unauthorized put", method: nethttp.MethodPut, path: "/cache/orders/a", body: "one", wantStatus: nethttp.StatusForbidden},
+		{name: "test put", method: nethttp.MethodPut, path: "/cache/orders/a", body: "one", auth: "secret", wantStatus: nethttp.StatusNoContent},
+		{name: "test get", method: nethttp.MethodGet, path: "/cache/orders/a", wantStatus: nethttp.StatusOK, wantBody: "one"},
+		{name: "test get json", method: nethttp.MethodGet, path: "/cache/orders/struct", wantStatus: nethttp.StatusOK, wantBody: `{"n":1}`},
+		{name: "test get escaped key", method: nethttp.MethodPut, path: "/cache/orders/b%2Fc", body: "two", auth: "secret", wantStatus: nethttp.StatusNoContent},
+		{name: "test invalid ttl", method: nethttp.MethodPut, path: "/cache/orders/a", ttl: "soon", auth: "secret", wantStatus: nethttp.StatusBadRequest},
+		{name: "test batch put", method: nethttp.MethodPut, path: "/cache/orders", body: `{"d":"four","e":"five"}`, ttl: "60", auth: "secret", wantStatus: nethttp.StatusNoContent},
+		{name: "test batch get", method: nethttp.MethodGet, path: "/cache/orders?key=a&key=b/c&key=d&key=x", wantStatus: nethttp.StatusOK, wantBody: `{"a":"one","b/c":"two","d":"four"}`},
+		{name: "test batch delete", method: nethttp.MethodDelete, path: "/cache/orders?key=a&key=d", auth: "secret", wantStatus: nethttp.StatusNoContent},
+		{name: "test delete", method: nethttp.MethodDelete, path: "/cache/orders/e", auth: "secret", wantStatus: nethttp.StatusNoContent},
+		{name: "test get deleted", method: nethttp.MethodGet, path: "/cache/orders/a", wantStatus: nethttp.StatusNotFound},
+		{name: "test unknown cache", method: nethttp.MethodGet, path: "/cache/users/a", wantStatus: nethttp.StatusNotFound},
+		{name: "test unauthorized put to unknown cache", method: nethttp.MethodPut, path: "/cache/users/a", body: "one", wantStatus: nethttp.StatusForbidden},
+		{name: "test unknown path", method: nethttp.MethodGet, path: "/orders/a", wantStatus: nethttp.StatusNotFound},
+		{name: "test method not allowed", method: nethttp.MethodPost, path: "/cache/orders/a", auth: "secret", wantStatus: nethttp.StatusMethodNotAllowed},
+		{name: "test unauthorized post", method: nethttp.MethodPost, path: "/cache/orders/a", wantStatus: nethttp.StatusForbidden},
+		{name: "test options is read", method: nethttp.MethodOptions, path: "/cache/orders/a", wantStatus: nethttp.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("Authorization", tt.auth)
+			r.Header.Set(TTLHeader, tt.ttl)
+			w := httptest.NewRecorder()
+
+			s.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("ServeHTTP() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(w.Body.String()); tt.wantBody != "" && got != tt.wantBody {
+				t.Errorf("ServeHTTP() body = %v, want %v", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+// failingCache fails to get key failed
+type failingCache struct {
+	cache.Cache
+}
+
+var errFailed = errors.New("failed")
+
+func (c *failingCache) Get(ctx context.Context, key string) (any, error) {
+	if key == "failed" {
+		return nil, errFailed
+	}
+
+	return c.Cache.Get(ctx, key)
+}
+
+func TestServer_NotFoundError(t *testing.T) {
+	c := memory.New(memory.WithNotFoundError())
+	_ = c.Set(context.Background(), "a", []byte("one"))
+
+	s := New()
+	s.Register("orders", &failingCache{Cache: c})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+		wantFailed []string
+	}{
+		{name: "test get missing", path: "/cache/orders/x", wantStatus: nethttp.StatusNotFound},
+		{name: "test batch get missing", path: "/cache/orders?key=a&key=x", wantStatus: nethttp.StatusOK, wantBody: `{"a":"one"}`},
+		{name: "test batch get keeps found values", path: "/cache/orders?key=a&key=failed", wantStatus: nethttp.StatusOK, wantBody: `{"a":"one"}`, wantFailed: []string{"failed"}},
+		{name: "test batch get of failed keys", path: "/cache/orders?key=failed", wantStatus: nethttp.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, httptest.NewRequest(nethttp.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("ServeHTTP() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(w.Body.String()); tt.wantBody != "" && got != tt.wantBody {
+				t.Errorf("ServeHTTP() body = %v, want %v", got, tt.wantBody)
+			}
+			if got := w.Header().Values(FailedKeyHeader); !reflect.DeepEqual(got, tt.wantFailed) {
+				t.Errorf("ServeHTTP() failed keys = %v, want %v", got, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestSetOptions(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{header: "30s", want: 30 * time.Second},
+		{header: "45", want: 45 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r := httptest.NewRequest(nethttp.MethodPut, "/cache/a/b", nil)
+			r.Header.Set(TTLHeader, tt.header)
+
+			options, err := setOptions(r)
+			if err != nil || len(options) != 1 {
+				t.Fatalf("setOptions() = %v, %v", options, err)
+			}
+
+			config := &cache.SetConfiguration{}
+			options[0](config)
+			if config.TTL != tt.want {
+				t.Errorf("setOptions() ttl = %v, want %v", config.TTL, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// Package memcached serves cache over memcached text protocol,
+// so clients with only memcached drivers can use caches of this module
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+)
+
+const (
+	// maxRelativeExpiration is largest expiration time in seconds treated as relative,
+	// larger expiration time is unix timestamp as in memcached
+	maxRelativeExpiration = 60 * 60 * 24 * 30
+	// maxLineLength is maximum length of command line, enough for get of hundreds of keys of 250 bytes
+	maxLineLength = 64 * 1024
+)
+
+var (
+	// ErrServerClosed is returned by Serve after server is closed
+	ErrServerClosed = errors.New("memcached: server closed")
+)
+
+// Server serves cache over memcached text protocol
+//
+// supported commands are get, gets, set, add, replace, delete, version and quit,
+// flags are accepted but not stored and are always returned as 0,
+// add and replace check existence of key before set and are not atomic
+type Server struct {
+	cache       cache.Cache
+	maxItemSize int
+	conns       internal.ConnServer
+}
+
+// Option provides server options
+type Option func(*Server)
+
+// WithMaxItemSize returns option to limit size of stored values, default is 1 MiB
+func WithMaxItemSize(size int) Option {
+	return func(s *Server) {
+		s.maxItemSize = size
+	}
+}
+
+// New returns memcached server of cache
+func New(c cache.Cache, options ...Option) *Server {
+	s := &Server{cache: c, maxItemSize: 1 << 20}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// ListenAndServe listens on tcp address and serves connections
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on listener and serves each of them in its own goroutine
+// it always returns non-nil error, ErrServerClosed after Close
+func (s *Server) Serve(listener net.Listener) error {
+	return s.conns.Serve(listener, s.serve, ErrServerClosed)
+}
+
+// Close closes listener and all connections and waits for them to finish
+func (s *Server) Close() error {
+	return s.conns.Close()
+}
+
+// serve serves commands of connection until it is closed or quit
+func (s *Server) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	ctx := context.Background()
+
+	for {
+		line, err := internal.ReadLine(r, maxLineLength)
+		if errors.Is(err, internal.ErrLineTooLong) {
+			fmt.Fprint(w, "CLIENT_ERROR line too long\r\n")
+			_ = w.Flush()
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			fmt.Fprint(w, "ERROR\r\n")
+		} else if fields[0] == "quit" {
+			return
+		} else if err := s.command(ctx, r, w, fields); err != nil {
+			return
+		}
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// command executes command of fields and writes its reply
+// returned error means connection can not be used anymore
+func (s *Server) command(ctx context.Context, r *bufio.Reader, w *bufio.Writer, fields []string) error {
+	switch fields[0] {
+	case "get", "gets":
+		if len(fields) < 2 {
+			fmt.Fprint(w, "ERROR\r\n")
+			return nil
+		}
+		for _, key := range fields[1:] {
+			value, err := s.get(ctx, key)
+			if err != nil {
+				fmt.Fprintf(w, "SERVER_ERROR %s\r\n", err)
+				return nil
+			}
+			if value == nil {
+				continue
+			}
+			data, ok := internal.BytesOf(value)
+			if !ok {
+				continue
+			}
+			if fields[0] == "gets" {
+				fmt.Fprintf(w, "VALUE %s 0 %d 0\r\n", key, len(data))
+			} else {
+				fmt.Fprintf(w, "VALUE %s 0 %d\r\n", key, len(data))
+			}
+			w.Write(data)
+			fmt.Fprint(w, "\r\n")
+		}
+		fmt.Fprint(w, "END\r\n")
+	case "set", "add", "replace":
+		return s.store(ctx, r, w, fields)
+	case "delete":
+		if len(fields) < 2 {
+			fmt.Fprint(w, "ERROR\r\n")
+			return nil
+		}
+		noreply := fields[len(fields)-1] == "noreply"
+		value, err := s.get(ctx, fields[1])
+		if err == nil && value != nil {
+			err = s.cache.Delete(ctx, fields[1])
+		}
+		if errors.Is(err, cache.ErrNotFound) {
+			value, err = nil, nil
+		}
+		switch {
+		case noreply:
+		case err != nil:
+			fmt.Fprintf(w, "SERVER_ERROR %s\r\n", err)
+		case value == nil:
+			fmt.Fprint(w, "NOT_FOUND\r\n")
+		default:
+			fmt.Fprint(w, "DELETED\r\n")
+		}
+	case "version":
+		fmt.Fprint(w, "VERSION albinzx-cache\r\n")
+	default:
+		fmt.Fprint(w, "ERROR\r\n")
+	}
+
+	return nil
+}
+
+// get returns value of key, missing key reported with cache.ErrNotFound is nil
+func (s *Server) get(ctx context.Context, key string) (any, error) {
+	value, err := s.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, nil
+	}
+
+	return value, err
+}
+
+// store executes storage command "<command> <key> <flags> <exptime> <bytes> [noreply]"
+func (s *Server) store(ctx context.Context, r *bufio.Reader, w *bufio.Writer, fields []string) error {
+	if len(fields) < 5 {
+		fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		return nil
+	}
+
+	exptime, err1 := strconv.ParseInt(fields[3], 10, 64)
+	size, err2 := strconv.Atoi(fields[4])
+	if err1 != nil || err2 != nil || size < 0 {
+		fmt.Fprint(w, "CLIENT_ERROR bad command line format\r\n")
+		return nil
+	}
+	if size > s.maxItemSize {
+		fmt.Fprint(w, "SERVER_ERROR object too large for cache\r\n")
+		// data block is discarded so connection stays in sync
+		_, err := r.Discard(size + 2)
+		return err
+	}
+
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return err
+	}
+	if string(data[size:]) != "\r\n" {
+		fmt.Fprint(w, "CLIENT_ERROR bad data chunk\r\n")
+		if data[size+1] != '\n' {
+			// rest of oversized data block is discarded, connection is closed if it does not end within item size
+			_, err := internal.ReadLine(r, s.maxItemSize)
+			return err
+		}
+		return nil
+	}
+	data = data[:size]
+
+	noreply := len(fields) > 5 && fields[5] == "noreply"
+	reply := func(format string, args ...any) {
+		if !noreply {
+			fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	if fields[0] != "set" {
+		value, err := s.get(ctx, fields[1])
+		if err != nil {
+			reply("SERVER_ERROR %s\r\n", err)
+			return nil
+		}
+		if (fields[0] == "add") == (value != nil) {
+			reply("NOT_STORED\r\n")
+			return nil
+		}
+	}
+
+	var options []cache.SetOption
+	if ttl, expired := expiration(exptime, time.Now()); expired {
+		// already expired value is removed as memcached does
+		if err := s.cache.Delete(ctx, fields[1]); err != nil {
+			reply("SERVER_ERROR %s\r\n", err)
+			return nil
+		}
+		reply("STORED\r\n")
+		return nil
+	} else if ttl > 0 {
+		options = append(options, cache.WithTTL(ttl))
+	}
+
+	if err := s.cache.Set(ctx, fields[1], data, options...); err != nil {
+		reply("SERVER_ERROR %s\r\n", err)
+		return nil
+	}
+	reply("STORED\r\n")
+
+	return nil
+}
+
+// expiration returns time to live of memcached expiration time,
+// which is relative seconds up to 30 days or unix timestamp
+// zero ttl means default ttl and expired reports whether value is already expired
+func expiration(exptime int64, now time.Time) (ttl time.Duration, expired bool) {
+	switch {
+	case exptime == 0:
+		return 0, false
+	case exptime < 0:
+		return 0, true
+	case exptime <= maxRelativeExpiration:
+		return time.Duration(exptime) * time.Second, false
+	default:
+		ttl = time.Unix(exptime, 0).Sub(now)
+		return ttl, ttl <= 0
+	}
+}
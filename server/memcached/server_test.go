@@ -0,0 +1,150 @@
+package memcached
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/memory"
+)
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	s := New(memory.New(), WithMaxItemSize(8))
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(listener) }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{name: "test set", command: "set a 0 0 3\r\none\r\n", want: "STORED\r\n"},
+		{name: "test get", command: "get a b\r\n", want: "VALUE a 0 3\r\none\r\nEND\r\n"},
+		{name: "test gets", command: "gets a\r\n", want: "VALUE a 0 3 0\r\none\r\nEND\r\n"},
+		{name: "test add existing", command: "add a 0 0 3\r\ntwo\r\n", want: "NOT_STORED\r\n"},
+		{name: "test replace missing", command: "replace b 0 0 3\r\ntwo\r\n", want: "NOT_STORED\r\n"},
+		{name: "test add noreply", command: "add b 0 60 3 noreply\r\ntwo\r\nget b\r\n", want: "VALUE b 0 3\r\ntwo\r\nEND\r\n"},
+		{name: "test too large", command: "set c 0 0 9\r\n123456789\r\n", want: "SERVER_ERROR object too large for cache\r\n"},
+		{name: "test bad chunk", command: "set c 0 0 1\r\nab\r\n", want: "CLIENT_ERROR bad data chunk\r\n"},
+		{name: "test delete", command: "delete a\r\n", want: "DELETED\r\n"},
+		{name: "test delete missing", command: "delete a\r\n", want: "NOT_FOUND\r\n"},
+		{name: "test expired set", command: "set b 0 -1 1\r\nx\r\nget b\r\n", want: "STORED\r\nEND\r\n"},
+		{name: "test unknown", command: "incr a 1\r\n", want: "ERROR\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := io.WriteString(conn, tt.command); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+			got := make([]byte, len(tt.want))
+			if _, err := io.ReadFull(r, got); err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := <-served; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Serve() error = %v, want %v", err, ErrServerClosed)
+	}
+}
+
+func TestServer_NotFoundError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	// missing keys of cache returning cache.ErrNotFound are answered like missing keys of memcached
+	s := New(memory.New(memory.WithNotFoundError()))
+	go func() { _ = s.Serve(listener) }()
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{name: "test get missing", command: "get a\r\n", want: "END\r\n"},
+		{name: "test delete missing", command: "delete a\r\n", want: "NOT_FOUND\r\n"},
+		{name: "test replace missing", command: "replace a 0 0 3\r\none\r\n", want: "NOT_STORED\r\n"},
+		{name: "test add missing", command: "add a 0 0 3\r\none\r\n", want: "STORED\r\n"},
+		{name: "test line too long", command: strings.Repeat("a", maxLineLength+1) + "\r\n", want: "CLIENT_ERROR line too long\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := io.WriteString(conn, tt.command); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+			got := make([]byte, len(tt.want))
+			if _, err := io.ReadFull(r, got); err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("reply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// connection is closed after too long line
+	if _, err := r.ReadByte(); err == nil {
+		t.Error("ReadByte() error = nil, want closed connection")
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	now := time.Unix(1000000000, 0)
+
+	tests := []struct {
+		name        string
+		exptime     int64
+		wantTTL     time.Duration
+		wantExpired bool
+	}{
+		{name: "test default", exptime: 0},
+		{name: "test relative", exptime: 60, wantTTL: time.Minute},
+		{name: "test absolute", exptime: now.Unix() + 3600, wantTTL: time.Hour},
+		{name: "test absolute past", exptime: now.Unix() - 1, wantTTL: -time.Second, wantExpired: true},
+		{name: "test negative", exptime: -1, wantExpired: true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimPrefix(tt.name, "test "), func(t *testing.T) {
+			ttl, expired := expiration(tt.exptime, now)
+			if ttl != tt.wantTTL || expired != tt.wantExpired {
+				t.Errorf("expiration() = %v, %v, want %v, %v", ttl, expired, tt.wantTTL, tt.wantExpired)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+// Package resp serves cache over redis serialization protocol,
+// so redis-cli and redis clients can read and write caches of this module
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+)
+
+var (
+	// ErrServerClosed is returned by Serve after server is closed
+	ErrServerClosed = errors.New("resp: server closed")
+
+	// errProtocol is returned when request does not follow the protocol
+	errProtocol = errors.New("protocol error")
+)
+
+const (
+	// maxMultibulkLength is maximum number of arguments of request, like limit of redis
+	maxMultibulkLength = 1024 * 1024
+	// maxInlineLength is maximum length of line of request, like limit of inline requests of redis
+	maxInlineLength = 64 * 1024
+)
+
+// Server serves cache over redis serialization protocol
+//
+// supported commands are PING, GET, SET with EX, PX, NX and XX, DEL, EXISTS, EXPIRE, MGET, COMMAND and QUIT,
+// NX and XX are sent to cache as cache.IfNotExists and cache.IfExists, EXPIRE reads the key before writing it
+// and is not atomic, values which are neither bytes nor string can not be read
+type Server struct {
+	cache       cache.Cache
+	maxBulkSize int
+	conns       internal.ConnServer
+}
+
+// Option provides server options
+type Option func(*Server)
+
+// WithMaxBulkSize returns option to limit size of bulk strings of requests, default is 1 MiB
+func WithMaxBulkSize(size int) Option {
+	return func(s *Server) {
+		s.maxBulkSize = size
+	}
+}
+
+// New returns resp server of cache
+func New(c cache.Cache, options ...Option) *Server {
+	s := &Server{cache: c, maxBulkSize: 1 << 20}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// ListenAndServe listens on tcp address and serves connections
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve accepts connections on listener and serves each of them in its own goroutine
+// it always returns non-nil error, ErrServerClosed after Close
+func (s *Server) Serve(listener net.Listener) error {
+	return s.conns.Serve(listener, s.serve, ErrServerClosed)
+}
+
+// Close closes listener and all connections and waits for them to finish
+func (s *Server) Close() error {
+	return s.conns.Close()
+}
+
+// serve serves commands of connection until it is closed or quit
+func (s *Server) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	w := bufio.NewWriter(conn)
+	ctx := context.Background()
+
+	for {
+		args, err := s.read(r)
+		if err != nil {
+			if errors.Is(err, errProtocol) {
+				writeError(w, "ERR "+err.Error())
+				_ = w.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := strings.EqualFold(args[0], "quit")
+		if quit {
+			writeSimple(w, "OK")
+		} else {
+			s.command(ctx, w, args)
+		}
+
+		if err := w.Flush(); err != nil || quit {
+			return
+		}
+	}
+}
+
+// read reads command of array of bulk strings or inline command
+func (s *Server) read(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 || n > maxMultibulkLength {
+		return nil, fmt.Errorf("%w: invalid multibulk length", errProtocol)
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		header, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(header, "$") {
+			return nil, fmt.Errorf("%w: expected '$', got '%.1s'", errProtocol, header)
+		}
+
+		size, err := strconv.Atoi(header[1:])
+		if err != nil || size < 0 || size > s.maxBulkSize {
+			return nil, fmt.Errorf("%w: invalid bulk length", errProtocol)
+		}
+
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		if data[size] != '\r' || data[size+1] != '\n' {
+			return nil, fmt.Errorf("%w: expected CRLF after bulk string", errProtocol)
+		}
+		args[i] = string(data[:size])
+	}
+
+	return args, nil
+}
+
+// readLine reads line without trailing CRLF, line longer than maxInlineLength is protocol error
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := internal.ReadLine(r, maxInlineLength)
+	if errors.Is(err, internal.ErrLineTooLong) {
+		return "", fmt.Errorf("%w: too big inline request", errProtocol)
+	}
+
+	return line, err
+}
+
+// command executes command of args and writes its reply
+func (s *Server) command(ctx context.Context, w *bufio.Writer, args []string) {
+	name := strings.ToUpper(args[0])
+	arity := map[string]int{"PING": 1, "GET": 2, "SET": 3, "DEL": 2, "EXISTS": 2, "EXPIRE": 3, "MGET": 2, "COMMAND": 1}
+
+	minArgs, ok := arity[name]
+	if !ok {
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", args[0]))
+		return
+	}
+	if len(args) < minArgs {
+		writeError(w, fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(name)))
+		return
+	}
+
+	switch name {
+	case "PING":
+		if len(args) > 1 {
+			writeBulk(w, []byte(args[1]))
+		} else {
+			writeSimple(w, "PONG")
+		}
+	case "GET":
+		value, err := s.get(ctx, args[1])
+		if err != nil {
+			writeError(w, "ERR "+err.Error())
+			return
+		}
+		if value == nil {
+			writeNull(w)
+			return
+		}
+		data, ok := internal.BytesOf(value)
+		if !ok {
+			writeError(w, "WRONGTYPE value is not bytes")
+			return
+		}
+		writeBulk(w, data)
+	case "SET":
+		s.set(ctx, w, args)
+	case "DEL", "EXISTS":
+		count := 0
+		for _, key := range args[1:] {
+			value, err := s.get(ctx, key)
+			if err == nil && value != nil && name == "DEL" {
+				err = s.cache.Delete(ctx, key)
+			}
+			if err != nil {
+				writeError(w, "ERR "+err.Error())
+				return
+			}
+			if value != nil {
+				count++
+			}
+		}
+		writeInteger(w, count)
+	case "EXPIRE":
+		seconds, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			writeError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		value, err := s.get(ctx, args[1])
+		if err == nil && value != nil {
+			if seconds <= 0 {
+				err = s.cache.Delete(ctx, args[1])
+			} else {
+				err = s.cache.Set(ctx, args[1], value, cache.WithTTL(time.Duration(seconds)*time.Second))
+			}
+		}
+		if err != nil {
+			writeError(w, "ERR "+err.Error())
+			return
+		}
+		if value == nil {
+			writeInteger(w, 0)
+		} else {
+			writeInteger(w, 1)
+		}
+	case "MGET":
+		fmt.Fprintf(w, "*%d\r\n", len(args)-1)
+		for _, key := range args[1:] {
+			value, err := s.get(ctx, key)
+			if data, ok := internal.BytesOf(value); err == nil && ok {
+				writeBulk(w, data)
+			} else {
+				writeNull(w)
+			}
+		}
+	case "COMMAND":
+		fmt.Fprint(w, "*0\r\n")
+	}
+}
+
+// get returns value of key, missing key reported with cache.ErrNotFound is nil
+func (s *Server) get(ctx context.Context, key string) (any, error) {
+	value, err := s.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, nil
+	}
+
+	return value, err
+}
+
+// set executes "SET key value [EX seconds|PX milliseconds] [NX|XX]"
+func (s *Server) set(ctx context.Context, w *bufio.Writer, args []string) {
+	var options []cache.SetOption
+	var nx, xx bool
+
+	for i := 3; i < len(args); i++ {
+		switch option := strings.ToUpper(args[i]); option {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if i+1 == len(args) {
+				writeError(w, "ERR syntax error")
+				return
+			}
+			i++
+			n, err := strconv.ParseInt(args[i], 10, 64)
+			if err != nil || n <= 0 {
+				writeError(w, "ERR invalid expire time in 'set' command")
+				return
+			}
+			unit := time.Second
+			if option == "PX" {
+				unit = time.Millisecond
+			}
+			options = append(options, cache.WithTTL(time.Duration(n)*unit))
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+
+	if nx && xx {
+		writeError(w, "ERR syntax error")
+		return
+	}
+
+	if nx {
+		options = append(options, cache.IfNotExists())
+	} else if xx {
+		options = append(options, cache.IfExists())
+	}
+
+	err := s.cache.Set(ctx, args[1], []byte(args[2]), options...)
+	if errors.Is(err, cache.ErrNotStored) {
+		writeNull(w)
+		return
+	}
+	if err != nil {
+		writeError(w, "ERR "+err.Error())
+		return
+	}
+	writeSimple(w, "OK")
+}
+
+// writeSimple writes simple string reply
+func writeSimple(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "+%s\r\n", s)
+}
+
+// writeError writes error reply
+func writeError(w *bufio.Writer, s string) {
+	fmt.Fprintf(w, "-%s\r\n", s)
+}
+
+// writeInteger writes integer reply
+func writeInteger(w *bufio.Writer, n int) {
+	fmt.Fprintf(w, ":%d\r\n", n)
+}
+
+// writeBulk writes bulk string reply
+func writeBulk(w *bufio.Writer, data []byte) {
+	fmt.Fprintf(w, "$%d\r\n", len(data))
+	w.Write(data)
+	w.WriteString("\r\n")
+}
+
+// writeNull writes null bulk string reply
+func writeNull(w *bufio.Writer) {
+	w.WriteString("$-1\r\n")
+}
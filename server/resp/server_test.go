@@ -0,0 +1,164 @@
+package resp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache/memory"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func TestServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	s := New(memory.New())
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(listener) }()
+
+	client := goredis.NewClient(&goredis.Options{Addr: listener.Addr().String(), Protocol: 2, DisableIndentity: true})
+	defer client.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		command func() (any, error)
+		want    any
+		wantErr bool
+	}{
+		{name: "test ping", command: func() (any, error) { return client.Ping(ctx).Result() }, want: "PONG"},
+		{name: "test set", command: func() (any, error) { return client.Set(ctx, "a", "one", time.Minute).Result() }, want: "OK"},
+		{name: "test set nx existing", command: func() (any, error) { return client.SetArgs(ctx, "a", "two", goredis.SetArgs{Mode: "NX"}).Result() }, want: "", wantErr: true},
+		{name: "test set xx missing", command: func() (any, error) { return client.SetArgs(ctx, "b", "two", goredis.SetArgs{Mode: "XX"}).Result() }, want: "", wantErr: true},
+		{name: "test get", command: func() (any, error) { return client.Get(ctx, "a").Result() }, want: "one"},
+		{name: "test get missing", command: func() (any, error) { return client.Get(ctx, "b").Result() }, want: "", wantErr: true},
+		{name: "test mget", command: func() (any, error) { return client.MGet(ctx, "a", "b").Result() }, want: []any{"one", nil}},
+		{name: "test exists", command: func() (any, error) { return client.Exists(ctx, "a", "b").Result() }, want: int64(1)},
+		{name: "test expire", command: func() (any, error) { return client.Expire(ctx, "a", time.Minute).Result() }, want: true},
+		{name: "test expire missing", command: func() (any, error) { return client.Expire(ctx, "b", time.Minute).Result() }, want: false},
+		{name: "test del", command: func() (any, error) { return client.Del(ctx, "a", "b").Result() }, want: int64(1)},
+		{name: "test unknown", command: func() (any, error) { return client.Incr(ctx, "a").Result() }, want: int64(0), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.command()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("command error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("command = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := <-served; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Serve() error = %v, want %v", err, ErrServerClosed)
+	}
+}
+
+func TestServer_NotFoundError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	// missing keys of cache returning cache.ErrNotFound are answered like missing keys of redis
+	s := New(memory.New(memory.WithNotFoundError()))
+	go func() { _ = s.Serve(listener) }()
+	defer s.Close()
+
+	client := goredis.NewClient(&goredis.Options{Addr: listener.Addr().String(), Protocol: 2, DisableIndentity: true})
+	defer client.Close()
+	ctx := context.Background()
+
+	if err := client.Get(ctx, "a").Err(); !errors.Is(err, goredis.Nil) {
+		t.Errorf("Get() error = %v, want %v", err, goredis.Nil)
+	}
+	if got, err := client.Exists(ctx, "a").Result(); err != nil || got != 0 {
+		t.Errorf("Exists() = %v, %v, want 0", got, err)
+	}
+	if got, err := client.Expire(ctx, "a", time.Minute).Result(); err != nil || got {
+		t.Errorf("Expire() = %v, %v, want false", got, err)
+	}
+	if got, err := client.Del(ctx, "a").Result(); err != nil || got != 0 {
+		t.Errorf("Del() = %v, %v, want 0", got, err)
+	}
+	if got, err := client.SetArgs(ctx, "a", "one", goredis.SetArgs{Mode: "NX"}).Result(); err != nil || got != "OK" {
+		t.Errorf("SetArgs() NX = %v, %v, want OK", got, err)
+	}
+	if err := client.SetArgs(ctx, "b", "one", goredis.SetArgs{Mode: "XX"}).Err(); !errors.Is(err, goredis.Nil) {
+		t.Errorf("SetArgs() XX error = %v, want %v", err, goredis.Nil)
+	}
+}
+
+func TestServer_read(t *testing.T) {
+	s := New(memory.New(), WithMaxBulkSize(8))
+
+	tests := []struct {
+		name    string
+		request string
+		want    []string
+		wantErr error
+	}{
+		{name: "test command", request: "*2\r\n$3\r\nGET\r\n$1\r\na\r\n", want: []string{"GET", "a"}},
+		{name: "test inline", request: "PING\r\n", want: []string{"PING"}},
+		{name: "test oversized multibulk", request: "*9999999999\r\n", wantErr: errProtocol},
+		{name: "test multibulk above limit", request: "*1048577\r\n", wantErr: errProtocol},
+		{name: "test negative multibulk", request: "*-5\r\n", wantErr: errProtocol},
+		{name: "test oversized bulk", request: "*1\r\n$9999999999\r\n", wantErr: errProtocol},
+		{name: "test bulk above limit", request: "*1\r\n$9\r\n", wantErr: errProtocol},
+		{name: "test negative bulk", request: "*1\r\n$-5\r\n", wantErr: errProtocol},
+		{name: "test bulk without crlf", request: "*1\r\n$3\r\nGETxx", wantErr: errProtocol},
+		{name: "test inline above limit", request: strings.Repeat("a", maxInlineLength+1) + "\r\n", wantErr: errProtocol},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.read(bufio.NewReader(strings.NewReader(tt.request)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("read() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("read() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_ProtocolError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	s := New(memory.New())
+	go func() { _ = s.Serve(listener) }()
+	defer s.Close()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("*9999999999\r\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("ReadString() error = %v", err)
+	}
+	if want := "-ERR protocol error: invalid multibulk length\r\n"; reply != want {
+		t.Errorf("reply = %q, want %q", reply, want)
+	}
+}
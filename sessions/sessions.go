@@ -0,0 +1,195 @@
+// Package sessions implements gorilla sessions store on top of any cacher,
+// so web applications can keep sessions in their cache infrastructure
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/gorilla/securecookie"
+	gosessions "github.com/gorilla/sessions"
+)
+
+// Store is gorilla sessions store keeping session values in cacher,
+// cookie holds only session id, signed if key pairs are set
+// values are gob encoded, so custom types stored in session must be registered with gob.Register
+// to encrypt values at rest, wrap cacher with cache.Encrypted
+type Store struct {
+	cache   cache.Cacher
+	codecs  []securecookie.Codec
+	options gosessions.Options
+	prefix  string
+}
+
+// Option provides store options
+type Option func(*Store)
+
+// WithKeyPairs returns option to sign and optionally encrypt session id cookie,
+// see securecookie.CodecsFromPairs
+func WithKeyPairs(keyPairs ...[]byte) Option {
+	return func(s *Store) {
+		s.codecs = securecookie.CodecsFromPairs(keyPairs...)
+	}
+}
+
+// WithOptions returns option to set default options of sessions, e.g. cookie path and max age
+func WithOptions(options gosessions.Options) Option {
+	return func(s *Store) {
+		s.options = options
+	}
+}
+
+// WithPrefix returns option to set prefix of session keys, default is "session:"
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		s.prefix = prefix
+	}
+}
+
+// New returns store keeping sessions in cacher
+func New(c cache.Cacher, options ...Option) *Store {
+	store := &Store{
+		cache:   c,
+		options: gosessions.Options{Path: "/", MaxAge: 86400 * 30},
+		prefix:  "session:",
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store
+}
+
+// Get returns session of name registered for request, creating it if it is not registered yet
+func (s *Store) Get(r *http.Request, name string) (*gosessions.Session, error) {
+	return gosessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns session of name, loaded from cache if request has cookie of a stored session,
+// otherwise new session is returned
+func (s *Store) New(r *http.Request, name string) (*gosessions.Session, error) {
+	session := gosessions.NewSession(s, name)
+	options := s.options
+	session.Options = &options
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	id, err := s.decodeID(name, cookie.Value)
+	if err != nil {
+		return session, err
+	}
+
+	found, err := s.load(r.Context(), id, session)
+	if err != nil {
+		return session, err
+	}
+	if found {
+		session.ID = id
+		session.IsNew = false
+	}
+
+	return session, nil
+}
+
+// Save stores session in cache and sets session id cookie,
+// session with negative max age is deleted from cache and its cookie is expired
+func (s *Store) Save(r *http.Request, w http.ResponseWriter, session *gosessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			if err := s.cache.Delete(r.Context(), s.prefix+session.ID); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, gosessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		session.ID = strings.TrimRight(base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(32)), "=")
+	}
+
+	if err := s.save(r.Context(), session); err != nil {
+		return err
+	}
+
+	encoded, err := s.encodeID(session.Name(), session.ID)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, gosessions.NewCookie(session.Name(), encoded, session.Options))
+
+	return nil
+}
+
+// save stores session values with time to live of session max age,
+// session without max age is stored with default time to live of cacher
+func (s *Store) save(ctx context.Context, session *gosessions.Session) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(session.Values); err != nil {
+		return err
+	}
+
+	var options []cache.SetOption
+	if session.Options.MaxAge > 0 {
+		options = append(options, cache.WithTTL(time.Duration(session.Options.MaxAge)*time.Second))
+	}
+
+	return s.cache.Set(ctx, s.prefix+session.ID, buf.Bytes(), options...)
+}
+
+// load reads values of session id into session, it reports whether session is found
+func (s *Store) load(ctx context.Context, id string, session *gosessions.Session) (bool, error) {
+	value, err := s.cache.Get(ctx, s.prefix+id)
+	if err != nil || value == nil {
+		return false, err
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return false, errors.New("session value is not bytes")
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&session.Values); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// encodeID returns cookie value of session id, signed if store has codecs
+func (s *Store) encodeID(name, id string) (string, error) {
+	if len(s.codecs) == 0 {
+		return id, nil
+	}
+
+	return securecookie.EncodeMulti(name, id, s.codecs...)
+}
+
+// decodeID returns session id of cookie value
+func (s *Store) decodeID(name, value string) (string, error) {
+	if len(s.codecs) == 0 {
+		return value, nil
+	}
+
+	var id string
+	err := securecookie.DecodeMulti(name, value, &id, s.codecs...)
+
+	return id, err
+}
@@ -0,0 +1,77 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/albinzx/cache/memory"
+)
+
+func TestStore(t *testing.T) {
+	cacher := memory.New()
+	store := New(cacher, WithKeyPairs([]byte("0123456789abcdef0123456789abcdef")))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	session, err := store.Get(r, "sid")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !session.IsNew {
+		t.Errorf("Get() IsNew = %v, want %v", session.IsNew, true)
+	}
+
+	session.Values["user"] = "alice"
+	w := httptest.NewRecorder()
+	if err := store.Save(r, w, session); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, _ := cacher.Get(context.Background(), "session:"+session.ID); got == nil {
+		t.Errorf("Save() cached = %v, want session", got)
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value == session.ID {
+		t.Fatalf("Save() cookies = %v, want signed session id", cookies)
+	}
+
+	tests := []struct {
+		name      string
+		cookie    *http.Cookie
+		wantNew   bool
+		wantUser  any
+		wantError bool
+	}{
+		{name: "test stored session", cookie: cookies[0], wantUser: "alice"},
+		{name: "test no cookie", wantNew: true},
+		{name: "test tampered cookie", cookie: &http.Cookie{Name: "sid", Value: session.ID}, wantNew: true, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.cookie != nil {
+				r.AddCookie(tt.cookie)
+			}
+
+			got, err := store.New(r, "sid")
+			if (err != nil) != tt.wantError {
+				t.Errorf("New() error = %v, wantError %v", err, tt.wantError)
+			}
+			if got.IsNew != tt.wantNew {
+				t.Errorf("New() IsNew = %v, want %v", got.IsNew, tt.wantNew)
+			}
+			if user := got.Values["user"]; user != tt.wantUser {
+				t.Errorf("New() user = %v, want %v", user, tt.wantUser)
+			}
+		})
+	}
+
+	session.Options.MaxAge = -1
+	if err := store.Save(r, httptest.NewRecorder(), session); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if got, _ := cacher.Get(context.Background(), "session:"+session.ID); got != nil {
+		t.Errorf("Save() cached = %v, want %v", got, nil)
+	}
+}
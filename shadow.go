@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+)
+
+// ShadowStats is outcome of shadow reads
+type ShadowStats struct {
+	// Matches is number of reads where cached value equals persisted value
+	Matches int64
+	// Divergences is number of reads where cached value differs from persisted value
+	Divergences int64
+	// Misses is number of reads where value is not cached
+	Misses int64
+	// Errors is number of failed cache operations
+	Errors int64
+}
+
+// Shadow is a cache pattern to roll out caching safely, cache is read and written
+// as usual but callers always get values of persistence storage,
+// cached values are only compared with persisted values to measure divergence
+// persister is required
+type Shadow struct {
+	// Equal compares cached and persisted value, default is reflect.DeepEqual
+	Equal func(cached, persisted any) bool
+	// OnDivergence is called when cached value differs from persisted value
+	OnDivergence func(key string, cached, persisted any)
+
+	matches     atomic.Int64
+	divergences atomic.Int64
+	misses      atomic.Int64
+	errors      atomic.Int64
+}
+
+// Stats returns outcome of shadow reads
+func (s *Shadow) Stats() ShadowStats {
+	return ShadowStats{
+		Matches:     s.matches.Load(),
+		Divergences: s.divergences.Load(),
+		Misses:      s.misses.Load(),
+		Errors:      s.errors.Load(),
+	}
+}
+
+// Set stores key-value to persistence storage and then to cache
+// failure to store to cache is only counted
+func (s *Shadow) Set(ctx context.Context, key string, value any, c Cacher, p Persister, options ...SetOption) error {
+	if err := p.Save(ctx, key, value); err != nil {
+		return err
+	}
+
+	if err := c.Set(ctx, key, value, options...); err != nil {
+		s.errors.Add(1)
+		loggerFrom(ctx).Error(ctx, "failed to set value to cache", "set", key, err)
+	}
+
+	return nil
+}
+
+// Get retrieves value from persistence storage and compares it with cached value
+// missing or divergent cached value is replaced with persisted value
+func (s *Shadow) Get(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
+	persisted, err := p.SelectOne(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := c.Get(ctx, key)
+	if err != nil {
+		s.errors.Add(1)
+		loggerFrom(ctx).Error(ctx, "failed to get value from cache", "get", key, err)
+		return persisted, nil
+	}
+
+	equal := s.Equal
+	if equal == nil {
+		equal = reflect.DeepEqual
+	}
+
+	switch {
+	case cached == nil:
+		s.misses.Add(1)
+	case equal(cached, persisted):
+		s.matches.Add(1)
+		return persisted, nil
+	default:
+		s.divergences.Add(1)
+		if s.OnDivergence != nil {
+			s.OnDivergence(key, cached, persisted)
+		}
+	}
+
+	if persisted == nil {
+		err = c.Delete(ctx, key)
+	} else {
+		err = c.Set(ctx, key, persisted)
+	}
+
+	if err != nil {
+		s.errors.Add(1)
+		loggerFrom(ctx).Error(ctx, "failed to update value in cache", "set", key, err)
+	}
+
+	return persisted, nil
+}
+
+// Delete deletes value from persistence storage and then from cache
+func (s *Shadow) Delete(ctx context.Context, key string, c Cacher, p Persister) error {
+	if err := p.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	if err := c.Delete(ctx, key); err != nil {
+		s.errors.Add(1)
+		loggerFrom(ctx).Error(ctx, "failed to delete value from cache", "delete", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestShadow(t *testing.T) {
+	m := newMapCacher()
+	p := newMapPersister()
+	p.data["key"] = "fresh"
+
+	var divergent []string
+	shadow := &Shadow{OnDivergence: func(key string, _, _ any) { divergent = append(divergent, key) }}
+	c, _ := New(m, p, WithPattern(shadow))
+	ctx := context.Background()
+
+	// miss fills cache, match returns persisted value
+	if got, _ := c.Get(ctx, "key"); got != "fresh" {
+		t.Errorf("Shadow.Get() = %v, want %v", got, "fresh")
+	}
+	_, _ = c.Get(ctx, "key")
+
+	// divergent cached value is never returned
+	m.data["key"] = "stale"
+	if got, _ := c.Get(ctx, "key"); got != "fresh" {
+		t.Errorf("Shadow.Get() = %v, want %v", got, "fresh")
+	}
+
+	_ = c.Set(ctx, "other", "value")
+	if p.data["other"] != "value" || m.data["other"] != "value" {
+		t.Errorf("Shadow.Set() persisted %v cached %v, want value in both", p.data["other"], m.data["other"])
+	}
+
+	want := ShadowStats{Matches: 1, Divergences: 1, Misses: 1}
+	if got := shadow.Stats(); got != want {
+		t.Errorf("Shadow.Stats() = %+v, want %+v", got, want)
+	}
+	if len(divergent) != 1 || divergent[0] != "key" {
+		t.Errorf("Shadow.OnDivergence() keys = %v, want [key]", divergent)
+	}
+}
@@ -0,0 +1,176 @@
+package sharded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// Move is movement of key between members by rebalancing
+type Move struct {
+	Key  string
+	From string
+	To   string
+	// Err is error failing the move, key stays in its former member
+	Err error
+}
+
+// RebalanceReport is result of rebalancing
+type RebalanceReport struct {
+	// Scanned is number of keys scanned
+	Scanned int
+	// Moved is number of keys moved to their owner
+	Moved int
+	// Failed is number of keys which failed to be moved
+	Failed int
+}
+
+// CheckHealth pings members implementing cache.Pinger and returns errors of unhealthy members
+// keys of unhealthy members are owned by the next healthy member until a later check finds them healthy,
+// members which do not implement cache.Pinger are assumed healthy
+func (c *Cacher) CheckHealth(ctx context.Context) map[string]error {
+	c.mu.RLock()
+	members := make([]Member, 0, len(c.members))
+	for _, member := range c.members {
+		members = append(members, member)
+	}
+	c.mu.RUnlock()
+
+	unhealthy := map[string]error{}
+	for _, member := range members {
+		if pinger, ok := member.Cacher.(cache.Pinger); ok {
+			if err := pinger.Ping(ctx); err != nil {
+				unhealthy[member.Name] = err
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.unhealthy = unhealthy
+	c.mu.Unlock()
+
+	return unhealthy
+}
+
+// Healthy reports whether member of name was healthy on the last health check
+func (c *Cacher) Healthy(name string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.unhealthy[name] == nil
+}
+
+// Rebalance moves keys of healthy members which are owned by other members to their owners,
+// e.g. after members are added or keys are pinned, keys are scanned in members implementing cache.Scanner
+// values keep their remaining time to live if member implements cache.TTLReader
+// returned error joins errors of scans, errors of keys are passed to move hook
+func (c *Cacher) Rebalance(ctx context.Context) (RebalanceReport, error) {
+	c.mu.RLock()
+	members := make([]Member, 0, len(c.members))
+	for name, member := range c.members {
+		if c.unhealthy[name] == nil {
+			members = append(members, member)
+		}
+	}
+	c.mu.RUnlock()
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+
+	var report RebalanceReport
+	var errs []error
+	for _, member := range members {
+		if err := c.migrate(ctx, member.Name, member.Cacher, &report); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", member.Name, err))
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// Migrate moves all keys of cacher of member name, e.g. cacher returned by Remove, to their owners
+// cacher must implement cache.Scanner
+func (c *Cacher) Migrate(ctx context.Context, name string, from cache.Cacher) (RebalanceReport, error) {
+	var report RebalanceReport
+	err := c.migrate(ctx, name, from, &report)
+
+	return report, err
+}
+
+// migrate moves keys of cacher of member name which are owned by other members
+func (c *Cacher) migrate(ctx context.Context, name string, from cache.Cacher, report *RebalanceReport) error {
+	scanner, ok := from.(cache.Scanner)
+	if !ok {
+		return nil
+	}
+
+	// keys are collected before they are moved, so iteration is not affected by deletes
+	var keys []string
+	it := scanner.Keys(ctx, "")
+	for it.Next(ctx) {
+		keys = append(keys, it.Key())
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		report.Scanned++
+
+		c.mu.RLock()
+		owner, err := c.locate(key)
+		c.mu.RUnlock()
+		if err != nil {
+			return err
+		}
+		if owner.Name == name {
+			continue
+		}
+
+		move := Move{Key: key, From: name, To: owner.Name}
+		move.Err = c.move(ctx, key, from, owner.Cacher)
+		if move.Err != nil {
+			report.Failed++
+		} else {
+			report.Moved++
+		}
+
+		if c.onMove != nil {
+			c.onMove(move)
+		}
+	}
+
+	return nil
+}
+
+// move copies value of key with its remaining time to live and deletes it from former member
+func (c *Cacher) move(ctx context.Context, key string, from cache.Cacher, to cache.Cacher) error {
+	var value any
+	var ttl time.Duration
+	var err error
+	if reader, ok := from.(cache.TTLReader); ok {
+		value, ttl, err = reader.GetWithTTL(ctx, key)
+	} else {
+		value, err = from.Get(ctx, key)
+	}
+	if err != nil {
+		return err
+	}
+
+	// value expired since it was scanned
+	if value == nil {
+		return nil
+	}
+
+	var options []cache.SetOption
+	if ttl > 0 {
+		options = append(options, cache.WithTTL(ttl))
+	}
+	if err := to.Set(ctx, key, value, options...); err != nil {
+		return err
+	}
+
+	return from.Delete(ctx, key)
+}
@@ -0,0 +1,345 @@
+// Package sharded distributes keys across multiple cachers using consistent hashing,
+// so adding or removing a cacher moves only a small share of keys
+package sharded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/albinzx/cache"
+)
+
+var (
+	// ErrNoMembers is returned when ring has no members
+	ErrNoMembers = errors.New("sharded: ring has no members")
+)
+
+// Member is cacher in the ring
+type Member struct {
+	// Name identifies member in the ring, keys of member depend only on names and weights
+	Name   string
+	Cacher cache.Cacher
+	// Weight is relative share of keys of member, default is 1
+	Weight int
+}
+
+// point is virtual node of member on the ring
+type point struct {
+	hash   uint64
+	member string
+}
+
+// Cacher is cacher distributing keys across members
+// keys of pinned prefixes are owned by their pinned member, other keys by member next on the ring,
+// unhealthy members are skipped and their keys are owned by the next healthy member
+type Cacher struct {
+	mu        sync.RWMutex
+	members   map[string]Member
+	points    []point
+	replicas  int
+	pins      map[string]string
+	prefixes  []string
+	unhealthy map[string]error
+	onMove    func(Move)
+}
+
+// Option provides sharded cacher options
+type Option func(*Cacher)
+
+// WithReplicas returns option to set number of virtual nodes of member of weight 1, default is 160
+func WithReplicas(replicas int) Option {
+	return func(c *Cacher) {
+		c.replicas = replicas
+	}
+}
+
+// WithPin returns option to pin keys starting with prefix to member of name, see Pin
+func WithPin(prefix string, name string) Option {
+	return func(c *Cacher) {
+		c.pin(prefix, name)
+	}
+}
+
+// WithMoveHook returns option to call onMove for every key moved or failed to be moved by rebalancing
+func WithMoveHook(onMove func(Move)) Option {
+	return func(c *Cacher) {
+		c.onMove = onMove
+	}
+}
+
+// New returns cacher distributing keys across members
+func New(members []Member, options ...Option) *Cacher {
+	c := &Cacher{members: map[string]Member{}, replicas: 160, pins: map[string]string{}, unhealthy: map[string]error{}}
+
+	for _, option := range options {
+		option(c)
+	}
+	if c.replicas < 1 {
+		c.replicas = 160
+	}
+
+	for _, member := range members {
+		c.members[member.Name] = normalize(member)
+	}
+	c.build()
+
+	return c
+}
+
+// normalize sets default weight of member
+func normalize(member Member) Member {
+	if member.Weight < 1 {
+		member.Weight = 1
+	}
+
+	return member
+}
+
+// hash returns hash of s
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+
+	// fnv hashes of similar strings are close, finalizer of splitmix64 spreads them over the ring
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return x
+}
+
+// build rebuilds ring of members, lock must be held
+func (c *Cacher) build() {
+	points := make([]point, 0, len(c.members)*c.replicas)
+	for name, member := range c.members {
+		for i := 0; i < member.Weight*c.replicas; i++ {
+			points = append(points, point{hash: hash(name + "#" + strconv.Itoa(i)), member: name})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash == points[j].hash {
+			return points[i].member < points[j].member
+		}
+		return points[i].hash < points[j].hash
+	})
+
+	c.points = points
+}
+
+// Add adds member to the ring or replaces member of the same name,
+// replaced cacher is not closed
+func (c *Cacher) Add(member Member) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.members[member.Name] = normalize(member)
+	c.build()
+}
+
+// Remove removes member of name from the ring and returns its cacher, it is not closed
+func (c *Cacher) Remove(name string) (cache.Cacher, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	member, ok := c.members[name]
+	if !ok {
+		return nil, false
+	}
+	delete(c.members, name)
+	delete(c.unhealthy, name)
+	c.build()
+
+	return member.Cacher, true
+}
+
+// Members returns sorted names of members
+func (c *Cacher) Members() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.members))
+	for name := range c.members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Locate returns name of member owning key
+func (c *Cacher) Locate(key string) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	member, err := c.locate(key)
+
+	return member.Name, err
+}
+
+// locate returns member owning key, read lock must be held
+// if all members are unhealthy, key is owned by its member on the ring
+func (c *Cacher) locate(key string) (Member, error) {
+	if len(c.points) == 0 {
+		return Member{}, ErrNoMembers
+	}
+
+	for _, prefix := range c.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			name := c.pins[prefix]
+			if _, ok := c.members[name]; ok && c.unhealthy[name] == nil {
+				return c.members[name], nil
+			}
+			break
+		}
+	}
+
+	h := hash(key)
+	i := sort.Search(len(c.points), func(i int) bool { return c.points[i].hash >= h })
+	if i == len(c.points) {
+		i = 0
+	}
+
+	for n := 0; n < len(c.points); n++ {
+		name := c.points[(i+n)%len(c.points)].member
+		if c.unhealthy[name] == nil {
+			return c.members[name], nil
+		}
+	}
+
+	return c.members[c.points[i].member], nil
+}
+
+// Pin pins keys starting with prefix to member of name, the longest matching prefix wins
+// keys are owned by the ring while member is not in the ring or is unhealthy
+// keys already cached are moved to pinned member by Rebalance
+func (c *Cacher) Pin(prefix string, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pin(prefix, name)
+}
+
+// pin pins prefix to member, lock must be held
+func (c *Cacher) pin(prefix string, name string) {
+	c.pins[prefix] = name
+	c.sortPins()
+}
+
+// Unpin removes pin of prefix
+func (c *Cacher) Unpin(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pins, prefix)
+	c.sortPins()
+}
+
+// sortPins sorts pinned prefixes from the longest, lock must be held
+func (c *Cacher) sortPins() {
+	c.prefixes = c.prefixes[:0]
+	for prefix := range c.pins {
+		c.prefixes = append(c.prefixes, prefix)
+	}
+	sort.Slice(c.prefixes, func(i, j int) bool {
+		if len(c.prefixes[i]) != len(c.prefixes[j]) {
+			return len(c.prefixes[i]) > len(c.prefixes[j])
+		}
+		return c.prefixes[i] < c.prefixes[j]
+	})
+}
+
+// shard returns cacher owning key
+func (c *Cacher) shard(key string) (cache.Cacher, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	member, err := c.locate(key)
+
+	return member.Cacher, err
+}
+
+// Set sets key-value to member owning key
+func (c *Cacher) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	shard, err := c.shard(key)
+	if err != nil {
+		return err
+	}
+
+	return shard.Set(ctx, key, value, options...)
+}
+
+// Get gets value from member owning key
+func (c *Cacher) Get(ctx context.Context, key string) (any, error) {
+	shard, err := c.shard(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return shard.Get(ctx, key)
+}
+
+// Delete deletes value from member owning key
+func (c *Cacher) Delete(ctx context.Context, key string) error {
+	shard, err := c.shard(key)
+	if err != nil {
+		return err
+	}
+
+	return shard.Delete(ctx, key)
+}
+
+// Load loads key-values to their members, one load per member
+func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
+	type batch struct {
+		cacher cache.Cacher
+		data   map[string]any
+	}
+
+	c.mu.RLock()
+	batches := map[string]*batch{}
+	for key, value := range data {
+		member, err := c.locate(key)
+		if err != nil {
+			c.mu.RUnlock()
+			return err
+		}
+		if batches[member.Name] == nil {
+			batches[member.Name] = &batch{cacher: member.Cacher, data: map[string]any{}}
+		}
+		batches[member.Name].data[key] = value
+	}
+	c.mu.RUnlock()
+
+	var errs []error
+	for name, batch := range batches {
+		if err := batch.cacher.Load(ctx, batch.data); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close closes cachers of all members
+func (c *Cacher) Close() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var errs []error
+	for name, member := range c.members {
+		if err := member.Cacher.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,197 @@
+package sharded
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/memory"
+)
+
+func TestCacher_Distribution(t *testing.T) {
+	c := New([]Member{
+		{Name: "a", Cacher: memory.New()},
+		{Name: "b", Cacher: memory.New()},
+		{Name: "c", Cacher: memory.New(), Weight: 2},
+	})
+
+	const keys = 20000
+	owners := make(map[string]string, keys)
+	counts := map[string]int{}
+	for i := 0; i < keys; i++ {
+		key := "key" + strconv.Itoa(i)
+		owner, err := c.Locate(key)
+		if err != nil {
+			t.Fatalf("Locate() error = %v", err)
+		}
+		owners[key] = owner
+		counts[owner]++
+	}
+
+	want := map[string]float64{"a": 0.25, "b": 0.25, "c": 0.5}
+	for name, share := range want {
+		if got := float64(counts[name]) / keys; math.Abs(got-share) > 0.05 {
+			t.Errorf("Locate() share of %s = %v, want %v", name, got, share)
+		}
+	}
+
+	c.Add(Member{Name: "d", Cacher: memory.New(), Weight: 2})
+	for key, owner := range owners {
+		if got, _ := c.Locate(key); got != owner && got != "d" {
+			t.Errorf("Locate(%s) = %v after add, want %v or d", key, got, owner)
+		}
+	}
+
+	c.Remove("d")
+	for key, owner := range owners {
+		if got, _ := c.Locate(key); got != owner {
+			t.Errorf("Locate(%s) = %v after remove, want %v", key, got, owner)
+		}
+	}
+}
+
+func TestCacher(t *testing.T) {
+	ctx := context.Background()
+	a, b := memory.New(), memory.New()
+	c := New([]Member{{Name: "a", Cacher: a}, {Name: "b", Cacher: b}})
+
+	data := map[string]any{}
+	for i := 0; i < 100; i++ {
+		data["key"+strconv.Itoa(i)] = i
+	}
+	if err := c.Load(ctx, data); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for key, value := range data {
+		if got, _ := c.Get(ctx, key); got != value {
+			t.Errorf("Get(%s) = %v, want %v", key, got, value)
+		}
+
+		owner, _ := c.Locate(key)
+		shard, other := a, b
+		if owner == "b" {
+			shard, other = b, a
+		}
+		if got, _ := shard.Get(ctx, key); got != value {
+			t.Errorf("Get(%s) of owner = %v, want %v", key, got, value)
+		}
+		if got, _ := other.Get(ctx, key); got != nil {
+			t.Errorf("Get(%s) of other = %v, want %v", key, got, nil)
+		}
+	}
+
+	if err := c.Delete(ctx, "key1"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if got, _ := c.Get(ctx, "key1"); got != nil {
+		t.Errorf("Get() = %v, want %v", got, nil)
+	}
+
+	if _, err := New(nil).Get(ctx, "key"); err != ErrNoMembers {
+		t.Errorf("Get() error = %v, want %v", err, ErrNoMembers)
+	}
+}
+
+// downCacher is memory cacher failing pings
+type downCacher struct {
+	*memory.Cacher
+}
+
+func (d *downCacher) Ping(context.Context) error {
+	return errors.New("down")
+}
+
+func TestCacher_Pin(t *testing.T) {
+	c := New([]Member{{Name: "a", Cacher: memory.New()}, {Name: "b", Cacher: memory.New()}}, WithPin("user.", "a"))
+	c.Pin("user.vip.", "b")
+
+	for i := 0; i < 100; i++ {
+		if got, _ := c.Locate("user." + strconv.Itoa(i)); got != "a" {
+			t.Fatalf("Locate() of pinned key = %v, want %v", got, "a")
+		}
+	}
+	if got, _ := c.Locate("user.vip.1"); got != "b" {
+		t.Errorf("Locate() of longer pinned prefix = %v, want %v", got, "b")
+	}
+
+	c.Unpin("user.vip.")
+	if got, _ := c.Locate("user.vip.1"); got != "a" {
+		t.Errorf("Locate() after Unpin() = %v, want %v", got, "a")
+	}
+}
+
+func TestCacher_CheckHealth(t *testing.T) {
+	ctx := context.Background()
+	down := &downCacher{memory.New()}
+	c := New([]Member{{Name: "a", Cacher: memory.New()}, {Name: "b", Cacher: down}}, WithPin("pinned.", "b"))
+
+	if unhealthy := c.CheckHealth(ctx); len(unhealthy) != 1 || unhealthy["b"] == nil {
+		t.Fatalf("CheckHealth() = %v, want b unhealthy", unhealthy)
+	}
+	if c.Healthy("b") || !c.Healthy("a") {
+		t.Errorf("Healthy() = %v, %v, want a healthy and b unhealthy", c.Healthy("a"), c.Healthy("b"))
+	}
+
+	for i := 0; i < 100; i++ {
+		if got, _ := c.Locate("key" + strconv.Itoa(i)); got != "a" {
+			t.Fatalf("Locate() with unhealthy member = %v, want %v", got, "a")
+		}
+	}
+	if got, _ := c.Locate("pinned.1"); got != "a" {
+		t.Errorf("Locate() of key pinned to unhealthy member = %v, want %v", got, "a")
+	}
+}
+
+func TestCacher_Rebalance(t *testing.T) {
+	ctx := context.Background()
+	a, b := memory.New(), memory.New()
+
+	var moves []Move
+	c := New([]Member{{Name: "a", Cacher: a}}, WithMoveHook(func(m Move) { moves = append(moves, m) }))
+	for i := 0; i < 100; i++ {
+		_ = c.Set(ctx, "key"+strconv.Itoa(i), i, cache.WithTTL(time.Hour))
+	}
+
+	c.Add(Member{Name: "b", Cacher: b})
+	report, err := c.Rebalance(ctx)
+	if err != nil {
+		t.Fatalf("Rebalance() error = %v", err)
+	}
+	if report.Scanned < 100 || report.Moved == 0 || report.Moved != len(moves) || report.Failed != 0 {
+		t.Errorf("Rebalance() = %+v, %v moves", report, len(moves))
+	}
+
+	for i := 0; i < 100; i++ {
+		key := "key" + strconv.Itoa(i)
+		if got, _ := c.Get(ctx, key); got != i {
+			t.Errorf("Get(%s) after Rebalance() = %v, want %v", key, got, i)
+		}
+	}
+	for _, m := range moves {
+		if m.From != "a" || m.To != "b" || m.Err != nil {
+			t.Errorf("move = %+v, want from a to b", m)
+		}
+		if _, ttl, _ := b.GetWithTTL(ctx, m.Key); ttl <= 0 || ttl > time.Hour {
+			t.Errorf("ttl of moved %s = %v, want remaining ttl", m.Key, ttl)
+		}
+	}
+
+	// keys of removed member are migrated
+	moved := len(moves)
+	removed, _ := c.Remove("b")
+	report, err = c.Migrate(ctx, "b", removed)
+	if err != nil || report.Moved != moved {
+		t.Errorf("Migrate() = %+v, %v, want %v moved", report, err, moved)
+	}
+	for i := 0; i < 100; i++ {
+		key := "key" + strconv.Itoa(i)
+		if got, _ := c.Get(ctx, key); got != i {
+			t.Errorf("Get(%s) after Migrate() = %v, want %v", key, got, i)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// sessionKey is context key of read-your-writes session
+type sessionKey struct{}
+
+// writeSession tracks recent writes of a session
+type writeSession struct {
+	mu     sync.Mutex
+	writes map[string]time.Time
+}
+
+// ReadYourWrites returns context starting read-your-writes session
+// reads through split cacher with this context return values written earlier
+// in the same session even before writes propagate to the read cacher
+func ReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &writeSession{writes: make(map[string]time.Time)})
+}
+
+// split is cacher reading from one cacher and writing to another
+type split struct {
+	Cacher
+	reader Cacher
+	delay  time.Duration
+}
+
+// SplitOption provides read/write splitting options
+type SplitOption func(*split)
+
+// WithPropagationDelay returns option to set how long after a write the key
+// is read from the write cacher within a read-your-writes session, default is one second
+func WithPropagationDelay(delay time.Duration) SplitOption {
+	return func(s *split) {
+		s.delay = delay
+	}
+}
+
+// Split returns cacher sending gets to reader, e.g. redis replica,
+// and sets, deletes and loads to writer, e.g. redis primary
+func Split(reader, writer Cacher, options ...SplitOption) Cacher {
+	s := &split{Cacher: writer, reader: reader, delay: time.Second}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// written records write of key in session of context
+func (s *split) written(ctx context.Context, keys ...string) {
+	session, ok := ctx.Value(sessionKey{}).(*writeSession)
+	if !ok {
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range keys {
+		session.writes[key] = now
+	}
+}
+
+// recent reports whether key was written within propagation delay in session of context
+func (s *split) recent(ctx context.Context, key string) bool {
+	session, ok := ctx.Value(sessionKey{}).(*writeSession)
+	if !ok {
+		return false
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	written, ok := session.writes[key]
+	if !ok {
+		return false
+	}
+
+	if time.Since(written) > s.delay {
+		delete(session.writes, key)
+		return false
+	}
+
+	return true
+}
+
+// Set sets key-value to writer
+func (s *split) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	defer s.written(ctx, key)
+
+	return s.Cacher.Set(ctx, key, value, options...)
+}
+
+// Get gets value from reader, or from writer if key was recently written in session
+func (s *split) Get(ctx context.Context, key string) (any, error) {
+	if s.recent(ctx, key) {
+		return s.Cacher.Get(ctx, key)
+	}
+
+	return s.reader.Get(ctx, key)
+}
+
+// Delete deletes value from writer
+func (s *split) Delete(ctx context.Context, key string) error {
+	defer s.written(ctx, key)
+
+	return s.Cacher.Delete(ctx, key)
+}
+
+// Load loads key-values into writer
+func (s *split) Load(ctx context.Context, data map[string]any) error {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	defer s.written(ctx, keys...)
+
+	return s.Cacher.Load(ctx, data)
+}
+
+// Close closes reader and writer
+func (s *split) Close() error {
+	return errors.Join(s.reader.Close(), s.Cacher.Close())
+}
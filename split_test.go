@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSplit(t *testing.T) {
+	reader, writer := newMapCacher(), newMapCacher()
+	c := Split(reader, writer, WithPropagationDelay(50*time.Millisecond))
+
+	ctx := context.Background()
+	session := ReadYourWrites(ctx)
+	_ = c.Set(session, "key", "value")
+
+	if _, ok := reader.data["key"]; ok {
+		t.Errorf("Split().Set() wrote to reader")
+	}
+	if got, _ := c.Get(ctx, "key"); got != nil {
+		t.Errorf("Split().Get() without session = %v, want read from reader", got)
+	}
+	if got, _ := c.Get(session, "key"); got != "value" {
+		t.Errorf("Split().Get() in session = %v, want %v", got, "value")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if got, _ := c.Get(session, "key"); got != nil {
+		t.Errorf("Split().Get() after propagation delay = %v, want read from reader", got)
+	}
+}
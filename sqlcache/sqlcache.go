@@ -0,0 +1,208 @@
+// Package sqlcache caches result sets of database/sql queries in any cacher,
+// cached results are invalidated per table
+package sqlcache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+func init() {
+	// time values are returned by most drivers and must be registered to be encoded in any
+	gob.Register(time.Time{})
+}
+
+// Querier runs queries, it is implemented by *sql.DB, *sql.Tx and *sql.Conn
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Result is cached result set of query
+type Result struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// Cache caches results of queries keyed by normalized query, args and generations of queried tables
+// invalidating a table changes its generation so results of queries of the table are no longer found
+type Cache struct {
+	cache cache.Cacher
+	db    Querier
+	ttl   time.Duration
+}
+
+// Option provides query cache options
+type Option func(*Cache)
+
+// WithTTL returns option to set time to live of cached results, default is cacher TTL
+func WithTTL(ttl time.Duration) Option {
+	return func(c *Cache) {
+		c.ttl = ttl
+	}
+}
+
+// New returns query cache of db storing results in c
+func New(c cache.Cacher, db Querier, options ...Option) *Cache {
+	qc := &Cache{cache: c, db: db}
+
+	for _, option := range options {
+		option(qc)
+	}
+
+	return qc
+}
+
+// Query returns result of query reading tables, from cache if it is cached
+// values of result are as returned by the driver, e.g. int64, float64, []byte, string or time.Time
+func (c *Cache) Query(ctx context.Context, tables []string, query string, args ...any) (*Result, error) {
+	key, err := c.key(ctx, tables, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if result, ok := c.lookup(ctx, key); ok {
+		return result, nil
+	}
+
+	result, err := c.query(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(result); err == nil {
+		var options []cache.SetOption
+		if c.ttl > 0 {
+			options = append(options, cache.WithTTL(c.ttl))
+		}
+		_ = c.cache.Set(ctx, key, buf.Bytes(), options...)
+	}
+
+	return result, nil
+}
+
+// Invalidate invalidates cached results of queries reading any of tables
+func (c *Cache) Invalidate(ctx context.Context, tables ...string) error {
+	for _, table := range tables {
+		if _, err := c.renew(ctx, table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generation returns current generation of table, new generation is set if table has none,
+// e.g. its generation expired, so results cached with earlier generations are never found again
+func (c *Cache) generation(ctx context.Context, table string) (string, error) {
+	generation, err := c.cache.Get(ctx, tableKey(table))
+	if err != nil {
+		return "", err
+	}
+	if generation != nil {
+		return fmt.Sprint(generation), nil
+	}
+
+	return c.renew(ctx, table)
+}
+
+// renew sets new generation of table
+func (c *Cache) renew(ctx context.Context, table string) (string, error) {
+	generation := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	return generation, c.cache.Set(ctx, tableKey(table), generation)
+}
+
+// query runs query and reads all rows
+func (c *Cache) query(ctx context.Context, query string, args []any) (*Result, error) {
+	rows, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Columns: columns, Rows: [][]any{}}
+	for rows.Next() {
+		values := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			// drivers may reuse byte slices between rows
+			if b, ok := value.([]byte); ok {
+				values[i] = bytes.Clone(b)
+			}
+		}
+		result.Rows = append(result.Rows, values)
+	}
+
+	return result, rows.Err()
+}
+
+// lookup returns cached result of key
+func (c *Cache) lookup(ctx context.Context, key string) (*Result, bool) {
+	value, err := c.cache.Get(ctx, key)
+	if err != nil || value == nil {
+		return nil, false
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, false
+	}
+
+	result := &Result{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(result); err != nil {
+		return nil, false
+	}
+
+	return result, true
+}
+
+// key returns cache key of normalized query, args and current generations of tables
+func (c *Cache) key(ctx context.Context, tables []string, query string, args []any) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n", strings.Join(strings.Fields(query), " "))
+	for _, arg := range args {
+		fmt.Fprintf(h, "%T:%v\n", arg, arg)
+	}
+
+	for _, table := range tables {
+		generation, err := c.generation(ctx, table)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s@%s\n", table, generation)
+	}
+
+	return "sqlcache:query:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// tableKey returns key of generation of table
+func tableKey(table string) string {
+	return "sqlcache:table:" + strings.ToLower(table)
+}
@@ -0,0 +1,106 @@
+package sqlcache
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/albinzx/cache/memory"
+)
+
+// queries counts queries executed by fake driver
+var queries atomic.Int64
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                               { return nil }
+func (fakeStmt) NumInput() int                              { return -1 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	queries.Add(1)
+	return &fakeRows{rows: [][]driver.Value{{int64(1), "a", args[0]}, {int64(2), "b", args[0]}}}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "name", "arg"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func init() {
+	sql.Register("sqlcache-fake", fakeDriver{})
+}
+
+func TestCache_Query(t *testing.T) {
+	db, err := sql.Open("sqlcache-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	c := New(memory.New(), db)
+
+	want := &Result{Columns: []string{"id", "name", "arg"}, Rows: [][]any{{int64(1), "a", "x"}, {int64(2), "b", "x"}}}
+
+	tests := []struct {
+		name        string
+		invalidate  []string
+		tables      []string
+		query       string
+		arg         string
+		wantQueries int64
+	}{
+		{name: "test miss", tables: []string{"users"}, query: "SELECT * FROM users WHERE arg = ?", arg: "x", wantQueries: 1},
+		{name: "test hit", tables: []string{"users"}, query: "SELECT * FROM users WHERE arg = ?", arg: "x", wantQueries: 0},
+		{name: "test normalized hit", tables: []string{"users"}, query: "SELECT *\n\tFROM users  WHERE arg = ?", arg: "x", wantQueries: 0},
+		{name: "test other args", tables: []string{"users"}, query: "SELECT * FROM users WHERE arg = ?", arg: "y", wantQueries: 1},
+		{name: "test other table invalidated", invalidate: []string{"orders"}, tables: []string{"users"}, query: "SELECT * FROM users WHERE arg = ?", arg: "x", wantQueries: 0},
+		{name: "test table invalidated", invalidate: []string{"users"}, tables: []string{"users"}, query: "SELECT * FROM users WHERE arg = ?", arg: "x", wantQueries: 1},
+		{name: "test hit after invalidation", tables: []string{"users"}, query: "SELECT * FROM users WHERE arg = ?", arg: "x", wantQueries: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.Invalidate(ctx, tt.invalidate...); err != nil {
+				t.Fatalf("Invalidate() error = %v", err)
+			}
+
+			before := queries.Load()
+			got, err := c.Query(ctx, tt.tables, tt.query, tt.arg)
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got := queries.Load() - before; got != tt.wantQueries {
+				t.Errorf("Query() queries = %v, want %v", got, tt.wantQueries)
+			}
+
+			want.Rows[0][2], want.Rows[1][2] = tt.arg, tt.arg
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Query() = %v, want %v", got, want)
+			}
+		})
+	}
+}
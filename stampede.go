@@ -0,0 +1,203 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ReadThroughOption provides read-through options
+type ReadThroughOption func(*ReadThrough)
+
+// WithRefreshAfter returns option to store values loaded from persistence storage fresh for soft ttl,
+// stale values are served immediately while they are refreshed from persistence storage in background,
+// cacher must record freshness of values, see Enveloped, values of other cachers are always fresh
+func WithRefreshAfter(soft time.Duration) ReadThroughOption {
+	return func(r *ReadThrough) {
+		r.soft = soft
+	}
+}
+
+// WithEarlyRefresh returns option to refresh fresh values in background before they become stale with
+// probability growing as they get closer to it and with time loads take, so refreshes of keys
+// loaded at the same time are spread instead of happening at once (XFetch)
+// beta scales how early values are refreshed, 1 is the usual choice and greater values refresh earlier
+func WithEarlyRefresh(beta float64) ReadThroughOption {
+	return func(r *ReadThrough) {
+		r.beta = beta
+	}
+}
+
+// NewReadThrough returns read-through pattern
+func NewReadThrough(options ...ReadThroughOption) *ReadThrough {
+	r := &ReadThrough{}
+
+	for _, option := range options {
+		option(r)
+	}
+
+	return r
+}
+
+// getFresh retrieves value with its freshness from cache, stale values and values chosen
+// for early refresh are returned as they are and refreshed in background
+func (r *ReadThrough) getFresh(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
+	if skipped(ctx) || refreshed(ctx) || p == nil {
+		return r.load(ctx, key, c, p)
+	}
+
+	envelope, err := GetEnvelope(ctx, c, key)
+	if err != nil {
+		loggerFrom(ctx).Error(ctx, "failed to get value from cache", "get", key, err)
+		if d := scopeFrom(ctx).degraded; d != nil && p != nil {
+			return d.read(ctx, key, c, p, err)
+		}
+	}
+	if envelope == nil || envelope.Value == nil {
+		return r.load(ctx, key, c, p)
+	}
+	if isNotFound(envelope.Value) {
+		return nil, ErrNotFound
+	}
+
+	now := r.time()
+	if !envelope.Fresh(now) && !r.servesStale(ctx, now.Sub(envelope.FreshUntil)) {
+		return r.load(ctx, key, c, p)
+	}
+	if !envelope.Fresh(now) || r.early(now, envelope.FreshUntil) {
+		r.refresh(ctx, key, c, p)
+	}
+
+	return envelope.Value, nil
+}
+
+// servesStale reports whether value stale for staleness is served while it is refreshed,
+// max staleness of context applies if it is given, otherwise stale values are served with WithRefreshAfter
+func (r *ReadThrough) servesStale(ctx context.Context, staleness time.Duration) bool {
+	if _, ok := staleAllowed(ctx); ok {
+		return allowsStale(ctx, staleness)
+	}
+
+	return r.soft > 0
+}
+
+// early reports whether value fresh until is refreshed early at now, XFetch of
+// "Optimal Probabilistic Cache Stampede Prevention" by Vattani, Chierichetti and Lowenstein
+func (r *ReadThrough) early(now, freshUntil time.Time) bool {
+	if r.beta <= 0 || freshUntil.IsZero() {
+		return false
+	}
+
+	random := rand.Float64
+	if r.random != nil {
+		random = r.random
+	}
+
+	delta := float64(r.delta.Load())
+	gap := time.Duration(-delta * r.beta * math.Log(random()))
+
+	return !now.Add(gap).Before(freshUntil)
+}
+
+// load retrieves value from persistence storage, measuring time it takes, and stores it to cache
+func (r *ReadThrough) load(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
+	if skipped(ctx) || p == nil {
+		return readThrough(ctx, key, c, p)
+	}
+
+	start := r.time()
+	value, err := p.SelectOne(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	r.measure(r.time().Sub(start))
+	if value != nil {
+		markLoaded(ctx, key)
+	}
+
+	if value != nil && !skipped(ctx) {
+		if err := c.Set(ctx, key, value, r.setOptions()...); err != nil {
+			loggerFrom(ctx).Error(ctx, "failed to set value to cache", "set", key, err)
+		}
+	} else if value == nil && !skipped(ctx) {
+		cacheNotFound(ctx, c, key)
+	}
+
+	return value, nil
+}
+
+// refresh reloads value of key from persistence storage in background, unless it is being refreshed,
+// refresh is not cancelled with context of request which triggers it
+func (r *ReadThrough) refresh(ctx context.Context, key string, c Cacher, p Persister) {
+	if _, loaded := r.refreshing.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	r.pending.Add(1)
+	go func() {
+		defer r.pending.Done()
+		defer r.refreshing.Delete(key)
+
+		report := reporterFrom(ctx, "refresh", key)
+		defer RecoverTo(report)
+
+		start := r.time()
+		value, err := p.SelectOne(ctx, key)
+		if err != nil {
+			report(err)
+			return
+		}
+		r.measure(r.time().Sub(start))
+
+		if value == nil {
+			err = c.Delete(ctx, key)
+		} else {
+			err = c.Set(ctx, key, value, r.setOptions()...)
+		}
+		if err != nil {
+			loggerFrom(ctx).Error(ctx, "failed to refresh value of cache", "refresh", key, err)
+			return
+		}
+		emit(ctx, Event{Type: EventRefresh, Key: key, Time: r.time()})
+	}()
+}
+
+// measure records time load took, as exponentially weighted moving average
+func (r *ReadThrough) measure(took time.Duration) {
+	for {
+		old := r.delta.Load()
+		updated := int64(took)
+		if old > 0 {
+			updated = old + (int64(took)-old)/8
+		}
+		if r.delta.CompareAndSwap(old, updated) {
+			return
+		}
+	}
+}
+
+// setOptions returns set options of loaded values
+func (r *ReadThrough) setOptions() []SetOption {
+	if r.soft <= 0 {
+		return nil
+	}
+
+	return []SetOption{WithSoftTTL(r.soft)}
+}
+
+// time returns current time
+func (r *ReadThrough) time() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+
+	return time.Now()
+}
+
+// Drain waits for background refreshes to finish
+// it returns context error if context is done before
+func (r *ReadThrough) Drain(ctx context.Context) error {
+	return wait(ctx, r.pending.Wait)
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReadThrough_RefreshAfter(t *testing.T) {
+	ctx := context.Background()
+	persister := newMapPersister()
+	persister.data["a"] = "one"
+
+	r := NewReadThrough(WithRefreshAfter(time.Minute))
+	c, _ := New(Enveloped(newMapCacher()), persister, WithPattern(r))
+
+	if got, _ := c.Get(ctx, "a"); got != "one" {
+		t.Fatalf("Get() = %v, want %v", got, "one")
+	}
+
+	_ = persister.Save(ctx, "a", "two")
+	if got, _ := c.Get(ctx, "a"); got != "one" {
+		t.Errorf("Get() fresh = %v, want %v", got, "one")
+	}
+
+	// stale value is served and refreshed in background
+	r.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if got, _ := c.Get(ctx, "a"); got != "one" {
+		t.Errorf("Get() stale = %v, want %v", got, "one")
+	}
+	if err := c.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if got, _ := c.Get(ctx, "a"); got != "two" {
+		t.Errorf("Get() refreshed = %v, want %v", got, "two")
+	}
+}
+
+func TestReadThrough_EarlyRefresh(t *testing.T) {
+	tests := []struct {
+		name   string
+		random float64
+		want   any
+	}{
+		{name: "test not refreshed", random: 1, want: "one"},
+		{name: "test refreshed early", random: 1e-9, want: "two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			persister := newMapPersister()
+			persister.data["a"] = "one"
+
+			r := NewReadThrough(WithRefreshAfter(time.Minute), WithEarlyRefresh(1))
+			r.random = func() float64 { return tt.random }
+			c, _ := New(Enveloped(newMapCacher()), persister, WithPattern(r))
+
+			_, _ = c.Get(ctx, "a")
+			r.delta.Store(int64(10 * time.Second))
+			_ = persister.Save(ctx, "a", "two")
+
+			_, _ = c.Get(ctx, "a")
+			_ = c.Drain(ctx)
+			if got, _ := c.Get(ctx, "a"); got != tt.want {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadThrough_AllowStale(t *testing.T) {
+	tests := []struct {
+		name      string
+		ctx       func(context.Context) context.Context
+		want      any
+		wantAfter any
+	}{
+		{name: "test stale served", ctx: func(ctx context.Context) context.Context { return AllowStale(ctx, 5*time.Minute) }, want: "one", wantAfter: "two"},
+		{name: "test any staleness served", ctx: func(ctx context.Context) context.Context { return AllowStale(ctx, 0) }, want: "one", wantAfter: "two"},
+		{name: "test too stale", ctx: func(ctx context.Context) context.Context { return AllowStale(ctx, 30*time.Second) }, want: "two", wantAfter: "two"},
+		{name: "test stale not allowed", ctx: func(ctx context.Context) context.Context { return ctx }, want: "two", wantAfter: "two"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			persister := newMapPersister()
+
+			now := time.Now()
+			e := Enveloped(newMapCacher(), WithStaleRetention(time.Hour))
+			e.now = func() time.Time { return now }
+			r := NewReadThrough()
+			r.now = e.now
+			c, _ := New(e, persister, WithPattern(r))
+
+			_ = c.Set(ctx, "a", "one", WithTTL(time.Minute))
+			persister.data["a"] = "two"
+			now = now.Add(2 * time.Minute)
+
+			if got, _ := c.Get(tt.ctx(ctx), "a"); got != tt.want {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+			if err := c.Drain(ctx); err != nil {
+				t.Fatalf("Drain() error = %v", err)
+			}
+			if got, _ := c.Get(ctx, "a"); got != tt.wantAfter {
+				t.Errorf("Get() revalidated = %v, want %v", got, tt.wantAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotTagInvalidator is returned when cacher can not invalidate keys by tags
+	ErrNotTagInvalidator = errors.New("cacher does not support tags")
+)
+
+// WithTags sets tags of value, values can be deleted by their tags with InvalidateTags,
+// tags are recorded by cachers implementing TagInvalidator and ignored by others
+func WithTags(tags ...string) SetOption {
+	return func(setConfig *SetConfiguration) {
+		setConfig.Tags = append(setConfig.Tags, tags...)
+	}
+}
+
+// TagInvalidator is implemented by cachers which record tags of values, e.g. memory and redis cachers
+type TagInvalidator interface {
+	// InvalidateTags deletes values set with any of tags
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
+
+// InvalidateTags deletes values set with any of tags from cache, values are not deleted
+// from persistence storage, cacher must implement TagInvalidator
+func (c *PatternedCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	invalidator, ok := c.unwrapped().(TagInvalidator)
+	if !ok {
+		return ErrNotTagInvalidator
+	}
+
+	ctx = withScope(ctx, c.scope)
+	if err := invalidator.InvalidateTags(ctx, tags...); err != nil {
+		loggerFrom(ctx).Error(ctx, "failed to invalidate tags", "invalidate_tags", "", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// taggedCacher is map cacher recording tags of keys
+type taggedCacher struct {
+	*mapCacher
+	tagged map[string][]string
+}
+
+func (c *taggedCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	setConfig := &SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+	for _, tag := range setConfig.Tags {
+		c.tagged[tag] = append(c.tagged[tag], key)
+	}
+	return c.mapCacher.Set(ctx, key, value, options...)
+}
+
+func (c *taggedCacher) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		if err := DeleteMany(ctx, c, c.tagged[tag]...); err != nil {
+			return err
+		}
+		delete(c.tagged, tag)
+	}
+	return nil
+}
+
+func TestPatternedCache_InvalidateTags(t *testing.T) {
+	ctx := context.Background()
+	cacher := &taggedCacher{mapCacher: newMapCacher(), tagged: map[string][]string{}}
+	c, _ := New(cacher, nil)
+
+	_ = c.Set(ctx, "a", "one", WithTags("user:1"))
+	_ = c.Set(ctx, "b", "two")
+	if err := c.InvalidateTags(ctx, "user:1"); err != nil {
+		t.Fatalf("InvalidateTags() error = %v", err)
+	}
+	if got, _ := c.Get(ctx, "a"); got != nil {
+		t.Errorf("Get() = %v, want nil", got)
+	}
+	if got, _ := c.Get(ctx, "b"); got != "two" {
+		t.Errorf("Get() = %v, want %v", got, "two")
+	}
+
+	plain, _ := New(newMapCacher(), nil)
+	if err := plain.InvalidateTags(ctx, "user:1"); !errors.Is(err, ErrNotTagInvalidator) {
+		t.Errorf("InvalidateTags() error = %v, want %v", err, ErrNotTagInvalidator)
+	}
+}
@@ -0,0 +1,291 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoTenant is returned when tenant is required but context has no tenant
+	ErrNoTenant = errors.New("no tenant in context")
+	// ErrTenantQuotaExceeded is returned when tenant has reached its maximum number of keys
+	ErrTenantQuotaExceeded = errors.New("tenant quota exceeded")
+)
+
+// tenantKey is context key of tenant
+type tenantKey struct{}
+
+// WithTenant returns context carrying tenant id
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom returns tenant id carried by context
+func TenantFrom(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// TenantCacher is cacher isolating keys of tenants by tenant prefix
+// tenant is taken from context of every operation
+type TenantCacher struct {
+	Cacher
+	extract  func(context.Context) (string, bool)
+	required bool
+	maxKeys  int
+	metrics  func(tenant string) Metrics
+
+	mu   sync.Mutex
+	keys map[string]map[string]struct{}
+}
+
+// TenantOption provides tenant options
+type TenantOption func(*TenantCacher)
+
+// WithTenantExtractor returns option to set function extracting tenant from context
+// by default tenant set with WithTenant is used
+func WithTenantExtractor(extract func(context.Context) (string, bool)) TenantOption {
+	return func(t *TenantCacher) {
+		t.extract = extract
+	}
+}
+
+// WithTenantRequired returns option to fail operations without tenant with ErrNoTenant
+// by default operations without tenant use keys as is
+func WithTenantRequired() TenantOption {
+	return func(t *TenantCacher) {
+		t.required = true
+	}
+}
+
+// WithTenantQuota returns option to limit number of keys set by each tenant
+// keys are counted in process until they are deleted, so expired keys still count
+func WithTenantQuota(maxKeys int) TenantOption {
+	return func(t *TenantCacher) {
+		t.maxKeys = maxKeys
+	}
+}
+
+// WithTenantMetrics returns option to record operations of every tenant to metrics of tenant,
+// e.g. TenantStats.Metrics, operations without tenant are not recorded
+func WithTenantMetrics(metrics func(tenant string) Metrics) TenantOption {
+	return func(t *TenantCacher) {
+		t.metrics = metrics
+	}
+}
+
+// Tenanted returns cacher isolating keys of tenants in c
+func Tenanted(c Cacher, options ...TenantOption) *TenantCacher {
+	t := &TenantCacher{
+		Cacher:  c,
+		extract: TenantFrom,
+		keys:    make(map[string]map[string]struct{}),
+	}
+
+	for _, option := range options {
+		option(t)
+	}
+
+	return t
+}
+
+// tenantPrefix returns prefix of keys of tenant
+func tenantPrefix(tenant string) string {
+	return tenant + "."
+}
+
+// key returns tenant and tenant scoped key
+func (t *TenantCacher) key(ctx context.Context, key string) (string, string, error) {
+	tenant, ok := t.extract(ctx)
+	if !ok {
+		if t.required {
+			return "", "", ErrNoTenant
+		}
+		return "", key, nil
+	}
+
+	return tenant, tenantPrefix(tenant) + key, nil
+}
+
+// record records operation of tenant since start to metrics of tenant
+func (t *TenantCacher) record(tenant string, op Operation, start time.Time, value any, err error) {
+	if t.metrics == nil || tenant == "" {
+		return
+	}
+
+	RecordOperation(t.metrics(tenant), op, start, value, err)
+}
+
+// track records key of tenant, failing if tenant quota is exceeded
+func (t *TenantCacher) track(tenant, key string) error {
+	if tenant == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	keys, ok := t.keys[tenant]
+	if !ok {
+		keys = make(map[string]struct{})
+		t.keys[tenant] = keys
+	}
+
+	if _, ok := keys[key]; !ok && t.maxKeys > 0 && len(keys) >= t.maxKeys {
+		return ErrTenantQuotaExceeded
+	}
+
+	keys[key] = struct{}{}
+
+	return nil
+}
+
+// untrack removes key of tenant
+func (t *TenantCacher) untrack(tenant, key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.keys[tenant], key)
+}
+
+// Set sets key-value of tenant to cache
+func (t *TenantCacher) Set(ctx context.Context, key string, value any, options ...SetOption) (err error) {
+	tenant, scoped, err := t.key(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer func(start time.Time) { t.record(tenant, OpSet, start, value, err) }(time.Now())
+
+	if err := t.track(tenant, scoped); err != nil {
+		return err
+	}
+
+	return t.Cacher.Set(ctx, scoped, value, options...)
+}
+
+// Get gets value of tenant from cache
+func (t *TenantCacher) Get(ctx context.Context, key string) (value any, err error) {
+	tenant, scoped, err := t.key(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer func(start time.Time) { t.record(tenant, OpGet, start, value, err) }(time.Now())
+
+	return t.Cacher.Get(ctx, scoped)
+}
+
+// Delete deletes value of tenant from cache
+func (t *TenantCacher) Delete(ctx context.Context, key string) (err error) {
+	tenant, scoped, err := t.key(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer func(start time.Time) { t.record(tenant, OpDelete, start, nil, err) }(time.Now())
+
+	defer t.untrack(tenant, scoped)
+
+	return t.Cacher.Delete(ctx, scoped)
+}
+
+// Load loads key-values of tenant into cache
+func (t *TenantCacher) Load(ctx context.Context, data map[string]any) (err error) {
+	tenant, ok := t.extract(ctx)
+	if ok {
+		defer func(start time.Time) { t.record(tenant, OpLoad, start, nil, err) }(time.Now())
+	}
+
+	scopedData := make(map[string]any, len(data))
+	for key, value := range data {
+		tenant, scoped, err := t.key(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if err := t.track(tenant, scoped); err != nil {
+			return err
+		}
+
+		scopedData[scoped] = value
+	}
+
+	return t.Cacher.Load(ctx, scopedData)
+}
+
+// KeyCount returns number of keys tracked for tenant
+func (t *TenantCacher) KeyCount(tenant string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.keys[tenant])
+}
+
+// Clear deletes all keys of tenant, keys set by this process are deleted
+// and, if underlying cacher implements Scanner, all keys with tenant prefix
+func (t *TenantCacher) Clear(ctx context.Context, tenant string) error {
+	t.mu.Lock()
+	keys := make([]string, 0, len(t.keys[tenant]))
+	for key := range t.keys[tenant] {
+		keys = append(keys, key)
+	}
+	delete(t.keys, tenant)
+	t.mu.Unlock()
+
+	if scanner, ok := t.Cacher.(Scanner); ok {
+		it := scanner.Keys(ctx, tenantPrefix(tenant)+"*")
+		for it.Next(ctx) {
+			keys = append(keys, it.Key())
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := t.Cacher.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// TenantStats creates stats of every tenant named by name and tenant, e.g. "orders.tenant42",
+// so tenants are labeled by stats name, e.g. by prometheus collector
+type TenantStats struct {
+	name     string
+	onCreate func(*Stats)
+
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+// NewTenantStats returns stats of tenants of cache with name, onCreate is called with stats of every new tenant
+// unless it is nil, e.g. collector.Register of prometheus collector
+func NewTenantStats(name string, onCreate func(*Stats)) *TenantStats {
+	return &TenantStats{name: name, onCreate: onCreate, stats: map[string]*Stats{}}
+}
+
+// Metrics returns stats of tenant, which are created on first use, see WithTenantMetrics
+func (s *TenantStats) Metrics(tenant string) Metrics {
+	return s.Stats(tenant)
+}
+
+// Stats returns stats of tenant, which are created on first use
+func (s *TenantStats) Stats(tenant string) *Stats {
+	s.mu.Lock()
+	stats, ok := s.stats[tenant]
+	if !ok {
+		stats = NewStats(s.name + "." + tenant)
+		s.stats[tenant] = stats
+	}
+	s.mu.Unlock()
+
+	if !ok && s.onCreate != nil {
+		s.onCreate(stats)
+	}
+
+	return stats
+}
@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTenanted(t *testing.T) {
+	m := newMapCacher()
+	c := Tenanted(m, WithTenantQuota(2))
+
+	acme := WithTenant(context.Background(), "acme")
+	globex := WithTenant(context.Background(), "globex")
+
+	_ = c.Set(acme, "key", "acme value")
+	_ = c.Set(globex, "key", "globex value")
+
+	if got, _ := c.Get(acme, "key"); got != "acme value" {
+		t.Errorf("TenantCacher.Get() = %v, want %v", got, "acme value")
+	}
+	if got, _ := c.Get(globex, "key"); got != "globex value" {
+		t.Errorf("TenantCacher.Get() = %v, want %v", got, "globex value")
+	}
+	if _, ok := m.data["acme.key"]; !ok {
+		t.Errorf("TenantCacher.Set() stored keys = %v, want acme.key", m.data)
+	}
+
+	_ = c.Set(acme, "other", "value")
+	if err := c.Set(acme, "third", "value"); !errors.Is(err, ErrTenantQuotaExceeded) {
+		t.Errorf("TenantCacher.Set() error = %v, want %v", err, ErrTenantQuotaExceeded)
+	}
+	if err := c.Set(acme, "key", "updated"); err != nil {
+		t.Errorf("TenantCacher.Set() of existing key error = %v", err)
+	}
+
+	if err := c.Clear(context.Background(), "acme"); err != nil {
+		t.Errorf("TenantCacher.Clear() error = %v", err)
+	}
+	if got, _ := c.Get(acme, "key"); got != nil {
+		t.Errorf("TenantCacher.Get() after clear = %v, want nil", got)
+	}
+	if got, _ := c.Get(globex, "key"); got != "globex value" {
+		t.Errorf("TenantCacher.Get() of other tenant after clear = %v, want %v", got, "globex value")
+	}
+	if got := c.KeyCount("acme"); got != 0 {
+		t.Errorf("TenantCacher.KeyCount() = %v, want %v", got, 0)
+	}
+}
+
+func TestTenanted_Required(t *testing.T) {
+	c := Tenanted(newMapCacher(), WithTenantRequired())
+	if err := c.Set(context.Background(), "key", "value"); !errors.Is(err, ErrNoTenant) {
+		t.Errorf("TenantCacher.Set() error = %v, want %v", err, ErrNoTenant)
+	}
+}
+
+func TestTenanted_Metrics(t *testing.T) {
+	var created []string
+	stats := NewTenantStats("orders", func(s *Stats) { created = append(created, s.Name()) })
+	c := Tenanted(newMapCacher(), WithTenantMetrics(stats.Metrics))
+
+	acme := WithTenant(context.Background(), "acme")
+	_ = c.Set(acme, "key", "value")
+	_, _ = c.Get(acme, "key")
+	_, _ = c.Get(acme, "missing")
+	_, _ = c.Get(WithTenant(context.Background(), "globex"), "key")
+	_, _ = c.Get(context.Background(), "key")
+
+	if snapshot := stats.Stats("acme").Snapshot(); snapshot.Sets != 1 || snapshot.Hits != 1 || snapshot.Misses != 1 {
+		t.Errorf("acme stats = %+v, want 1 set, 1 hit and 1 miss", snapshot)
+	}
+	if snapshot := stats.Stats("globex").Snapshot(); snapshot.Misses != 1 {
+		t.Errorf("globex stats = %+v, want 1 miss", snapshot)
+	}
+	if want := []string{"orders.acme", "orders.globex"}; !reflect.DeepEqual(created, want) {
+		t.Errorf("created stats = %v, want %v", created, want)
+	}
+}
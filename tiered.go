@@ -0,0 +1,314 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/albinzx/cache/internal"
+)
+
+// TierStats is activity of tiered cacher
+type TierStats struct {
+	// Promotions is number of keys copied from cold tier to hot tier
+	Promotions int64
+	// Demotions is number of keys evicted from hot tier
+	Demotions int64
+	// Resident is number of keys in hot tier
+	Resident int
+}
+
+// TieredCacher is cacher keeping frequently accessed keys of cold tier, e.g. redis, in hot tier, e.g. memory
+// access frequency of keys is estimated with count-min sketch whose counters decay over time,
+// keys are promoted when their frequency reaches promote threshold and demoted when it falls below
+// demote threshold or when hotter key needs room in hot tier of limited capacity
+type TieredCacher struct {
+	Cacher
+	hot       Cacher
+	sketch    *internal.Sketch
+	promote   int
+	demote    int
+	capacity  int
+	hotTTL    time.Duration
+	interval  time.Duration
+	clock     Clock
+	mu        sync.Mutex
+	residents map[string]struct{}
+
+	promotions atomic.Int64
+	demotions  atomic.Int64
+
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// TierOption provides tiered cacher options
+type TierOption func(*TieredCacher)
+
+// WithPromoteThreshold returns option to set estimated accesses of key to promote it to hot tier, default is 3
+func WithPromoteThreshold(threshold int) TierOption {
+	return func(t *TieredCacher) {
+		t.promote = threshold
+	}
+}
+
+// WithDemoteThreshold returns option to set estimated accesses below which key is demoted by Demote, default is 1
+func WithDemoteThreshold(threshold int) TierOption {
+	return func(t *TieredCacher) {
+		t.demote = threshold
+	}
+}
+
+// WithHotCapacity returns option to limit number of keys in hot tier, default is unlimited
+func WithHotCapacity(capacity int) TierOption {
+	return func(t *TieredCacher) {
+		t.capacity = capacity
+	}
+}
+
+// WithHotTTL returns option to set time to live of values in hot tier, default is hot cacher TTL
+func WithHotTTL(ttl time.Duration) TierOption {
+	return func(t *TieredCacher) {
+		t.hotTTL = ttl
+	}
+}
+
+// WithDemoteInterval returns option to run Demote periodically until tiered cacher is stopped or closed
+func WithDemoteInterval(interval time.Duration) TierOption {
+	return func(t *TieredCacher) {
+		t.interval = interval
+	}
+}
+
+// WithTierSketchWidth returns option to set counters per row of frequency sketch,
+// it should be about the number of frequently accessed keys, default is 4096
+func WithTierSketchWidth(width int) TierOption {
+	return func(t *TieredCacher) {
+		t.sketch = internal.NewSketch(width)
+	}
+}
+
+// Tiered returns cacher storing values in cold tier and keeping frequently accessed keys in hot tier
+func Tiered(hot, cold Cacher, options ...TierOption) *TieredCacher {
+	t := &TieredCacher{
+		Cacher:    cold,
+		hot:       hot,
+		promote:   3,
+		demote:    1,
+		clock:     SystemClock,
+		residents: map[string]struct{}{},
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(t)
+	}
+
+	if t.sketch == nil {
+		t.sketch = internal.NewSketch(4096)
+	}
+
+	if t.interval > 0 {
+		go t.run()
+	} else {
+		close(t.done)
+	}
+
+	return t
+}
+
+// run demotes cold keys periodically
+func (t *TieredCacher) run() {
+	defer close(t.done)
+
+	ticker := t.clock.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C():
+			t.Demote(context.Background())
+		}
+	}
+}
+
+// resident reports whether key is in hot tier
+func (t *TieredCacher) resident(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.residents[key]
+
+	return ok
+}
+
+// hotOptions returns set options of hot tier
+func (t *TieredCacher) hotOptions(options []SetOption) []SetOption {
+	if t.hotTTL > 0 {
+		return append(options, WithTTL(t.hotTTL))
+	}
+
+	return options
+}
+
+// Get gets value from hot tier if key is resident, otherwise from cold tier,
+// promoting key if it is accessed frequently enough
+func (t *TieredCacher) Get(ctx context.Context, key string) (any, error) {
+	t.sketch.Add(key)
+
+	if t.resident(key) {
+		value, err := t.hot.Get(ctx, key)
+		if err == nil && value != nil {
+			return value, nil
+		}
+	}
+
+	value, err := t.Cacher.Get(ctx, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	if t.sketch.Estimate(key) >= t.promote {
+		t.promoteKey(ctx, key, value)
+	}
+
+	return value, nil
+}
+
+// promoteKey copies key-value to hot tier, demoting the coldest resident if hot tier is full
+func (t *TieredCacher) promoteKey(ctx context.Context, key string, value any) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.residents[key]; !ok && t.capacity > 0 && len(t.residents) >= t.capacity {
+		coldest, frequency := "", 0
+		for resident := range t.residents {
+			if f := t.sketch.Estimate(resident); coldest == "" || f < frequency {
+				coldest, frequency = resident, f
+			}
+		}
+		if frequency >= t.sketch.Estimate(key) {
+			return
+		}
+		if err := t.hot.Delete(ctx, coldest); err != nil {
+			return
+		}
+		delete(t.residents, coldest)
+		t.demotions.Add(1)
+	}
+
+	if err := t.hot.Set(ctx, key, value, t.hotOptions(nil)...); err != nil {
+		return
+	}
+	if _, ok := t.residents[key]; !ok {
+		t.residents[key] = struct{}{}
+		t.promotions.Add(1)
+	}
+}
+
+// Set sets key-value to cold tier and to hot tier if key is resident
+func (t *TieredCacher) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	t.sketch.Add(key)
+
+	if err := t.Cacher.Set(ctx, key, value, options...); err != nil {
+		return err
+	}
+
+	if t.resident(key) {
+		if err := t.hot.Set(ctx, key, value, t.hotOptions(options)...); err != nil {
+			t.evict(ctx, key)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Delete deletes value from both tiers
+func (t *TieredCacher) Delete(ctx context.Context, key string) error {
+	if err := t.Cacher.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	return t.evict(ctx, key)
+}
+
+// Load loads key-values to cold tier and updates resident keys in hot tier
+func (t *TieredCacher) Load(ctx context.Context, data map[string]any) error {
+	if err := t.Cacher.Load(ctx, data); err != nil {
+		return err
+	}
+
+	var errs []error
+	for key, value := range data {
+		if t.resident(key) {
+			if err := t.hot.Set(ctx, key, value, t.hotOptions(nil)...); err != nil {
+				errs = append(errs, t.evict(ctx, key))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// evict deletes key from hot tier
+func (t *TieredCacher) evict(ctx context.Context, key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.residents[key]; !ok {
+		return nil
+	}
+	delete(t.residents, key)
+
+	return t.hot.Delete(ctx, key)
+}
+
+// Demote evicts resident keys whose estimated accesses fell below demote threshold from hot tier,
+// it returns number of demoted keys
+func (t *TieredCacher) Demote(ctx context.Context) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	demoted := 0
+	for key := range t.residents {
+		if t.sketch.Estimate(key) >= t.demote {
+			continue
+		}
+		if err := t.hot.Delete(ctx, key); err != nil {
+			continue
+		}
+		delete(t.residents, key)
+		demoted++
+	}
+	t.demotions.Add(int64(demoted))
+
+	return demoted
+}
+
+// Stats returns activity of tiered cacher
+func (t *TieredCacher) Stats() TierStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return TierStats{Promotions: t.promotions.Load(), Demotions: t.demotions.Load(), Resident: len(t.residents)}
+}
+
+// Stop stops periodic demotion and waits for it to finish
+func (t *TieredCacher) Stop() {
+	t.stopOnce.Do(func() { close(t.stop) })
+	<-t.done
+}
+
+// Close stops periodic demotion and closes both tiers
+func (t *TieredCacher) Close() error {
+	t.Stop()
+
+	return errors.Join(t.hot.Close(), t.Cacher.Close())
+}
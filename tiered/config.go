@@ -0,0 +1,31 @@
+package tiered
+
+import (
+	"fmt"
+
+	"github.com/albinzx/cache"
+)
+
+func init() {
+	cache.RegisterBackend("tiered", fromConfig)
+}
+
+// fromConfig returns tiered cacher of two tiers of backend config, tiers are created by their registered backends
+func fromConfig(config cache.BackendConfig, _ cache.Marshaller) (cache.Cacher, error) {
+	if len(config.Tiers) != 2 {
+		return nil, fmt.Errorf("%w: tiered backend has %d tiers, want 2", cache.ErrInvalidOption, len(config.Tiers))
+	}
+
+	l1, err := cache.NewBackend(config.Tiers[0])
+	if err != nil {
+		return nil, err
+	}
+
+	l2, err := cache.NewBackend(config.Tiers[1])
+	if err != nil {
+		_ = l1.Close()
+		return nil, err
+	}
+
+	return New(l1, l2), nil
+}
@@ -0,0 +1,249 @@
+// Package tiered composes two cachers, e.g. in-process memory in front of redis, into one layered cacher
+package tiered
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/albinzx/cache"
+)
+
+// keeper is first tier which stores values read from second tier differently than written values,
+// e.g. invalidation.Cacher which does not broadcast them to other nodes
+type keeper interface {
+	Keep(ctx context.Context, key string, value any, options ...cache.SetOption) error
+}
+
+// Cacher is layered cacher reading first tier before second tier and writing both tiers
+//
+// reads are served from first tier and fall back to second tier, values found in second tier
+// are backfilled to first tier, writes and deletes go to second tier first and then to first tier,
+// second tier is the shared source so its errors fail operations, errors of first tier
+// never fail reads and are reported to error handler
+//
+// first tier of multiple nodes is kept coherent by invalidation.Cacher, e.g.
+// invalidation.New(ctx, memory.New(), invalidation.NewRedis(client, channel)), writes of one node
+// evict keys from first tier of other nodes while backfilled values are kept without broadcast
+type Cacher struct {
+	l1    cache.Cacher
+	l2    cache.Cacher
+	l1TTL time.Duration
+	l2TTL time.Duration
+
+	onError func(error)
+}
+
+// Option provides tiered cacher options
+type Option func(*Cacher)
+
+// WithL1TTL returns option to set time to live of values in first tier, it caps ttl given on set,
+// default is ttl given on set or first tier TTL
+func WithL1TTL(ttl time.Duration) Option {
+	return func(c *Cacher) {
+		c.l1TTL = ttl
+	}
+}
+
+// WithL2TTL returns option to set time to live of values in second tier when no ttl is given on set,
+// default is second tier TTL
+func WithL2TTL(ttl time.Duration) Option {
+	return func(c *Cacher) {
+		c.l2TTL = ttl
+	}
+}
+
+// WithErrorHandler returns option to report errors of first tier which do not fail operations,
+// errors are cache.KeyError of the key, by default they are ignored
+func WithErrorHandler(handler func(error)) Option {
+	return func(c *Cacher) {
+		c.onError = handler
+	}
+}
+
+// New returns cacher layering l1, e.g. memory cacher, in front of l2, e.g. redis cacher
+func New(l1, l2 cache.Cacher, options ...Option) *Cacher {
+	c := &Cacher{l1: l1, l2: l2, onError: func(error) {}}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	return c
+}
+
+// L1 returns first tier
+func (c *Cacher) L1() cache.Cacher {
+	return c.l1
+}
+
+// L2 returns second tier
+func (c *Cacher) L2() cache.Cacher {
+	return c.l2
+}
+
+// report reports error of first tier on key
+func (c *Cacher) report(key string, err error) {
+	if err != nil && !errors.Is(err, cache.ErrNotFound) {
+		c.onError(&cache.KeyError{Key: key, Err: err})
+	}
+}
+
+// l1Options returns set options of first tier, ttl is capped by first tier ttl
+func (c *Cacher) l1Options(options []cache.SetOption) []cache.SetOption {
+	if c.l1TTL <= 0 {
+		return options
+	}
+
+	setConfig := &cache.SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+	if setConfig.TTL > 0 && setConfig.TTL < c.l1TTL {
+		return options
+	}
+
+	return append(options[:len(options):len(options)], cache.WithTTL(c.l1TTL))
+}
+
+// l2Options returns set options of second tier, second tier ttl is used if no ttl is given
+func (c *Cacher) l2Options(options []cache.SetOption) []cache.SetOption {
+	if c.l2TTL <= 0 {
+		return options
+	}
+
+	setConfig := &cache.SetConfiguration{}
+	for _, option := range options {
+		option(setConfig)
+	}
+	if setConfig.TTL > 0 {
+		return options
+	}
+
+	return append([]cache.SetOption{cache.WithTTL(c.l2TTL)}, options...)
+}
+
+// Set sets key-value to second tier and then to first tier
+// if second tier fails, key is evicted from first tier so it does not keep the former value,
+// if first tier fails, key is evicted from it and error is returned only if eviction fails too
+func (c *Cacher) Set(ctx context.Context, key string, value any, options ...cache.SetOption) error {
+	if err := c.l2.Set(ctx, key, value, c.l2Options(options)...); err != nil {
+		c.report(key, c.l1.Delete(ctx, key))
+		return err
+	}
+
+	if err := c.l1.Set(ctx, key, value, c.l1Options(options)...); err != nil {
+		c.report(key, err)
+		if derr := c.l1.Delete(ctx, key); derr != nil {
+			return errors.Join(err, derr)
+		}
+	}
+
+	return nil
+}
+
+// Get gets value from first tier, or from second tier and backfills it to first tier
+// errors of first tier are treated as miss
+func (c *Cacher) Get(ctx context.Context, key string) (any, error) {
+	value, err := c.l1.Get(ctx, key)
+	if err == nil && value != nil {
+		return value, nil
+	}
+	c.report(key, err)
+
+	value, err = c.l2.Get(ctx, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	c.report(key, c.backfill(ctx, key, value))
+
+	return value, nil
+}
+
+// backfill stores value read from second tier to first tier
+func (c *Cacher) backfill(ctx context.Context, key string, value any) error {
+	if keeper, ok := c.l1.(keeper); ok {
+		return keeper.Keep(ctx, key, value, c.l1Options(nil)...)
+	}
+
+	return c.l1.Set(ctx, key, value, c.l1Options(nil)...)
+}
+
+// Delete deletes value from second tier and then from first tier, both tiers are deleted
+// even if second tier fails, so first tier does not serve value which may be gone
+func (c *Cacher) Delete(ctx context.Context, key string) error {
+	return errors.Join(c.l2.Delete(ctx, key), c.l1.Delete(ctx, key))
+}
+
+// Load loads key-values to second tier and then to first tier
+func (c *Cacher) Load(ctx context.Context, data map[string]any) error {
+	if err := c.l2.Load(ctx, data); err != nil {
+		return err
+	}
+
+	if c.l1TTL <= 0 {
+		return c.l1.Load(ctx, data)
+	}
+
+	var errs []error
+	for key, value := range data {
+		if err := c.l1.Set(ctx, key, value, cache.WithTTL(c.l1TTL)); err != nil {
+			errs = append(errs, &cache.KeyError{Key: key, Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// GetMany gets values of keys from first tier, keys which miss are read from second tier at once
+// and backfilled to first tier
+func (c *Cacher) GetMany(ctx context.Context, keys []string) (map[string]any, error) {
+	values, err := cache.GetMany(ctx, c.l1, keys)
+	if err != nil {
+		c.onError(err)
+	}
+	if values == nil {
+		values = map[string]any{}
+	}
+
+	missing := make([]string, 0, len(keys)-len(values))
+	for _, key := range keys {
+		if _, ok := values[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	found, err := cache.GetMany(ctx, c.l2, missing)
+	for key, value := range found {
+		values[key] = value
+		c.report(key, c.backfill(ctx, key, value))
+	}
+
+	return values, err
+}
+
+// DeleteMany deletes values of keys from second tier and then from first tier
+func (c *Cacher) DeleteMany(ctx context.Context, keys ...string) error {
+	return errors.Join(cache.DeleteMany(ctx, c.l2, keys...), cache.DeleteMany(ctx, c.l1, keys...))
+}
+
+// Ping pings tiers implementing cache.Pinger
+func (c *Cacher) Ping(ctx context.Context) error {
+	var errs []error
+	for _, tier := range []cache.Cacher{c.l1, c.l2} {
+		if pinger, ok := tier.(cache.Pinger); ok {
+			errs = append(errs, pinger.Ping(ctx))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close closes both tiers
+func (c *Cacher) Close() error {
+	return errors.Join(c.l1.Close(), c.l2.Close())
+}
@@ -0,0 +1,170 @@
+package tiered
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+	"github.com/albinzx/cache/invalidation"
+	"github.com/albinzx/cache/memory"
+)
+
+func TestConformance(t *testing.T) {
+	cachetest.Conformance(t, func(testing.TB) cache.Cacher {
+		return New(memory.New(), memory.New())
+	})
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	l1, l2 := cachetest.NewCacher(), cachetest.NewCacher()
+	c := New(l1, l2, WithL1TTL(time.Second))
+
+	_ = l2.Set(ctx, "key", "value")
+	if value, err := c.Get(ctx, "key"); err != nil || value != "value" {
+		t.Fatalf("Get() = %v, %v, want value", value, err)
+	}
+	cachetest.AssertCached(t, l1, "key", "value")
+
+	l2.Reset()
+	if value, _ := c.Get(ctx, "key"); value != "value" {
+		t.Errorf("Get() = %v, want value from first tier", value)
+	}
+	cachetest.AssertCallCount(t, l2, cache.OpGet, 0)
+
+	// first tier ttl caps ttl of backfilled value
+	l1.Clock().Advance(2 * time.Second)
+	cachetest.AssertNotCached(t, l1, "key")
+}
+
+func TestIndependentTTL(t *testing.T) {
+	ctx := context.Background()
+	l1, l2 := cachetest.NewCacher(), cachetest.NewCacher()
+	c := New(l1, l2, WithL1TTL(time.Second), WithL2TTL(time.Hour))
+
+	if err := c.Set(ctx, "key", "value"); err != nil {
+		t.Fatal(err)
+	}
+	if calls := l1.Calls(cache.OpSet); calls[0].TTL != time.Second {
+		t.Errorf("first tier ttl = %v, want 1s", calls[0].TTL)
+	}
+	if calls := l2.Calls(cache.OpSet); calls[0].TTL != time.Hour {
+		t.Errorf("second tier ttl = %v, want 1h", calls[0].TTL)
+	}
+
+	_ = c.Set(ctx, "short", "value", cache.WithTTL(100*time.Millisecond))
+	if calls := l1.Calls(cache.OpSet); calls[1].TTL != 100*time.Millisecond {
+		t.Errorf("first tier ttl = %v, want 100ms", calls[1].TTL)
+	}
+}
+
+func TestFailures(t *testing.T) {
+	ctx := context.Background()
+	l1, l2 := cachetest.NewCacher(), cachetest.NewCacher()
+	var reported []error
+	c := New(l1, l2, WithErrorHandler(func(err error) { reported = append(reported, err) }))
+
+	_ = c.Set(ctx, "key", "old")
+
+	// failed second tier evicts first tier, so it does not keep the former value
+	down := errors.New("down")
+	l2.Fail(cache.OpSet, down)
+	if err := c.Set(ctx, "key", "new"); !errors.Is(err, down) {
+		t.Errorf("Set() error = %v, want %v", err, down)
+	}
+	cachetest.AssertNotCached(t, l1, "key")
+
+	// failed first tier is a miss
+	l1.Fail(cache.OpGet, down)
+	if value, err := c.Get(ctx, "key"); err != nil || value != "old" {
+		t.Errorf("Get() = %v, %v, want old", value, err)
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], down) {
+		t.Errorf("reported = %v, want first tier error", reported)
+	}
+
+	// both tiers are deleted even if second tier fails
+	l1.Fail(cache.OpGet)
+	_ = l1.Set(ctx, "key", "old")
+	l2.Fail(cache.OpDelete, down)
+	if err := c.Delete(ctx, "key"); !errors.Is(err, down) {
+		t.Errorf("Delete() error = %v, want %v", err, down)
+	}
+	cachetest.AssertNotCached(t, l1, "key")
+}
+
+func TestGetMany(t *testing.T) {
+	ctx := context.Background()
+	l1, l2 := cachetest.NewCacher(), cachetest.NewCacher()
+	c := New(l1, l2)
+
+	_ = l1.Set(ctx, "a", "one")
+	_ = l2.Set(ctx, "b", "two")
+
+	values, err := c.GetMany(ctx, []string{"a", "b", "c"})
+	if err != nil || len(values) != 2 {
+		t.Fatalf("GetMany() = %v, %v, want a and b", values, err)
+	}
+	cachetest.AssertCached(t, l1, "b", "two")
+	cachetest.AssertNotCalled(t, l2, cache.OpGet, "a")
+}
+
+func TestInvalidation(t *testing.T) {
+	ctx := context.Background()
+	bus := invalidation.NewLocal()
+	shared := memory.New()
+
+	nodes := make([]*Cacher, 2)
+	for i := range nodes {
+		l1, err := invalidation.New(ctx, memory.New(), bus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = New(l1, shared)
+	}
+
+	_ = nodes[0].Set(ctx, "key", "old")
+	if value, _ := nodes[1].Get(ctx, "key"); value != "old" {
+		t.Fatalf("Get() = %v, want old", value)
+	}
+
+	// backfill of second node does not evict first node
+	if value, _ := nodes[0].L1().Get(ctx, "key"); value != "old" {
+		t.Errorf("first tier of first node = %v, want old", value)
+	}
+
+	// write of first node evicts first tier of second node
+	_ = nodes[0].Set(ctx, "key", "new")
+	if value, _ := nodes[1].L1().Get(ctx, "key"); value != nil {
+		t.Errorf("first tier of second node = %v, want evicted", value)
+	}
+	if value, _ := nodes[1].Get(ctx, "key"); value != "new" {
+		t.Errorf("Get() = %v, want new", value)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := cache.Open("memory://?ttl=1m&max=100 | memory://?marshaller=json&pattern=cache-aside")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if err := c.Set(ctx, "key", map[string]any{"name": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := c.Get(ctx, "key"); err != nil || value.(map[string]any)["name"] != "alice" {
+		t.Errorf("Get() = %v, %v, want value of key", value, err)
+	}
+
+	if _, err := cache.Open("memory:// | memory:// | memory://"); !errors.Is(err, cache.ErrInvalidOption) {
+		t.Errorf("Open() error = %v, want %v", err, cache.ErrInvalidOption)
+	}
+	if _, err := cache.Open("memory://?max=many | memory://"); !errors.Is(err, cache.ErrInvalidOption) {
+		t.Errorf("Open() error = %v, want %v", err, cache.ErrInvalidOption)
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTiered(t *testing.T) {
+	ctx := context.Background()
+	hot, cold := newMapCacher(), newMapCacher()
+	cold.data = map[string]any{"a": 1, "b": 2, "c": 3}
+	tiered := Tiered(hot, cold, WithPromoteThreshold(3), WithDemoteThreshold(5), WithHotCapacity(1))
+
+	get := func(key string, times int) {
+		for i := 0; i < times; i++ {
+			if got, _ := tiered.Get(ctx, key); got != cold.data[key] {
+				t.Errorf("Get(%s) = %v, want %v", key, got, cold.data[key])
+			}
+		}
+	}
+
+	tests := []struct {
+		name      string
+		access    func()
+		wantHot   map[string]any
+		wantStats TierStats
+	}{
+		{name: "test below promote threshold", access: func() { get("a", 2) }, wantHot: map[string]any{}},
+		{name: "test promoted", access: func() { get("a", 1) }, wantHot: map[string]any{"a": 1}, wantStats: TierStats{Promotions: 1, Resident: 1}},
+		{name: "test colder key not promoted into full tier", access: func() { get("b", 3) }, wantHot: map[string]any{"a": 1}, wantStats: TierStats{Promotions: 1, Resident: 1}},
+		{name: "test hotter key replaces coldest", access: func() { get("b", 2) }, wantHot: map[string]any{"b": 2}, wantStats: TierStats{Promotions: 2, Demotions: 1, Resident: 1}},
+		{name: "test set updates resident", access: func() { _ = tiered.Set(ctx, "b", 2) }, wantHot: map[string]any{"b": 2}, wantStats: TierStats{Promotions: 2, Demotions: 1, Resident: 1}},
+		{name: "test demote below threshold", access: func() { tiered.Demote(ctx) }, wantHot: map[string]any{"b": 2}, wantStats: TierStats{Promotions: 2, Demotions: 1, Resident: 1}},
+		{name: "test delete evicts", access: func() { _ = tiered.Delete(ctx, "b"); cold.data["b"] = 2 }, wantHot: map[string]any{}, wantStats: TierStats{Promotions: 2, Demotions: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.access()
+
+			if len(hot.data) != len(tt.wantHot) {
+				t.Errorf("hot = %v, want %v", hot.data, tt.wantHot)
+			}
+			for key, value := range tt.wantHot {
+				if hot.data[key] != value {
+					t.Errorf("hot = %v, want %v", hot.data, tt.wantHot)
+				}
+			}
+			if got := tiered.Stats(); got != tt.wantStats {
+				t.Errorf("Stats() = %v, want %v", got, tt.wantStats)
+			}
+		})
+	}
+
+	get("c", 3)
+	if got := tiered.Demote(ctx); got != 1 {
+		t.Errorf("Demote() = %v, want %v", got, 1)
+	}
+	if got := hot.data["c"]; got != nil {
+		t.Errorf("Demote() hot = %v, want %v", got, nil)
+	}
+}
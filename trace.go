@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/albinzx/cache/internal"
+)
+
+const (
+	// AttrBackend is span attribute of backend name, e.g. "redis"
+	AttrBackend = "cache.backend"
+	// AttrKeyPrefix is span attribute of key prefix, part of key before the first '.' or ':'
+	AttrKeyPrefix = "cache.key_prefix"
+	// AttrPattern is span attribute of pattern type of patterned cache, e.g. "ReadThrough"
+	AttrPattern = "cache.pattern"
+)
+
+// AttributeSpan is implemented by spans which record attributes
+type AttributeSpan interface {
+	Span
+	// SetAttribute sets attribute of span
+	SetAttribute(key, value string)
+}
+
+// setAttribute sets attribute of span if it records attributes and value is not empty
+func setAttribute(span Span, key, value string) {
+	if value == "" {
+		return
+	}
+
+	if span, ok := span.(AttributeSpan); ok {
+		span.SetAttribute(key, value)
+	}
+}
+
+// keyPrefix returns part of key before the first '.' or ':', or empty if there is none
+func keyPrefix(key string) string {
+	if i := strings.IndexAny(key, ".:"); i >= 0 {
+		return key[:i]
+	}
+
+	return ""
+}
+
+// Traced returns cacher which traces every operation of c, spans have backend and key prefix attributes
+func Traced(c Cacher, tracer Tracer, backend string) Cacher {
+	return &instrumented{
+		Cacher:  c,
+		logger:  internal.NewLogger(nil, slog.LevelDebug, slog.LevelWarn),
+		tracer:  tracer,
+		backend: backend,
+	}
+}
+
+// WithTracer returns option to trace operations of patterned cache and of its persistence storage,
+// spans of persistence storage are children of spans of patterned cache, as are spans of cacher
+// traced with Traced, so traces follow read through and write through paths
+func WithTracer(tracer Tracer) Option {
+	return func(c *PatternedCache) {
+		c.tracer = tracer
+	}
+}
+
+// trace starts span of operation of patterned cache and returns function to end it
+func (c *PatternedCache) trace(ctx context.Context, op Operation, key string) (context.Context, func(any, error)) {
+	if c.tracer == nil {
+		return ctx, func(any, error) {}
+	}
+
+	ctx, span := c.tracer.Start(ctx, op, key)
+	setAttribute(span, AttrPattern, patternName(c.pattern))
+	setAttribute(span, AttrKeyPrefix, keyPrefix(key))
+
+	return ctx, func(value any, err error) {
+		span.End(resultOf(op, value, err), err)
+	}
+}
+
+// patternName returns name of pattern type
+func patternName(pattern Pattern) string {
+	t := reflect.TypeOf(pattern)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// tracedPersister is persistence storage tracing every operation
+type tracedPersister struct {
+	Persister
+	tracer Tracer
+}
+
+// trace starts span of operation of persistence storage and returns function to end it
+func (t *tracedPersister) trace(ctx context.Context, op Operation, key string) (context.Context, func(any, error)) {
+	ctx, span := t.tracer.Start(ctx, op, key)
+	setAttribute(span, AttrBackend, "persister")
+	setAttribute(span, AttrKeyPrefix, keyPrefix(key))
+
+	return ctx, func(value any, err error) {
+		result := ResultOK
+		switch {
+		case err != nil:
+			result = ResultError
+		case op == OpSelectOne && value == nil:
+			result = ResultMiss
+		case op == OpSelectOne:
+			result = ResultHit
+		}
+		span.End(result, err)
+	}
+}
+
+// Save stores key value to persistence storage
+func (t *tracedPersister) Save(ctx context.Context, key string, value any) error {
+	ctx, done := t.trace(ctx, OpSave, key)
+	err := t.Persister.Save(ctx, key, value)
+	done(nil, err)
+
+	return err
+}
+
+// SelectOne retrieves value by key from persistence storage
+func (t *tracedPersister) SelectOne(ctx context.Context, key string) (any, error) {
+	ctx, done := t.trace(ctx, OpSelectOne, key)
+	value, err := t.Persister.SelectOne(ctx, key)
+	done(value, err)
+
+	return value, err
+}
+
+// SelectAll retrieves all key-values from persistence storage
+func (t *tracedPersister) SelectAll(ctx context.Context) (map[string]any, error) {
+	ctx, done := t.trace(ctx, OpSelectAll, "")
+	data, err := t.Persister.SelectAll(ctx)
+	done(nil, err)
+
+	return data, err
+}
+
+// SaveAll stores key-values to persistence storage, at once if it implements BatchPersister
+func (t *tracedPersister) SaveAll(ctx context.Context, data map[string]any) error {
+	ctx, done := t.trace(ctx, OpSave, strings.Join(internal.SortedKeys(data), ","))
+	err := SaveAll(ctx, t.Persister, data)
+	done(nil, err)
+
+	return err
+}
+
+// SelectMany retrieves values of keys from persistence storage, at once if it implements BatchPersister
+func (t *tracedPersister) SelectMany(ctx context.Context, keys []string) (map[string]any, error) {
+	ctx, done := t.trace(ctx, OpSelectOne, strings.Join(keys, ","))
+	values, err := SelectMany(ctx, t.Persister, keys)
+	if len(values) > 0 {
+		done(values, err)
+	} else {
+		done(nil, err)
+	}
+
+	return values, err
+}
+
+// Delete deletes value by key from persistence storage
+func (t *tracedPersister) Delete(ctx context.Context, key string) error {
+	ctx, done := t.trace(ctx, OpDelete, key)
+	err := t.Persister.Delete(ctx, key)
+	done(nil, err)
+
+	return err
+}
+
+// Ping pings persistence storage if it implements Pinger
+func (t *tracedPersister) Ping(ctx context.Context) error {
+	if pinger, ok := t.Persister.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
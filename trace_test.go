@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+// attributeTracer records spans with their attributes
+type attributeTracer struct {
+	spans []*attributeSpan
+}
+
+// attributeSpan is recorded span
+type attributeSpan struct {
+	op     Operation
+	parent *attributeSpan
+	attrs  map[string]string
+	result Result
+}
+
+// spanKey is context key of current span
+type spanKey struct{}
+
+func (a *attributeTracer) Start(ctx context.Context, op Operation, _ string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(*attributeSpan)
+	span := &attributeSpan{op: op, parent: parent, attrs: map[string]string{}}
+	a.spans = append(a.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (s *attributeSpan) SetAttribute(key, value string) {
+	s.attrs[key] = value
+}
+
+func (s *attributeSpan) End(result Result, _ error) {
+	s.result = result
+}
+
+func TestWithTracer(t *testing.T) {
+	ctx := context.Background()
+	tracer := &attributeTracer{}
+	p := newMapPersister()
+	p.data["orders.1"] = "one"
+
+	c, err := New(Traced(newMapCacher(), tracer, "map"), p, WithPattern(&ReadThrough{}), WithTracer(tracer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := c.Get(ctx, "orders.1"); err != nil || value != "one" {
+		t.Fatalf("Get() = %v, %v, want one", value, err)
+	}
+
+	// get of patterned cache, miss of cacher, select of persistence storage and set of cacher
+	if len(tracer.spans) != 4 {
+		t.Fatalf("spans = %d, want 4", len(tracer.spans))
+	}
+
+	root := tracer.spans[0]
+	if root.op != OpGet || root.result != ResultHit || root.attrs[AttrPattern] != "ReadThrough" || root.attrs[AttrKeyPrefix] != "orders" {
+		t.Errorf("root span = %+v", root)
+	}
+	for _, span := range tracer.spans[1:] {
+		if span.parent != root {
+			t.Errorf("span %s is not child of get of patterned cache", span.op)
+		}
+	}
+	if get := tracer.spans[1]; get.result != ResultMiss || get.attrs[AttrBackend] != "map" {
+		t.Errorf("cacher span = %+v", get)
+	}
+	if sel := tracer.spans[2]; sel.op != OpSelectOne || sel.result != ResultHit || sel.attrs[AttrBackend] != "persister" {
+		t.Errorf("persister span = %+v", sel)
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotTTLManager is returned when cacher can not change time to live of values
+	ErrNotTTLManager = errors.New("cacher does not support ttl management")
+)
+
+// TTLManager is implemented by cachers which change time to live of values without rewriting them,
+// e.g. memory and redis cachers, ErrNotFound is returned if key does not exist
+type TTLManager interface {
+	TTLReader
+	// Expire sets time to live of value, non-positive ttl deletes it
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	// Persist removes time to live of value so it never expires
+	Persist(ctx context.Context, key string) error
+}
+
+// GetWithTTL retrieves value of key and its remaining time to live from c,
+// zero ttl is returned for cachers which do not implement TTLReader
+func GetWithTTL(ctx context.Context, c Cacher, key string) (any, time.Duration, error) {
+	if reader, ok := c.(TTLReader); ok {
+		return reader.GetWithTTL(ctx, key)
+	}
+
+	value, err := c.Get(ctx, key)
+	return value, 0, err
+}
+
+// Expire sets time to live of value of key in c, c must implement TTLManager
+func Expire(ctx context.Context, c Cacher, key string, ttl time.Duration) error {
+	manager, ok := c.(TTLManager)
+	if !ok {
+		return ErrNotTTLManager
+	}
+
+	return manager.Expire(ctx, key, ttl)
+}
+
+// Persist removes time to live of value of key in c, c must implement TTLManager
+func Persist(ctx context.Context, c Cacher, key string) error {
+	manager, ok := c.(TTLManager)
+	if !ok {
+		return ErrNotTTLManager
+	}
+
+	return manager.Persist(ctx, key)
+}
+
+// Expire sets time to live of value of key in cache, cacher must implement TTLManager
+func (c *PatternedCache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	ctx = withScope(ctx, c.scope)
+
+	err := Expire(ctx, c.unwrapped(), key, ttl)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		loggerFrom(ctx).Error(ctx, "failed to expire key", "expire", key, err)
+	}
+
+	return err
+}
+
+// Persist removes time to live of value of key in cache, cacher must implement TTLManager
+func (c *PatternedCache) Persist(ctx context.Context, key string) error {
+	ctx = withScope(ctx, c.scope)
+
+	err := Persist(ctx, c.unwrapped(), key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		loggerFrom(ctx).Error(ctx, "failed to persist key", "persist", key, err)
+	}
+
+	return err
+}
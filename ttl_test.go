@@ -0,0 +1,25 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExpire_NotTTLManager(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCacher()
+
+	if err := Expire(ctx, c, "key", time.Minute); !errors.Is(err, ErrNotTTLManager) {
+		t.Errorf("Expire() error = %v, want %v", err, ErrNotTTLManager)
+	}
+	if err := Persist(ctx, c, "key"); !errors.Is(err, ErrNotTTLManager) {
+		t.Errorf("Persist() error = %v, want %v", err, ErrNotTTLManager)
+	}
+
+	_ = c.Set(ctx, "key", "value")
+	if value, ttl, err := GetWithTTL(ctx, c, "key"); value != "value" || ttl != 0 || err != nil {
+		t.Errorf("GetWithTTL() = %v, %v, %v, want value without ttl", value, ttl, err)
+	}
+}
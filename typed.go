@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnexpectedType is returned when cached value is not of type of typed cache
+	ErrUnexpectedType = errors.New("unexpected value type")
+)
+
+// Typed is patterned cache of values of type T
+type Typed[T any] struct {
+	cache      *PatternedCache
+	marshaller Marshaller
+}
+
+// NewTyped returns patterned cache of values of type T
+// if marshaller is not nil, values are marshalled by cacher behind pattern, see EncodedAs,
+// so pattern and persister see values of T, marshaller must unmarshal to T,
+// e.g. json marshaller created with type of T, otherwise values are stored as is
+func NewTyped[T any](cacher Cacher, persister Persister, marshaller Marshaller, options ...Option) (*Typed[T], error) {
+	if marshaller != nil && cacher != nil {
+		cacher = EncodedAs[T](cacher, MarshallerCodec(marshaller))
+	}
+
+	cache, err := New(cacher, persister, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Typed[T]{cache: cache, marshaller: marshaller}, nil
+}
+
+// Cache returns underlying patterned cache
+func (t *Typed[T]) Cache() *PatternedCache {
+	return t.cache
+}
+
+// Set sets key-value to cache
+func (t *Typed[T]) Set(ctx context.Context, key string, value T, options ...SetOption) error {
+	return t.cache.Set(ctx, key, value, options...)
+}
+
+// Get retrieves value from cache
+// zero value is returned if key is not found
+func (t *Typed[T]) Get(ctx context.Context, key string) (T, error) {
+	var zero T
+
+	value, err := t.cache.Get(ctx, key)
+	if err != nil || value == nil {
+		return zero, err
+	}
+
+	return t.decode(key, value)
+}
+
+// Delete deletes value from cache
+func (t *Typed[T]) Delete(ctx context.Context, key string) error {
+	return t.cache.Delete(ctx, key)
+}
+
+// SetMany sets multiple key-values to cache
+func (t *Typed[T]) SetMany(ctx context.Context, data map[string]T, options ...SetOption) error {
+	values := make(map[string]any, len(data))
+	for key, value := range data {
+		values[key] = value
+	}
+
+	return t.cache.SetMany(ctx, values, options...)
+}
+
+// GetMany retrieves values of multiple keys from cache
+// returned map contains only keys which are found
+func (t *Typed[T]) GetMany(ctx context.Context, keys []string) (map[string]T, error) {
+	values, err := t.cache.GetMany(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := make(map[string]T, len(values))
+	for key, value := range values {
+		v, err := t.decode(key, value)
+		if err != nil {
+			return nil, err
+		}
+		decoded[key] = v
+	}
+
+	return decoded, nil
+}
+
+// DeleteMany deletes values of multiple keys from cache
+func (t *Typed[T]) DeleteMany(ctx context.Context, keys ...string) error {
+	return t.cache.DeleteMany(ctx, keys...)
+}
+
+// decode returns cached value as T, values encoded by cacher are already decoded,
+// values marshalled without codec header, e.g. stored by other clients, are unmarshalled if marshaller is set
+func (t *Typed[T]) decode(key string, value any) (T, error) {
+	var zero T
+
+	if typed, ok := value.(T); ok {
+		return typed, nil
+	}
+
+	if t.marshaller != nil {
+		var encoded []byte
+		switch v := value.(type) {
+		case []byte:
+			encoded = v
+		case string:
+			encoded = []byte(v)
+		}
+
+		if encoded != nil {
+			unmarshalled, err := t.marshaller.Unmarshal(encoded)
+			if err != nil {
+				return zero, keyError(key, err)
+			}
+			if typed, ok := unmarshalled.(T); ok {
+				return typed, nil
+			}
+			value = unmarshalled
+		}
+	}
+
+	return zero, keyError(key, fmt.Errorf("%w: %T, want %T", ErrUnexpectedType, value, zero))
+}
@@ -0,0 +1,105 @@
+// Package typed provides cache of values of one type on top of any cache, e.g. cacher or patterned cache
+package typed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/albinzx/cache"
+)
+
+// Cache is cache of values of type T on top of any cache, so callers neither type-assert nor marshal values,
+// cache.Typed is its counterpart owning patterned cache, both encode values behind pattern with cache.EncodedAs
+type Cache[T any] struct {
+	cache cache.Cache
+	codec cache.Codec
+}
+
+// config holds typed cache options
+type config struct {
+	codec cache.Codec
+}
+
+// Option provides typed cache options
+type Option func(*config)
+
+// WithCodec returns option to set codec of values, default is cache.JSONCodec
+func WithCodec(codec cache.Codec) Option {
+	return func(c *config) {
+		c.codec = codec
+	}
+}
+
+// New returns cache of values of type T stored in c, which may be cache.Cacher or *cache.PatternedCache,
+// values set to cacher are encoded with codec by cache.EncodedAs, values set to other caches,
+// e.g. patterned cache, are passed as is, so pattern and persister see values of T,
+// cacher of patterned cache should be wrapped with cache.EncodedAs to store them encoded
+func New[T any](c cache.Cache, options ...Option) *Cache[T] {
+	config := &config{codec: cache.JSONCodec{}}
+	for _, option := range options {
+		option(config)
+	}
+
+	if cacher, ok := c.(cache.Cacher); ok {
+		c = cache.EncodedAs[T](cacher, config.codec)
+	}
+
+	return &Cache[T]{cache: c, codec: config.codec}
+}
+
+// Set sets key-value to cache
+func (c *Cache[T]) Set(ctx context.Context, key string, value T, options ...cache.SetOption) error {
+	return c.cache.Set(ctx, key, value, options...)
+}
+
+// Get gets value from cache, false is returned if key is not found
+func (c *Cache[T]) Get(ctx context.Context, key string) (T, bool, error) {
+	var zero T
+
+	value, err := c.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrNotFound) {
+		return zero, false, nil
+	}
+	if err != nil || value == nil {
+		return zero, false, err
+	}
+
+	decoded, err := c.decode(value)
+	if err != nil {
+		return zero, false, &cache.KeyError{Key: key, Err: err}
+	}
+
+	return decoded, true, nil
+}
+
+// Delete deletes value from cache
+func (c *Cache[T]) Delete(ctx context.Context, key string) error {
+	return c.cache.Delete(ctx, key)
+}
+
+// decode returns cached value as T, values encoded behind pattern are already decoded,
+// bytes without codec header, e.g. stored by other clients, are decoded with codec
+func (c *Cache[T]) decode(value any) (T, error) {
+	var decoded T
+
+	if typed, ok := value.(T); ok {
+		return typed, nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return decoded, fmt.Errorf("%w: %T, want %T", cache.ErrUnexpectedType, value, decoded)
+	}
+
+	if err := c.codec.Unmarshal(data, &decoded); err != nil {
+		return decoded, err
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,117 @@
+package typed
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+	"github.com/albinzx/cache/memory"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	c := New[user](memory.New(memory.WithNotFoundError()))
+
+	if _, ok, err := c.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("Get(missing) = %v, %v, want not found", ok, err)
+	}
+
+	want := user{ID: 1, Name: "one"}
+	if err := c.Set(ctx, "user.1", want); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, ok, err := c.Get(ctx, "user.1")
+	if err != nil || !ok || got != want {
+		t.Errorf("Get() = %v, %v, %v, want %v", got, ok, err, want)
+	}
+
+	if err := c.Delete(ctx, "user.1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, ok, _ := c.Get(ctx, "user.1"); ok {
+		t.Error("Get() found deleted value")
+	}
+}
+
+func TestPatternedCache(t *testing.T) {
+	ctx := context.Background()
+	p := cachetest.NewPersister(map[string]any{"user.2": user{ID: 2, Name: "two"}})
+
+	m := memory.New()
+	patterned, err := cache.New(cache.EncodedAs[user](m, cache.JSONCodec{}), p, cache.WithPattern(&cache.ReadThrough{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := New[user](patterned)
+
+	// value read through from persistence storage is T itself, cacher behind pattern stores it encoded
+	got, ok, err := c.Get(ctx, "user.2")
+	if err != nil || !ok || got.Name != "two" {
+		t.Errorf("Get() = %v, %v, %v, want two", got, ok, err)
+	}
+	if stored, _ := m.Get(ctx, "user.2"); !isBytes(stored) {
+		t.Errorf("cacher stored %T, want encoded bytes", stored)
+	}
+
+	if err := c.Set(ctx, "user.3", user{ID: 3, Name: "three"}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, ok, err := c.Get(ctx, "user.3"); err != nil || !ok || got.Name != "three" {
+		t.Errorf("Get() = %v, %v, %v, want three", got, ok, err)
+	}
+}
+
+func TestBytesAndStrings(t *testing.T) {
+	ctx := context.Background()
+
+	b := New[[]byte](memory.New())
+	if err := b.Set(ctx, "key", []byte("x")); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, ok, err := b.Get(ctx, "key"); err != nil || !ok || string(got) != "x" {
+		t.Errorf("Get() = %q, %v, %v, want x", got, ok, err)
+	}
+
+	s := New[string](memory.New())
+	if err := s.Set(ctx, "key", "x"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, ok, err := s.Get(ctx, "key"); err != nil || !ok || got != "x" {
+		t.Errorf("Get() = %q, %v, %v, want x", got, ok, err)
+	}
+}
+
+func TestForeignEncoded(t *testing.T) {
+	ctx := context.Background()
+	m := memory.New()
+	// value stored encoded by other client, without codec header
+	_ = m.Set(ctx, "user.4", `{"id":4,"name":"four"}`)
+
+	got, ok, err := New[user](m).Get(ctx, "user.4")
+	if err != nil || !ok || got != (user{ID: 4, Name: "four"}) {
+		t.Errorf("Get() = %v, %v, %v, want user four", got, ok, err)
+	}
+}
+
+func isBytes(value any) bool {
+	_, ok := value.([]byte)
+	return ok
+}
+
+func TestUnexpectedType(t *testing.T) {
+	ctx := context.Background()
+	m := memory.New()
+	_ = m.Set(ctx, "key", 42)
+
+	_, _, err := New[user](m).Get(ctx, "key")
+	if !errors.Is(err, cache.ErrUnexpectedType) {
+		t.Errorf("Get() error = %v, want ErrUnexpectedType", err)
+	}
+}
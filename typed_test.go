@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/albinzx/marshal"
+	"github.com/albinzx/marshal/json"
+)
+
+type typedValue struct {
+	Number int
+	Text   string
+}
+
+func TestTyped(t *testing.T) {
+	jsonMarshaller, _ := json.New(reflect.TypeOf(typedValue{}))
+
+	tests := []struct {
+		name       string
+		marshaller marshal.Marshaller
+		cached     any
+		want       typedValue
+		wantErr    error
+	}{
+		{
+			name:   "test value as is",
+			cached: typedValue{Number: 1, Text: "one"},
+			want:   typedValue{Number: 1, Text: "one"},
+		},
+		{
+			name:       "test marshalled value",
+			marshaller: jsonMarshaller,
+			cached:     `{"Number":1,"Text":"one"}`,
+			want:       typedValue{Number: 1, Text: "one"},
+		},
+		{
+			name:    "test unexpected type",
+			cached:  "one",
+			wantErr: ErrUnexpectedType,
+		},
+		{
+			name: "test miss",
+			want: typedValue{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := newMapCacher()
+			if tt.cached != nil {
+				cacher.data["key"] = tt.cached
+			}
+			c, _ := NewTyped[typedValue](cacher, nil, tt.marshaller)
+
+			got, err := c.Get(context.Background(), "key")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTyped_SetMany(t *testing.T) {
+	jsonMarshaller, _ := json.New(reflect.TypeOf(typedValue{}))
+	cacher := newMapCacher()
+	c, _ := NewTyped[typedValue](cacher, nil, jsonMarshaller)
+
+	want := map[string]typedValue{"a": {Number: 1}, "b": {Number: 2}}
+	if err := c.SetMany(context.Background(), want); err != nil {
+		t.Errorf("SetMany() error = %v", err)
+	}
+	if _, ok := cacher.data["a"].([]byte); !ok {
+		t.Errorf("SetMany() stored = %T, want %T", cacher.data["a"], []byte{})
+	}
+
+	got, err := c.GetMany(context.Background(), []string{"a", "b", "c"})
+	if err != nil {
+		t.Errorf("GetMany() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMany() = %v, want %v", got, want)
+	}
+}
+
+func TestTyped_Bytes(t *testing.T) {
+	ctx := context.Background()
+	cacher := newMapCacher()
+	c, _ := NewTyped[[]byte](cacher, nil, CodecMarshaller(JSONCodec{}, []byte{}))
+
+	if err := c.Set(ctx, "key", []byte("x")); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := c.Get(ctx, "key")
+	if err != nil || string(got) != "x" {
+		t.Errorf("Get() = %q, %v, want x", got, err)
+	}
+}
@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+)
+
+// versionedMagic starts header of versioned values
+// header is magic, kind of original value and schema version as uvarint, followed by value
+var versionedMagic = []byte{0xc7, 0x56}
+
+// Upgrade converts encoded value of one schema version to the next version
+type Upgrade func(ctx context.Context, key string, data []byte) ([]byte, error)
+
+// versioned is cacher which stores values in envelope recording schema version
+type versioned struct {
+	Cacher
+	version  uint64
+	upgrades map[uint64]Upgrade
+}
+
+// VersionOption provides versioned cacher options
+type VersionOption func(*versioned)
+
+// WithUpgrade returns option to register upgrade of values of schema version from to version from+1
+func WithUpgrade(from uint64, upgrade Upgrade) VersionOption {
+	return func(v *versioned) {
+		v.upgrades[from] = upgrade
+	}
+}
+
+// Versioned returns cacher which stores values with schema version, so deploys changing
+// shape of cached values do not require flushing the cache
+// values of older version are upgraded on Get by registered upgrades applied in sequence,
+// values which can not be upgraded and values of newer version, e.g. written by another
+// instance during rolling deploy, are reported as not found
+// only []byte and string values have envelope, so versioning is usually combined with marshaller,
+// values written before versioning was enabled are version 0
+func Versioned(c Cacher, version uint64, options ...VersionOption) Cacher {
+	v := &versioned{Cacher: c, version: version, upgrades: map[uint64]Upgrade{}}
+
+	for _, option := range options {
+		option(v)
+	}
+
+	return v
+}
+
+// wrap returns value in envelope of current version
+func (v *versioned) wrap(value any) any {
+	var data []byte
+	var kind byte
+
+	switch val := value.(type) {
+	case []byte:
+		data, kind = val, kindBytes
+	case string:
+		data, kind = []byte(val), kindString
+	default:
+		return value
+	}
+
+	out := make([]byte, 0, len(versionedMagic)+1+binary.MaxVarintLen64+len(data))
+	out = append(out, versionedMagic...)
+	out = append(out, kind)
+	out = binary.AppendUvarint(out, v.version)
+
+	return append(out, data...)
+}
+
+// unwrap returns value of current version, upgraded if needed, nil value means value can not be used
+func (v *versioned) unwrap(ctx context.Context, key string, value any) (any, error) {
+	var data []byte
+	kind := kindBytes
+
+	switch val := value.(type) {
+	case []byte:
+		data = val
+	case string:
+		data, kind = []byte(val), kindString
+	default:
+		return value, nil
+	}
+
+	version := uint64(0)
+	if len(data) > len(versionedMagic) && bytes.Equal(data[:len(versionedMagic)], versionedMagic) {
+		kind = data[len(versionedMagic)]
+		n := 0
+		version, n = binary.Uvarint(data[len(versionedMagic)+1:])
+		if n <= 0 {
+			return nil, nil
+		}
+		data = data[len(versionedMagic)+1+n:]
+	}
+
+	for ; version < v.version; version++ {
+		upgrade, ok := v.upgrades[version]
+		if !ok {
+			return nil, nil
+		}
+
+		upgraded, err := upgrade(ctx, key, data)
+		if err != nil {
+			return nil, keyError(key, err)
+		}
+		data = upgraded
+	}
+	if version > v.version {
+		return nil, nil
+	}
+
+	if kind == kindString {
+		return string(data), nil
+	}
+
+	return data, nil
+}
+
+// Set sets value in envelope of current version to cache
+func (v *versioned) Set(ctx context.Context, key string, value any, options ...SetOption) error {
+	return v.Cacher.Set(ctx, key, v.wrap(value), options...)
+}
+
+// Get gets value from cache and upgrades it to current version
+func (v *versioned) Get(ctx context.Context, key string) (any, error) {
+	value, err := v.Cacher.Get(ctx, key)
+	if err != nil || value == nil {
+		return value, err
+	}
+
+	return v.unwrap(ctx, key, value)
+}
+
+// Load loads values in envelopes of current version into cache
+func (v *versioned) Load(ctx context.Context, data map[string]any) error {
+	values := make(map[string]any, len(data))
+	for key, value := range data {
+		values[key] = v.wrap(value)
+	}
+
+	return v.Cacher.Load(ctx, values)
+}
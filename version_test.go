@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestVersioned(t *testing.T) {
+	ctx := context.Background()
+	m := newMapCacher()
+	v1 := Versioned(m, 1)
+	v2 := Versioned(m, 2,
+		WithUpgrade(0, func(_ context.Context, _ string, data []byte) ([]byte, error) {
+			return append([]byte("v1:"), data...), nil
+		}),
+		WithUpgrade(1, func(_ context.Context, key string, data []byte) ([]byte, error) {
+			if key == "broken" {
+				return nil, errors.New("broken")
+			}
+			return []byte(strings.Replace(string(data), "v1:", "v2:", 1)), nil
+		}),
+	)
+
+	m.data["legacy"] = "name"
+	_ = v1.Set(ctx, "old", "v1:name")
+	_ = v1.Set(ctx, "broken", []byte("v1:name"))
+	_ = v2.Load(ctx, map[string]any{"new": []byte("v2:name")})
+	m.data["other"] = 42
+
+	tests := []struct {
+		name    string
+		cacher  Cacher
+		key     string
+		want    any
+		wantErr bool
+	}{
+		{name: "test legacy value upgraded", cacher: v2, key: "legacy", want: "v2:name"},
+		{name: "test older value upgraded", cacher: v2, key: "old", want: "v2:name"},
+		{name: "test current value", cacher: v2, key: "new", want: "v2:name"},
+		{name: "test failed upgrade", cacher: v2, key: "broken", wantErr: true},
+		{name: "test newer value not found", cacher: v1, key: "new", want: nil},
+		{name: "test no upgrade not found", cacher: Versioned(m, 3), key: "new", want: nil},
+		{name: "test value without envelope", cacher: v2, key: "other", want: 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cacher.Get(ctx, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if b, ok := got.([]byte); ok {
+				got = string(b)
+			}
+			if got != tt.want {
+				t.Errorf("Get() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
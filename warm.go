@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/albinzx/cache/internal"
+)
+
+var (
+	// ErrPersisterNil is returned when operation requires persister but it is nil
+	ErrPersisterNil = errors.New("persister is nil")
+)
+
+// warmConfig holds configuration of warm operation
+type warmConfig struct {
+	filter      func(key string, value any) bool
+	concurrency int
+	batchSize   int
+	progress    func(WarmProgress)
+}
+
+// WarmProgress is progress of warm operation
+type WarmProgress struct {
+	// Loaded is number of key-values loaded so far
+	Loaded int
+	// Failed is number of key-values failed to load so far
+	Failed int
+	// Total is number of key-values to load
+	Total int
+}
+
+// WarmOption provides options for warm operation
+type WarmOption func(*warmConfig)
+
+// WithWarmFilter returns option to load only key-values accepted by filter
+func WithWarmFilter(filter func(key string, value any) bool) WarmOption {
+	return func(config *warmConfig) {
+		config.filter = filter
+	}
+}
+
+// WithWarmConcurrency returns option to set number of batches loaded concurrently, default is 1
+func WithWarmConcurrency(concurrency int) WarmOption {
+	return func(config *warmConfig) {
+		config.concurrency = concurrency
+	}
+}
+
+// WithWarmBatchSize returns option to set maximum number of key-values loaded at once, default is 1000
+func WithWarmBatchSize(batchSize int) WarmOption {
+	return func(config *warmConfig) {
+		config.batchSize = batchSize
+	}
+}
+
+// WithWarmProgress returns option to call progress after every loaded or failed batch,
+// it is called by one batch at a time so it must not block
+func WithWarmProgress(progress func(WarmProgress)) WarmOption {
+	return func(config *warmConfig) {
+		config.progress = progress
+	}
+}
+
+// WithWarmOnStart returns option to warm cache with options when it is created by New,
+// New waits until cache is warm, failures are logged and leave cache partially warm
+func WithWarmOnStart(options ...WarmOption) Option {
+	return func(c *PatternedCache) {
+		c.warmOnStart = true
+		c.warmOptions = options
+	}
+}
+
+// Warm preloads cache with all key-values selected from persistence storage
+// it returns number of key-values loaded, batches which fail to load are skipped
+// and returned error joins their errors
+func (c *PatternedCache) Warm(ctx context.Context, options ...WarmOption) (int, error) {
+	if c.persister == nil {
+		return 0, ErrPersisterNil
+	}
+
+	config := &warmConfig{concurrency: 1, batchSize: 1000}
+	for _, option := range options {
+		option(config)
+	}
+	if config.concurrency < 1 {
+		config.concurrency = 1
+	}
+	if config.batchSize < 1 {
+		config.batchSize = 1000
+	}
+
+	data, err := c.persister.SelectAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	var batches []map[string]any
+	batch := make(map[string]any, config.batchSize)
+	for _, key := range internal.SortedKeys(data) {
+		if config.filter != nil && !config.filter(key, data[key]) {
+			continue
+		}
+
+		batch[key] = data[key]
+		total++
+		if len(batch) == config.batchSize {
+			batches = append(batches, batch)
+			batch = make(map[string]any, config.batchSize)
+		}
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var errs []error
+	loaded, failed := 0, 0
+	semaphore := make(chan struct{}, config.concurrency)
+
+	for _, batch := range batches {
+		semaphore <- struct{}{}
+		wg.Add(1)
+		go func(batch map[string]any) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			err := c.cacher.Load(ctx, batch)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				failed += len(batch)
+				c.scope.logger.Error(ctx, "failed to load values to cache", "load", "", err)
+			} else {
+				loaded += len(batch)
+			}
+			if config.progress != nil {
+				config.progress(WarmProgress{Loaded: loaded, Failed: failed, Total: total})
+			}
+		}(batch)
+	}
+	wg.Wait()
+
+	return loaded, errors.Join(errs...)
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// failingLoadCacher fails loading batches containing key
+type failingLoadCacher struct {
+	*mapCacher
+	key string
+}
+
+func (c *failingLoadCacher) Load(ctx context.Context, data map[string]any) error {
+	if _, ok := data[c.key]; ok {
+		return errors.New("failed")
+	}
+	return c.mapCacher.Load(ctx, data)
+}
+
+func TestPatternedCache_Warm(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    []WarmOption
+		failKey    string
+		want       int
+		wantErr    bool
+		wantCached map[string]any
+	}{
+		{
+			name:       "test warm all",
+			options:    []WarmOption{WithWarmConcurrency(2), WithWarmBatchSize(2)},
+			want:       3,
+			wantCached: map[string]any{"a": 1, "b": 2, "skip": 3},
+		},
+		{
+			name:       "test warm filtered",
+			options:    []WarmOption{WithWarmFilter(func(key string, _ any) bool { return !strings.HasPrefix(key, "skip") })},
+			want:       2,
+			wantCached: map[string]any{"a": 1, "b": 2},
+		},
+		{
+			name:       "test warm failed batch",
+			options:    []WarmOption{WithWarmBatchSize(2)},
+			failKey:    "skip",
+			want:       2,
+			wantErr:    true,
+			wantCached: map[string]any{"a": 1, "b": 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacher := newMapCacher()
+			persister := newMapPersister()
+			persister.data = map[string]any{"a": 1, "b": 2, "skip": 3}
+			c, _ := New(&failingLoadCacher{mapCacher: cacher, key: tt.failKey}, persister)
+
+			got, err := c.Warm(context.Background(), tt.options...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Warm() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Warm() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(cacher.data, tt.wantCached) {
+				t.Errorf("Warm() cache = %v, want %v", cacher.data, tt.wantCached)
+			}
+		})
+	}
+}
+
+func TestPatternedCache_WarmWithoutPersister(t *testing.T) {
+	c, _ := New(newMapCacher(), nil)
+
+	if _, err := c.Warm(context.Background()); !errors.Is(err, ErrPersisterNil) {
+		t.Errorf("Warm() error = %v, want %v", err, ErrPersisterNil)
+	}
+}
+
+func TestWithWarmOnStart(t *testing.T) {
+	persister := newMapPersister()
+	persister.data = map[string]any{"a": 1, "b": 2, "c": 3}
+	cacher := newMapCacher()
+
+	var progress []WarmProgress
+	_, err := New(cacher, persister, WithWarmOnStart(WithWarmBatchSize(2),
+		WithWarmProgress(func(p WarmProgress) { progress = append(progress, p) })))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if got, _ := cacher.Get(context.Background(), "c"); got != 3 {
+		t.Errorf("Get() = %v, want %v", got, 3)
+	}
+	want := []WarmProgress{{Loaded: 2, Total: 3}, {Loaded: 3, Total: 3}}
+	if !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress = %v, want %v", progress, want)
+	}
+}
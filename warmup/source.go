@@ -0,0 +1,74 @@
+package warmup
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/albinzx/cache"
+)
+
+// Source provides key-values to warm up cache
+type Source interface {
+	// Name identifies source in progress and errors
+	Name() string
+	// Fetch fetches key-values and passes them to emit in batches
+	// fetching stops when emit returns error
+	Fetch(ctx context.Context, emit func(map[string]any) error) error
+}
+
+// funcSource is source fetching with function
+type funcSource struct {
+	name  string
+	fetch func(context.Context, func(map[string]any) error) error
+}
+
+// Name returns source name
+func (f *funcSource) Name() string {
+	return f.name
+}
+
+// Fetch fetches key-values with function
+func (f *funcSource) Fetch(ctx context.Context, emit func(map[string]any) error) error {
+	return f.fetch(ctx, emit)
+}
+
+// SourceFunc returns source with name fetching key-values with fetch
+func SourceFunc(name string, fetch func(ctx context.Context, emit func(map[string]any) error) error) Source {
+	return &funcSource{name: name, fetch: fetch}
+}
+
+// LoaderSource returns source with name loading all key-values at once with load
+func LoaderSource(name string, load func(context.Context) (map[string]any, error)) Source {
+	return SourceFunc(name, func(ctx context.Context, emit func(map[string]any) error) error {
+		data, err := load(ctx)
+		if err != nil {
+			return err
+		}
+
+		return emit(data)
+	})
+}
+
+// PersisterSource returns source loading all key-values of persister with SelectAll
+func PersisterSource(p cache.Persister) Source {
+	return LoaderSource("persister", p.SelectAll)
+}
+
+// FileSource returns source loading key-values from json file containing single object
+func FileSource(path string) Source {
+	return LoaderSource(path, func(context.Context) (map[string]any, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		data := map[string]any{}
+		if err := json.NewDecoder(f).Decode(&data); err != nil {
+			return nil, err
+		}
+
+		return data, nil
+	})
+}
@@ -0,0 +1,249 @@
+// Package warmup coordinates initial population of cache from multiple sources
+package warmup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/internal"
+)
+
+// Progress is progress of warm up
+type Progress struct {
+	// Source is name of source of the last loaded batch
+	Source string
+	// Loaded is number of key-values loaded so far
+	Loaded int
+	// Failed is number of key-values failed to load so far
+	Failed int
+}
+
+// Report is result of warm up
+type Report struct {
+	Loaded int
+	Failed int
+	// Errors are errors of failed sources and batches
+	Errors []error
+}
+
+// Manager warms up cache from sources
+type Manager struct {
+	cacher      cache.Cacher
+	sources     []Source
+	concurrency int
+	batchSize   int
+	progress    func(Progress)
+	failFast    bool
+
+	mu     sync.Mutex
+	report Report
+	ready  chan struct{}
+	once   sync.Once
+}
+
+// Option provides warm up options
+type Option func(*Manager)
+
+// defaults sets default warm up option
+func defaults(m *Manager) {
+	if m.concurrency < 1 {
+		m.concurrency = 1
+	}
+
+	if m.batchSize < 1 {
+		m.batchSize = 1000
+	}
+}
+
+// New returns warm up manager loading key-values of sources into c
+func New(c cache.Cacher, options ...Option) *Manager {
+	m := &Manager{cacher: c, ready: make(chan struct{})}
+
+	for _, option := range options {
+		option(m)
+	}
+
+	defaults(m)
+
+	return m
+}
+
+// WithSources returns option to add sources
+func WithSources(sources ...Source) Option {
+	return func(m *Manager) {
+		m.sources = append(m.sources, sources...)
+	}
+}
+
+// WithConcurrency returns option to set number of concurrent loads into cache
+func WithConcurrency(concurrency int) Option {
+	return func(m *Manager) {
+		m.concurrency = concurrency
+	}
+}
+
+// WithBatchSize returns option to set maximum number of key-values per load into cache
+func WithBatchSize(batchSize int) Option {
+	return func(m *Manager) {
+		m.batchSize = batchSize
+	}
+}
+
+// WithProgress returns option to report progress after every loaded batch
+// progress is called concurrently when concurrency is more than one
+func WithProgress(progress func(Progress)) Option {
+	return func(m *Manager) {
+		m.progress = progress
+	}
+}
+
+// WithFailFast returns option to stop warm up on first error
+// by default failed sources and batches are reported and the rest is still loaded
+func WithFailFast() Option {
+	return func(m *Manager) {
+		m.failFast = true
+	}
+}
+
+// batch is key-values of source to load
+type batch struct {
+	source string
+	data   map[string]any
+}
+
+// Run warms up cache and marks manager ready when done, even on failure
+// returned error joins errors of failed sources and batches
+func (m *Manager) Run(ctx context.Context) (Report, error) {
+	defer m.once.Do(func() { close(m.ready) })
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := make(chan batch)
+
+	var loaders sync.WaitGroup
+	for i := 0; i < m.concurrency; i++ {
+		loaders.Add(1)
+		go func() {
+			defer loaders.Done()
+			for b := range batches {
+				m.load(ctx, b, cancel)
+			}
+		}()
+	}
+
+	var fetchers sync.WaitGroup
+	for _, source := range m.sources {
+		fetchers.Add(1)
+		go func(source Source) {
+			defer fetchers.Done()
+			defer cache.RecoverTo(func(err error) {
+				m.fail(fmt.Errorf("source %s: %w", source.Name(), err), 0, cancel)
+			})
+
+			err := source.Fetch(ctx, func(data map[string]any) error {
+				for _, chunk := range m.split(data) {
+					select {
+					case batches <- batch{source: source.Name(), data: chunk}:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+				return nil
+			})
+			if err != nil && !(m.failFast && errors.Is(err, context.Canceled)) {
+				m.fail(fmt.Errorf("source %s: %w", source.Name(), err), 0, cancel)
+			}
+		}(source)
+	}
+
+	fetchers.Wait()
+	close(batches)
+	loaders.Wait()
+
+	report := m.Report()
+
+	return report, errors.Join(report.Errors...)
+}
+
+// split splits data into batches of batch size
+func (m *Manager) split(data map[string]any) []map[string]any {
+	var chunks []map[string]any
+	chunk := make(map[string]any, m.batchSize)
+
+	for _, key := range internal.SortedKeys(data) {
+		chunk[key] = data[key]
+		if len(chunk) == m.batchSize {
+			chunks = append(chunks, chunk)
+			chunk = make(map[string]any, m.batchSize)
+		}
+	}
+
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// load loads batch into cache and reports progress
+// panic of cacher or progress is recovered and reported as failure of the batch
+func (m *Manager) load(ctx context.Context, b batch, cancel context.CancelFunc) {
+	defer cache.RecoverTo(func(err error) {
+		m.fail(fmt.Errorf("source %s: %w", b.source, err), len(b.data), cancel)
+	})
+
+	if err := m.cacher.Load(ctx, b.data); err != nil {
+		m.fail(fmt.Errorf("source %s: %w", b.source, err), len(b.data), cancel)
+	} else {
+		m.mu.Lock()
+		m.report.Loaded += len(b.data)
+		m.mu.Unlock()
+	}
+
+	if m.progress != nil {
+		report := m.Report()
+		m.progress(Progress{Source: b.source, Loaded: report.Loaded, Failed: report.Failed})
+	}
+}
+
+// fail records error of failed key-values and stops warm up if fail fast
+func (m *Manager) fail(err error, failed int, cancel context.CancelFunc) {
+	m.mu.Lock()
+	m.report.Errors = append(m.report.Errors, err)
+	m.report.Failed += failed
+	m.mu.Unlock()
+
+	if m.failFast {
+		cancel()
+	}
+}
+
+// Report returns current report of warm up
+func (m *Manager) Report() Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report := m.report
+	report.Errors = append([]error(nil), m.report.Errors...)
+
+	return report
+}
+
+// Ready returns channel which is closed when warm up is done
+func (m *Manager) Ready() <-chan struct{} {
+	return m.ready
+}
+
+// Wait blocks until warm up is done or context is done
+func (m *Manager) Wait(ctx context.Context) error {
+	select {
+	case <-m.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,86 @@
+package warmup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/albinzx/cache"
+	"github.com/albinzx/cache/cachetest"
+)
+
+func TestManager_Run(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "warm.json")
+	if err := os.WriteFile(file, []byte(`{"file1": "value", "file2": "value"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	errSource := errors.New("source down")
+	tests := []struct {
+		name       string
+		sources    []Source
+		fail       error
+		wantLoaded int
+		wantFailed int
+		wantErr    bool
+	}{
+		{
+			name: "test all sources",
+			sources: []Source{
+				PersisterSource(cachetest.NewPersister(map[string]any{"a": 1, "b": 2, "c": 3})),
+				FileSource(file),
+			},
+			wantLoaded: 5,
+		},
+		{
+			name: "test failing source",
+			sources: []Source{
+				PersisterSource(cachetest.NewPersister(map[string]any{"a": 1})),
+				LoaderSource("broken", func(context.Context) (map[string]any, error) { return nil, errSource }),
+			},
+			wantLoaded: 1,
+			wantErr:    true,
+		},
+		{
+			name: "test failing load",
+			sources: []Source{
+				PersisterSource(cachetest.NewPersister(map[string]any{"a": 1, "b": 2, "c": 3})),
+			},
+			fail:       errors.New("cache down"),
+			wantFailed: 3,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cachetest.NewCacher()
+			if tt.fail != nil {
+				c.Fail(cache.OpLoad, tt.fail)
+			}
+
+			var progress []Progress
+			m := New(c, WithSources(tt.sources...), WithBatchSize(2), WithProgress(func(p Progress) {
+				progress = append(progress, p)
+			}))
+
+			report, err := m.Run(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Manager.Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if report.Loaded != tt.wantLoaded || report.Failed != tt.wantFailed {
+				t.Errorf("Manager.Run() report = %+v, want loaded %v failed %v", report, tt.wantLoaded, tt.wantFailed)
+			}
+			if got := c.Len(); got != tt.wantLoaded {
+				t.Errorf("Manager.Run() cached = %v, want %v", got, tt.wantLoaded)
+			}
+			if len(progress) == 0 && tt.wantLoaded+tt.wantFailed > 0 {
+				t.Errorf("Manager.Run() reported no progress")
+			}
+			if err := m.Wait(context.Background()); err != nil {
+				t.Errorf("Manager.Wait() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,506 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultWriteBatchSize is default number of writes persisted in one batch
+	DefaultWriteBatchSize = 100
+	// DefaultWriteConcurrency is default number of writes persisted at the same time
+	DefaultWriteConcurrency = 4
+)
+
+var (
+	// ErrWriteBehindClosed is returned by writes of write-behind pattern which is closed
+	ErrWriteBehindClosed = errors.New("write-behind is closed")
+)
+
+// WriteBehind is a cache pattern that writes to cache first
+// and then writes to persistence storage asynchronously
+//
+// writes are queued and persisted in batches by background writer, queued write of a key is replaced
+// by later write of the same key so only the last one is persisted, unless coalescing is disabled,
+// batches are persisted one after another so writes of a key are persisted in order they were made
+// writes are persisted with context detached from cancellation of caller context,
+// so cancelled requests do not drop persistence
+type WriteBehind struct {
+	journal     WriteJournal
+	writeQueue  WriteQueue
+	attempts    int
+	backoff     time.Duration
+	deadLetters DeadLetterSink
+	batchSize   int
+	interval    time.Duration
+	concurrency int
+	noCoalesce  bool
+	clock       Clock
+
+	// closing is read locked by writes from check of closed state until they are queued,
+	// so writes accepted before Close are persisted by it
+	closing sync.RWMutex
+
+	mu      sync.Mutex
+	queue   []*queuedWrite
+	queued  map[string]*queuedWrite
+	pending int
+	idle    *sync.Cond
+	closed  bool
+	start   sync.Once
+	wake    chan struct{}
+	flush   chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+
+	retries      atomic.Int64
+	deadLettered atomic.Int64
+	dropped      atomic.Int64
+	coalesced    atomic.Int64
+}
+
+// queuedWrite is write waiting to be persisted
+type queuedWrite struct {
+	ctx   context.Context
+	op    Operation
+	key   string
+	value any
+	seq   uint64
+	c     Cacher
+	p     Persister
+}
+
+// WriteBehindOption provides write-behind options
+type WriteBehindOption func(*WriteBehind)
+
+// WithWriteJournal returns option to record pending writes in journal before they are persisted,
+// writes which are not persisted when process stops are replayed when patterned cache is created
+func WithWriteJournal(journal WriteJournal) WriteBehindOption {
+	return func(w *WriteBehind) {
+		w.journal = journal
+	}
+}
+
+// WithWriteBatchSize returns option to set maximum number of writes persisted in one batch,
+// default is DefaultWriteBatchSize
+func WithWriteBatchSize(size int) WriteBehindOption {
+	return func(w *WriteBehind) {
+		w.batchSize = size
+	}
+}
+
+// WithFlushInterval returns option to persist queued writes once per interval or when batch is full,
+// so more writes of the same key are coalesced, by default writes are persisted as soon as they are queued
+func WithFlushInterval(interval time.Duration) WriteBehindOption {
+	return func(w *WriteBehind) {
+		w.interval = interval
+	}
+}
+
+// WithWriteConcurrency returns option to set maximum number of writes persisted at the same time,
+// default is DefaultWriteConcurrency
+func WithWriteConcurrency(concurrency int) WriteBehindOption {
+	return func(w *WriteBehind) {
+		w.concurrency = concurrency
+	}
+}
+
+// WithoutWriteCoalescing returns option to persist every write instead of only the last queued write of a key,
+// e.g. for append-style persisters recording history of values, writes of a key are still persisted in order
+func WithoutWriteCoalescing() WriteBehindOption {
+	return func(w *WriteBehind) {
+		w.noCoalesce = true
+	}
+}
+
+// NewWriteBehind returns write-behind pattern
+func NewWriteBehind(options ...WriteBehindOption) *WriteBehind {
+	w := &WriteBehind{}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+// init starts background writer once, zero value of write-behind is started on first write
+func (w *WriteBehind) init() {
+	w.start.Do(func() {
+		if w.batchSize <= 0 {
+			w.batchSize = DefaultWriteBatchSize
+		}
+		if w.concurrency <= 0 {
+			w.concurrency = DefaultWriteConcurrency
+		}
+		if w.clock == nil {
+			w.clock = SystemClock
+		}
+		w.queued = map[string]*queuedWrite{}
+		w.idle = sync.NewCond(&w.mu)
+		w.wake = make(chan struct{}, 1)
+		w.flush = make(chan struct{}, 1)
+		w.stop = make(chan struct{})
+		w.done = make(chan struct{})
+
+		go w.run()
+	})
+}
+
+// run persists queued writes until write-behind is closed
+func (w *WriteBehind) run() {
+	defer close(w.done)
+
+	var tick <-chan time.Time
+	if w.interval > 0 {
+		ticker := w.clock.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C()
+	}
+
+	for {
+		select {
+		case <-w.wake:
+			w.dispatch(w.interval <= 0)
+		case <-tick:
+			w.dispatch(true)
+		case <-w.flush:
+			w.dispatch(true)
+		case <-w.stop:
+			w.dispatch(true)
+			return
+		}
+	}
+}
+
+// signal notifies background writer without blocking
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue records write in journal and queues it to be persisted, or pushes it to write queue if it is set
+func (w *WriteBehind) enqueue(ctx context.Context, op Operation, key string, value any, c Cacher, p Persister) error {
+	if w.writeQueue != nil {
+		return w.writeQueue.Push(ctx, op, key, value)
+	}
+
+	w.init()
+
+	seq := w.record(ctx, op, key, value)
+	write := &queuedWrite{ctx: context.WithoutCancel(ctx), op: op, key: key, value: value, seq: seq, c: c, p: p}
+
+	w.mu.Lock()
+	superseded := w.push(write)
+	w.mu.Unlock()
+
+	w.ack(ctx, key, superseded)
+	signal(w.wake)
+
+	return nil
+}
+
+// push queues write, replacing queued write of the same key unless coalescing is disabled,
+// and returns sequence number of replaced write to be acked, it must be called with lock held
+func (w *WriteBehind) push(write *queuedWrite) uint64 {
+	if !w.noCoalesce {
+		if queued, ok := w.queued[write.key]; ok {
+			superseded := queued.seq
+			*queued = *write
+			w.coalesced.Add(1)
+			return superseded
+		}
+		w.queued[write.key] = write
+	}
+
+	w.pending++
+	w.queue = append(w.queue, write)
+
+	return 0
+}
+
+// admit read locks closing of write-behind until returned release is called, so write is queued
+// before Close persists queued writes, ErrWriteBehindClosed is returned if write-behind is closed
+func (w *WriteBehind) admit() (func(), error) {
+	w.closing.RLock()
+
+	w.mu.Lock()
+	closed := w.closed
+	w.mu.Unlock()
+
+	if closed {
+		w.closing.RUnlock()
+		return nil, ErrWriteBehindClosed
+	}
+
+	return w.closing.RUnlock, nil
+}
+
+// persisted marks queued write persisted
+func (w *WriteBehind) persisted() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending--
+	if w.pending == 0 {
+		w.idle.Broadcast()
+	}
+}
+
+// wait waits until queued writes are persisted
+func (w *WriteBehind) wait() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for w.pending > 0 {
+		w.idle.Wait()
+	}
+}
+
+// dispatch persists queued writes batch by batch, only full batches are persisted unless all is set
+func (w *WriteBehind) dispatch(all bool) {
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 || (!all && len(w.queue) < w.batchSize) {
+			w.mu.Unlock()
+			return
+		}
+		n := min(len(w.queue), w.batchSize)
+		batch := w.queue[:n:n]
+		w.queue = w.queue[n:]
+		for _, write := range batch {
+			delete(w.queued, write.key)
+		}
+		w.mu.Unlock()
+
+		w.persist(batch)
+	}
+}
+
+// persist persists batch of writes by at most concurrency workers,
+// writes of the same key are persisted by one worker in order they were made
+func (w *WriteBehind) persist(batch []*queuedWrite) {
+	keys := make([]string, 0, len(batch))
+	writes := make(map[string][]*queuedWrite, len(batch))
+	for _, write := range batch {
+		if _, ok := writes[write.key]; !ok {
+			keys = append(keys, write.key)
+		}
+		writes[write.key] = append(writes[write.key], write)
+	}
+
+	sem := make(chan struct{}, w.concurrency)
+	var wg sync.WaitGroup
+	for _, key := range keys {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(writes []*queuedWrite) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			for _, write := range writes {
+				if write.op == OpDelete {
+					w.remove(write.ctx, write.key, write.p, write.seq)
+				} else {
+					w.save(write.ctx, write.key, write.value, write.c, write.p, write.seq)
+				}
+				w.persisted()
+			}
+		}(writes[key])
+	}
+	wg.Wait()
+}
+
+// record records pending write in journal and returns its sequence number, 0 if it is not recorded
+// write is still persisted asynchronously if it can not be recorded
+func (w *WriteBehind) record(ctx context.Context, op Operation, key string, value any) uint64 {
+	if w.journal == nil {
+		return 0
+	}
+
+	seq, err := w.journal.Append(op, key, value)
+	if err != nil {
+		reporterFrom(ctx, "journal", key)(err)
+		return 0
+	}
+
+	return seq
+}
+
+// ack marks recorded write done
+func (w *WriteBehind) ack(ctx context.Context, key string, seq uint64) {
+	if seq == 0 {
+		return
+	}
+
+	if err := w.journal.Ack(seq); err != nil {
+		reporterFrom(ctx, "journal", key)(err)
+	}
+}
+
+// save persists value of key, retrying it, and evicts it from cache if it fails,
+// failed write is given to dead letter sink and stays in journal if sink fails
+func (w *WriteBehind) save(ctx context.Context, key string, value any, c Cacher, p Persister, seq uint64) {
+	report := reporterFrom(ctx, "save", key)
+	defer RecoverTo(report)
+
+	attempts, err := w.deliver(ctx, func() error { return p.Save(ctx, key, value) })
+	if err == nil {
+		w.ack(ctx, key, seq)
+		return
+	}
+
+	report(err)
+	if w.deadLetter(ctx, DeadLetter{Op: OpSave, Key: key, Value: value, Err: err, Attempts: attempts, Time: w.clock.Now()}) {
+		w.ack(ctx, key, seq)
+	}
+
+	if derr := c.Delete(ctx, key); derr != nil {
+		loggerFrom(ctx).Error(ctx, "failed to delete value from cache", "delete", key, derr)
+	} else {
+		emit(ctx, Event{Type: EventEvictOnError, Key: key, Time: w.clock.Now(), Err: err})
+	}
+}
+
+// remove deletes value of key from persistence storage, retrying it,
+// failed write is given to dead letter sink and stays in journal if sink fails
+func (w *WriteBehind) remove(ctx context.Context, key string, p Persister, seq uint64) {
+	report := reporterFrom(ctx, "delete", key)
+	defer RecoverTo(report)
+
+	attempts, err := w.deliver(ctx, func() error { return p.Delete(ctx, key) })
+	if err == nil {
+		w.ack(ctx, key, seq)
+		return
+	}
+
+	report(err)
+	if w.deadLetter(ctx, DeadLetter{Op: OpDelete, Key: key, Err: err, Attempts: attempts, Time: w.clock.Now()}) {
+		w.ack(ctx, key, seq)
+	}
+}
+
+// Set stores key-value to cache and queues it to be persisted
+func (w *WriteBehind) Set(ctx context.Context, key string, value any, c Cacher, p Persister, options ...SetOption) error {
+	release, err := w.admit()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := c.Set(ctx, key, value, options...); err != nil {
+		return err
+	}
+
+	if p != nil {
+		return w.enqueue(ctx, OpSave, key, value, c, p)
+	}
+
+	return nil
+}
+
+// Get retrieves value from cache
+// if not found, retrieves value from persistence storage
+// and stores the value to cache
+func (w *WriteBehind) Get(ctx context.Context, key string, c Cacher, p Persister) (any, error) {
+	return readThrough(ctx, key, c, p)
+}
+
+// Delete deletes value from cache and queues it to be deleted from persistence storage
+func (w *WriteBehind) Delete(ctx context.Context, key string, c Cacher, p Persister) error {
+	release, err := w.admit()
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if err := c.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	if p != nil {
+		return w.enqueue(ctx, OpDelete, key, nil, c, p)
+	}
+
+	return nil
+}
+
+// replay queues pending writes of journal, in order they were recorded
+func (w *WriteBehind) replay(ctx context.Context, c Cacher, p Persister) {
+	if w.journal == nil || p == nil {
+		return
+	}
+
+	entries, err := w.journal.Pending()
+	if err != nil {
+		reporterFrom(ctx, "journal", "")(err)
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	w.init()
+
+	var superseded []uint64
+	w.mu.Lock()
+	for _, entry := range entries {
+		if seq := w.push(&queuedWrite{ctx: ctx, op: entry.Op, key: entry.Key, value: entry.Value, seq: entry.Seq, c: c, p: p}); seq != 0 {
+			superseded = append(superseded, seq)
+		}
+	}
+	w.mu.Unlock()
+
+	for _, seq := range superseded {
+		w.ack(ctx, "", seq)
+	}
+
+	signal(w.wake)
+}
+
+// Flush persists queued writes without waiting for flush interval and waits for them to finish
+// it returns context error if context is done before
+func (w *WriteBehind) Flush(ctx context.Context) error {
+	w.init()
+	signal(w.flush)
+
+	return wait(ctx, w.wait)
+}
+
+// Drain waits for pending asynchronous writes to persistence storage to finish, same as Flush
+// it returns context error if context is done before
+func (w *WriteBehind) Drain(ctx context.Context) error {
+	return w.Flush(ctx)
+}
+
+// Close stops accepting writes, persists queued writes and stops background writer
+// later writes return ErrWriteBehindClosed, writes which are not persisted before
+// context is done stay in journal if there is one
+func (w *WriteBehind) Close(ctx context.Context) error {
+	w.init()
+
+	w.closing.Lock()
+	w.mu.Lock()
+	closed := w.closed
+	w.closed = true
+	w.mu.Unlock()
+	w.closing.Unlock()
+
+	if closed {
+		return wait(ctx, func() { <-w.done })
+	}
+
+	close(w.stop)
+
+	return wait(ctx, func() {
+		<-w.done
+		w.wait()
+	})
+}
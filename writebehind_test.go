@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingPersister counts saves and concurrent saves, and fails saves with cancelled context
+type countingPersister struct {
+	mapPersister
+	delay   time.Duration
+	saves   int
+	running int
+	busiest int
+}
+
+func (p *countingPersister) Save(ctx context.Context, key string, value any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.saves++
+	p.running++
+	p.busiest = max(p.busiest, p.running)
+	p.mu.Unlock()
+
+	time.Sleep(p.delay)
+
+	p.mu.Lock()
+	p.running--
+	p.mu.Unlock()
+
+	return p.mapPersister.Save(ctx, key, value)
+}
+
+func TestWriteBehind_Coalesce(t *testing.T) {
+	persister := &countingPersister{mapPersister: *newMapPersister()}
+	w := NewWriteBehind(WithFlushInterval(time.Hour))
+	c, _ := New(newMapCacher(), persister, WithPattern(w))
+
+	for i := 0; i < 3; i++ {
+		_ = c.Set(context.Background(), "key", i)
+	}
+	if persister.saves != 0 {
+		t.Fatalf("saves before flush = %v, want %v", persister.saves, 0)
+	}
+
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if persister.saves != 1 || persister.data["key"] != 2 {
+		t.Errorf("saves = %v, persisted = %v, want %v, %v", persister.saves, persister.data["key"], 1, 2)
+	}
+	if got := w.Stats().Coalesced; got != 2 {
+		t.Errorf("Stats().Coalesced = %v, want %v", got, 2)
+	}
+}
+
+// historyPersister records every saved value of keys
+type historyPersister struct {
+	mapPersister
+	history map[string][]any
+}
+
+func (p *historyPersister) Save(ctx context.Context, key string, value any) error {
+	p.mu.Lock()
+	p.history[key] = append(p.history[key], value)
+	p.mu.Unlock()
+
+	return p.mapPersister.Save(ctx, key, value)
+}
+
+func TestWriteBehind_WithoutWriteCoalescing(t *testing.T) {
+	persister := &historyPersister{mapPersister: *newMapPersister(), history: map[string][]any{}}
+	w := NewWriteBehind(WithFlushInterval(time.Hour), WithWriteBatchSize(2), WithoutWriteCoalescing())
+	c, _ := New(newMapCacher(), persister, WithPattern(w))
+
+	for i := 0; i < 5; i++ {
+		_ = c.Set(context.Background(), "key", i)
+		_ = c.Set(context.Background(), "other", i)
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	want := []any{0, 1, 2, 3, 4}
+	for _, key := range []string{"key", "other"} {
+		if got := persister.history[key]; !reflect.DeepEqual(got, want) {
+			t.Errorf("saved %s = %v, want %v", key, got, want)
+		}
+	}
+	if got := w.Stats().Coalesced; got != 0 {
+		t.Errorf("Stats().Coalesced = %v, want %v", got, 0)
+	}
+}
+
+func TestWriteBehind_Concurrency(t *testing.T) {
+	persister := &countingPersister{mapPersister: *newMapPersister(), delay: 5 * time.Millisecond}
+	w := NewWriteBehind(WithWriteBatchSize(4), WithWriteConcurrency(2))
+	c, _ := New(newMapCacher(), persister, WithPattern(w))
+
+	data := map[string]any{}
+	for i := 0; i < 10; i++ {
+		data[fmt.Sprint("key", i)] = i
+	}
+	_ = c.SetMany(context.Background(), data)
+	_ = c.Drain(context.Background())
+
+	if len(persister.data) != len(data) {
+		t.Errorf("persisted = %v, want %v", len(persister.data), len(data))
+	}
+	if persister.busiest > 2 {
+		t.Errorf("concurrent saves = %v, want at most %v", persister.busiest, 2)
+	}
+}
+
+func TestWriteBehind_DetachedContext(t *testing.T) {
+	persister := &countingPersister{mapPersister: *newMapPersister()}
+	w := NewWriteBehind(WithFlushInterval(time.Hour))
+	c, _ := New(newMapCacher(), persister, WithPattern(w))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = c.Set(ctx, "key", "value")
+	cancel()
+	_ = w.Flush(context.Background())
+
+	if got := persister.data["key"]; got != "value" {
+		t.Errorf("persisted = %v, want %v", got, "value")
+	}
+}
+
+func TestWriteBehind_Close(t *testing.T) {
+	persister := &countingPersister{mapPersister: *newMapPersister()}
+	w := NewWriteBehind(WithFlushInterval(time.Hour))
+	c, _ := New(newMapCacher(), persister, WithPattern(w))
+
+	_ = c.Set(context.Background(), "a", "one")
+	_ = c.Delete(context.Background(), "b")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := w.Close(context.Background()); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := persister.data["a"]; got != "one" {
+		t.Errorf("persisted = %v, want %v", got, "one")
+	}
+	if err := c.Set(context.Background(), "c", "three"); !errors.Is(err, ErrWriteBehindClosed) {
+		t.Errorf("Set() error = %v, want %v", err, ErrWriteBehindClosed)
+	}
+	if err := w.Flush(context.Background()); err != nil {
+		t.Errorf("Flush() error = %v", err)
+	}
+}
+
+func TestWriteBehind_CloseWhileWriting(t *testing.T) {
+	persister := newMapPersister()
+	cacher := newMapCacher()
+	w := NewWriteBehind()
+	c, _ := New(cacher, persister, WithPattern(w))
+
+	var wg sync.WaitGroup
+	accepted := make([]bool, 50)
+	for i := range accepted {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			accepted[i] = c.Set(context.Background(), fmt.Sprint(i), i) == nil
+			_ = w.Flush(context.Background())
+		}(i)
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	wg.Wait()
+
+	persister.mu.Lock()
+	defer persister.mu.Unlock()
+	for i, ok := range accepted {
+		if _, persisted := persister.data[fmt.Sprint(i)]; ok && !persisted {
+			t.Errorf("accepted write of %v is not persisted", i)
+		}
+		if cached, _ := cacher.Get(context.Background(), fmt.Sprint(i)); !ok && cached != nil {
+			t.Errorf("rejected write of %v is cached", i)
+		}
+	}
+}